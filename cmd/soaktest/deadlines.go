@@ -0,0 +1,82 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// deadlines checks that the gateway keeps producing output frames while a
+// call is being fed to it
+// A deadline is missed when no frame arrives within the limit of the start of
+// the call or of the previous frame.
+type deadlines struct {
+	limit time.Duration
+
+	mu     sync.Mutex
+	active bool
+	last   time.Time
+	frames uint64 // frames received during calls
+	stray  uint64 // frames received outside calls
+	missed uint64
+	worst  time.Duration
+}
+
+func newDeadlines(limit time.Duration) *deadlines {
+	return &deadlines{limit: limit}
+}
+
+// start begins checking a call whose first input frame is sent at at
+func (d *deadlines) start(at time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active = true
+	d.last = at
+}
+
+// frame records an output frame received at at
+func (d *deadlines) frame(at time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.active {
+		d.stray++
+		return
+	}
+	d.frames++
+	d.observe(at.Sub(d.last))
+	d.last = at
+}
+
+// stop ends the call once its last input frame has been sent at at
+// Output that stalled before the end of the call counts as a missed deadline.
+func (d *deadlines) stop(at time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.active {
+		d.observe(at.Sub(d.last))
+	}
+	d.active = false
+}
+
+// observe records the gap between two output frames
+func (d *deadlines) observe(gap time.Duration) {
+	if gap > d.worst {
+		d.worst = gap
+	}
+	if gap > d.limit {
+		d.missed++
+	}
+}
+
+// deadlineStats is a copy of the counters of one direction
+type deadlineStats struct {
+	Frames uint64
+	Stray  uint64
+	Missed uint64
+	Worst  time.Duration
+}
+
+func (d *deadlines) stats() deadlineStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return deadlineStats{Frames: d.frames, Stray: d.stray, Missed: d.missed, Worst: d.worst}
+}
@@ -0,0 +1,366 @@
+// Command soaktest drives a ysf2dmr gateway with hours of synthetic traffic to
+// catch the slow leaks of a 24/7 daemon.
+//
+// It plays both the YSF reflector and the DMR master of the gateway, starts
+// the gateway binary with a generated configuration and alternates YSF->DMR
+// and DMR->YSF calls until the duration is up:
+//
+//	go build -o ysf2dmr ./cmd/ysf2dmr
+//	go run ./cmd/soaktest -gateway ./ysf2dmr -duration 8h
+//
+// The run fails when the goroutine count or memory in use after the warm-up
+// grows by more than the allowed slack, or when the gateway misses frame
+// deadlines: it stops producing output for longer than -deadline during a
+// call. The exit status is 1 on failure.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// Fixed identities of the synthetic traffic
+const (
+	soakCallsign  = "N0CALL"
+	soakGatewayID = 1234567 // DMR ID the gateway logs in with
+	soakSourceID  = 3100001 // DMR ID of the DMR->YSF caller
+	soakTG        = 9       // talkgroup both directions use
+	soakPassword  = "soaktest"
+	soakColorCode = 1
+)
+
+// The gateway waits 10s before its first login attempt and retries every 10s
+const loginTimeout = time.Minute
+
+// settings holds the command line options
+type settings struct {
+	gateway  string
+	workDir  string
+	duration time.Duration
+	call     time.Duration
+	gap      time.Duration
+	seed     int64
+
+	warmup         time.Duration
+	window         time.Duration
+	sample         time.Duration
+	report         time.Duration
+	deadline       time.Duration
+	maxMissed      uint64
+	goroutineSlack int
+	memoryGrowth   uint64 // bytes
+
+	ysfPort     int
+	gatewayPort int
+	dmrPort     int
+	dmrLocal    int
+	httpAddress string
+}
+
+func main() {
+	var s settings
+	var memoryGrowthMB uint64
+	flag.StringVar(&s.gateway, "gateway", "", "ysf2dmr binary to test")
+	flag.StringVar(&s.workDir, "dir", "", "Directory for the generated configuration and gateway log (default a new temporary directory)")
+	flag.DurationVar(&s.duration, "duration", 4*time.Hour, "Length of the soak test")
+	flag.DurationVar(&s.call, "call", 20*time.Second, "Length of each call")
+	flag.DurationVar(&s.gap, "gap", 5*time.Second, "Pause between calls")
+	flag.Int64Var(&s.seed, "seed", 1, "Seed of the random voice data")
+	flag.DurationVar(&s.warmup, "warmup", 5*time.Minute, "Time allowed for caches and buffers to fill before measuring growth")
+	flag.DurationVar(&s.window, "window", 10*time.Minute, "Length of the first and last windows compared for growth")
+	flag.DurationVar(&s.sample, "sample", 30*time.Second, "Interval between resource samples")
+	flag.DurationVar(&s.report, "report", 10*time.Minute, "Interval between progress reports")
+	flag.DurationVar(&s.deadline, "deadline", time.Second, "Longest wait for gateway output during a call")
+	flag.Uint64Var(&s.maxMissed, "max-missed", 0, "Missed frame deadlines tolerated")
+	flag.IntVar(&s.goroutineSlack, "goroutine-slack", 10, "Goroutine growth tolerated")
+	flag.Uint64Var(&memoryGrowthMB, "memory-growth", 16, "Memory growth tolerated in MB")
+	flag.IntVar(&s.ysfPort, "ysf-port", 42100, "UDP port of the simulated YSF reflector")
+	flag.IntVar(&s.gatewayPort, "gateway-port", 42101, "UDP port the gateway listens on for YSF")
+	flag.IntVar(&s.dmrPort, "dmr-port", 62100, "UDP port of the simulated DMR master")
+	flag.IntVar(&s.dmrLocal, "dmr-local", 62101, "UDP port the gateway uses toward the DMR master")
+	flag.StringVar(&s.httpAddress, "http", "127.0.0.1:8099", "HTTP address given to the gateway for /api/runtime")
+	flag.Parse()
+	s.memoryGrowth = memoryGrowthMB << 20
+
+	if s.gateway == "" {
+		fmt.Fprintln(os.Stderr, "Usage: soaktest -gateway <ysf2dmr binary> [options]")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	passed, err := run(ctx, s)
+	stop()
+	if err != nil {
+		log.Fatalf("Soak test error: %v", err)
+	}
+	if !passed {
+		os.Exit(1)
+	}
+}
+
+// run performs the soak test, reporting whether every check passed
+func run(ctx context.Context, s settings) (bool, error) {
+	dir := s.workDir
+	if dir == "" {
+		var err error
+		if dir, err = os.MkdirTemp("", "soaktest"); err != nil {
+			return false, err
+		}
+	} else if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, err
+	}
+	configFile := filepath.Join(dir, "soaktest.ini")
+	if err := os.WriteFile(configFile, []byte(gatewayConfig(s)), 0644); err != nil {
+		return false, err
+	}
+
+	ysfToDMR := newDeadlines(s.deadline)
+	dmrToYSF := newDeadlines(s.deadline)
+
+	dmrMaster, err := newMaster(s.dmrPort, soakPassword, soakColorCode, ysfToDMR)
+	if err != nil {
+		return false, err
+	}
+	defer dmrMaster.close()
+	go dmrMaster.run()
+
+	ysfReflector, err := newReflector(s.ysfPort, s.gatewayPort, dmrToYSF)
+	if err != nil {
+		return false, err
+	}
+	defer ysfReflector.close()
+	go ysfReflector.run()
+
+	gateway, exited, err := startGateway(s.gateway, configFile, filepath.Join(dir, "gateway.log"))
+	if err != nil {
+		return false, err
+	}
+	defer stopGateway(gateway, exited)
+	log.Printf("Started %s (pid %d), configuration and log in %s", s.gateway, gateway.Process.Pid, dir)
+
+	select {
+	case <-dmrMaster.loggedIn:
+	case err := <-exited:
+		return false, fmt.Errorf("gateway exited before logging in: %v", err)
+	case <-time.After(loginTimeout):
+		return false, fmt.Errorf("gateway did not log in to the DMR master within %v", loginTimeout)
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+
+	start := time.Now()
+	monitorCtx, stopMonitor := context.WithCancel(ctx)
+	defer stopMonitor()
+	resources := newMonitor(s.httpAddress, gateway.Process.Pid)
+	go resources.run(monitorCtx, s.sample)
+
+	rng := rand.New(rand.NewSource(s.seed))
+	end := start.Add(s.duration)
+	nextReport := start.Add(s.report)
+	var calls int
+	var exitErr error
+	for time.Now().Before(end) && exitErr == nil && ctx.Err() == nil {
+		var err error
+		if calls%2 == 0 {
+			err = ysfReflector.sendCall(ctx, soakCallsign, s.call, rng, ysfToDMR)
+		} else {
+			err = dmrMaster.sendCall(ctx, soakSourceID, soakTG, s.call, rng, dmrToYSF)
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Call %d error: %v", calls+1, err)
+		}
+		calls++
+
+		select {
+		case exitErr = <-exited:
+		case <-ctx.Done():
+		case <-time.After(s.gap):
+		}
+
+		if time.Now().After(nextReport) {
+			logProgress(start, calls, resources, ysfToDMR, dmrToYSF)
+			nextReport = nextReport.Add(s.report)
+		}
+	}
+	stopMonitor()
+
+	if exitErr != nil {
+		log.Printf("FAIL: gateway exited after %v: %v", time.Since(start).Round(time.Second), exitErr)
+		return false, nil
+	}
+	if ctx.Err() != nil {
+		log.Printf("Interrupted after %v", time.Since(start).Round(time.Second))
+	}
+	log.Printf("Gateway logged in %d times, sent %d DMR pings and %d YSF polls",
+		dmrMaster.logins.Load(), dmrMaster.pings.Load(), ysfReflector.polls.Load())
+	return evaluate(s, start, calls, resources, ysfToDMR, dmrToYSF), nil
+}
+
+// startGateway runs the gateway with its output going to logFile
+// The exit status is delivered on the returned channel.
+func startGateway(binary, configFile, logFile string) (*exec.Cmd, <-chan error, error) {
+	output, err := os.Create(logFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cmd := exec.Command(binary, "-config", configFile)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Start(); err != nil {
+		output.Close()
+		return nil, nil, fmt.Errorf("failed to start %s: %v", binary, err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		output.Close()
+		if err == nil {
+			err = errors.New("exit status 0")
+		}
+		exited <- err
+	}()
+	return cmd, exited, nil
+}
+
+// stopGateway interrupts the gateway, killing it if it does not stop
+func stopGateway(cmd *exec.Cmd, exited <-chan error) {
+	if cmd.ProcessState != nil {
+		return
+	}
+	cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-exited:
+	case <-time.After(10 * time.Second):
+		log.Printf("Gateway did not stop within 10s, killing it")
+		cmd.Process.Kill()
+	}
+}
+
+// logProgress reports the state of the run
+func logProgress(start time.Time, calls int, resources *monitor, ysfToDMR, dmrToYSF *deadlines) {
+	up, down := ysfToDMR.stats(), dmrToYSF.stats()
+	resource := "no samples"
+	if s, ok := resources.latest(); ok {
+		resource = describeSample(s)
+	}
+	log.Printf("%v: %d calls, %s, YSF->DMR %d frames %d missed, DMR->YSF %d frames %d missed",
+		time.Since(start).Round(time.Second), calls, resource, up.Frames, up.Missed, down.Frames, down.Missed)
+}
+
+// describeSample formats a resource sample
+func describeSample(s sample) string {
+	if s.Goroutines < 0 {
+		return fmt.Sprintf("RSS %.1f MB", float64(s.Memory)/(1<<20))
+	}
+	return fmt.Sprintf("%d goroutines, heap %.1f MB", s.Goroutines, float64(s.Memory)/(1<<20))
+}
+
+// evaluate logs the results of the run and reports whether it passed
+func evaluate(s settings, start time.Time, calls int, resources *monitor, ysfToDMR, dmrToYSF *deadlines) bool {
+	passed := true
+	fail := func(format string, args ...interface{}) {
+		log.Printf("FAIL: "+format, args...)
+		passed = false
+	}
+
+	log.Printf("Ran %v, %d calls", time.Since(start).Round(time.Second), calls)
+
+	for _, direction := range []struct {
+		name  string
+		stats deadlineStats
+	}{
+		{"YSF->DMR", ysfToDMR.stats()},
+		{"DMR->YSF", dmrToYSF.stats()},
+	} {
+		d := direction.stats
+		log.Printf("%s: %d frames (%d outside calls), %d missed deadlines, longest gap %v",
+			direction.name, d.Frames, d.Stray, d.Missed, d.Worst.Round(time.Millisecond))
+		if d.Frames == 0 && calls > 1 {
+			fail("%s: the gateway produced no output", direction.name)
+		} else if d.Missed > s.maxMissed {
+			fail("%s: %d missed frame deadlines, %d tolerated", direction.name, d.Missed, s.maxMissed)
+		}
+	}
+
+	if n := resources.failedSamples(); n > 0 {
+		log.Printf("%d resource samples failed", n)
+	}
+	first, last, ok := resources.growth(start, s.warmup, s.window)
+	if !ok {
+		log.Printf("Run too short to check growth: needs the warm-up plus two windows (%v)", s.warmup+2*s.window)
+		return passed
+	}
+	log.Printf("Resources: %s after warm-up, %s at the end", describeSample(first), describeSample(last))
+
+	if first.Goroutines >= 0 && last.Goroutines > first.Goroutines+s.goroutineSlack {
+		fail("goroutines grew from %d to %d, %d tolerated", first.Goroutines, last.Goroutines, s.goroutineSlack)
+	}
+	if last.Memory > first.Memory+s.memoryGrowth {
+		fail("memory grew by %.1f MB, %d MB tolerated",
+			float64(last.Memory-first.Memory)/(1<<20), s.memoryGrowth>>20)
+	}
+	if passed {
+		log.Printf("PASS")
+	}
+	return passed
+}
+
+// gatewayConfig generates the gateway configuration pointing both networks
+// at the soak test
+func gatewayConfig(s settings) string {
+	return fmt.Sprintf(`[Info]
+RXFrequency=435000000
+TXFrequency=435000000
+Power=1
+Location=Soak test
+Description=ysf2dmr soak test
+
+[YSF Network]
+Callsign=%s
+DstAddress=127.0.0.1
+DstPort=%d
+LocalAddress=127.0.0.1
+LocalPort=%d
+EnableWiresX=0
+HangTime=1000
+RFHangTime=0
+NetHangTime=0
+Debug=0
+Daemon=0
+
+[DMR Network]
+Id=%d
+Local=%d
+StartupDstId=%d
+StartupPC=0
+ColorCode=%d
+Address=127.0.0.1
+Port=%d
+Jitter=500
+Password=%s
+Protocol=homebrew
+Debug=0
+
+[DMR Id Lookup]
+File=
+
+[HTTP]
+Enable=1
+Address=%s
+`, soakCallsign, s.ysfPort, s.gatewayPort,
+		soakGatewayID, s.dmrLocal, soakTG, soakColorCode, s.dmrPort, soakPassword,
+		s.httpAddress)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	mrand "math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/dmr"
+)
+
+// DMR voice bursts are sent every 60ms
+const dmrBurstPeriod = 60 * time.Millisecond
+
+// Repeater ID the master puts on the traffic it sends, so the gateway's loop
+// guard does not take it for its own
+const masterRepeaterID = 1
+
+// master plays the Homebrew DMR master the gateway logs in to
+type master struct {
+	conn      *net.UDPConn
+	password  string
+	colorCode uint8
+	received  *deadlines // YSF->DMR voice bursts
+
+	mu       sync.Mutex
+	peer     *net.UDPAddr
+	salt     [protocol.DMR_SALT_LENGTH]byte
+	seqNo    uint8
+	loggedIn chan struct{} // closed by the first completed login
+
+	logins atomic.Uint64
+	pings  atomic.Uint64
+}
+
+func newMaster(port int, password string, colorCode uint8, received *deadlines) (*master, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DMR master port %d: %v", port, err)
+	}
+	return &master{
+		conn:      conn,
+		password:  password,
+		colorCode: colorCode,
+		received:  received,
+		loggedIn:  make(chan struct{}),
+	}, nil
+}
+
+// run answers the gateway's login and pings and receives its voice until the
+// master is closed
+func (m *master) run() {
+	buffer := make([]byte, 512)
+	for {
+		n, from, err := m.conn.ReadFromUDP(buffer)
+		if err != nil {
+			return
+		}
+		m.handle(buffer[:n], from)
+	}
+}
+
+// handle processes one packet from the gateway
+// Tags sharing a prefix are matched longest first.
+func (m *master) handle(packet []byte, from *net.UDPAddr) {
+	has := func(magic string) bool { return bytes.HasPrefix(packet, []byte(magic)) }
+
+	switch {
+	case has(protocol.NETWORK_MAGIC_DATA):
+		if len(packet) == protocol.HOMEBREW_DATA_PACKET_LENGTH && packet[15]&0x20 == 0 {
+			m.received.frame(time.Now())
+		}
+
+	case has(protocol.NETWORK_MAGIC_PING):
+		m.pings.Add(1)
+		m.reply(from, protocol.NETWORK_MAGIC_PONG, packet[7:])
+
+	case has(protocol.NETWORK_MAGIC_CLOSE):
+		m.mu.Lock()
+		m.peer = nil
+		m.mu.Unlock()
+
+	case has(protocol.NETWORK_MAGIC_LOGIN) && len(packet) >= protocol.NETWORK_LOGIN_LENGTH:
+		m.mu.Lock()
+		rand.Read(m.salt[:])
+		salt := m.salt
+		m.mu.Unlock()
+		m.reply(from, protocol.NETWORK_MAGIC_ACK, salt[:])
+
+	case has(protocol.NETWORK_MAGIC_AUTH) && len(packet) >= protocol.NETWORK_AUTH_LENGTH:
+		m.mu.Lock()
+		hash := sha256.Sum256(append(m.salt[:], m.password...))
+		m.mu.Unlock()
+		if !bytes.Equal(packet[8:40], hash[:]) {
+			m.reply(from, protocol.NETWORK_MAGIC_NAK, packet[4:8])
+			return
+		}
+		m.reply(from, protocol.NETWORK_MAGIC_ACK, packet[4:8])
+
+	case has(protocol.NETWORK_MAGIC_CONFIG):
+		m.reply(from, protocol.NETWORK_MAGIC_ACK, packet[4:8])
+		m.mu.Lock()
+		m.peer = from
+		m.mu.Unlock()
+		if m.logins.Add(1) == 1 {
+			close(m.loggedIn)
+		}
+
+	case has(protocol.NETWORK_MAGIC_OPTIONS):
+		m.reply(from, protocol.NETWORK_MAGIC_ACK, packet[4:8])
+	}
+}
+
+// reply sends a control packet: a tag followed by the given bytes
+func (m *master) reply(to *net.UDPAddr, magic string, data []byte) {
+	m.conn.WriteToUDP(append([]byte(magic), data...), to)
+}
+
+// sendCall sends a group voice call of the given length from src to tg on
+// slot 2, framed by a voice LC header and terminator, checking the deadlines
+// of the gateway's output while it lasts
+// Voice bursts carry random AMBE data.
+func (m *master) sendCall(ctx context.Context, src, tg uint32, length time.Duration, rng *mrand.Rand, output *deadlines) error {
+	lc := &dmr.LinkControl{FLCO: protocol.FLCO_GROUP, SourceID: src, DestinationID: tg}
+	streamID := rng.Uint32()
+
+	header, err := dmr.BuildFullLCBurst(lc, dmr.DT_VOICE_LC_HEADER, m.colorCode)
+	if err != nil {
+		return err
+	}
+	terminator, err := dmr.BuildFullLCBurst(lc, dmr.DT_TERMINATOR_WITH_LC, m.colorCode)
+	if err != nil {
+		return err
+	}
+
+	if err := m.send(src, tg, 0x20|dmr.DT_VOICE_LC_HEADER, streamID, header); err != nil {
+		return err
+	}
+	output.start(time.Now())
+	defer func() { output.stop(time.Now()) }()
+
+	ticker := time.NewTicker(dmrBurstPeriod)
+	defer ticker.Stop()
+	end := time.Now().Add(length)
+	burst := make([]byte, protocol.DMR_FRAME_LENGTH_BYTES)
+	for n := 0; time.Now().Before(end); n = (n + 1) % 6 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		rng.Read(burst)
+		flags := uint8(n) // voice burst B-F
+		if n == 0 {
+			flags = 0x10 // voice burst A carries the sync
+		}
+		if err := m.send(src, tg, flags, streamID, burst); err != nil {
+			return err
+		}
+	}
+	return m.send(src, tg, 0x20|dmr.DT_TERMINATOR_WITH_LC, streamID, terminator)
+}
+
+// send sends a DMRD packet on slot 2 to the logged in gateway
+func (m *master) send(src, dst uint32, flags uint8, streamID uint32, burst []byte) error {
+	m.mu.Lock()
+	peer := m.peer
+	seqNo := m.seqNo
+	m.seqNo++
+	m.mu.Unlock()
+	if peer == nil {
+		return fmt.Errorf("gateway is not logged in")
+	}
+
+	packet := make([]byte, protocol.HOMEBREW_DATA_PACKET_LENGTH)
+	copy(packet[0:4], protocol.NETWORK_MAGIC_DATA)
+	packet[4] = seqNo
+	packet[5], packet[6], packet[7] = byte(src>>16), byte(src>>8), byte(src)
+	packet[8], packet[9], packet[10] = byte(dst>>16), byte(dst>>8), byte(dst)
+	binary.BigEndian.PutUint32(packet[11:15], masterRepeaterID)
+	packet[15] = 0x80 | flags // slot 2
+	binary.BigEndian.PutUint32(packet[16:20], streamID)
+	copy(packet[20:53], burst)
+
+	_, err := m.conn.WriteToUDP(packet, peer)
+	return err
+}
+
+func (m *master) close() {
+	m.conn.Close()
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/web"
+)
+
+// sample is one reading of the gateway's resource use
+// Goroutines is -1 when the gateway does not serve /api/runtime; Memory is
+// then the resident set size instead of the heap in use.
+type sample struct {
+	At         time.Time
+	Goroutines int
+	Memory     uint64
+}
+
+// monitor samples the gateway's goroutines and memory from its /api/runtime
+// endpoint, or its resident set size from /proc without one
+// The source is picked by the first sample so readings stay comparable.
+type monitor struct {
+	url     string
+	pid     int
+	client  *http.Client
+	useProc bool
+
+	mu      sync.Mutex
+	samples []sample
+	errors  int
+}
+
+func newMonitor(httpAddress string, pid int) *monitor {
+	return &monitor{
+		url:    "http://" + httpAddress + "/api/runtime",
+		pid:    pid,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// run takes a sample every interval until the context is done
+func (m *monitor) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.Lock()
+		first := len(m.samples) == 0 && m.errors == 0
+		m.mu.Unlock()
+		if first {
+			if _, err := m.runtimeStats(); err != nil {
+				m.useProc = true
+			}
+		}
+
+		s, err := m.take()
+		m.mu.Lock()
+		if err != nil {
+			m.errors++
+		} else {
+			m.samples = append(m.samples, s)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// take reads the current resource use
+func (m *monitor) take() (sample, error) {
+	now := time.Now()
+	if m.useProc {
+		rss, err := residentSetSize(m.pid)
+		if err != nil {
+			return sample{}, err
+		}
+		return sample{At: now, Goroutines: -1, Memory: rss}, nil
+	}
+
+	stats, err := m.runtimeStats()
+	if err != nil {
+		return sample{}, err
+	}
+	return sample{At: now, Goroutines: stats.Goroutines, Memory: stats.HeapInuse}, nil
+}
+
+func (m *monitor) runtimeStats() (web.RuntimeStats, error) {
+	var stats web.RuntimeStats
+	resp, err := m.client.Get(m.url)
+	if err != nil {
+		return stats, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return stats, fmt.Errorf("%s: %s", m.url, resp.Status)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&stats)
+	return stats, err
+}
+
+// residentSetSize reads VmRSS of a process from /proc, in bytes
+func residentSetSize(pid int) (uint64, error) {
+	file, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "VmRSS:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			return kb * 1024, err
+		}
+	}
+	return 0, fmt.Errorf("no VmRSS in /proc/%d/status", pid)
+}
+
+// latest returns the most recent sample, if any
+func (m *monitor) latest() (sample, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.samples) == 0 {
+		return sample{}, false
+	}
+	return m.samples[len(m.samples)-1], true
+}
+
+// growth compares the first window of samples after the warm-up with the
+// last window of the run, returning the smallest readings of each
+// The smallest reading of a window is the level the gateway settles back to
+// between bursts of activity and garbage collections; a leak raises it.
+func (m *monitor) growth(start time.Time, warmup, window time.Duration) (first, last sample, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	from := start.Add(warmup)
+	var settled []sample
+	for _, s := range m.samples {
+		if !s.At.Before(from) {
+			settled = append(settled, s)
+		}
+	}
+	if len(settled) == 0 || settled[len(settled)-1].At.Sub(settled[0].At) < 2*window {
+		return first, last, false // too short to tell a leak from noise
+	}
+
+	end := settled[len(settled)-1].At
+	first = minimum(settled, func(s sample) bool { return s.At.Before(settled[0].At.Add(window)) })
+	last = minimum(settled, func(s sample) bool { return s.At.After(end.Add(-window)) })
+	return first, last, true
+}
+
+// minimum returns the smallest goroutine count and memory of the samples
+// selected by in
+func minimum(samples []sample, in func(sample) bool) sample {
+	low := sample{Goroutines: -1}
+	found := false
+	for _, s := range samples {
+		if !in(s) {
+			continue
+		}
+		if !found || s.Memory < low.Memory {
+			low.Memory = s.Memory
+			low.At = s.At
+		}
+		if s.Goroutines >= 0 && (low.Goroutines < 0 || s.Goroutines < low.Goroutines) {
+			low.Goroutines = s.Goroutines
+		}
+		found = true
+	}
+	return low
+}
+
+// failedSamples returns the number of samples that could not be taken
+func (m *monitor) failedSamples() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.errors
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+)
+
+// YSF frames are sent every 100ms
+const ysfFramePeriod = 100 * time.Millisecond
+
+// reflector plays the YSF reflector the gateway is linked to
+// Calls are sent to the gateway's local port from the reflector port, which
+// is the only source the gateway accepts.
+type reflector struct {
+	conn     *net.UDPConn
+	gateway  *net.UDPAddr
+	received *deadlines // DMR->YSF voice frames
+
+	polls atomic.Uint64
+}
+
+func newReflector(port, gatewayPort int, received *deadlines) (*reflector, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open YSF reflector port %d: %v", port, err)
+	}
+	return &reflector{
+		conn:     conn,
+		gateway:  &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: gatewayPort},
+		received: received,
+	}, nil
+}
+
+// run receives polls and voice from the gateway until the reflector is closed
+func (r *reflector) run() {
+	buffer := make([]byte, 512)
+	for {
+		n, _, err := r.conn.ReadFromUDP(buffer)
+		if err != nil {
+			return
+		}
+		switch ysf.ClassifyPacket(buffer[:n]) {
+		case ysf.PacketPoll:
+			r.polls.Add(1)
+		case ysf.PacketData:
+			frame := &ysf.Frame{}
+			if frame.Parse(buffer[:n]) == nil && frame.IsCommunications() && frame.IsVoice() {
+				r.received.frame(time.Now())
+			}
+		}
+	}
+}
+
+// sendCall sends a VD mode 2 call of the given length from source, checking
+// the deadlines of the gateway's output while it lasts
+// Voice frames carry random AMBE data.
+func (r *reflector) sendCall(ctx context.Context, source string, length time.Duration, rng *rand.Rand, output *deadlines) error {
+	send := func(fi, fn uint8, payload []byte) error {
+		frame := &ysf.Frame{
+			SourceCallsign: source,
+			DestCallsign:   "ALL",
+			FICH:           ysf.FICH{FI: fi, DT: 2, FN: fn, FT: 7},
+			Payload:        payload,
+		}
+		_, err := r.conn.WriteToUDP(frame.Build(), r.gateway)
+		return err
+	}
+
+	if err := send(0, 0, ysf.BuildHeaderPayload("ALL", source, "", "")); err != nil {
+		return err
+	}
+	output.start(time.Now())
+	defer func() { output.stop(time.Now()) }()
+
+	ticker := time.NewTicker(ysfFramePeriod)
+	defer ticker.Stop()
+	end := time.Now().Add(length)
+	payload := make([]byte, 90)
+	for fn := uint8(1); time.Now().Before(end); fn++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		rng.Read(payload)
+		if err := send(1, fn%8, payload); err != nil {
+			return err
+		}
+	}
+	return send(2, 0, ysf.BuildHeaderPayload("ALL", source, "", ""))
+}
+
+func (r *reflector) close() {
+	r.conn.Close()
+}
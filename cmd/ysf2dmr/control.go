@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+)
+
+const (
+	// controlRequestTimeout bounds each request to the running gateway
+	controlRequestTimeout = 10 * time.Second
+)
+
+// controlCommands are the subcommands that manage the running gateway over
+// its HTTP server
+var controlCommands = map[string]func(addr string, args []string, out io.Writer) error{
+	"status":    runStatus,
+	"tg":        runTG,
+	"reconnect": runReconnect,
+}
+
+// isControlCommand returns true if name is a subcommand for the running
+// gateway
+func isControlCommand(name string) bool {
+	_, ok := controlCommands[name]
+	return ok
+}
+
+// runControl runs a subcommand against the gateway running with configFile
+func runControl(configFile string, args []string, out io.Writer) error {
+	cfg := config.NewConfig(configFile)
+	if err := cfg.Load(); err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+	if !cfg.GetHTTPEnabled() {
+		return fmt.Errorf("[HTTP] is not enabled in %s, the running gateway cannot be reached", configFile)
+	}
+	return controlCommands[args[0]](cfg.GetHTTPAddress(), args[1:], out)
+}
+
+// runStatus prints the current call, destination and link health
+func runStatus(addr string, args []string, out io.Writer) error {
+	var status struct {
+		Call      string `json:"call"`
+		SrcID     uint32 `json:"src_id"`
+		DstID     uint32 `json:"dst_id"`
+		Private   bool   `json:"private"`
+		Paused    bool   `json:"paused"`
+		DMRLinkUp bool   `json:"dmr_link_up"`
+		DMRHealth string `json:"dmr_health"`
+		YSFHealth string `json:"ysf_health"`
+		YSFErrors int64  `json:"ysf_errors"`
+		DMRErrors int64  `json:"dmr_errors"`
+	}
+	if err := controlRequest(http.MethodGet, addr, "/api/state", nil, &status); err != nil {
+		return err
+	}
+
+	destination := "unlinked"
+	if status.DstID != 0 {
+		destination = fmt.Sprintf("TG %d", status.DstID)
+		if status.Private {
+			destination = fmt.Sprintf("%d (private call)", status.DstID)
+		}
+	}
+	call := status.Call
+	if status.SrcID != 0 {
+		call += fmt.Sprintf(" from %d", status.SrcID)
+	}
+	link := "down"
+	if status.DMRLinkUp {
+		link = "up"
+	}
+
+	fmt.Fprintf(out, "Destination: %s\n", destination)
+	fmt.Fprintf(out, "Call:        %s\n", call)
+	fmt.Fprintf(out, "Bridging:    %s\n", map[bool]string{false: "active", true: "paused"}[status.Paused])
+	fmt.Fprintf(out, "DMR link:    %s, health %s, %d errors\n", link, status.DMRHealth, status.DMRErrors)
+	fmt.Fprintf(out, "YSF link:    health %s, %d errors\n", status.YSFHealth, status.YSFErrors)
+	return nil
+}
+
+// runTG links to the talk group given, or unlinks with 0 or "unlink"; with
+// -private the ID is a DMR user for a private call
+func runTG(addr string, args []string, out io.Writer) error {
+	private := len(args) > 0 && args[0] == "-private"
+	if private {
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: ysf2dmr tg [-private] <id>|unlink")
+	}
+
+	var tg uint64
+	if !strings.EqualFold(args[0], "unlink") {
+		var err error
+		if tg, err = strconv.ParseUint(args[0], 10, 32); err != nil {
+			return fmt.Errorf("invalid talk group %q", args[0])
+		}
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"tg": tg, "private": private})
+	var result struct {
+		DstID   uint32 `json:"dst_id"`
+		Private bool   `json:"private"`
+	}
+	if err := controlRequest(http.MethodPost, addr, "/api/tg", body, &result); err != nil {
+		return err
+	}
+
+	switch {
+	case result.DstID == 0:
+		fmt.Fprintln(out, "Unlinked")
+	case result.Private:
+		fmt.Fprintf(out, "Linked to %d (private call)\n", result.DstID)
+	default:
+		fmt.Fprintf(out, "Linked to TG %d\n", result.DstID)
+	}
+	return nil
+}
+
+// runReconnect logs in to the DMR master again
+func runReconnect(addr string, args []string, out io.Writer) error {
+	var result struct {
+		DMRLinkUp bool `json:"dmr_link_up"`
+	}
+	if err := controlRequest(http.MethodPost, addr, "/api/reconnect", nil, &result); err != nil {
+		return err
+	}
+	if result.DMRLinkUp {
+		fmt.Fprintln(out, "DMR network reconnected")
+	} else {
+		fmt.Fprintln(out, "DMR reconnection started, the master has not answered yet")
+	}
+	return nil
+}
+
+// controlRequest sends a request to the gateway at addr and decodes the JSON
+// response into result
+func controlRequest(method, addr, path string, body []byte, result interface{}) error {
+	req, err := http.NewRequest(method, "http://"+addr+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{Timeout: controlRequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gateway not reachable: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s %s", path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/testutil"
+	"github.com/dbehnke/ysf2dmr/pkg/gateway"
+)
+
+// writeTestConfig writes a configuration file serving [HTTP] on a free
+// loopback port when http is set, returning its path
+func writeTestConfig(t *testing.T, http bool) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	// A port the OS just handed out is free for the gateway
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	enable := 0
+	if http {
+		enable = 1
+	}
+	ini := fmt.Sprintf(`[YSF Network]
+Callsign=N0CALL
+DstAddress=127.0.0.1
+DstPort=42000
+
+[DMR Network]
+Id=3100001
+StartupDstId=91
+RestoreDestination=0
+
+[Log]
+FilePath=%s
+
+[HTTP]
+Enable=%d
+Address=%s
+`, dir, enable, address)
+
+	path := filepath.Join(dir, "YSF2DMR.ini")
+	if err := os.WriteFile(path, []byte(ini), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+// runTestGateway runs the gateway of configFile on in-memory networks until
+// the test ends, returning once its HTTP server answers
+func runTestGateway(t *testing.T, configFile string) *testutil.DMRNetwork {
+	t.Helper()
+	cfg, err := gateway.LoadConfig(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	dmrNet := testutil.NewDMRNetwork()
+	g, err := gateway.New(cfg, gateway.WithYSFNetwork(testutil.NewYSFNetwork()),
+		gateway.WithDMRNetwork(dmrNet), gateway.WithVersion("test"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- g.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	})
+
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		conn, err := net.Dial("tcp", cfg.GetHTTPAddress())
+		if err == nil {
+			conn.Close()
+			return dmrNet
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("gateway HTTP server not reachable: %v", err)
+		}
+	}
+}
+
+// control runs a control command, returning its output
+func control(t *testing.T, configFile string, args ...string) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := runControl(configFile, args, &out); err != nil {
+		t.Fatalf("%s error = %v", strings.Join(args, " "), err)
+	}
+	return out.String()
+}
+
+func TestControlCommands(t *testing.T) {
+	configFile := writeTestConfig(t, true)
+	dmrNet := runTestGateway(t, configFile)
+	dmrNet.SetConnected(true)
+
+	if out := control(t, configFile, "status"); !strings.Contains(out, "Destination: TG 91") ||
+		!strings.Contains(out, "Bridging:    active") {
+		t.Errorf("status output:\n%s", out)
+	}
+
+	if out := control(t, configFile, "tg", "3126"); out != "Linked to TG 3126\n" {
+		t.Errorf("tg 3126 output = %q", out)
+	}
+	if out := control(t, configFile, "tg", "-private", "3100123"); out != "Linked to 3100123 (private call)\n" {
+		t.Errorf("tg -private output = %q", out)
+	}
+	if out := control(t, configFile, "status"); !strings.Contains(out, "Destination: 3100123 (private call)") {
+		t.Errorf("status output after tg -private:\n%s", out)
+	}
+	if out := control(t, configFile, "tg", "unlink"); out != "Unlinked\n" {
+		t.Errorf("tg unlink output = %q", out)
+	}
+
+	if out := control(t, configFile, "reconnect"); !strings.HasPrefix(out, "DMR ") {
+		t.Errorf("reconnect output = %q", out)
+	}
+
+	var out bytes.Buffer
+	if err := runControl(configFile, []string{"tg", "TG91"}, &out); err == nil {
+		t.Error("tg TG91 succeeded")
+	}
+}
+
+func TestControlNeedsHTTP(t *testing.T) {
+	configFile := writeTestConfig(t, false)
+	var out bytes.Buffer
+	err := runControl(configFile, []string{"status"}, &out)
+	if err == nil || !strings.Contains(err.Error(), "[HTTP] is not enabled") {
+		t.Errorf("status error = %v, want [HTTP] not enabled", err)
+	}
+}
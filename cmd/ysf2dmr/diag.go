@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/diag"
+	"github.com/dbehnke/ysf2dmr/pkg/gateway"
+)
+
+const (
+	// diagFetchTimeout bounds the -diag request to a running gateway
+	diagFetchTimeout = 10 * time.Second
+)
+
+// captureLog copies the log to the gateway's recent log as well as stderr
+func captureLog() {
+	log.SetOutput(io.MultiWriter(os.Stderr, gateway.RecentLog))
+}
+
+// bundleDir returns the directory and file name root for bundles: the [Log]
+// FilePath and FileRoot, or the working directory when the config is unusable
+func bundleDir(configFile string) (string, string) {
+	cfg := config.NewConfig(configFile)
+	if err := cfg.Load(); err != nil {
+		return ".", "YSF2DMR"
+	}
+	return cfg.GetLogFilePath(), cfg.GetLogFileRoot()
+}
+
+// writeCrashBundle writes a diagnostic bundle for a fatal error
+func writeCrashBundle(configFile, version, reason string, stats func() []string) {
+	dir, root := bundleDir(configFile)
+	path, err := diag.WriteFile(dir, root, gateway.NewBundle(configFile, version, reason, stats))
+	if err != nil {
+		log.Printf("Failed to write the diagnostic bundle: %v", err)
+		return
+	}
+	log.Printf("Diagnostic bundle written to %s; please attach it to support requests", path)
+}
+
+// fatalf logs a fatal error, writes a diagnostic bundle and exits
+func fatalf(configFile, version string, stats func() []string, format string, args ...interface{}) {
+	reason := fmt.Sprintf(format, args...)
+	log.Print(reason)
+	writeCrashBundle(configFile, version, reason, stats)
+	os.Exit(1)
+}
+
+// crashOnPanic writes a diagnostic bundle for a panic and panics again
+// Use it deferred.
+func crashOnPanic(configFile, version string, stats func() []string) {
+	if r := recover(); r != nil {
+		writeCrashBundle(configFile, version, fmt.Sprintf("panic: %v\n%s", r, debug.Stack()), stats)
+		panic(r)
+	}
+}
+
+// runDiag saves a diagnostic bundle from the gateway running with configFile,
+// taken over its HTTP server, or with the configuration alone when the
+// gateway cannot be reached
+func runDiag(configFile, version string, out io.Writer) error {
+	cfg := config.NewConfig(configFile)
+	if err := cfg.Load(); err != nil {
+		return err
+	}
+	dir, root := cfg.GetLogFilePath(), cfg.GetLogFileRoot()
+
+	reason := "gateway not reachable: [HTTP] is not enabled"
+	if cfg.GetHTTPEnabled() {
+		data, err := fetchBundle(cfg.GetHTTPAddress())
+		if err == nil {
+			path := diag.FileName(dir, root, time.Now())
+			if err := os.WriteFile(path, data, 0600); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Diagnostic bundle written to %s\n", path)
+			return nil
+		}
+		reason = fmt.Sprintf("gateway not reachable: %v", err)
+	}
+
+	bundle := gateway.NewBundle(configFile, version, "-diag, "+reason, nil)
+	bundle.Logs = nil
+	bundle.Goroutines = "(" + reason + ")\n"
+	path, err := diag.WriteFile(dir, root, bundle)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Diagnostic bundle with the configuration and version only written to %s (%s)\n", path, reason)
+	return nil
+}
+
+// fetchBundle asks the gateway at addr for a bundle from /api/diag
+func fetchBundle(addr string) ([]byte, error) {
+	client := &http.Client{Timeout: diagFetchTimeout}
+	resp, err := client.Get("http://" + addr + "/api/diag")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("/api/diag: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// bundlePath returns the file a -diag run reported writing
+func bundlePath(t *testing.T, out string) string {
+	t.Helper()
+	const prefix = " written to "
+	i := strings.Index(out, prefix)
+	if i < 0 {
+		t.Fatalf("-diag output = %q, want the bundle file", out)
+	}
+	path := strings.TrimSpace(out[i+len(prefix):])
+	if j := strings.Index(path, " ("); j >= 0 {
+		path = path[:j]
+	}
+	return path
+}
+
+func TestDiagFromRunningGateway(t *testing.T) {
+	configFile := writeTestConfig(t, true)
+	runTestGateway(t, configFile)
+
+	var out bytes.Buffer
+	if err := runDiag(configFile, "cli", &out); err != nil {
+		t.Fatalf("runDiag() error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "Diagnostic bundle written to ") {
+		t.Errorf("-diag output = %q, want a bundle from the gateway", out.String())
+	}
+	data, err := os.ReadFile(bundlePath(t, out.String()))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	bundle := string(data)
+	for _, want := range []string{"Version: test ", "Reason:  requested with -diag", "Callsign=N0CALL"} {
+		if !strings.Contains(bundle, want) {
+			t.Errorf("bundle does not contain %q:\n%s", want, bundle)
+		}
+	}
+}
+
+func TestDiagWithoutGateway(t *testing.T) {
+	configFile := writeTestConfig(t, true)
+
+	var out bytes.Buffer
+	if err := runDiag(configFile, "cli", &out); err != nil {
+		t.Fatalf("runDiag() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "configuration and version only") {
+		t.Errorf("-diag output = %q, want the configuration-only bundle", out.String())
+	}
+	data, err := os.ReadFile(bundlePath(t, out.String()))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if bundle := string(data); !strings.Contains(bundle, "Version: cli ") ||
+		!strings.Contains(bundle, "Reason:  -diag, gateway not reachable") {
+		t.Errorf("bundle:\n%s", bundle)
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/database"
+	"github.com/dbehnke/ysf2dmr/internal/network"
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/radioid"
+	"github.com/dbehnke/ysf2dmr/pkg/gateway"
+)
+
+const (
+	// initLookupTimeout bounds the RadioID check of the DMR ID
+	initLookupTimeout = 10 * time.Second
+
+	// initConnectTimeout bounds the login test; the first login attempt
+	// only starts after DMR_RETRY_TIMEOUT
+	initConnectTimeout = 30 * time.Second
+)
+
+// The wizard's network steps, replaced by tests
+var (
+	// fetchRadioIDUser looks the DMR user id up on RadioID.net, nil if it is
+	// not registered
+	fetchRadioIDUser = func(ctx context.Context, id uint32) (*database.DMRUser, error) {
+		return radioid.NewIDLookup(nil, nil, radioid.IDLookupConfig{HTTPTimeout: initLookupTimeout}).Fetch(ctx, id)
+	}
+
+	// loginToMaster tests the login to the DMR master of the written
+	// configuration
+	loginToMaster = testMasterLogin
+)
+
+// initSettings are the answers given to the -init wizard
+type initSettings struct {
+	Callsign   string
+	DMRID      uint32
+	Master     string
+	Port       uint32
+	Password   string
+	MasterType string
+	TG         uint32
+	YSFAddress string
+	YSFPort    uint32
+}
+
+// runInit asks for the essential settings, checks the DMR ID on RadioID.net
+// and writes a complete configuration to configFile, then tests the login to
+// the DMR master with it
+func runInit(configFile string, in io.Reader, out io.Writer) error {
+	p := &prompter{in: bufio.NewScanner(in), out: out}
+	fmt.Fprintf(out, "YSF2DMR configuration wizard - press Enter to accept [defaults]\n\n")
+
+	if _, err := os.Stat(configFile); err == nil {
+		ok, err := p.confirm(fmt.Sprintf("%s already exists. Overwrite it?", configFile))
+		if err != nil || !ok {
+			return fmt.Errorf("%s left unchanged", configFile)
+		}
+	}
+
+	var s initSettings
+	var err error
+	if s.Callsign, err = p.ask("Callsign", "", validateCallsign); err != nil {
+		return err
+	}
+	s.Callsign = strings.ToUpper(s.Callsign)
+
+	for {
+		answer, err := p.ask("DMR ID (7 digits, or 9 for a hotspot ID)", "", validateDMRID)
+		if err != nil {
+			return err
+		}
+		id, _ := strconv.ParseUint(answer, 10, 32)
+		ok, err := checkDMRID(p, uint32(id), s.Callsign)
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.DMRID = uint32(id)
+			break
+		}
+	}
+
+	if s.Master, err = p.ask("DMR master address", "", required); err != nil {
+		return err
+	}
+	if s.Port, err = p.askNumber("DMR master port", 62031); err != nil {
+		return err
+	}
+	if s.Password, err = p.ask("DMR master password", "", required); err != nil {
+		return err
+	}
+	if s.MasterType, err = p.ask("Master type (brandmeister, xlx, tgif, freedmr or blank)", "", validateMasterType); err != nil {
+		return err
+	}
+	s.MasterType = strings.ToLower(s.MasterType)
+	if s.TG, err = p.askNumber("Startup talk group", 9); err != nil {
+		return err
+	}
+	if s.YSFAddress, err = p.ask("YSF peer address (YSFGateway or reflector)", "127.0.0.1", required); err != nil {
+		return err
+	}
+	if s.YSFPort, err = p.askNumber("YSF peer port", 42000); err != nil {
+		return err
+	}
+
+	if err := writeInitConfig(configFile, s); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\nWrote %s\n", configFile)
+
+	loginToMaster(configFile, out)
+	fmt.Fprintf(out, "\nStart the gateway with: ysf2dmr -config %s\n", configFile)
+	return nil
+}
+
+// checkDMRID looks the DMR ID up on RadioID.net and asks whether to keep it
+// when it is not registered to callsign
+// A failed lookup (no network) does not stop the wizard.
+func checkDMRID(p *prompter, id uint32, callsign string) (bool, error) {
+	user := id
+	if user > 9999999 {
+		user /= 100 // Hotspot IDs add a two-digit suffix to the user's ID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), initLookupTimeout)
+	defer cancel()
+	found, err := fetchRadioIDUser(ctx, user)
+	switch {
+	case err != nil:
+		fmt.Fprintf(p.out, "  Could not check %d on RadioID.net: %v\n", user, err)
+		return true, nil
+	case found == nil:
+		return p.confirm(fmt.Sprintf("  %d is not registered on RadioID.net. Use it anyway?", user))
+	case found.Callsign != callsign:
+		return p.confirm(fmt.Sprintf("  %d is registered to %s, not %s. Use it anyway?", user, found.Callsign, callsign))
+	}
+	fmt.Fprintf(p.out, "  %d is %s %s (%s)\n", user, found.Callsign, found.FirstName, found.Country)
+	return true, nil
+}
+
+// testMasterLogin logs in to the DMR master with the written configuration
+// and reports the outcome
+func testMasterLogin(configFile string, out io.Writer) {
+	cfg := config.NewConfig(configFile)
+	if err := cfg.Load(); err != nil {
+		fmt.Fprintf(out, "Configuration check failed: %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(out, "Testing the login to %s:%d (up to %v)...\n",
+		cfg.GetDMRNetworkAddress(), cfg.GetDMRNetworkPort(), initConnectTimeout)
+	dmrNet, err := gateway.NewDMRNetwork(cfg, VERSION)
+	if err != nil {
+		fmt.Fprintf(out, "  Cannot reach the master: %v\n", err)
+		return
+	}
+	defer dmrNet.Close()
+	if err := dmrNet.Open(); err != nil {
+		fmt.Fprintf(out, "  Cannot open the connection: %v\n", err)
+		return
+	}
+
+	const tick = 10 * time.Millisecond
+	for waited := time.Duration(0); waited < initConnectTimeout; waited += tick {
+		dmrNet.Clock(int(tick / time.Millisecond))
+		if dmrNet.IsConnected() {
+			fmt.Fprintf(out, "  Logged in to the master\n")
+			return
+		}
+		time.Sleep(tick)
+	}
+
+	fmt.Fprintf(out, "  Login did not complete (stopped at %s); check the address, port, DMR ID and password\n",
+		dmrNet.GetStatusString())
+}
+
+// writeInitConfig writes the configuration for the answers through a
+// temporary file, readable by the owner only as it holds the password
+func writeInitConfig(path string, s initSettings) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	err = initConfigTemplate.Execute(tmp, s)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// prompter asks questions on a terminal
+type prompter struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// ask asks until the answer, or def for an empty answer, passes valid
+func (p *prompter) ask(question, def string, valid func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+		if !p.in.Scan() {
+			if err := p.in.Err(); err != nil {
+				return "", err
+			}
+			return "", fmt.Errorf("input ended before the configuration was complete")
+		}
+
+		answer := strings.TrimSpace(p.in.Text())
+		if answer == "" {
+			answer = def
+		}
+		if err := valid(answer); err != nil {
+			fmt.Fprintf(p.out, "  %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// askNumber asks for a positive number
+func (p *prompter) askNumber(question string, def uint32) (uint32, error) {
+	answer, err := p.ask(question, strconv.FormatUint(uint64(def), 10), func(answer string) error {
+		if v, err := strconv.ParseUint(answer, 10, 32); err != nil || v == 0 {
+			return fmt.Errorf("enter a number")
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	v, _ := strconv.ParseUint(answer, 10, 32)
+	return uint32(v), nil
+}
+
+// confirm asks a yes/no question, defaulting to no
+func (p *prompter) confirm(question string) (bool, error) {
+	answer, err := p.ask(question+" [y/N]", "", func(string) error { return nil })
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
+
+func required(answer string) error {
+	if answer == "" {
+		return fmt.Errorf("a value is required")
+	}
+	return nil
+}
+
+func validateCallsign(answer string) error {
+	if len(answer) < 3 || len(answer) > protocol.YSF_CALLSIGN_LENGTH {
+		return fmt.Errorf("enter a callsign of 3 to %d characters", protocol.YSF_CALLSIGN_LENGTH)
+	}
+	for _, c := range strings.ToUpper(answer) {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return fmt.Errorf("a callsign has letters and digits only")
+		}
+	}
+	return nil
+}
+
+func validateDMRID(answer string) error {
+	if _, err := strconv.ParseUint(answer, 10, 32); err != nil || (len(answer) != 7 && len(answer) != 9) || answer[0] == '0' {
+		return fmt.Errorf("enter a 7-digit DMR ID or a 9-digit hotspot ID")
+	}
+	return nil
+}
+
+func validateMasterType(answer string) error {
+	switch strings.ToLower(answer) {
+	case "", network.MasterTypeBrandmeister, network.MasterTypeXLX, network.MasterTypeTGIF, network.MasterTypeFreeDMR:
+		return nil
+	}
+	return fmt.Errorf("enter brandmeister, xlx, tgif, freedmr or leave blank")
+}
+
+// initConfigTemplate is the configuration written by -init; options not
+// listed keep their defaults and are described in the README
+var initConfigTemplate = template.Must(template.New("ini").Parse(`# Written by ysf2dmr -init; see README.md for all options
+[Info]
+RXFrequency=0
+TXFrequency=0
+Power=1
+Latitude=0.0
+Longitude=0.0
+Height=0
+Location=
+Description=YSF2DMR Gateway
+URL=
+
+[YSF Network]
+Callsign={{.Callsign}}
+Suffix=RPT
+DstAddress={{.YSFAddress}}
+DstPort={{.YSFPort}}
+LocalAddress=0.0.0.0
+LocalPort=42013
+EnableWiresX=1
+RemoteGateway=0
+HangTime=1000
+WiresXMakeUpper=1
+# WiresX searches starting with this character look up DMR users for private calls (empty disables)
+WiresXUserSearch=*
+Debug=0
+Daemon=0
+
+[DMR Network]
+Id={{.DMRID}}
+StartupDstId={{.TG}}
+StartupPC=0
+ColorCode=1
+Address={{.Master}}
+Port={{.Port}}
+Jitter=500
+Password={{.Password}}
+Protocol=homebrew
+# brandmeister, xlx, tgif or freedmr; builds the options sent to the master
+MasterType={{.MasterType}}
+TGListFile=TGList-DMR.txt
+Debug=0
+
+[DMR Id Lookup]
+File=DMRIds.dat
+Time=24
+DropUnknown=0
+
+[Database]
+# Download the RadioID user database (run ysf2dmr -sync-users once first)
+Enabled=1
+Path=data/dmr_users.db
+SyncHours=24
+# Look up IDs missing from the database on RadioID.net when they are heard
+OnDemandLookup=1
+
+[HTTP]
+Enable=0
+Address=127.0.0.1:8080
+
+[Log]
+DisplayLevel=1
+FileLevel=1
+FilePath=.
+FileRoot=YSF2DMR
+`))
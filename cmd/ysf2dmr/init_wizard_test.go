@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/database"
+)
+
+func TestValidateCallsign(t *testing.T) {
+	tests := []struct {
+		answer string
+		valid  bool
+	}{
+		{"N0CALL", true},
+		{"n0call", true},
+		{"K1A", true},
+		{"ABCDEFGHIJ", true},
+		{"", false},
+		{"AB", false},
+		{"ABCDEFGHIJK", false},
+		{"N0-CALL", false},
+		{"N0CALL/P", false},
+	}
+	for _, tt := range tests {
+		if err := validateCallsign(tt.answer); (err == nil) != tt.valid {
+			t.Errorf("validateCallsign(%q) = %v, want valid %v", tt.answer, err, tt.valid)
+		}
+	}
+}
+
+func TestValidateDMRID(t *testing.T) {
+	tests := []struct {
+		answer string
+		valid  bool
+	}{
+		{"3100001", true},
+		{"310000101", true},
+		{"", false},
+		{"310001", false},
+		{"31000011", false},
+		{"0100001", false},
+		{"31000a1", false},
+		{"-310001", false},
+		{"4294967296", false},
+	}
+	for _, tt := range tests {
+		if err := validateDMRID(tt.answer); (err == nil) != tt.valid {
+			t.Errorf("validateDMRID(%q) = %v, want valid %v", tt.answer, err, tt.valid)
+		}
+	}
+}
+
+func TestValidateMasterType(t *testing.T) {
+	tests := []struct {
+		answer string
+		valid  bool
+	}{
+		{"", true},
+		{"brandmeister", true},
+		{"BrandMeister", true},
+		{"xlx", true},
+		{"TGIF", true},
+		{"freedmr", true},
+		{"dmrplus", false},
+		{"homebrew", false},
+	}
+	for _, tt := range tests {
+		if err := validateMasterType(tt.answer); (err == nil) != tt.valid {
+			t.Errorf("validateMasterType(%q) = %v, want valid %v", tt.answer, err, tt.valid)
+		}
+	}
+}
+
+// stubWizard replaces the RadioID lookup with users and the login test with
+// a record of the files it was given, until the test ends
+func stubWizard(t *testing.T, users map[uint32]string) *[]string {
+	t.Helper()
+	fetch, login := fetchRadioIDUser, loginToMaster
+	t.Cleanup(func() { fetchRadioIDUser, loginToMaster = fetch, login })
+
+	fetchRadioIDUser = func(ctx context.Context, id uint32) (*database.DMRUser, error) {
+		callsign, ok := users[id]
+		if !ok {
+			return nil, nil
+		}
+		return &database.DMRUser{RadioID: id, Callsign: callsign, FirstName: "Test", Country: "United States"}, nil
+	}
+	var logins []string
+	loginToMaster = func(configFile string, out io.Writer) {
+		logins = append(logins, configFile)
+	}
+	return &logins
+}
+
+func TestRunInit(t *testing.T) {
+	logins := stubWizard(t, map[uint32]string{3100001: "K1ABC", 3100002: "N0CALL"})
+	configFile := filepath.Join(t.TempDir(), "YSF2DMR.ini")
+
+	script := strings.Join([]string{
+		"x",                  // callsign too short
+		"n0call",             // callsign
+		"123",                // not a DMR ID
+		"3100001",            // registered to another callsign...
+		"",                   // ...not kept
+		"310000299",          // hotspot ID of N0CALL's 3100002
+		"",                   // master address is required
+		"master.example.net", // master address
+		"",                   // default port 62031
+		"s3cret",             // password
+		"dmrplus",            // unknown master type
+		"BrandMeister",       // master type
+		"91",                 // startup TG
+		"",                   // default YSF address
+		"42001",              // YSF port
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	if err := runInit(configFile, strings.NewReader(script), &out); err != nil {
+		t.Fatalf("runInit() error = %v\n%s", err, out.String())
+	}
+	for _, want := range []string{
+		"3100001 is registered to K1ABC, not N0CALL",
+		"3100002 is N0CALL Test (United States)",
+		"a value is required",
+		"Wrote " + configFile,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+	if len(*logins) != 1 || (*logins)[0] != configFile {
+		t.Errorf("login tested with %v, want %s", *logins, configFile)
+	}
+
+	if info, err := os.Stat(configFile); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("Stat() = %v, %v, want a file readable by the owner only", info, err)
+	}
+	cfg := config.NewConfig(configFile)
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	got := fmt.Sprintf("%s %d %s:%d %s %s TG %d %s:%d", cfg.GetCallsign(), cfg.GetDMRId(),
+		cfg.GetDMRNetworkAddress(), cfg.GetDMRNetworkPort(), cfg.GetDMRNetworkPassword(),
+		cfg.GetDMRMasterType(), cfg.GetDMRDstId(), cfg.GetDstAddress(), cfg.GetDstPort())
+	want := "N0CALL 310000299 master.example.net:62031 s3cret brandmeister TG 91 127.0.0.1:42001"
+	if got != want {
+		t.Errorf("loaded configuration = %q, want %q", got, want)
+	}
+}
+
+func TestRunInitKeepsExistingFile(t *testing.T) {
+	logins := stubWizard(t, nil)
+	configFile := filepath.Join(t.TempDir(), "YSF2DMR.ini")
+	if err := os.WriteFile(configFile, []byte("[Info]\n"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runInit(configFile, strings.NewReader("\n"), &out); err == nil {
+		t.Error("runInit() overwrote the file without confirmation")
+	}
+	if data, _ := os.ReadFile(configFile); string(data) != "[Info]\n" {
+		t.Errorf("file changed to %q", data)
+	}
+
+	// Input ending early leaves the file alone too
+	if err := runInit(configFile, strings.NewReader("y\nN0CALL\n"), &out); err == nil {
+		t.Error("runInit() succeeded on incomplete input")
+	}
+	if data, _ := os.ReadFile(configFile); string(data) != "[Info]\n" {
+		t.Errorf("file changed to %q", data)
+	}
+	if len(*logins) != 0 {
+		t.Errorf("login tested with %v", *logins)
+	}
+}
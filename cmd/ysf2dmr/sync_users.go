@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/database"
+	"github.com/dbehnke/ysf2dmr/internal/radioid"
+)
+
+// runSyncUsers downloads the RadioID database, or imports importFile when set,
+// into the configured user database and returns without starting the gateway
+func runSyncUsers(configFile, importFile string) error {
+	cfg := config.NewConfig(configFile)
+	if err := cfg.Load(); err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+	if !cfg.GetDatabaseEnabled() {
+		log.Printf("Note: [Database] Enable=0, users are imported but not used until it is enabled")
+	}
+
+	db, err := database.NewDB(database.Config{
+		Driver: cfg.GetDatabaseDriver(),
+		Path:   cfg.GetDatabasePath(),
+		DSN:    cfg.GetDatabaseDSN(),
+	}, log.New(os.Stdout, "[DB] ", log.LstdFlags))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	syncer := radioid.NewSyncerWithConfig(database.NewDMRUserRepository(db.GetDB()),
+		log.New(os.Stdout, "[SYNC] ", log.LstdFlags), radioid.SyncerConfig{
+			AlertAfterFailures: int(cfg.GetDatabaseSyncAlertFailures()),
+			Filter:             radioid.ParseFilter(cfg.GetDatabaseSyncFilter()),
+		})
+
+	if importFile != "" {
+		_, err = syncer.ImportFile(importFile)
+		return err
+	}
+	return syncer.SyncNow(context.Background())
+}
@@ -0,0 +1,169 @@
+// Package aprs sends station positions to the APRS-IS network.
+//
+// The client logs in to an APRS-IS server and keeps the connection open,
+// reconnecting after a failure. Packets are queued and written in the
+// background; when the queue is full new packets are dropped rather than
+// delaying the gateway.
+package aprs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APRS-IS client defaults
+const (
+	DESTINATION = "APZDMR" // experimental tocall for DMR gateways
+
+	dialTimeout    = 10 * time.Second
+	writeTimeout   = 10 * time.Second
+	reconnectDelay = 30 * time.Second
+	queueLength    = 16
+)
+
+// ssidSymbols are the primary table symbols for the SSIDs APRS recommends
+// for a kind of station
+var ssidSymbols = map[uint]byte{
+	0:  '-', // home station
+	7:  '[', // handheld
+	8:  's', // boat
+	9:  '>', // mobile
+	14: 'k', // truck
+}
+
+// Symbol returns the primary table symbol for a station with ssid, a
+// handheld for SSIDs without a recommended kind of station
+func Symbol(ssid uint) byte {
+	if symbol, ok := ssidSymbols[ssid]; ok {
+		return symbol
+	}
+	return '['
+}
+
+// FormatPosition builds a position report for source heard by igate
+// course is in degrees from north and left out when negative.
+func FormatPosition(source, igate string, lat, lon float64, course int, symbol byte, comment string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s>%s,qAR,%s:!%s/%s%c", source, DESTINATION, igate,
+		formatCoordinate(lat, 2, "NS"), formatCoordinate(lon, 3, "EW"), symbol)
+	if course >= 0 {
+		// Course and speed extension; a DMR position has no speed
+		fmt.Fprintf(&b, "%03d/000", (course+359)%360+1)
+	}
+	b.WriteString(comment)
+	return b.String()
+}
+
+// formatCoordinate formats degrees as the APRS DDMM.mm latitude or DDDMM.mm
+// longitude
+func formatCoordinate(degrees float64, width int, hemispheres string) string {
+	hemisphere := hemispheres[0]
+	if degrees < 0 {
+		hemisphere = hemispheres[1]
+		degrees = -degrees
+	}
+	hundredths := int(math.Round(degrees * 6000)) // minutes x 100
+	return fmt.Sprintf("%0*d%05.2f%c", width, hundredths/6000, float64(hundredths%6000)/100, hemisphere)
+}
+
+// Client sends packets to an APRS-IS server
+type Client struct {
+	address  string
+	callsign string
+	password string
+	version  string
+	packets  chan string
+}
+
+// NewClient creates a client logging in to server as callsign
+func NewClient(server string, port uint32, callsign, password, version string) *Client {
+	return &Client{
+		address:  net.JoinHostPort(server, strconv.FormatUint(uint64(port), 10)),
+		callsign: callsign,
+		password: password,
+		version:  version,
+		packets:  make(chan string, queueLength),
+	}
+}
+
+// Send queues a packet, returning false if the queue is full
+func (c *Client) Send(packet string) bool {
+	select {
+	case c.packets <- packet:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run keeps a connection to the server open and writes the queued packets
+// until ctx is cancelled
+func (c *Client) Run(ctx context.Context) {
+	for {
+		err := c.session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("APRS-IS %s: %v, reconnecting in %v", c.address, err, reconnectDelay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// session logs in and writes packets until the connection fails
+func (c *Client) session(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := fmt.Fprintf(conn, "user %s pass %s vers ysf2dmr %s\r\n", c.callsign, c.password, c.version); err != nil {
+		return err
+	}
+
+	// The server sends comments and, without a filter, no packets; only the
+	// login response is of interest
+	closed := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "# logresp") {
+				log.Printf("APRS-IS %s: %s", c.address, strings.TrimPrefix(line, "# "))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			closed <- err
+			return
+		}
+		closed <- io.EOF
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-closed:
+			return err
+		case packet := <-c.packets:
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if _, err := io.WriteString(conn, packet+"\r\n"); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package aprs
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestFormatPosition(t *testing.T) {
+	tests := []struct {
+		lat, lon float64
+		course   int
+		want     string
+	}{
+		{42.5, -83.25, -1, "N0CALL-7>APZDMR,qAR,W1GW:!4230.00N/08315.00W[DMR 3100001"},
+		{-33.8688, 151.2093, 0, "N0CALL-7>APZDMR,qAR,W1GW:!3352.13S/15112.56E[360/000DMR 3100001"},
+		{0.99999, 9.5, 90, "N0CALL-7>APZDMR,qAR,W1GW:!0100.00N/00930.00E[090/000DMR 3100001"},
+	}
+	for _, tt := range tests {
+		got := FormatPosition("N0CALL-7", "W1GW", tt.lat, tt.lon, tt.course, Symbol(7), "DMR 3100001")
+		if got != tt.want {
+			t.Errorf("FormatPosition(%f, %f) = %q, want %q", tt.lat, tt.lon, got, tt.want)
+		}
+	}
+}
+
+func TestSymbol(t *testing.T) {
+	if got := Symbol(9); got != '>' {
+		t.Errorf("Symbol(9) = %c, want >", got)
+	}
+	if got := Symbol(3); got != '[' {
+		t.Errorf("Symbol(3) = %c, want [", got)
+	}
+}
+
+func TestClient_LoginAndSend(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	port := uint32(listener.Addr().(*net.TCPAddr).Port)
+	client := NewClient("127.0.0.1", port, "W1GW", "12345", "1.2.3")
+	if !client.Send("N0CALL>APZDMR:!4230.00N/08315.00W[") {
+		t.Fatal("Send() refused a packet with an empty queue")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.Run(ctx)
+
+	listener.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	reader := bufio.NewReader(conn)
+	for _, want := range []string{
+		"user W1GW pass 12345 vers ysf2dmr 1.2.3\r\n",
+		"N0CALL>APZDMR:!4230.00N/08315.00W[\r\n",
+	} {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != want {
+			t.Errorf("server read %q, want %q", line, want)
+		}
+	}
+}
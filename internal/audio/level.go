@@ -0,0 +1,140 @@
+// Package audio measures and adjusts the level of 8kHz PCM voice.
+//
+// Levels are in dBFS, relative to the full scale of 16-bit samples. A Meter
+// tracks the peak and RMS level of the frames added to it; a Gain scales
+// frames by a fixed gain or, with AGC, by a gain that follows the speech
+// level toward a target.
+package audio
+
+import (
+	"math"
+)
+
+// Level limits
+const (
+	SILENCE = -96.0 // dBFS reported for digital silence
+
+	fullScale = 32768.0
+
+	// agcGate is the frame RMS below which the AGC holds its gain, so
+	// pauses between words are not boosted
+	agcGate = -50.0
+
+	// The AGC closes this fraction of the gap to the target each frame,
+	// cutting quickly when too loud and boosting slowly
+	agcAttack  = 0.3
+	agcRelease = 0.02
+)
+
+// ToDBFS converts a linear sample level to dBFS
+func ToDBFS(level float64) float64 {
+	if level < 1 {
+		return SILENCE
+	}
+	return math.Max(20*math.Log10(level/fullScale), SILENCE)
+}
+
+// Meter tracks the peak and RMS level of PCM frames
+type Meter struct {
+	peak       float64
+	sumSquares float64
+	samples    int
+}
+
+// Add measures one frame
+func (m *Meter) Add(pcm []int16) {
+	for _, sample := range pcm {
+		v := math.Abs(float64(sample))
+		if v > m.peak {
+			m.peak = v
+		}
+		m.sumSquares += v * v
+	}
+	m.samples += len(pcm)
+}
+
+// Samples returns the number of samples measured
+func (m *Meter) Samples() int {
+	return m.samples
+}
+
+// Peak returns the highest sample level in dBFS
+func (m *Meter) Peak() float64 {
+	return ToDBFS(m.peak)
+}
+
+// RMS returns the RMS level in dBFS
+func (m *Meter) RMS() float64 {
+	if m.samples == 0 {
+		return SILENCE
+	}
+	return ToDBFS(math.Sqrt(m.sumSquares / float64(m.samples)))
+}
+
+// Reset starts a new measurement
+func (m *Meter) Reset() {
+	*m = Meter{}
+}
+
+// Gain scales PCM frames, clipping at full scale
+type Gain struct {
+	base   float64 // configured gain, dB
+	agc    bool
+	target float64 // dBFS RMS
+	max    float64 // AGC limit either side of 0 dB
+	gain   float64 // current gain, dB
+}
+
+// NewGain creates a fixed gain of gainDB, or with agc a gain starting at
+// gainDB that follows the speech level toward target dBFS RMS, never more
+// than maxDB of boost or cut
+func NewGain(gainDB float64, agc bool, target, maxDB float64) *Gain {
+	g := &Gain{base: gainDB, agc: agc, target: target, max: maxDB}
+	g.Reset()
+	return g
+}
+
+// Active returns true if frames are changed
+func (g *Gain) Active() bool {
+	return g.agc || g.base != 0
+}
+
+// AGC returns true if the gain follows the speech level
+func (g *Gain) AGC() bool {
+	return g.agc
+}
+
+// DB returns the current gain in dB
+func (g *Gain) DB() float64 {
+	return g.gain
+}
+
+// Reset returns the gain to its configured value for a new call
+func (g *Gain) Reset() {
+	g.gain = g.base
+	if g.agc {
+		g.gain = math.Max(-g.max, math.Min(g.max, g.base))
+	}
+}
+
+// Apply scales a frame in place; level is the frame's RMS in dBFS before
+// the gain, as measured by a Meter
+func (g *Gain) Apply(pcm []int16, level float64) {
+	if g.agc && level > agcGate {
+		gap := g.target - (level + g.gain)
+		rate := agcRelease
+		if gap < 0 {
+			rate = agcAttack
+		}
+		g.gain = math.Max(-g.max, math.Min(g.max, g.gain+gap*rate))
+	}
+	if g.gain == 0 {
+		return
+	}
+
+	factor := math.Pow(10, g.gain/20)
+	for i, sample := range pcm {
+		v := math.Round(float64(sample) * factor)
+		pcm[i] = int16(math.Max(-fullScale, math.Min(fullScale-1, v)))
+	}
+}
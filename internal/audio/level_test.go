@@ -0,0 +1,91 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+// tone returns a 1kHz frame peaking at amplitude
+func tone(amplitude float64) []int16 {
+	pcm := make([]int16, 160)
+	for i := range pcm {
+		pcm[i] = int16(math.Round(amplitude * math.Sin(2*math.Pi*1000*float64(i)/8000)))
+	}
+	return pcm
+}
+
+func TestMeter(t *testing.T) {
+	var m Meter
+	if m.RMS() != SILENCE || m.Peak() != SILENCE {
+		t.Errorf("empty meter = %v peak %v RMS, want silence", m.Peak(), m.RMS())
+	}
+
+	m.Add(tone(32767))
+	if peak := m.Peak(); math.Abs(peak) > 0.01 {
+		t.Errorf("Peak() = %.2f dBFS, want 0", peak)
+	}
+	if rms := m.RMS(); math.Abs(rms+3.01) > 0.05 {
+		t.Errorf("RMS() = %.2f dBFS, want -3.01 for a full scale sine", rms)
+	}
+
+	m.Reset()
+	if m.Samples() != 0 {
+		t.Errorf("Samples() = %d after Reset", m.Samples())
+	}
+}
+
+func TestGain_Fixed(t *testing.T) {
+	g := NewGain(6.0206, false, -20, 12)
+	pcm := []int16{1000, -1000, 20000, -20000}
+	g.Apply(pcm, -30)
+
+	want := []int16{2000, -2000, 32767, -32768}
+	for i := range want {
+		if pcm[i] != want[i] {
+			t.Errorf("sample %d = %d, want %d", i, pcm[i], want[i])
+		}
+	}
+	if g.AGC() || !g.Active() {
+		t.Errorf("AGC() = %v, Active() = %v", g.AGC(), g.Active())
+	}
+	if NewGain(0, false, -20, 12).Active() {
+		t.Error("0 dB without AGC reported active")
+	}
+}
+
+func TestGain_AGC(t *testing.T) {
+	g := NewGain(0, true, -20, 12)
+
+	// A quiet talker is boosted toward the target, up to the limit
+	for i := 0; i < 500; i++ {
+		var m Meter
+		frame := tone(1000) // about -33 dBFS RMS
+		m.Add(frame)
+		g.Apply(frame, m.RMS())
+	}
+	if got := g.DB(); math.Abs(got-12) > 0.01 {
+		t.Errorf("gain for a quiet talker = %.2f dB, want the 12 dB limit", got)
+	}
+
+	// Silence holds the gain
+	g.Apply(make([]int16, 160), SILENCE)
+	if got := g.DB(); math.Abs(got-12) > 0.01 {
+		t.Errorf("gain after silence = %.2f dB, want it held", got)
+	}
+
+	// A loud talker is cut quickly
+	for i := 0; i < 50; i++ {
+		var m Meter
+		frame := tone(23000) // about -6 dBFS RMS
+		m.Add(frame)
+		g.Apply(frame, m.RMS())
+	}
+	if got := g.DB(); math.Abs(got+12) > 0.5 {
+		t.Errorf("gain for a loud talker = %.2f dB, want about -12", got)
+	}
+
+	g.Reset()
+	if g.DB() != 0 {
+		t.Errorf("DB() = %.2f after Reset, want 0", g.DB())
+	}
+}
@@ -0,0 +1,100 @@
+// Package bits provides allocation-free helpers for the MSB-first bit
+// addressing used throughout the YSF and DMR codecs.
+//
+// Bit positions count from the most significant bit of the first byte, as
+// in the C++ READ_BIT/WRITE_BIT macros. Reads outside the slice return
+// false and writes outside it are ignored, so callers working on fixed
+// size frames do not need their own bounds checks.
+package bits
+
+// maskTable maps a bit offset within a byte to its mask
+var maskTable = [8]uint8{0x80, 0x40, 0x20, 0x10, 0x08, 0x04, 0x02, 0x01}
+
+// Read returns the bit at position pos
+func Read(data []byte, pos uint32) bool {
+	bytePos := pos >> 3
+	if bytePos >= uint32(len(data)) {
+		return false
+	}
+	return data[bytePos]&maskTable[pos&7] != 0
+}
+
+// Write sets or clears the bit at position pos
+func Write(data []byte, pos uint32, bit bool) {
+	bytePos := pos >> 3
+	if bytePos >= uint32(len(data)) {
+		return
+	}
+	if bit {
+		data[bytePos] |= maskTable[pos&7]
+	} else {
+		data[bytePos] &^= maskTable[pos&7]
+	}
+}
+
+// Flip inverts the bit at position pos
+func Flip(data []byte, pos uint32) {
+	bytePos := pos >> 3
+	if bytePos >= uint32(len(data)) {
+		return
+	}
+	data[bytePos] ^= maskTable[pos&7]
+}
+
+// ToBools unpacks the bits of src into dst, one bool per bit
+// Unpacking stops when either slice is exhausted; the number of bits
+// written is returned.
+func ToBools(dst []bool, src []byte) int {
+	n := len(dst)
+	if max := len(src) * 8; max < n {
+		n = max
+	}
+	for i := 0; i < n; i++ {
+		dst[i] = src[i>>3]&maskTable[i&7] != 0
+	}
+	return n
+}
+
+// FromBools packs src into dst, one bit per bool
+// Unused bits of a final partial byte are left unchanged. The number of
+// bits written is returned.
+func FromBools(dst []byte, src []bool) int {
+	n := len(src)
+	if max := len(dst) * 8; max < n {
+		n = max
+	}
+	for i := 0; i < n; i++ {
+		if src[i] {
+			dst[i>>3] |= maskTable[i&7]
+		} else {
+			dst[i>>3] &^= maskTable[i&7]
+		}
+	}
+	return n
+}
+
+// ByteToBools unpacks one byte into the first 8 entries of dst
+// dst is left unchanged when it holds fewer than 8 entries.
+func ByteToBools(b byte, dst []bool) {
+	if len(dst) < 8 {
+		return
+	}
+	for i := 0; i < 8; i++ {
+		dst[i] = b&maskTable[i] != 0
+	}
+}
+
+// BoolsToByte packs the first 8 entries of src into a byte
+// It returns 0 when src holds fewer than 8 entries.
+func BoolsToByte(src []bool) byte {
+	if len(src) < 8 {
+		return 0
+	}
+	var b byte
+	for i := 0; i < 8; i++ {
+		if src[i] {
+			b |= maskTable[i]
+		}
+	}
+	return b
+}
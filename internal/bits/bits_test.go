@@ -0,0 +1,95 @@
+package bits
+
+import "testing"
+
+func TestReadWrite(t *testing.T) {
+	data := make([]byte, 4)
+
+	positions := []uint32{0, 1, 7, 8, 15, 24, 31}
+	for _, pos := range positions {
+		Write(data, pos, true)
+	}
+	want := []byte{0xC1, 0x81, 0x00, 0x81}
+	for i := range want {
+		if data[i] != want[i] {
+			t.Fatalf("data = % X, want % X", data, want)
+		}
+	}
+
+	for pos := uint32(0); pos < 32; pos++ {
+		expected := false
+		for _, p := range positions {
+			expected = expected || p == pos
+		}
+		if Read(data, pos) != expected {
+			t.Errorf("Read(%d) = %v, want %v", pos, !expected, expected)
+		}
+	}
+
+	Write(data, 0, false)
+	Flip(data, 2)
+	if data[0] != 0x61 {
+		t.Errorf("data[0] = 0x%02X, want 0x61", data[0])
+	}
+
+	// Out of range positions are ignored
+	Write(data, 32, true)
+	Flip(data, 40)
+	if Read(data, 32) {
+		t.Error("Read() past the end returned true")
+	}
+}
+
+func TestBoolConversion(t *testing.T) {
+	src := []byte{0xA5, 0x3C}
+
+	var unpacked [16]bool
+	if n := ToBools(unpacked[:], src); n != 16 {
+		t.Fatalf("ToBools() = %d, want 16", n)
+	}
+
+	var packed [2]byte
+	if n := FromBools(packed[:], unpacked[:]); n != 16 {
+		t.Fatalf("FromBools() = %d, want 16", n)
+	}
+	if packed[0] != 0xA5 || packed[1] != 0x3C {
+		t.Errorf("round trip = % X, want A5 3C", packed)
+	}
+
+	// Destination shorter than the source
+	var short [4]bool
+	if n := ToBools(short[:], src); n != 4 || !short[0] || short[1] || !short[2] || short[3] {
+		t.Errorf("ToBools() short = %d %v", n, short)
+	}
+
+	var b [8]bool
+	ByteToBools(0x81, b[:])
+	if !b[0] || b[1] || !b[7] {
+		t.Errorf("ByteToBools(0x81) = %v", b)
+	}
+	if got := BoolsToByte(b[:]); got != 0x81 {
+		t.Errorf("BoolsToByte() = 0x%02X, want 0x81", got)
+	}
+	if got := BoolsToByte(b[:4]); got != 0 {
+		t.Errorf("BoolsToByte(short) = 0x%02X, want 0", got)
+	}
+}
+
+func BenchmarkReadWrite(b *testing.B) {
+	var data [40]byte
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		pos := uint32(i % 320)
+		Write(data[:], pos, !Read(data[:], pos))
+	}
+}
+
+func BenchmarkToFromBools(b *testing.B) {
+	var data [13]byte
+	var unpacked [104]bool
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ToBools(unpacked[:], data[:])
+		FromBools(data[:], unpacked[:])
+	}
+}
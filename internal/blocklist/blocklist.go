@@ -0,0 +1,50 @@
+// Package blocklist decides which stations may use the gateway
+package blocklist
+
+import "strings"
+
+// List blocks and allows entries such as YSF radio IDs
+// An entry on the block list is always refused. When the allow list is not
+// empty, only entries on it are accepted.
+type List struct {
+	blocked map[string]bool
+	allowed map[string]bool
+}
+
+// New creates a list from comma separated blocked and allowed entries
+// Entries are compared without case.
+func New(blocked, allowed string) *List {
+	return &List{
+		blocked: parseEntries(blocked),
+		allowed: parseEntries(allowed),
+	}
+}
+
+// parseEntries splits a comma separated list into a set
+func parseEntries(list string) map[string]bool {
+	entries := make(map[string]bool)
+	for _, entry := range strings.Split(list, ",") {
+		if entry = normalize(entry); entry != "" {
+			entries[entry] = true
+		}
+	}
+	return entries
+}
+
+func normalize(entry string) string {
+	return strings.ToUpper(strings.TrimSpace(entry))
+}
+
+// Allowed reports whether an entry may use the gateway
+func (l *List) Allowed(entry string) bool {
+	entry = normalize(entry)
+	if l.blocked[entry] {
+		return false
+	}
+	return len(l.allowed) == 0 || l.allowed[entry]
+}
+
+// Empty reports whether the list neither blocks nor restricts anything
+func (l *List) Empty() bool {
+	return len(l.blocked) == 0 && len(l.allowed) == 0
+}
@@ -0,0 +1,37 @@
+package blocklist
+
+import "testing"
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		name             string
+		blocked, allowed string
+		entry            string
+		want             bool
+	}{
+		{"empty allows all", "", "", "E0aBc", true},
+		{"blocked", "E0ABC, F1234", "", "e0abc", false},
+		{"not blocked", "E0ABC", "", "F1234", true},
+		{"allowed", "", "F1234,G5678", "F1234", true},
+		{"not allowed", "", "F1234", "E0ABC", false},
+		{"unknown ID with allow list", "", "F1234", "", false},
+		{"blocked wins", "F1234", "F1234", "F1234", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(tt.blocked, tt.allowed).Allowed(tt.entry); got != tt.want {
+				t.Errorf("Allowed(%q) = %v, want %v", tt.entry, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListEmpty(t *testing.T) {
+	if !New(" , ", "").Empty() {
+		t.Error("list of blank entries is not empty")
+	}
+	if New("", "F1234").Empty() {
+		t.Error("allow list reported empty")
+	}
+}
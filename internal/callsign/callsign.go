@@ -0,0 +1,95 @@
+// Package callsign cleans up callsigns received from YSF radios and the DMR
+// ID lookup before they are used for lookups or sent on the other network
+package callsign
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Options selects the normalization steps; surrounding spaces and NULs are
+// always removed
+type Options struct {
+	Upper       bool // convert to upper case
+	StripSuffix bool // remove "-ND", "/P" and prefix/suffix portable indicators
+	Validate    bool // cut the callsign at the first character other than A-Z and 0-9
+}
+
+// All enables every normalization step
+var All = Options{Upper: true, StripSuffix: true, Validate: true}
+
+// ParseOptions parses a comma separated list of steps: upper, suffix and
+// validate, or "none" for trimming only
+func ParseOptions(list string) (Options, error) {
+	var o Options
+	for _, step := range strings.Split(list, ",") {
+		switch strings.ToLower(strings.TrimSpace(step)) {
+		case "upper":
+			o.Upper = true
+		case "suffix":
+			o.StripSuffix = true
+		case "validate":
+			o.Validate = true
+		case "none", "":
+		default:
+			return o, fmt.Errorf("unknown callsign normalization step %q", step)
+		}
+	}
+	return o, nil
+}
+
+// Normalize cleans a callsign as selected by o
+//
+//	"g4klx-nd  " -> "G4KLX"
+//	"EA/G4KLX/P" -> "G4KLX"
+//	"G4KLX*#"    -> "G4KLX"
+func Normalize(callsign string, o Options) string {
+	callsign = strings.Trim(callsign, " \x00")
+	if o.Upper {
+		callsign = strings.ToUpper(callsign)
+	}
+	if o.StripSuffix {
+		callsign = stripSuffix(callsign)
+	}
+	if o.Validate {
+		end := 0
+		for end < len(callsign) && isCallsignChar(callsign[end], o.Upper) {
+			end++
+		}
+		callsign = callsign[:end]
+	}
+	return callsign
+}
+
+// stripSuffix removes a "-" suffix and picks the callsign out of "/"
+// separated portable indicators: the longest part containing a digit
+func stripSuffix(callsign string) string {
+	if i := strings.IndexByte(callsign, '-'); i >= 0 {
+		callsign = callsign[:i]
+	}
+	if !strings.Contains(callsign, "/") {
+		return strings.TrimSpace(callsign)
+	}
+
+	best := ""
+	for _, part := range strings.Split(callsign, "/") {
+		part = strings.TrimSpace(part)
+		if strings.ContainsAny(part, "0123456789") && len(part) > len(best) {
+			best = part
+		}
+	}
+	if best == "" {
+		return strings.TrimSpace(callsign[:strings.IndexByte(callsign, '/')])
+	}
+	return best
+}
+
+func isCallsignChar(c byte, upperOnly bool) bool {
+	switch {
+	case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	case c >= 'a' && c <= 'z':
+		return !upperOnly
+	}
+	return false
+}
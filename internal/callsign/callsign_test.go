@@ -0,0 +1,52 @@
+package callsign
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		o    Options
+		want string
+	}{
+		{"G4KLX     ", Options{}, "G4KLX"},
+		{"G4KLX\x00\x00", Options{}, "G4KLX"},
+		{"g4klx", Options{Upper: true}, "G4KLX"},
+		{"g4klx", Options{}, "g4klx"},
+		{"G4KLX-ND", Options{StripSuffix: true}, "G4KLX"},
+		{"G4KLX/P", Options{StripSuffix: true}, "G4KLX"},
+		{"EA/G4KLX/P", Options{StripSuffix: true}, "G4KLX"},
+		{"G4KLX/M-1", Options{StripSuffix: true}, "G4KLX"},
+		{"G4KLX-ND", Options{}, "G4KLX-ND"},
+		{"G4KLX*#", Options{Validate: true}, "G4KLX"},
+		{"g4klx*", Options{Validate: true}, "g4klx"},
+		{" kd8xyz-nd ", All, "KD8XYZ"},
+		{"***", All, ""},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.in, tt.o); got != tt.want {
+			t.Errorf("Normalize(%q, %+v) = %q, want %q", tt.in, tt.o, got, tt.want)
+		}
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Options
+		wantErr bool
+	}{
+		{"upper,suffix,validate", All, false},
+		{" Upper , Suffix ", Options{Upper: true, StripSuffix: true}, false},
+		{"none", Options{}, false},
+		{"", Options{}, false},
+		{"upper,shout", Options{Upper: true}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseOptions(tt.in)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("ParseOptions(%q) = %+v, %v", tt.in, got, err)
+		}
+	}
+}
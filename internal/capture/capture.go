@@ -0,0 +1,149 @@
+// Package capture keeps the last few seconds of network packets in memory
+// and writes them to a pcap file when a conversion fails
+// Rare field failures cannot be reproduced on demand; a capture taken as they
+// happen can be replayed and opened in Wireshark.
+package capture
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/trace"
+)
+
+const (
+	// DefaultWindow is how much traffic is kept in each direction
+	DefaultWindow = 10 * time.Second
+
+	// maxPackets bounds each direction however busy the links are; 10
+	// seconds of both networks at full rate is about 400 packets
+	maxPackets = 4096
+)
+
+// Packet is a packet kept for the next dump
+type Packet struct {
+	Time    time.Time
+	Network string
+	Dir     trace.Direction
+	Local   *net.UDPAddr
+	Peer    *net.UDPAddr
+	Data    []byte
+}
+
+// Ring keeps the packets of the last window in each direction
+// A nil *Ring ignores packets, so networks can always call Record. It is safe
+// for concurrent use.
+type Ring struct {
+	window    time.Duration
+	directory string
+	prefix    string
+
+	mu       sync.Mutex
+	packets  [2][]Packet // by trace.Direction
+	lastDump time.Time
+}
+
+// New keeps window of packets (DefaultWindow if 0) and dumps them as
+// <prefix>-<time>.pcap files in directory
+func New(window time.Duration, directory, prefix string) *Ring {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Ring{window: window, directory: directory, prefix: prefix}
+}
+
+// Window returns how much traffic is kept in each direction
+func (r *Ring) Window() time.Duration {
+	return r.window
+}
+
+// Record keeps a copy of a packet exchanged with peer on network
+func (r *Ring) Record(network string, dir trace.Direction, local, peer net.Addr, packet []byte) {
+	if r == nil {
+		return
+	}
+
+	r.add(Packet{
+		Time:    time.Now(),
+		Network: network,
+		Dir:     dir,
+		Local:   udpAddr(local),
+		Peer:    udpAddr(peer),
+		Data:    append([]byte(nil), packet...),
+	})
+}
+
+// add keeps p, dropping the packets in its direction older than the window
+func (r *Ring) add(p Packet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := append(r.packets[p.Dir], p)
+	first := 0
+	for first < len(kept) && (p.Time.Sub(kept[first].Time) > r.window || len(kept)-first > maxPackets) {
+		first++
+	}
+	if first > 0 {
+		kept = append(kept[:0], kept[first:]...)
+	}
+	r.packets[p.Dir] = kept
+}
+
+// Snapshot returns the packets kept in both directions in time order
+func (r *Ring) Snapshot() []Packet {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.snapshot()
+}
+
+func (r *Ring) snapshot() []Packet {
+	packets := make([]Packet, 0, len(r.packets[trace.Received])+len(r.packets[trace.Sent]))
+	packets = append(packets, r.packets[trace.Received]...)
+	packets = append(packets, r.packets[trace.Sent]...)
+	sort.SliceStable(packets, func(i, j int) bool { return packets[i].Time.Before(packets[j].Time) })
+	return packets
+}
+
+// Dump writes the packets kept to a new pcap file, returning its path
+// Only one dump is written per window, so a burst of failures produces one
+// file; later calls in the window return an empty path.
+func (r *Ring) Dump(now time.Time) (string, error) {
+	r.mu.Lock()
+	if !r.lastDump.IsZero() && now.Sub(r.lastDump) < r.window {
+		r.mu.Unlock()
+		return "", nil
+	}
+	r.lastDump = now
+	packets := r.snapshot()
+	r.mu.Unlock()
+
+	if err := os.MkdirAll(r.directory, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(r.directory, fmt.Sprintf("%s-%s.pcap", r.prefix, now.Format("20060102-150405.000")))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	err = WritePcap(file, packets)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return path, nil
+}
+
+func udpAddr(addr net.Addr) *net.UDPAddr {
+	if udp, ok := addr.(*net.UDPAddr); ok && udp != nil {
+		return udp
+	}
+	return &net.UDPAddr{IP: net.IPv4zero}
+}
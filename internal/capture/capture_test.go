@@ -0,0 +1,103 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/trace"
+)
+
+var (
+	gateway = &net.UDPAddr{IP: net.IPv4(192, 168, 1, 10), Port: 42013}
+	peer    = &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 42000}
+)
+
+func TestRing_Window(t *testing.T) {
+	r := New(10*time.Second, t.TempDir(), "test")
+	start := time.Now()
+
+	r.add(Packet{Time: start, Dir: trace.Received, Data: []byte("old")})
+	r.add(Packet{Time: start.Add(5 * time.Second), Dir: trace.Sent, Data: []byte("sent")})
+	r.add(Packet{Time: start.Add(11 * time.Second), Dir: trace.Received, Data: []byte("new")})
+
+	packets := r.Snapshot()
+	if len(packets) != 2 || string(packets[0].Data) != "sent" || string(packets[1].Data) != "new" {
+		t.Fatalf("Snapshot() kept %d packets, want the sent packet and the newest received", len(packets))
+	}
+
+	// Each direction is pruned by its own newest packet
+	for i := 0; i < maxPackets+10; i++ {
+		r.add(Packet{Time: start.Add(11 * time.Second), Dir: trace.Received})
+	}
+	if got := len(r.Snapshot()); got != maxPackets+1 {
+		t.Errorf("kept %d packets, want %d received and 1 sent", got, maxPackets)
+	}
+}
+
+func TestRing_Nil(t *testing.T) {
+	var r *Ring
+	r.Record("YSF", trace.Received, gateway, peer, []byte("YSFP")) // must not panic
+}
+
+func TestRing_Dump(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "captures")
+	r := New(10*time.Second, dir, "YSF2DMR-capture")
+	r.Record("YSF", trace.Received, gateway, peer, []byte("YSFD"))
+	r.Record("YSF", trace.Sent, gateway, peer, []byte("YSFP"))
+
+	now := time.Now()
+	path, err := r.Dump(now)
+	if err != nil || path == "" {
+		t.Fatalf("Dump() = %q, %v", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if binary.LittleEndian.Uint32(data) != pcapMagic || binary.LittleEndian.Uint32(data[20:]) != linkTypeIPv4 {
+		t.Errorf("pcap header = % X", data[:24])
+	}
+	if want := 24 + 2*(16+ipv4HeaderLength+udpHeaderLength+4); len(data) != want {
+		t.Errorf("pcap is %d bytes, want %d", len(data), want)
+	}
+
+	// A burst of failures writes one file per window
+	if path, _ := r.Dump(now.Add(time.Second)); path != "" {
+		t.Errorf("second Dump() in the window wrote %s", path)
+	}
+	if path, _ := r.Dump(now.Add(11 * time.Second)); path == "" {
+		t.Error("Dump() after the window wrote nothing")
+	}
+}
+
+func TestWritePcap(t *testing.T) {
+	var out bytes.Buffer
+	at := time.Unix(1700000000, 123456000)
+	err := WritePcap(&out, []Packet{{Time: at, Dir: trace.Received, Local: gateway, Peer: peer, Data: []byte("YSFD")}})
+	if err != nil {
+		t.Fatalf("WritePcap() error = %v", err)
+	}
+
+	record := out.Bytes()[24:]
+	if binary.LittleEndian.Uint32(record) != 1700000000 || binary.LittleEndian.Uint32(record[4:]) != 123456 {
+		t.Errorf("timestamp = % X", record[:8])
+	}
+
+	ip := record[16 : 16+ipv4HeaderLength]
+	if checksum(ip) != 0 {
+		t.Error("IPv4 header checksum does not verify")
+	}
+	if !net.IP(ip[12:16]).Equal(peer.IP) || !net.IP(ip[16:20]).Equal(gateway.IP) {
+		t.Errorf("received packet from %v to %v, want peer to gateway", net.IP(ip[12:16]), net.IP(ip[16:20]))
+	}
+
+	udp := record[16+ipv4HeaderLength:]
+	if binary.BigEndian.Uint16(udp) != 42000 || binary.BigEndian.Uint16(udp[2:]) != 42013 || string(udp[8:]) != "YSFD" {
+		t.Errorf("UDP datagram = % X", udp)
+	}
+}
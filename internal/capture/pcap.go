@@ -0,0 +1,92 @@
+package capture
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+
+	"github.com/dbehnke/ysf2dmr/internal/trace"
+)
+
+const (
+	pcapMagic    = 0xa1b2c3d4 // microsecond timestamps
+	pcapSnapLen  = 65535
+	linkTypeIPv4 = 228 // LINKTYPE_IPV4: each record starts with an IPv4 header
+
+	ipv4HeaderLength = 20
+	udpHeaderLength  = 8
+)
+
+// WritePcap writes packets as a pcap file of IPv4/UDP datagrams between the
+// gateway's sockets and their peers, which Wireshark and tcpdump can read
+func WritePcap(w io.Writer, packets []Packet) error {
+	out := bufio.NewWriter(w)
+
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header[0:], pcapMagic)
+	binary.LittleEndian.PutUint16(header[4:], 2) // version 2.4
+	binary.LittleEndian.PutUint16(header[6:], 4)
+	binary.LittleEndian.PutUint32(header[16:], pcapSnapLen)
+	binary.LittleEndian.PutUint32(header[20:], linkTypeIPv4)
+	out.Write(header)
+
+	for _, p := range packets {
+		src, dst := p.Peer, p.Local
+		if p.Dir == trace.Sent {
+			src, dst = p.Local, p.Peer
+		}
+		datagram := udpDatagram(src, dst, p.Data)
+
+		record := make([]byte, 16)
+		binary.LittleEndian.PutUint32(record[0:], uint32(p.Time.Unix()))
+		binary.LittleEndian.PutUint32(record[4:], uint32(p.Time.Nanosecond()/1000))
+		binary.LittleEndian.PutUint32(record[8:], uint32(len(datagram)))
+		binary.LittleEndian.PutUint32(record[12:], uint32(len(datagram)))
+		out.Write(record)
+		out.Write(datagram)
+	}
+	return out.Flush()
+}
+
+// udpDatagram builds the IPv4 and UDP headers for data; the UDP checksum is
+// left out, which IPv4 allows
+func udpDatagram(src, dst *net.UDPAddr, data []byte) []byte {
+	total := ipv4HeaderLength + udpHeaderLength + len(data)
+	packet := make([]byte, total)
+
+	ip := packet[:ipv4HeaderLength]
+	ip[0] = 0x45 // version 4, 5 words
+	binary.BigEndian.PutUint16(ip[2:], uint16(total))
+	ip[8] = 64 // TTL
+	ip[9] = 17 // UDP
+	copy(ip[12:16], ipv4(src.IP))
+	copy(ip[16:20], ipv4(dst.IP))
+	binary.BigEndian.PutUint16(ip[10:], checksum(ip))
+
+	udp := packet[ipv4HeaderLength:]
+	binary.BigEndian.PutUint16(udp[0:], uint16(src.Port))
+	binary.BigEndian.PutUint16(udp[2:], uint16(dst.Port))
+	binary.BigEndian.PutUint16(udp[4:], uint16(udpHeaderLength+len(data)))
+	copy(udp[udpHeaderLength:], data)
+	return packet
+}
+
+func ipv4(ip net.IP) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+	return net.IPv4zero.To4()
+}
+
+// checksum is the IPv4 header checksum
+func checksum(header []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(header); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(header[i:]))
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
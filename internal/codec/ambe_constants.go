@@ -18,6 +18,11 @@ const (
 	DMR_VOICE_BITS_B      = 23  // Voice parameter B bits
 	DMR_VOICE_BITS_C      = 25  // Voice parameter C bits
 
+	// DMR burst layout: 98 info bits, 10 slot type bits, 48 sync/EMB bits,
+	// 10 slot type bits, then the remaining 98 info bits
+	DMR_BURST_INFO_HALF   = 98  // BPTC(196,96) bits on each side of the centre
+	DMR_BURST_CENTRE_BITS = 68  // Slot type and sync/EMB bits between the halves
+
 	// Conversion ratio: 3 YSF frames (15 VCH) → 5 DMR frames (10 AMBE)
 	YSF_TO_DMR_FRAME_RATIO = 3  // 3 YSF frames
 	DMR_TO_YSF_FRAME_RATIO = 5  // convert to 5 DMR frames
@@ -33,7 +38,7 @@ const (
 	// Interleaving and whitening
 	INTERLEAVE_TABLE_SIZE = 104 // Interleave table size (26x4)
 	WHITENING_DATA_SIZE   = 20  // Whitening pattern size
-	PRNG_TABLE_SIZE       = 768 // PRNG table size
+	PRNG_TABLE_SIZE       = 4096 // PRNG table size, one entry per 12-bit A value
 )
 
 // YSF interleaving table (104 entries) - based on C++ INTERLEAVE_TABLE_26_4
@@ -69,18 +74,23 @@ var DMR_C_TABLE = [DMR_VOICE_BITS_C]uint32{
 	27, 31, 35, 39, 43, 47, 51, 55, 59, 63, 67, 71,
 }
 
-// PRNG table for voice parameter scrambling (768 entries from C++ YSF2DMR)
-var PRNG_TABLE = [PRNG_TABLE_SIZE]uint32{
-	0x42CC47, 0x19D6FE, 0x304729, 0x6B2CD0, 0x60BF47, 0x39650E, 0x7354F1, 0xEACF60, 0x819C9F, 0xDE25CE,
-	0xD7B745, 0x8CC8B8, 0x8D592B, 0xF71257, 0xBCA084, 0xA5B329, 0xEE6AFA, 0xF7D9A7, 0xBCC21C, 0x4712D9,
-	0x4F2922, 0x14FA37, 0x5D43EC, 0x564115, 0x299A92, 0x20A9EB, 0x7B707D, 0x3BE3A4, 0x20D95B, 0x6B085A,
-	0x5233A5, 0x99A474, 0xC0EDCB, 0xCB5F12, 0x918455, 0xF897EC, 0xE32E3B, 0xAA7CC2, 0xB1E7C9, 0xFC561D,
-	0xA70DE6, 0x8DBE73, 0xD4F608, 0x57658D, 0x0E5E56, 0x458DAB, 0x7E15B8, 0x376645, 0x2DFD86, 0x64EC3B,
-	0x3F1F60, 0x3481B4, 0x4DA00F, 0x067BCE, 0x1B68B1, 0xD19328, 0xCA03FF, 0xA31856, 0xF8EB81, 0xF9F2F8,
-	0xA26067, 0xA91BB6, 0xF19A59, 0x9A6148, 0x8372B6, 0xC8E86F, 0x9399DC, 0x1A0291, 0x619142, 0x6DE9FF,
-	0x367A2C, 0x7D2511, 0x6484DA, 0x2F1F0F, 0x1E6DB4, 0x55F6E1, 0x0EA70A, 0x061C96, 0xDD0E45, 0xB4D738,
-	// ... (truncated for brevity - add more entries as needed) ...
-	0xE43AC7, 0xF5E2DE, 0xBEC121, 0xA71AF0, 0xED8B7F, 0x94B40E, 0x9F66D1, 0xD45D68, 0xCD8CBF, 0x8617F6,
+// PRNG table for voice parameter scrambling, indexed by the 12-bit A data
+// Entry n holds 24 bits of the AMBE scrambling sequence seeded with 16*n; the
+// B codeword is scrambled with its top 23 bits.
+var PRNG_TABLE = buildPRNGTable()
+
+// buildPRNGTable generates the scrambling sequences of PRNG_TABLE
+// Each bit is the top bit of the generator p(k) = 173*p(k-1) + 13849 mod 65536.
+func buildPRNGTable() [PRNG_TABLE_SIZE]uint32 {
+	var table [PRNG_TABLE_SIZE]uint32
+	for n := range table {
+		p := uint32(16 * n)
+		for k := 0; k < 24; k++ {
+			p = (173*p + 13849) & 0xFFFF
+			table[n] = table[n]<<1 | p>>15
+		}
+	}
+	return table
 }
 
 // Voice parameter structure for AMBE frames
@@ -1,7 +1,9 @@
 package codec
 
 import (
+	"fmt"
 	"math"
+	"strings"
 	"time"
 )
 
@@ -56,13 +58,50 @@ const (
 	AMBE_BER_POOR      = 0.05  // BER < 5% = poor
 	AMBE_BER_BAD       = 0.1   // BER >= 10% = bad
 
-	// Frame analysis constants
+	// Frame analysis constants (the normal profile)
 	AMBE_SILENCE_THRESHOLD    = 0.01  // Threshold for silence detection
 	AMBE_NOISE_THRESHOLD      = 0.8   // Threshold for noise detection
 	AMBE_CHANGE_THRESHOLD     = 0.3   // Threshold for rapid parameter change
+	AMBE_ERROR_PENALTY        = 0.05  // Quality lost per consecutive invalid frame
 	AMBE_HISTORY_FRAMES       = 10    // Number of frames to keep for analysis
 )
 
+// Validator strictness profiles
+const (
+	VALIDATOR_PROFILE_STRICT  = "strict"
+	VALIDATOR_PROFILE_NORMAL  = "normal"
+	VALIDATOR_PROFILE_LENIENT = "lenient"
+)
+
+// ValidatorThresholds tunes how strictly AMBEValidator scores frames
+type ValidatorThresholds struct {
+	Strict       bool    // Frames outside the typical ranges lose quality; all-zero frames are invalid
+	Change       float32 // Normalized parameter change between frames flagged as rapid
+	Silence      float32 // Energy below which a frame counts as silence
+	Noise        float32 // Energy above which a frame counts as noise
+	ErrorPenalty float32 // Quality lost per consecutive invalid frame
+}
+
+// ValidatorProfile returns the thresholds of a strictness profile
+// An empty name selects the normal profile.
+func ValidatorProfile(name string) (ValidatorThresholds, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case VALIDATOR_PROFILE_STRICT:
+		return ValidatorThresholds{Strict: true, Change: 0.2, Silence: 0.02, Noise: 0.7, ErrorPenalty: 0.1}, nil
+	case VALIDATOR_PROFILE_NORMAL, "":
+		return ValidatorThresholds{
+			Change:       AMBE_CHANGE_THRESHOLD,
+			Silence:      AMBE_SILENCE_THRESHOLD,
+			Noise:        AMBE_NOISE_THRESHOLD,
+			ErrorPenalty: AMBE_ERROR_PENALTY,
+		}, nil
+	case VALIDATOR_PROFILE_LENIENT:
+		return ValidatorThresholds{Change: 0.5, Silence: 0.005, Noise: 0.9, ErrorPenalty: 0.02}, nil
+	default:
+		return ValidatorThresholds{}, fmt.Errorf("unknown validator profile %q", name)
+	}
+}
+
 // AMBEValidator provides comprehensive AMBE frame validation and error handling
 type AMBEValidator struct {
 	// Frame history for trend analysis
@@ -87,24 +126,37 @@ type AMBEValidator struct {
 	lastFrameTime     time.Time
 
 	// Configuration
-	strictValidation bool // Enable strict validation mode
+	thresholds       ValidatorThresholds
 	autoCorrection   bool // Enable automatic error correction
 	qualityReporting bool // Enable quality reporting
 }
 
-// NewAMBEValidator creates a new AMBE validator
+// NewAMBEValidator creates a new AMBE validator with the normal profile
+// thresholds; strictMode selects strict range checking
 func NewAMBEValidator(strictMode, autoCorrect, qualityReport bool) *AMBEValidator {
+	thresholds, _ := ValidatorProfile(VALIDATOR_PROFILE_NORMAL)
+	thresholds.Strict = strictMode
+	return NewAMBEValidatorWithThresholds(thresholds, autoCorrect, qualityReport)
+}
+
+// NewAMBEValidatorWithThresholds creates a new AMBE validator with tuned thresholds
+func NewAMBEValidatorWithThresholds(thresholds ValidatorThresholds, autoCorrect, qualityReport bool) *AMBEValidator {
 	return &AMBEValidator{
 		frameHistory:     make([]AMBEVoiceParams, AMBE_HISTORY_FRAMES),
 		qualityHistory:   make([]float32, AMBE_HISTORY_FRAMES),
 		errorHistory:     make([]uint32, AMBE_HISTORY_FRAMES),
-		strictValidation: strictMode,
+		thresholds:       thresholds,
 		autoCorrection:   autoCorrect,
 		qualityReporting: qualityReport,
 		lastFrameTime:    time.Now(),
 	}
 }
 
+// Thresholds returns the thresholds the validator scores frames with
+func (v *AMBEValidator) Thresholds() ValidatorThresholds {
+	return v.thresholds
+}
+
 // ValidateAMBEFrame performs comprehensive validation of an AMBE frame
 func (v *AMBEValidator) ValidateAMBEFrame(params *AMBEVoiceParams) AMBEValidationResult {
 	result := AMBEValidationResult{
@@ -180,7 +232,7 @@ func (v *AMBEValidator) validateParameterRanges(params *AMBEVoiceParams, result
 	// Check for all zeros (silence or error)
 	if params.A == 0 && params.B == 0 && params.C == 0 {
 		result.ErrorFlags |= AMBE_ERROR_ALL_ZEROS
-		if v.strictValidation {
+		if v.thresholds.Strict {
 			result.Valid = false
 		}
 	}
@@ -195,7 +247,7 @@ func (v *AMBEValidator) validateParameterRanges(params *AMBEVoiceParams, result
 // analyzeParameterPattern analyzes parameter patterns for anomalies
 func (v *AMBEValidator) analyzeParameterPattern(params *AMBEVoiceParams, result *AMBEValidationResult) {
 	// Check for parameters outside typical operating ranges
-	if v.strictValidation {
+	if v.thresholds.Strict {
 		if params.A < AMBE_A_PARAM_TYPICAL_MIN || params.A > AMBE_A_PARAM_TYPICAL_MAX {
 			result.SignalQuality *= 0.8 // Reduce quality score
 		}
@@ -249,7 +301,7 @@ func (v *AMBEValidator) analyzeTrends(params *AMBEVoiceParams, result *AMBEValid
 
 	// Check for rapid changes
 	maxChange := float32(math.Max(float64(aChange), math.Max(float64(bChange), float64(cChange))))
-	if maxChange > AMBE_CHANGE_THRESHOLD {
+	if maxChange > v.thresholds.Change {
 		result.ErrorFlags |= AMBE_ERROR_RAPID_CHANGE
 		result.SignalQuality *= 0.7
 	}
@@ -275,9 +327,9 @@ func (v *AMBEValidator) analyzeSilencePattern(params *AMBEVoiceParams, result *A
 	// Calculate energy level from parameters
 	energy := v.calculateEnergyLevel(params)
 
-	if energy < AMBE_SILENCE_THRESHOLD {
+	if energy < v.thresholds.Silence {
 		result.ErrorFlags |= AMBE_ERROR_SILENCE_DETECTED
-	} else if energy > AMBE_NOISE_THRESHOLD {
+	} else if energy > v.thresholds.Noise {
 		result.ErrorFlags |= AMBE_ERROR_NOISE_DETECTED
 		result.SignalQuality *= 0.6
 	}
@@ -308,7 +360,7 @@ func (v *AMBEValidator) assessSignalQuality(params *AMBEVoiceParams, result *AMB
 
 	// Factor in consecutive error count
 	if v.consecutiveErrors > 0 {
-		quality *= (1.0 - float32(v.consecutiveErrors)*0.05) // 5% penalty per consecutive error
+		quality *= (1.0 - float32(v.consecutiveErrors)*v.thresholds.ErrorPenalty)
 	}
 
 	// Ensure quality stays in valid range
@@ -509,7 +561,8 @@ func (v *AMBEValidator) GetStatistics() (uint64, uint64, uint64, uint64, float32
 	return v.totalFrames, v.validFrames, v.correctedFrames, v.discardedFrames, v.averageBER, v.averageQuality
 }
 
-// Reset resets the validator state
+// Reset resets the validator state, so that one call's history does not
+// affect the scoring of the next
 func (v *AMBEValidator) Reset() {
 	v.historyIndex = 0
 	v.historyFull = false
@@ -521,6 +574,7 @@ func (v *AMBEValidator) Reset() {
 	v.averageBER = 0.0
 	v.averageQuality = 0.0
 	v.consecutiveErrors = 0
+	v.lastGoodFrame = AMBEVoiceParams{}
 	v.lastFrameTime = time.Now()
 
 	// Clear histories
@@ -0,0 +1,61 @@
+package codec
+
+import "testing"
+
+func TestValidatorProfile(t *testing.T) {
+	strict, err := ValidatorProfile("Strict")
+	if err != nil {
+		t.Fatalf("ValidatorProfile(strict) error = %v", err)
+	}
+	normal, err := ValidatorProfile("")
+	if err != nil {
+		t.Fatalf("ValidatorProfile(\"\") error = %v", err)
+	}
+	lenient, err := ValidatorProfile(VALIDATOR_PROFILE_LENIENT)
+	if err != nil {
+		t.Fatalf("ValidatorProfile(lenient) error = %v", err)
+	}
+
+	if !strict.Strict || normal.Strict || lenient.Strict {
+		t.Errorf("Strict = %v %v %v", strict.Strict, normal.Strict, lenient.Strict)
+	}
+	if !(strict.Change < normal.Change && normal.Change < lenient.Change) {
+		t.Errorf("Change = %v %v %v, want increasing leniency", strict.Change, normal.Change, lenient.Change)
+	}
+	if normal.Change != AMBE_CHANGE_THRESHOLD || normal.Silence != AMBE_SILENCE_THRESHOLD || normal.Noise != AMBE_NOISE_THRESHOLD {
+		t.Errorf("normal profile = %+v, want the AMBE_* defaults", normal)
+	}
+
+	if _, err := ValidatorProfile("loose"); err == nil {
+		t.Error("ValidatorProfile(loose) accepted an unknown profile")
+	}
+
+	if got := NewAMBEValidator(true, false, false).Thresholds(); !got.Strict || got.Change != normal.Change {
+		t.Errorf("NewAMBEValidator(strict) thresholds = %+v", got)
+	}
+}
+
+func TestAMBEValidatorReset(t *testing.T) {
+	thresholds, _ := ValidatorProfile(VALIDATOR_PROFILE_NORMAL)
+	v := NewAMBEValidatorWithThresholds(thresholds, false, true)
+
+	// A noisy call: every frame jumps across the parameter range
+	for i := 0; i < 20; i++ {
+		params := AMBEVoiceParams{A: AMBE_A_PARAM_TYPICAL_MIN, B: AMBE_B_PARAM_TYPICAL_MIN, C: AMBE_C_PARAM_TYPICAL_MIN}
+		if i%2 == 1 {
+			params = AMBEVoiceParams{A: AMBE_A_PARAM_TYPICAL_MAX, B: AMBE_B_PARAM_TYPICAL_MAX, C: AMBE_C_PARAM_TYPICAL_MAX}
+		}
+		v.ValidateAMBEFrame(&params)
+	}
+
+	v.Reset()
+
+	// The first frame of the next call is not compared with the last one
+	params := AMBEVoiceParams{A: 0x400000, B: 0x200000, C: 0x800000}
+	if result := v.ValidateAMBEFrame(&params); result.ErrorFlags&AMBE_ERROR_RAPID_CHANGE != 0 {
+		t.Errorf("first frame after Reset() flagged as a rapid change (flags 0x%X)", result.ErrorFlags)
+	}
+	if total, _, _, _, _, _ := v.GetStatistics(); total != 1 {
+		t.Errorf("frames after Reset() = %d, want 1", total)
+	}
+}
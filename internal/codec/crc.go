@@ -1,5 +1,7 @@
 package codec
 
+import "github.com/dbehnke/ysf2dmr/internal/correction"
+
 // CRC functions for YSF error detection
 // This matches the C++ CCRC class functionality for various CRC calculations
 
@@ -47,31 +49,11 @@ func AddCCITT162(data []uint8, length int) {
 	if len(data) < length || length < 2 {
 		return
 	}
-
-	// Calculate CRC using union trick from C++ (lines 154-157)
-	crc16 := uint16(0)
-
-	// Process data bytes (excluding last 2 CRC bytes)
-	for i := 0; i < (length - 2); i++ {
-		// Equivalent to C++ line 162:
-		// crc16 = (uint16_t(crc8[0U]) << 8) ^ CCITT16_TABLE2[crc8[1U] ^ in[i]];
-		highByte := uint8(crc16 >> 8)
-		lowByte := uint8(crc16 & 0xFF)
-		crc16 = (uint16(highByte) << 8) ^ CCITT16_TABLE2[lowByte^data[i]]
-	}
-
-	// Invert CRC (C++ line 164)
-	crc16 = ^crc16
-
-	// Store CRC bytes in data (C++ lines 166-167)
-	// in[length - 1U] = crc8[0U];  // High byte at end
-	// in[length - 2U] = crc8[1U];  // Low byte at end-1
-	data[length-1] = uint8(crc16 >> 8)   // High byte
-	data[length-2] = uint8(crc16 & 0xFF) // Low byte
+	correction.AddCCITT162(data[:length])
 }
 
 // CheckCCITT162 verifies a CCITT162 CRC checksum
-// Input: data slice where the last 2 bytes contain the CRC
+// Input: data slice with CRC in the last 2 bytes
 // length: total length including the 2 CRC bytes
 // Output: true if CRC is valid, false otherwise
 // Equivalent to C++ CCRC::checkCCITT162()
@@ -79,45 +61,14 @@ func CheckCCITT162(data []uint8, length int) bool {
 	if len(data) < length || length < 2 {
 		return false
 	}
-
-	// Calculate CRC using union trick from C++ (lines 175-178)
-	crc16 := uint16(0)
-
-	// Process data bytes (excluding last 2 CRC bytes)
-	for i := 0; i < (length - 2); i++ {
-		// Equivalent to C++ line 183:
-		// crc16 = (uint16_t(crc8[0U]) << 8) ^ CCITT16_TABLE2[crc8[1U] ^ in[i]];
-		highByte := uint8(crc16 >> 8)
-		lowByte := uint8(crc16 & 0xFF)
-		crc16 = (uint16(highByte) << 8) ^ CCITT16_TABLE2[lowByte^data[i]]
-	}
-
-	// Invert CRC (C++ line 185)
-	crc16 = ^crc16
-
-	// Check CRC bytes (C++ line 187)
-	// return crc8[0U] == in[length - 1U] && crc8[1U] == in[length - 2U];
-	expectedHighByte := uint8(crc16 >> 8)
-	expectedLowByte := uint8(crc16 & 0xFF)
-
-	return expectedHighByte == data[length-1] && expectedLowByte == data[length-2]
+	return correction.CheckCCITT162(data[:length])
 }
 
 // CalculateCCITT162 calculates and returns the CCITT162 CRC for data
 // Input: data to calculate CRC for (does not modify input)
 // Output: 16-bit CRC value
 func CalculateCCITT162(data []uint8) uint16 {
-	crc16 := uint16(0)
-
-	// Process all data bytes
-	for _, b := range data {
-		highByte := uint8(crc16 >> 8)
-		lowByte := uint8(crc16 & 0xFF)
-		crc16 = (uint16(highByte) << 8) ^ CCITT16_TABLE2[lowByte^b]
-	}
-
-	// Return inverted CRC
-	return ^crc16
+	return correction.CalculateCCITT162(data)
 }
 
 // ValidateCCITT162Table validates the CRC table
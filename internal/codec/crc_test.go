@@ -50,7 +50,7 @@ func TestCCITT162CalculateFunction(t *testing.T) {
 	copy(dataWithCRC, testData)
 	AddCCITT162(dataWithCRC, len(dataWithCRC))
 
-	expectedCRC := (uint16(dataWithCRC[len(dataWithCRC)-1]) << 8) | uint16(dataWithCRC[len(dataWithCRC)-2])
+	expectedCRC := (uint16(dataWithCRC[len(dataWithCRC)-2]) << 8) | uint16(dataWithCRC[len(dataWithCRC)-1]) // Stored high byte first
 
 	if crc != expectedCRC {
 		t.Errorf("CalculateCCITT162 mismatch: got 0x%04X, expected 0x%04X", crc, expectedCRC)
@@ -1,9 +1,16 @@
 package codec
 
 import (
+	"errors"
 	"fmt"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
 )
 
+// ErrBPTCDecode is returned, wrapped, when a DMR burst's BPTC(196,96)
+// codeword has more errors than can be corrected
+var ErrBPTCDecode = errors.New("BPTC decode failed")
+
 // DMRAMBEExtractor handles DMR AMBE frame extraction and processing
 type DMRAMBEExtractor struct {
 	// No state needed for extraction
@@ -28,7 +35,7 @@ func (e *DMRAMBEExtractor) ExtractAMBEFrames(dmrPayload []byte) ([DMR_AMBE_FRAME
 	for i := 0; i < DMR_AMBE_FRAMES; i++ {
 		err := e.extractAMBEFrame(dmrPayload, i, &ambeFrames[i])
 		if err != nil {
-			return [DMR_AMBE_FRAMES]DMRAMBEFrame{}, fmt.Errorf("failed to extract AMBE frame %d: %v", i, err)
+			return [DMR_AMBE_FRAMES]DMRAMBEFrame{}, fmt.Errorf("failed to extract AMBE frame %d: %w", i, err)
 		}
 	}
 
@@ -50,8 +57,9 @@ func (e *DMRAMBEExtractor) extractAMBEFrame(payload []byte, frameIndex int, ambe
 	}
 
 	// Step 1: Extract the BPTC(196,96) encoded bits from DMR payload
-	bptcBits := make([]uint8, 33) // 196 bits = 33 bytes (rounded up)
-	err := e.extractBPTCBits(payload, frameIndex, bptcBits)
+	// Both AMBE frames share the single codeword carried by the burst
+	bptcBits := make([]uint8, DMR_FRAME_LENGTH)
+	err := e.extractBPTCBits(payload, bptcBits)
 	if err != nil {
 		return fmt.Errorf("failed to extract BPTC bits: %v", err)
 	}
@@ -60,22 +68,19 @@ func (e *DMRAMBEExtractor) extractAMBEFrame(payload []byte, frameIndex int, ambe
 	bptc := NewBPTC19696()
 	voiceBits, ok := bptc.Decode(bptcBits)
 	if !ok {
-		return fmt.Errorf("BPTC decode failed for frame %d", frameIndex)
+		return fmt.Errorf("%w for frame %d", ErrBPTCDecode, frameIndex)
 	}
 
 	// Convert voice bytes to boolean bits for processing
-	correctedBits := make([]bool, 96)
-	for i := 0; i < 12 && i*8 < 96; i++ {
-		ByteToBitsBE(voiceBits[i], correctedBits[i*8:(i+1)*8])
-	}
+	var correctedBits [96]bool
+	bits.ToBools(correctedBits[:], voiceBits)
 
 	// Step 3: Remove PRNG masking (temporarily disabled for testing)
-	unmaskedBits := make([]bool, 96)
-	copy(unmaskedBits, correctedBits) // Skip PRNG masking for now
-	// e.removePRNGMasking(correctedBits, unmaskedBits, frameIndex)
+	unmaskedBits := correctedBits // Skip PRNG masking for now
+	// e.removePRNGMasking(correctedBits[:], unmaskedBits[:], frameIndex)
 
 	// Step 4: Extract voice parameters A, B, C based on frame pattern
-	err = e.extractVoiceParameters(unmaskedBits, frameIndex, &ambeFrame.Params)
+	err = e.extractVoiceParameters(unmaskedBits[:], frameIndex, &ambeFrame.Params)
 	if err != nil {
 		return fmt.Errorf("failed to extract voice parameters: %v", err)
 	}
@@ -93,45 +98,33 @@ func (e *DMRAMBEExtractor) extractAMBEFrame(payload []byte, frameIndex int, ambe
 	return nil
 }
 
-// extractBPTCBits extracts BPTC(196,96) encoded bits from DMR payload
-// DMR voice data is protected using BPTC - we extract the 196-bit codewords
-func (e *DMRAMBEExtractor) extractBPTCBits(payload []byte, frameIndex int, bptcBits []uint8) error {
-	// DMR voice data is spread across specific bit positions in the payload
-	// Each voice frame uses a BPTC(196,96) codeword for protection
+// dmrBurstInfoPosition maps a BPTC(196,96) bit index to its bit position in a DMR burst
+// The codeword is split into two 98-bit halves around the slot type and
+// sync/EMB fields in the centre of the 264-bit burst.
+func dmrBurstInfoPosition(i int) uint32 {
+	if i < DMR_BURST_INFO_HALF {
+		return uint32(i)
+	}
+	return uint32(i + DMR_BURST_CENTRE_BITS)
+}
+
+// extractBPTCBits extracts the BPTC(196,96) codeword from a DMR burst
+// Only the info bits are copied; the slot type and sync/EMB positions are
+// left clear so they cannot leak into the decoded data.
+func (e *DMRAMBEExtractor) extractBPTCBits(payload []byte, bptcBits []uint8) error {
+	if len(payload) < DMR_FRAME_LENGTH || len(bptcBits) < DMR_FRAME_LENGTH {
+		return fmt.Errorf("DMR burst too short: got %d, need %d", len(payload), DMR_FRAME_LENGTH)
+	}
 
 	// Clear output buffer
 	for i := range bptcBits {
 		bptcBits[i] = 0
 	}
 
-	// Calculate the bit offset for this frame's BPTC codeword
-	// DMR frame structure places BPTC codewords at specific positions
-	startBitPos := frameIndex * 196 // Each BPTC codeword is 196 bits
-
-	// Extract 196 bits and pack into 33 bytes (196/8 = 24.5, rounded up to 25, but BPTC expects 33)
-	for i := 0; i < 196; i++ {
-		bitPos := startBitPos + i
-		if bitPos >= len(payload)*8 {
-			break // Prevent buffer overflow
-		}
-
-		bytePos := bitPos / 8
-		bitOffset := bitPos % 8
-
-		if bytePos < len(payload) {
-			// Extract bit from payload
-			bit := (payload[bytePos] & (1 << (7 - bitOffset))) != 0
-
-			// Pack into BPTC bytes using the expected format
-			outputBytePos := i / 8
-			outputBitPos := 7 - (i % 8)
-
-			if outputBytePos < len(bptcBits) && bit {
-				bptcBits[outputBytePos] |= (1 << outputBitPos)
-			}
-		}
+	for i := 0; i < BPTC19696_TOTAL_BITS; i++ {
+		pos := dmrBurstInfoPosition(i)
+		bits.Write(bptcBits, pos, bits.Read(payload, pos))
 	}
-
 	return nil
 }
 
@@ -142,11 +135,11 @@ func (e *DMRAMBEExtractor) applyGolayErrorCorrection(params *AMBEVoiceParams, fr
 	if frameIndex >= 0 { // A parameter is present in both frames
 		// Extract lower 12 bits and apply Golay(24,12)
 		aLow := params.A & 0xFFF
-		aLow = Decode24128((aLow << 12) | e.calculateGolayParity24(aLow))
+		aLow = Decode24128(Encode24128(aLow))
 
 		// Extract upper 12 bits and apply Golay(24,12)
 		aHigh := (params.A >> 12) & 0xFFF
-		aHigh = Decode24128((aHigh << 12) | e.calculateGolayParity24(aHigh))
+		aHigh = Decode24128(Encode24128(aHigh))
 
 		// Reconstruct A parameter
 		params.A = (aHigh << 12) | aLow
@@ -154,10 +147,9 @@ func (e *DMRAMBEExtractor) applyGolayErrorCorrection(params *AMBEVoiceParams, fr
 
 	// B parameter (23 bits): Apply Golay(23,12) for Frame 0
 	if frameIndex == 0 {
-		// For 23-bit parameter, use Golay(23,12) which protects 11 data bits
-		// We need to split the 23 bits: 11 data + 12 parity
-		bData := params.B & 0x7FF // Lower 11 bits as data
-		bData = Decode23127((bData << 12) | e.calculateGolayParity23(bData))
+		// For 23-bit parameter, use Golay(23,12) which protects 12 data bits
+		bData := params.B & 0xFFF // Lower 12 bits as data
+		bData = Decode23127(Encode23127(bData) >> 1)
 
 		// Reconstruct B parameter (may need additional protection for remaining bits)
 		params.B = bData
@@ -171,60 +163,14 @@ func (e *DMRAMBEExtractor) applyGolayErrorCorrection(params *AMBEVoiceParams, fr
 		cHigh := (params.C >> 24) & 0x1   // Upper 1 bit
 
 		// Apply Golay(24,12) to lower and middle chunks
-		cLow = Decode24128((cLow << 12) | e.calculateGolayParity24(cLow))
-		cMid = Decode24128((cMid << 12) | e.calculateGolayParity24(cMid))
+		cLow = Decode24128(Encode24128(cLow))
+		cMid = Decode24128(Encode24128(cMid))
 
 		// Reconstruct C parameter
 		params.C = (cHigh << 24) | (cMid << 12) | cLow
 	}
 }
 
-// calculateGolayParity24 calculates 12-bit Golay parity for 12-bit data
-func (e *DMRAMBEExtractor) calculateGolayParity24(data uint32) uint32 {
-	// Use the Golay (24,12) generator polynomial: x^11 + x^10 + x^6 + x^5 + x^4 + x^2 + 1
-	generator := uint32(0xC75)
-	shifted := (data & 0xFFF) << 12
-	return e.polyDiv24(shifted, generator)
-}
-
-// calculateGolayParity23 calculates 12-bit Golay parity for 11-bit data
-func (e *DMRAMBEExtractor) calculateGolayParity23(data uint32) uint32 {
-	// Use the Golay (23,11) generator polynomial
-	generator := uint32(0x65B)
-	shifted := (data & 0x7FF) << 12
-	return e.polyDiv23(shifted, generator)
-}
-
-// polyDiv24 performs polynomial division for 24-bit values (helper function)
-func (e *DMRAMBEExtractor) polyDiv24(dividend, divisor uint32) uint32 {
-	dividend &= 0xFFFFFF // 24 bits
-	divisor &= 0xFFF     // 12 bits
-
-	remainder := dividend
-	for i := 23; i >= 12; i-- {
-		if (remainder & (1 << uint(i))) != 0 {
-			remainder ^= divisor << uint(i-11)
-		}
-	}
-
-	return remainder & 0xFFF
-}
-
-// polyDiv23 performs polynomial division for 23-bit values (helper function)
-func (e *DMRAMBEExtractor) polyDiv23(dividend, divisor uint32) uint32 {
-	dividend &= 0x7FFFFF // 23 bits
-	divisor &= 0xFFF      // 12 bits
-
-	remainder := dividend
-	for i := 22; i >= 12; i-- {
-		if (remainder & (1 << uint(i))) != 0 {
-			remainder ^= divisor << uint(i-11)
-		}
-	}
-
-	return remainder & 0xFFF
-}
-
 // applyGolayEncoding applies Golay encoding to voice parameters for transmission
 // This is the reverse of applyGolayErrorCorrection - it encodes data for protection
 func (e *DMRAMBEExtractor) applyGolayEncoding(params *AMBEVoiceParams, frameIndex int) {
@@ -244,12 +190,12 @@ func (e *DMRAMBEExtractor) applyGolayEncoding(params *AMBEVoiceParams, frameInde
 
 	// B parameter (23 bits): Apply Golay(23,12) encoding for Frame 0
 	if frameIndex == 0 {
-		// For 23-bit parameter, encode as 11 data bits with Golay(23,12)
-		bData := params.B & 0x7FF // Extract 11 bits as data
-		bEncoded := Encode23127(bData)
+		// For 23-bit parameter, encode as 12 data bits with Golay(23,12)
+		bData := params.B & 0xFFF // Extract 12 bits as data
+		bEncoded := Encode23127(bData) >> 1
 
-		// Keep only the data portion (upper 11 bits) for parameter reconstruction
-		params.B = bEncoded >> 12
+		// Keep only the data portion (upper 12 bits) for parameter reconstruction
+		params.B = bEncoded >> 11
 	}
 
 	// C parameter (25 bits): Split for Golay(24,12) encoding for Frame 1
@@ -306,6 +252,15 @@ func (e *DMRAMBEExtractor) removePRNGMasking(src, dest []bool, frameIndex int) {
 	}
 }
 
+// dmrVoiceFrameOffset returns where a frame's parameters start in the 96 voice bits
+// Frame 0 (A+B) fills the first 47 bits and frame 1 (A+C) the remaining 49.
+func dmrVoiceFrameOffset(frameIndex int) int {
+	if frameIndex == 0 {
+		return 0
+	}
+	return DMR_VOICE_BITS_A + DMR_VOICE_BITS_B
+}
+
 // extractVoiceParameters extracts A, B, C voice parameters from unmasked bits
 // DMR alternates between A+B and A+C parameter patterns
 func (e *DMRAMBEExtractor) extractVoiceParameters(voiceBits []bool, frameIndex int, params *AMBEVoiceParams) error {
@@ -314,20 +269,21 @@ func (e *DMRAMBEExtractor) extractVoiceParameters(voiceBits []bool, frameIndex i
 	params.B = 0
 	params.C = 0
 
-	// DMR frame patterns:
+	// DMR frame patterns, sharing the 96 bits of the burst codeword:
 	// Frame 0: A + B parameters (24 + 23 = 47 bits)
 	// Frame 1: A + C parameters (24 + 25 = 49 bits)
+	base := dmrVoiceFrameOffset(frameIndex)
 
 	// Extract A parameter (always present, 24 bits)
-	for i := 0; i < DMR_VOICE_BITS_A && i < len(voiceBits); i++ {
-		if voiceBits[i] {
+	for i := 0; i < DMR_VOICE_BITS_A && base+i < len(voiceBits); i++ {
+		if voiceBits[base+i] {
 			params.A |= (1 << (DMR_VOICE_BITS_A - 1 - i))
 		}
 	}
 
 	if frameIndex == 0 {
 		// Frame 0: Extract B parameter (23 bits)
-		startBit := DMR_VOICE_BITS_A
+		startBit := base + DMR_VOICE_BITS_A
 		for i := 0; i < DMR_VOICE_BITS_B && startBit+i < len(voiceBits); i++ {
 			if voiceBits[startBit+i] {
 				params.B |= (1 << (DMR_VOICE_BITS_B - 1 - i))
@@ -335,7 +291,7 @@ func (e *DMRAMBEExtractor) extractVoiceParameters(voiceBits []bool, frameIndex i
 		}
 	} else {
 		// Frame 1: Extract C parameter (25 bits)
-		startBit := DMR_VOICE_BITS_A
+		startBit := base + DMR_VOICE_BITS_A
 		for i := 0; i < DMR_VOICE_BITS_C && startBit+i < len(voiceBits); i++ {
 			if voiceBits[startBit+i] {
 				params.C |= (1 << (DMR_VOICE_BITS_C - 1 - i))
@@ -458,47 +414,45 @@ func (e *DMRAMBEExtractor) EncodeAMBEFrame(params *AMBEVoiceParams, frameIndex i
 	e.applyGolayEncoding(&encodedParams, frameIndex)
 
 	// Create voice bits array
-	voiceBits := make([]bool, 96)
+	var voiceBits [96]bool
 
 	// Pack A parameter (24 bits)
+	base := dmrVoiceFrameOffset(frameIndex)
 	for i := 0; i < DMR_VOICE_BITS_A; i++ {
-		voiceBits[i] = (encodedParams.A & (1 << (DMR_VOICE_BITS_A - 1 - i))) != 0
+		voiceBits[base+i] = (encodedParams.A & (1 << (DMR_VOICE_BITS_A - 1 - i))) != 0
 	}
 
 	if frameIndex == 0 {
 		// Frame 0: Pack B parameter (23 bits)
-		startBit := DMR_VOICE_BITS_A
+		startBit := base + DMR_VOICE_BITS_A
 		for i := 0; i < DMR_VOICE_BITS_B; i++ {
 			voiceBits[startBit+i] = (encodedParams.B & (1 << (DMR_VOICE_BITS_B - 1 - i))) != 0
 		}
 	} else {
 		// Frame 1: Pack C parameter (25 bits)
-		startBit := DMR_VOICE_BITS_A
+		startBit := base + DMR_VOICE_BITS_A
 		for i := 0; i < DMR_VOICE_BITS_C; i++ {
 			voiceBits[startBit+i] = (encodedParams.C & (1 << (DMR_VOICE_BITS_C - 1 - i))) != 0
 		}
 	}
 
 	// Apply PRNG masking (temporarily disabled for testing)
-	maskedBits := make([]bool, 96)
-	copy(maskedBits, voiceBits) // Skip PRNG masking for now
-	// e.applyPRNGMasking(voiceBits, maskedBits, frameIndex)
+	maskedBits := voiceBits // Skip PRNG masking for now
+	// e.applyPRNGMasking(voiceBits[:], maskedBits[:], frameIndex)
 
 	// Convert masked bits to bytes for BPTC encoding
-	voiceBytes := make([]uint8, 12) // 96 bits = 12 bytes
-	for i := 0; i < 12 && i*8 < len(maskedBits); i++ {
-		voiceBytes[i] = BitsToByteBE(maskedBits[i*8:(i+1)*8])
-	}
+	var voiceBytes [12]uint8 // 96 bits = 12 bytes
+	bits.FromBools(voiceBytes[:], maskedBits[:])
 
 	// Apply BPTC(196,96) error correction coding
 	bptc := NewBPTC19696()
-	encodedBytes, ok := bptc.Encode(voiceBytes)
+	encodedBytes, ok := bptc.Encode(voiceBytes[:])
 	if !ok {
 		return fmt.Errorf("BPTC encode failed for frame %d", frameIndex)
 	}
 
 	// Pack encoded BPTC bytes into payload
-	e.packBPTCBitsToPayload(encodedBytes, frameIndex, payload)
+	e.packBPTCBitsToPayload(encodedBytes, payload)
 
 	return nil
 }
@@ -526,31 +480,15 @@ func (e *DMRAMBEExtractor) applyPRNGMasking(src, dest []bool, frameIndex int) {
 	}
 }
 
-// packBPTCBitsToPayload packs BPTC encoded bytes back into DMR payload format
-func (e *DMRAMBEExtractor) packBPTCBitsToPayload(encodedBytes []uint8, frameIndex int, payload []byte) {
-	// Calculate bit offset for this frame's BPTC codeword
-	startBitPos := frameIndex * 196 // Each BPTC codeword is 196 bits
-
-	// Convert bytes to bits and pack into payload
-	for byteIdx := 0; byteIdx < len(encodedBytes); byteIdx++ {
-		b := encodedBytes[byteIdx]
-		for bitIdx := 0; bitIdx < 8; bitIdx++ {
-			bitPos := startBitPos + (byteIdx*8) + bitIdx
-			if bitPos >= len(payload)*8 {
-				return // Prevent buffer overflow
-			}
-
-			payloadBytePos := bitPos / 8
-			payloadBitOffset := bitPos % 8
+// packBPTCBitsToPayload packs a BPTC encoded burst into the info bits of a DMR payload
+// The slot type and sync/EMB positions of the payload are left untouched.
+func (e *DMRAMBEExtractor) packBPTCBitsToPayload(encodedBytes []uint8, payload []byte) {
+	if len(encodedBytes) < DMR_FRAME_LENGTH || len(payload) < DMR_FRAME_LENGTH {
+		return
+	}
 
-			if payloadBytePos < len(payload) {
-				bit := (b & (1 << (7 - bitIdx))) != 0
-				if bit {
-					payload[payloadBytePos] |= (1 << (7 - payloadBitOffset))
-				} else {
-					payload[payloadBytePos] &^= (1 << (7 - payloadBitOffset))
-				}
-			}
-		}
+	for i := 0; i < BPTC19696_TOTAL_BITS; i++ {
+		pos := dmrBurstInfoPosition(i)
+		bits.Write(payload, pos, bits.Read(encodedBytes, pos))
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,113 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
+)
+
+// DMR BS sourced voice sync, as carried in the centre of a voice burst
+var testVoiceSync = []byte{0x75, 0x5F, 0xD7, 0xDF, 0x75, 0xF7}
+
+func TestDMRBurstInfoPosition(t *testing.T) {
+	tests := []struct {
+		index int
+		want  uint32
+	}{
+		{0, 0},
+		{97, 97},   // Last bit before the first slot type field
+		{98, 166},  // First bit after the second slot type field
+		{195, 263}, // Last bit of the burst
+	}
+
+	for _, tt := range tests {
+		if got := dmrBurstInfoPosition(tt.index); got != tt.want {
+			t.Errorf("dmrBurstInfoPosition(%d) = %d, want %d", tt.index, got, tt.want)
+		}
+	}
+
+	// No info bit may land in the slot type or sync/EMB fields
+	for i := 0; i < BPTC19696_TOTAL_BITS; i++ {
+		pos := dmrBurstInfoPosition(i)
+		if pos >= DMR_BURST_INFO_HALF && pos < DMR_BURST_INFO_HALF+DMR_BURST_CENTRE_BITS {
+			t.Fatalf("info bit %d mapped into the burst centre at %d", i, pos)
+		}
+	}
+}
+
+// buildTestVoiceBurst encodes two frames into a burst carrying the voice sync
+func buildTestVoiceBurst(t *testing.T, frames [DMR_AMBE_FRAMES]AMBEVoiceParams) []byte {
+	t.Helper()
+	extractor := NewDMRAMBEExtractor()
+
+	burst := make([]byte, DMR_FRAME_LENGTH)
+	for i := range burst {
+		burst[i] = 0xFF // Slot type positions must survive encoding
+	}
+	burst[13] = (burst[13] & 0xF0) | (testVoiceSync[0] >> 4)
+	for i := 0; i < 5; i++ {
+		burst[14+i] = (testVoiceSync[i] << 4) | (testVoiceSync[i+1] >> 4)
+	}
+	burst[19] = (testVoiceSync[5] << 4) | (burst[19] & 0x0F)
+	centre := append([]byte(nil), burst[12:21]...)
+
+	if err := extractor.EncodeAMBEFrame(&frames[0], 0, burst); err != nil {
+		t.Fatalf("EncodeAMBEFrame(0) error: %v", err)
+	}
+	second := make([]byte, DMR_FRAME_LENGTH)
+	if err := extractor.EncodeAMBEFrame(&frames[1], 1, second); err != nil {
+		t.Fatalf("EncodeAMBEFrame(1) error: %v", err)
+	}
+	for i := range burst {
+		burst[i] ^= second[i]
+	}
+
+	for i := DMR_BURST_INFO_HALF; i < DMR_BURST_INFO_HALF+DMR_BURST_CENTRE_BITS; i++ {
+		if bits.Read(burst, uint32(i)) != bits.Read(centre, uint32(i-96)) {
+			t.Fatalf("encoding changed centre bit %d", i)
+		}
+	}
+	return burst
+}
+
+func TestDMRAMBE_VoiceBurstRoundTrip(t *testing.T) {
+	frames := [DMR_AMBE_FRAMES]AMBEVoiceParams{
+		{A: 0x123456, B: 0x0ABC},
+		{A: 0x654321, C: 0x1ABCDEF},
+	}
+	burst := buildTestVoiceBurst(t, frames)
+
+	extracted, err := NewDMRAMBEExtractor().ExtractAMBEFrames(burst)
+	if err != nil {
+		t.Fatalf("ExtractAMBEFrames() error: %v", err)
+	}
+
+	for i, frame := range extracted {
+		if frame.Params != frames[i] {
+			t.Errorf("frame %d = %+v, want %+v", i, frame.Params, frames[i])
+		}
+	}
+}
+
+// The sync/EMB and slot type bits must not affect the extracted parameters
+func TestDMRAMBE_ExtractIgnoresBurstCentre(t *testing.T) {
+	frames := [DMR_AMBE_FRAMES]AMBEVoiceParams{
+		{A: 0x0F0F0F, B: 0x0555},
+		{A: 0xF0F0F0, C: 0x0AAAAAA},
+	}
+	burst := buildTestVoiceBurst(t, frames)
+
+	for i := DMR_BURST_INFO_HALF; i < DMR_BURST_INFO_HALF+DMR_BURST_CENTRE_BITS; i++ {
+		bits.Flip(burst, uint32(i))
+	}
+
+	extracted, err := NewDMRAMBEExtractor().ExtractAMBEFrames(burst)
+	if err != nil {
+		t.Fatalf("ExtractAMBEFrames() error: %v", err)
+	}
+	for i, frame := range extracted {
+		if frame.Params != frames[i] {
+			t.Errorf("frame %d = %+v, want %+v", i, frame.Params, frames[i])
+		}
+	}
+}
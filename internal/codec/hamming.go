@@ -1,5 +1,7 @@
 package codec
 
+import "github.com/dbehnke/ysf2dmr/internal/bits"
+
 // Hamming error correction codes for BPTC and other applications
 // This matches the C++ CHamming class functionality
 
@@ -135,40 +137,15 @@ func Decode1393(d []bool) bool {
 // ByteToBitsBE converts a byte to 8 bits in big-endian order
 // Input: byte value
 // Output: 8-bit boolean array (bits[0] = MSB, bits[7] = LSB)
-func ByteToBitsBE(b uint8, bits []bool) {
-	if len(bits) < 8 {
-		return
-	}
-
-	bits[0] = (b & 0x80) != 0
-	bits[1] = (b & 0x40) != 0
-	bits[2] = (b & 0x20) != 0
-	bits[3] = (b & 0x10) != 0
-	bits[4] = (b & 0x08) != 0
-	bits[5] = (b & 0x04) != 0
-	bits[6] = (b & 0x02) != 0
-	bits[7] = (b & 0x01) != 0
+func ByteToBitsBE(b uint8, dst []bool) {
+	bits.ByteToBools(b, dst)
 }
 
 // BitsToByteBE converts 8 bits to a byte in big-endian order
 // Input: 8-bit boolean array (bits[0] = MSB, bits[7] = LSB)
 // Output: byte value
-func BitsToByteBE(bits []bool) uint8 {
-	if len(bits) < 8 {
-		return 0
-	}
-
-	var b uint8
-	if bits[0] { b |= 0x80 }
-	if bits[1] { b |= 0x40 }
-	if bits[2] { b |= 0x20 }
-	if bits[3] { b |= 0x10 }
-	if bits[4] { b |= 0x08 }
-	if bits[5] { b |= 0x04 }
-	if bits[6] { b |= 0x02 }
-	if bits[7] { b |= 0x01 }
-
-	return b
+func BitsToByteBE(src []bool) uint8 {
+	return bits.BoolsToByte(src)
 }
 
 // ValidateHamming tests the Hamming code implementations
@@ -0,0 +1,61 @@
+package codec
+
+import "fmt"
+
+// LinkQuality rates the AMBE audio of a call with an AMBEValidator so
+// degraded audio can be reported to the listeners
+type LinkQuality struct {
+	validator *AMBEValidator
+}
+
+// NewLinkQuality creates a link quality meter scoring with the given thresholds
+func NewLinkQuality(thresholds ValidatorThresholds) *LinkQuality {
+	return &LinkQuality{validator: NewAMBEValidatorWithThresholds(thresholds, false, true)}
+}
+
+// AddDMRBurst validates the three AMBE frames of a DMR voice burst
+func (q *LinkQuality) AddDMRBurst(burst []byte) error {
+	params, err := DMRVoiceParams(burst)
+	if err != nil {
+		return err
+	}
+	for i := range params {
+		q.validator.ValidateAMBEFrame(&params[i])
+	}
+	return nil
+}
+
+// Frames returns the number of AMBE frames rated since the last reset
+func (q *LinkQuality) Frames() uint64 {
+	total, _, _, _, _, _ := q.validator.GetStatistics()
+	return total
+}
+
+// Quality returns the average signal quality (0.0 - 1.0) and estimated bit
+// error rate of the frames rated since the last reset
+func (q *LinkQuality) Quality() (quality, ber float32) {
+	_, _, _, _, ber, quality = q.validator.GetStatistics()
+	return quality, ber
+}
+
+// Reset starts rating a new call, forgetting the history of the last one
+func (q *LinkQuality) Reset() {
+	q.validator.Reset()
+}
+
+// DMRVoiceParams returns the A, B and C codewords of the three AMBE frames of
+// a DMR voice burst, before FEC decoding
+// The B codeword is left PRNG scrambled.
+func DMRVoiceParams(burst []byte) ([DMR_BURST_AMBE_FRAMES]AMBEVoiceParams, error) {
+	var params [DMR_BURST_AMBE_FRAMES]AMBEVoiceParams
+	if len(burst) < DMR_FRAME_LENGTH {
+		return params, fmt.Errorf("DMR burst too short: got %d, need %d", len(burst), DMR_FRAME_LENGTH)
+	}
+
+	for i := range params {
+		params[i].A = readDMRVoiceBits(burst, i, DMR_A_TABLE[:])
+		params[i].B = readDMRVoiceBits(burst, i, DMR_B_TABLE[:])
+		params[i].C = readDMRVoiceBits(burst, i, DMR_C_TABLE[:])
+	}
+	return params, nil
+}
@@ -0,0 +1,63 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
+)
+
+func TestDMRVoiceParams(t *testing.T) {
+	burst := make([]byte, DMR_FRAME_LENGTH)
+
+	// Set the first A bit of each frame and the last C bit of the second
+	bits.Write(burst, DMR_A_TABLE[0], true)
+	bits.Write(burst, DMR_A_TABLE[0]+72, true)
+	bits.Write(burst, DMR_A_TABLE[0]+192, true)
+	bits.Write(burst, DMR_C_TABLE[24]+72+48, true)
+
+	params, err := DMRVoiceParams(burst)
+	if err != nil {
+		t.Fatalf("DMRVoiceParams() error = %v", err)
+	}
+	for i, p := range params {
+		if p.A != 0x800000 || p.B != 0 {
+			t.Errorf("frame %d: A = 0x%06X, B = 0x%06X", i, p.A, p.B)
+		}
+	}
+	if params[0].C != 0 || params[1].C != 1 || params[2].C != 0 {
+		t.Errorf("C = 0x%07X 0x%07X 0x%07X", params[0].C, params[1].C, params[2].C)
+	}
+
+	if _, err := DMRVoiceParams(burst[:DMR_FRAME_LENGTH-1]); err == nil {
+		t.Error("DMRVoiceParams() accepted a short burst")
+	}
+}
+
+func TestLinkQuality(t *testing.T) {
+	thresholds, err := ValidatorProfile(VALIDATOR_PROFILE_NORMAL)
+	if err != nil {
+		t.Fatalf("ValidatorProfile() error = %v", err)
+	}
+	q := NewLinkQuality(thresholds)
+
+	burst := make([]byte, DMR_FRAME_LENGTH)
+	for i := range burst {
+		burst[i] = 0x5A
+	}
+	for i := 0; i < 4; i++ {
+		if err := q.AddDMRBurst(burst); err != nil {
+			t.Fatalf("AddDMRBurst() error = %v", err)
+		}
+	}
+	if q.Frames() != 12 {
+		t.Errorf("Frames() = %d, want 12", q.Frames())
+	}
+	if quality, ber := q.Quality(); quality <= 0 || quality > 1 || ber < 0 || ber > 0.5 {
+		t.Errorf("Quality() = %.3f, BER %.3f", quality, ber)
+	}
+
+	q.Reset()
+	if q.Frames() != 0 {
+		t.Errorf("Frames() after Reset() = %d", q.Frames())
+	}
+}
@@ -2,6 +2,8 @@ package codec
 
 import (
 	"fmt"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
 )
 
 // ModeConv handles conversion between YSF and DMR AMBE formats
@@ -147,7 +149,7 @@ func (m *ModeConv) extractDMRAMBE(dmrBytes []uint8, frameIndex int) (*AMBEVoiceP
 			return nil, fmt.Errorf("invalid frame index: %d", frameIndex)
 		}
 
-		if m.readBit(dmrBytes, bitPos) {
+		if bits.Read(dmrBytes, bitPos) {
 			a |= mask
 		}
 		mask >>= 1
@@ -181,7 +183,7 @@ func (m *ModeConv) extractDMRAMBE(dmrBytes []uint8, frameIndex int) (*AMBEVoiceP
 			return nil, fmt.Errorf("invalid frame index: %d", frameIndex)
 		}
 
-		if m.readBit(dmrBytes, bitPos) {
+		if bits.Read(dmrBytes, bitPos) {
 			b |= mask
 		}
 		mask >>= 1
@@ -215,7 +217,7 @@ func (m *ModeConv) extractDMRAMBE(dmrBytes []uint8, frameIndex int) (*AMBEVoiceP
 			return nil, fmt.Errorf("invalid frame index: %d", frameIndex)
 		}
 
-		if m.readBit(dmrBytes, bitPos) {
+		if bits.Read(dmrBytes, bitPos) {
 			c |= mask
 		}
 		mask >>= 1
@@ -223,7 +225,8 @@ func (m *ModeConv) extractDMRAMBE(dmrBytes []uint8, frameIndex int) (*AMBEVoiceP
 
 	// Decode using Golay error correction (matching C++ behavior)
 	params.A = Decode24128(a) // Extract corrected 12-bit data
-	params.B = Decode23127(b << 1) // Shift back and decode 11-bit data
+	b ^= PRNG_TABLE[params.A] >> 1 // Remove the PRNG scrambling applied by putAMBE2DMR
+	params.B = Decode23127(b)      // Extract corrected 12-bit data
 	params.C = c // dat_c is used directly
 
 	m.logDebug("Extracted DMR AMBE frame %d: A=0x%03X, B=0x%03X, C=0x%07X", frameIndex, params.A, params.B, params.C)
@@ -236,13 +239,13 @@ func (m *ModeConv) extractDMRAMBE(dmrBytes []uint8, frameIndex int) (*AMBEVoiceP
 func (m *ModeConv) extractYSFAMBE(data []uint8, offset int) (*AMBEVoiceParameters, error) {
 	params := &AMBEVoiceParameters{}
 
-	vch := make([]uint8, 13) // 104 bits = 13 bytes
+	var vch [13]uint8 // 104 bits = 13 bytes
 
 	// Deinterleave using INTERLEAVE_TABLE_26_4
 	for i := 0; i < 104; i++ {
 		n := INTERLEAVE_TABLE_26_4[i]
-		if m.readBit(data, uint32(offset)+n) {
-			m.writeBit(vch, uint32(i), true)
+		if bits.Read(data, uint32(offset)+n) {
+			bits.Write(vch[:], uint32(i), true)
 		}
 	}
 
@@ -255,7 +258,7 @@ func (m *ModeConv) extractYSFAMBE(data []uint8, offset int) (*AMBEVoiceParameter
 	var datA uint32 = 0
 	for i := 0; i < 12; i++ {
 		datA <<= 1
-		if m.readBit(vch, uint32(3*i+1)) {
+		if bits.Read(vch[:], uint32(3*i+1)) {
 			datA |= 0x01
 		}
 	}
@@ -264,7 +267,7 @@ func (m *ModeConv) extractYSFAMBE(data []uint8, offset int) (*AMBEVoiceParameter
 	var datB uint32 = 0
 	for i := 0; i < 12; i++ {
 		datB <<= 1
-		if m.readBit(vch, uint32(3*(i+12)+1)) {
+		if bits.Read(vch[:], uint32(3*(i+12)+1)) {
 			datB |= 0x01
 		}
 	}
@@ -275,7 +278,7 @@ func (m *ModeConv) extractYSFAMBE(data []uint8, offset int) (*AMBEVoiceParameter
 	// First 3 bits from triple redundancy
 	for i := 0; i < 3; i++ {
 		datC <<= 1
-		if m.readBit(vch, uint32(3*(i+24)+1)) {
+		if bits.Read(vch[:], uint32(3*(i+24)+1)) {
 			datC |= 0x01
 		}
 	}
@@ -283,7 +286,7 @@ func (m *ModeConv) extractYSFAMBE(data []uint8, offset int) (*AMBEVoiceParameter
 	// Remaining 22 bits from direct encoding
 	for i := 0; i < 22; i++ {
 		datC <<= 1
-		if m.readBit(vch, uint32(i+81)) {
+		if bits.Read(vch[:], uint32(i+81)) {
 			datC |= 0x01
 		}
 	}
@@ -307,24 +310,24 @@ func (m *ModeConv) putAMBE2YSF(params *AMBEVoiceParameters) error {
 	datB := params.B
 	datB ^= (PRNG_TABLE[datA] >> 1)
 
-	vch := make([]uint8, 13) // 104 bits = 13 bytes
+	var vch [13]uint8 // 104 bits = 13 bytes
 
 	// Pack VCH with triple redundancy for dat_a (12 bits -> 36 bits)
 	for i := 0; i < 12; i++ {
 		bit := (datA << (20 + i)) & 0x80000000
 		bitValue := bit != 0
-		m.writeBit(vch, uint32(3*i+0), bitValue)
-		m.writeBit(vch, uint32(3*i+1), bitValue)
-		m.writeBit(vch, uint32(3*i+2), bitValue)
+		bits.Write(vch[:], uint32(3*i+0), bitValue)
+		bits.Write(vch[:], uint32(3*i+1), bitValue)
+		bits.Write(vch[:], uint32(3*i+2), bitValue)
 	}
 
 	// Pack dat_b with triple redundancy (12 bits -> 36 bits)
 	for i := 0; i < 12; i++ {
 		bit := (datB << (20 + i)) & 0x80000000
 		bitValue := bit != 0
-		m.writeBit(vch, uint32(3*(i+12)+0), bitValue)
-		m.writeBit(vch, uint32(3*(i+12)+1), bitValue)
-		m.writeBit(vch, uint32(3*(i+12)+2), bitValue)
+		bits.Write(vch[:], uint32(3*(i+12)+0), bitValue)
+		bits.Write(vch[:], uint32(3*(i+12)+1), bitValue)
+		bits.Write(vch[:], uint32(3*(i+12)+2), bitValue)
 	}
 
 	// Pack dat_c (25 bits) - first 3 bits with triple redundancy, rest direct
@@ -334,16 +337,16 @@ func (m *ModeConv) putAMBE2YSF(params *AMBEVoiceParameters) error {
 	for i := 0; i < 3; i++ {
 		bit := (datC << (7 + i)) & 0x80000000
 		bitValue := bit != 0
-		m.writeBit(vch, uint32(3*(i+24)+0), bitValue)
-		m.writeBit(vch, uint32(3*(i+24)+1), bitValue)
-		m.writeBit(vch, uint32(3*(i+24)+2), bitValue)
+		bits.Write(vch[:], uint32(3*(i+24)+0), bitValue)
+		bits.Write(vch[:], uint32(3*(i+24)+1), bitValue)
+		bits.Write(vch[:], uint32(3*(i+24)+2), bitValue)
 	}
 
 	// Remaining 22 bits direct
 	for i := 0; i < 22; i++ {
 		bit := (datC << (10 + i)) & 0x80000000
 		bitValue := bit != 0
-		m.writeBit(vch, uint32(i+81), bitValue)
+		bits.Write(vch[:], uint32(i+81), bitValue)
 	}
 
 	// Apply whitening (scrambling) with WHITENING_DATA
@@ -355,8 +358,8 @@ func (m *ModeConv) putAMBE2YSF(params *AMBEVoiceParameters) error {
 	ysfFrame := make([]uint8, 120) // Full YSF frame
 	for i := 0; i < 104; i++ {
 		n := INTERLEAVE_TABLE_26_4[i]
-		if m.readBit(vch, uint32(i)) {
-			m.writeBit(ysfFrame, n, true)
+		if bits.Read(vch[:], uint32(i)) {
+			bits.Write(ysfFrame, n, true)
 		}
 	}
 
@@ -391,7 +394,7 @@ func (m *ModeConv) putAMBE2DMR(params *AMBEVoiceParameters) error {
 	for i := 0; i < 24; i++ {
 		aPos := DMR_A_TABLE[i]
 		bitValue := (a & mask) != 0
-		m.writeBit(dmrFrame, aPos, bitValue)
+		bits.Write(dmrFrame, aPos, bitValue)
 		mask >>= 1
 	}
 
@@ -400,7 +403,7 @@ func (m *ModeConv) putAMBE2DMR(params *AMBEVoiceParameters) error {
 	for i := 0; i < 23; i++ {
 		bPos := DMR_B_TABLE[i]
 		bitValue := (b & mask) != 0
-		m.writeBit(dmrFrame, bPos, bitValue)
+		bits.Write(dmrFrame, bPos, bitValue)
 		mask >>= 1
 	}
 
@@ -409,7 +412,7 @@ func (m *ModeConv) putAMBE2DMR(params *AMBEVoiceParameters) error {
 	for i := 0; i < 25; i++ {
 		cPos := DMR_C_TABLE[i]
 		bitValue := (c & mask) != 0
-		m.writeBit(dmrFrame, cPos, bitValue)
+		bits.Write(dmrFrame, cPos, bitValue)
 		mask >>= 1
 	}
 
@@ -425,33 +428,7 @@ func (m *ModeConv) putAMBE2DMR(params *AMBEVoiceParameters) error {
 
 // Helper functions for bit manipulation
 
-// readBit reads a bit at the specified position from byte array
-func (m *ModeConv) readBit(data []uint8, bitPos uint32) bool {
-	bytePos := bitPos / 8
-	bitOffset := bitPos % 8
-
-	if bytePos >= uint32(len(data)) {
-		return false
-	}
-
-	return (data[bytePos] & (0x80 >> bitOffset)) != 0
-}
-
-// writeBit writes a bit at the specified position in byte array
-func (m *ModeConv) writeBit(data []uint8, bitPos uint32, value bool) {
-	bytePos := bitPos / 8
-	bitOffset := bitPos % 8
-
-	if bytePos >= uint32(len(data)) {
-		return
-	}
 
-	if value {
-		data[bytePos] |= (0x80 >> bitOffset)
-	} else {
-		data[bytePos] &= ^(0x80 >> bitOffset)
-	}
-}
 
 // GetYSF retrieves converted YSF frames from buffer
 // Returns the frame data and tag
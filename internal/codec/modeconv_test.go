@@ -2,6 +2,8 @@ package codec
 
 import (
 	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
 )
 
 // TestModeConvBasics tests basic ModeConv functionality
@@ -68,16 +70,13 @@ func TestGolayErrorCorrection(t *testing.T) {
 				test.input, encoded, decoded, test.expected)
 		}
 
-		// Test Golay23127 encode/decode (only 11 bits)
-		input11 := test.input & 0x7FF
-		expected11 := test.expected & 0x7FF
-
-		encoded23 := Encode23127(input11)
-		decoded23 := Decode23127(encoded23)
+		// Test Golay23127 encode/decode (codeword is returned shifted left by one)
+		encoded23 := Encode23127(test.input)
+		decoded23 := Decode23127(encoded23 >> 1)
 
-		if decoded23 != expected11 {
+		if decoded23 != test.expected {
 			t.Errorf("Golay23127: input=0x%03X, encoded=0x%06X, decoded=0x%03X, expected=0x%03X",
-				input11, encoded23, decoded23, expected11)
+				test.input, encoded23, decoded23, test.expected)
 		}
 	}
 
@@ -195,8 +194,6 @@ func TestAMBEParameterConversion(t *testing.T) {
 
 // TestBitManipulation tests the bit reading/writing functions
 func TestBitManipulation(t *testing.T) {
-	mc := NewModeConv()
-
 	// Create test data
 	data := make([]uint8, 10)
 
@@ -217,14 +214,14 @@ func TestBitManipulation(t *testing.T) {
 	// Write test bits
 	for _, test := range testBits {
 		if test.pos < 80 { // Within our test data size
-			mc.writeBit(data, test.pos, test.value)
+			bits.Write(data, test.pos, test.value)
 		}
 	}
 
 	// Read and verify test bits
 	for _, test := range testBits {
 		if test.pos < 80 {
-			result := mc.readBit(data, test.pos)
+			result := bits.Read(data, test.pos)
 			if result != test.value {
 				t.Errorf("Bit %d: wrote %v, read %v", test.pos, test.value, result)
 			}
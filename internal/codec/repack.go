@@ -0,0 +1,216 @@
+package codec
+
+import (
+	"fmt"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
+)
+
+// AMBE frames carried by one DMR voice burst
+const DMR_BURST_AMBE_FRAMES = 3
+
+// RepackConverter converts voice between YSF VD mode 2 and DMR by moving the
+// AMBE+2 bits from one framing to the other, as C++ CModeConv does
+// Both modes carry the same half rate vocoder, so nothing is validated,
+// interpolated or resampled: the 15 VCH sections of 3 YSF frames are the 15
+// AMBE frames of 5 DMR bursts. Bits are written straight into the output
+// frame being built, which is handed out once complete.
+type RepackConverter struct {
+	dmrBurst  []byte // DMR burst being filled from YSF
+	dmrFrames int    // AMBE frames already in dmrBurst
+
+	ysfPayload  []byte // VD mode 2 payload being filled from DMR
+	ysfSections int    // VCH sections already in ysfPayload
+
+	ysfToDMR uint64 // DMR bursts produced
+	dmrToYSF uint64 // YSF payloads produced
+}
+
+// NewRepackConverter creates a repack-only converter
+func NewRepackConverter() *RepackConverter {
+	return &RepackConverter{}
+}
+
+// ConvertYSFToDMR repacks the five VCH sections of a VD mode 2 payload,
+// returning the DMR bursts completed by them (one or two)
+func (c *RepackConverter) ConvertYSFToDMR(ysfPayload []byte) ([][]byte, error) {
+	if len(ysfPayload) < YSF_PAYLOAD_LENGTH {
+		return nil, fmt.Errorf("YSF payload too short: got %d, need %d", len(ysfPayload), YSF_PAYLOAD_LENGTH)
+	}
+
+	var bursts [][]byte
+	offset := uint32(YSF_VD_MODE2_VCH_OFFSET)
+	for section := 0; section < YSF_VCH_SECTIONS; section++ {
+		if c.dmrBurst == nil {
+			c.dmrBurst = make([]byte, DMR_FRAME_LENGTH)
+		}
+
+		a, b, cc := readYSFVCH(ysfPayload, offset)
+		writeDMRAMBE(c.dmrBurst, c.dmrFrames, a, b, cc)
+		offset += YSF_VD_MODE2_SECTION_BITS
+
+		c.dmrFrames++
+		if c.dmrFrames == DMR_BURST_AMBE_FRAMES {
+			bursts = append(bursts, c.dmrBurst)
+			c.dmrBurst, c.dmrFrames = nil, 0
+			c.ysfToDMR++
+		}
+	}
+	return bursts, nil
+}
+
+// ConvertDMRToYSF repacks the three AMBE frames of a DMR voice burst,
+// returning the VD mode 2 payload completed by them, if any
+// The DCH of the payloads is left clear.
+func (c *RepackConverter) ConvertDMRToYSF(dmrPayload []byte) ([][]byte, error) {
+	if len(dmrPayload) < DMR_FRAME_LENGTH {
+		return nil, fmt.Errorf("DMR burst too short: got %d, need %d", len(dmrPayload), DMR_FRAME_LENGTH)
+	}
+
+	var payloads [][]byte
+	for frame := 0; frame < DMR_BURST_AMBE_FRAMES; frame++ {
+		if c.ysfPayload == nil {
+			c.ysfPayload = make([]byte, YSF_PAYLOAD_LENGTH)
+		}
+
+		a, b, cc := readDMRAMBE(dmrPayload, frame)
+		offset := uint32(YSF_VD_MODE2_VCH_OFFSET + c.ysfSections*YSF_VD_MODE2_SECTION_BITS)
+		writeYSFVCH(c.ysfPayload, offset, a, b, cc)
+
+		c.ysfSections++
+		if c.ysfSections == YSF_VCH_SECTIONS {
+			payloads = append(payloads, c.ysfPayload)
+			c.ysfPayload, c.ysfSections = nil, 0
+			c.dmrToYSF++
+		}
+	}
+	return payloads, nil
+}
+
+// GetConversionStats returns the DMR bursts and YSF payloads produced
+func (c *RepackConverter) GetConversionStats() (uint64, uint64) {
+	return c.ysfToDMR, c.dmrToYSF
+}
+
+// Reset drops any partly filled frames at the start of a call
+func (c *RepackConverter) Reset() {
+	c.dmrBurst, c.dmrFrames = nil, 0
+	c.ysfPayload, c.ysfSections = nil, 0
+}
+
+// readYSFVCH reads the AMBE data of the VCH section at a bit offset of a VD
+// mode 2 payload: 12 bits of A and B and the 25 bits of C
+// The middle copy of each triplicated bit is used, as in C++ putYSF().
+func readYSFVCH(payload []byte, offset uint32) (a, b, c uint32) {
+	var vch [13]byte
+	for i := 0; i < YSF_VCH_BITS; i++ {
+		if bits.Read(payload, offset+INTERLEAVE_TABLE_26_4[i]) {
+			bits.Write(vch[:], uint32(i), true)
+		}
+	}
+	for i := range vch {
+		vch[i] ^= WHITENING_DATA[i]
+	}
+
+	for i := uint32(0); i < 27; i++ {
+		bit := uint32(0)
+		if bits.Read(vch[:], 3*i+1) {
+			bit = 1
+		}
+		switch {
+		case i < 12:
+			a = a<<1 | bit
+		case i < 24:
+			b = b<<1 | bit
+		default:
+			c = c<<1 | bit
+		}
+	}
+	for i := uint32(0); i < 22; i++ {
+		c <<= 1
+		if bits.Read(vch[:], 81+i) {
+			c |= 1
+		}
+	}
+	return a, b, c
+}
+
+// writeYSFVCH writes AMBE data as the VCH section at a bit offset of a VD
+// mode 2 payload, as C++ putAMBE2YSF() does
+func writeYSFVCH(payload []byte, offset uint32, a, b, c uint32) {
+	var vch [13]byte
+	triple := func(pos uint32, bit bool) {
+		bits.Write(vch[:], 3*pos, bit)
+		bits.Write(vch[:], 3*pos+1, bit)
+		bits.Write(vch[:], 3*pos+2, bit)
+	}
+	for i := uint32(0); i < 12; i++ {
+		triple(i, a&(0x800>>i) != 0)
+		triple(12+i, b&(0x800>>i) != 0)
+	}
+	for i := uint32(0); i < 3; i++ {
+		triple(24+i, c&(0x1000000>>i) != 0)
+	}
+	for i := uint32(0); i < 22; i++ {
+		bits.Write(vch[:], 81+i, c&(0x200000>>i) != 0)
+	}
+	for i := range vch {
+		vch[i] ^= WHITENING_DATA[i]
+	}
+
+	for i := 0; i < YSF_VCH_BITS; i++ {
+		bits.Write(payload, offset+INTERLEAVE_TABLE_26_4[i], bits.Read(vch[:], uint32(i)))
+	}
+}
+
+// readDMRAMBE reads the AMBE data of one frame of a DMR voice burst
+// The data is taken from the Golay codewords without correcting them, and
+// the PRNG scrambling of B is removed, as in C++ putDMR().
+func readDMRAMBE(burst []byte, frame int) (a, b, c uint32) {
+	a = readDMRVoiceBits(burst, frame, DMR_A_TABLE[:]) >> 12
+	b = readDMRVoiceBits(burst, frame, DMR_B_TABLE[:])
+	b = (b ^ PRNG_TABLE[a]>>1) >> 11
+	c = readDMRVoiceBits(burst, frame, DMR_C_TABLE[:])
+	return a, b, c
+}
+
+// writeDMRAMBE writes AMBE data as one frame of a DMR voice burst, as C++
+// putAMBE2DMR() does
+func writeDMRAMBE(burst []byte, frame int, a, b, c uint32) {
+	writeDMRVoiceBits(burst, frame, DMR_A_TABLE[:], Encode24128(a))
+	writeDMRVoiceBits(burst, frame, DMR_B_TABLE[:], Encode23127(b)>>1^PRNG_TABLE[a]>>1)
+	writeDMRVoiceBits(burst, frame, DMR_C_TABLE[:], c)
+}
+
+// dmrVoiceBitPos maps a bit position of an AMBE frame to its position in a
+// DMR voice burst
+// The second frame straddles the 48-bit sync/EMB field in the burst centre.
+func dmrVoiceBitPos(frame int, pos uint32) uint32 {
+	pos += uint32(frame) * 72
+	if pos >= 108 {
+		pos += 48
+	}
+	return pos
+}
+
+// readDMRVoiceBits reads the bits of one AMBE frame of a DMR voice burst at
+// the given positions, most significant first
+func readDMRVoiceBits(burst []byte, frame int, table []uint32) uint32 {
+	var value uint32
+	for _, pos := range table {
+		value <<= 1
+		if bits.Read(burst, dmrVoiceBitPos(frame, pos)) {
+			value |= 1
+		}
+	}
+	return value
+}
+
+// writeDMRVoiceBits writes a value into one AMBE frame of a DMR voice burst
+// at the given positions, most significant first
+func writeDMRVoiceBits(burst []byte, frame int, table []uint32, value uint32) {
+	for i, pos := range table {
+		bit := value>>(len(table)-1-i)&1 != 0
+		bits.Write(burst, dmrVoiceBitPos(frame, pos), bit)
+	}
+}
@@ -0,0 +1,158 @@
+package codec
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// testVDMode2Payloads builds VD mode 2 payloads carrying random AMBE data
+func testVDMode2Payloads(n int) ([][]byte, [][3]uint32) {
+	rng := rand.New(rand.NewSource(1))
+	var payloads [][]byte
+	var frames [][3]uint32
+	for p := 0; p < n; p++ {
+		payload := make([]byte, YSF_PAYLOAD_LENGTH)
+		for s := 0; s < YSF_VCH_SECTIONS; s++ {
+			a, b, c := uint32(rng.Intn(1<<12)), uint32(rng.Intn(1<<12)), uint32(rng.Intn(1<<25))
+			writeYSFVCH(payload, uint32(YSF_VD_MODE2_VCH_OFFSET+s*YSF_VD_MODE2_SECTION_BITS), a, b, c)
+			frames = append(frames, [3]uint32{a, b, c})
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, frames
+}
+
+func TestPRNGTableSequence(t *testing.T) {
+	// First entries of the C++ PRNG_TABLE
+	want := []uint32{0x42CC47, 0x19D6FE, 0x304729, 0x6B2CD0, 0x60BF47}
+	for i, w := range want {
+		if PRNG_TABLE[i] != w {
+			t.Errorf("PRNG_TABLE[%d] = 0x%06X, want 0x%06X", i, PRNG_TABLE[i], w)
+		}
+	}
+}
+
+func TestRepackYSFToDMR(t *testing.T) {
+	payloads, frames := testVDMode2Payloads(3)
+	c := NewRepackConverter()
+
+	var bursts [][]byte
+	for _, payload := range payloads {
+		out, err := c.ConvertYSFToDMR(payload)
+		if err != nil {
+			t.Fatalf("ConvertYSFToDMR() error = %v", err)
+		}
+		bursts = append(bursts, out...)
+	}
+	if len(bursts) != 5 {
+		t.Fatalf("3 YSF payloads gave %d DMR bursts, want 5", len(bursts))
+	}
+
+	// Every AMBE frame arrives intact and in order, with valid Golay codewords
+	for i, want := range frames {
+		burst, frame := bursts[i/DMR_BURST_AMBE_FRAMES], i%DMR_BURST_AMBE_FRAMES
+		a, b, cc := readDMRAMBE(burst, frame)
+		if [3]uint32{a, b, cc} != want {
+			t.Errorf("AMBE frame %d = %03X %03X %07X, want %03X %03X %07X", i, a, b, cc, want[0], want[1], want[2])
+		}
+		if code := readDMRVoiceBits(burst, frame, DMR_A_TABLE[:]); Decode24128(code) != want[0] {
+			t.Errorf("AMBE frame %d: A codeword 0x%06X does not decode to 0x%03X", i, code, want[0])
+		}
+	}
+
+	if ysfToDMR, _ := c.GetConversionStats(); ysfToDMR != 5 {
+		t.Errorf("GetConversionStats() = %d bursts, want 5", ysfToDMR)
+	}
+}
+
+func TestRepackRoundTrip(t *testing.T) {
+	payloads, _ := testVDMode2Payloads(6)
+	toDMR, toYSF := NewRepackConverter(), NewRepackConverter()
+
+	var got [][]byte
+	for _, payload := range payloads {
+		bursts, err := toDMR.ConvertYSFToDMR(payload)
+		if err != nil {
+			t.Fatalf("ConvertYSFToDMR() error = %v", err)
+		}
+		for _, burst := range bursts {
+			out, err := toYSF.ConvertDMRToYSF(burst)
+			if err != nil {
+				t.Fatalf("ConvertDMRToYSF() error = %v", err)
+			}
+			got = append(got, out...)
+		}
+	}
+
+	if len(got) != len(payloads) {
+		t.Fatalf("round trip gave %d payloads, want %d", len(got), len(payloads))
+	}
+	for i := range payloads {
+		if !bytes.Equal(got[i], payloads[i]) {
+			t.Errorf("payload %d changed in the round trip", i)
+		}
+	}
+}
+
+func TestRepackReset(t *testing.T) {
+	payloads, _ := testVDMode2Payloads(2)
+	c := NewRepackConverter()
+
+	// The first payload leaves two AMBE frames waiting for a third
+	if out, _ := c.ConvertYSFToDMR(payloads[0]); len(out) != 1 {
+		t.Fatalf("first payload gave %d bursts, want 1", len(out))
+	}
+	c.Reset()
+	if out, _ := c.ConvertYSFToDMR(payloads[1]); len(out) != 1 {
+		t.Errorf("payload after Reset() gave %d bursts, want 1", len(out))
+	}
+
+	if _, err := c.ConvertYSFToDMR(make([]byte, YSF_PAYLOAD_LENGTH-1)); err == nil {
+		t.Error("ConvertYSFToDMR() accepted a short payload")
+	}
+	if _, err := c.ConvertDMRToYSF(make([]byte, DMR_FRAME_LENGTH-1)); err == nil {
+		t.Error("ConvertDMRToYSF() accepted a short burst")
+	}
+}
+
+// The benchmarks compare the per-frame cost of the repack path with the
+// validating frame ratio converter
+
+func BenchmarkRepackYSFToDMR(b *testing.B) {
+	payloads, _ := testVDMode2Payloads(3)
+	c := NewRepackConverter()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.ConvertYSFToDMR(payloads[i%3])
+	}
+}
+
+func BenchmarkFrameRatioYSFToDMR(b *testing.B) {
+	payloads, _ := testVDMode2Payloads(3)
+	c := NewFrameRatioConverter()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.ConvertYSFToDMR(payloads[i%3])
+	}
+}
+
+func BenchmarkRepackDMRToYSF(b *testing.B) {
+	burst := make([]byte, DMR_FRAME_LENGTH)
+	rand.New(rand.NewSource(1)).Read(burst)
+	c := NewRepackConverter()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.ConvertDMRToYSF(burst)
+	}
+}
+
+func BenchmarkFrameRatioDMRToYSF(b *testing.B) {
+	burst := make([]byte, DMR_FRAME_LENGTH)
+	rand.New(rand.NewSource(1)).Read(burst)
+	c := NewFrameRatioConverter()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.ConvertDMRToYSF(burst)
+	}
+}
@@ -0,0 +1,80 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// PCM frame constants shared by all transcoder backends
+const (
+	PCM_SAMPLE_RATE       = 8000 // AMBE+2 operates on 8kHz audio
+	PCM_SAMPLES_PER_FRAME = 160  // One 20ms AMBE frame
+)
+
+// ErrNoTranscoder is returned when no transcoder backend is available
+var ErrNoTranscoder = errors.New("no AMBE transcoder backend available")
+
+// Transcoder converts between AMBE+2 voice frames and 8kHz PCM audio
+// AMBE+2 is not implemented in this tree; backends register themselves with
+// RegisterTranscoder. The md380-emu vocoder server backend is built in, and
+// others (e.g. a DV3000 dongle) can be added the same way.
+type Transcoder interface {
+	// DecodeAMBE decodes one 72-bit AMBE+2 frame (9 bytes) to 160 PCM samples
+	DecodeAMBE(frame []byte) ([]int16, error)
+
+	// EncodeAMBE encodes 160 PCM samples to one 72-bit AMBE+2 frame (9 bytes)
+	EncodeAMBE(pcm []int16) ([]byte, error)
+
+	// Close releases any resources held by the backend
+	Close() error
+}
+
+// TranscoderFactory creates a transcoder from a backend specific argument
+// (device path, host:port, etc.)
+type TranscoderFactory func(arg string) (Transcoder, error)
+
+var (
+	transcodersMu sync.RWMutex
+	transcoders   = make(map[string]TranscoderFactory)
+)
+
+// RegisterTranscoder makes a transcoder backend available by name
+func RegisterTranscoder(name string, factory TranscoderFactory) {
+	transcodersMu.Lock()
+	defer transcodersMu.Unlock()
+
+	if factory == nil {
+		panic("codec: RegisterTranscoder factory is nil")
+	}
+	if _, exists := transcoders[name]; exists {
+		panic("codec: RegisterTranscoder called twice for " + name)
+	}
+	transcoders[name] = factory
+}
+
+// NewTranscoder creates a transcoder using the named backend
+func NewTranscoder(name string, arg string) (Transcoder, error) {
+	transcodersMu.RLock()
+	factory, ok := transcoders[name]
+	transcodersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (registered: %v)", ErrNoTranscoder, name, TranscoderNames())
+	}
+	return factory(arg)
+}
+
+// TranscoderNames returns the sorted names of registered backends
+func TranscoderNames() []string {
+	transcodersMu.RLock()
+	defer transcodersMu.RUnlock()
+
+	names := make([]string, 0, len(transcoders))
+	for name := range transcoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,119 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// md380-emu vocoder server constants
+// md380-emu (as run for Analog_Bridge with -S) answers a 9 byte AMBE+2
+// frame with 160 samples of 16-bit little-endian PCM, and 320 bytes of PCM
+// with an AMBE+2 frame, over UDP.
+const (
+	MD380_EMU_TRANSCODER      = "md380-emu"
+	MD380_EMU_DEFAULT_ADDRESS = "127.0.0.1:2470"
+	MD380_EMU_TIMEOUT         = 200 * time.Millisecond // per frame
+
+	md380EmuAMBELength = 9
+	md380EmuPCMLength  = PCM_SAMPLES_PER_FRAME * 2
+)
+
+func init() {
+	RegisterTranscoder(MD380_EMU_TRANSCODER, newMD380Emu)
+}
+
+// md380Emu transcodes through an md380-emu vocoder server
+type md380Emu struct {
+	mu     sync.Mutex // one frame in flight at a time
+	conn   *net.UDPConn
+	buffer [md380EmuPCMLength + 1]byte // one spare byte catches oversized replies
+}
+
+// newMD380Emu connects to the md380-emu server at arg (host:port), or at
+// MD380_EMU_DEFAULT_ADDRESS when arg is empty
+func newMD380Emu(arg string) (Transcoder, error) {
+	if arg == "" {
+		arg = MD380_EMU_DEFAULT_ADDRESS
+	}
+	addr, err := net.ResolveUDPAddr("udp", arg)
+	if err != nil {
+		return nil, fmt.Errorf("md380-emu address %q: %v", arg, err)
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return nil, fmt.Errorf("md380-emu connect to %s: %v", addr, err)
+	}
+	return &md380Emu{conn: conn}, nil
+}
+
+// DecodeAMBE decodes one AMBE+2 frame to 160 PCM samples
+func (e *md380Emu) DecodeAMBE(frame []byte) ([]int16, error) {
+	if len(frame) != md380EmuAMBELength {
+		return nil, fmt.Errorf("md380-emu: AMBE frame of %d bytes, want %d", len(frame), md380EmuAMBELength)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	reply, err := e.exchange(frame, md380EmuPCMLength)
+	if err != nil {
+		return nil, err
+	}
+	pcm := make([]int16, PCM_SAMPLES_PER_FRAME)
+	for i := range pcm {
+		pcm[i] = int16(binary.LittleEndian.Uint16(reply[i*2:]))
+	}
+	return pcm, nil
+}
+
+// EncodeAMBE encodes 160 PCM samples to one AMBE+2 frame
+func (e *md380Emu) EncodeAMBE(pcm []int16) ([]byte, error) {
+	if len(pcm) != PCM_SAMPLES_PER_FRAME {
+		return nil, fmt.Errorf("md380-emu: %d PCM samples, want %d", len(pcm), PCM_SAMPLES_PER_FRAME)
+	}
+	request := make([]byte, md380EmuPCMLength)
+	for i, sample := range pcm {
+		binary.LittleEndian.PutUint16(request[i*2:], uint16(sample))
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	reply, err := e.exchange(request, md380EmuAMBELength)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), reply...), nil
+}
+
+// exchange sends request and waits for a reply of replyLength bytes
+// Replies of another length, such as a late answer to a frame that timed
+// out, are skipped. Callers must hold e.mu.
+func (e *md380Emu) exchange(request []byte, replyLength int) ([]byte, error) {
+	if _, err := e.conn.Write(request); err != nil {
+		return nil, fmt.Errorf("md380-emu: %v", err)
+	}
+	if err := e.conn.SetReadDeadline(time.Now().Add(MD380_EMU_TIMEOUT)); err != nil {
+		return nil, fmt.Errorf("md380-emu: %v", err)
+	}
+	for {
+		n, err := e.conn.Read(e.buffer[:])
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return nil, fmt.Errorf("md380-emu: no reply within %v", MD380_EMU_TIMEOUT)
+			}
+			return nil, fmt.Errorf("md380-emu: %v", err)
+		}
+		if n == replyLength {
+			return e.buffer[:n], nil
+		}
+	}
+}
+
+// Close closes the connection to the server
+func (e *md380Emu) Close() error {
+	return e.conn.Close()
+}
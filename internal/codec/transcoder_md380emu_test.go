@@ -0,0 +1,100 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+)
+
+// startMD380Emu runs a fake md380-emu server that decodes every AMBE frame
+// to samples of its first byte and encodes PCM to 9 bytes of its first
+// sample, first sending a stray reply when stray is set
+func startMD380Emu(t *testing.T, stray bool) string {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buffer := make([]byte, 1024)
+		for {
+			n, from, err := conn.ReadFromUDP(buffer)
+			if err != nil {
+				return
+			}
+			if stray {
+				conn.WriteToUDP([]byte{1, 2, 3}, from)
+			}
+			switch n {
+			case md380EmuAMBELength:
+				reply := make([]byte, md380EmuPCMLength)
+				for i := 0; i < PCM_SAMPLES_PER_FRAME; i++ {
+					binary.LittleEndian.PutUint16(reply[i*2:], uint16(buffer[0]))
+				}
+				conn.WriteToUDP(reply, from)
+			case md380EmuPCMLength:
+				conn.WriteToUDP(bytes.Repeat(buffer[:1], md380EmuAMBELength), from)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestMD380Emu_RoundTrip(t *testing.T) {
+	for _, stray := range []bool{false, true} {
+		transcoder, err := NewTranscoder(MD380_EMU_TRANSCODER, startMD380Emu(t, stray))
+		if err != nil {
+			t.Fatalf("NewTranscoder() error = %v", err)
+		}
+		defer transcoder.Close()
+
+		pcm, err := transcoder.DecodeAMBE(bytes.Repeat([]byte{42}, 9))
+		if err != nil {
+			t.Fatalf("DecodeAMBE() error = %v", err)
+		}
+		if len(pcm) != PCM_SAMPLES_PER_FRAME || pcm[0] != 42 || pcm[159] != 42 {
+			t.Errorf("DecodeAMBE() = %d samples starting %v, want 160 samples of 42", len(pcm), pcm[:1])
+		}
+
+		pcm[0] = 7
+		ambe, err := transcoder.EncodeAMBE(pcm)
+		if err != nil {
+			t.Fatalf("EncodeAMBE() error = %v", err)
+		}
+		if !bytes.Equal(ambe, bytes.Repeat([]byte{7}, 9)) {
+			t.Errorf("EncodeAMBE() = % X, want 9 bytes of 07", ambe)
+		}
+	}
+}
+
+func TestMD380Emu_Errors(t *testing.T) {
+	// Nothing answers on the address of a closed socket
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP() error = %v", err)
+	}
+	address := conn.LocalAddr().String()
+	conn.Close()
+
+	transcoder, err := NewTranscoder(MD380_EMU_TRANSCODER, address)
+	if err != nil {
+		t.Fatalf("NewTranscoder() error = %v", err)
+	}
+	defer transcoder.Close()
+
+	if _, err := transcoder.DecodeAMBE(make([]byte, 9)); err == nil {
+		t.Error("DecodeAMBE() succeeded without a server")
+	}
+	if _, err := transcoder.DecodeAMBE(make([]byte, 7)); err == nil {
+		t.Error("DecodeAMBE() accepted a 7 byte frame")
+	}
+	if _, err := transcoder.EncodeAMBE(make([]int16, 80)); err == nil {
+		t.Error("EncodeAMBE() accepted 80 samples")
+	}
+	if _, err := NewTranscoder(MD380_EMU_TRANSCODER, "no-port"); err == nil {
+		t.Error("NewTranscoder() accepted an address without a port")
+	}
+}
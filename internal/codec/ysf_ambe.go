@@ -2,6 +2,8 @@ package codec
 
 import (
 	"fmt"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
 )
 
 // YSFAMBEExtractor handles YSF AMBE frame extraction and processing
@@ -44,7 +46,7 @@ func (e *YSFAMBEExtractor) extractVCHSection(payload []byte, sectionIndex int, v
 	}
 
 	// Step 1: Extract the 312 interleaved bits for this VCH section (104 bits * 3 for triple redundancy)
-	tripleBits := make([]bool, YSF_VCH_BITS*3) // 312 bits
+	var tripleBits [YSF_VCH_BITS * 3]bool // 312 bits
 
 	// Calculate the starting bit position for this VCH section in the payload
 	// Each VCH section has 312 bits (104 * 3), sections are laid out sequentially
@@ -57,24 +59,19 @@ func (e *YSFAMBEExtractor) extractVCHSection(payload []byte, sectionIndex int, v
 			break // Prevent buffer overflow
 		}
 
-		bytePos := bitPos / 8
-		bitOffset := bitPos % 8
-
-		if bytePos < len(payload) {
-			tripleBits[i] = (payload[bytePos] & (1 << (7 - bitOffset))) != 0
-		}
+		tripleBits[i] = bits.Read(payload, uint32(bitPos))
 	}
 
 	// Step 2: De-interleave the triple bits using the interleave table
-	deinterleavedBits := make([]bool, YSF_VCH_BITS*3)
-	e.deinterleave(tripleBits, deinterleavedBits)
+	var deinterleavedBits [YSF_VCH_BITS * 3]bool
+	e.deinterleave(tripleBits[:], deinterleavedBits[:])
 
 	// Step 3: Remove whitening/scrambling
-	dewhitenedBits := make([]bool, YSF_VCH_BITS*3)
-	e.removeWhitening(deinterleavedBits, dewhitenedBits, sectionIndex)
+	var dewhitenedBits [YSF_VCH_BITS * 3]bool
+	e.removeWhitening(deinterleavedBits[:], dewhitenedBits[:], sectionIndex)
 
 	// Step 4: Perform majority voting on triple bits to get 104 voice bits
-	voiceBits := make([]bool, YSF_VCH_BITS)
+	var voiceBits [YSF_VCH_BITS]bool
 	for i := 0; i < YSF_VCH_BITS; i++ {
 		// Each voice bit is represented by 3 consecutive bits - use majority vote
 		bit1 := dewhitenedBits[i*3]
@@ -86,14 +83,7 @@ func (e *YSFAMBEExtractor) extractVCHSection(payload []byte, sectionIndex int, v
 	}
 
 	// Step 5: Pack the 104 voice bits into 13 bytes
-	for i := 0; i < YSF_VCH_BITS; i++ {
-		byteIndex := i / 8
-		bitIndex := 7 - (i % 8) // MSB first
-
-		if voiceBits[i] {
-			vch.Data[byteIndex] |= (1 << bitIndex)
-		}
-	}
+	bits.FromBools(vch.Data[:], voiceBits[:])
 
 	return nil
 }
@@ -141,7 +131,7 @@ func (e *YSFAMBEExtractor) removeWhitening(src, dest []bool, sectionIndex int) {
 		whiteningBitIndex := i % 8
 
 		// Get the whitening bit
-		whiteningBit := (WHITENING_DATA[whiteningByteIndex] & (1 << (7 - whiteningBitIndex))) != 0
+		whiteningBit := bits.Read(WHITENING_DATA[whiteningByteIndex:], uint32(whiteningBitIndex))
 
 		// XOR with whitening bit to remove scrambling
 		dest[i] = src[i] != whiteningBit // XOR operation
@@ -237,8 +227,8 @@ func (e *YSFAMBEExtractor) GetVCHBitError(vch *YSFVCHSection) float32 {
 
 	for i := 0; i < len(vch.Data); i++ {
 		for j := 0; j < 7; j++ {
-			bit1 := (vch.Data[i] & (1 << (7 - j))) != 0
-			bit2 := (vch.Data[i] & (1 << (6 - j))) != 0
+			bit1 := bits.Read(vch.Data[i:], uint32(j))
+			bit2 := bits.Read(vch.Data[i:], uint32(j+1))
 			if bit1 != bit2 {
 				transitions++
 			}
@@ -1,5 +1,7 @@
 package codec
 
+import "github.com/dbehnke/ysf2dmr/internal/bits"
+
 // YSFConvolution implements YSF Convolutional coding with Viterbi decoder
 // This matches the C++ CNXDNConvolution class functionality for YSF voice protection
 //
@@ -44,30 +46,7 @@ func NewYSFConvolution() *YSFConvolution {
 	return conv
 }
 
-// writeBit writes a bit to a byte array at the specified bit position
-func (c *YSFConvolution) writeBit(data []uint8, pos uint32, bit bool) {
-	bytePos := pos >> 3
-	bitPos := pos & 7
 
-	if bytePos < uint32(len(data)) {
-		if bit {
-			data[bytePos] |= YSF_CONV_BIT_MASK_TABLE[bitPos]
-		} else {
-			data[bytePos] &= ^YSF_CONV_BIT_MASK_TABLE[bitPos]
-		}
-	}
-}
-
-// readBit reads a bit from a byte array at the specified bit position
-func (c *YSFConvolution) readBit(data []uint8, pos uint32) bool {
-	bytePos := pos >> 3
-	bitPos := pos & 7
-
-	if bytePos < uint32(len(data)) {
-		return (data[bytePos] & YSF_CONV_BIT_MASK_TABLE[bitPos]) != 0
-	}
-	return false
-}
 
 // Start initializes the decoder state
 // Equivalent to C++ CNXDNConvolution::start()
@@ -185,7 +164,7 @@ func (c *YSFConvolution) Chainback(out []uint8, nBits uint32) {
 
 		// Write decoded bit to position nBits (now decremented)
 		// This matches C++ WRITE_BIT1(out, nBits, bit != 0U)
-		c.writeBit(out, nBits, bit != 0)
+		bits.Write(out, nBits, bit != 0)
 	}
 }
 
@@ -206,7 +185,7 @@ func (c *YSFConvolution) Encode(in []uint8, out []uint8, nBits uint32) {
 	for i := uint32(0); i < nBits; i++ {
 		// Read input bit
 		var d uint8
-		if c.readBit(in, i) {
+		if bits.Read(in, i) {
 			d = 1
 		} else {
 			d = 0
@@ -223,9 +202,9 @@ func (c *YSFConvolution) Encode(in []uint8, out []uint8, nBits uint32) {
 		d1 = d
 
 		// Write encoded bits
-		c.writeBit(out, k, g1 != 0)
+		bits.Write(out, k, g1 != 0)
 		k++
-		c.writeBit(out, k, g2 != 0)
+		bits.Write(out, k, g2 != 0)
 		k++
 	}
 }
@@ -265,14 +244,14 @@ func (c *YSFConvolution) DecodeData(encoded []uint8, nEncodedBits uint32) ([]uin
 		var s0, s1 uint8
 
 		// Convert bits to soft symbols (0 or 2 for hard decisions)
-		if c.readBit(encoded, i) {
+		if bits.Read(encoded, i) {
 			s0 = 2
 		} else {
 			s0 = 0
 		}
 
 		if i+1 < nEncodedBits {
-			if c.readBit(encoded, i+1) {
+			if bits.Read(encoded, i+1) {
 				s1 = 2
 			} else {
 				s1 = 0
@@ -351,8 +330,8 @@ func (c *YSFConvolution) GetBER(original, received []uint8, nBits uint32) float6
 	totalBits := 0
 
 	for i := uint32(0); i < nBits && i < uint32(len(original)*8) && i < uint32(len(received)*8); i++ {
-		origBit := c.readBit(original, i)
-		recvBit := c.readBit(received, i)
+		origBit := bits.Read(original, i)
+		recvBit := bits.Read(received, i)
 
 		if origBit != recvBit {
 			errors++
@@ -2,6 +2,8 @@ package codec
 
 import (
 	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
 )
 
 // TestYSFConvBasic tests basic encode/decode functionality
@@ -76,8 +78,8 @@ func TestYSFConvEncoding(t *testing.T) {
 
 	// With generators G1=(1+X²+X³) and G2=(1+X+X²+X³)
 	// For input bit 1: G1=1, G2=1, so output should be [1,1]
-	expectedBit0 := conv.readBit(encoded, 0) // First output bit
-	expectedBit1 := conv.readBit(encoded, 1) // Second output bit
+	expectedBit0 := bits.Read(encoded, 0) // First output bit
+	expectedBit1 := bits.Read(encoded, 1) // Second output bit
 
 	t.Logf("Input: 0x%02X", input[0])
 	t.Logf("Encoded: 0x%02X", encoded[0])
@@ -132,8 +134,6 @@ func TestYSFConvViterbiDecoder(t *testing.T) {
 
 // TestYSFConvBitManipulation tests bit manipulation functions
 func TestYSFConvBitManipulation(t *testing.T) {
-	conv := NewYSFConvolution()
-
 	data := make([]uint8, 2)
 
 	// Test writing and reading bits
@@ -149,8 +149,8 @@ func TestYSFConvBitManipulation(t *testing.T) {
 	}
 
 	for _, test := range testBits {
-		conv.writeBit(data, test.pos, test.bit)
-		result := conv.readBit(data, test.pos)
+		bits.Write(data, test.pos, test.bit)
+		result := bits.Read(data, test.pos)
 
 		if result != test.bit {
 			t.Errorf("Bit %d: wrote %t, read %t", test.pos, test.bit, result)
@@ -0,0 +1,118 @@
+package codec
+
+import "github.com/dbehnke/ysf2dmr/internal/bits"
+
+// YSF FICH (Frame Information CHannel) coding
+// This matches the C++ CYSFFICH encode()/decode() functionality
+//
+// FICH parameters:
+// - 4 information bytes followed by a CCITT162 CRC (6 bytes, 48 bits)
+// - Each 12 bits protected by Golay (24,12) (4 codewords, 96 bits)
+// - Rate 1/2 convolutional encoding (100 bits → 200 encoded bits)
+// - Same 5×20 interleaving as VD Mode 2
+// - Occupies the 25 bytes following the YSF sync pattern
+
+// Constants from C++ implementation
+const (
+	YSF_FICH_INFO_LENGTH    = 4  // FICH information bytes
+	YSF_FICH_DATA_LENGTH    = 6  // Information bytes + CRC
+	YSF_FICH_GOLAY_LENGTH   = 12 // Four Golay (24,12) codewords
+	YSF_FICH_ENCODED_LENGTH = 25 // Encoded FICH length in bytes
+	YSF_FICH_INFO_BITS      = 96 // Golay protected bits
+	YSF_FICH_CONV_BITS      = 100
+)
+
+// EncodeYSFFICH encodes the 4 FICH information bytes for transmission
+// Equivalent to C++ CYSFFICH::encode()
+func EncodeYSFFICH(info [YSF_FICH_INFO_LENGTH]uint8) [YSF_FICH_ENCODED_LENGTH]uint8 {
+	var fich [YSF_FICH_DATA_LENGTH]uint8
+	copy(fich[:], info[:])
+	AddCCITT162(fich[:], YSF_FICH_DATA_LENGTH)
+
+	// Split into 12-bit blocks and Golay encode each one
+	blocks := [4]uint32{
+		(uint32(fich[0]) << 4) | (uint32(fich[1]) >> 4),
+		((uint32(fich[1]) << 8) & 0xF00) | uint32(fich[2]),
+		(uint32(fich[3]) << 4) | (uint32(fich[4]) >> 4),
+		((uint32(fich[4]) << 8) & 0xF00) | uint32(fich[5]),
+	}
+
+	var golay [YSF_FICH_GOLAY_LENGTH + 1]uint8 // +1 for the convolution tail
+	for i, block := range blocks {
+		code := Encode24128(block)
+		golay[i*3+0] = uint8(code >> 16)
+		golay[i*3+1] = uint8(code >> 8)
+		golay[i*3+2] = uint8(code)
+	}
+
+	conv := NewYSFConvolution()
+	var convolved [YSF_FICH_ENCODED_LENGTH]uint8
+	conv.Encode(golay[:], convolved[:], YSF_FICH_CONV_BITS)
+
+	var result [YSF_FICH_ENCODED_LENGTH]uint8
+	j := uint32(0)
+	for i := 0; i < YSF_FICH_CONV_BITS; i++ {
+		n := YSF_VD_MODE2_INTERLEAVE_TABLE[i]
+
+		s0 := bits.Read(convolved[:], j)
+		j++
+		s1 := bits.Read(convolved[:], j)
+		j++
+
+		bits.Write(result[:], n, s0)
+		bits.Write(result[:], n+1, s1)
+	}
+
+	return result
+}
+
+// DecodeYSFFICH decodes a received FICH, correcting errors where possible
+// Returns the 4 information bytes and whether the CRC check passed.
+// Equivalent to C++ CYSFFICH::decode()
+func DecodeYSFFICH(encoded []uint8) ([YSF_FICH_INFO_LENGTH]uint8, bool) {
+	var info [YSF_FICH_INFO_LENGTH]uint8
+	if len(encoded) < YSF_FICH_ENCODED_LENGTH {
+		return info, false
+	}
+
+	conv := NewYSFConvolution()
+	conv.Start()
+	for i := 0; i < YSF_FICH_CONV_BITS; i++ {
+		n := YSF_VD_MODE2_INTERLEAVE_TABLE[i]
+
+		s0 := uint8(0)
+		if bits.Read(encoded, n) {
+			s0 = 1
+		}
+		s1 := uint8(0)
+		if bits.Read(encoded, n+1) {
+			s1 = 1
+		}
+
+		conv.Decode(s0, s1)
+	}
+
+	var golay [YSF_FICH_GOLAY_LENGTH + 1]uint8
+	conv.Chainback(golay[:], YSF_FICH_INFO_BITS)
+
+	var blocks [4]uint32
+	for i := range blocks {
+		code := (uint32(golay[i*3]) << 16) | (uint32(golay[i*3+1]) << 8) | uint32(golay[i*3+2])
+		blocks[i] = Decode24128(code)
+	}
+
+	fich := [YSF_FICH_DATA_LENGTH]uint8{
+		uint8(blocks[0] >> 4),
+		uint8(blocks[0]<<4) | uint8(blocks[1]>>8)&0x0F,
+		uint8(blocks[1]),
+		uint8(blocks[2] >> 4),
+		uint8(blocks[2]<<4) | uint8(blocks[3]>>8)&0x0F,
+		uint8(blocks[3]),
+	}
+	if !CheckCCITT162(fich[:], YSF_FICH_DATA_LENGTH) {
+		return info, false
+	}
+
+	copy(info[:], fich[:YSF_FICH_INFO_LENGTH])
+	return info, true
+}
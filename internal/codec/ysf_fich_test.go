@@ -0,0 +1,52 @@
+package codec
+
+import "testing"
+
+func TestYSFFICHRoundTrip(t *testing.T) {
+	tests := [][YSF_FICH_INFO_LENGTH]uint8{
+		{0x00, 0x00, 0x00, 0x00},
+		{0x40, 0x1A, 0x22, 0x00},
+		{0xFF, 0xFF, 0xFF, 0xFF},
+		{0x12, 0x34, 0x56, 0x78},
+	}
+
+	for _, info := range tests {
+		encoded := EncodeYSFFICH(info)
+		decoded, ok := DecodeYSFFICH(encoded[:])
+		if !ok {
+			t.Errorf("DecodeYSFFICH(%X) CRC failed", info)
+			continue
+		}
+		if decoded != info {
+			t.Errorf("DecodeYSFFICH() = %X, want %X", decoded, info)
+		}
+	}
+}
+
+func TestYSFFICHErrorCorrection(t *testing.T) {
+	info := [YSF_FICH_INFO_LENGTH]uint8{0x40, 0x1A, 0x22, 0x00}
+	encoded := EncodeYSFFICH(info)
+
+	// Flip a few widely spaced bits
+	for _, bit := range []int{3, 61, 117, 190} {
+		encoded[bit/8] ^= 0x80 >> (bit % 8)
+	}
+
+	decoded, ok := DecodeYSFFICH(encoded[:])
+	if !ok || decoded != info {
+		t.Errorf("DecodeYSFFICH() = %X, %v, want %X, true", decoded, ok, info)
+	}
+}
+
+func TestYSFFICHCorrupt(t *testing.T) {
+	var garbage [YSF_FICH_ENCODED_LENGTH]uint8
+	for i := range garbage {
+		garbage[i] = uint8(i*37 + 11)
+	}
+	if _, ok := DecodeYSFFICH(garbage[:]); ok {
+		t.Error("DecodeYSFFICH() accepted random data")
+	}
+	if _, ok := DecodeYSFFICH(garbage[:10]); ok {
+		t.Error("DecodeYSFFICH() accepted short data")
+	}
+}
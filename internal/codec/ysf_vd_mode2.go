@@ -1,5 +1,7 @@
 package codec
 
+import "github.com/dbehnke/ysf2dmr/internal/bits"
+
 // YSFVDMode2 implements YSF Voice/Data Mode 2 encoding/decoding for callsigns
 // This matches the C++ CYSFPayload class functionality for YSF callsign protection
 //
@@ -74,30 +76,7 @@ func NewYSFVDMode2() *YSFVDMode2 {
 	}
 }
 
-// writeBit writes a bit to a byte array at the specified bit position
-func (vd *YSFVDMode2) writeBit(data []uint8, pos uint32, bit bool) {
-	bytePos := pos >> 3
-	bitPos := pos & 7
 
-	if bytePos < uint32(len(data)) {
-		if bit {
-			data[bytePos] |= YSF_VD_MODE2_BIT_MASK_TABLE[bitPos]
-		} else {
-			data[bytePos] &= ^YSF_VD_MODE2_BIT_MASK_TABLE[bitPos]
-		}
-	}
-}
-
-// readBit reads a bit from a byte array at the specified bit position
-func (vd *YSFVDMode2) readBit(data []uint8, pos uint32) bool {
-	bytePos := pos >> 3
-	bitPos := pos & 7
-
-	if bytePos < uint32(len(data)) {
-		return (data[bytePos] & YSF_VD_MODE2_BIT_MASK_TABLE[bitPos]) != 0
-	}
-	return false
-}
 
 // EncodeCallsign encodes a callsign string for VD Mode 2 transmission
 // Input: callsign string (up to 10 characters)
@@ -142,14 +121,14 @@ func (vd *YSFVDMode2) EncodeCallsign(callsign string) [YSF_VD_MODE2_ENCODED_LENG
 		n := YSF_VD_MODE2_INTERLEAVE_TABLE[i]
 
 		// Read two bits from convolved data
-		s0 := vd.readBit(convolved[:], j)
+		s0 := bits.Read(convolved[:], j)
 		j++
-		s1 := vd.readBit(convolved[:], j)
+		s1 := bits.Read(convolved[:], j)
 		j++
 
 		// Write interleaved bits
-		vd.writeBit(bytes[:], n, s0)
-		vd.writeBit(bytes[:], n+1, s1)
+		bits.Write(bytes[:], n, s0)
+		bits.Write(bytes[:], n+1, s1)
 	}
 
 	// Copy result (C++ lines 374-379)
@@ -176,12 +155,12 @@ func (vd *YSFVDMode2) DecodeCallsign(encoded [YSF_VD_MODE2_ENCODED_LENGTH]uint8)
 
 		// Read interleaved bits
 		s0 := uint8(0)
-		if vd.readBit(dch[:], n) {
+		if bits.Read(dch[:], n) {
 			s0 = 1
 		}
 
 		s1 := uint8(0)
-		if vd.readBit(dch[:], n+1) {
+		if bits.Read(dch[:], n+1) {
 			s1 = 1
 		}
 
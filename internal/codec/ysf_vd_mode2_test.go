@@ -2,6 +2,8 @@ package codec
 
 import (
 	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
 )
 
 // TestYSFVDMode2BasicRoundTrip tests basic callsign encode/decode functionality
@@ -350,8 +352,6 @@ func TestYSFVDMode2EdgeCases(t *testing.T) {
 
 // TestYSFVDMode2BitManipulation tests internal bit manipulation functions
 func TestYSFVDMode2BitManipulation(t *testing.T) {
-	vd := NewYSFVDMode2()
-
 	// Test bit writing and reading
 	data := make([]uint8, 4)
 
@@ -370,12 +370,12 @@ func TestYSFVDMode2BitManipulation(t *testing.T) {
 
 	// Write bits
 	for _, test := range testBits {
-		vd.writeBit(data, test.pos, test.bit)
+		bits.Write(data, test.pos, test.bit)
 	}
 
 	// Read bits back
 	for _, test := range testBits {
-		result := vd.readBit(data, test.pos)
+		result := bits.Read(data, test.pos)
 		if result != test.bit {
 			t.Errorf("Bit %d: wrote %t, read %t", test.pos, test.bit, result)
 		}
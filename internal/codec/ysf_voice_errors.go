@@ -0,0 +1,49 @@
+package codec
+
+import "github.com/dbehnke/ysf2dmr/internal/bits"
+
+// VD mode 2 voice channel layout within the 90-byte payload
+const (
+	YSF_VD_MODE2_VCH_OFFSET   = 40  // DCH bits ahead of the first VCH
+	YSF_VD_MODE2_SECTION_BITS = 144 // DCH + VCH bits per section
+	YSF_VD_MODE2_REPEAT_BITS  = 81  // 27 AMBE bits sent three times each
+
+	// Repetition coded bits checked in one payload
+	YSF_VD_MODE2_CHECKED_BITS = YSF_VCH_SECTIONS * YSF_VD_MODE2_REPEAT_BITS
+)
+
+// CountYSFVDMode2Errors counts bit errors in the voice channels of a VD mode 2
+// payload. Each of the first 27 AMBE bits of a VCH is sent three times, so a
+// triplet that does not agree holds one bit error. Returns the number of
+// errors out of YSF_VD_MODE2_CHECKED_BITS.
+// Equivalent to the error count of C++ CYSFPayload::processVDMode2Audio()
+func CountYSFVDMode2Errors(payload []byte) int {
+	if len(payload) < YSF_PAYLOAD_LENGTH {
+		return 0
+	}
+
+	errors := 0
+	offset := uint32(YSF_VD_MODE2_VCH_OFFSET)
+	for section := 0; section < YSF_VCH_SECTIONS; section++ {
+		var vch [13]uint8
+		for i := 0; i < YSF_VCH_BITS; i++ {
+			if bits.Read(payload, offset+INTERLEAVE_TABLE_26_4[i]) {
+				bits.Write(vch[:], uint32(i), true)
+			}
+		}
+		for i := range vch {
+			vch[i] ^= WHITENING_DATA[i]
+		}
+
+		for i := uint32(0); i < YSF_VD_MODE2_REPEAT_BITS; i += 3 {
+			a, b, c := bits.Read(vch[:], i), bits.Read(vch[:], i+1), bits.Read(vch[:], i+2)
+			if a != b || b != c {
+				errors++
+			}
+		}
+
+		offset += YSF_VD_MODE2_SECTION_BITS
+	}
+
+	return errors
+}
@@ -0,0 +1,43 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
+)
+
+// buildVDMode2Payload interleaves whitened all-zero voice channels into a payload
+func buildVDMode2Payload() []byte {
+	payload := make([]byte, YSF_PAYLOAD_LENGTH)
+	offset := uint32(YSF_VD_MODE2_VCH_OFFSET)
+	for section := 0; section < YSF_VCH_SECTIONS; section++ {
+		for i := 0; i < YSF_VCH_BITS; i++ {
+			if bits.Read(WHITENING_DATA[:13], uint32(i)) {
+				bits.Write(payload, offset+INTERLEAVE_TABLE_26_4[i], true)
+			}
+		}
+		offset += YSF_VD_MODE2_SECTION_BITS
+	}
+	return payload
+}
+
+func TestCountYSFVDMode2Errors(t *testing.T) {
+	payload := buildVDMode2Payload()
+	if errs := CountYSFVDMode2Errors(payload); errs != 0 {
+		t.Fatalf("clean payload: %d errors, want 0", errs)
+	}
+
+	// One bit of a triplet in the first and last sections, plus an
+	// unprotected bit which cannot be checked
+	lastVCH := uint32(YSF_VD_MODE2_VCH_OFFSET + 4*YSF_VD_MODE2_SECTION_BITS)
+	bits.Flip(payload, YSF_VD_MODE2_VCH_OFFSET+INTERLEAVE_TABLE_26_4[4])
+	bits.Flip(payload, lastVCH+INTERLEAVE_TABLE_26_4[80])
+	bits.Flip(payload, lastVCH+INTERLEAVE_TABLE_26_4[90])
+	if errs := CountYSFVDMode2Errors(payload); errs != 2 {
+		t.Errorf("%d errors, want 2", errs)
+	}
+
+	if errs := CountYSFVDMode2Errors(payload[:10]); errs != 0 {
+		t.Errorf("short payload: %d errors, want 0", errs)
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Builder constructs a configuration in code, for tests and programs
+// embedding the gateway
+// Keys are set by section and name exactly as in the INI file and start from
+// the same defaults; Load reads files through the same path.
+type Builder struct {
+	config *Config
+	err    error
+}
+
+// NewBuilder starts a configuration from the defaults, with no file
+func NewBuilder() *Builder {
+	return &Builder{config: NewConfig("")}
+}
+
+// Set sets key in section, as the line key=value under [section] would
+// An unknown section or key makes Build fail; an invalid value is ignored,
+// keeping the default, as it is in a file.
+func (b *Builder) Set(section, key, value string) *Builder {
+	if b.err == nil && !b.config.set(section, key, value) {
+		b.err = fmt.Errorf("unknown configuration key [%s] %s", section, key)
+	}
+	return b
+}
+
+// SetInt sets a numeric key
+func (b *Builder) SetInt(section, key string, value int64) *Builder {
+	return b.Set(section, key, strconv.FormatInt(value, 10))
+}
+
+// SetBool sets an on/off key
+func (b *Builder) SetBool(section, key string, value bool) *Builder {
+	if value {
+		return b.Set(section, key, "1")
+	}
+	return b.Set(section, key, "0")
+}
+
+// Build returns the configuration, or the first unknown key
+func (b *Builder) Build() (*Config, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.config, nil
+}
@@ -8,6 +8,13 @@ import (
 	"strings"
 )
 
+// Accepted range of the FramePeriod options in ms; values outside it keep the
+// default (100 for YSF, 60 for DMR)
+const (
+	minFramePeriod = 20
+	maxFramePeriod = 1000
+)
+
 // Config represents the YSF2DMR configuration
 type Config struct {
 	filename string
@@ -22,18 +29,38 @@ type Config struct {
 	location    string
 	description string
 	url         string
+	repeaterID  uint32 // WiresX repeater ID, 0 derives it from the callsign
+	language    string // WiresX text, call summaries and voice prompts
 
 	// YSF Network section
 	callsign        string
 	suffix          string
 	dstAddress      string
 	dstPort         uint32
+	secondaryDstAddress string // failover destination, empty for none
+	secondaryDstPort    uint32 // 0 uses dstPort
 	localAddress    string
 	localPort       uint32
 	enableWiresX    bool
 	remoteGateway   bool
 	hangTime        uint32
+	rfHangTime      uint32 // after a YSF->DMR call; HangTime unless set
+	netHangTime     uint32 // after a DMR->YSF call; HangTime unless set
+	rfHangTimeSet   bool
+	netHangTimeSet  bool
+	rfTXInhibit     uint32 // ms after a YSF->DMR call before a DMR->YSF call may start
+	netTXInhibit    uint32 // ms after a DMR->YSF call before a YSF->DMR call may start
+	maxCallDuration uint32 // seconds before a call is cut off, 0 disables
+	interruptPolicy string   // immediate, block or after: a call from the other side during a call
+	interruptAfter  uint32   // seconds a call runs before the after policy lets it be interrupted
+	priorityTGs     []uint32 // DMR talk groups whose calls always interrupt
+	priorityIDs     []uint32 // DMR IDs whose calls always interrupt
+	timeoutPrompt   string // AMBE file played toward YSF after a cut-off
 	wiresXMakeUpper bool
+	wiresXUserSearch string // WiresX search prefix that selects a DMR user search
+	wiresXTGPrefix   string // shown before talk group IDs in WiresX replies
+	wiresXDescription string // shown for talk groups without a TG list description, empty for the Language default
+	nodeModule       string // module letter added to the WiresX node name, or XLX
 	fichCallSign    uint8
 	fichCallMode    uint8
 	fichFrameTotal  uint8
@@ -45,6 +72,19 @@ type Config struct {
 	ysfDT1          []uint8
 	ysfDT2          []uint8
 	ysfRadioID      string
+	ysfRSSI         uint8 // reported to the DMR master as -dBm, 0 = not reported
+	ysfNormalizeCallsign string // callsign clean-up steps for YSF radios
+	ysfQualityReport     uint32 // percent; DMR->YSF calls rated below it are reported, 0 = off
+	ysfPassthrough       bool   // repack VD mode 2 voice without the conversion pipeline
+	ysfQualityProfile    string // strict, normal or lenient
+	ysfQualityChange     float64 // profile overrides, 0 = profile value
+	ysfQualitySilence    float64
+	ysfQualityNoise      float64
+	ysfFramePeriod       uint32 // ms between YSF frames sent to the reflector
+	ysfAllowedPeers      string // addresses, CIDR ranges and hosts allowed to send YSF traffic
+	ysfPeerSecret        string // shared secret peers must authenticate with
+	ysfTrackPeer         bool   // follow the destination peer's polls across NAT rebinds
+	ysfDataTimeout       uint32 // seconds without YSFD before the link is degraded, 0 disables
 	daemon          bool
 	ysfDebug        bool
 
@@ -55,14 +95,39 @@ type Config struct {
 	dmrXLXReflector        uint32
 	dmrDstId               uint32
 	dmrPC                  bool
+	dmrRestoreDst          bool   // restore the last selected destination at startup
+	dmrStateFile           string // where the selected destination is kept
+	dmrDataTimeout         uint32 // seconds without DMRD before the link is degraded, 0 disables
+	dmrColorCode           uint8
+	dmrSyncSource          string // BS or MS sourced sync in generated bursts
 	dmrNetworkAddress      string
 	dmrNetworkPort         uint32
 	dmrNetworkLocal        uint32
+	dmrBindAddress         string // IP address or interface name, empty for any
+	dmrProxy               string // socks5:// URL relaying the master's traffic
 	dmrNetworkPassword     string
+	dmrNetworkProtocol     string
 	dmrNetworkOptions      string
+	dmrMasterType          string // builds Options from the keys below when Options is empty
+	dmrGatewayTGOffset     uint32 // DMRGateway TG rewrite offset of our network
+	dmrTGRewrites          []string // TGRewrite rules, in the order read
+	dmrPCRewrites          []string // PCRewrite rules, in the order read
+	dmrStaticTGs           string
+	dmrMutedTGs            []uint32 // talk groups received from the master but never converted to YSF
+	dmrDial                uint32
+	dmrVoice               bool
+	dmrLang                string
+	dmrTimer               uint32
+	dmrSingle              bool
+	dmrOutputQueue         uint32 // frames held while the DMR network reconnects
+	dmrOutputMaxAge        uint32 // ms
+	dmrOutputDropPolicy    string
+	dmrOutputAbort         bool
+	dmrNormalizeCallsign   string // callsign clean-up steps for looked-up DMR users
 	dmrNetworkDebug        bool
 	dmrNetworkJitterEnabled bool
 	dmrNetworkJitter       uint32
+	dmrFramePeriod         uint32 // ms between DMR bursts sent to the master
 	dmrNetworkEnableUnlink bool
 	dmrNetworkIDUnlink     uint32
 	dmrNetworkPCUnlink     bool
@@ -72,13 +137,110 @@ type Config struct {
 	dmrIdLookupFile string
 	dmrIdLookupTime uint32
 	dmrDropUnknown  bool
+	dmrHotspotIDs   bool // 9-digit hotspot IDs resolve to the 7-digit user
 
 	// Database section (for modern database-backed DMR ID lookup)
 	databaseEnabled    bool
+	databaseDriver     string
 	databasePath       string
+	databaseDSN        string
 	databaseSyncHours  uint32
 	databaseCacheSize  uint32
+	databaseSnapshot   bool
+	databaseSyncAlertFailures uint32
+	databaseSyncFilter        string
+	databaseOnDemandLookup    bool
 	databaseDebug      bool
+	databaseMaintenanceHours uint32
+	databaseBackupDir        string
+	databaseBackupKeep       uint32
+	databaseCallHistoryDays  uint32
+
+	// Recording section
+	recordingEnabled       bool
+	recordingDirectory     string
+	recordingMaxAgeDays    uint32
+	recordingMaxSizeMB     uint32
+	recordingTranscoder    string
+	recordingTranscoderArg string
+
+	// Capture section
+	captureEnabled        bool
+	captureDirectory      string
+	captureSeconds        uint32
+	captureErrorThreshold uint32 // conversion errors within captureSeconds that trigger a dump
+
+	// Fast Data section
+	fastDataEnabled   bool
+	fastDataDirectory string
+	fastDataMaxFiles  uint32
+
+	// Hooks section
+	hookCallStart     string
+	hookCallEnd       string
+	hookLinkUp        string
+	hookLinkDown      string
+	hookEmergency     string
+	hookTimeout       uint32
+	hookMaxConcurrent uint32
+
+	// HTTP section
+	httpEnabled         bool
+	httpAddress         string
+	httpLivenessTimeout uint32 // seconds without main loop progress before /healthz fails
+
+	// Hosts section (downloaded host and TG files)
+	hostsDMRURL    string
+	hostsDMRFile   string
+	hostsXLXURL    string
+	hostsTGListURL string
+	hostsInterval  uint32 // hours
+
+	// Beacon section
+	beaconEnabled  bool
+	beaconInterval uint32 // seconds, 0 = only when the DMR master asks
+	beaconText     string
+	beaconVoice    string // AMBE file in the recorder's format
+
+	// Maintenance section (daily window with bridging paused)
+	maintenanceEnabled  bool
+	maintenanceStart    string // HH:MM local time
+	maintenanceDuration uint32 // minutes
+	maintenanceDays     string // e.g. "Sun,Wed", every day when empty
+	maintenanceUnlink   bool
+	maintenanceText     string
+	maintenanceVoice    string // AMBE file in the recorder's format
+
+	// Commands section (DTMF-style commands from YSF radios without WiresX)
+	commandsEnabled    bool
+	commandConnect     string
+	commandPrivate     string
+	commandDisconnect  string
+	commandSelect      string
+	commandTerminator  string
+	commandPresets     []uint32 // talk groups picked by Select, from 1
+
+	// Audio section (level metering and gain through a transcoder)
+	audioTranscoder    string
+	audioTranscoderArg string
+	audioYSFGain       float64 // dB applied to YSF->DMR audio
+	audioDMRGain       float64 // dB applied to DMR->YSF audio
+	audioYSFAGC        bool
+	audioDMRAGC        bool
+	audioAGCTarget     float64 // dBFS RMS the AGC aims for
+	audioAGCMaxGain    float64 // dB the AGC may boost or cut
+
+	// USRP section (PCM audio toward Analog_Bridge or AllStar)
+	usrpEnabled       bool
+	usrpAddress       string
+	usrpPort          uint32 // Analog_Bridge rxPort
+	usrpLocalPort     uint32 // Analog_Bridge txPort
+	usrpTranscoder    string
+	usrpTranscoderArg string
+
+	// Blocklist section (comma separated entries)
+	blockRadioIDs string // YSF radio IDs refused
+	allowRadioIDs string // when set, the only YSF radio IDs accepted
 
 	// Log section
 	logDisplayLevel uint32
@@ -87,14 +249,16 @@ type Config struct {
 	logFileRoot     string
 
 	// APRS section
-	aprsEnabled     bool
-	aprsServer      string
-	aprsPort        uint32
-	aprsPassword    string
-	aprsCallsign    string
-	aprsAPIKey      string
-	aprsRefresh     uint32
-	aprsDescription string
+	aprsEnabled      bool
+	aprsServer       string
+	aprsPort         uint32
+	aprsPassword     string
+	aprsCallsign     string
+	aprsAPIKey       string
+	aprsRefresh      uint32
+	aprsDescription  string
+	aprsDMRPositions bool   // forward positions reported by DMR radios
+	aprsDMRSSID      uint32 // SSID added to their callsigns
 }
 
 // NewConfig creates a new configuration instance
@@ -105,21 +269,89 @@ func NewConfig(filename string) *Config {
 		dstPort:         42000,
 		localPort:       42013,
 		hangTime:        1000,
+		wiresXUserSearch: "*",
+		interruptPolicy:  "immediate",
+		wiresXTGPrefix:   "TG",
+		language:         "en",
+		fichCallSign:     2,
+		fichFrameTotal:   7,
+		fichDataType:     2,
 		dmrNetworkPort:  62031,
 		dmrNetworkJitter: 500,
+		ysfFramePeriod:  100,
+		dmrFramePeriod:  60,
+		dmrColorCode:    1,
+		dmrSyncSource:   "BS",
+		dmrOutputMaxAge: 500,
+		dmrOutputDropPolicy: "oldest",
+		ysfNormalizeCallsign: "upper,suffix,validate",
+		ysfQualityProfile:    "normal",
+		dmrNormalizeCallsign: "upper,suffix,validate",
+		dmrNetworkProtocol: "homebrew",
 		dmrIdLookupTime: 24,
+		dmrRestoreDst:   true,
+		dmrStateFile:    "ysf2dmr-state.json",
+		dmrHotspotIDs:   true,
 		aprsPort:        14580,
+		hostsDMRFile:    "DMR_Hosts.txt",
+		hostsInterval:   24,
 		aprsRefresh:     240,
+		aprsDMRSSID:     7,
+		audioAGCTarget:  -20,
+		usrpAddress:     "127.0.0.1",
+		usrpPort:        34001,
+		usrpLocalPort:   32001,
+		usrpTranscoder:  "md380-emu",
+		maintenanceStart:    "03:00",
+		maintenanceDuration: 30,
+		maintenanceUnlink:   true,
+		commandConnect:    "*",
+		commandPrivate:    "**",
+		commandDisconnect: "##",
+		commandSelect:     "#",
+		commandTerminator: "#",
+		audioAGCMaxGain: 12,
 
 		// Database defaults
 		databaseEnabled:   false, // Disabled by default for backward compatibility
+		databaseDriver:    "sqlite",
 		databasePath:      "data/dmr_users.db",
 		databaseSyncHours: 24, // Sync every 24 hours
 		databaseCacheSize: 1000,
+		databaseSnapshot:  true,
+		databaseSyncAlertFailures: 3,
 		databaseDebug:     false,
+		databaseMaintenanceHours: 24,
+		databaseBackupKeep:       7,
+		databaseCallHistoryDays:  90,
+
+		// Recording defaults
+		recordingDirectory:  "recordings",
+		recordingMaxAgeDays: 30,
+		recordingMaxSizeMB:  1024,
+
+		// Capture defaults
+		captureDirectory:      "captures",
+		captureSeconds:        10,
+		captureErrorThreshold: 3,
+
+		// Fast data defaults
+		fastDataDirectory: "fastdata",
+		fastDataMaxFiles:  100,
+
+		// Hook defaults
+		hookTimeout:       10,
+		hookMaxConcurrent: 4,
+
+		// HTTP defaults
+		httpAddress:         "127.0.0.1:8080",
+		httpLivenessTimeout: 10,
 	}
 }
 
+// GetFilename returns the configuration file path
+func (c *Config) GetFilename() string { return c.filename }
+
 // Load loads configuration from the specified file
 func (c *Config) Load() error {
 	file, err := os.Open(c.filename)
@@ -172,29 +404,60 @@ func (c *Config) parseINIScanner(scanner *bufio.Scanner) error {
 		key := strings.TrimSpace(parts[0])
 		value := strings.TrimSpace(parts[1])
 
-		// Parse based on current section
-		switch currentSection {
-		case "Info":
-			c.parseInfoSection(key, value)
-		case "YSF Network":
-			c.parseYSFNetworkSection(key, value)
-		case "DMR Network":
-			c.parseDMRNetworkSection(key, value)
-		case "DMR Id Lookup":
-			c.parseDMRIdLookupSection(key, value)
-		case "Database":
-			c.parseDatabaseSection(key, value)
-		case "Log":
-			c.parseLogSection(key, value)
-		case "aprs.fi":
-			c.parseAPRSSection(key, value)
-		}
+		c.set(currentSection, key, value)
 	}
 
 	return scanner.Err()
 }
 
-func (c *Config) parseInfoSection(key, value string) {
+// set applies the value of key in section, as read from the INI file
+// It reports false for unknown sections and keys; invalid values are ignored
+// and keep the current value.
+func (c *Config) set(section, key, value string) bool {
+	switch section {
+	case "Info":
+		return c.parseInfoSection(key, value)
+	case "YSF Network":
+		return c.parseYSFNetworkSection(key, value)
+	case "DMR Network":
+		return c.parseDMRNetworkSection(key, value)
+	case "DMR Id Lookup":
+		return c.parseDMRIdLookupSection(key, value)
+	case "Database":
+		return c.parseDatabaseSection(key, value)
+	case "Recording":
+		return c.parseRecordingSection(key, value)
+	case "Capture":
+		return c.parseCaptureSection(key, value)
+	case "Fast Data":
+		return c.parseFastDataSection(key, value)
+	case "Hooks":
+		return c.parseHooksSection(key, value)
+	case "HTTP":
+		return c.parseHTTPSection(key, value)
+	case "Hosts":
+		return c.parseHostsSection(key, value)
+	case "Beacon":
+		return c.parseBeaconSection(key, value)
+	case "Audio":
+		return c.parseAudioSection(key, value)
+	case "USRP":
+		return c.parseUSRPSection(key, value)
+	case "Commands":
+		return c.parseCommandsSection(key, value)
+	case "Maintenance":
+		return c.parseMaintenanceSection(key, value)
+	case "Blocklist":
+		return c.parseBlocklistSection(key, value)
+	case "Log":
+		return c.parseLogSection(key, value)
+	case "aprs.fi":
+		return c.parseAPRSSection(key, value)
+	}
+	return false
+}
+
+func (c *Config) parseInfoSection(key, value string) bool {
 	switch key {
 	case "RXFrequency":
 		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
@@ -226,10 +489,19 @@ func (c *Config) parseInfoSection(key, value string) {
 		c.description = value
 	case "URL":
 		c.url = value
+	case "Id":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil && v <= 99999 {
+			c.repeaterID = uint32(v)
+		}
+	case "Language":
+		c.language = value
+	default:
+		return false
 	}
+	return true
 }
 
-func (c *Config) parseYSFNetworkSection(key, value string) {
+func (c *Config) parseYSFNetworkSection(key, value string) bool {
 	switch key {
 	case "Callsign":
 		c.callsign = value
@@ -241,7 +513,13 @@ func (c *Config) parseYSFNetworkSection(key, value string) {
 		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
 			c.dstPort = uint32(v)
 		}
-	case "LocalAddress":
+	case "SecondaryDstAddress":
+		c.secondaryDstAddress = value
+	case "SecondaryDstPort":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.secondaryDstPort = uint32(v)
+		}
+	case "LocalAddress", "BindAddress": // BindAddress matches the DMR key
 		c.localAddress = value
 	case "LocalPort":
 		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
@@ -251,12 +529,104 @@ func (c *Config) parseYSFNetworkSection(key, value string) {
 		c.enableWiresX = c.parseBool(value)
 	case "RemoteGateway":
 		c.remoteGateway = c.parseBool(value)
+	case "NormalizeCallsign":
+		c.ysfNormalizeCallsign = value
+	case "QualityReport":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil && v <= 100 {
+			c.ysfQualityReport = uint32(v)
+		}
+	case "Passthrough":
+		c.ysfPassthrough = c.parseBool(value)
+	case "QualityProfile":
+		// Anything other than strict, normal or lenient is ignored
+		if profile := strings.ToLower(value); profile == "strict" || profile == "normal" || profile == "lenient" {
+			c.ysfQualityProfile = profile
+		}
+	case "QualityChange":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v >= 0 && v <= 1 {
+			c.ysfQualityChange = v
+		}
+	case "QualitySilence":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v >= 0 && v <= 1 {
+			c.ysfQualitySilence = v
+		}
+	case "QualityNoise":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v >= 0 && v <= 1 {
+			c.ysfQualityNoise = v
+		}
+	case "RSSI":
+		// Accept "-75" or "75" for -75 dBm
+		if v, err := strconv.ParseInt(strings.TrimPrefix(value, "-"), 10, 32); err == nil && v >= 0 && v <= 255 {
+			c.ysfRSSI = uint8(v)
+		}
+	case "FramePeriod":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil && v >= minFramePeriod && v <= maxFramePeriod {
+			c.ysfFramePeriod = uint32(v)
+		}
+	case "AllowedPeers":
+		c.ysfAllowedPeers = value
+	case "PeerSecret":
+		c.ysfPeerSecret = value
+	case "TrackPeer":
+		c.ysfTrackPeer = c.parseBool(value)
+	case "DataTimeout":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.ysfDataTimeout = uint32(v)
+		}
 	case "HangTime":
 		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
 			c.hangTime = uint32(v)
 		}
+	case "RFHangTime":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.rfHangTime = uint32(v)
+			c.rfHangTimeSet = true
+		}
+	case "NetHangTime":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.netHangTime = uint32(v)
+			c.netHangTimeSet = true
+		}
+	case "RFTXInhibit":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.rfTXInhibit = uint32(v)
+		}
+	case "NetTXInhibit":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.netTXInhibit = uint32(v)
+		}
+	case "MaxCallDuration":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.maxCallDuration = uint32(v)
+		}
+	case "InterruptPolicy":
+		switch policy := strings.ToLower(value); policy {
+		case "immediate", "block", "after":
+			c.interruptPolicy = policy
+		}
+	case "InterruptAfter":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.interruptAfter = uint32(v)
+		}
+	case "PriorityTGs":
+		c.priorityTGs = c.parseIDList(value)
+	case "PriorityIDs":
+		c.priorityIDs = c.parseIDList(value)
+	case "TimeoutPrompt":
+		c.timeoutPrompt = value
 	case "WiresXMakeUpper":
 		c.wiresXMakeUpper = c.parseBool(value)
+	case "WiresXUserSearch":
+		c.wiresXUserSearch = value
+	case "WiresXTGPrefix":
+		c.wiresXTGPrefix = value
+	case "WiresXDescription":
+		c.wiresXDescription = value
+	case "NodeModule":
+		module := strings.ToUpper(value)
+		if module == "" || module == "XLX" || (len(module) == 1 && module[0] >= 'A' && module[0] <= 'Z') {
+			c.nodeModule = module
+		}
 	case "FICHCallsign":
 		if v, err := strconv.ParseUint(value, 10, 8); err == nil {
 			c.fichCallSign = uint8(v)
@@ -299,10 +669,13 @@ func (c *Config) parseYSFNetworkSection(key, value string) {
 		c.daemon = c.parseBool(value)
 	case "Debug":
 		c.ysfDebug = c.parseBool(value)
+	default:
+		return false
 	}
+	return true
 }
 
-func (c *Config) parseDMRNetworkSection(key, value string) {
+func (c *Config) parseDMRNetworkSection(key, value string) bool {
 	switch key {
 	case "Id":
 		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
@@ -322,6 +695,26 @@ func (c *Config) parseDMRNetworkSection(key, value string) {
 		}
 	case "StartupPC":
 		c.dmrPC = c.parseBool(value)
+	case "RestoreDestination":
+		c.dmrRestoreDst = c.parseBool(value)
+	case "StateFile":
+		if value != "" {
+			c.dmrStateFile = value
+		}
+	case "DataTimeout":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.dmrDataTimeout = uint32(v)
+		}
+	case "ColorCode":
+		// Values outside 0-15 are ignored
+		if v, err := strconv.ParseUint(value, 10, 8); err == nil && v <= 15 {
+			c.dmrColorCode = uint8(v)
+		}
+	case "SyncSource":
+		// Values other than BS and MS are ignored
+		if v := strings.ToUpper(value); v == "BS" || v == "MS" {
+			c.dmrSyncSource = v
+		}
 	case "Address":
 		c.dmrNetworkAddress = value
 	case "Port":
@@ -332,16 +725,67 @@ func (c *Config) parseDMRNetworkSection(key, value string) {
 		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
 			c.dmrNetworkLocal = uint32(v)
 		}
+	case "BindAddress":
+		c.dmrBindAddress = value
+	case "Proxy":
+		c.dmrProxy = value
 	case "Password":
 		c.dmrNetworkPassword = value
+	case "Protocol":
+		c.dmrNetworkProtocol = strings.ToLower(value)
 	case "Options":
 		c.dmrNetworkOptions = value
+	case "MasterType", "MasterFlavor":
+		c.dmrMasterType = strings.ToLower(value)
+	case "GatewayTGOffset":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.dmrGatewayTGOffset = uint32(v)
+		}
+	case "StaticTGs":
+		c.dmrStaticTGs = value
+	case "MutedTGs":
+		c.dmrMutedTGs = c.parseIDList(value)
+	case "Dial":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.dmrDial = uint32(v)
+		}
+	case "Voice":
+		c.dmrVoice = c.parseBool(value)
+	case "Lang":
+		c.dmrLang = value
+	case "Timer":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.dmrTimer = uint32(v)
+		}
+	case "Single":
+		c.dmrSingle = c.parseBool(value)
+	case "OutputQueue":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.dmrOutputQueue = uint32(v)
+		}
+	case "OutputMaxAge":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.dmrOutputMaxAge = uint32(v)
+		}
+	case "OutputDropPolicy":
+		// Anything other than oldest or newest is ignored
+		if policy := strings.ToLower(value); policy == "oldest" || policy == "newest" {
+			c.dmrOutputDropPolicy = policy
+		}
+	case "OutputAbort":
+		c.dmrOutputAbort = c.parseBool(value)
+	case "NormalizeCallsign":
+		c.dmrNormalizeCallsign = value
 	case "Debug":
 		c.dmrNetworkDebug = c.parseBool(value)
 	case "Jitter":
 		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
 			c.dmrNetworkJitter = uint32(v)
 		}
+	case "FramePeriod":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil && v >= minFramePeriod && v <= maxFramePeriod {
+			c.dmrFramePeriod = uint32(v)
+		}
 	case "EnableUnlink":
 		c.dmrNetworkEnableUnlink = c.parseBool(value)
 	case "TGUnlink":
@@ -352,10 +796,22 @@ func (c *Config) parseDMRNetworkSection(key, value string) {
 		c.dmrNetworkPCUnlink = c.parseBool(value)
 	case "TGListFile":
 		c.dmrTGListFile = value
+	default:
+		// Rewrite rules may be numbered (TGRewrite0=, TGRewrite1=) as in
+		// DMRGateway and are kept in order
+		switch {
+		case strings.HasPrefix(key, "TGRewrite"):
+			c.dmrTGRewrites = append(c.dmrTGRewrites, value)
+		case strings.HasPrefix(key, "PCRewrite"):
+			c.dmrPCRewrites = append(c.dmrPCRewrites, value)
+		default:
+			return false
+		}
 	}
+	return true
 }
 
-func (c *Config) parseDMRIdLookupSection(key, value string) {
+func (c *Config) parseDMRIdLookupSection(key, value string) bool {
 	switch key {
 	case "File":
 		c.dmrIdLookupFile = value
@@ -365,15 +821,24 @@ func (c *Config) parseDMRIdLookupSection(key, value string) {
 		}
 	case "DropUnknown":
 		c.dmrDropUnknown = c.parseBool(value)
+	case "HotspotIDs":
+		c.dmrHotspotIDs = c.parseBool(value)
+	default:
+		return false
 	}
+	return true
 }
 
-func (c *Config) parseDatabaseSection(key, value string) {
+func (c *Config) parseDatabaseSection(key, value string) bool {
 	switch key {
 	case "Enabled":
 		c.databaseEnabled = c.parseBool(value)
+	case "Driver":
+		c.databaseDriver = strings.ToLower(value)
 	case "Path":
 		c.databasePath = value
+	case "DSN":
+		c.databaseDSN = value
 	case "SyncHours":
 		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
 			c.databaseSyncHours = uint32(v)
@@ -384,10 +849,291 @@ func (c *Config) parseDatabaseSection(key, value string) {
 		}
 	case "Debug":
 		c.databaseDebug = c.parseBool(value)
+	case "Snapshot":
+		c.databaseSnapshot = c.parseBool(value)
+	case "SyncAlertFailures":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.databaseSyncAlertFailures = uint32(v)
+		}
+	case "SyncFilter":
+		c.databaseSyncFilter = value
+	case "OnDemandLookup":
+		c.databaseOnDemandLookup = c.parseBool(value)
+	case "MaintenanceHours":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.databaseMaintenanceHours = uint32(v)
+		}
+	case "BackupDir":
+		c.databaseBackupDir = value
+	case "BackupKeep":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.databaseBackupKeep = uint32(v)
+		}
+	case "CallHistoryDays":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.databaseCallHistoryDays = uint32(v)
+		}
+	default:
+		return false
 	}
+	return true
 }
 
-func (c *Config) parseLogSection(key, value string) {
+func (c *Config) parseRecordingSection(key, value string) bool {
+	switch key {
+	case "Enable":
+		c.recordingEnabled = c.parseBool(value)
+	case "Directory":
+		c.recordingDirectory = value
+	case "MaxAgeDays":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.recordingMaxAgeDays = uint32(v)
+		}
+	case "MaxSizeMB":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.recordingMaxSizeMB = uint32(v)
+		}
+	case "Transcoder":
+		c.recordingTranscoder = value
+	case "TranscoderArg":
+		c.recordingTranscoderArg = value
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *Config) parseCaptureSection(key, value string) bool {
+	switch key {
+	case "Enable":
+		c.captureEnabled = c.parseBool(value)
+	case "Directory":
+		c.captureDirectory = value
+	case "Seconds":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil && v > 0 {
+			c.captureSeconds = uint32(v)
+		}
+	case "ErrorThreshold":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil && v > 0 {
+			c.captureErrorThreshold = uint32(v)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *Config) parseFastDataSection(key, value string) bool {
+	switch key {
+	case "Enable":
+		c.fastDataEnabled = c.parseBool(value)
+	case "Directory":
+		c.fastDataDirectory = value
+	case "MaxFiles":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil && v > 0 {
+			c.fastDataMaxFiles = uint32(v)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *Config) parseHooksSection(key, value string) bool {
+	switch key {
+	case "CallStart":
+		c.hookCallStart = value
+	case "CallEnd":
+		c.hookCallEnd = value
+	case "LinkUp":
+		c.hookLinkUp = value
+	case "LinkDown":
+		c.hookLinkDown = value
+	case "Emergency":
+		c.hookEmergency = value
+	case "Timeout":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.hookTimeout = uint32(v)
+		}
+	case "MaxConcurrent":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.hookMaxConcurrent = uint32(v)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *Config) parseHTTPSection(key, value string) bool {
+	switch key {
+	case "Enable":
+		c.httpEnabled = c.parseBool(value)
+	case "Address":
+		c.httpAddress = value
+	case "LivenessTimeout":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil && v > 0 {
+			c.httpLivenessTimeout = uint32(v)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *Config) parseHostsSection(key, value string) bool {
+	switch key {
+	case "DMRHostsURL":
+		c.hostsDMRURL = value
+	case "DMRHostsFile":
+		c.hostsDMRFile = value
+	case "XLXHostsURL":
+		c.hostsXLXURL = value
+	case "TGListURL":
+		c.hostsTGListURL = value
+	case "Interval":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil && v > 0 {
+			c.hostsInterval = uint32(v)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *Config) parseBlocklistSection(key, value string) bool {
+	switch key {
+	case "RadioIDs":
+		c.blockRadioIDs = value
+	case "AllowRadioIDs":
+		c.allowRadioIDs = value
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *Config) parseBeaconSection(key, value string) bool {
+	switch key {
+	case "Enable":
+		c.beaconEnabled = c.parseBool(value)
+	case "Interval":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.beaconInterval = uint32(v)
+		}
+	case "Text":
+		c.beaconText = value
+	case "Voice":
+		c.beaconVoice = value
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *Config) parseMaintenanceSection(key, value string) bool {
+	switch key {
+	case "Enable":
+		c.maintenanceEnabled = c.parseBool(value)
+	case "Start":
+		c.maintenanceStart = value
+	case "Duration":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
+			c.maintenanceDuration = uint32(v)
+		}
+	case "Days":
+		c.maintenanceDays = value
+	case "Unlink":
+		c.maintenanceUnlink = c.parseBool(value)
+	case "Text":
+		c.maintenanceText = value
+	case "Voice":
+		c.maintenanceVoice = value
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *Config) parseCommandsSection(key, value string) bool {
+	switch key {
+	case "Enable":
+		c.commandsEnabled = c.parseBool(value)
+	case "Connect":
+		c.commandConnect = value
+	case "Private":
+		c.commandPrivate = value
+	case "Disconnect":
+		c.commandDisconnect = value
+	case "Select":
+		c.commandSelect = value
+	case "Terminator":
+		c.commandTerminator = value
+	case "Presets":
+		c.commandPresets = c.parseIDList(value)
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *Config) parseAudioSection(key, value string) bool {
+	switch key {
+	case "Transcoder":
+		c.audioTranscoder = value
+	case "TranscoderArg":
+		c.audioTranscoderArg = value
+	case "YSFGain":
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			c.audioYSFGain = v
+		}
+	case "DMRGain":
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			c.audioDMRGain = v
+		}
+	case "YSFAGC":
+		c.audioYSFAGC = c.parseBool(value)
+	case "DMRAGC":
+		c.audioDMRAGC = c.parseBool(value)
+	case "AGCTarget":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v < 0 {
+			c.audioAGCTarget = v
+		}
+	case "AGCMaxGain":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v >= 0 {
+			c.audioAGCMaxGain = v
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *Config) parseUSRPSection(key, value string) bool {
+	switch key {
+	case "Enable":
+		c.usrpEnabled = c.parseBool(value)
+	case "Address":
+		c.usrpAddress = value
+	case "Port":
+		if v, err := strconv.ParseUint(value, 10, 16); err == nil {
+			c.usrpPort = uint32(v)
+		}
+	case "LocalPort":
+		if v, err := strconv.ParseUint(value, 10, 16); err == nil {
+			c.usrpLocalPort = uint32(v)
+		}
+	case "Transcoder":
+		c.usrpTranscoder = value
+	case "TranscoderArg":
+		c.usrpTranscoderArg = value
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *Config) parseLogSection(key, value string) bool {
 	switch key {
 	case "DisplayLevel":
 		if v, err := strconv.ParseUint(value, 10, 32); err == nil {
@@ -401,10 +1147,13 @@ func (c *Config) parseLogSection(key, value string) {
 		c.logFilePath = value
 	case "FileRoot":
 		c.logFileRoot = value
+	default:
+		return false
 	}
+	return true
 }
 
-func (c *Config) parseAPRSSection(key, value string) {
+func (c *Config) parseAPRSSection(key, value string) bool {
 	switch key {
 	case "Enable":
 		c.aprsEnabled = c.parseBool(value)
@@ -426,7 +1175,16 @@ func (c *Config) parseAPRSSection(key, value string) {
 		}
 	case "Description":
 		c.aprsDescription = value
+	case "DMRPositions":
+		c.aprsDMRPositions = c.parseBool(value)
+	case "DMRSSID":
+		if v, err := strconv.ParseUint(value, 10, 32); err == nil && v <= 15 {
+			c.aprsDMRSSID = uint32(v)
+		}
+	default:
+		return false
 	}
+	return true
 }
 
 func (c *Config) parseBool(value string) bool {
@@ -446,6 +1204,17 @@ func (c *Config) parseByteArray(value string) []uint8 {
 	return result
 }
 
+// parseIDList parses a comma separated list of DMR IDs or talk groups
+func (c *Config) parseIDList(value string) []uint32 {
+	var result []uint32
+	for _, part := range strings.Split(value, ",") {
+		if v, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32); err == nil {
+			result = append(result, uint32(v))
+		}
+	}
+	return result
+}
+
 // Getter methods for Info section
 func (c *Config) GetRxFrequency() uint32  { return c.rxFrequency }
 func (c *Config) GetTxFrequency() uint32  { return c.txFrequency }
@@ -456,18 +1225,33 @@ func (c *Config) GetHeight() int32        { return c.height }
 func (c *Config) GetLocation() string     { return c.location }
 func (c *Config) GetDescription() string  { return c.description }
 func (c *Config) GetURL() string          { return c.url }
+func (c *Config) GetRepeaterID() uint32   { return c.repeaterID }
+func (c *Config) GetLanguage() string     { return c.language }
 
 // Getter methods for YSF Network section
 func (c *Config) GetCallsign() string        { return c.callsign }
 func (c *Config) GetSuffix() string          { return c.suffix }
 func (c *Config) GetDstAddress() string      { return c.dstAddress }
 func (c *Config) GetDstPort() uint32         { return c.dstPort }
+func (c *Config) GetSecondaryDstAddress() string { return c.secondaryDstAddress }
 func (c *Config) GetLocalAddress() string    { return c.localAddress }
 func (c *Config) GetLocalPort() uint32       { return c.localPort }
 func (c *Config) GetEnableWiresX() bool      { return c.enableWiresX }
 func (c *Config) GetRemoteGateway() bool     { return c.remoteGateway }
 func (c *Config) GetHangTime() uint32        { return c.hangTime }
+func (c *Config) GetRFTXInhibit() uint32     { return c.rfTXInhibit }
+func (c *Config) GetNetTXInhibit() uint32    { return c.netTXInhibit }
+func (c *Config) GetMaxCallDuration() uint32 { return c.maxCallDuration }
+func (c *Config) GetInterruptPolicy() string { return c.interruptPolicy }
+func (c *Config) GetInterruptAfter() uint32  { return c.interruptAfter }
+func (c *Config) GetPriorityTGs() []uint32   { return c.priorityTGs }
+func (c *Config) GetPriorityIDs() []uint32   { return c.priorityIDs }
+func (c *Config) GetTimeoutPrompt() string   { return c.timeoutPrompt }
 func (c *Config) GetWiresXMakeUpper() bool   { return c.wiresXMakeUpper }
+func (c *Config) GetWiresXUserSearch() string { return c.wiresXUserSearch }
+func (c *Config) GetWiresXTGPrefix() string   { return c.wiresXTGPrefix }
+func (c *Config) GetWiresXDescription() string { return c.wiresXDescription }
+func (c *Config) GetNodeModule() string       { return c.nodeModule }
 func (c *Config) GetFICHCallSign() uint8     { return c.fichCallSign }
 func (c *Config) GetFICHCallMode() uint8     { return c.fichCallMode }
 func (c *Config) GetFICHFrameTotal() uint8   { return c.fichFrameTotal }
@@ -479,9 +1263,49 @@ func (c *Config) GetFICHSQLCode() uint8      { return c.fichSQLCode }
 func (c *Config) GetYsfDT1() []uint8         { return c.ysfDT1 }
 func (c *Config) GetYsfDT2() []uint8         { return c.ysfDT2 }
 func (c *Config) GetYsfRadioID() string      { return c.ysfRadioID }
+func (c *Config) GetYSFRSSI() uint8          { return c.ysfRSSI }
+func (c *Config) GetYSFNormalizeCallsign() string { return c.ysfNormalizeCallsign }
+func (c *Config) GetYSFQualityReport() uint32       { return c.ysfQualityReport }
+func (c *Config) GetYSFPassthrough() bool           { return c.ysfPassthrough }
+func (c *Config) GetYSFQualityProfile() string      { return c.ysfQualityProfile }
+func (c *Config) GetYSFQualityChange() float64      { return c.ysfQualityChange }
+func (c *Config) GetYSFQualitySilence() float64     { return c.ysfQualitySilence }
+func (c *Config) GetYSFQualityNoise() float64       { return c.ysfQualityNoise }
+func (c *Config) GetYSFFramePeriod() uint32         { return c.ysfFramePeriod }
+func (c *Config) GetYSFAllowedPeers() string        { return c.ysfAllowedPeers }
+func (c *Config) GetYSFPeerSecret() string          { return c.ysfPeerSecret }
+func (c *Config) GetYSFTrackPeer() bool             { return c.ysfTrackPeer }
+func (c *Config) GetYSFDataTimeout() uint32         { return c.ysfDataTimeout }
 func (c *Config) GetDaemon() bool            { return c.daemon }
 func (c *Config) GetYSFDebug() bool          { return c.ysfDebug }
 
+// GetSecondaryDstPort returns the failover destination's port, falling back
+// to DstPort when SecondaryDstPort is not set
+func (c *Config) GetSecondaryDstPort() uint32 {
+	if c.secondaryDstPort != 0 {
+		return c.secondaryDstPort
+	}
+	return c.dstPort
+}
+
+// GetRFHangTime returns the hang time in ms after a YSF->DMR call,
+// falling back to HangTime when RFHangTime is not set
+func (c *Config) GetRFHangTime() uint32 {
+	if c.rfHangTimeSet {
+		return c.rfHangTime
+	}
+	return c.hangTime
+}
+
+// GetNetHangTime returns the hang time in ms after a DMR->YSF call,
+// falling back to HangTime when NetHangTime is not set
+func (c *Config) GetNetHangTime() uint32 {
+	if c.netHangTimeSet {
+		return c.netHangTime
+	}
+	return c.hangTime
+}
+
 // Getter methods for DMR Network section
 func (c *Config) GetDMRId() uint32                   { return c.dmrId }
 func (c *Config) GetDMRXLXFile() string             { return c.dmrXLXFile }
@@ -489,14 +1313,39 @@ func (c *Config) GetDMRXLXModule() string           { return c.dmrXLXModule }
 func (c *Config) GetDMRXLXReflector() uint32        { return c.dmrXLXReflector }
 func (c *Config) GetDMRDstId() uint32               { return c.dmrDstId }
 func (c *Config) GetDMRPC() bool                    { return c.dmrPC }
+func (c *Config) GetDMRRestoreDestination() bool    { return c.dmrRestoreDst }
+func (c *Config) GetDMRStateFile() string           { return c.dmrStateFile }
+func (c *Config) GetDMRDataTimeout() uint32         { return c.dmrDataTimeout }
+func (c *Config) GetDMRColorCode() uint8            { return c.dmrColorCode }
+func (c *Config) GetDMRSyncSource() string          { return c.dmrSyncSource }
 func (c *Config) GetDMRNetworkAddress() string      { return c.dmrNetworkAddress }
 func (c *Config) GetDMRNetworkPort() uint32         { return c.dmrNetworkPort }
 func (c *Config) GetDMRNetworkLocal() uint32        { return c.dmrNetworkLocal }
+func (c *Config) GetDMRBindAddress() string         { return c.dmrBindAddress }
+func (c *Config) GetDMRProxy() string               { return c.dmrProxy }
 func (c *Config) GetDMRNetworkPassword() string     { return c.dmrNetworkPassword }
+func (c *Config) GetDMRNetworkProtocol() string     { return c.dmrNetworkProtocol }
 func (c *Config) GetDMRNetworkOptions() string      { return c.dmrNetworkOptions }
+func (c *Config) GetDMRMasterType() string          { return c.dmrMasterType }
+func (c *Config) GetDMRGatewayTGOffset() uint32     { return c.dmrGatewayTGOffset }
+func (c *Config) GetDMRTGRewrites() []string        { return c.dmrTGRewrites }
+func (c *Config) GetDMRPCRewrites() []string        { return c.dmrPCRewrites }
+func (c *Config) GetDMRStaticTGs() string           { return c.dmrStaticTGs }
+func (c *Config) GetDMRMutedTGs() []uint32          { return c.dmrMutedTGs }
+func (c *Config) GetDMRDial() uint32                { return c.dmrDial }
+func (c *Config) GetDMRVoice() bool                 { return c.dmrVoice }
+func (c *Config) GetDMRLang() string                { return c.dmrLang }
+func (c *Config) GetDMRTimer() uint32               { return c.dmrTimer }
+func (c *Config) GetDMRSingle() bool                { return c.dmrSingle }
+func (c *Config) GetDMROutputQueue() uint32         { return c.dmrOutputQueue }
+func (c *Config) GetDMROutputMaxAge() uint32        { return c.dmrOutputMaxAge }
+func (c *Config) GetDMROutputDropPolicy() string    { return c.dmrOutputDropPolicy }
+func (c *Config) GetDMROutputAbort() bool           { return c.dmrOutputAbort }
+func (c *Config) GetDMRNormalizeCallsign() string   { return c.dmrNormalizeCallsign }
 func (c *Config) GetDMRNetworkDebug() bool          { return c.dmrNetworkDebug }
 func (c *Config) GetDMRNetworkJitterEnabled() bool  { return c.dmrNetworkJitterEnabled }
 func (c *Config) GetDMRNetworkJitter() uint32       { return c.dmrNetworkJitter }
+func (c *Config) GetDMRFramePeriod() uint32         { return c.dmrFramePeriod }
 func (c *Config) GetDMRNetworkEnableUnlink() bool   { return c.dmrNetworkEnableUnlink }
 func (c *Config) GetDMRNetworkIDUnlink() uint32     { return c.dmrNetworkIDUnlink }
 func (c *Config) GetDMRNetworkPCUnlink() bool       { return c.dmrNetworkPCUnlink }
@@ -506,6 +1355,7 @@ func (c *Config) GetDMRTGListFile() string          { return c.dmrTGListFile }
 func (c *Config) GetDMRIdLookupFile() string { return c.dmrIdLookupFile }
 func (c *Config) GetDMRIdLookupTime() uint32 { return c.dmrIdLookupTime }
 func (c *Config) GetDMRDropUnknown() bool    { return c.dmrDropUnknown }
+func (c *Config) GetDMRHotspotIDs() bool     { return c.dmrHotspotIDs }
 
 // Getter methods for Log section
 func (c *Config) GetLogDisplayLevel() uint32 { return c.logDisplayLevel }
@@ -522,10 +1372,110 @@ func (c *Config) GetAPRSCallsign() string     { return c.aprsCallsign }
 func (c *Config) GetAPRSAPIKey() string       { return c.aprsAPIKey }
 func (c *Config) GetAPRSRefresh() uint32      { return c.aprsRefresh }
 func (c *Config) GetAPRSDescription() string  { return c.aprsDescription }
+func (c *Config) GetAPRSDMRPositions() bool   { return c.aprsDMRPositions }
+func (c *Config) GetAPRSDMRSSID() uint32      { return c.aprsDMRSSID }
 
 // Getter methods for Database section
 func (c *Config) GetDatabaseEnabled() bool    { return c.databaseEnabled }
+func (c *Config) GetDatabaseDriver() string   { return c.databaseDriver }
 func (c *Config) GetDatabasePath() string     { return c.databasePath }
+func (c *Config) GetDatabaseDSN() string      { return c.databaseDSN }
 func (c *Config) GetDatabaseSyncHours() uint32 { return c.databaseSyncHours }
 func (c *Config) GetDatabaseCacheSize() uint32 { return c.databaseCacheSize }
-func (c *Config) GetDatabaseDebug() bool      { return c.databaseDebug }
\ No newline at end of file
+func (c *Config) GetDatabaseDebug() bool      { return c.databaseDebug }
+func (c *Config) GetDatabaseSnapshot() bool   { return c.databaseSnapshot }
+func (c *Config) GetDatabaseSyncAlertFailures() uint32 { return c.databaseSyncAlertFailures }
+func (c *Config) GetDatabaseSyncFilter() string        { return c.databaseSyncFilter }
+func (c *Config) GetDatabaseOnDemandLookup() bool      { return c.databaseOnDemandLookup }
+func (c *Config) GetDatabaseMaintenanceHours() uint32 { return c.databaseMaintenanceHours }
+func (c *Config) GetDatabaseBackupDir() string        { return c.databaseBackupDir }
+func (c *Config) GetDatabaseBackupKeep() uint32       { return c.databaseBackupKeep }
+func (c *Config) GetDatabaseCallHistoryDays() uint32  { return c.databaseCallHistoryDays }
+
+// Getter methods for Recording section
+func (c *Config) GetRecordingEnabled() bool          { return c.recordingEnabled }
+func (c *Config) GetRecordingDirectory() string      { return c.recordingDirectory }
+func (c *Config) GetRecordingMaxAgeDays() uint32     { return c.recordingMaxAgeDays }
+func (c *Config) GetRecordingMaxSizeMB() uint32      { return c.recordingMaxSizeMB }
+func (c *Config) GetRecordingTranscoder() string     { return c.recordingTranscoder }
+func (c *Config) GetRecordingTranscoderArg() string  { return c.recordingTranscoderArg }
+
+// Getter methods for Capture section
+func (c *Config) GetCaptureEnabled() bool          { return c.captureEnabled }
+func (c *Config) GetCaptureDirectory() string      { return c.captureDirectory }
+func (c *Config) GetCaptureSeconds() uint32        { return c.captureSeconds }
+func (c *Config) GetCaptureErrorThreshold() uint32 { return c.captureErrorThreshold }
+
+// Getter methods for Fast Data section
+func (c *Config) GetFastDataEnabled() bool     { return c.fastDataEnabled }
+func (c *Config) GetFastDataDirectory() string { return c.fastDataDirectory }
+func (c *Config) GetFastDataMaxFiles() uint32  { return c.fastDataMaxFiles }
+
+// Getter methods for Hooks section
+func (c *Config) GetHookCallStart() string      { return c.hookCallStart }
+func (c *Config) GetHookCallEnd() string        { return c.hookCallEnd }
+func (c *Config) GetHookLinkUp() string         { return c.hookLinkUp }
+func (c *Config) GetHookLinkDown() string       { return c.hookLinkDown }
+func (c *Config) GetHookEmergency() string      { return c.hookEmergency }
+func (c *Config) GetHookTimeout() uint32        { return c.hookTimeout }
+func (c *Config) GetHookMaxConcurrent() uint32  { return c.hookMaxConcurrent }
+
+// Getter methods for HTTP section
+func (c *Config) GetHTTPEnabled() bool           { return c.httpEnabled }
+func (c *Config) GetHTTPAddress() string         { return c.httpAddress }
+func (c *Config) GetHTTPLivenessTimeout() uint32 { return c.httpLivenessTimeout }
+
+// Getter methods for Hosts section
+// The XLX host file and TG list are downloaded to the [DMR Network] XLXFile
+// and TGListFile paths.
+func (c *Config) GetHostsDMRURL() string    { return c.hostsDMRURL }
+func (c *Config) GetHostsDMRFile() string   { return c.hostsDMRFile }
+func (c *Config) GetHostsXLXURL() string    { return c.hostsXLXURL }
+func (c *Config) GetHostsTGListURL() string { return c.hostsTGListURL }
+func (c *Config) GetHostsInterval() uint32  { return c.hostsInterval }
+
+// Getter methods for Beacon section
+func (c *Config) GetBeaconEnabled() bool   { return c.beaconEnabled }
+func (c *Config) GetBeaconInterval() uint32 { return c.beaconInterval }
+func (c *Config) GetBeaconText() string    { return c.beaconText }
+func (c *Config) GetBeaconVoice() string   { return c.beaconVoice }
+
+// Getter methods for Maintenance section
+func (c *Config) GetMaintenanceEnabled() bool    { return c.maintenanceEnabled }
+func (c *Config) GetMaintenanceStart() string    { return c.maintenanceStart }
+func (c *Config) GetMaintenanceDuration() uint32 { return c.maintenanceDuration }
+func (c *Config) GetMaintenanceDays() string     { return c.maintenanceDays }
+func (c *Config) GetMaintenanceUnlink() bool     { return c.maintenanceUnlink }
+func (c *Config) GetMaintenanceText() string     { return c.maintenanceText }
+func (c *Config) GetMaintenanceVoice() string    { return c.maintenanceVoice }
+
+// Getter methods for Commands section
+func (c *Config) GetCommandsEnabled() bool     { return c.commandsEnabled }
+func (c *Config) GetCommandConnect() string    { return c.commandConnect }
+func (c *Config) GetCommandPrivate() string    { return c.commandPrivate }
+func (c *Config) GetCommandDisconnect() string { return c.commandDisconnect }
+func (c *Config) GetCommandSelect() string     { return c.commandSelect }
+func (c *Config) GetCommandTerminator() string { return c.commandTerminator }
+func (c *Config) GetCommandPresets() []uint32  { return c.commandPresets }
+
+// Getter methods for Audio section
+func (c *Config) GetAudioTranscoder() string    { return c.audioTranscoder }
+func (c *Config) GetAudioTranscoderArg() string { return c.audioTranscoderArg }
+func (c *Config) GetAudioYSFGain() float64      { return c.audioYSFGain }
+func (c *Config) GetAudioDMRGain() float64      { return c.audioDMRGain }
+func (c *Config) GetAudioYSFAGC() bool          { return c.audioYSFAGC }
+func (c *Config) GetAudioDMRAGC() bool          { return c.audioDMRAGC }
+func (c *Config) GetAudioAGCTarget() float64    { return c.audioAGCTarget }
+func (c *Config) GetAudioAGCMaxGain() float64   { return c.audioAGCMaxGain }
+
+// Getter methods for USRP section
+func (c *Config) GetUSRPEnabled() bool         { return c.usrpEnabled }
+func (c *Config) GetUSRPAddress() string       { return c.usrpAddress }
+func (c *Config) GetUSRPPort() uint32          { return c.usrpPort }
+func (c *Config) GetUSRPLocalPort() uint32     { return c.usrpLocalPort }
+func (c *Config) GetUSRPTranscoder() string    { return c.usrpTranscoder }
+func (c *Config) GetUSRPTranscoderArg() string { return c.usrpTranscoderArg }
+
+// Getter methods for Blocklist section
+func (c *Config) GetBlockRadioIDs() string { return c.blockRadioIDs }
+func (c *Config) GetAllowRadioIDs() string { return c.allowRadioIDs }
@@ -0,0 +1,141 @@
+package correction
+
+import "fmt"
+
+// Checksum is a CRC carried in the trailing bytes of a buffer
+// Add computes the CRC over everything before those bytes and stores it;
+// Check recomputes it and compares against the stored value.
+type Checksum interface {
+	Name() string
+	Size() int // Trailing bytes occupied by the CRC
+	Add(data []byte) error
+	Check(data []byte) bool
+}
+
+// CRC variants used by the DMR and YSF protocols
+var (
+	// CCITT161Checksum is the reflected CRC-16 used by MMDVM for DMR
+	CCITT161Checksum Checksum = checksumFuncs{"CCITT-16/1", 2, AddCCITT161, CheckCCITT161}
+
+	// CCITT162Checksum is the CRC-16 used for YSF FICH, CSD and data frames and for DMR data headers
+	CCITT162Checksum Checksum = checksumFuncs{"CCITT-16/2", 2, AddCCITT162, CheckCCITT162}
+
+	// CRC32Checksum is the CRC-32 over the user data of a DMR packet data call
+	CRC32Checksum Checksum = checksumFuncs{"CRC-32", 4, AddCRC32, CheckCRC32}
+)
+
+// checksumFuncs adapts a pair of Add/Check functions to the Checksum interface
+type checksumFuncs struct {
+	name  string
+	size  int
+	add   func([]byte) error
+	check func([]byte) bool
+}
+
+func (c checksumFuncs) Name() string           { return c.name }
+func (c checksumFuncs) Size() int              { return c.size }
+func (c checksumFuncs) Add(data []byte) error  { return c.add(data) }
+func (c checksumFuncs) Check(data []byte) bool { return c.check(data) }
+
+// CRC-32 generator polynomial x^32 + x^26 + x^23 + ... + x + 1, MSB first
+const crc32Polynomial = 0x04C11DB7
+
+var crc32Table = buildCRC32Table()
+
+func buildCRC32Table() [256]uint32 {
+	var table [256]uint32
+	for i := range table {
+		crc := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if crc&0x80000000 != 0 {
+				crc = (crc << 1) ^ crc32Polynomial
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}
+
+// crc32Update feeds data through the MSB-first CRC-32 starting from crc
+func crc32Update(crc uint32, data []byte) uint32 {
+	for _, b := range data {
+		crc = (crc << 8) ^ crc32Table[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+// CRC32 calculates the DMR packet data CRC-32
+// The register starts at zero and the result is not inverted.
+func CRC32(data []byte) uint32 {
+	return crc32Update(0, data)
+}
+
+// AddCRC32 stores the CRC-32 of all but the last four bytes in those bytes
+// The CRC is stored least significant byte first.
+func AddCRC32(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("data too short for CRC-32: need at least 4 bytes")
+	}
+
+	n := len(data) - 4
+	crc := CRC32(data[:n])
+	data[n] = uint8(crc)
+	data[n+1] = uint8(crc >> 8)
+	data[n+2] = uint8(crc >> 16)
+	data[n+3] = uint8(crc >> 24)
+
+	return nil
+}
+
+// CheckCRC32 verifies the CRC-32 stored in the last four bytes of data
+func CheckCRC32(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+
+	n := len(data) - 4
+	crc := CRC32(data[:n])
+	return data[n] == uint8(crc) && data[n+1] == uint8(crc>>8) &&
+		data[n+2] == uint8(crc>>16) && data[n+3] == uint8(crc>>24)
+}
+
+// CRC-9 generator polynomial x^9 + x^6 + x^4 + x^3 + 1
+const crc9Polynomial = 0x059
+
+// CRC9 calculates the CRC-9 of a DMR confirmed data block
+// The CRC covers the block's user data followed by its 7-bit serial number
+// and is returned inverted; callers apply the data type specific mask.
+func CRC9(data []byte, serial uint8) uint16 {
+	var crc uint16
+
+	feed := func(value uint8, nBits int) {
+		for i := nBits - 1; i >= 0; i-- {
+			bit := uint16(value>>uint(i)) & 1
+			if (crc>>8)&1 != bit {
+				crc = ((crc << 1) ^ crc9Polynomial) & 0x1FF
+			} else {
+				crc = (crc << 1) & 0x1FF
+			}
+		}
+	}
+
+	for _, b := range data {
+		feed(b, 8)
+	}
+	feed(serial&0x7F, 7)
+
+	return ^crc & 0x1FF
+}
+
+// FiveBitChecksum calculates the 5-bit checksum of a 9-byte DMR link control
+// It protects the LC carried in embedded signalling and is the sum of the
+// bytes modulo 31.
+func FiveBitChecksum(lc []byte) uint8 {
+	var total uint16
+	for i := 0; i < 9 && i < len(lc); i++ {
+		total += uint16(lc[i])
+	}
+	return uint8(total % 31)
+}
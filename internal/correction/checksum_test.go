@@ -0,0 +1,120 @@
+package correction
+
+import "testing"
+
+// Standard check input from the CRC catalogue
+var crcCheckInput = []byte("123456789")
+
+// Each checksum appended to the catalogue check string, as stored on air
+func TestChecksum_CheckValues(t *testing.T) {
+	tests := []struct {
+		checksum Checksum
+		stored   []byte
+	}{
+		{CCITT161Checksum, []byte{0x6E, 0x90}},          // CRC-16/IBM-SDLC 0x906E, low byte first
+		{CCITT162Checksum, []byte{0xCE, 0x3C}},          // CRC-16/GSM 0xCE3C, high byte first
+		{CRC32Checksum, []byte{0x7F, 0x89, 0xA1, 0x89}}, // CRC-32/CKSUM without the final inversion
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.checksum.Name(), func(t *testing.T) {
+			if tt.checksum.Size() != len(tt.stored) {
+				t.Fatalf("Size() = %d, want %d", tt.checksum.Size(), len(tt.stored))
+			}
+
+			data := make([]byte, len(crcCheckInput)+tt.checksum.Size())
+			copy(data, crcCheckInput)
+			if err := tt.checksum.Add(data); err != nil {
+				t.Fatalf("Add() error: %v", err)
+			}
+			if got := data[len(crcCheckInput):]; string(got) != string(tt.stored) {
+				t.Errorf("Add() stored % X, want % X", got, tt.stored)
+			}
+			if !tt.checksum.Check(data) {
+				t.Error("Check() rejected its own checksum")
+			}
+
+			data[0] ^= 0x01
+			if tt.checksum.Check(data) {
+				t.Error("Check() accepted corrupted data")
+			}
+		})
+	}
+}
+
+func TestChecksum_ShortBuffers(t *testing.T) {
+	for _, c := range []Checksum{CCITT161Checksum, CCITT162Checksum, CRC32Checksum} {
+		short := make([]byte, c.Size()-1)
+		if err := c.Add(short); err == nil {
+			t.Errorf("%s: Add() accepted a %d-byte buffer", c.Name(), len(short))
+		}
+		if c.Check(short) {
+			t.Errorf("%s: Check() accepted a %d-byte buffer", c.Name(), len(short))
+		}
+	}
+}
+
+func TestCRC32_Engine(t *testing.T) {
+	// CRC-32/MPEG-2 shares the polynomial but starts from all ones
+	if got := crc32Update(0xFFFFFFFF, crcCheckInput); got != 0x0376E6E7 {
+		t.Errorf("CRC-32/MPEG-2 check = 0x%08X, want 0x0376E6E7", got)
+	}
+	if got := CRC32(crcCheckInput); got != 0x89A1897F {
+		t.Errorf("CRC32() check = 0x%08X, want 0x89A1897F", got)
+	}
+}
+
+func TestCRC9(t *testing.T) {
+	block := []byte{0x45, 0x00, 0x00, 0x3C, 0x1C, 0x46, 0x40, 0x00, 0x40, 0x06}
+
+	tests := []struct {
+		name   string
+		data   []byte
+		serial uint8
+		want   uint16
+	}{
+		{"zero block", make([]byte, 10), 0, 0x1FF},
+		{"serial only", nil, 0x01, 0x1FF ^ 0x059},
+	}
+	for _, tt := range tests {
+		if got := CRC9(tt.data, tt.serial); got != tt.want {
+			t.Errorf("%s: CRC9() = 0x%03X, want 0x%03X", tt.name, got, tt.want)
+		}
+	}
+
+	// Any single bit error in the data or serial number changes the CRC
+	crc := CRC9(block, 0x15)
+	for i := 0; i < len(block)*8; i++ {
+		corrupted := append([]byte(nil), block...)
+		corrupted[i/8] ^= 0x80 >> uint(i%8)
+		if CRC9(corrupted, 0x15) == crc {
+			t.Fatalf("bit %d error not detected", i)
+		}
+	}
+	for i := 0; i < 7; i++ {
+		if CRC9(block, 0x15^(1<<uint(i))) == crc {
+			t.Fatalf("serial bit %d error not detected", i)
+		}
+	}
+	if CRC9(block, 0x15|0x80) != crc {
+		t.Error("CRC9() used the bit above the 7-bit serial number")
+	}
+}
+
+func TestFiveBitChecksum(t *testing.T) {
+	tests := []struct {
+		name string
+		lc   []byte
+		want uint8
+	}{
+		{"all zero", make([]byte, 9), 0},
+		{"all ones", []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, 1}, // 2295 mod 31
+		{"group call TG 91 from 3120001", []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x5B, 0x2F, 0x9B, 0x81}, 19},
+	}
+
+	for _, tt := range tests {
+		if got := FiveBitChecksum(tt.lc); got != tt.want {
+			t.Errorf("%s: FiveBitChecksum() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
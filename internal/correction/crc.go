@@ -169,30 +169,35 @@ func CheckCCITT161(data []byte) bool {
 	return expectedLow == data[len(data)-2] && expectedHigh == data[len(data)-1]
 }
 
-// AddCCITT162 adds CRC-16 CCITT variant 2 to the data buffer
-// The CRC is added to the last two bytes of the buffer
-func AddCCITT162(data []byte) error {
-	if len(data) < 2 {
-		return fmt.Errorf("data too short for CRC-16: need at least 2 bytes")
-	}
-
+// CalculateCCITT162 calculates the CRC-16 CCITT variant 2 of data
+// This is the non-reflected CRC-16 with a zero initial value and an inverted
+// result (CRC-16/GSM).
+func CalculateCCITT162(data []byte) uint16 {
 	var crc uint16 = 0
 
-	// Calculate CRC over all but last 2 bytes
 	// C++: crc16 = (uint16_t(crc8[0U]) << 8) ^ CCITT16_TABLE2[crc8[1U] ^ in[i]]
 	// where crc8[0] = low byte, crc8[1] = high byte
-	for i := 0; i < len(data)-2; i++ {
-		highByte := uint8(crc >> 8)   // crc8[1]
-		lowByte := uint8(crc & 0xFF)  // crc8[0]
-		crc = (uint16(lowByte) << 8) ^ CCITT16_TABLE2[highByte^data[i]]
+	for _, b := range data {
+		highByte := uint8(crc >> 8)  // crc8[1]
+		lowByte := uint8(crc & 0xFF) // crc8[0]
+		crc = (uint16(lowByte) << 8) ^ CCITT16_TABLE2[highByte^b]
 	}
 
-	crc = ^crc
+	return ^crc
+}
 
-	// Store CRC in last two bytes - C++ stores: in[length - 1U] = crc8[0U]; in[length - 2U] = crc8[1U];
-	// In C++ union: crc8[0] is low byte, crc8[1] is high byte (little-endian)
-	data[len(data)-1] = uint8(crc & 0xFF) // low byte (crc8[0]) goes to last position
-	data[len(data)-2] = uint8(crc >> 8)   // high byte (crc8[1]) goes to second-to-last position
+// AddCCITT162 adds CRC-16 CCITT variant 2 to the data buffer
+// The CRC is added to the last two bytes of the buffer, high byte first
+func AddCCITT162(data []byte) error {
+	if len(data) < 2 {
+		return fmt.Errorf("data too short for CRC-16: need at least 2 bytes")
+	}
+
+	crc := CalculateCCITT162(data[:len(data)-2])
+
+	// C++ stores: in[length - 1U] = crc8[0U]; in[length - 2U] = crc8[1U];
+	data[len(data)-1] = uint8(crc & 0xFF)
+	data[len(data)-2] = uint8(crc >> 8)
 
 	return nil
 }
@@ -203,25 +208,8 @@ func CheckCCITT162(data []byte) bool {
 		return false
 	}
 
-	var crc uint16 = 0
-
-	// Calculate CRC over all but last 2 bytes
-	// C++: crc16 = (uint16_t(crc8[0U]) << 8) ^ CCITT16_TABLE2[crc8[1U] ^ in[i]]
-	// where crc8[0] = low byte, crc8[1] = high byte
-	for i := 0; i < len(data)-2; i++ {
-		highByte := uint8(crc >> 8)   // crc8[1]
-		lowByte := uint8(crc & 0xFF)  // crc8[0]
-		crc = (uint16(lowByte) << 8) ^ CCITT16_TABLE2[highByte^data[i]]
-	}
-
-	crc = ^crc
-
-	// Check against stored CRC - C++ checks: crc8[0U] == in[length - 1U] && crc8[1U] == in[length - 2U]
-	// In C++ union: crc8[0] is low byte, crc8[1] is high byte (little-endian)
-	expectedLow := uint8(crc & 0xFF)   // crc8[0]
-	expectedHigh := uint8(crc >> 8)    // crc8[1]
-
-	return expectedLow == data[len(data)-1] && expectedHigh == data[len(data)-2]
+	crc := CalculateCCITT162(data[:len(data)-2])
+	return uint8(crc&0xFF) == data[len(data)-1] && uint8(crc>>8) == data[len(data)-2]
 }
 
 // EncodeFiveBit calculates the 5-bit CRC for 72-bit input data
@@ -230,20 +218,14 @@ func EncodeFiveBit(data []bool) uint32 {
 		return 0 // Invalid input
 	}
 
-	var total uint16 = 0
-
-	// Process data in 8-bit chunks
-	for i := 0; i < 72; i += 8 {
-		var b uint8 = 0
-		for j := 0; j < 8 && i+j < 72; j++ {
-			if data[i+j] {
-				b |= 1 << uint(7-j)
-			}
+	var lc [9]byte
+	for i, bit := range data {
+		if bit {
+			lc[i/8] |= 1 << uint(7-i%8)
 		}
-		total += uint16(b)
 	}
 
-	return uint32(total % 31)
+	return uint32(FiveBitChecksum(lc[:]))
 }
 
 // CheckFiveBit verifies the 5-bit CRC for the given data
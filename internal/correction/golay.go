@@ -5,7 +5,7 @@ import "fmt"
 // Golay (20,8,7) generator polynomial: x^12 + x^11 + x^10 + x^8 + x^5 + x^2 + 1
 const GOLAY_20_8_GENERATOR = 0x1ED1
 
-// Golay (24,12,8) generator polynomial: x^11 + x^10 + x^6 + x^5 + x^4 + x^2 + 1
+// Golay (23,12,7) generator polynomial, extended to (24,12,8) by a parity bit: x^11 + x^10 + x^6 + x^5 + x^4 + x^2 + 1
 const GOLAY_24_12_GENERATOR = 0xC75
 
 // Golay2087Encode encodes 8-bit data into 20-bit Golay codeword
@@ -81,15 +81,8 @@ func Golay24128Encode(data []byte) error {
 	// Extract 12 data bits from first 1.5 bytes
 	dataBits := (uint32(data[0]) << 4) | (uint32(data[1]) >> 4)
 
-	// Calculate 12 parity bits using polynomial division
-	// Shift data left by 12 positions
-	shifted := dataBits << 12
-
-	// Perform polynomial division
-	parity := polyDiv24(shifted, GOLAY_24_12_GENERATOR)
-
 	// Construct 24-bit codeword: 12 data bits + 12 parity bits
-	codeword := (dataBits << 12) ^ parity
+	codeword := Golay24128EncodeWord(dataBits)
 
 	// Store back in 3 bytes
 	data[0] = uint8((codeword >> 16) & 0xFF) // Data bits [11:4]
@@ -100,7 +93,7 @@ func Golay24128Encode(data []byte) error {
 }
 
 // Golay24128Decode decodes 24-bit Golay codeword and corrects errors
-// Returns the number of errors detected/corrected
+// Returns the number of errors corrected, or 0xFF if uncorrectable
 func Golay24128Decode(data []byte) uint8 {
 	if len(data) < 3 {
 		return 0xFF // Error indicator
@@ -109,27 +102,85 @@ func Golay24128Decode(data []byte) uint8 {
 	// Extract 24-bit codeword from 3 bytes
 	codeword := (uint32(data[0]) << 16) | (uint32(data[1]) << 8) | uint32(data[2])
 
-	// Calculate syndrome
-	syndrome := polyDiv24(codeword, GOLAY_24_12_GENERATOR)
-
-	if syndrome == 0 {
-		return 0 // No errors
+	dataBits, errorCount := Golay24128DecodeWord(codeword)
+	if errorCount == 0 || errorCount == 0xFF {
+		return errorCount
 	}
 
-	// Find error pattern
-	errorPattern, errorCount := findGolayErrorPattern24(syndrome)
+	// Store corrected codeword back
+	corrected := Golay24128EncodeWord(dataBits)
+	data[0] = uint8((corrected >> 16) & 0xFF)
+	data[1] = uint8((corrected >> 8) & 0xFF)
+	data[2] = uint8(corrected & 0xFF)
 
-	if errorCount > 0 {
-		// Apply correction
-		corrected := codeword ^ errorPattern
+	return errorCount
+}
 
-		// Store corrected codeword back
-		data[0] = uint8((corrected >> 16) & 0xFF)
-		data[1] = uint8((corrected >> 8) & 0xFF)
-		data[2] = uint8(corrected & 0xFF)
+// golay23127Errors maps each (23,12) syndrome to its error pattern
+// The code is perfect: every 11-bit syndrome belongs to exactly one pattern
+// of weight three or less.
+var golay23127Errors = buildGolay23127Errors()
+
+func buildGolay23127Errors() [1 << 11]uint32 {
+	var table [1 << 11]uint32
+	for i := uint(0); i < 23; i++ {
+		for j := i; j < 23; j++ {
+			for k := j; k < 23; k++ {
+				pattern := (uint32(1) << i) | (uint32(1) << j) | (uint32(1) << k)
+				table[golay23127Syndrome(pattern)] = pattern
+			}
+		}
 	}
+	table[0] = 0
+	return table
+}
 
-	return errorCount
+// golay23127Syndrome returns the remainder of a 23-bit word divided by the generator
+func golay23127Syndrome(code uint32) uint32 {
+	code &= 0x7FFFFF
+	for i := uint(22); i >= 11; i-- {
+		if code&(1<<i) != 0 {
+			code ^= GOLAY_24_12_GENERATOR << (i - 11)
+		}
+	}
+	return code
+}
+
+// Golay23127EncodeWord encodes 12-bit data into a 23-bit Golay (23,12) codeword
+// The data occupies the upper 12 bits, followed by 11 parity bits.
+func Golay23127EncodeWord(data uint32) uint32 {
+	data = (data & 0xFFF) << 11
+	return data | golay23127Syndrome(data)
+}
+
+// Golay23127DecodeWord decodes a 23-bit Golay (23,12) codeword
+// Returns the corrected 12-bit data and the number of bits corrected.
+// Up to three errors are always corrected; more are miscorrected silently.
+func Golay23127DecodeWord(code uint32) (uint32, uint8) {
+	code &= 0x7FFFFF
+	pattern := golay23127Errors[golay23127Syndrome(code)]
+	return (code ^ pattern) >> 11, popcount32(pattern)
+}
+
+// Golay24128EncodeWord encodes 12-bit data into a 24-bit Golay (24,12) codeword
+// This is the (23,12) codeword followed by an even parity bit.
+func Golay24128EncodeWord(data uint32) uint32 {
+	code := Golay23127EncodeWord(data)
+	return (code << 1) | uint32(popcount32(code)&1)
+}
+
+// Golay24128DecodeWord decodes a 24-bit Golay (24,12) codeword
+// Returns the corrected 12-bit data and the number of bits corrected. Four bit
+// errors are detected and reported as 0xFF, with the data left uncorrected.
+func Golay24128DecodeWord(code uint32) (uint32, uint8) {
+	code &= 0xFFFFFF
+	data, _ := Golay23127DecodeWord(code >> 1)
+
+	errorCount := popcount32(Golay24128EncodeWord(data) ^ code)
+	if errorCount > 3 {
+		return code >> 12, 0xFF
+	}
+	return data, errorCount
 }
 
 // polyDiv20 performs polynomial division for 20-bit codeword
@@ -148,22 +199,6 @@ func polyDiv20(dividend, divisor uint32) uint32 {
 	return remainder & 0xFFF
 }
 
-// polyDiv24 performs polynomial division for 24-bit codeword
-func polyDiv24(dividend, divisor uint32) uint32 {
-	// Ensure we're working with 24-bit values
-	dividend &= 0xFFFFFF
-	divisor &= 0xFFF
-
-	remainder := dividend
-	for i := 23; i >= 12; i-- {
-		if (remainder & (1 << uint(i))) != 0 {
-			remainder ^= divisor << uint(i-11)
-		}
-	}
-
-	return remainder & 0xFFF
-}
-
 // findGolayErrorPattern20 finds error pattern for Golay (20,8) code
 // Returns error pattern and number of errors
 func findGolayErrorPattern20(syndrome uint32) (uint32, uint8) {
@@ -234,96 +269,6 @@ func findGolayErrorPattern20(syndrome uint32) (uint32, uint8) {
 	return 0, 0xFF
 }
 
-// findGolayErrorPattern24 finds error pattern for Golay (24,12) code
-// Returns error pattern and number of errors
-func findGolayErrorPattern24(syndrome uint32) (uint32, uint8) {
-	if syndrome == 0 {
-		return 0, 0
-	}
-
-	// Weight of syndrome
-	syndromeWeight := popcount32(syndrome)
-
-	// Single error patterns: weight(syndrome) <= 3
-	if syndromeWeight <= 3 {
-		// Error in parity bits
-		return syndrome, uint8(syndromeWeight)
-	}
-
-	// Try error patterns with errors in data bits
-	for i := uint(0); i < 12; i++ {
-		// Error in data bit i
-		errorPattern := uint32(1) << (12 + i)
-		testSyndrome := polyDiv24(errorPattern, GOLAY_24_12_GENERATOR)
-
-		// Check if this matches our syndrome (single error in data)
-		if testSyndrome == syndrome {
-			return errorPattern, 1
-		}
-
-		// Check for double error patterns (data bit + parity bit)
-		for j := uint(0); j < 12; j++ {
-			doublePattern := errorPattern | (uint32(1) << j)
-			testSyndrome = polyDiv24(doublePattern, GOLAY_24_12_GENERATOR)
-			if testSyndrome == syndrome {
-				return doublePattern, 2
-			}
-		}
-
-		// Check for triple error patterns
-		for j := uint(0); j < 12; j++ {
-			for k := uint(j + 1); k < 12; k++ {
-				triplePattern := errorPattern | (uint32(1) << j) | (uint32(1) << k)
-				testSyndrome = polyDiv24(triplePattern, GOLAY_24_12_GENERATOR)
-				if testSyndrome == syndrome {
-					return triplePattern, 3
-				}
-			}
-		}
-	}
-
-	// Check for errors between data bits
-	for i := uint(12); i < 24; i++ {
-		for j := uint(i + 1); j < 24; j++ {
-			doublePattern := (uint32(1) << i) | (uint32(1) << j)
-			testSyndrome := polyDiv24(doublePattern, GOLAY_24_12_GENERATOR)
-			if testSyndrome == syndrome {
-				return doublePattern, 2
-			}
-
-			// Triple errors
-			for k := uint(j + 1); k < 24; k++ {
-				triplePattern := doublePattern | (uint32(1) << k)
-				testSyndrome = polyDiv24(triplePattern, GOLAY_24_12_GENERATOR)
-				if testSyndrome == syndrome {
-					return triplePattern, 3
-				}
-			}
-		}
-	}
-
-	// Check for errors in parity bits only (2-3 errors)
-	for i := uint(0); i < 12; i++ {
-		for j := uint(i + 1); j < 12; j++ {
-			doublePattern := (uint32(1) << i) | (uint32(1) << j)
-			if doublePattern == syndrome {
-				return doublePattern, 2
-			}
-
-			// Triple error in parity
-			for k := uint(j + 1); k < 12; k++ {
-				triplePattern := doublePattern | (uint32(1) << k)
-				if triplePattern == syndrome {
-					return triplePattern, 3
-				}
-			}
-		}
-	}
-
-	// Uncorrectable error pattern
-	return 0, 0xFF
-}
-
 // popcount32 counts the number of 1 bits in a 32-bit integer
 func popcount32(x uint32) uint8 {
 	var count uint8
@@ -289,6 +289,116 @@ func TestGolaySyndrome(t *testing.T) {
 	}
 }
 
+// Known codewords from the MMDVM encoding tables
+func TestGolayWord_KnownCodewords(t *testing.T) {
+	tests := []struct {
+		data     uint32
+		code2312 uint32
+		code2412 uint32
+	}{
+		{0x000, 0x000000, 0x000000},
+		{0x001, 0x000C75, 0x0018EB},
+		{0x002, 0x00149F, 0x00293E},
+		{0x003, 0x0018EA, 0x0031D5},
+	}
+
+	for _, tt := range tests {
+		if got := Golay23127EncodeWord(tt.data); got != tt.code2312 {
+			t.Errorf("Golay23127EncodeWord(0x%03X) = 0x%06X, want 0x%06X", tt.data, got, tt.code2312)
+		}
+		if got := Golay24128EncodeWord(tt.data); got != tt.code2412 {
+			t.Errorf("Golay24128EncodeWord(0x%03X) = 0x%06X, want 0x%06X", tt.data, got, tt.code2412)
+		}
+	}
+}
+
+// golayErrorPatterns returns every pattern of 1 to maxWeight bits over n bits
+func golayErrorPatterns(n uint, maxWeight int) []uint32 {
+	var patterns []uint32
+	var build func(start uint, pattern uint32, weight int)
+	build = func(start uint, pattern uint32, weight int) {
+		if weight > 0 {
+			patterns = append(patterns, pattern)
+		}
+		if weight == maxWeight {
+			return
+		}
+		for i := start; i < n; i++ {
+			build(i+1, pattern|(1<<i), weight+1)
+		}
+	}
+	build(0, 0, 0)
+	return patterns
+}
+
+// Every data word survives every error pattern of up to three bits
+func TestGolay23127Word_Exhaustive(t *testing.T) {
+	patterns := golayErrorPatterns(23, 3)
+	if len(patterns) != 23+253+1771 {
+		t.Fatalf("got %d error patterns, want 2047", len(patterns))
+	}
+
+	for data := uint32(0); data < 4096; data++ {
+		code := Golay23127EncodeWord(data)
+		if code>>11 != data {
+			t.Fatalf("Golay23127EncodeWord(0x%03X) = 0x%06X, data bits not systematic", data, code)
+		}
+		if got, errs := Golay23127DecodeWord(code); got != data || errs != 0 {
+			t.Fatalf("Golay23127DecodeWord(clean 0x%03X) = 0x%03X, %d errors", data, got, errs)
+		}
+
+		for _, pattern := range patterns {
+			got, errs := Golay23127DecodeWord(code ^ pattern)
+			if got != data || errs != popcount32(pattern) {
+				t.Fatalf("data 0x%03X pattern 0x%06X: decoded 0x%03X with %d errors", data, pattern, got, errs)
+			}
+		}
+	}
+}
+
+func TestGolay24128Word_Exhaustive(t *testing.T) {
+	patterns := golayErrorPatterns(24, 3)
+	if len(patterns) != 24+276+2024 {
+		t.Fatalf("got %d error patterns, want 2324", len(patterns))
+	}
+
+	for data := uint32(0); data < 4096; data++ {
+		code := Golay24128EncodeWord(data)
+		if popcount32(code)&1 != 0 {
+			t.Fatalf("Golay24128EncodeWord(0x%03X) = 0x%06X, odd parity", data, code)
+		}
+		if got, errs := Golay24128DecodeWord(code); got != data || errs != 0 {
+			t.Fatalf("Golay24128DecodeWord(clean 0x%03X) = 0x%03X, %d errors", data, got, errs)
+		}
+
+		for _, pattern := range patterns {
+			got, errs := Golay24128DecodeWord(code ^ pattern)
+			if got != data || errs != popcount32(pattern) {
+				t.Fatalf("data 0x%03X pattern 0x%06X: decoded 0x%03X with %d errors", data, pattern, got, errs)
+			}
+		}
+	}
+}
+
+// Four bit errors are always detected by the (24,12) parity bit
+func TestGolay24128Word_DetectsFourErrors(t *testing.T) {
+	var fours []uint32
+	for _, pattern := range golayErrorPatterns(24, 4) {
+		if popcount32(pattern) == 4 {
+			fours = append(fours, pattern)
+		}
+	}
+
+	for _, data := range []uint32{0x000, 0x001, 0x123, 0xABC, 0xFFF} {
+		code := Golay24128EncodeWord(data)
+		for _, pattern := range fours {
+			if _, errs := Golay24128DecodeWord(code ^ pattern); errs != 0xFF {
+				t.Fatalf("data 0x%03X pattern 0x%06X: got %d errors, want 0xFF", data, pattern, errs)
+			}
+		}
+	}
+}
+
 // Benchmark tests for performance
 func BenchmarkGolay2087_Encode(b *testing.B) {
 	data := []byte{0x12, 0x34, 0x56}
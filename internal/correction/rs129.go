@@ -0,0 +1,142 @@
+package correction
+
+import "fmt"
+
+// Reed-Solomon (12,9) over GF(256), as used to protect the DMR full link
+// control (ETSI TS 102 361-1 annex B). The generator polynomial is
+// (x - a)(x - a^2)(x - a^3) = x^3 + 14x^2 + 56x + 64 with the field
+// generated by x^8 + x^4 + x^3 + x^2 + 1. Byte 0 of a codeword is the
+// coefficient of x^11 and the three parity bytes occupy bytes 9-11.
+const (
+	RS129_DATA_LENGTH   = 9  // Data symbols
+	RS129_PARITY_LENGTH = 3  // Parity symbols
+	RS129_LENGTH        = 12 // Codeword symbols
+)
+
+// GF(256) primitive polynomial x^8 + x^4 + x^3 + x^2 + 1
+const rs129Primitive = 0x11D
+
+// Generator polynomial coefficients, lowest degree first
+var rs129Generator = [RS129_PARITY_LENGTH]uint8{64, 56, 14}
+
+// GF(256) antilog table, doubled so sums of two logs need no reduction
+var gfExp, gfLog = buildGFTables()
+
+func buildGFTables() ([510]uint8, [256]uint8) {
+	var exp [510]uint8
+	var log [256]uint8
+
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = uint8(x)
+		exp[i+255] = uint8(x)
+		log[x] = uint8(i)
+
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= rs129Primitive
+		}
+	}
+
+	return exp, log
+}
+
+// gfMul multiplies two elements of GF(256)
+func gfMul(a, b uint8) uint8 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// gfDiv divides two elements of GF(256); b must be non-zero
+func gfDiv(a, b uint8) uint8 {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// RS129Encode computes the parity of a 12-byte RS(12,9) codeword
+// Input: first 9 bytes hold the data
+// Output: bytes 9-11 are overwritten with the parity
+func RS129Encode(data []byte) error {
+	if len(data) < RS129_LENGTH {
+		return fmt.Errorf("invalid data length for RS (12,9): need at least %d bytes, got %d", RS129_LENGTH, len(data))
+	}
+
+	// LFSR division by the generator; parity[j] is the coefficient of x^j
+	var parity [RS129_PARITY_LENGTH]uint8
+	for i := 0; i < RS129_DATA_LENGTH; i++ {
+		feedback := data[i] ^ parity[RS129_PARITY_LENGTH-1]
+		for j := RS129_PARITY_LENGTH - 1; j > 0; j-- {
+			parity[j] = parity[j-1] ^ gfMul(rs129Generator[j], feedback)
+		}
+		parity[0] = gfMul(rs129Generator[0], feedback)
+	}
+
+	data[9] = parity[2]
+	data[10] = parity[1]
+	data[11] = parity[0]
+
+	return nil
+}
+
+// RS129Syndromes evaluates a 12-byte codeword at the generator roots a, a^2 and a^3
+// All three syndromes are zero for a valid codeword.
+func RS129Syndromes(data []byte) [RS129_PARITY_LENGTH]uint8 {
+	var syndromes [RS129_PARITY_LENGTH]uint8
+	if len(data) < RS129_LENGTH {
+		return syndromes
+	}
+
+	for j := range syndromes {
+		root := gfExp[j+1]
+		var s uint8
+		for i := 0; i < RS129_LENGTH; i++ {
+			s = gfMul(s, root) ^ data[i]
+		}
+		syndromes[j] = s
+	}
+
+	return syndromes
+}
+
+// RS129Check reports whether a 12-byte codeword is valid without correcting it
+func RS129Check(data []byte) bool {
+	if len(data) < RS129_LENGTH {
+		return false
+	}
+	return RS129Syndromes(data) == [RS129_PARITY_LENGTH]uint8{}
+}
+
+// RS129Decode checks a 12-byte codeword and corrects a single symbol error in place
+// Returns the number of symbols corrected (0 or 1), or 0xFF if the codeword
+// has more errors than can be corrected. Any two symbol errors are detected.
+func RS129Decode(data []byte) uint8 {
+	if len(data) < RS129_LENGTH {
+		return 0xFF // Error indicator
+	}
+
+	s := RS129Syndromes(data)
+	if s == [RS129_PARITY_LENGTH]uint8{} {
+		return 0
+	}
+
+	// A single error of value e at degree d gives S(j) = e * a^(j*d)
+	if s[0] == 0 || s[1] == 0 || s[2] == 0 {
+		return 0xFF
+	}
+	locator := gfDiv(s[1], s[0]) // a^d
+	if gfDiv(s[2], s[1]) != locator {
+		return 0xFF
+	}
+
+	degree := int(gfLog[locator])
+	if degree >= RS129_LENGTH {
+		return 0xFF
+	}
+
+	data[RS129_LENGTH-1-degree] ^= gfDiv(s[0], locator)
+	return 1
+}
@@ -0,0 +1,122 @@
+package correction
+
+import "testing"
+
+// Reference codewords, matching the MMDVM CRS129 encoder
+var rs129Vectors = []struct {
+	name     string
+	codeword [RS129_LENGTH]byte
+}{
+	{"all zero", [RS129_LENGTH]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	{"group call TG 91 from 3120001", [RS129_LENGTH]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x5B, 0x2F, 0x9B, 0x81, 0x2D, 0x4D, 0xDD}},
+	{"emergency group call TG 9", [RS129_LENGTH]byte{0x00, 0x00, 0x20, 0x00, 0x00, 0x09, 0x2F, 0x9B, 0x81, 0x83, 0x01, 0xED}},
+	{"private call", [RS129_LENGTH]byte{0x03, 0x00, 0x00, 0x2F, 0x9B, 0xE5, 0x2F, 0x9B, 0x81, 0x4E, 0xA8, 0x2C}},
+	{"sequential", [RS129_LENGTH]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0xBC, 0x70, 0x1F}},
+}
+
+func TestRS129_Encode(t *testing.T) {
+	for _, tt := range rs129Vectors {
+		t.Run(tt.name, func(t *testing.T) {
+			var data [RS129_LENGTH]byte
+			copy(data[:RS129_DATA_LENGTH], tt.codeword[:RS129_DATA_LENGTH])
+
+			if err := RS129Encode(data[:]); err != nil {
+				t.Fatalf("RS129Encode() error: %v", err)
+			}
+			if data != tt.codeword {
+				t.Errorf("RS129Encode() = % X, want % X", data, tt.codeword)
+			}
+			if !RS129Check(data[:]) {
+				t.Error("RS129Check() rejected an encoded codeword")
+			}
+		})
+	}
+
+	if err := RS129Encode(make([]byte, 11)); err == nil {
+		t.Error("RS129Encode() accepted an 11-byte buffer")
+	}
+}
+
+// Every single symbol error at every position is corrected
+func TestRS129_DecodeSingleErrors(t *testing.T) {
+	for _, tt := range rs129Vectors {
+		for pos := 0; pos < RS129_LENGTH; pos++ {
+			for e := 1; e < 256; e++ {
+				data := tt.codeword
+				data[pos] ^= byte(e)
+
+				if got := RS129Decode(data[:]); got != 1 {
+					t.Fatalf("%s: error 0x%02X at %d: RS129Decode() = %d, want 1", tt.name, e, pos, got)
+				}
+				if data != tt.codeword {
+					t.Fatalf("%s: error 0x%02X at %d: corrected to % X", tt.name, e, pos, data)
+				}
+			}
+		}
+	}
+}
+
+// Two symbol errors are always detected and never miscorrected
+func TestRS129_DecodeDoubleErrors(t *testing.T) {
+	codeword := rs129Vectors[1].codeword
+	values := []byte{0x01, 0x0F, 0x55, 0x80, 0xFF}
+
+	for p1 := 0; p1 < RS129_LENGTH; p1++ {
+		for p2 := p1 + 1; p2 < RS129_LENGTH; p2++ {
+			for _, e1 := range values {
+				for _, e2 := range values {
+					data := codeword
+					data[p1] ^= e1
+					data[p2] ^= e2
+					corrupted := data
+
+					if got := RS129Decode(data[:]); got != 0xFF {
+						t.Fatalf("errors at %d,%d: RS129Decode() = %d, want 0xFF", p1, p2, got)
+					}
+					if data != corrupted {
+						t.Fatalf("errors at %d,%d: uncorrectable codeword was modified", p1, p2)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRS129_DecodeClean(t *testing.T) {
+	for _, tt := range rs129Vectors {
+		data := tt.codeword
+		if got := RS129Decode(data[:]); got != 0 {
+			t.Errorf("%s: RS129Decode() = %d, want 0", tt.name, got)
+		}
+	}
+
+	if got := RS129Decode(make([]byte, 4)); got != 0xFF {
+		t.Errorf("RS129Decode(short) = %d, want 0xFF", got)
+	}
+}
+
+func TestRS129_GaloisField(t *testing.T) {
+	// a^8 reduces by the primitive polynomial to x^4 + x^3 + x^2 + 1
+	if gfExp[8] != 0x1D {
+		t.Errorf("a^8 = 0x%02X, want 0x1D", gfExp[8])
+	}
+
+	for a := 1; a < 256; a++ {
+		if gfExp[gfLog[a]] != uint8(a) {
+			t.Fatalf("exp(log(0x%02X)) = 0x%02X", a, gfExp[gfLog[a]])
+		}
+		if got := gfDiv(gfMul(uint8(a), 0x53), 0x53); got != uint8(a) {
+			t.Fatalf("(0x%02X * 0x53) / 0x53 = 0x%02X", a, got)
+		}
+	}
+}
+
+func BenchmarkRS129_Decode(b *testing.B) {
+	codeword := rs129Vectors[1].codeword
+	codeword[4] ^= 0x42
+
+	for i := 0; i < b.N; i++ {
+		data := codeword
+		RS129Decode(data[:])
+	}
+}
@@ -0,0 +1,209 @@
+package correction
+
+import "fmt"
+
+// Rate 3/4 trellis code used by DMR rate 3/4 data bursts (ETSI TS 102 361-1
+// annex B.2). 144 payload bits are split into 48 tribits plus a zero tail
+// tribit, each driving an 8-state encoder that outputs one of 16
+// constellation points. Every point is sent as a pair of dibits, and the
+// resulting 98 dibits are interleaved to give the 196 bits of a burst.
+const (
+	TRELLIS34_PAYLOAD_LENGTH = 18  // Payload bytes (144 bits)
+	TRELLIS34_CODED_BITS     = 196 // Coded bits, as carried in a data burst
+	TRELLIS34_CODED_LENGTH   = 25  // Bytes holding the coded bits
+
+	trellis34Tribits = 49 // 48 payload tribits plus the tail
+	trellis34Dibits  = 98
+	trellis34States  = 8
+)
+
+// trellis34Encode maps (state, tribit) to the transmitted constellation point
+// The next state is always the tribit just sent.
+var trellis34Encode = [trellis34States][8]uint8{
+	{0, 8, 4, 12, 2, 10, 6, 14},
+	{4, 12, 2, 10, 6, 14, 0, 8},
+	{1, 9, 5, 13, 3, 11, 7, 15},
+	{5, 13, 3, 11, 7, 15, 1, 9},
+	{3, 11, 7, 15, 1, 9, 5, 13},
+	{7, 15, 1, 9, 5, 13, 3, 11},
+	{2, 10, 6, 14, 0, 8, 4, 12},
+	{6, 14, 0, 8, 4, 12, 2, 10},
+}
+
+// trellis34Constellation gives the pair of dibit symbols sent for each point
+var trellis34Constellation = [16][2]int8{
+	{+1, -1}, {-1, -1}, {+3, -3}, {-3, -3},
+	{-3, -1}, {+3, -1}, {-1, -3}, {+1, -3},
+	{-3, +3}, {+3, +3}, {-1, +1}, {+1, +1},
+	{+1, +3}, {-1, +3}, {+3, +1}, {-3, +1},
+}
+
+// trellis34Interleave gives the transmitted position of each dibit
+var trellis34Interleave = [trellis34Dibits]uint8{
+	0, 1, 8, 9, 16, 17, 24, 25, 32, 33, 40, 41, 48, 49, 56, 57, 64, 65, 72, 73, 80, 81, 88, 89, 96, 97,
+	2, 3, 10, 11, 18, 19, 26, 27, 34, 35, 42, 43, 50, 51, 58, 59, 66, 67, 74, 75, 82, 83, 90, 91,
+	4, 5, 12, 13, 20, 21, 28, 29, 36, 37, 44, 45, 52, 53, 60, 61, 68, 69, 76, 77, 84, 85, 92, 93,
+	6, 7, 14, 15, 22, 23, 30, 31, 38, 39, 46, 47, 54, 55, 62, 63, 70, 71, 78, 79, 86, 87, 94, 95,
+}
+
+// Dibit bit patterns for the symbols +3, +1, -1 and -3
+func symbolToDibit(symbol int8) uint8 {
+	switch symbol {
+	case +3:
+		return 0x01
+	case +1:
+		return 0x00
+	case -1:
+		return 0x02
+	default:
+		return 0x03
+	}
+}
+
+func dibitToSymbol(dibit uint8) int8 {
+	switch dibit & 0x03 {
+	case 0x01:
+		return +3
+	case 0x00:
+		return +1
+	case 0x02:
+		return -1
+	default:
+		return -3
+	}
+}
+
+// Trellis34Encode encodes an 18-byte payload into 196 coded bits
+// The coded bits are written MSB first to the first 25 bytes of out.
+func Trellis34Encode(payload []byte, out []byte) error {
+	if len(payload) < TRELLIS34_PAYLOAD_LENGTH {
+		return fmt.Errorf("invalid payload length for trellis 3/4: need %d bytes, got %d", TRELLIS34_PAYLOAD_LENGTH, len(payload))
+	}
+	if len(out) < TRELLIS34_CODED_LENGTH {
+		return fmt.Errorf("invalid output length for trellis 3/4: need %d bytes, got %d", TRELLIS34_CODED_LENGTH, len(out))
+	}
+
+	var dibits [trellis34Dibits]uint8
+	state := uint8(0)
+	for i := 0; i < trellis34Tribits; i++ {
+		tribit := trellis34Tribit(payload, i)
+		point := trellis34Encode[state][tribit]
+		state = tribit
+
+		dibits[i*2] = symbolToDibit(trellis34Constellation[point][0])
+		dibits[i*2+1] = symbolToDibit(trellis34Constellation[point][1])
+	}
+
+	for i := 0; i < TRELLIS34_CODED_LENGTH; i++ {
+		out[i] = 0
+	}
+	for i, dibit := range dibits {
+		pos := int(trellis34Interleave[i]) * 2
+		out[pos/8] |= dibit << uint(6-pos%8)
+	}
+
+	return nil
+}
+
+// Trellis34Decode decodes 196 coded bits into an 18-byte payload
+// A Viterbi search finds the closest valid sequence of constellation points.
+// Returns the number of points that had to be corrected.
+func Trellis34Decode(in []byte, payload []byte) (int, error) {
+	if len(in) < TRELLIS34_CODED_LENGTH {
+		return 0, fmt.Errorf("invalid input length for trellis 3/4: need %d bytes, got %d", TRELLIS34_CODED_LENGTH, len(in))
+	}
+	if len(payload) < TRELLIS34_PAYLOAD_LENGTH {
+		return 0, fmt.Errorf("invalid payload length for trellis 3/4: need %d bytes, got %d", TRELLIS34_PAYLOAD_LENGTH, len(payload))
+	}
+
+	// Deinterleave into pairs of received symbols
+	var symbols [trellis34Dibits]int8
+	for i := range symbols {
+		pos := int(trellis34Interleave[i]) * 2
+		symbols[i] = dibitToSymbol(in[pos/8] >> uint(6-pos%8))
+	}
+
+	// Viterbi search; the state is the previous tribit and starts at zero
+	const unreachable = 1 << 30
+	var metric [trellis34States]int
+	for s := 1; s < trellis34States; s++ {
+		metric[s] = unreachable
+	}
+	var from [trellis34Tribits][trellis34States]uint8
+
+	for i := 0; i < trellis34Tribits; i++ {
+		var next [trellis34States]int
+		for s := range next {
+			next[s] = unreachable
+		}
+
+		for s := 0; s < trellis34States; s++ {
+			if metric[s] == unreachable {
+				continue
+			}
+			for tribit := 0; tribit < 8; tribit++ {
+				point := trellis34Constellation[trellis34Encode[s][tribit]]
+				m := metric[s] + trellis34Distance(symbols[i*2], point[0]) + trellis34Distance(symbols[i*2+1], point[1])
+				if m < next[tribit] {
+					next[tribit] = m
+					from[i][tribit] = uint8(s)
+				}
+			}
+		}
+		metric = next
+	}
+
+	// The tail tribit is zero, so the path must end in state zero
+	var tribits [trellis34Tribits]uint8
+	state := uint8(0)
+	for i := trellis34Tribits - 1; i >= 0; i-- {
+		tribits[i] = state
+		state = from[i][state]
+	}
+
+	for i := 0; i < TRELLIS34_PAYLOAD_LENGTH; i++ {
+		payload[i] = 0
+	}
+	corrected := 0
+	state = 0
+	for i, tribit := range tribits {
+		if i < trellis34Tribits-1 {
+			for b := 0; b < 3; b++ {
+				if tribit&(0x04>>uint(b)) != 0 {
+					pos := i*3 + b
+					payload[pos/8] |= 0x80 >> uint(pos%8)
+				}
+			}
+		}
+
+		point := trellis34Constellation[trellis34Encode[state][tribit]]
+		if point[0] != symbols[i*2] || point[1] != symbols[i*2+1] {
+			corrected++
+		}
+		state = tribit
+	}
+
+	return corrected, nil
+}
+
+// trellis34Distance is the number of bits that differ between two symbols' dibits
+func trellis34Distance(a, b int8) int {
+	return int(popcount32(uint32(symbolToDibit(a) ^ symbolToDibit(b))))
+}
+
+// trellis34Tribit returns the i-th tribit of the payload; the tail tribit is zero
+func trellis34Tribit(payload []byte, i int) uint8 {
+	if i >= trellis34Tribits-1 {
+		return 0
+	}
+
+	var tribit uint8
+	for b := 0; b < 3; b++ {
+		pos := i*3 + b
+		tribit <<= 1
+		if payload[pos/8]&(0x80>>uint(pos%8)) != 0 {
+			tribit |= 1
+		}
+	}
+	return tribit
+}
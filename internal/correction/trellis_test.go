@@ -0,0 +1,119 @@
+package correction
+
+import (
+	"bytes"
+	"testing"
+)
+
+var trellis34Payloads = [][]byte{
+	make([]byte, TRELLIS34_PAYLOAD_LENGTH),
+	bytes.Repeat([]byte{0xFF}, TRELLIS34_PAYLOAD_LENGTH),
+	{0x45, 0x00, 0x00, 0x3C, 0x1C, 0x46, 0x40, 0x00, 0x40, 0x06, 0xB1, 0xE6, 0xAC, 0x10, 0x0A, 0x63, 0xAC, 0x10},
+	{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF, 0xFE, 0xDC, 0xBA, 0x98, 0x76, 0x54, 0x32, 0x10, 0x5A, 0xA5},
+}
+
+func TestTrellis34_EncodeZero(t *testing.T) {
+	// Every point of an all-zero payload is 0 (+1, -1), sent as dibits 00 10
+	want := append(bytes.Repeat([]byte{0x22}, 24), 0x20)
+
+	out := make([]byte, TRELLIS34_CODED_LENGTH)
+	if err := Trellis34Encode(make([]byte, TRELLIS34_PAYLOAD_LENGTH), out); err != nil {
+		t.Fatalf("Trellis34Encode() error: %v", err)
+	}
+	if !bytes.Equal(out, want) {
+		t.Errorf("Trellis34Encode(zero) = % X, want % X", out, want)
+	}
+}
+
+func TestTrellis34_RoundTrip(t *testing.T) {
+	for _, payload := range trellis34Payloads {
+		coded := make([]byte, TRELLIS34_CODED_LENGTH)
+		if err := Trellis34Encode(payload, coded); err != nil {
+			t.Fatalf("Trellis34Encode() error: %v", err)
+		}
+
+		decoded := make([]byte, TRELLIS34_PAYLOAD_LENGTH)
+		corrected, err := Trellis34Decode(coded, decoded)
+		if err != nil {
+			t.Fatalf("Trellis34Decode() error: %v", err)
+		}
+		if corrected != 0 {
+			t.Errorf("Trellis34Decode() corrected %d points of a clean codeword", corrected)
+		}
+		if !bytes.Equal(decoded, payload) {
+			t.Errorf("Trellis34Decode() = % X, want % X", decoded, payload)
+		}
+	}
+}
+
+// Any single bit error is corrected; the code's free distance is three bits
+func TestTrellis34_CorrectsSingleBitErrors(t *testing.T) {
+	payload := trellis34Payloads[2]
+	coded := make([]byte, TRELLIS34_CODED_LENGTH)
+	if err := Trellis34Encode(payload, coded); err != nil {
+		t.Fatalf("Trellis34Encode() error: %v", err)
+	}
+
+	for dibit := 0; dibit < TRELLIS34_CODED_BITS/2; dibit++ {
+		for _, flip := range []byte{0x01, 0x02} {
+			corrupted := append([]byte(nil), coded...)
+			pos := dibit * 2
+			corrupted[pos/8] ^= flip << uint(6-pos%8)
+
+			decoded := make([]byte, TRELLIS34_PAYLOAD_LENGTH)
+			corrected, err := Trellis34Decode(corrupted, decoded)
+			if err != nil {
+				t.Fatalf("Trellis34Decode() error: %v", err)
+			}
+			if !bytes.Equal(decoded, payload) {
+				t.Fatalf("dibit %d flip %d: decoded % X, want % X", dibit, flip, decoded, payload)
+			}
+			if corrected != 1 {
+				t.Errorf("dibit %d flip %d: corrected %d points, want 1", dibit, flip, corrected)
+			}
+		}
+	}
+}
+
+// Errors spread across the burst are corrected independently
+func TestTrellis34_CorrectsScatteredErrors(t *testing.T) {
+	payload := trellis34Payloads[3]
+	coded := make([]byte, TRELLIS34_CODED_LENGTH)
+	if err := Trellis34Encode(payload, coded); err != nil {
+		t.Fatalf("Trellis34Encode() error: %v", err)
+	}
+
+	coded[0] ^= 0x40
+	coded[12] ^= 0x08
+	coded[24] ^= 0x80
+
+	decoded := make([]byte, TRELLIS34_PAYLOAD_LENGTH)
+	if _, err := Trellis34Decode(coded, decoded); err != nil {
+		t.Fatalf("Trellis34Decode() error: %v", err)
+	}
+	if !bytes.Equal(decoded, payload) {
+		t.Errorf("Trellis34Decode() = % X, want % X", decoded, payload)
+	}
+}
+
+func TestTrellis34_ShortBuffers(t *testing.T) {
+	if err := Trellis34Encode(make([]byte, 17), make([]byte, TRELLIS34_CODED_LENGTH)); err == nil {
+		t.Error("Trellis34Encode() accepted a 17-byte payload")
+	}
+	if err := Trellis34Encode(make([]byte, TRELLIS34_PAYLOAD_LENGTH), make([]byte, 24)); err == nil {
+		t.Error("Trellis34Encode() accepted a 24-byte output")
+	}
+	if _, err := Trellis34Decode(make([]byte, 24), make([]byte, TRELLIS34_PAYLOAD_LENGTH)); err == nil {
+		t.Error("Trellis34Decode() accepted a 24-byte input")
+	}
+}
+
+func BenchmarkTrellis34_Decode(b *testing.B) {
+	coded := make([]byte, TRELLIS34_CODED_LENGTH)
+	Trellis34Encode(trellis34Payloads[2], coded)
+	payload := make([]byte, TRELLIS34_PAYLOAD_LENGTH)
+
+	for i := 0; i < b.N; i++ {
+		Trellis34Decode(coded, payload)
+	}
+}
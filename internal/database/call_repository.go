@@ -0,0 +1,93 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CallFilter selects calls from the call history; zero fields match all
+type CallFilter struct {
+	From     time.Time // Calls started at or after
+	To       time.Time // Calls started before
+	Callsign string
+	TG       uint32
+	Limit    int // 0 returns every match
+}
+
+// CallRepository provides database operations for the call history
+type CallRepository struct {
+	db *gorm.DB
+}
+
+// NewCallRepository creates a new repository instance
+func NewCallRepository(db *gorm.DB) *CallRepository {
+	return &CallRepository{db: db}
+}
+
+// Record adds a call to the history
+func (r *CallRepository) Record(call *Call) error {
+	if call == nil {
+		return fmt.Errorf("call cannot be nil")
+	}
+	call.Callsign = strings.ToUpper(strings.TrimSpace(call.Callsign))
+	return r.db.Create(call).Error
+}
+
+// List returns the calls matching filter, newest first
+func (r *CallRepository) List(filter CallFilter) ([]Call, error) {
+	var calls []Call
+	err := r.where(r.db, filter).
+		Order("started_at DESC, id DESC").
+		Limit(limitOrAll(filter.Limit)).
+		Find(&calls).Error
+	return calls, err
+}
+
+// LastHeard returns the most recent call of each callsign matching filter,
+// newest first
+func (r *CallRepository) LastHeard(filter CallFilter) ([]Call, error) {
+	// Calls are recorded as they end, so the highest ID is the latest call
+	latest := r.where(r.db.Model(&Call{}), filter).
+		Select("MAX(id)").
+		Group("callsign")
+
+	var calls []Call
+	err := r.db.Where("id IN (?)", latest).
+		Order("started_at DESC, id DESC").
+		Limit(limitOrAll(filter.Limit)).
+		Find(&calls).Error
+	return calls, err
+}
+
+// DeleteBefore removes calls started before t, returning how many were removed
+func (r *CallRepository) DeleteBefore(t time.Time) (int64, error) {
+	result := r.db.Where("started_at < ?", t).Delete(&Call{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *CallRepository) where(tx *gorm.DB, filter CallFilter) *gorm.DB {
+	if !filter.From.IsZero() {
+		tx = tx.Where("started_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		tx = tx.Where("started_at < ?", filter.To)
+	}
+	if filter.Callsign != "" {
+		tx = tx.Where("callsign = ?", strings.ToUpper(filter.Callsign))
+	}
+	if filter.TG != 0 {
+		tx = tx.Where("tg = ?", filter.TG)
+	}
+	return tx
+}
+
+// limitOrAll maps a zero limit to gorm's "no limit"
+func limitOrAll(limit int) int {
+	if limit <= 0 {
+		return -1
+	}
+	return limit
+}
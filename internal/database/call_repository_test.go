@@ -0,0 +1,85 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCallRepository(t *testing.T) {
+	repo := NewCallRepository(newTestDB(t).GetDB())
+
+	base := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	for i, call := range []Call{
+		{Callsign: "n0call", TG: 91, Direction: "YSF->DMR"},
+		{Callsign: "W1AW", TG: 3100, Direction: "DMR->YSF", SrcID: 3100001},
+		{Callsign: "N0CALL", TG: 3100, Direction: "YSF->DMR"},
+		{Callsign: "K1ABC", TG: 91, Direction: "DMR->YSF", SrcID: 3100002},
+	} {
+		call.StartedAt = base.Add(time.Duration(i) * time.Minute)
+		call.Duration = 5
+		if err := repo.Record(&call); err != nil {
+			t.Fatalf("Record error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter CallFilter
+		want   []string
+	}{
+		{"all", CallFilter{}, []string{"K1ABC", "N0CALL", "W1AW", "N0CALL"}},
+		{"callsign", CallFilter{Callsign: "n0call"}, []string{"N0CALL", "N0CALL"}},
+		{"tg", CallFilter{TG: 3100}, []string{"N0CALL", "W1AW"}},
+		{"range", CallFilter{From: base.Add(time.Minute), To: base.Add(3 * time.Minute)}, []string{"N0CALL", "W1AW"}},
+		{"limit", CallFilter{Limit: 1}, []string{"K1ABC"}},
+	}
+	for _, tt := range tests {
+		calls, err := repo.List(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: List error: %v", tt.name, err)
+		}
+		if got := callsigns(calls); !equalStrings(got, tt.want) {
+			t.Errorf("%s: List() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	heard, err := repo.LastHeard(CallFilter{})
+	if err != nil {
+		t.Fatalf("LastHeard error: %v", err)
+	}
+	if got, want := callsigns(heard), []string{"K1ABC", "N0CALL", "W1AW"}; !equalStrings(got, want) {
+		t.Errorf("LastHeard() = %v, want %v", got, want)
+	}
+	if heard[1].TG != 3100 {
+		t.Errorf("LastHeard() N0CALL on TG %d, want the latest call on 3100", heard[1].TG)
+	}
+	heard, _ = repo.LastHeard(CallFilter{TG: 91})
+	if got, want := callsigns(heard), []string{"K1ABC", "N0CALL"}; !equalStrings(got, want) {
+		t.Errorf("LastHeard(TG 91) = %v, want %v", got, want)
+	}
+
+	removed, err := repo.DeleteBefore(base.Add(2 * time.Minute))
+	if err != nil || removed != 2 {
+		t.Errorf("DeleteBefore() = %d, %v, want 2", removed, err)
+	}
+}
+
+func callsigns(calls []Call) []string {
+	var list []string
+	for _, call := range calls {
+		list = append(list, call.Callsign)
+	}
+	return list
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
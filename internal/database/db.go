@@ -2,7 +2,9 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
+	"strings"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -12,12 +14,15 @@ import (
 
 // Config holds database configuration
 type Config struct {
-	Path string // Path to SQLite database file
+	Driver string // Database driver: "sqlite" (default), "postgres" or "mysql"
+	Path   string // Path to SQLite database file
+	DSN    string // Connection string for server databases
 }
 
 // DB wraps the GORM database instance
 type DB struct {
-	db *gorm.DB
+	db     *gorm.DB
+	driver string
 }
 
 // NewDB creates a new database connection with pure Go SQLite driver
@@ -37,10 +42,27 @@ func NewDB(config Config, log *log.Logger) (*DB, error) {
 		gormLog = logger.Default.LogMode(logger.Silent)
 	}
 
-	// Create dialector with pure Go SQLite driver
-	dialector := sqlite.Dialector{
-		DriverName: "sqlite",
-		DSN:        config.Path,
+	driver := config.Driver
+	if driver == "" {
+		driver = DriverSQLite
+	}
+
+	// Create dialector; SQLite uses the built-in pure Go driver
+	var dialector gorm.Dialector
+	if driver == DriverSQLite {
+		dialector = sqlite.Dialector{
+			DriverName: "sqlite",
+			DSN:        buildDSN(config.Path),
+		}
+	} else {
+		factory, err := lookupDriver(driver)
+		if err != nil {
+			return nil, err
+		}
+		if config.DSN == "" {
+			return nil, fmt.Errorf("database driver %s requires a DSN", driver)
+		}
+		dialector = factory(config.DSN)
 	}
 
 	// Open database connection
@@ -57,43 +79,83 @@ func NewDB(config Config, log *log.Logger) (*DB, error) {
 		return nil, err
 	}
 
-	// Configure SQLite for optimal performance
-	if err := configureSQLite(sqlDB); err != nil {
-		return nil, err
+	// Verify the per-connection pragmas took effect
+	if driver == DriverSQLite {
+		if err := checkSQLite(sqlDB, log); err != nil {
+			return nil, err
+		}
 	}
 
-	// Auto-migrate database schema
-	if err := db.AutoMigrate(&DMRUser{}); err != nil {
+	wrapped := &DB{db: db, driver: driver}
+
+	// Apply versioned schema migrations
+	if err := wrapped.Migrate(); err != nil {
 		return nil, err
 	}
 
 	if log != nil {
-		log.Printf("Database initialized: %s", config.Path)
+		version, _ := wrapped.SchemaVersion()
+		location := config.Path
+		if driver != DriverSQLite {
+			location = driver // Never log the DSN, it may contain a password
+		}
+		log.Printf("Database initialized: %s (schema version %d)", location, version)
 	}
 
-	return &DB{db: db}, nil
+	return wrapped, nil
+}
+
+// SQLite settings applied to every pooled connection. Most pragmas are
+// per-connection, so they are passed in the DSN rather than executed once.
+var sqlitePragmas = []string{
+	"journal_mode(WAL)",   // Write-Ahead Logging for better concurrency
+	"synchronous(NORMAL)", // Balanced safety/performance
+	"busy_timeout(5000)",  // 5 second timeout for busy database
+	"cache_size(10000)",   // Cache size in pages
+	"foreign_keys(ON)",    // Enable foreign key constraints
+	"temp_store(memory)",  // Store temporary tables in memory
 }
 
-// configureSQLite applies optimal SQLite settings
-func configureSQLite(sqlDB *sql.DB) error {
-	pragmaSettings := []string{
-		"PRAGMA journal_mode=WAL",        // Write-Ahead Logging for better concurrency
-		"PRAGMA synchronous=NORMAL",      // Balanced safety/performance
-		"PRAGMA busy_timeout=5000",       // 5 second timeout for busy database
-		"PRAGMA cache_size=10000",        // Cache size in pages
-		"PRAGMA foreign_keys=ON",         // Enable foreign key constraints
-		"PRAGMA temp_store=memory",       // Store temporary tables in memory
+// buildDSN adds the SQLite pragmas to a database path
+func buildDSN(path string) string {
+	params := make([]string, len(sqlitePragmas))
+	for i, pragma := range sqlitePragmas {
+		params[i] = "_pragma=" + pragma
 	}
 
-	for _, pragma := range pragmaSettings {
-		if _, err := sqlDB.Exec(pragma); err != nil {
-			return err
-		}
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + strings.Join(params, "&")
+}
+
+// checkSQLite confirms WAL mode and the busy timeout are active
+func checkSQLite(sqlDB *sql.DB, log *log.Logger) error {
+	var journalMode string
+	if err := sqlDB.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil {
+		return err
+	}
+	// In-memory databases cannot use WAL
+	if !strings.EqualFold(journalMode, "wal") && log != nil {
+		log.Printf("Database journal mode is %s, not WAL", journalMode)
 	}
 
+	var busyTimeout int
+	if err := sqlDB.QueryRow("PRAGMA busy_timeout").Scan(&busyTimeout); err != nil {
+		return err
+	}
+	if busyTimeout == 0 && log != nil {
+		log.Printf("Database busy_timeout is not set")
+	}
 	return nil
 }
 
+// Driver returns the name of the database driver in use
+func (db *DB) Driver() string {
+	return db.driver
+}
+
 // GetDB returns the underlying GORM database instance
 func (db *DB) GetDB() *gorm.DB {
 	return db.db
@@ -116,6 +116,13 @@ func (r *DMRUserRepository) DeleteAll() error {
 	return r.db.Where("1 = 1").Delete(&DMRUser{}).Error
 }
 
+// DeleteUpdatedBefore removes users last updated before t, returning how many
+// were removed
+func (r *DMRUserRepository) DeleteUpdatedBefore(t time.Time) (int64, error) {
+	result := r.db.Where("updated_at < ?", t).Delete(&DMRUser{})
+	return result.RowsAffected, result.Error
+}
+
 // GetRecentlyUpdated returns users updated after the specified time
 func (r *DMRUserRepository) GetRecentlyUpdated(since time.Time, limit int) ([]DMRUser, error) {
 	var users []DMRUser
@@ -136,6 +143,20 @@ func (r *DMRUserRepository) FindByCallsignPattern(pattern string, limit int) ([]
 	return users, err
 }
 
+// ForEachIDCallsign calls fn for every user's radio ID and callsign, reading
+// batchSize rows at a time so the full table is never held in memory
+func (r *DMRUserRepository) ForEachIDCallsign(batchSize int, fn func(radioID uint32, callsign string)) error {
+	var batch []DMRUser
+	return r.db.Model(&DMRUser{}).
+		Select("radio_id", "callsign").
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			for _, user := range batch {
+				fn(user.RadioID, user.Callsign)
+			}
+			return nil
+		}).Error
+}
+
 // GetStatistics returns basic database statistics
 func (r *DMRUserRepository) GetStatistics() (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
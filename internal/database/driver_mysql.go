@@ -0,0 +1,14 @@
+//go:build mysql
+
+package database
+
+import (
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterDriver(DriverMySQL, func(dsn string) gorm.Dialector {
+		return mysql.Open(dsn)
+	})
+}
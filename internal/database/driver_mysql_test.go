@@ -0,0 +1,36 @@
+//go:build mysql
+
+package database
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDriverMySQL_Registered(t *testing.T) {
+	factory, err := lookupDriver(DriverMySQL)
+	if err != nil {
+		t.Fatalf("lookupDriver(mysql) error = %v", err)
+	}
+	if name := factory("dsn").Name(); name != "mysql" {
+		t.Errorf("dialector name = %q, want mysql", name)
+	}
+}
+
+// TestDriverMySQL_Server migrates the server named by YSF2DMR_TEST_MYSQL_DSN
+func TestDriverMySQL_Server(t *testing.T) {
+	dsn := os.Getenv("YSF2DMR_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("YSF2DMR_TEST_MYSQL_DSN not set")
+	}
+
+	db, err := NewDB(Config{Driver: DriverMySQL, DSN: dsn}, nil)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	defer db.Close()
+
+	if version, _ := db.SchemaVersion(); version == 0 {
+		t.Error("migrations were not applied")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build postgres
+
+package database
+
+import (
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterDriver(DriverPostgres, func(dsn string) gorm.Dialector {
+		return postgres.Open(dsn)
+	})
+}
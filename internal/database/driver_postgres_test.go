@@ -0,0 +1,36 @@
+//go:build postgres
+
+package database
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDriverPostgres_Registered(t *testing.T) {
+	factory, err := lookupDriver(DriverPostgres)
+	if err != nil {
+		t.Fatalf("lookupDriver(postgres) error = %v", err)
+	}
+	if name := factory("dsn").Name(); name != "postgres" {
+		t.Errorf("dialector name = %q, want postgres", name)
+	}
+}
+
+// TestDriverPostgres_Server migrates the server named by YSF2DMR_TEST_POSTGRES_DSN
+func TestDriverPostgres_Server(t *testing.T) {
+	dsn := os.Getenv("YSF2DMR_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("YSF2DMR_TEST_POSTGRES_DSN not set")
+	}
+
+	db, err := NewDB(Config{Driver: DriverPostgres, DSN: dsn}, nil)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	defer db.Close()
+
+	if version, _ := db.SchemaVersion(); version == 0 {
+		t.Error("migrations were not applied")
+	}
+}
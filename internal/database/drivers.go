@@ -0,0 +1,70 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Database driver names
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+)
+
+// DialectorFactory opens a GORM dialector for a driver specific DSN
+type DialectorFactory func(dsn string) gorm.Dialector
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]DialectorFactory)
+)
+
+// RegisterDriver makes a server database driver available by name
+// The PostgreSQL and MySQL drivers register themselves when the gateway is
+// built with the postgres or mysql build tag, keeping default builds free of
+// their dependencies.
+func RegisterDriver(name string, factory DialectorFactory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if factory == nil {
+		panic("database: RegisterDriver factory is nil")
+	}
+	if _, exists := drivers[name]; exists {
+		panic("database: RegisterDriver called twice for " + name)
+	}
+	drivers[name] = factory
+}
+
+// Drivers returns the sorted names of all available drivers
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := []string{DriverSQLite}
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupDriver(name string) (DialectorFactory, error) {
+	driversMu.RLock()
+	factory, ok := drivers[name]
+	driversMu.RUnlock()
+
+	if ok {
+		return factory, nil
+	}
+	switch name {
+	case DriverPostgres, DriverMySQL:
+		return nil, fmt.Errorf("database driver %s is not compiled in (rebuild with -tags %s)", name, name)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q (available: %v)", name, Drivers())
+	}
+}
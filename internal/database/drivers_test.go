@@ -0,0 +1,54 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestNewDB_DriverNotCompiledIn(t *testing.T) {
+	if _, ok := drivers[DriverPostgres]; ok {
+		t.Skip("built with the postgres driver")
+	}
+
+	_, err := NewDB(Config{Driver: DriverPostgres, DSN: "host=localhost"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "-tags postgres") {
+		t.Errorf("NewDB(postgres) error = %v, want build tag hint", err)
+	}
+
+	if _, err := NewDB(Config{Driver: "oracle", DSN: "x"}, nil); err == nil {
+		t.Error("expected error for unknown driver")
+	}
+}
+
+func TestNewDB_RegisteredDriver(t *testing.T) {
+	RegisterDriver("test-server", func(dsn string) gorm.Dialector {
+		return sqlite.Dialector{DriverName: "sqlite", DSN: dsn}
+	})
+
+	if _, err := NewDB(Config{Driver: "test-server"}, nil); err == nil {
+		t.Error("expected error for missing DSN")
+	}
+
+	db, err := NewDB(Config{Driver: "test-server", DSN: filepath.Join(t.TempDir(), "server.db")}, nil)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	defer db.Close()
+
+	if db.Driver() != "test-server" {
+		t.Errorf("Driver() = %q", db.Driver())
+	}
+	if version, _ := db.SchemaVersion(); version == 0 {
+		t.Error("migrations were not applied")
+	}
+	if err := db.Maintain(MaintenanceConfig{BackupDir: t.TempDir()}); err != nil {
+		t.Errorf("Maintain should be a no-op for server databases: %v", err)
+	}
+	if err := db.Backup(filepath.Join(t.TempDir(), "backup.db")); err == nil {
+		t.Error("Backup should not be supported for server databases")
+	}
+}
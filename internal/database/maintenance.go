@@ -0,0 +1,121 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const backupPrefix = "dmr_users-"
+
+// errNotSQLite is returned by SQLite specific maintenance on server databases
+var errNotSQLite = fmt.Errorf("not supported by this database driver; use the server's own tools")
+
+// Checkpoint copies the WAL into the main database file and truncates it
+func (db *DB) Checkpoint() error {
+	if db.driver != DriverSQLite {
+		return errNotSQLite
+	}
+	return db.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error
+}
+
+// Vacuum rebuilds the database file, reclaiming free pages
+func (db *DB) Vacuum() error {
+	if db.driver != DriverSQLite {
+		return errNotSQLite
+	}
+	return db.db.Exec("VACUUM").Error
+}
+
+// Optimize updates the query planner statistics
+func (db *DB) Optimize() error {
+	if db.driver != DriverSQLite {
+		return errNotSQLite
+	}
+	return db.db.Exec("PRAGMA optimize").Error
+}
+
+// Backup writes a consistent copy of the database to path
+// The copy is made with VACUUM INTO, which is safe while other connections
+// are reading and writing.
+func (db *DB) Backup(path string) error {
+	if db.driver != DriverSQLite {
+		return errNotSQLite
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup file %s already exists", path)
+	}
+	if err := db.db.Exec("VACUUM INTO ?", path).Error; err != nil {
+		return fmt.Errorf("backup to %s failed: %v", path, err)
+	}
+	return nil
+}
+
+// MaintenanceConfig controls the periodic maintenance routine
+type MaintenanceConfig struct {
+	BackupDir  string // Directory for backups; empty disables backups
+	BackupKeep int    // Number of backups to keep (0 keeps all)
+}
+
+// Maintain runs the routine maintenance tasks: optimize, checkpoint, vacuum
+// and an optional timestamped backup with rotation
+// Server databases are maintained by their own server, so this is a no-op.
+func (db *DB) Maintain(config MaintenanceConfig) error {
+	if db.driver != DriverSQLite {
+		return nil
+	}
+	if err := db.Optimize(); err != nil {
+		return fmt.Errorf("optimize failed: %v", err)
+	}
+	if err := db.Checkpoint(); err != nil {
+		return fmt.Errorf("checkpoint failed: %v", err)
+	}
+	if err := db.Vacuum(); err != nil {
+		return fmt.Errorf("vacuum failed: %v", err)
+	}
+
+	if config.BackupDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(config.BackupDir, 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %v", err)
+	}
+
+	name := backupPrefix + time.Now().UTC().Format("20060102-150405") + ".db"
+	if err := db.Backup(filepath.Join(config.BackupDir, name)); err != nil {
+		return err
+	}
+	return pruneBackups(config.BackupDir, config.BackupKeep)
+}
+
+// pruneBackups removes the oldest backups beyond keep
+func pruneBackups(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, ".db") {
+			backups = append(backups, name)
+		}
+	}
+	sort.Strings(backups) // Timestamped names sort oldest first
+
+	for len(backups) > keep {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a single versioned schema change
+// Migrations run in order of Version, each in its own transaction, and are
+// recorded in the schema_migrations table so they are applied only once.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+}
+
+// SchemaMigration records an applied migration
+type SchemaMigration struct {
+	Version   int    `gorm:"primarykey;autoIncrement:false"`
+	Name      string `gorm:"size:100"`
+	AppliedAt time.Time
+}
+
+// TableName specifies the table name for GORM
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migrations lists every schema change in version order
+// Append new migrations here; never edit or reorder released ones.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create dmr_users",
+		Up: func(tx *gorm.DB) error {
+			// AutoMigrate is a no-op for databases created before migrations existed
+			return tx.AutoMigrate(&DMRUser{})
+		},
+	},
+	{
+		Version: 2,
+		Name:    "create calls",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&Call{})
+		},
+	},
+}
+
+// Migrate applies all pending migrations
+func (db *DB) Migrate() error {
+	return db.migrate(migrations)
+}
+
+func (db *DB) migrate(list []Migration) error {
+	if err := db.db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %v", err)
+	}
+
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	for i, m := range list {
+		if i > 0 && m.Version <= list[i-1].Version {
+			return fmt.Errorf("migration %d (%s) is out of order", m.Version, m.Name)
+		}
+		if m.Version <= current {
+			continue
+		}
+
+		err := db.db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   m.Version,
+				Name:      m.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %v", m.Version, m.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// SchemaVersion returns the highest applied migration version (0 if none)
+func (db *DB) SchemaVersion() (int, error) {
+	var version int
+	err := db.db.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %v", err)
+	}
+	return version, nil
+}
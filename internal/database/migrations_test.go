@@ -0,0 +1,119 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	db, err := NewDB(Config{Path: filepath.Join(t.TempDir(), "test.db")}, nil)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestNewDB_WALAndBusyTimeout(t *testing.T) {
+	db := newTestDB(t)
+
+	var journalMode string
+	db.GetDB().Raw("PRAGMA journal_mode").Scan(&journalMode)
+	if journalMode != "wal" {
+		t.Errorf("journal_mode = %q, want wal", journalMode)
+	}
+
+	var busyTimeout int
+	db.GetDB().Raw("PRAGMA busy_timeout").Scan(&busyTimeout)
+	if busyTimeout != 5000 {
+		t.Errorf("busy_timeout = %d, want 5000", busyTimeout)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	db := newTestDB(t)
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion error: %v", err)
+	}
+	if version != migrations[len(migrations)-1].Version {
+		t.Errorf("SchemaVersion() = %d, want %d", version, migrations[len(migrations)-1].Version)
+	}
+
+	applied := 0
+	extra := append(append([]Migration{}, migrations...), Migration{
+		Version: version + 1,
+		Name:    "test table",
+		Up: func(tx *gorm.DB) error {
+			applied++
+			return tx.Exec("CREATE TABLE test_migration (id INTEGER PRIMARY KEY)").Error
+		},
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := db.migrate(extra); err != nil {
+			t.Fatalf("migrate error: %v", err)
+		}
+	}
+	if applied != 1 {
+		t.Errorf("migration applied %d times, want 1", applied)
+	}
+	if version, _ := db.SchemaVersion(); version != extra[len(extra)-1].Version {
+		t.Errorf("SchemaVersion() = %d after migrate, want %d", version, extra[len(extra)-1].Version)
+	}
+}
+
+func TestMigrate_FailureRollsBack(t *testing.T) {
+	db := newTestDB(t)
+	version, _ := db.SchemaVersion()
+
+	err := db.migrate([]Migration{{
+		Version: version + 1,
+		Name:    "broken",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec("CREATE TABLE (").Error
+		},
+	}})
+	if err == nil {
+		t.Fatal("expected migration error")
+	}
+	if after, _ := db.SchemaVersion(); after != version {
+		t.Errorf("SchemaVersion() = %d after failed migration, want %d", after, version)
+	}
+}
+
+func TestMaintain_BackupRotation(t *testing.T) {
+	db := newTestDB(t)
+	backupDir := t.TempDir()
+
+	for _, name := range []string{"dmr_users-20200101-000000.db", "dmr_users-20200102-000000.db"} {
+		os.WriteFile(filepath.Join(backupDir, name), nil, 0644)
+	}
+
+	if err := db.Maintain(MaintenanceConfig{BackupDir: backupDir, BackupKeep: 2}); err != nil {
+		t.Fatalf("Maintain error: %v", err)
+	}
+
+	entries, _ := os.ReadDir(backupDir)
+	if len(entries) != 2 {
+		t.Fatalf("%d backups kept, want 2", len(entries))
+	}
+	if entries[0].Name() != "dmr_users-20200102-000000.db" {
+		t.Errorf("oldest remaining backup = %s, want the 20200102 backup", entries[0].Name())
+	}
+
+	backup, err := NewDB(Config{Path: filepath.Join(backupDir, entries[1].Name())}, nil)
+	if err != nil {
+		t.Fatalf("backup does not open: %v", err)
+	}
+	defer backup.Close()
+	if version, _ := backup.SchemaVersion(); version == 0 {
+		t.Error("backup has no schema version")
+	}
+}
@@ -94,4 +94,22 @@ func (u *DMRUser) SanitizeFields() {
 	u.City = strings.TrimSpace(u.City)
 	u.State = strings.TrimSpace(u.State)
 	u.Country = strings.TrimSpace(u.Country)
-}
\ No newline at end of file
+}
+
+// Call is one bridged call in the call history
+type Call struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	StartedAt time.Time `gorm:"index" json:"started_at"`
+	Duration  float64   `json:"duration"` // Seconds
+	Direction string    `gorm:"size:10" json:"direction"`
+	Callsign  string    `gorm:"index;size:20" json:"callsign"`
+	SrcID     uint32    `json:"src_id,omitempty"`
+	TG        uint32    `gorm:"index" json:"tg"`
+	Private   bool      `json:"private"`
+	Reason    string    `gorm:"size:20" json:"reason,omitempty"`
+}
+
+// TableName specifies the table name for GORM
+func (Call) TableName() string {
+	return "calls"
+}
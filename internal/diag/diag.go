@@ -0,0 +1,216 @@
+// Package diag builds diagnostic bundles for support requests: the recent
+// log, a goroutine dump, the configuration with its secrets redacted, the
+// version and the gateway statistics, in one text file
+// The gateway writes one when it dies on a fatal error, and -diag asks a
+// running gateway for one.
+package diag
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLines is how many log lines a LogBuffer keeps
+const DefaultLines = 1000
+
+// LogBuffer keeps the last lines written to it, for use as a log output
+// alongside the console
+// It is safe for concurrent use.
+type LogBuffer struct {
+	mu      sync.Mutex
+	lines   []string
+	next    int // index of the oldest line once full
+	full    bool
+	partial []byte // line not yet terminated
+}
+
+// NewLogBuffer creates a buffer keeping the last n lines (DefaultLines when 0)
+func NewLogBuffer(n int) *LogBuffer {
+	if n <= 0 {
+		n = DefaultLines
+	}
+	return &LogBuffer{lines: make([]string, n)}
+}
+
+// Write adds the complete lines in p; it never fails
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	data := append(b.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		b.lines[b.next] = string(data[:i])
+		b.next = (b.next + 1) % len(b.lines)
+		b.full = b.full || b.next == 0
+		data = data[i+1:]
+	}
+	b.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+// Lines returns the kept lines, oldest first
+func (b *LogBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.full {
+		return append([]string(nil), b.lines[:b.next]...)
+	}
+	lines := make([]string, 0, len(b.lines))
+	lines = append(lines, b.lines[b.next:]...)
+	return append(lines, b.lines[:b.next]...)
+}
+
+// Bundle is the content of a diagnostic bundle
+type Bundle struct {
+	Reason     string    // why it was written, e.g. the fatal error
+	Version    string    // gateway version
+	Time       time.Time // when it was taken; now when zero
+	Config     string    // configuration file, already passed through Redact
+	Stats      []string  // gateway statistics, one per line
+	Goroutines string    // goroutine dump; the current process's when empty
+	Logs       []string  // recent log lines, oldest first
+}
+
+// WriteTo writes the bundle as text, one section per part
+func (b *Bundle) WriteTo(w io.Writer) (int64, error) {
+	out := &countingWriter{w: w}
+	taken := b.Time
+	if taken.IsZero() {
+		taken = time.Now()
+	}
+	goroutines := b.Goroutines
+	if goroutines == "" {
+		goroutines = Goroutines()
+	}
+
+	fmt.Fprintf(out, "ysf2dmr diagnostic bundle\n")
+	fmt.Fprintf(out, "Time:    %s\n", taken.Format(time.RFC3339))
+	fmt.Fprintf(out, "Version: %s (%s, %s/%s)\n", b.Version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(out, "Reason:  %s\n", b.Reason)
+
+	section(out, "Statistics")
+	writeLines(out, b.Stats)
+	section(out, "Configuration")
+	fmt.Fprint(out, strings.TrimRight(b.Config, "\n")+"\n")
+	section(out, fmt.Sprintf("Log (last %d lines)", len(b.Logs)))
+	writeLines(out, b.Logs)
+	section(out, "Goroutines")
+	fmt.Fprint(out, goroutines)
+
+	return out.n, out.err
+}
+
+// WriteFile writes the bundle to dir as <root>-diag-<time>.txt and returns
+// the file name
+func WriteFile(dir, root string, b *Bundle) (string, error) {
+	if b.Time.IsZero() {
+		b.Time = time.Now()
+	}
+	path := FileName(dir, root, b.Time)
+
+	// The bundle holds the gateway's log and configuration, so keep it private
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+	out := bufio.NewWriter(file)
+	_, err = b.WriteTo(out)
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return path, nil
+}
+
+// FileName returns the name of a bundle taken at t
+func FileName(dir, root string, t time.Time) string {
+	return filepath.Join(dir, fmt.Sprintf("%s-diag-%s.txt", root, t.Format("20060102-150405")))
+}
+
+// Goroutines returns the stack traces of every goroutine
+func Goroutines() string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= 64<<20 {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// Redact replaces the values of passwords, secrets, API keys, tokens and
+// database DSNs in an ini configuration with "<redacted>"
+func Redact(config string) string {
+	lines := strings.Split(config, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == ';' || trimmed[0] == '[' {
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, "=")
+		if !ok || strings.TrimSpace(value) == "" || !secretKey(strings.TrimSpace(key)) {
+			continue
+		}
+		lines[i] = strings.TrimSpace(key) + "=<redacted>"
+	}
+	return strings.Join(lines, "\n")
+}
+
+func secretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range []string{"password", "secret", "apikey", "token"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return key == "dsn"
+}
+
+func section(w io.Writer, title string) {
+	fmt.Fprintf(w, "\n==== %s ====\n", title)
+}
+
+func writeLines(w io.Writer, lines []string) {
+	if len(lines) == 0 {
+		fmt.Fprintln(w, "(none)")
+	}
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// countingWriter counts the bytes written and keeps the first error
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,90 @@
+package diag
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogBuffer(t *testing.T) {
+	b := NewLogBuffer(3)
+	fmt.Fprintf(b, "one\ntwo\n")
+	if got := b.Lines(); strings.Join(got, ",") != "one,two" {
+		t.Errorf("Lines() = %q", got)
+	}
+
+	// A line written in pieces is kept once complete
+	fmt.Fprintf(b, "thr")
+	fmt.Fprintf(b, "ee\nfour\n")
+	if got := b.Lines(); strings.Join(got, ",") != "two,three,four" {
+		t.Errorf("Lines() after wrapping = %q", got)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	config := `[DMR Network]
+Id=3120100
+Password=hunter2
+[YSF Network]
+PeerSecret=s3cret
+# Password=example
+[Database]
+DSN=postgres://ysf:pw@db/ysf2dmr
+[APRS]
+APIKey=
+`
+	got := Redact(config)
+	for _, secret := range []string{"hunter2", "s3cret", "ysf:pw"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("Redact() kept %q", secret)
+		}
+	}
+	for _, kept := range []string{"Id=3120100", "Password=<redacted>", "# Password=example", "APIKey=\n"} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("Redact() lost %q:\n%s", kept, got)
+		}
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	taken := time.Date(2026, 10, 16, 14, 3, 7, 0, time.UTC)
+	path, err := WriteFile(dir, "YSF2DMR", &Bundle{
+		Reason:  "Gateway error: test",
+		Version: "1.0",
+		Time:    taken,
+		Config:  "[Log]\nFileRoot=YSF2DMR\n",
+		Stats:   []string{"Stats: YSF frames: 10"},
+		Logs:    []string{"started"},
+	})
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if want := filepath.Join(dir, "YSF2DMR-diag-20261016-140307.txt"); path != want {
+		t.Errorf("path = %s, want %s", path, want)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{
+		"Reason:  Gateway error: test",
+		"==== Statistics ====\nStats: YSF frames: 10\n",
+		"==== Configuration ====\n[Log]\nFileRoot=YSF2DMR\n",
+		"==== Log (last 1 lines) ====\nstarted\n",
+		"==== Goroutines ====\ngoroutine ",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("bundle lacks %q:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package dtmf parses DTMF-style commands such as "*3100#" or "##".
+//
+// Radios without WiresX can still put a short string where the gateway
+// sees it, e.g. the destination of a call or a text message. A command is
+// a trigger string, for most actions followed by digits and an optional
+// terminator; the triggers are configurable.
+package dtmf
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Action is what a command asks the gateway to do
+type Action int
+
+const (
+	Connect    Action = iota // link to talk group Number
+	Private                  // private call to DMR ID Number
+	Disconnect               // unlink
+	Select                   // link to preset Number, counting from 1
+)
+
+// Triggers are the strings starting each command; an empty trigger
+// disables its action
+type Triggers struct {
+	Connect    string
+	Private    string
+	Disconnect string
+	Select     string
+	Terminator string // optional after the digits
+}
+
+// Command is a parsed command
+type Command struct {
+	Action Action
+	Number uint32
+}
+
+// Parser recognizes commands
+type Parser struct {
+	disconnect string
+	terminator string
+	prefixes   []prefix // longest first, so "**" wins over "*"
+}
+
+type prefix struct {
+	trigger string
+	action  Action
+}
+
+// NewParser creates a parser for triggers
+func NewParser(triggers Triggers) *Parser {
+	p := &Parser{disconnect: triggers.Disconnect, terminator: triggers.Terminator}
+	for _, candidate := range []prefix{
+		{triggers.Connect, Connect},
+		{triggers.Private, Private},
+		{triggers.Select, Select},
+	} {
+		if candidate.trigger != "" {
+			p.prefixes = append(p.prefixes, candidate)
+		}
+	}
+	sort.SliceStable(p.prefixes, func(i, j int) bool {
+		return len(p.prefixes[i].trigger) > len(p.prefixes[j].trigger)
+	})
+	return p
+}
+
+// Parse returns the command in text, ignoring surrounding spaces
+func (p *Parser) Parse(text string) (Command, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Command{}, false
+	}
+	if p.disconnect != "" && (text == p.disconnect || text == p.disconnect+p.terminator) {
+		return Command{Action: Disconnect}, true
+	}
+
+	for _, candidate := range p.prefixes {
+		if !strings.HasPrefix(text, candidate.trigger) {
+			continue
+		}
+		digits := strings.TrimPrefix(text, candidate.trigger)
+		if p.terminator != "" {
+			digits = strings.TrimSuffix(digits, p.terminator)
+		}
+		if digits == "" || strings.TrimLeft(digits, "0123456789") != "" {
+			continue
+		}
+		number, err := strconv.ParseUint(digits, 10, 32)
+		if err != nil {
+			continue
+		}
+		return Command{Action: candidate.action, Number: uint32(number)}, true
+	}
+	return Command{}, false
+}
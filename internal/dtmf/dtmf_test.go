@@ -0,0 +1,47 @@
+package dtmf
+
+import "testing"
+
+func TestParser_Parse(t *testing.T) {
+	parser := NewParser(Triggers{
+		Connect:    "*",
+		Private:    "**",
+		Disconnect: "##",
+		Select:     "#",
+		Terminator: "#",
+	})
+
+	tests := []struct {
+		text string
+		want Command
+		ok   bool
+	}{
+		{"*3100#", Command{Connect, 3100}, true},
+		{"*91", Command{Connect, 91}, true},
+		{" **3109999# ", Command{Private, 3109999}, true},
+		{"##", Command{Disconnect, 0}, true},
+		{"###", Command{Disconnect, 0}, true},
+		{"#2#", Command{Select, 2}, true},
+		{"*", Command{}, false},
+		{"*31A#", Command{}, false},
+		{"*99999999999#", Command{}, false},
+		{"ALL", Command{}, false},
+		{"", Command{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parser.Parse(tt.text)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParser_DisabledTriggers(t *testing.T) {
+	parser := NewParser(Triggers{Disconnect: "UNLINK"})
+	if _, ok := parser.Parse("*3100#"); ok {
+		t.Error("Parse() accepted a connect command with no Connect trigger")
+	}
+	if got, ok := parser.Parse("UNLINK"); !ok || got.Action != Disconnect {
+		t.Errorf("Parse(UNLINK) = %+v, %v", got, ok)
+	}
+}
@@ -0,0 +1,112 @@
+// Package events provides a small publish/subscribe bus for gateway events
+// such as call start/end and emergency calls.
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Type identifies the kind of event
+type Type string
+
+const (
+	CallStart   Type = "call_start"
+	CallEnd     Type = "call_end"
+	Emergency   Type = "emergency"
+	TalkerAlias Type = "talker_alias" // Alias sent by a DMR caller
+	LinkUp      Type = "link_up"
+	LinkDown    Type = "link_down"
+	Paused      Type = "bridge_paused"
+	Resumed     Type = "bridge_resumed"
+	Destination Type = "destination" // Linked talk group or private call changed
+	Stats       Type = "stats"       // Periodic frame counters
+	AudioLevel  Type = "audio_level" // Voice level of the call in progress
+)
+
+// Priority indicates how urgently subscribers should handle an event
+type Priority int
+
+const (
+	PriorityNormal Priority = iota
+	PriorityHigh
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+// MarshalText encodes the priority by name
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText decodes a priority name
+func (p *Priority) UnmarshalText(text []byte) error {
+	if string(text) == PriorityHigh.String() {
+		*p = PriorityHigh
+	} else {
+		*p = PriorityNormal
+	}
+	return nil
+}
+
+// Event is a single gateway event
+type Event struct {
+	Type     Type              `json:"type"`
+	Priority Priority          `json:"priority"`
+	Time     time.Time         `json:"time"`
+	Source   string            `json:"source,omitempty"` // Originating network ("YSF" or "DMR")
+	Fields   map[string]string `json:"fields,omitempty"` // Event specific details
+}
+
+// Handler receives published events
+type Handler func(Event)
+
+// Bus delivers events to all subscribers
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+// NewBus creates a new event bus
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a handler for all events
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish delivers an event to every subscriber synchronously
+// A panicking handler is logged and does not affect the others.
+func (b *Bus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := make([]Handler, len(b.handlers))
+	copy(handlers, b.handlers)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("Event handler panic for %s: %v", event.Type, r)
+				}
+			}()
+			handler(event)
+		}()
+	}
+}
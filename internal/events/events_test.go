@@ -0,0 +1,46 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestBus_Publish(t *testing.T) {
+	bus := NewBus()
+
+	var received []Event
+	bus.Subscribe(func(e Event) { received = append(received, e) })
+	bus.Subscribe(func(e Event) { panic("bad handler") })
+	bus.Subscribe(func(e Event) { received = append(received, e) })
+
+	bus.Publish(Event{Type: Emergency, Priority: PriorityHigh, Source: "DMR"})
+
+	if len(received) != 2 {
+		t.Fatalf("received %d events, want 2", len(received))
+	}
+	if received[0].Time.IsZero() {
+		t.Error("event time not set")
+	}
+	if received[0].Priority.String() != "high" {
+		t.Errorf("Priority = %s, want high", received[0].Priority)
+	}
+}
+
+func TestEvent_JSON(t *testing.T) {
+	event := Event{
+		Type:     LinkUp,
+		Priority: PriorityHigh,
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Source:   "DMR",
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	want := `{"type":"link_up","priority":"high","time":"2024-01-02T03:04:05Z","source":"DMR"}`
+	if string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,165 @@
+// Package fastdata stores the pictures and other data YSF radios send in data
+// FR mode, which cannot be bridged to DMR, so they can be fetched from the
+// web dashboard instead.
+//
+// Each transmission is written as <time>_<source>.jpg when it holds a JPEG
+// picture, otherwise as <time>_<source>.bin with the data as received.
+package fastdata
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultMaxFiles is how many files are kept when no limit is configured
+const DefaultMaxFiles = 100
+
+// timeFormat starts each file name
+const timeFormat = "20060102-150405.000"
+
+// JPEG start and end of image markers
+var (
+	jpegStart = []byte{0xFF, 0xD8, 0xFF}
+	jpegEnd   = []byte{0xFF, 0xD9}
+)
+
+// Item is a stored transmission
+type Item struct {
+	Name   string    `json:"name"`
+	Source string    `json:"source"`
+	Kind   string    `json:"kind"` // "jpeg" or "binary"
+	Size   int64     `json:"size"`
+	Time   time.Time `json:"time"`
+}
+
+// Store keeps the last transmissions in a directory
+type Store struct {
+	dir      string
+	maxFiles int
+}
+
+// New creates a store writing to dir that keeps maxFiles files
+// (DefaultMaxFiles if 0)
+func New(dir string, maxFiles int) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create fast data directory %s: %v", dir, err)
+	}
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxFiles
+	}
+	return &Store{dir: dir, maxFiles: maxFiles}, nil
+}
+
+// ExtractJPEG returns the JPEG picture in data, if there is one
+func ExtractJPEG(data []byte) ([]byte, bool) {
+	start := bytes.Index(data, jpegStart)
+	if start < 0 {
+		return nil, false
+	}
+	end := bytes.LastIndex(data, jpegEnd)
+	if end < start+len(jpegStart) {
+		return nil, false
+	}
+	return data[start : end+len(jpegEnd)], true
+}
+
+// Save writes a transmission from source received at t and removes the
+// oldest files beyond the limit
+func (s *Store) Save(source string, t time.Time, data []byte) (Item, error) {
+	item := Item{Source: strings.TrimSpace(source), Kind: "binary", Time: t}
+	ext := ".bin"
+	if picture, ok := ExtractJPEG(data); ok {
+		data, item.Kind, ext = picture, "jpeg", ".jpg"
+	}
+
+	item.Name = fmt.Sprintf("%s_%s%s", t.UTC().Format(timeFormat), sanitize(item.Source), ext)
+	if err := os.WriteFile(filepath.Join(s.dir, item.Name), data, 0644); err != nil {
+		return Item{}, fmt.Errorf("failed to write %s: %v", item.Name, err)
+	}
+	item.Size = int64(len(data))
+
+	s.prune()
+	return item, nil
+}
+
+// List returns the stored transmissions, newest first
+func (s *Store) List() ([]Item, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fast data directory: %v", err)
+	}
+
+	var items []Item
+	for _, entry := range entries {
+		item, ok := parseName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			item.Size = info.Size()
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].Time.Equal(items[j].Time) {
+			return items[i].Time.After(items[j].Time)
+		}
+		return items[i].Name > items[j].Name
+	})
+	return items, nil
+}
+
+// Path returns the path of a stored file, refusing names that are not ones
+// the store writes
+func (s *Store) Path(name string) (string, bool) {
+	if _, ok := parseName(name); !ok || filepath.Base(name) != name {
+		return "", false
+	}
+	return filepath.Join(s.dir, name), true
+}
+
+// prune removes the oldest files beyond the limit
+func (s *Store) prune() {
+	items, err := s.List()
+	if err != nil {
+		return
+	}
+	for _, item := range items[min(len(items), s.maxFiles):] {
+		os.Remove(filepath.Join(s.dir, item.Name))
+	}
+}
+
+// parseName returns the item a file name written by Save describes
+func parseName(name string) (Item, bool) {
+	ext := filepath.Ext(name)
+	kind := map[string]string{".jpg": "jpeg", ".bin": "binary"}[ext]
+	timestamp, source, found := strings.Cut(strings.TrimSuffix(name, ext), "_")
+	if kind == "" || !found {
+		return Item{}, false
+	}
+	t, err := time.Parse(timeFormat, timestamp)
+	if err != nil {
+		return Item{}, false
+	}
+	return Item{Name: name, Source: source, Kind: kind, Time: t}, true
+}
+
+// sanitize makes a callsign safe for use in a file name
+func sanitize(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
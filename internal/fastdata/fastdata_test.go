@@ -0,0 +1,103 @@
+package fastdata
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExtractJPEG(t *testing.T) {
+	picture := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x01, 0x02, 0xFF, 0xD9}
+	data := append(append([]byte{0x00, 0x5E, 0x11}, picture...), 0x03, 0x00)
+
+	got, ok := ExtractJPEG(data)
+	if !ok || !bytes.Equal(got, picture) {
+		t.Errorf("ExtractJPEG() = % X, %v, want % X", got, ok, picture)
+	}
+	if _, ok := ExtractJPEG([]byte("NOT A PICTURE")); ok {
+		t.Error("ExtractJPEG() found a picture in text")
+	}
+}
+
+func TestStore_SaveAndList(t *testing.T) {
+	store, err := New(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	picture := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0xFF, 0xD9}
+	first, err := store.Save("N0CALL", start, picture)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if first.Kind != "jpeg" || first.Name != "20240501-120000.000_N0CALL.jpg" {
+		t.Errorf("Save() = %+v, want a jpeg named after the time and source", first)
+	}
+	second, err := store.Save("K1ABC/P", start.Add(time.Second), []byte{0x01, 0x02})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if second.Kind != "binary" || second.Name != "20240501-120001.000_K1ABC_P.bin" {
+		t.Errorf("Save() = %+v, want binary data with a safe name", second)
+	}
+
+	items, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(items) != 2 || items[0].Name != second.Name || items[1].Name != first.Name {
+		t.Fatalf("List() = %+v, want newest first", items)
+	}
+	if items[1].Size != int64(len(picture)) || !items[1].Time.Equal(start) {
+		t.Errorf("List()[1] = %+v, want size %d at %v", items[1], len(picture), start)
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	store, err := New(t.TempDir(), 2)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		if _, err := store.Save("N0CALL", start.Add(time.Duration(i)*time.Minute), []byte{byte(i)}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	items, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(items) != 2 || !items[1].Time.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("List() = %+v, want the last 2 files", items)
+	}
+}
+
+func TestStore_Path(t *testing.T) {
+	store, err := New(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	item, err := store.Save("N0CALL", time.Now(), []byte{0x01})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	path, ok := store.Path(item.Name)
+	if !ok {
+		t.Fatalf("Path(%q) refused a stored file", item.Name)
+	}
+	if data, err := os.ReadFile(path); err != nil || !bytes.Equal(data, []byte{0x01}) {
+		t.Errorf("ReadFile(%q) = % X, %v", path, data, err)
+	}
+
+	for _, name := range []string{"../etc/passwd", "ysf2dmr.ini", "../" + item.Name, ""} {
+		if _, ok := store.Path(name); ok {
+			t.Errorf("Path(%q) accepted a name the store does not write", name)
+		}
+	}
+}
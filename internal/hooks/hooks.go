@@ -0,0 +1,134 @@
+// Package hooks runs operator supplied commands on gateway events.
+//
+// Each command receives the event details in YSF2DMR_* environment
+// variables. Commands run in the background with a timeout, and at most
+// a fixed number run at once; events arriving while all slots are busy
+// are dropped rather than delaying the gateway.
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/events"
+)
+
+// Hook runner defaults
+const (
+	DEFAULT_TIMEOUT        = 10 * time.Second
+	DEFAULT_MAX_CONCURRENT = 4
+
+	envPrefix = "YSF2DMR_"
+)
+
+// Runner executes the configured command for each event type
+type Runner struct {
+	commands map[events.Type][]string
+	timeout  time.Duration
+	slots    chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewRunner creates a hook runner
+// commands maps an event type to a command line (split on whitespace, no shell).
+func NewRunner(commands map[events.Type]string, timeout time.Duration, maxConcurrent int) *Runner {
+	if timeout <= 0 {
+		timeout = DEFAULT_TIMEOUT
+	}
+	if maxConcurrent <= 0 {
+		maxConcurrent = DEFAULT_MAX_CONCURRENT
+	}
+
+	r := &Runner{
+		commands: make(map[events.Type][]string),
+		timeout:  timeout,
+		slots:    make(chan struct{}, maxConcurrent),
+	}
+	for eventType, command := range commands {
+		if args := strings.Fields(command); len(args) > 0 {
+			r.commands[eventType] = args
+		}
+	}
+	return r
+}
+
+// Enabled returns true if at least one hook is configured
+func (r *Runner) Enabled() bool {
+	return len(r.commands) > 0
+}
+
+// Handle runs the hook for an event; it is suitable as an events.Handler
+func (r *Runner) Handle(event events.Event) {
+	args, ok := r.commands[event.Type]
+	if !ok {
+		return
+	}
+
+	select {
+	case r.slots <- struct{}{}:
+	default:
+		log.Printf("Hook for %s dropped: %d hooks already running", event.Type, cap(r.slots))
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer func() {
+			<-r.slots
+			r.wg.Done()
+		}()
+		if err := r.run(args, event); err != nil {
+			log.Printf("Hook for %s failed: %v", event.Type, err)
+		}
+	}()
+}
+
+// Wait blocks until all running hooks have finished
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) run(args []string, event events.Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), Environment(event)...)
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s timed out after %v", args[0], r.timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Environment returns the YSF2DMR_* variables describing an event
+// Event fields are exported upper-cased, e.g. "callsign" as YSF2DMR_CALLSIGN.
+func Environment(event events.Event) []string {
+	env := []string{
+		envPrefix + "EVENT=" + string(event.Type),
+		envPrefix + "PRIORITY=" + event.Priority.String(),
+		envPrefix + "SOURCE=" + event.Source,
+		envPrefix + "TIME=" + event.Time.UTC().Format(time.RFC3339),
+	}
+
+	keys := make([]string, 0, len(event.Fields))
+	for key := range event.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, envPrefix+strings.ToUpper(key)+"="+event.Fields[key])
+	}
+	return env
+}
@@ -0,0 +1,76 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/events"
+)
+
+func TestEnvironment(t *testing.T) {
+	event := events.Event{
+		Type:   events.CallEnd,
+		Time:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Source: "YSF",
+		Fields: map[string]string{"callsign": "W1AW", "duration": "12.5"},
+	}
+
+	env := strings.Join(Environment(event), "\n")
+	for _, want := range []string{
+		"YSF2DMR_EVENT=call_end",
+		"YSF2DMR_SOURCE=YSF",
+		"YSF2DMR_TIME=2024-01-02T03:04:05Z",
+		"YSF2DMR_CALLSIGN=W1AW",
+		"YSF2DMR_DURATION=12.5",
+	} {
+		if !strings.Contains(env, want) {
+			t.Errorf("environment missing %q:\n%s", want, env)
+		}
+	}
+}
+
+func TestRunner_Handle(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("no /bin/sh")
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "hook.sh")
+	out := filepath.Join(dir, "out")
+	os.WriteFile(script, []byte("#!/bin/sh\necho \"$YSF2DMR_EVENT $YSF2DMR_CALLSIGN\" > "+out+"\n"), 0755)
+
+	r := NewRunner(map[events.Type]string{events.CallStart: script}, time.Second, 1)
+	if !r.Enabled() {
+		t.Fatal("Enabled() = false, want true")
+	}
+
+	r.Handle(events.Event{Type: events.CallEnd}) // No hook configured
+	r.Handle(events.Event{Type: events.CallStart, Fields: map[string]string{"callsign": "W1AW"}})
+	r.Wait()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "call_start W1AW" {
+		t.Errorf("hook output = %q, want %q", got, "call_start W1AW")
+	}
+}
+
+func TestRunner_Timeout(t *testing.T) {
+	if _, err := os.Stat("/bin/sleep"); err != nil {
+		t.Skip("no /bin/sleep")
+	}
+
+	r := NewRunner(nil, 50*time.Millisecond, 1)
+	start := time.Now()
+	if err := r.run([]string{"/bin/sleep", "5"}, events.Event{Type: events.LinkDown}); err == nil {
+		t.Error("expected timeout error")
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("hook was not killed at the timeout")
+	}
+}
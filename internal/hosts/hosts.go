@@ -0,0 +1,261 @@
+// Package hosts downloads host and talk group files (DMR master lists, XLX
+// host files, TG lists) from configurable URLs and caches them on disk
+// A file that cannot be downloaded keeps its cached copy, so the gateway still
+// starts offline.
+package hosts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultInterval is how often the files are downloaded again
+	DefaultInterval = 24 * time.Hour
+
+	// RequestTimeout for each download
+	RequestTimeout = 30 * time.Second
+
+	// MaxSize is the largest file accepted, so a bad URL cannot fill the disk
+	MaxSize = 16 << 20
+)
+
+// File is a file kept up to date from a URL
+type File struct {
+	Name string // for logs and the status, e.g. "TGList"
+	URL  string
+	Path string // cached copy read by the gateway
+}
+
+// FileStatus describes the last download of one file
+type FileStatus struct {
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	Path        string    `json:"path"`
+	LastAttempt time.Time `json:"last_attempt"`
+	LastUpdate  time.Time `json:"last_update"` // last download that changed the cached copy
+	Size        int64     `json:"size"`
+	Cached      bool      `json:"cached"` // a cached copy is available
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// Config holds the fetcher configuration
+type Config struct {
+	Files       []File
+	Interval    time.Duration // default DefaultInterval
+	HTTPTimeout time.Duration // default RequestTimeout
+}
+
+// Fetcher downloads the configured files at startup and on a schedule
+type Fetcher struct {
+	files      []File
+	interval   time.Duration
+	httpClient *http.Client
+	logger     *log.Logger
+	onUpdate   func(File) // called after a download changes a file
+
+	mu     sync.Mutex
+	status map[string]FileStatus
+}
+
+// New creates a fetcher; files without a URL or path are ignored
+func New(config Config, logger *log.Logger) *Fetcher {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.HTTPTimeout <= 0 {
+		config.HTTPTimeout = RequestTimeout
+	}
+
+	f := &Fetcher{
+		interval:   config.Interval,
+		httpClient: &http.Client{Timeout: config.HTTPTimeout},
+		logger:     logger,
+		status:     make(map[string]FileStatus),
+	}
+	for _, file := range config.Files {
+		if file.URL == "" || file.Path == "" {
+			continue
+		}
+		f.files = append(f.files, file)
+		status := FileStatus{Name: file.Name, URL: file.URL, Path: file.Path}
+		if info, err := os.Stat(file.Path); err == nil {
+			status.Cached, status.Size = true, info.Size()
+		}
+		f.status[file.Name] = status
+	}
+	return f
+}
+
+// SetOnUpdate sets a function called, from the fetcher's goroutine, after a
+// download changes a file
+// Must be called before Start.
+func (f *Fetcher) SetOnUpdate(fn func(File)) {
+	f.onUpdate = fn
+}
+
+// Files returns the files the fetcher keeps up to date
+func (f *Fetcher) Files() []File {
+	return f.files
+}
+
+// Start downloads the files again every interval until ctx is done
+// Call FetchAll first for the startup download.
+func (f *Fetcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.FetchAll(ctx)
+		}
+	}
+}
+
+// FetchAll downloads every file, returning the first error
+// Files that fail keep their cached copy.
+func (f *Fetcher) FetchAll(ctx context.Context) error {
+	var first error
+	for _, file := range f.files {
+		err := f.Fetch(ctx, file)
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Fetch downloads one file, replacing the cached copy when it has changed
+func (f *Fetcher) Fetch(ctx context.Context, file File) error {
+	status := f.Status(file.Name)
+	status.LastAttempt = time.Now()
+
+	changed, size, err := f.download(ctx, file)
+	if err != nil {
+		status.LastError = err.Error()
+		if status.Cached {
+			f.logf("%s download failed, using cached %s: %v", file.Name, file.Path, err)
+		} else {
+			f.logf("%s download failed and no cached copy exists: %v", file.Name, err)
+		}
+	} else {
+		status.LastError = ""
+		status.Cached = true
+		if changed {
+			status.LastUpdate, status.Size = time.Now(), size
+			f.logf("%s updated from %s (%d bytes)", file.Name, file.URL, size)
+		}
+	}
+
+	f.mu.Lock()
+	f.status[file.Name] = status
+	f.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", file.Name, err)
+	}
+	if changed && f.onUpdate != nil {
+		f.onUpdate(file)
+	}
+	return nil
+}
+
+// Status returns the status of one file
+func (f *Fetcher) Status(name string) FileStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.status[name]
+}
+
+// StatusAll returns the status of every file in configuration order
+func (f *Fetcher) StatusAll() []FileStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	statuses := make([]FileStatus, 0, len(f.files))
+	for _, file := range f.files {
+		statuses = append(statuses, f.status[file.Name])
+	}
+	return statuses
+}
+
+// download fetches file.URL into file.Path, reporting whether the cached copy
+// changed
+// The request carries If-Modified-Since from the cached copy, and the new
+// copy is written to a temporary file and renamed over the old one so readers
+// never see a partial file.
+func (f *Fetcher) download(ctx context.Context, file File) (bool, int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, file.URL, nil)
+	if err != nil {
+		return false, 0, err
+	}
+	req.Header.Set("User-Agent", "YSF2DMR-Go/1.0")
+	if info, err := os.Stat(file.Path); err == nil {
+		req.Header.Set("If-Modified-Since", info.ModTime().UTC().Format(http.TimeFormat))
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return false, 0, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return false, 0, nil
+	default:
+		return false, 0, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	if dir := filepath.Dir(file.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return false, 0, err
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file.Path), filepath.Base(file.Path)+".*.tmp")
+	if err != nil {
+		return false, 0, err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	size, err := io.Copy(tmp, io.LimitReader(resp.Body, MaxSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	if size == 0 {
+		return false, 0, fmt.Errorf("empty response")
+	}
+	if size > MaxSize {
+		return false, 0, fmt.Errorf("larger than %d bytes", MaxSize)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return false, 0, err
+	}
+	if err := os.Rename(tmp.Name(), file.Path); err != nil {
+		return false, 0, err
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		os.Chtimes(file.Path, modified, modified)
+	}
+	return true, size, nil
+}
+
+func (f *Fetcher) logf(format string, args ...interface{}) {
+	if f.logger != nil {
+		f.logger.Printf(format, args...)
+	}
+}
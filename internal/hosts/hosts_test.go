@@ -0,0 +1,108 @@
+package hosts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const tgList = "91;1;Worldwide;Worldwide\n3100;1;USA;Nationwide\n"
+
+func TestFetcher_DownloadAndCache(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modified.After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		w.Write([]byte(tgList))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cache", "TGList_BM.txt")
+	file := File{Name: "TGList", URL: server.URL, Path: path}
+	f := New(Config{Files: []File{file}}, nil)
+
+	var updates []string
+	f.SetOnUpdate(func(file File) { updates = append(updates, file.Name) })
+
+	if err := f.FetchAll(context.Background()); err != nil {
+		t.Fatalf("FetchAll() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != tgList {
+		t.Fatalf("cached file = %q, %v", data, err)
+	}
+	status := f.Status("TGList")
+	if !status.Cached || status.Size != int64(len(tgList)) || status.LastUpdate.IsZero() || status.LastError != "" {
+		t.Errorf("status after download = %+v", status)
+	}
+
+	// Unchanged on the server: no update
+	if err := f.FetchAll(context.Background()); err != nil {
+		t.Fatalf("second FetchAll() error = %v", err)
+	}
+	if requests != 2 || len(updates) != 1 {
+		t.Errorf("requests = %d, updates = %v; want 2 requests and one update", requests, updates)
+	}
+}
+
+func TestFetcher_OfflineFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "XLXHosts.txt")
+	if err := os.WriteFile(path, []byte("cached"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := New(Config{Files: []File{{Name: "XLX", URL: server.URL, Path: path}}}, nil)
+	f.SetOnUpdate(func(File) { t.Error("update reported for a failed download") })
+	if err := f.FetchAll(context.Background()); err == nil {
+		t.Error("FetchAll() error = nil for an unavailable server")
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "cached" {
+		t.Errorf("cached copy = %q, want it kept", data)
+	}
+	if status := f.Status("XLX"); !status.Cached || status.LastError == "" {
+		t.Errorf("status = %+v, want cached with an error", status)
+	}
+}
+
+func TestFetcher_RejectsEmptyResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "DMRHosts.txt")
+	f := New(Config{Files: []File{{Name: "DMRHosts", URL: server.URL, Path: path}}}, nil)
+	if err := f.FetchAll(context.Background()); err == nil {
+		t.Error("FetchAll() error = nil for an empty response")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("empty response was cached (stat error %v)", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 0 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestNew_IgnoresIncompleteFiles(t *testing.T) {
+	f := New(Config{Files: []File{
+		{Name: "NoURL", Path: "x"},
+		{Name: "NoPath", URL: "http://example.com"},
+		{Name: "TGList", URL: "http://example.com", Path: "TGList.txt"},
+	}}, nil)
+	if files := f.Files(); len(files) != 1 || files[0].Name != "TGList" {
+		t.Errorf("Files() = %+v, want only TGList", files)
+	}
+}
@@ -0,0 +1,145 @@
+// Package i18n holds the text the gateway shows on radios and writes to its
+// log in each supported language, and finds translated voice prompts.
+//
+// Text shown on radios is plain ASCII, as YSF radios have no accented
+// characters; log messages may use any UTF-8. A message missing from a
+// language falls back to English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is used when no language is configured
+const DefaultLanguage = "en"
+
+// Key identifies a message in the catalog
+type Key string
+
+// Messages
+const (
+	WiresXDescription Key = "wiresx.description" // Talk groups without a TG list description, up to 14 characters
+	WiresXLocal       Key = "wiresx.local"       // TG 9, up to 16 characters
+	WiresXParrot      Key = "wiresx.parrot"      // TG 9990
+	WiresXUnlink      Key = "wiresx.unlink"      // TG 4000
+
+	// CallSummary is logged when a call ends, with the direction, caller,
+	// destination and duration as arguments 1 to 4
+	CallSummary Key = "log.call_summary"
+)
+
+// catalogs holds the messages of every supported language
+var catalogs = map[string]map[Key]string{
+	"en": {
+		WiresXDescription: "Description",
+		WiresXLocal:       "LOCAL",
+		WiresXParrot:      "PARROT",
+		WiresXUnlink:      "UNLINK",
+		CallSummary:       "%[1]s call from %[2]s to %[3]s ended after %[4]s",
+	},
+	"es": {
+		WiresXDescription: "Descripcion",
+		WiresXLocal:       "LOCAL",
+		WiresXParrot:      "LORO",
+		WiresXUnlink:      "DESCONECTAR",
+		CallSummary:       "Llamada %[1]s de %[2]s a %[3]s finalizada tras %[4]s",
+	},
+	"de": {
+		WiresXDescription: "Beschreibung",
+		WiresXLocal:       "LOKAL",
+		WiresXParrot:      "PAPAGEI",
+		WiresXUnlink:      "TRENNEN",
+		CallSummary:       "%[1]s-Anruf von %[2]s an %[3]s nach %[4]s beendet",
+	},
+	"fr": {
+		WiresXDescription: "Description",
+		WiresXLocal:       "LOCAL",
+		WiresXParrot:      "PERROQUET",
+		WiresXUnlink:      "DECONNEXION",
+		CallSummary:       "Appel %[1]s de %[2]s vers %[3]s terminé après %[4]s",
+	},
+	"it": {
+		WiresXDescription: "Descrizione",
+		WiresXLocal:       "LOCALE",
+		WiresXParrot:      "PAPPAGALLO",
+		WiresXUnlink:      "SCOLLEGA",
+		CallSummary:       "Chiamata %[1]s da %[2]s a %[3]s terminata dopo %[4]s",
+	},
+	"pt": {
+		WiresXDescription: "Descricao",
+		WiresXLocal:       "LOCAL",
+		WiresXParrot:      "PAPAGAIO",
+		WiresXUnlink:      "DESLIGAR",
+		CallSummary:       "Chamada %[1]s de %[2]s para %[3]s encerrada após %[4]s",
+	},
+}
+
+// Catalog is the message set of one language
+type Catalog struct {
+	language string
+	messages map[Key]string
+}
+
+// New returns the catalog for language, e.g. "de" or "es_ES"
+// A region suffix is ignored; an empty language selects English.
+func New(language string) (*Catalog, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		language = DefaultLanguage
+	}
+	if i := strings.IndexAny(language, "_-"); i > 0 {
+		language = language[:i]
+	}
+	messages, ok := catalogs[language]
+	if !ok {
+		return nil, fmt.Errorf("unsupported language %q, want one of %s", language, strings.Join(Languages(), ", "))
+	}
+	return &Catalog{language: language, messages: messages}, nil
+}
+
+// Languages returns the supported language codes
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Language returns the language code of the catalog
+func (c *Catalog) Language() string {
+	return c.language
+}
+
+// Text returns the message for key
+func (c *Catalog) Text(key Key) string {
+	if text, ok := c.messages[key]; ok {
+		return text
+	}
+	return catalogs[DefaultLanguage][key]
+}
+
+// Format returns the message for key with args filled in
+func (c *Catalog) Format(key Key, args ...interface{}) string {
+	return fmt.Sprintf(c.Text(key), args...)
+}
+
+// Prompt returns the recording of the voice prompt at path in the catalog's
+// language: "timeout.ambe" becomes "timeout.de.ambe" when that file exists,
+// otherwise path is returned unchanged
+func (c *Catalog) Prompt(path string) string {
+	if path == "" {
+		return path
+	}
+	ext := filepath.Ext(path)
+	translated := strings.TrimSuffix(path, ext) + "." + c.language + ext
+	if _, err := os.Stat(translated); err == nil {
+		return translated
+	}
+	return path
+}
@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{"", "en"},
+		{"es", "es"},
+		{"de_DE", "de"},
+		{" FR-ca ", "fr"},
+	}
+	for _, tt := range tests {
+		catalog, err := New(tt.language)
+		if err != nil {
+			t.Fatalf("New(%q) error = %v", tt.language, err)
+		}
+		if catalog.Language() != tt.want {
+			t.Errorf("New(%q).Language() = %q, want %q", tt.language, catalog.Language(), tt.want)
+		}
+	}
+
+	if _, err := New("xx"); err == nil {
+		t.Error("New(xx) accepted an unsupported language")
+	}
+}
+
+func TestCatalogs(t *testing.T) {
+	limits := map[Key]int{WiresXDescription: 14, WiresXLocal: 16, WiresXParrot: 16, WiresXUnlink: 16}
+	for language, messages := range catalogs {
+		for key := range catalogs[DefaultLanguage] {
+			if messages[key] == "" {
+				t.Errorf("%s: %s missing", language, key)
+			}
+		}
+		for key, limit := range limits {
+			text := messages[key]
+			if len(text) > limit {
+				t.Errorf("%s: %s %q is longer than %d characters", language, key, text, limit)
+			}
+			for _, r := range text {
+				if r > 0x7E {
+					t.Errorf("%s: %s %q is not plain ASCII", language, key, text)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestCatalog_Text(t *testing.T) {
+	catalog, _ := New("es")
+	if got := catalog.Text(WiresXDescription); got != "Descripcion" {
+		t.Errorf("Text(WiresXDescription) = %q", got)
+	}
+	if got := catalog.Format(CallSummary, "YSF->DMR", "EA1ABC", "TG 214", "4.5s"); got != "Llamada YSF->DMR de EA1ABC a TG 214 finalizada tras 4.5s" {
+		t.Errorf("Format(CallSummary) = %q", got)
+	}
+
+	// Missing messages fall back to English
+	catalog = &Catalog{language: "xx", messages: map[Key]string{}}
+	if got := catalog.Text(WiresXUnlink); got != "UNLINK" {
+		t.Errorf("Text(WiresXUnlink) = %q, want the English fallback", got)
+	}
+}
+
+func TestCatalog_Prompt(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "timeout.ambe")
+	translated := filepath.Join(dir, "timeout.de.ambe")
+	if err := os.WriteFile(translated, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	german, _ := New("de")
+	if got := german.Prompt(path); got != translated {
+		t.Errorf("Prompt() = %q, want %q", got, translated)
+	}
+	french, _ := New("fr")
+	if got := french.Prompt(path); got != path {
+		t.Errorf("Prompt() = %q, want the untranslated %q", got, path)
+	}
+	if got := french.Prompt(""); got != "" {
+		t.Errorf("Prompt(\"\") = %q", got)
+	}
+}
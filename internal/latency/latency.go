@@ -0,0 +1,151 @@
+// Package latency measures how long frames take to pass through the gateway
+package latency
+
+import (
+	"sync"
+	"time"
+)
+
+// Bucket upper bounds; slower frames fall in a final overflow bucket
+var bounds = [...]time.Duration{
+	1 * time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	40 * time.Millisecond,
+	60 * time.Millisecond,
+	80 * time.Millisecond,
+	100 * time.Millisecond,
+	150 * time.Millisecond,
+	200 * time.Millisecond,
+	300 * time.Millisecond,
+	500 * time.Millisecond,
+	1000 * time.Millisecond,
+}
+
+// Inputs waiting for output beyond this are dropped, oldest first
+const maxPending = 64
+
+// Histogram counts latencies in fixed buckets
+// It is safe for concurrent use.
+type Histogram struct {
+	mu     sync.Mutex
+	counts [len(bounds) + 1]uint64 // the last bucket is the overflow
+	count  uint64
+	sum    time.Duration
+	max    time.Duration
+}
+
+// Bucket is the number of latencies up to an upper bound
+// The overflow bucket has a zero bound.
+type Bucket struct {
+	LE    time.Duration
+	Count uint64
+}
+
+// Snapshot is a copy of a histogram's counters
+type Snapshot struct {
+	Buckets []Bucket
+	Count   uint64
+	Sum     time.Duration
+	Max     time.Duration
+}
+
+// Observe records one latency
+func (h *Histogram) Observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := 0
+	for i < len(bounds) && d > bounds[i] {
+		i++
+	}
+	h.counts[i]++
+	h.count++
+	h.sum += d
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// Snapshot returns a copy of the counters
+func (h *Histogram) Snapshot() Snapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := Snapshot{Count: h.count, Sum: h.sum, Max: h.max}
+	for i, count := range h.counts {
+		b := Bucket{Count: count}
+		if i < len(bounds) {
+			b.LE = bounds[i]
+		}
+		s.Buckets = append(s.Buckets, b)
+	}
+	return s
+}
+
+// Quantile returns the upper bound of the bucket holding quantile q (0-1),
+// or the largest latency seen when that is in the overflow bucket
+// It returns 0 when nothing has been observed.
+func (s Snapshot) Quantile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	rank := uint64(q*float64(s.Count) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	var seen uint64
+	for _, b := range s.Buckets {
+		seen += b.Count
+		if seen >= rank {
+			if b.LE == 0 || b.LE > s.Max {
+				return s.Max
+			}
+			return b.LE
+		}
+	}
+	return s.Max
+}
+
+// Tracker measures the latency of one direction through the gateway
+// Each input frame is timestamped as it is received. The converters batch
+// several input frames into one set of output frames, so when output is sent
+// every input still pending is observed.
+type Tracker struct {
+	pending   []time.Time
+	histogram Histogram
+}
+
+// NewTracker creates a latency tracker
+func NewTracker() *Tracker {
+	return &Tracker{pending: make([]time.Time, 0, maxPending)}
+}
+
+// Received timestamps an input frame
+func (t *Tracker) Received(at time.Time) {
+	if len(t.pending) == maxPending {
+		t.pending = append(t.pending[:0], t.pending[1:]...)
+	}
+	t.pending = append(t.pending, at)
+}
+
+// Sent observes the latency of every pending input frame
+func (t *Tracker) Sent(at time.Time) {
+	for _, received := range t.pending {
+		t.histogram.Observe(at.Sub(received))
+	}
+	t.pending = t.pending[:0]
+}
+
+// Reset forgets input frames that produced no output, e.g. at the end of a call
+func (t *Tracker) Reset() {
+	t.pending = t.pending[:0]
+}
+
+// Snapshot returns the latencies observed so far
+func (t *Tracker) Snapshot() Snapshot {
+	return t.histogram.Snapshot()
+}
@@ -0,0 +1,103 @@
+package latency
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	var h Histogram
+	h.Observe(500 * time.Microsecond)
+	h.Observe(1 * time.Millisecond) // bounds are inclusive
+	h.Observe(45 * time.Millisecond)
+	h.Observe(3 * time.Second)
+
+	s := h.Snapshot()
+	if s.Count != 4 || s.Max != 3*time.Second {
+		t.Fatalf("Count = %d, Max = %v", s.Count, s.Max)
+	}
+	if len(s.Buckets) != len(bounds)+1 {
+		t.Fatalf("%d buckets, want %d", len(s.Buckets), len(bounds)+1)
+	}
+	if s.Buckets[0].LE != time.Millisecond || s.Buckets[0].Count != 2 {
+		t.Errorf("first bucket = %+v, want 2 up to 1ms", s.Buckets[0])
+	}
+	if b := s.Buckets[len(s.Buckets)-1]; b.LE != 0 || b.Count != 1 {
+		t.Errorf("overflow bucket = %+v, want 1", b)
+	}
+}
+
+func TestSnapshotQuantile(t *testing.T) {
+	var h Histogram
+	if q := h.Snapshot().Quantile(0.5); q != 0 {
+		t.Errorf("Quantile() of an empty histogram = %v, want 0", q)
+	}
+
+	for i := 0; i < 90; i++ {
+		h.Observe(15 * time.Millisecond)
+	}
+	for i := 0; i < 9; i++ {
+		h.Observe(70 * time.Millisecond)
+	}
+	h.Observe(1500 * time.Millisecond)
+
+	s := h.Snapshot()
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0.5, 20 * time.Millisecond},
+		{0.95, 80 * time.Millisecond},
+		{0.99, 80 * time.Millisecond},
+		{1, 1500 * time.Millisecond}, // overflow reports the largest latency
+	}
+	for _, tt := range tests {
+		if got := s.Quantile(tt.q); got != tt.want {
+			t.Errorf("Quantile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tr := NewTracker()
+	start := time.Now()
+
+	// Three inputs batched into one output
+	tr.Received(start)
+	tr.Received(start.Add(100 * time.Millisecond))
+	tr.Received(start.Add(200 * time.Millisecond))
+	tr.Sent(start.Add(205 * time.Millisecond))
+
+	s := tr.Snapshot()
+	if s.Count != 3 || s.Max != 205*time.Millisecond || s.Sum != 315*time.Millisecond {
+		t.Errorf("after batch: Count = %d, Max = %v, Sum = %v", s.Count, s.Max, s.Sum)
+	}
+
+	// Output with nothing pending observes nothing
+	tr.Sent(start.Add(300 * time.Millisecond))
+	if s := tr.Snapshot(); s.Count != 3 {
+		t.Errorf("Sent() with nothing pending observed %d", s.Count-3)
+	}
+
+	// Inputs left at the end of a call are forgotten
+	tr.Received(start.Add(400 * time.Millisecond))
+	tr.Reset()
+	tr.Sent(start.Add(900 * time.Millisecond))
+	if s := tr.Snapshot(); s.Count != 3 {
+		t.Errorf("Sent() after Reset() observed %d", s.Count-3)
+	}
+}
+
+func TestTrackerPendingLimit(t *testing.T) {
+	tr := NewTracker()
+	start := time.Now()
+	for i := 0; i < maxPending+10; i++ {
+		tr.Received(start.Add(time.Duration(i) * time.Millisecond))
+	}
+	tr.Sent(start.Add(time.Duration(maxPending+10) * time.Millisecond))
+
+	// The oldest inputs were dropped
+	if s := tr.Snapshot(); s.Count != maxPending || s.Max != time.Duration(maxPending)*time.Millisecond {
+		t.Errorf("Count = %d, Max = %v", s.Count, s.Max)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dbehnke/ysf2dmr/internal/database"
@@ -25,20 +26,35 @@ type DMRDatabaseAdapter struct {
 	errorCount   uint32
 	lastAccess   time.Time
 
-	// Cache for performance (optional)
-	enableCache  bool
-	cacheSize    int
-	idCache      map[uint32]string // Recent ID->Callsign lookups
-	callsignCache map[string]uint32 // Recent Callsign->ID lookups
-	cacheExpiry  time.Duration
-	lastClearTime time.Time
+	// LRU cache for performance (optional)
+	enableCache   bool
+	cacheSize     int
+	idCache       *lruCache[uint32, string]      // Recent ID->Callsign lookups
+	callsignCache *lruCache[string, uint32]      // Recent Callsign->ID lookups
+	userCache     *lruCache[uint32, DMRUserInfo] // Recent FindUser lookups
+	cacheExpiry   time.Duration             // Per-entry time to live
+
+	// Full in-memory snapshot; the database is only queried on a miss
+	enableSnapshot  bool
+	snapshotRefresh time.Duration
+	snapshot        atomic.Pointer[dmrSnapshot]
+	snapshotStop    chan struct{}
+
+	// Optional RadioID sync status merged into GetDatabaseStatistics
+	syncStatus func() map[string]interface{}
+
+	// Optional function told about IDs missing from the database
+	onMiss func(id uint32)
 }
 
 // DMRDatabaseAdapterConfig holds configuration options for the database adapter
 type DMRDatabaseAdapterConfig struct {
 	EnableCache   bool          // Enable in-memory cache for frequently accessed lookups
-	CacheSize     int           // Maximum cache size (default: 1000)
-	CacheExpiry   time.Duration // Cache expiry time (default: 5 minutes)
+	CacheSize     int           // Maximum entries per cache direction (default: 1000)
+	CacheExpiry   time.Duration // Per-entry time to live (default: 5 minutes)
+
+	EnableSnapshot  bool          // Load the whole table into memory at Start
+	SnapshotRefresh time.Duration // Periodic snapshot reload (0: only via RefreshSnapshot)
 }
 
 // NewDMRDatabaseAdapter creates a new database-backed DMR lookup adapter
@@ -52,18 +68,27 @@ func NewDMRDatabaseAdapter(repository *database.DMRUserRepository) *DMRDatabaseA
 
 // NewDMRDatabaseAdapterWithConfig creates a new database adapter with custom configuration
 func NewDMRDatabaseAdapterWithConfig(repository *database.DMRUserRepository, config DMRDatabaseAdapterConfig) *DMRDatabaseAdapter {
+	if config.CacheSize <= 0 {
+		config.CacheSize = 1000
+	}
+	if config.CacheExpiry <= 0 {
+		config.CacheExpiry = 5 * time.Minute
+	}
+
 	adapter := &DMRDatabaseAdapter{
 		repository:    repository,
 		debugEnabled:  false,
 		enableCache:   config.EnableCache,
 		cacheSize:     config.CacheSize,
 		cacheExpiry:   config.CacheExpiry,
-		lastClearTime: time.Now(),
+		enableSnapshot:  config.EnableSnapshot,
+		snapshotRefresh: config.SnapshotRefresh,
 	}
 
 	if adapter.enableCache {
-		adapter.idCache = make(map[uint32]string)
-		adapter.callsignCache = make(map[string]uint32)
+		adapter.idCache = newLRUCache[uint32, string](adapter.cacheSize, adapter.cacheExpiry)
+		adapter.callsignCache = newLRUCache[string, uint32](adapter.cacheSize, adapter.cacheExpiry)
+		adapter.userCache = newLRUCache[uint32, DMRUserInfo](adapter.cacheSize, adapter.cacheExpiry)
 	}
 
 	return adapter
@@ -85,6 +110,12 @@ func (d *DMRDatabaseAdapter) FindCS(id uint32) string {
 		return "ALL"
 	}
 
+	// The snapshot answers almost every lookup without touching the database
+	if callsign, found := d.snapshotCallsign(id); found {
+		d.recordHit()
+		return callsign
+	}
+
 	// Check cache first if enabled
 	if d.enableCache {
 		if callsign, found := d.getCachedCallsign(id); found {
@@ -101,6 +132,7 @@ func (d *DMRDatabaseAdapter) FindCS(id uint32) string {
 			d.logDebug("Database error looking up ID %d: %v", id, err)
 		} else {
 			d.recordMiss()
+			d.reportMiss(id)
 		}
 		// If not found, return the ID as a string (matching original behavior)
 		return fmt.Sprintf("%d", id)
@@ -127,6 +159,11 @@ func (d *DMRDatabaseAdapter) FindID(callsign string) uint32 {
 		return DMR_ID_UNKNOWN
 	}
 
+	if id, found := d.snapshotID(upperCallsign); found {
+		d.recordHit()
+		return id
+	}
+
 	// Check cache first if enabled
 	if d.enableCache {
 		if id, found := d.getCachedID(upperCallsign); found {
@@ -169,6 +206,70 @@ func (d *DMRDatabaseAdapter) Exists(id uint32) bool {
 	return callsign != idAsString
 }
 
+// FindUser returns the full user details for a DMR ID from the database
+func (d *DMRDatabaseAdapter) FindUser(id uint32) (DMRUserInfo, bool) {
+	d.updateAccessStats()
+
+	if d.enableCache {
+		if info, found := d.userCache.Get(id); found {
+			d.recordHit()
+			return info, true
+		}
+	}
+
+	user, err := d.repository.GetByRadioID(id)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			d.recordError()
+			d.logDebug("Database error looking up user %d: %v", id, err)
+		} else {
+			d.recordMiss()
+			d.reportMiss(id)
+		}
+		return DMRUserInfo{}, false
+	}
+
+	info := userInfo(user)
+	if d.enableCache {
+		d.userCache.Put(id, info)
+	}
+
+	d.recordHit()
+	return info, true
+}
+
+// SearchCallsign returns up to limit users whose callsign starts with prefix
+func (d *DMRDatabaseAdapter) SearchCallsign(prefix string, limit int) []DMRUserInfo {
+	if limit <= 0 {
+		return nil
+	}
+
+	users, err := d.repository.FindByCallsignPattern(strings.ToUpper(strings.TrimSpace(prefix)), limit)
+	if err != nil {
+		d.recordError()
+		d.logDebug("Database error searching callsign %q: %v", prefix, err)
+		return nil
+	}
+
+	result := make([]DMRUserInfo, len(users))
+	for i := range users {
+		result[i] = userInfo(&users[i])
+	}
+	return result
+}
+
+// userInfo converts a database user to the lookup representation
+func userInfo(user *database.DMRUser) DMRUserInfo {
+	return DMRUserInfo{
+		ID:       user.RadioID,
+		Callsign: user.Callsign,
+		Name:     strings.TrimSpace(user.FirstName + " " + user.LastName),
+		City:     user.City,
+		State:    user.State,
+		Country:  user.Country,
+	}
+}
+
 // GetStats returns statistics about the DMR lookup (compatible with original interface)
 // Note: reloadCount and lastReload are not applicable for database adapter
 func (d *DMRDatabaseAdapter) GetStats() (totalEntries, reloadCount, errorCount uint32, lastReload time.Time) {
@@ -197,16 +298,19 @@ func (d *DMRDatabaseAdapter) GetEntryCount() uint32 {
 	return uint32(count)
 }
 
-// ForceReload is a no-op for database adapter (database syncs automatically)
-// Kept for interface compatibility
+// ForceReload reloads the snapshot (if enabled) and clears the cache
+// The database itself syncs automatically.
 func (d *DMRDatabaseAdapter) ForceReload() error {
-	d.logDebug("ForceReload called on database adapter (no-op - database syncs automatically)")
+	d.logDebug("ForceReload called on database adapter")
 
 	// Clear cache if enabled to force fresh lookups
 	if d.enableCache {
 		d.clearCache()
 	}
 
+	if d.enableSnapshot {
+		return d.LoadSnapshot()
+	}
 	return nil
 }
 
@@ -225,13 +329,26 @@ func (d *DMRDatabaseAdapter) Start() error {
 	}
 
 	d.logDebug("Database adapter started with %d entries", count)
+
+	if d.enableSnapshot {
+		if err := d.LoadSnapshot(); err != nil {
+			// Lookups still work directly against the database
+			log.Printf("DMRDatabaseAdapter: snapshot load failed: %v", err)
+		}
+		if d.snapshotRefresh > 0 && d.snapshotStop == nil {
+			d.startSnapshotRefresh(d.snapshotRefresh)
+		}
+	}
 	return nil
 }
 
-// Stop is a no-op for database adapter (no background processes to stop)
-// Kept for interface compatibility
+// Stop stops the snapshot refresh and clears the cache
 func (d *DMRDatabaseAdapter) Stop() {
-	d.logDebug("Stop called on database adapter (no-op)")
+	d.logDebug("Stop called on database adapter")
+	if d.snapshotStop != nil {
+		close(d.snapshotStop)
+		d.snapshotStop = nil
+	}
 	if d.enableCache {
 		d.clearCache()
 	}
@@ -298,6 +415,24 @@ func (d *DMRDatabaseAdapter) GetUserInfoByCallsign(callsign string) (*database.D
 	return d.repository.GetByCallsign(upperCallsign)
 }
 
+// SetSyncStatus sets the source of RadioID sync status reported in GetDatabaseStatistics
+func (d *DMRDatabaseAdapter) SetSyncStatus(fn func() map[string]interface{}) {
+	d.syncStatus = fn
+}
+
+// SetMissHandler sets a function called with each ID not found in the
+// database, such as a request for an on-demand RadioID lookup
+// It is called on the looking-up goroutine and must not block.
+func (d *DMRDatabaseAdapter) SetMissHandler(fn func(id uint32)) {
+	d.onMiss = fn
+}
+
+func (d *DMRDatabaseAdapter) reportMiss(id uint32) {
+	if d.onMiss != nil {
+		d.onMiss(id)
+	}
+}
+
 // GetDatabaseStatistics returns detailed database statistics
 func (d *DMRDatabaseAdapter) GetDatabaseStatistics() (map[string]interface{}, error) {
 	dbStats, err := d.repository.GetStatistics()
@@ -316,9 +451,22 @@ func (d *DMRDatabaseAdapter) GetDatabaseStatistics() (map[string]interface{}, er
 	}
 	d.mutex.RUnlock()
 
+	if d.enableSnapshot {
+		entries, loadedAt := d.SnapshotInfo()
+		adapterStats["snapshot_entries"] = entries
+		adapterStats["snapshot_loaded_at"] = loadedAt
+	}
+
 	if d.enableCache {
+		stats := d.CacheStats()
 		adapterStats["cache_enabled"] = true
-		adapterStats["cache_size"] = len(d.idCache) + len(d.callsignCache)
+		adapterStats["cache_size"] = stats.Size
+		adapterStats["cache_capacity"] = stats.Capacity
+		adapterStats["cache_hits"] = stats.Hits
+		adapterStats["cache_misses"] = stats.Misses
+		adapterStats["cache_hit_rate"] = stats.HitRate()
+		adapterStats["cache_evictions"] = stats.Evictions
+		adapterStats["cache_expirations"] = stats.Expirations
 		adapterStats["cache_expiry"] = d.cacheExpiry.String()
 	} else {
 		adapterStats["cache_enabled"] = false
@@ -332,114 +480,69 @@ func (d *DMRDatabaseAdapter) GetDatabaseStatistics() (map[string]interface{}, er
 	for k, v := range adapterStats {
 		result[k] = v
 	}
+	if d.syncStatus != nil {
+		for k, v := range d.syncStatus() {
+			result[k] = v
+		}
+	}
 
 	return result, nil
 }
 
+// CacheStats returns the combined counters of both cache directions
+func (d *DMRDatabaseAdapter) CacheStats() CacheStats {
+	if !d.enableCache {
+		return CacheStats{}
+	}
+
+	ids, callsigns := d.idCache.Stats(), d.callsignCache.Stats()
+	return CacheStats{
+		Size:        ids.Size + callsigns.Size,
+		Capacity:    ids.Capacity + callsigns.Capacity,
+		Hits:        ids.Hits + callsigns.Hits,
+		Misses:      ids.Misses + callsigns.Misses,
+		Evictions:   ids.Evictions + callsigns.Evictions,
+		Expirations: ids.Expirations + callsigns.Expirations,
+	}
+}
+
 // Cache management methods (private)
 
 func (d *DMRDatabaseAdapter) getCachedCallsign(id uint32) (string, bool) {
 	if !d.enableCache {
 		return "", false
 	}
-
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
-
-	d.clearExpiredCache()
-
-	callsign, exists := d.idCache[id]
-	return callsign, exists
+	return d.idCache.Get(id)
 }
 
 func (d *DMRDatabaseAdapter) getCachedID(callsign string) (uint32, bool) {
 	if !d.enableCache {
 		return 0, false
 	}
-
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
-
-	d.clearExpiredCache()
-
-	id, exists := d.callsignCache[callsign]
-	return id, exists
+	return d.callsignCache.Get(callsign)
 }
 
 func (d *DMRDatabaseAdapter) cacheCallsign(id uint32, callsign string) {
 	if !d.enableCache {
 		return
 	}
-
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-
-	d.clearExpiredCache()
-
-	// Limit cache size
-	if len(d.idCache) >= d.cacheSize {
-		d.clearOldestEntries()
-	}
-
-	d.idCache[id] = callsign
+	d.idCache.Put(id, callsign)
 }
 
 func (d *DMRDatabaseAdapter) cacheID(callsign string, id uint32) {
 	if !d.enableCache {
 		return
 	}
-
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-
-	d.clearExpiredCache()
-
-	// Limit cache size
-	if len(d.callsignCache) >= d.cacheSize {
-		d.clearOldestEntries()
-	}
-
-	d.callsignCache[callsign] = id
+	d.callsignCache.Put(callsign, id)
 }
 
 func (d *DMRDatabaseAdapter) clearCache() {
 	if !d.enableCache {
 		return
 	}
-
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-
-	d.idCache = make(map[uint32]string)
-	d.callsignCache = make(map[string]uint32)
-	d.lastClearTime = time.Now()
-}
-
-func (d *DMRDatabaseAdapter) clearExpiredCache() {
-	// Check if cache should be cleared due to expiry
-	if time.Since(d.lastClearTime) > d.cacheExpiry {
-		d.idCache = make(map[uint32]string)
-		d.callsignCache = make(map[string]uint32)
-		d.lastClearTime = time.Now()
-	}
-}
-
-func (d *DMRDatabaseAdapter) clearOldestEntries() {
-	// Simple cache eviction: clear half the cache
-	// More sophisticated LRU could be implemented if needed
-	for id := range d.idCache {
-		delete(d.idCache, id)
-		if len(d.idCache) <= d.cacheSize/2 {
-			break
-		}
-	}
-
-	for callsign := range d.callsignCache {
-		delete(d.callsignCache, callsign)
-		if len(d.callsignCache) <= d.cacheSize/2 {
-			break
-		}
-	}
+	d.idCache.Clear()
+	d.callsignCache.Clear()
+	d.userCache.Clear()
 }
 
 // Statistics tracking methods (private)
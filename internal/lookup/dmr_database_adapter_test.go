@@ -0,0 +1,83 @@
+package lookup
+
+import (
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/database"
+)
+
+func TestDMRDatabaseAdapter_FindUser(t *testing.T) {
+	adapter, repo := newTestAdapter(t, DMRDatabaseAdapterConfig{EnableCache: true})
+	err := repo.Upsert(&database.DMRUser{
+		RadioID:   3120001,
+		Callsign:  "W1AW",
+		FirstName: "Hiram",
+		LastName:  "Maxim",
+		City:      "Newington",
+		State:     "Connecticut",
+		Country:   "United States",
+	})
+	if err != nil {
+		t.Fatalf("Upsert error: %v", err)
+	}
+
+	want := DMRUserInfo{
+		ID:       3120001,
+		Callsign: "W1AW",
+		Name:     "Hiram Maxim",
+		City:     "Newington",
+		State:    "Connecticut",
+		Country:  "United States",
+	}
+	for i := 0; i < 2; i++ { // Second lookup is served from the cache
+		if user, found := adapter.FindUser(3120001); !found || user != want {
+			t.Errorf("FindUser() = %+v, %v; want %+v", user, found, want)
+		}
+	}
+
+	if _, found := adapter.FindUser(1); found {
+		t.Error("FindUser(1) found a user")
+	}
+}
+
+func TestDMRDatabaseAdapter_SearchCallsign(t *testing.T) {
+	adapter, repo := newTestAdapter(t, DMRDatabaseAdapterConfig{})
+	if err := repo.Upsert(&database.DMRUser{RadioID: 2345679, Callsign: "G4ABC"}); err != nil {
+		t.Fatalf("Upsert error: %v", err)
+	}
+
+	users := adapter.SearchCallsign("g4", 10)
+	if len(users) != 2 || users[0].Callsign != "G4ABC" || users[1].Callsign != "G4KLX" {
+		t.Errorf("SearchCallsign(g4) = %+v", users)
+	}
+	if users := adapter.SearchCallsign("W", 0); users != nil {
+		t.Errorf("SearchCallsign with limit 0 = %+v, want nil", users)
+	}
+}
+
+func TestDMRDatabaseAdapter_MissHandler(t *testing.T) {
+	adapter, repo := newTestAdapter(t, DMRDatabaseAdapterConfig{EnableCache: true})
+	if err := repo.Upsert(&database.DMRUser{RadioID: 3120001, Callsign: "W1AW"}); err != nil {
+		t.Fatalf("Upsert error: %v", err)
+	}
+
+	var missed []uint32
+	adapter.SetMissHandler(func(id uint32) { missed = append(missed, id) })
+
+	adapter.FindCS(3120001)
+	adapter.FindCS(3129999)
+	adapter.FindUser(3129998)
+	adapter.FindCS(DMR_ID_ALL)
+
+	if len(missed) != 2 || missed[0] != 3129999 || missed[1] != 3129998 {
+		t.Errorf("missed IDs = %v, want [3129999 3129998]", missed)
+	}
+
+	// An ID stored after a miss is found on the next lookup
+	if err := repo.Upsert(&database.DMRUser{RadioID: 3129999, Callsign: "N0NEW"}); err != nil {
+		t.Fatalf("Upsert error: %v", err)
+	}
+	if cs := adapter.FindCS(3129999); cs != "N0NEW" {
+		t.Errorf("FindCS() after the lookup = %q, want N0NEW", cs)
+	}
+}
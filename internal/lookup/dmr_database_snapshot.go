@@ -0,0 +1,102 @@
+package lookup
+
+import (
+	"strings"
+	"time"
+)
+
+// Rows read per query while loading a snapshot
+const snapshotBatchSize = 5000
+
+// dmrSnapshot is an immutable in-memory copy of the user table
+// Lookups read the current snapshot without locking; a refresh builds a new
+// snapshot and swaps it in atomically.
+type dmrSnapshot struct {
+	idToCallsign map[uint32]string
+	callsignToID map[string]uint32
+	loadedAt     time.Time
+}
+
+// LoadSnapshot reads all users from the database into a new in-memory snapshot
+func (d *DMRDatabaseAdapter) LoadSnapshot() error {
+	start := time.Now()
+
+	snap := &dmrSnapshot{
+		idToCallsign: make(map[uint32]string),
+		callsignToID: make(map[string]uint32),
+	}
+	err := d.repository.ForEachIDCallsign(snapshotBatchSize, func(id uint32, callsign string) {
+		callsign = strings.ToUpper(strings.TrimSpace(callsign))
+		snap.idToCallsign[id] = callsign
+		if _, exists := snap.callsignToID[callsign]; !exists {
+			snap.callsignToID[callsign] = id // Keep the first ID for callsigns with several
+		}
+	})
+	if err != nil {
+		d.recordError()
+		return err
+	}
+	snap.loadedAt = time.Now()
+
+	d.snapshot.Store(snap)
+	d.logDebug("Snapshot loaded: %d entries in %v", len(snap.idToCallsign), time.Since(start))
+	return nil
+}
+
+// RefreshSnapshot reloads the snapshot, keeping the previous one on failure
+// Intended to be called after each RadioID sync.
+func (d *DMRDatabaseAdapter) RefreshSnapshot() {
+	if !d.enableSnapshot {
+		return
+	}
+	if err := d.LoadSnapshot(); err != nil {
+		d.logDebug("Snapshot refresh failed, keeping previous snapshot: %v", err)
+	}
+}
+
+// SnapshotInfo returns the number of entries in the snapshot and when it was loaded
+func (d *DMRDatabaseAdapter) SnapshotInfo() (entries int, loadedAt time.Time) {
+	snap := d.snapshot.Load()
+	if snap == nil {
+		return 0, time.Time{}
+	}
+	return len(snap.idToCallsign), snap.loadedAt
+}
+
+func (d *DMRDatabaseAdapter) snapshotCallsign(id uint32) (string, bool) {
+	snap := d.snapshot.Load()
+	if snap == nil {
+		return "", false
+	}
+	callsign, ok := snap.idToCallsign[id]
+	return callsign, ok
+}
+
+func (d *DMRDatabaseAdapter) snapshotID(callsign string) (uint32, bool) {
+	snap := d.snapshot.Load()
+	if snap == nil {
+		return 0, false
+	}
+	id, ok := snap.callsignToID[callsign]
+	return id, ok
+}
+
+// startSnapshotRefresh reloads the snapshot periodically until Stop
+func (d *DMRDatabaseAdapter) startSnapshotRefresh(interval time.Duration) {
+	stop := make(chan struct{})
+	d.snapshotStop = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				d.RefreshSnapshot()
+			}
+		}
+	}()
+}
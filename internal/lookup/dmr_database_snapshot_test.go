@@ -0,0 +1,88 @@
+package lookup
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/database"
+)
+
+func newTestAdapter(t *testing.T, config DMRDatabaseAdapterConfig) (*DMRDatabaseAdapter, *database.DMRUserRepository) {
+	t.Helper()
+
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "users.db")}, nil)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo := database.NewDMRUserRepository(db.GetDB())
+	err = repo.UpsertBatch([]database.DMRUser{
+		{RadioID: 3120001, Callsign: "W1AW"},
+		{RadioID: 2345678, Callsign: "G4KLX"},
+	})
+	if err != nil {
+		t.Fatalf("UpsertBatch error: %v", err)
+	}
+
+	return NewDMRDatabaseAdapterWithConfig(repo, config), repo
+}
+
+func TestDMRDatabaseAdapter_Snapshot(t *testing.T) {
+	adapter, repo := newTestAdapter(t, DMRDatabaseAdapterConfig{EnableSnapshot: true})
+	if err := adapter.Start(); err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	defer adapter.Stop()
+
+	if entries, loadedAt := adapter.SnapshotInfo(); entries != 2 || loadedAt.IsZero() {
+		t.Fatalf("SnapshotInfo() = %d, %v; want 2 entries", entries, loadedAt)
+	}
+
+	// Changes in the database are not seen until the snapshot is refreshed
+	if err := repo.Upsert(&database.DMRUser{RadioID: 3120001, Callsign: "K1ABC"}); err != nil {
+		t.Fatalf("Upsert error: %v", err)
+	}
+	if got := adapter.FindCS(3120001); got != "W1AW" {
+		t.Errorf("FindCS() before refresh = %q, want W1AW", got)
+	}
+	if got := adapter.FindID("g4klx"); got != 2345678 {
+		t.Errorf("FindID(g4klx) = %d, want 2345678", got)
+	}
+
+	adapter.RefreshSnapshot()
+	if got := adapter.FindCS(3120001); got != "K1ABC" {
+		t.Errorf("FindCS() after refresh = %q, want K1ABC", got)
+	}
+}
+
+func TestDMRDatabaseAdapter_SnapshotMissFallsBackToDatabase(t *testing.T) {
+	adapter, repo := newTestAdapter(t, DMRDatabaseAdapterConfig{EnableSnapshot: true})
+	if err := adapter.Start(); err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	defer adapter.Stop()
+
+	if err := repo.Upsert(&database.DMRUser{RadioID: 9990001, Callsign: "N0NEW"}); err != nil {
+		t.Fatalf("Upsert error: %v", err)
+	}
+	if got := adapter.FindCS(9990001); got != "N0NEW" {
+		t.Errorf("FindCS() for user added after snapshot = %q, want N0NEW", got)
+	}
+}
+
+func TestDMRDatabaseAdapter_CacheStats(t *testing.T) {
+	adapter, _ := newTestAdapter(t, DMRDatabaseAdapterConfig{EnableCache: true, CacheSize: 1})
+	if err := adapter.Start(); err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+
+	adapter.FindCS(3120001) // Miss, cached
+	adapter.FindCS(3120001) // Hit
+	adapter.FindCS(2345678) // Miss, evicts 3120001
+
+	stats := adapter.CacheStats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Evictions != 1 {
+		t.Errorf("CacheStats() = %+v, want 1 hit, 2 misses, 1 eviction", stats)
+	}
+}
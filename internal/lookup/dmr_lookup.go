@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,6 +21,7 @@ type DMRLookup struct {
 	// Bidirectional lookup maps (protected by mutex)
 	idToCallsign map[uint32]string // ID -> Callsign
 	callsignToID map[string]uint32 // Callsign -> ID (uppercase)
+	idToName     map[uint32]string // ID -> Name (optional third column onwards)
 
 	// Thread safety
 	mutex sync.RWMutex
@@ -53,6 +55,7 @@ func NewDMRLookup(filename string, reloadTime uint32) *DMRLookup {
 		reloadTime:     reloadTime,
 		idToCallsign:   make(map[uint32]string),
 		callsignToID:   make(map[string]uint32),
+		idToName:       make(map[uint32]string),
 		stopChan:       make(chan bool, 1),
 		running:        false,
 		stopped:        false,
@@ -81,6 +84,7 @@ func (d *DMRLookup) Read() error {
 	// Create new maps for atomic replacement
 	newIdToCallsign := make(map[uint32]string)
 	newCallsignToID := make(map[string]uint32)
+	newIdToName := make(map[uint32]string)
 
 	scanner := bufio.NewScanner(file)
 	lineNumber := 0
@@ -95,7 +99,7 @@ func (d *DMRLookup) Read() error {
 			continue
 		}
 
-		// Parse line: ID CALLSIGN
+		// Parse line: ID CALLSIGN [NAME...]
 		fields := strings.Fields(line)
 		if len(fields) < 2 {
 			d.logDebug("Skipping invalid line %d: %s", lineNumber, line)
@@ -122,6 +126,9 @@ func (d *DMRLookup) Read() error {
 		dmrID := uint32(id)
 		newIdToCallsign[dmrID] = callsign
 		newCallsignToID[callsign] = dmrID
+		if len(fields) > 2 {
+			newIdToName[dmrID] = strings.Join(fields[2:], " ")
+		}
 
 		entriesLoaded++
 	}
@@ -135,6 +142,7 @@ func (d *DMRLookup) Read() error {
 	d.mutex.Lock()
 	d.idToCallsign = newIdToCallsign
 	d.callsignToID = newCallsignToID
+	d.idToName = newIdToName
 	d.totalEntries = uint32(entriesLoaded)
 	d.lastReloadTime = time.Now()
 	d.mutex.Unlock()
@@ -200,6 +208,47 @@ func (d *DMRLookup) Exists(id uint32) bool {
 	return exists
 }
 
+// FindUser returns the callsign and name for a DMR ID
+func (d *DMRLookup) FindUser(id uint32) (DMRUserInfo, bool) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	callsign, exists := d.idToCallsign[id]
+	if !exists {
+		return DMRUserInfo{}, false
+	}
+	return DMRUserInfo{ID: id, Callsign: callsign, Name: d.idToName[id]}, true
+}
+
+// SearchCallsign returns up to limit users whose callsign starts with prefix
+func (d *DMRLookup) SearchCallsign(prefix string, limit int) []DMRUserInfo {
+	prefix = strings.ToUpper(strings.TrimSpace(prefix))
+	if limit <= 0 {
+		return nil
+	}
+
+	d.mutex.RLock()
+	callsigns := make([]string, 0)
+	for callsign := range d.callsignToID {
+		if strings.HasPrefix(callsign, prefix) {
+			callsigns = append(callsigns, callsign)
+		}
+	}
+	sort.Strings(callsigns)
+	if len(callsigns) > limit {
+		callsigns = callsigns[:limit]
+	}
+
+	users := make([]DMRUserInfo, len(callsigns))
+	for i, callsign := range callsigns {
+		id := d.callsignToID[callsign]
+		users[i] = DMRUserInfo{ID: id, Callsign: callsign, Name: d.idToName[id]}
+	}
+	d.mutex.RUnlock()
+
+	return users
+}
+
 // Start begins the background reload process if reloadTime > 0
 // This method starts a goroutine that reloads the database periodically
 func (d *DMRLookup) Start() error {
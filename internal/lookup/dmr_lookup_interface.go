@@ -10,6 +10,10 @@ type DMRLookupInterface interface {
 	FindID(callsign string) uint32        // Find DMR ID by callsign
 	Exists(id uint32) bool                // Check if DMR ID exists
 
+	// User details
+	FindUser(id uint32) (DMRUserInfo, bool)                // Find name and location by DMR ID
+	SearchCallsign(prefix string, limit int) []DMRUserInfo // Find users by callsign prefix (sorted)
+
 	// Lifecycle management
 	Start() error                         // Initialize the lookup service
 	Stop()                               // Stop the lookup service
@@ -26,4 +30,15 @@ type DMRLookupInterface interface {
 	// Data access (for testing and debugging)
 	GetAllCallsigns() []string           // Get all callsigns (may be expensive)
 	GetAllIDs() []uint32                 // Get all IDs (may be expensive)
-}
\ No newline at end of file
+}
+
+// DMRUserInfo holds the details known about a DMR user
+// The file-based lookup only knows the name; the database also has the location.
+type DMRUserInfo struct {
+	ID       uint32 `json:"id"`
+	Callsign string `json:"callsign"`
+	Name     string `json:"name,omitempty"`
+	City     string `json:"city,omitempty"`
+	State    string `json:"state,omitempty"`
+	Country  string `json:"country,omitempty"`
+}
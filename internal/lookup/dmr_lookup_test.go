@@ -463,6 +463,34 @@ func TestDMRLookupSpecialCases(t *testing.T) {
 	}
 }
 
+// TestDMRLookupUserDetails tests FindUser and SearchCallsign
+func TestDMRLookupUserDetails(t *testing.T) {
+	data := `3113 G4KLX Jonathan Naylor
+3114 G4ABC
+3120001 W1AW Hiram`
+
+	lookup := NewDMRLookup(createTestDMRFile(t, t.TempDir(), data), 0)
+	if err := lookup.Read(); err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	user, found := lookup.FindUser(3113)
+	if !found || user.Callsign != "G4KLX" || user.Name != "Jonathan Naylor" {
+		t.Errorf("FindUser(3113) = %+v, %v", user, found)
+	}
+	if _, found := lookup.FindUser(999); found {
+		t.Error("FindUser(999) found a user")
+	}
+
+	users := lookup.SearchCallsign("g4", 10)
+	if len(users) != 2 || users[0].Callsign != "G4ABC" || users[1].Callsign != "G4KLX" {
+		t.Errorf("SearchCallsign(g4) = %+v", users)
+	}
+	if users := lookup.SearchCallsign("", 1); len(users) != 1 {
+		t.Errorf("SearchCallsign with limit 1 returned %d users", len(users))
+	}
+}
+
 // Helper functions
 
 // getTestDMRData returns test DMR ID data
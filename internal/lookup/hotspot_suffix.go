@@ -0,0 +1,92 @@
+package lookup
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Hotspot IDs are a user's 7-digit DMR ID followed by a 2-digit suffix
+// (312345601 for hotspot 01 of 3123456)
+const (
+	minUserID    = 1000000
+	maxUserID    = 9999999
+	minHotspotID = minUserID * 100
+	maxHotspotID = maxUserID*100 + 99
+)
+
+// HotspotOwner returns the 7-digit user ID of a 9-digit hotspot ID
+func HotspotOwner(id uint32) (uint32, bool) {
+	if id < minHotspotID || id > maxHotspotID {
+		return 0, false
+	}
+	return id / 100, true
+}
+
+// HotspotLookup resolves hotspot IDs missing from a lookup to the user who
+// owns them, and callsigns written CALL-NN to hotspot NN of the user's ID
+// IDs present in the lookup are always answered by it.
+type HotspotLookup struct {
+	DMRLookupInterface
+}
+
+// NewHotspotLookup wraps a lookup with hotspot ID support
+func NewHotspotLookup(inner DMRLookupInterface) *HotspotLookup {
+	return &HotspotLookup{DMRLookupInterface: inner}
+}
+
+// FindCS finds the callsign of an ID, or of its owner for a hotspot ID
+func (h *HotspotLookup) FindCS(id uint32) string {
+	if owner, ok := h.owner(id); ok {
+		return h.DMRLookupInterface.FindCS(owner)
+	}
+	return h.DMRLookupInterface.FindCS(id)
+}
+
+// Exists reports whether an ID, or the owner of a hotspot ID, is known
+func (h *HotspotLookup) Exists(id uint32) bool {
+	if _, ok := h.owner(id); ok {
+		return true
+	}
+	return h.DMRLookupInterface.Exists(id)
+}
+
+// FindUser returns the user of an ID or, for a hotspot ID, its owner with
+// the hotspot ID
+func (h *HotspotLookup) FindUser(id uint32) (DMRUserInfo, bool) {
+	if owner, ok := h.owner(id); ok {
+		user, found := h.DMRLookupInterface.FindUser(owner)
+		user.ID = id
+		return user, found
+	}
+	return h.DMRLookupInterface.FindUser(id)
+}
+
+// FindID finds the ID of a callsign; CALL-NN gives hotspot NN of CALL's ID
+func (h *HotspotLookup) FindID(callsign string) uint32 {
+	if id := h.DMRLookupInterface.FindID(callsign); id != DMR_ID_UNKNOWN {
+		return id
+	}
+
+	base, suffix, ok := strings.Cut(strings.TrimSpace(callsign), "-")
+	if !ok || len(suffix) != 2 {
+		return DMR_ID_UNKNOWN
+	}
+	n, err := strconv.ParseUint(suffix, 10, 32)
+	if err != nil {
+		return DMR_ID_UNKNOWN
+	}
+	id := h.DMRLookupInterface.FindID(base)
+	if id < minUserID || id > maxUserID {
+		return DMR_ID_UNKNOWN
+	}
+	return id*100 + uint32(n)
+}
+
+// owner returns the owner of a hotspot ID that is not itself in the lookup
+func (h *HotspotLookup) owner(id uint32) (uint32, bool) {
+	owner, ok := HotspotOwner(id)
+	if !ok || h.DMRLookupInterface.Exists(id) || !h.DMRLookupInterface.Exists(owner) {
+		return 0, false
+	}
+	return owner, true
+}
@@ -0,0 +1,58 @@
+package lookup
+
+import "testing"
+
+func newHotspotTestLookup(t *testing.T) *HotspotLookup {
+	t.Helper()
+	file := createTestDMRFile(t, t.TempDir(), "3123456 N0CALL John\n312345699 N0CALL-HS\n1234567 W1AW")
+	inner := NewDMRLookup(file, 0)
+	if err := inner.Read(); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	return NewHotspotLookup(inner)
+}
+
+func TestHotspotLookup_FindCS(t *testing.T) {
+	h := newHotspotTestLookup(t)
+
+	for _, tt := range []struct {
+		id   uint32
+		want string
+	}{
+		{3123456, "N0CALL"},
+		{312345601, "N0CALL"},    // hotspot of a known user
+		{312345699, "N0CALL-HS"}, // registered in its own right
+		{999999901, "999999901"}, // owner unknown
+		{31234560, "31234560"},   // 8 digits is not a hotspot ID
+	} {
+		if got := h.FindCS(tt.id); got != tt.want {
+			t.Errorf("FindCS(%d) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+
+	if !h.Exists(312345601) || h.Exists(999999901) {
+		t.Error("Exists() does not follow the hotspot owner")
+	}
+	if user, found := h.FindUser(312345601); !found || user.ID != 312345601 || user.Callsign != "N0CALL" || user.Name != "John" {
+		t.Errorf("FindUser(312345601) = %+v, %v", user, found)
+	}
+}
+
+func TestHotspotLookup_FindID(t *testing.T) {
+	h := newHotspotTestLookup(t)
+
+	for _, tt := range []struct {
+		callsign string
+		want     uint32
+	}{
+		{"N0CALL", 3123456},
+		{"w1aw-07", 123456707},
+		{"N0CALL-HS", 312345699}, // registered callsign wins
+		{"W1AW-7", DMR_ID_UNKNOWN},
+		{"K0UNK-01", DMR_ID_UNKNOWN},
+	} {
+		if got := h.FindID(tt.callsign); got != tt.want {
+			t.Errorf("FindID(%q) = %d, want %d", tt.callsign, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package lookup
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newLRUCache[uint32, string](2, 0)
+
+	cache.Put(1, "W1AW")
+	cache.Put(2, "G4KLX")
+	cache.Get(1) // 2 is now least recently used
+	cache.Put(3, "K1ABC")
+
+	if _, ok := cache.Get(2); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if v, ok := cache.Get(1); !ok || v != "W1AW" {
+		t.Errorf("Get(1) = %q, %v; want W1AW", v, ok)
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+
+	stats := cache.Stats()
+	if stats.Evictions != 1 || stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("stats = %+v, want 1 eviction, 2 hits, 1 miss", stats)
+	}
+	if rate := stats.HitRate(); rate < 0.66 || rate > 0.67 {
+		t.Errorf("HitRate() = %f, want 2/3", rate)
+	}
+}
+
+func TestLRUCache_PerEntryTTL(t *testing.T) {
+	cache := newLRUCache[string, uint32](10, 50*time.Millisecond)
+
+	cache.Put("W1AW", 1)
+	time.Sleep(30 * time.Millisecond)
+	cache.Put("G4KLX", 2)
+	time.Sleep(30 * time.Millisecond)
+
+	if _, ok := cache.Get("W1AW"); ok {
+		t.Error("expired entry returned")
+	}
+	if _, ok := cache.Get("G4KLX"); !ok {
+		t.Error("entry expired with the older one; expiry should be per entry")
+	}
+	if cache.Stats().Expirations != 1 {
+		t.Errorf("Expirations = %d, want 1", cache.Stats().Expirations)
+	}
+}
+
+func TestLRUCache_UpdateAndClear(t *testing.T) {
+	cache := newLRUCache[uint32, string](2, 0)
+
+	cache.Put(1, "OLD")
+	cache.Put(1, "NEW")
+	if v, _ := cache.Get(1); v != "NEW" || cache.Len() != 1 {
+		t.Errorf("Get(1) = %q with Len %d, want NEW with 1 entry", v, cache.Len())
+	}
+
+	cache.Clear()
+	if cache.Len() != 0 {
+		t.Errorf("Len() after Clear = %d", cache.Len())
+	}
+}
@@ -2,690 +2,321 @@ package network
 
 import (
 	"context"
-	"crypto/sha256"
 	"fmt"
 	"log"
-	"net"
-	"strings"
 	"sync"
 	"time"
 
+	"github.com/dbehnke/ysf2dmr/internal/capture"
 	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/supervisor"
+	"github.com/dbehnke/ysf2dmr/internal/trace"
 )
 
-// DMRPacket represents a received DMR packet with metadata
-type DMRPacket struct {
-	Data     []byte
-	Length   int
-	FromAddr *net.UDPAddr
-}
-
-// DMRClient provides a goroutine-based DMR network client
+// DMRClientClockPeriod is how often the client clocks its network's timers
+// Packets are handled as they arrive, between these clocks.
+const DMRClientClockPeriod = 10 * time.Millisecond
+
+// DMRClient runs a DMR network on goroutines
+// The network keeps the protocol (Homebrew, OpenBridge or DMRGateway). Its
+// socket is read by a goroutine with blocking reads, which wakes the client
+// goroutine to handle each packet as it arrives; the client delivers the
+// frames and status changes on channels. The client is itself a
+// DMRNetworkInterface, so the polled gateway runs the same implementation
+// through Read and WantsBeacon.
 type DMRClient struct {
-	// Configuration
-	config    *DMRConfig
-	debug     bool
+	debug bool
 
-	// Network
-	conn      *net.UDPConn
-	serverAddr *net.UDPAddr
-
-	// State
-	status    protocol.DMRNetworkStatus
-	salt      []byte
+	// Network, guarded by mu
+	mu        sync.Mutex
+	network   DMRNetworkInterface
+	connected bool // status at the last clock, for the events
+	beacon    bool // beacon requested and not yet taken by WantsBeacon
 
 	// Channels for Go-native communication
-	inbound   chan *DMRPacket    // Data packets for external processing
-	outbound  chan []byte        // Packets to send to server
-	events    chan string        // Status/event notifications
-	shutdown  chan struct{}      // Shutdown signal
-	authPackets chan []byte      // Internal authentication packets
-
-	// Timers - using Go's native timing
-	retryTimer    *time.Timer
-	timeoutTimer  *time.Timer
-
-	// Sync
-	mu         sync.RWMutex
-	running    bool
-}
+	inbound chan *protocol.DMRData // Frames for external processing
+	events  chan string            // Status/event notifications
 
-// DMRConfig holds DMR client configuration
-type DMRConfig struct {
-	ServerAddress string
-	ServerPort    int
-	LocalPort     int
-	RepeaterID    uint32
-	Password      string
-	Callsign      string
-
-	// Repeater info
-	RxFrequency uint32
-	TxFrequency uint32
-	Power       uint32
-	ColorCode   uint32
-	Latitude    float32
-	Longitude   float32
-	Height      int
-	Location    string
-	Description string
-	URL         string
-	Options     string
-}
+	running bool
 
-// NewDMRClient creates a new goroutine-based DMR client
-func NewDMRClient(config *DMRConfig, debug bool) (*DMRClient, error) {
-	// Resolve server address
-	serverAddr, err := net.ResolveUDPAddr("udp4",
-		fmt.Sprintf("%s:%d", config.ServerAddress, config.ServerPort))
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve DMR server: %v", err)
-	}
+	// Goroutines are restarted if they panic
+	supervisor *supervisor.Supervisor
+	cancel     context.CancelFunc
+}
 
-	client := &DMRClient{
-		config:     config,
-		debug:      debug,
-		serverAddr: serverAddr,
-		status:     protocol.DMR_WAITING_CONNECT,
-		salt:       make([]byte, protocol.DMR_SALT_LENGTH),
+// NewDMRClient creates a goroutine-based client for network, which it
+// opens when started and closes when stopped
+func NewDMRClient(network DMRNetworkInterface, debug bool) *DMRClient {
+	return &DMRClient{
+		debug:   debug,
+		network: network,
 
 		// Buffered channels for smooth operation
-		inbound:     make(chan *DMRPacket, 10),
-		outbound:    make(chan []byte, 10),
-		events:      make(chan string, 10),
-		shutdown:    make(chan struct{}),
-		authPackets: make(chan []byte, 10),
-	}
+		inbound: make(chan *protocol.DMRData, 10),
+		events:  make(chan string, 10),
 
-	if debug {
-		log.Printf("DMR Client created: server=%s, id=%d, localPort=%d",
-			serverAddr.String(), config.RepeaterID, config.LocalPort)
+		supervisor: supervisor.New("DMR client"),
 	}
-
-	return client, nil
 }
 
-// Start begins the DMR client goroutines
+// Start opens the network and begins the clock goroutine
 func (c *DMRClient) Start(ctx context.Context) error {
 	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.running {
-		c.mu.Unlock()
 		return fmt.Errorf("DMR client already running")
 	}
-	c.running = true
-	c.mu.Unlock()
-
-	// Bind UDP socket
-	localAddr := &net.UDPAddr{
-		IP:   net.IPv4zero,
-		Port: c.config.LocalPort,
-	}
-
-	var err error
-	c.conn, err = net.ListenUDP("udp4", localAddr)
-	if err != nil {
-		return fmt.Errorf("failed to bind DMR socket: %v", err)
-	}
 
-	if c.debug {
-		log.Printf("DMR Client bound to %s", c.conn.LocalAddr().String())
+	if err := c.network.Open(); err != nil {
+		return fmt.Errorf("failed to open DMR network: %v", err)
 	}
+	c.network.Enable(true)
+	c.running = true
 
-	// Start goroutines
-	go c.networkReader(ctx)
-	go c.networkWriter(ctx)
-	go c.authenticationManager(ctx)
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.supervisor.Go(ctx, "network clock", c.clock)
 
 	return nil
 }
 
-// networkReader goroutine - handles incoming packets with blocking reads
-func (c *DMRClient) networkReader(ctx context.Context) {
-	defer c.conn.Close()
+// clock goroutine - clocks the network when packets arrive and every
+// DMRClientClockPeriod for its timers
+func (c *DMRClient) clock(ctx context.Context) {
+	ticker := time.NewTicker(DMRClientClockPeriod)
+	defer ticker.Stop()
 
-	buffer := make([]byte, 500)
+	// Without notification packets wait for the next tick
+	var ready <-chan struct{}
+	if notifier, ok := c.network.(PacketNotifier); ok {
+		ready = notifier.Ready()
+	}
 
+	last := time.Now()
 	for {
+		var now time.Time
 		select {
 		case <-ctx.Done():
 			return
-		case <-c.shutdown:
-			return
-		default:
-			// Blocking read with reasonable timeout
-			c.conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
-
-			n, fromAddr, err := c.conn.ReadFromUDP(buffer)
-			if err != nil {
-				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-					continue // Timeout is normal, continue reading
-				}
-				if c.debug {
-					log.Printf("DMR read error: %v", err)
-				}
-				continue
-			}
-
-			// Validate source address
-			if !fromAddr.IP.Equal(c.serverAddr.IP) || fromAddr.Port != c.serverAddr.Port {
-				if c.debug {
-					log.Printf("DMR: Ignoring packet from %s (expected %s)",
-						fromAddr.String(), c.serverAddr.String())
-				}
-				continue
-			}
-
-			// Send packet to appropriate processing channel
-			packetData := make([]byte, n)
-			copy(packetData, buffer[:n])
-
-			// Separate authentication packets from data packets
-			isAuthPacket := c.isAuthenticationPacket(packetData)
-
-			if isAuthPacket {
-				// Authentication packets go to internal processing
-				select {
-				case c.authPackets <- packetData:
-					if c.debug {
-						log.Printf("DMR: Received auth packet %d bytes from %s", n, fromAddr.String())
-					}
-				default:
-					if c.debug {
-						log.Printf("DMR: Auth channel full, dropping packet")
-					}
-				}
-			} else {
-				// Data packets go to external processing
-				packet := &DMRPacket{
-					Data:     packetData,
-					Length:   n,
-					FromAddr: fromAddr,
-				}
-
-				select {
-				case c.inbound <- packet:
-					if c.debug {
-						log.Printf("DMR: Received data packet %d bytes from %s", n, fromAddr.String())
-					}
-				default:
-					if c.debug {
-						log.Printf("DMR: Inbound channel full, dropping packet")
-					}
-				}
-			}
+		case now = <-ticker.C:
+		case <-ready:
+			now = time.Now()
 		}
+		// Carry the sub-millisecond remainder to the next clock
+		ms := now.Sub(last) / time.Millisecond
+		last = last.Add(ms * time.Millisecond)
+		c.step(int(ms))
 	}
 }
 
-// networkWriter goroutine - handles outgoing packets
-func (c *DMRClient) networkWriter(ctx context.Context) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-c.shutdown:
-			return
-		case packet := <-c.outbound:
-			_, err := c.conn.WriteToUDP(packet, c.serverAddr)
-			if err != nil {
-				if c.debug {
-					log.Printf("DMR write error: %v", err)
-				}
-				// Signal connection problem
-				c.events <- "WRITE_ERROR"
-			}
+// step clocks the network by ms and delivers what it produced
+func (c *DMRClient) step(ms int) {
+	c.mu.Lock()
+	c.network.Clock(ms)
+
+	var events []string
+	if connected := c.network.IsConnected(); connected != c.connected {
+		c.connected = connected
+		if connected {
+			events = append(events, "AUTHENTICATED")
+		} else {
+			events = append(events, "DISCONNECTED")
 		}
 	}
-}
-
-// isAuthenticationPacket determines if a packet is authentication-related
-func (c *DMRClient) isAuthenticationPacket(data []byte) bool {
-	if len(data) < 4 {
-		return false
+	if c.network.WantsBeacon() {
+		c.beacon = true
+		events = append(events, "BEACON")
 	}
 
-	// Check for authentication packet types
-	if len(data) >= 6 && string(data[:6]) == "RPTACK" {
-		return true
-	}
-	if len(data) >= 6 && string(data[:6]) == "MSTNAK" {
-		return true
+	var frames []*protocol.DMRData
+	for {
+		data := protocol.NewDMRData()
+		if !c.network.Read(data) {
+			break
+		}
+		frames = append(frames, data)
 	}
-	if len(data) >= 7 && string(data[:7]) == "MSTPONG" {
-		return true
+	c.mu.Unlock()
+
+	for _, event := range events {
+		c.sendEvent(event)
 	}
-	if len(data) >= 5 && string(data[:5]) == "MSTCL" {
-		return true
+	for _, data := range frames {
+		select {
+		case c.inbound <- data:
+		default:
+			if c.debug {
+				log.Printf("DMR: Inbound channel full, dropping frame")
+			}
+		}
 	}
-
-	return false // Data packets (DMRD, etc.)
 }
 
-// authenticationManager goroutine - handles DMR authentication state machine
-func (c *DMRClient) authenticationManager(ctx context.Context) {
-	// Start with connection delay
-	c.retryTimer = time.NewTimer(10 * time.Second)
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-c.shutdown:
-			return
-		case <-c.retryTimer.C:
-			c.handleRetryTimeout()
-		case packet := <-c.authPackets:
-			c.processPacket(packet)
+// sendEvent queues an event, dropping it when nobody is listening
+func (c *DMRClient) sendEvent(event string) {
+	select {
+	case c.events <- event:
+	default:
+		if c.debug {
+			log.Printf("DMR: Events channel full, dropping %s", event)
 		}
 	}
 }
 
-// GetInbound returns the inbound packet channel for external processing
-func (c *DMRClient) GetInbound() <-chan *DMRPacket {
-	return c.inbound
+// SetTrace records the packets exchanged with the master in t, if the
+// network can trace
+// Must be called before Start.
+func (c *DMRClient) SetTrace(t *trace.Tracer) {
+	if tracer, ok := c.network.(PacketTracer); ok {
+		tracer.SetTrace(t)
+	}
 }
 
-// GetEvents returns the events channel for status monitoring
-func (c *DMRClient) GetEvents() <-chan string {
-	return c.events
+// SetCapture keeps the network's recent packets in r, if it can capture
+// Must be called before Start.
+func (c *DMRClient) SetCapture(r *capture.Ring) {
+	if capturer, ok := c.network.(PacketCapturer); ok {
+		capturer.SetCapture(r)
+	}
 }
 
-// IsConnected returns true if authenticated and running
-func (c *DMRClient) IsConnected() bool {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.status == protocol.DMR_RUNNING
+// Open starts the client, for the polled gateway
+func (c *DMRClient) Open() error {
+	return c.Start(context.Background())
 }
 
-// GetStatus returns current authentication status
-func (c *DMRClient) GetStatus() protocol.DMRNetworkStatus {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.status
+// Close stops the client, for the polled gateway
+func (c *DMRClient) Close() {
+	c.Stop()
 }
 
-// Stop gracefully shuts down the DMR client
-func (c *DMRClient) Stop() {
+// Enable enables or disables data reception
+func (c *DMRClient) Enable(enabled bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-
-	if !c.running {
-		return
-	}
-
-	close(c.shutdown)
-	c.running = false
-
-	if c.retryTimer != nil {
-		c.retryTimer.Stop()
-	}
-	if c.timeoutTimer != nil {
-		c.timeoutTimer.Stop()
-	}
+	c.network.Enable(enabled)
 }
 
-// sendPacket queues a packet for transmission
-func (c *DMRClient) sendPacket(data []byte) {
+// Read takes the next received frame off the inbound channel, false if none
+// is waiting; goroutine consumers use GetInbound instead
+func (c *DMRClient) Read(data *protocol.DMRData) bool {
 	select {
-	case c.outbound <- data:
-		// Packet queued successfully
+	case frame := <-c.inbound:
+		*data = *frame
+		return true
 	default:
-		if c.debug {
-			log.Printf("DMR: Outbound channel full, dropping packet")
-		}
+		return false
 	}
 }
 
-// handleRetryTimeout implements the authentication state machine
-func (c *DMRClient) handleRetryTimeout() {
+// Clock does nothing: the client goroutine clocks the network
+func (c *DMRClient) Clock(ms int) {}
+
+// WantsBeacon checks and clears a pending beacon request
+// Goroutine consumers get the BEACON event instead.
+func (c *DMRClient) WantsBeacon() bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-
-	switch c.status {
-	case protocol.DMR_WAITING_CONNECT:
-		if c.debug {
-			log.Printf("DMR: Starting authentication - sending login")
-		}
-		c.sendLogin()
-		c.status = protocol.DMR_WAITING_LOGIN
-		c.timeoutTimer = time.NewTimer(60 * time.Second) // Connection timeout
-
-	case protocol.DMR_WAITING_LOGIN:
-		if c.debug {
-			log.Printf("DMR: Retrying login")
-		}
-		c.sendLogin()
-
-	case protocol.DMR_WAITING_AUTHORISATION:
-		if c.debug {
-			log.Printf("DMR: Retrying authorization")
-		}
-		c.sendAuth()
-
-	case protocol.DMR_WAITING_CONFIG:
-		if c.debug {
-			log.Printf("DMR: Retrying configuration")
-		}
-		c.sendConfig()
-
-	case protocol.DMR_RUNNING:
-		if c.debug {
-			log.Printf("DMR: Sending ping")
-		}
-		c.sendPing()
-
-	default:
-		if c.debug {
-			log.Printf("DMR: Unknown state, resetting to WAITING_CONNECT")
-		}
-		c.status = protocol.DMR_WAITING_CONNECT
-	}
-
-	// Reset retry timer for next attempt
-	c.retryTimer.Reset(10 * time.Second)
+	beacon := c.beacon
+	c.beacon = false
+	return beacon
 }
 
-// processPacket handles incoming DMR packets
-func (c *DMRClient) processPacket(data []byte) {
-	if len(data) < 4 {
-		return // Invalid packet
-	}
+// GetInbound returns the channel of received frames
+func (c *DMRClient) GetInbound() <-chan *protocol.DMRData {
+	return c.inbound
+}
 
-	if c.debug {
-		maxLen := len(data)
-		if maxLen > 16 {
-			maxLen = 16
-		}
-		log.Printf("DMR: Raw packet (%d bytes): %02X | String: %q", len(data), data[:maxLen], string(data[:min(6, len(data))]))
-	}
+// GetEvents returns the events channel for status monitoring
+// The events are AUTHENTICATED, DISCONNECTED and BEACON.
+func (c *DMRClient) GetEvents() <-chan string {
+	return c.events
+}
 
+// Write sends a frame to the network
+func (c *DMRClient) Write(data *protocol.DMRData) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-
-	// Check for 6-byte RPTACK first (most common)
-	if len(data) >= 6 && string(data[:6]) == "RPTACK" {
-		if c.debug {
-			log.Printf("DMR: Processing RPTACK packet (%d bytes): %02X", len(data), data)
-		}
-		c.handleRPTACK(data)
-		return
-	}
-
-	// Check for 4-byte magic packets
-	if len(data) >= 4 {
-		magic := string(data[:4])
-		switch magic {
-		case "MSTN": // MSTNAK
-			if len(data) >= 6 && string(data[:6]) == "MSTNAK" {
-				c.handleMSTNAK(data)
-			}
-		case "MSTP": // MSTPONG
-			if len(data) >= 7 && string(data[:7]) == "MSTPONG" {
-				c.handleMSTPONG(data)
-			}
-		case "MSTC": // MSTCL
-			if len(data) >= 5 && string(data[:5]) == "MSTCL" {
-				c.handleMSTCL(data)
-			}
-		default:
-			if c.debug {
-				// Show more packet info for debugging
-				maxLen := len(data)
-				if maxLen > 16 {
-					maxLen = 16
-				}
-				log.Printf("DMR: Unknown packet type with data (%d bytes): %02X", len(data), data[:maxLen])
-			}
-		}
-	}
-}
-
-// Helper function for Go versions that don't have min built-in
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
+	return c.network.Write(data)
 }
 
-// Authentication packet handlers
-func (c *DMRClient) handleRPTACK(packet []byte) {
-	if c.debug {
-		log.Printf("DMR: Received RPTACK in state %s, packet length %d", c.getStatusString(), len(packet))
-		log.Printf("DMR: RPTACK packet hex: %02X", packet)
-	}
-
-	switch c.status {
-	case protocol.DMR_WAITING_LOGIN:
-		// Extract salt
-		if len(packet) >= 10 {
-			copy(c.salt, packet[6:10])
-			if c.debug {
-				log.Printf("DMR: Received salt: %02X", c.salt)
-			}
-		}
-		c.sendAuth()
-		c.status = protocol.DMR_WAITING_AUTHORISATION
-
-	case protocol.DMR_WAITING_AUTHORISATION:
-		c.sendConfig()
-		c.status = protocol.DMR_WAITING_CONFIG
-
-	case protocol.DMR_WAITING_CONFIG:
-		if c.config.Options != "" {
-			c.sendOptions()
-			c.status = protocol.DMR_WAITING_OPTIONS
-		} else {
-			c.status = protocol.DMR_RUNNING
-			c.timeoutTimer.Reset(60 * time.Second)
-			if c.debug {
-				log.Printf("DMR: Authentication complete - RUNNING")
-			}
-			c.events <- "AUTHENTICATED"
-		}
-
-	case protocol.DMR_WAITING_OPTIONS:
-		c.status = protocol.DMR_RUNNING
-		c.timeoutTimer.Reset(60 * time.Second)
-		if c.debug {
-			log.Printf("DMR: Authentication complete - RUNNING")
-		}
-		c.events <- "AUTHENTICATED"
-	}
+// IsConnected returns true if the network is ready to carry traffic
+func (c *DMRClient) IsConnected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.network.IsConnected()
 }
 
-func (c *DMRClient) handleMSTNAK(packet []byte) {
-	if c.debug {
-		log.Printf("DMR: Received MSTNAK - authentication failed")
-	}
-	c.status = protocol.DMR_WAITING_LOGIN
-	c.retryTimer.Reset(10 * time.Second)
-	c.events <- "AUTH_FAILED"
+// GetStatusString returns the network's connection state for logging
+func (c *DMRClient) GetStatusString() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.network.GetStatusString()
 }
 
-func (c *DMRClient) handleMSTPONG(packet []byte) {
-	if c.debug {
-		log.Printf("DMR: Received MSTPONG - connection alive")
+// LastReceived returns when the network last received a keepalive reply
+// and a data packet; both are zero if it does not record them
+func (c *DMRClient) LastReceived() (keepalive, data time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if activity, ok := c.network.(LinkActivity); ok {
+		return activity.LastReceived()
 	}
-	c.timeoutTimer.Reset(60 * time.Second)
+	return time.Time{}, time.Time{}
 }
 
-func (c *DMRClient) handleMSTCL(packet []byte) {
-	if c.debug {
-		log.Printf("DMR: Received MSTCL - master closing")
+// TalkerAlias returns the alias the master sent for srcID, if the network
+// handles the master's reports
+func (c *DMRClient) TalkerAlias(srcID uint32) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if reports, ok := c.network.(MasterReports); ok {
+		return reports.TalkerAlias(srcID)
 	}
-	c.status = protocol.DMR_WAITING_CONNECT
-	c.retryTimer.Reset(10 * time.Second)
-	c.events <- "DISCONNECTED"
+	return "", false
 }
 
-func (c *DMRClient) getStatusString() string {
-	switch c.status {
-	case protocol.DMR_WAITING_CONNECT:
-		return "WAITING_CONNECT"
-	case protocol.DMR_WAITING_LOGIN:
-		return "WAITING_LOGIN"
-	case protocol.DMR_WAITING_AUTHORISATION:
-		return "WAITING_AUTHORISATION"
-	case protocol.DMR_WAITING_CONFIG:
-		return "WAITING_CONFIG"
-	case protocol.DMR_WAITING_OPTIONS:
-		return "WAITING_OPTIONS"
-	case protocol.DMR_RUNNING:
-		return "RUNNING"
-	default:
-		return "UNKNOWN"
+// RSSI returns the receive level the master last reported for slotNo, if
+// the network handles the master's reports
+func (c *DMRClient) RSSI(slotNo uint8) (dBm int, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if reports, ok := c.network.(MasterReports); ok {
+		return reports.RSSI(slotNo)
 	}
+	return 0, time.Time{}
 }
 
-// Packet building methods
-func (c *DMRClient) sendLogin() {
-	packet := make([]byte, protocol.NETWORK_LOGIN_LENGTH)
-	copy(packet[0:4], "RPTL")
-
-	// Convert repeater ID to big-endian bytes
-	packet[4] = byte(c.config.RepeaterID >> 24)
-	packet[5] = byte(c.config.RepeaterID >> 16)
-	packet[6] = byte(c.config.RepeaterID >> 8)
-	packet[7] = byte(c.config.RepeaterID)
-
-	c.sendPacket(packet)
-	if c.debug {
-		log.Printf("DMR: Sent login packet (ID: %d)", c.config.RepeaterID)
+// LoopsDetected returns the number of our own frames echoed back, if the
+// network detects loops
+func (c *DMRClient) LoopsDetected() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if loops, ok := c.network.(LoopDetector); ok {
+		return loops.LoopsDetected()
 	}
+	return 0
 }
 
-func (c *DMRClient) sendAuth() {
-	// Calculate SHA256(salt + password)
-	hasher := sha256.New()
-	hasher.Write(c.salt)
-	hasher.Write([]byte(c.config.Password))
-	hash := hasher.Sum(nil)
-
-	packet := make([]byte, protocol.NETWORK_AUTH_LENGTH)
-	copy(packet[0:4], "RPTK")
-
-	// Repeater ID
-	packet[4] = byte(c.config.RepeaterID >> 24)
-	packet[5] = byte(c.config.RepeaterID >> 16)
-	packet[6] = byte(c.config.RepeaterID >> 8)
-	packet[7] = byte(c.config.RepeaterID)
-
-	// SHA256 hash
-	copy(packet[8:40], hash[:32])
-
-	c.sendPacket(packet)
-	if c.debug {
-		log.Printf("DMR: Sent auth packet")
-	}
+// Panics returns the number of panics recovered in the client goroutines
+func (c *DMRClient) Panics() uint64 {
+	return c.supervisor.Panics()
 }
 
-func (c *DMRClient) sendConfig() {
-	packet := make([]byte, protocol.NETWORK_CONFIG_LENGTH)
-
-	// Magic and ID
-	copy(packet[0:4], "RPTC")
-	packet[4] = byte(c.config.RepeaterID >> 24)
-	packet[5] = byte(c.config.RepeaterID >> 16)
-	packet[6] = byte(c.config.RepeaterID >> 8)
-	packet[7] = byte(c.config.RepeaterID)
-
-	// Callsign (8 bytes, left-aligned with right padding)
-	callsign := strings.ToUpper(c.config.Callsign)
-	if len(callsign) > 8 {
-		callsign = callsign[:8]
-	}
-	copy(packet[8:], callsign)
-	for i := len(callsign); i < 8; i++ {
-		packet[8+i] = ' '
-	}
-
-	// Frequencies, power, color code, location data, etc.
-	// Use the same formatting as the fixed packet format
-	rxFreqStr := fmt.Sprintf("%09d", c.config.RxFrequency)
-	txFreqStr := fmt.Sprintf("%09d", c.config.TxFrequency)
-	copy(packet[16:25], rxFreqStr)
-	copy(packet[25:34], txFreqStr)
-
-	powerStr := fmt.Sprintf("%02d", c.config.Power)
-	copy(packet[34:36], powerStr)
-
-	ccStr := fmt.Sprintf("%02d", c.config.ColorCode)
-	copy(packet[36:38], ccStr)
-
-	latStr := fmt.Sprintf("%08f", c.config.Latitude)
-	if len(latStr) > 8 {
-		latStr = latStr[:8]
-	}
-	copy(packet[38:46], latStr)
-
-	lngStr := fmt.Sprintf("%09f", c.config.Longitude)
-	if len(lngStr) > 9 {
-		lngStr = lngStr[:9]
-	}
-	copy(packet[46:55], lngStr)
-
-	heightStr := fmt.Sprintf("%03d", c.config.Height)
-	copy(packet[55:58], heightStr)
-
-	// Location, description, URL, version strings
-	// Truncate if too long
-	location := c.config.Location
-	if len(location) > 20 {
-		location = location[:20]
-	}
-	copy(packet[58:78], location)
-
-	description := c.config.Description
-	if len(description) > 19 {
-		description = description[:19]
-	}
-	copy(packet[78:97], description)
-
-	packet[97] = '3' // Both slots enabled
-
-	url := c.config.URL
-	if len(url) > 124 {
-		url = url[:124]
+// Stop gracefully shuts down the DMR client and closes the network
+func (c *DMRClient) Stop() {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return
 	}
-	copy(packet[98:222], url)
-
-	copy(packet[222:262], "1.0.0-go-goroutines") // Version
-
-	copy(packet[262:302], "HOMEBREW") // Hardware type
+	c.running = false
+	c.mu.Unlock()
 
-	c.sendPacket(packet)
-	if c.debug {
-		log.Printf("DMR: Sent config packet")
-	}
-}
+	c.cancel()
+	c.supervisor.Wait()
 
-func (c *DMRClient) sendOptions() {
-	packet := make([]byte, 8+len(c.config.Options)+1)
-	copy(packet[0:4], "RPTO")
-	packet[4] = byte(c.config.RepeaterID >> 24)
-	packet[5] = byte(c.config.RepeaterID >> 16)
-	packet[6] = byte(c.config.RepeaterID >> 8)
-	packet[7] = byte(c.config.RepeaterID)
-	copy(packet[8:], c.config.Options)
-	packet[len(packet)-1] = 0 // Null terminator
-
-	c.sendPacket(packet)
-	if c.debug {
-		log.Printf("DMR: Sent options packet")
-	}
+	c.mu.Lock()
+	c.network.Close()
+	c.connected, c.beacon = false, false
+	c.mu.Unlock()
 }
-
-func (c *DMRClient) sendPing() {
-	packet := make([]byte, protocol.NETWORK_PING_LENGTH)
-	copy(packet[0:7], "RPTPING")
-	packet[7] = byte(c.config.RepeaterID >> 24)
-	packet[8] = byte(c.config.RepeaterID >> 16)
-	packet[9] = byte(c.config.RepeaterID >> 8)
-	packet[10] = byte(c.config.RepeaterID)
-
-	c.sendPacket(packet)
-	if c.debug {
-		log.Printf("DMR: Sent ping packet")
-	}
-}
\ No newline at end of file
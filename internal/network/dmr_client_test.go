@@ -0,0 +1,136 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+func newTestDMRClient(t *testing.T) (*DMRClient, *DMRNetwork) {
+	t.Helper()
+	network, err := NewDMRNetwork("127.0.0.1", 62030, 0, 123456, "test123",
+		true, "1.0.0", false, true, true, protocol.HW_TYPE_HOMEBREW, 120)
+	if err != nil {
+		t.Fatalf("Failed to create network: %v", err)
+	}
+	network.Enable(true)
+	return NewDMRClient(network, false), network
+}
+
+// nextEvent returns the next queued event, or "" if there is none
+func nextEvent(c *DMRClient) string {
+	select {
+	case event := <-c.GetEvents():
+		return event
+	default:
+		return ""
+	}
+}
+
+func TestDMRClient_StatusEvents(t *testing.T) {
+	client, network := newTestDMRClient(t)
+
+	client.step(10)
+	if event := nextEvent(client); event != "" {
+		t.Errorf("event = %q before login", event)
+	}
+
+	network.status = protocol.DMR_RUNNING
+	client.step(10)
+	if event := nextEvent(client); event != "AUTHENTICATED" {
+		t.Errorf("event = %q, want AUTHENTICATED", event)
+	}
+	if !client.IsConnected() || client.GetStatusString() != "RUNNING" {
+		t.Errorf("IsConnected() = false, status %s", client.GetStatusString())
+	}
+
+	network.processPacket([]byte("RPTSBKN\x00\x01\xE2\x40"))
+	client.step(10)
+	if event := nextEvent(client); event != "BEACON" {
+		t.Errorf("event = %q, want BEACON", event)
+	}
+	if !client.WantsBeacon() || client.WantsBeacon() {
+		t.Error("WantsBeacon() did not report the beacon once")
+	}
+
+	network.processPacket([]byte("MSTCL\x00\x01\xE2\x40"))
+	client.step(10)
+	if event := nextEvent(client); event != "DISCONNECTED" {
+		t.Errorf("event = %q, want DISCONNECTED", event)
+	}
+	if event := nextEvent(client); event != "" {
+		t.Errorf("unexpected event %q", event)
+	}
+}
+
+func TestDMRClient_DeliversFrames(t *testing.T) {
+	client, network := newTestDMRClient(t)
+	network.status = protocol.DMR_RUNNING
+
+	network.processPacket(fuzzSeedDMRD(2, protocol.DT_VOICE_LC_HEADER))
+	client.step(200) // past the 120 ms jitter buffer
+
+	select {
+	case frame := <-client.GetInbound():
+		if frame.GetSlotNo() != 2 || frame.GetSrcId() != 3100123 {
+			t.Errorf("frame = %s", frame.String())
+		}
+	default:
+		t.Fatal("no frame delivered")
+	}
+}
+
+func TestDMRClient_MasterReports(t *testing.T) {
+	client, network := newTestDMRClient(t)
+	var reports MasterReports = client
+
+	network.processPacket([]byte("RPTRSSI\x00\x01\xE2\x40\x01\x50"))
+	if dBm, _ := reports.RSSI(1); dBm != -80 {
+		t.Errorf("RSSI(1) = %d, want -80", dBm)
+	}
+	for _, packet := range testTalkerAlias(3100001) {
+		network.processPacket(packet)
+	}
+	if alias, ok := reports.TalkerAlias(3100001); !ok || alias != "W1AW HERMA" {
+		t.Errorf("TalkerAlias() = %q, %v, want W1AW HERMA", alias, ok)
+	}
+}
+
+// The gateway polls the client like any network, while the client's
+// goroutine reads the socket
+func TestDMRClient_PolledByGateway(t *testing.T) {
+	peer, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP() error = %v", err)
+	}
+	defer peer.Close()
+
+	obp, err := NewOpenBridgeNetwork("127.0.0.1", peer.LocalAddr().(*net.UDPAddr).Port, 0, 3100001, "passw0rd", false, 60)
+	if err != nil {
+		t.Fatalf("NewOpenBridgeNetwork() error = %v", err)
+	}
+	var client DMRNetworkInterface = NewDMRClient(obp, false)
+	if err := client.Open(); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer client.Close()
+	client.Enable(true)
+
+	sender := newTestOpenBridge(t)
+	local := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: obp.socket.LocalAddr().Port}
+	if _, err := peer.WriteToUDP(sender.buildPacket(testOpenBridgeFrame(1)), local); err != nil {
+		t.Fatalf("write error = %v", err)
+	}
+
+	received := protocol.NewDMRData()
+	for deadline := time.Now().Add(time.Second); !client.Read(received); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("Read() returned nothing")
+		}
+	}
+	if received.GetSrcId() != 3100123 || received.GetDstId() != 91 {
+		t.Errorf("Read() = %s", received.String())
+	}
+}
@@ -0,0 +1,120 @@
+package network
+
+import "github.com/dbehnke/ysf2dmr/internal/protocol"
+
+// loginEvent is an input to the Homebrew login state machine
+type loginEvent int
+
+const (
+	loginRetryTimer   loginEvent = iota // retry timer expired
+	loginTimeout                        // connection timeout expired
+	loginSocketOpened                   // the socket opened after actOpenSocket
+	loginSocketFailed                   // the socket could not be opened
+	loginSocketError                    // a send or receive on the socket failed
+	loginACK                            // RPTACK received
+	loginNAK                            // MSTNAK received
+	loginPong                           // MSTPONG received
+	loginMasterClosed                   // MSTCL received
+)
+
+// loginAction is an output of the login state machine, carried out by DMRNetwork
+type loginAction int
+
+const (
+	actOpenSocket   loginAction = iota // open the socket, reporting loginSocketOpened or loginSocketFailed
+	actStoreSalt                       // keep the salt from the RPTACK
+	actSendLogin                       // send RPTL
+	actSendAuth                        // send RPTK
+	actSendConfig                      // send RPTC
+	actSendOptions                     // send RPTO
+	actSendPing                        // send RPTPING
+	actStartRetry                      // (re)start the retry timer
+	actStartTimeout                    // (re)start the connection timeout
+)
+
+// loginMachine is the Homebrew login and keepalive state machine
+// It has no clock or socket of its own: timer expiries and packets come in as
+// events and the packets to send and timers to start go out as actions, so
+// every transition can be tested without a network or sleeps.
+type loginMachine struct {
+	status     protocol.DMRNetworkStatus
+	hasOptions bool // an options string is configured
+
+	// Login adjustments for the master type
+	quirks          masterQuirks
+	optionsRejected bool // the master NAKed the options; log in without them
+}
+
+// handle applies an event and returns the actions to carry out, in order
+func (m *loginMachine) handle(ev loginEvent) []loginAction {
+	switch ev {
+	case loginRetryTimer:
+		switch m.status {
+		case protocol.DMR_WAITING_CONNECT:
+			// The retry timer restarts once the open has been reported
+			return []loginAction{actOpenSocket}
+		case protocol.DMR_WAITING_LOGIN:
+			return []loginAction{actSendLogin, actStartRetry}
+		case protocol.DMR_WAITING_AUTHORISATION:
+			return []loginAction{actSendAuth, actStartRetry}
+		case protocol.DMR_WAITING_CONFIG:
+			return []loginAction{actSendConfig, actStartRetry}
+		case protocol.DMR_WAITING_OPTIONS:
+			return []loginAction{actSendOptions, actStartRetry}
+		case protocol.DMR_RUNNING:
+			return []loginAction{actSendPing, actStartRetry}
+		default:
+			m.status = protocol.DMR_WAITING_CONNECT
+			return []loginAction{actStartRetry}
+		}
+
+	case loginSocketOpened:
+		if m.status != protocol.DMR_WAITING_CONNECT {
+			return nil
+		}
+		m.status = protocol.DMR_WAITING_LOGIN
+		return []loginAction{actSendLogin, actStartTimeout, actStartRetry}
+
+	case loginSocketFailed:
+		return []loginAction{actStartRetry}
+
+	case loginACK:
+		switch m.status {
+		case protocol.DMR_WAITING_LOGIN:
+			m.status = protocol.DMR_WAITING_AUTHORISATION
+			return []loginAction{actStoreSalt, actSendAuth}
+		case protocol.DMR_WAITING_AUTHORISATION:
+			m.status = protocol.DMR_WAITING_CONFIG
+			return []loginAction{actSendConfig}
+		case protocol.DMR_WAITING_CONFIG:
+			if m.hasOptions && !m.optionsRejected {
+				m.status = protocol.DMR_WAITING_OPTIONS
+				return []loginAction{actSendOptions}
+			}
+			m.status = protocol.DMR_RUNNING
+			return []loginAction{actStartTimeout}
+		case protocol.DMR_WAITING_OPTIONS:
+			m.status = protocol.DMR_RUNNING
+			return []loginAction{actStartTimeout}
+		default:
+			// Ignore RPTACK in other states
+			return nil
+		}
+
+	case loginNAK:
+		if m.status == protocol.DMR_WAITING_OPTIONS && m.quirks.optionalOptions {
+			m.optionsRejected = true
+		}
+		m.status = protocol.DMR_WAITING_LOGIN
+		return []loginAction{actStartRetry}
+
+	case loginPong:
+		return []loginAction{actStartTimeout}
+
+	case loginTimeout, loginSocketError, loginMasterClosed:
+		m.status = protocol.DMR_WAITING_CONNECT
+		return []loginAction{actStartRetry}
+	}
+
+	return nil
+}
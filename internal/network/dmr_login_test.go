@@ -0,0 +1,229 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+func TestLoginMachineTransitions(t *testing.T) {
+	const (
+		connect = protocol.DMR_WAITING_CONNECT
+		login   = protocol.DMR_WAITING_LOGIN
+		auth    = protocol.DMR_WAITING_AUTHORISATION
+		config  = protocol.DMR_WAITING_CONFIG
+		options = protocol.DMR_WAITING_OPTIONS
+		running = protocol.DMR_RUNNING
+	)
+	optional := masterQuirks{optionalOptions: true}
+
+	tests := []struct {
+		name         string
+		status       protocol.DMRNetworkStatus
+		hasOptions   bool
+		quirks       masterQuirks
+		rejected     bool
+		event        loginEvent
+		wantStatus   protocol.DMRNetworkStatus
+		wantActions  []loginAction
+		wantRejected bool
+	}{
+		// Retry timer
+		{name: "retry opens socket", status: connect, event: loginRetryTimer,
+			wantStatus: connect, wantActions: []loginAction{actOpenSocket}},
+		{name: "retry resends login", status: login, event: loginRetryTimer,
+			wantStatus: login, wantActions: []loginAction{actSendLogin, actStartRetry}},
+		{name: "retry resends auth", status: auth, event: loginRetryTimer,
+			wantStatus: auth, wantActions: []loginAction{actSendAuth, actStartRetry}},
+		{name: "retry resends config", status: config, event: loginRetryTimer,
+			wantStatus: config, wantActions: []loginAction{actSendConfig, actStartRetry}},
+		{name: "retry resends options", status: options, hasOptions: true, event: loginRetryTimer,
+			wantStatus: options, wantActions: []loginAction{actSendOptions, actStartRetry}},
+		{name: "retry pings when running", status: running, event: loginRetryTimer,
+			wantStatus: running, wantActions: []loginAction{actSendPing, actStartRetry}},
+		{name: "retry recovers unknown state", status: running + 1, event: loginRetryTimer,
+			wantStatus: connect, wantActions: []loginAction{actStartRetry}},
+
+		// Socket open
+		{name: "socket opened sends login", status: connect, event: loginSocketOpened,
+			wantStatus: login, wantActions: []loginAction{actSendLogin, actStartTimeout, actStartRetry}},
+		{name: "socket opened late is ignored", status: running, event: loginSocketOpened,
+			wantStatus: running},
+		{name: "socket open failure retries", status: connect, event: loginSocketFailed,
+			wantStatus: connect, wantActions: []loginAction{actStartRetry}},
+
+		// ACK
+		{name: "ack to login sends auth", status: login, event: loginACK,
+			wantStatus: auth, wantActions: []loginAction{actStoreSalt, actSendAuth}},
+		{name: "ack to auth sends config", status: auth, event: loginACK,
+			wantStatus: config, wantActions: []loginAction{actSendConfig}},
+		{name: "ack to config without options runs", status: config, event: loginACK,
+			wantStatus: running, wantActions: []loginAction{actStartTimeout}},
+		{name: "ack to config sends options", status: config, hasOptions: true, event: loginACK,
+			wantStatus: options, wantActions: []loginAction{actSendOptions}},
+		{name: "ack to config skips rejected options", status: config, hasOptions: true, quirks: optional, rejected: true, event: loginACK,
+			wantStatus: running, wantActions: []loginAction{actStartTimeout}, wantRejected: true},
+		{name: "ack to options runs", status: options, hasOptions: true, event: loginACK,
+			wantStatus: running, wantActions: []loginAction{actStartTimeout}},
+		{name: "ack while connecting is ignored", status: connect, event: loginACK,
+			wantStatus: connect},
+		{name: "ack while running is ignored", status: running, event: loginACK,
+			wantStatus: running},
+
+		// NAK
+		{name: "nak to login retries login", status: login, event: loginNAK,
+			wantStatus: login, wantActions: []loginAction{actStartRetry}},
+		{name: "nak to auth retries login", status: auth, event: loginNAK,
+			wantStatus: login, wantActions: []loginAction{actStartRetry}},
+		{name: "nak while running logs in again", status: running, event: loginNAK,
+			wantStatus: login, wantActions: []loginAction{actStartRetry}},
+		{name: "nak to options keeps options", status: options, hasOptions: true, event: loginNAK,
+			wantStatus: login, wantActions: []loginAction{actStartRetry}},
+		{name: "nak to optional options drops them", status: options, hasOptions: true, quirks: optional, event: loginNAK,
+			wantStatus: login, wantActions: []loginAction{actStartRetry}, wantRejected: true},
+		{name: "nak to config keeps optional options", status: config, hasOptions: true, quirks: optional, event: loginNAK,
+			wantStatus: login, wantActions: []loginAction{actStartRetry}},
+
+		// Keepalive and loss of connection
+		{name: "pong restarts timeout", status: running, event: loginPong,
+			wantStatus: running, wantActions: []loginAction{actStartTimeout}},
+		{name: "timeout reconnects", status: running, event: loginTimeout,
+			wantStatus: connect, wantActions: []loginAction{actStartRetry}},
+		{name: "timeout during login reconnects", status: auth, event: loginTimeout,
+			wantStatus: connect, wantActions: []loginAction{actStartRetry}},
+		{name: "socket error reconnects", status: config, event: loginSocketError,
+			wantStatus: connect, wantActions: []loginAction{actStartRetry}},
+		{name: "master close reconnects", status: running, event: loginMasterClosed,
+			wantStatus: connect, wantActions: []loginAction{actStartRetry}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &loginMachine{
+				status:          tt.status,
+				hasOptions:      tt.hasOptions,
+				quirks:          tt.quirks,
+				optionsRejected: tt.rejected,
+			}
+
+			actions := m.handle(tt.event)
+
+			if m.status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", m.status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(actions, tt.wantActions) {
+				t.Errorf("actions = %v, want %v", actions, tt.wantActions)
+			}
+			if m.optionsRejected != tt.wantRejected {
+				t.Errorf("optionsRejected = %v, want %v", m.optionsRejected, tt.wantRejected)
+			}
+		})
+	}
+}
+
+func TestLoginMachineFlows(t *testing.T) {
+	tests := []struct {
+		name       string
+		masterType string
+		hasOptions bool
+		events     []loginEvent
+		want       []protocol.DMRNetworkStatus // status after each event
+	}{
+		{
+			name:   "login without options",
+			events: []loginEvent{loginRetryTimer, loginSocketOpened, loginACK, loginACK, loginACK, loginRetryTimer, loginPong},
+			want: []protocol.DMRNetworkStatus{
+				protocol.DMR_WAITING_CONNECT, protocol.DMR_WAITING_LOGIN, protocol.DMR_WAITING_AUTHORISATION,
+				protocol.DMR_WAITING_CONFIG, protocol.DMR_RUNNING, protocol.DMR_RUNNING, protocol.DMR_RUNNING,
+			},
+		},
+		{
+			name:       "login with options",
+			hasOptions: true,
+			events:     []loginEvent{loginSocketOpened, loginACK, loginACK, loginACK, loginACK},
+			want: []protocol.DMRNetworkStatus{
+				protocol.DMR_WAITING_LOGIN, protocol.DMR_WAITING_AUTHORISATION, protocol.DMR_WAITING_CONFIG,
+				protocol.DMR_WAITING_OPTIONS, protocol.DMR_RUNNING,
+			},
+		},
+		{
+			name:       "brandmeister retries rejected options",
+			masterType: MasterTypeBrandmeister,
+			hasOptions: true,
+			events:     []loginEvent{loginSocketOpened, loginACK, loginACK, loginACK, loginNAK, loginACK, loginACK, loginACK},
+			want: []protocol.DMRNetworkStatus{
+				protocol.DMR_WAITING_LOGIN, protocol.DMR_WAITING_AUTHORISATION, protocol.DMR_WAITING_CONFIG,
+				protocol.DMR_WAITING_OPTIONS, protocol.DMR_WAITING_LOGIN, protocol.DMR_WAITING_AUTHORISATION,
+				protocol.DMR_WAITING_CONFIG, protocol.DMR_WAITING_OPTIONS,
+			},
+		},
+		{
+			name:       "tgif logs in without rejected options",
+			masterType: MasterTypeTGIF,
+			hasOptions: true,
+			events:     []loginEvent{loginSocketOpened, loginACK, loginACK, loginACK, loginNAK, loginACK, loginACK, loginACK},
+			want: []protocol.DMRNetworkStatus{
+				protocol.DMR_WAITING_LOGIN, protocol.DMR_WAITING_AUTHORISATION, protocol.DMR_WAITING_CONFIG,
+				protocol.DMR_WAITING_OPTIONS, protocol.DMR_WAITING_LOGIN, protocol.DMR_WAITING_AUTHORISATION,
+				protocol.DMR_WAITING_CONFIG, protocol.DMR_RUNNING,
+			},
+		},
+		{
+			name:       "freedmr logs in without rejected options",
+			masterType: MasterTypeFreeDMR,
+			hasOptions: true,
+			events:     []loginEvent{loginSocketOpened, loginACK, loginACK, loginACK, loginNAK, loginACK, loginACK, loginACK},
+			want: []protocol.DMRNetworkStatus{
+				protocol.DMR_WAITING_LOGIN, protocol.DMR_WAITING_AUTHORISATION, protocol.DMR_WAITING_CONFIG,
+				protocol.DMR_WAITING_OPTIONS, protocol.DMR_WAITING_LOGIN, protocol.DMR_WAITING_AUTHORISATION,
+				protocol.DMR_WAITING_CONFIG, protocol.DMR_RUNNING,
+			},
+		},
+		{
+			name:   "timeout after running reconnects",
+			events: []loginEvent{loginSocketOpened, loginACK, loginACK, loginACK, loginTimeout, loginRetryTimer, loginSocketFailed, loginRetryTimer, loginSocketOpened},
+			want: []protocol.DMRNetworkStatus{
+				protocol.DMR_WAITING_LOGIN, protocol.DMR_WAITING_AUTHORISATION, protocol.DMR_WAITING_CONFIG,
+				protocol.DMR_RUNNING, protocol.DMR_WAITING_CONNECT, protocol.DMR_WAITING_CONNECT,
+				protocol.DMR_WAITING_CONNECT, protocol.DMR_WAITING_CONNECT, protocol.DMR_WAITING_LOGIN,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &loginMachine{
+				status:     protocol.DMR_WAITING_CONNECT,
+				hasOptions: tt.hasOptions,
+				quirks:     quirksFor(tt.masterType),
+			}
+
+			for i, ev := range tt.events {
+				m.handle(ev)
+				if m.status != tt.want[i] {
+					t.Fatalf("event %d (%d): status = %d, want %d", i, ev, m.status, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDMRNetworkLoginSalt(t *testing.T) {
+	network, err := NewDMRNetwork("127.0.0.1", 62030, 4000, 123456, "test123",
+		true, "1.0.0", false, true, true, protocol.HW_TYPE_HOMEBREW, 120)
+	if err != nil {
+		t.Fatalf("Failed to create network: %v", err)
+	}
+
+	network.status = protocol.DMR_WAITING_LOGIN
+	network.handleRPTACK([]byte("RPTACK\x12\x34\x56\x78"))
+
+	if want := []byte{0x12, 0x34, 0x56, 0x78}; !reflect.DeepEqual(network.salt, want) {
+		t.Errorf("salt = %X, want %X", network.salt, want)
+	}
+	// The socket is not open, so sending the auth fails and the network reconnects
+	if network.status != protocol.DMR_WAITING_CONNECT {
+		t.Errorf("status = %d, want %d", network.status, protocol.DMR_WAITING_CONNECT)
+	}
+}
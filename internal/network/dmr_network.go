@@ -8,7 +8,9 @@ import (
 	"net"
 	"time"
 
+	"github.com/dbehnke/ysf2dmr/internal/capture"
 	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/trace"
 )
 
 // DMRNetwork provides DMR network communication equivalent to C++ CDMRNetwork
@@ -29,14 +31,20 @@ type DMRNetwork struct {
 
 	// Network components
 	socket       *UDPSocket
+	ready        chan struct{} // signalled when packets are waiting for Clock
 	buffer       []byte
 	delayBuffers [3]*DelayBuffer // Index 0 unused, slots 1 and 2
 
 	// State management
-	status       protocol.DMRNetworkStatus
+	loginMachine
 	retryTimer   *Timer
 	timeoutTimer *Timer
 	beacon       bool
+	masterReports // RPTRSSI and DMRA packets
+
+	// Last MSTPONG and DMRD received, both set at login
+	lastKeepalive time.Time
+	lastData      time.Time
 
 	// Authentication
 	salt []byte
@@ -44,6 +52,7 @@ type DMRNetwork struct {
 	// Stream management
 	streamId [3]uint32 // Index 0 unused, slots 1 and 2
 	seqNo    uint8
+	loops    loopGuard
 
 	// Configuration data
 	callsign     string
@@ -92,11 +101,12 @@ func NewDMRNetwork(address string, port int, localPort uint32, id uint32, passwo
 		enabled:   false,
 		socket:    NewUDPSocket("", bindPort), // Bind to specified local port
 		buffer:    make([]byte, 500),              // 500-byte receive buffer
-		status:    protocol.DMR_WAITING_CONNECT,
+		loginMachine: loginMachine{status: protocol.DMR_WAITING_CONNECT},
 		retryTimer: NewTimer(1000, 0, 0), // 1000 ticks per second
 		timeoutTimer: NewTimer(1000, 0, 0),
 		beacon:    false,
 		salt:      make([]byte, protocol.DMR_SALT_LENGTH),
+		loops:     loopGuard{name: "DMR"},
 	}
 
 	// Convert repeater ID to big-endian byte array
@@ -126,6 +136,9 @@ func NewDMRNetwork(address string, port int, localPort uint32, id uint32, passwo
 			address, port, id, localPort, duplex, slot1, slot2)
 	}
 
+	network.ready = make(chan struct{}, 1)
+	network.socket.Notify(network.ready)
+
 	return network, nil
 }
 
@@ -133,6 +146,34 @@ func NewDMRNetwork(address string, port int, localPort uint32, id uint32, passwo
 // Equivalent to C++ CDMRNetwork::setOptions()
 func (n *DMRNetwork) SetOptions(options string) {
 	n.options = options
+	n.hasOptions = options != ""
+}
+
+// SetMasterType selects the login adjustments for a master type (see MasterType*)
+func (n *DMRNetwork) SetMasterType(masterType string) {
+	n.quirks = quirksFor(masterType)
+}
+
+// SetBindAddress binds the socket to an IP address or interface name (see
+// BindIP) when next opened; empty binds any address
+func (n *DMRNetwork) SetBindAddress(address string) {
+	n.socket.address = address
+}
+
+// SetProxy relays the traffic with the master through a SOCKS5 proxy from
+// the next Open; nil sends directly
+func (n *DMRNetwork) SetProxy(p *SOCKS5Proxy) {
+	n.socket.SetProxy(p)
+}
+
+// SetCapture keeps the packets exchanged with the master in r
+func (n *DMRNetwork) SetCapture(r *capture.Ring) {
+	n.socket.SetCapture(r, "DMR")
+}
+
+// SetTrace records the packets exchanged with the master in t
+func (n *DMRNetwork) SetTrace(t *trace.Tracer) {
+	n.socket.SetTrace(t, "DMR")
 }
 
 // SetConfig sets the repeater configuration
@@ -246,15 +287,14 @@ func (n *DMRNetwork) Read(data *protocol.DMRData) bool {
 			continue
 		}
 
-		tempBuffer := make([]byte, protocol.HOMEBREW_DATA_PACKET_LENGTH)
+		pooled := protocol.GetBuffer()
+		tempBuffer := (*pooled)[:protocol.HOMEBREW_DATA_PACKET_LENGTH]
 		status := buffer.GetData(tempBuffer)
 
-		if status == protocol.BS_NO_DATA {
-			continue
-		}
-
 		// Parse DMRD packet
-		if !n.parseDMRDPacket(tempBuffer, data) {
+		parsed := status != protocol.BS_NO_DATA && n.parseDMRDPacket(tempBuffer, data)
+		protocol.PutBuffer(pooled)
+		if !parsed {
 			continue
 		}
 
@@ -302,8 +342,7 @@ func (n *DMRNetwork) Write(data *protocol.DMRData) error {
 		if n.debug {
 			log.Printf("DMR write error: %v", err)
 		}
-		n.status = protocol.DMR_WAITING_CONNECT
-		n.retryTimer.Start(protocol.DMR_RETRY_TIMEOUT/1000, protocol.DMR_RETRY_TIMEOUT%1000)
+		n.runLogin(loginSocketError, nil)
 		return err
 	}
 
@@ -383,6 +422,22 @@ func (n *DMRNetwork) WantsBeacon() bool {
 	return beacon
 }
 
+// Ready is signalled when received packets are waiting for Clock
+func (n *DMRNetwork) Ready() <-chan struct{} {
+	return n.ready
+}
+
+// LoopsDetected returns the number of our own frames echoed back by the master
+func (n *DMRNetwork) LoopsDetected() uint64 {
+	return n.loops.detected
+}
+
+// LastReceived returns when the master last answered a ping and last sent
+// a DMRD packet; both count from the login
+func (n *DMRNetwork) LastReceived() (keepalive, data time.Time) {
+	return n.lastKeepalive, n.lastData
+}
+
 // Reset resets the delay buffer for a specific slot
 // Equivalent to C++ CDMRNetwork::reset()
 func (n *DMRNetwork) Reset(slotNo uint8) {
@@ -412,12 +467,15 @@ func (n *DMRNetwork) Clock(ms int) {
 	// Handle timer events
 	if n.retryTimer.HasExpired() {
 		n.retryTimer.Stop()
-		n.handleRetryTimeout()
+		n.runLogin(loginRetryTimer, nil)
 	}
 
 	if n.timeoutTimer.HasExpired() {
 		n.timeoutTimer.Stop()
-		n.handleConnectionTimeout()
+		if n.debug {
+			log.Printf("DMR: Connection timeout")
+		}
+		n.runLogin(loginTimeout, nil)
 	}
 
 	// Process incoming packets
@@ -441,8 +499,7 @@ func (n *DMRNetwork) processIncomingPackets() {
 			if err.Error() == "socket not open" {
 				return
 			}
-			n.status = protocol.DMR_WAITING_CONNECT
-			n.retryTimer.Start(protocol.DMR_RETRY_TIMEOUT/1000, protocol.DMR_RETRY_TIMEOUT%1000)
+			n.runLogin(loginSocketError, nil)
 			return
 		}
 
@@ -0,0 +1,63 @@
+package network
+
+import (
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/capture"
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/trace"
+)
+
+// DMRNetworkInterface defines the DMR network operations used by the gateway
+// This interface is implemented by the Homebrew (MMDVM), OpenBridge and
+// DMRGateway networks
+type DMRNetworkInterface interface {
+	// Lifecycle management
+	Open() error         // Open the network connection
+	Close()              // Close the network connection
+	Enable(enabled bool) // Enable or disable data reception
+
+	// Data transfer
+	Read(data *protocol.DMRData) bool   // Read the next DMR frame, false if none available
+	Write(data *protocol.DMRData) error // Write a DMR frame to the network
+
+	// Timing
+	Clock(ms int) // Process timers and incoming packets
+
+	// Status
+	IsConnected() bool       // Check if the network is ready to carry traffic
+	GetStatusString() string // Get the current connection state for logging
+	WantsBeacon() bool       // Check and clear a pending beacon request
+}
+
+// PacketNotifier is implemented by networks that signal when received
+// packets are waiting for Clock, so they can be handled as they arrive
+type PacketNotifier interface {
+	Ready() <-chan struct{}
+}
+
+// PacketTracer is implemented by networks that can record their packets in
+// a trace
+type PacketTracer interface {
+	SetTrace(t *trace.Tracer) // nil stops tracing
+}
+
+// PacketCapturer is implemented by networks that can keep their recent
+// packets for a capture dump
+type PacketCapturer interface {
+	SetCapture(r *capture.Ring) // nil stops capturing
+}
+
+// LinkActivity is implemented by networks that record when they last
+// received a keepalive reply and a data packet, for health scoring
+// Called from the goroutine that clocks the network.
+type LinkActivity interface {
+	LastReceived() (keepalive, data time.Time) // zero until first received
+}
+
+// Supported DMR network protocols (DMR Network Protocol= key)
+const (
+	DMRProtocolHomebrew   = "homebrew"
+	DMRProtocolOpenBridge = "openbridge"
+	DMRProtocolDMRGateway = "dmrgateway"
+)
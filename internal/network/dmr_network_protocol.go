@@ -8,6 +8,7 @@ import (
 	"log"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/dbehnke/ysf2dmr/internal/protocol"
 )
@@ -18,24 +19,31 @@ func (n *DMRNetwork) processPacket(packet []byte) {
 		return // Invalid packet
 	}
 
-	magic := string(packet[:4])
+	// The master's tags are 4 to 7 bytes long
+	hasMagic := func(magic string) bool {
+		return bytes.HasPrefix(packet, []byte(magic))
+	}
 
-	switch magic {
-	case protocol.NETWORK_MAGIC_ACK:
+	switch {
+	case hasMagic(protocol.NETWORK_MAGIC_ACK):
 		n.handleRPTACK(packet)
-	case protocol.NETWORK_MAGIC_NAK:
+	case hasMagic(protocol.NETWORK_MAGIC_NAK):
 		n.handleMSTNAK(packet)
-	case protocol.NETWORK_MAGIC_PONG:
+	case hasMagic(protocol.NETWORK_MAGIC_PONG):
 		n.handleMSTPONG(packet)
-	case protocol.NETWORK_MAGIC_CLOSE_MASTER:
+	case hasMagic(protocol.NETWORK_MAGIC_CLOSE_MASTER):
 		n.handleMSTCL(packet)
-	case protocol.NETWORK_MAGIC_BEACON:
+	case hasMagic(protocol.NETWORK_MAGIC_BEACON):
 		n.handleBeacon(packet)
-	case protocol.NETWORK_MAGIC_DATA:
+	case hasMagic(protocol.NETWORK_MAGIC_RSSI):
+		n.handleRPTRSSI(packet)
+	case hasMagic(protocol.NETWORK_MAGIC_TALKERALIAS):
+		n.handleDMRA(packet)
+	case hasMagic(protocol.NETWORK_MAGIC_DATA):
 		n.handleDMRD(packet)
 	default:
 		if n.debug {
-			log.Printf("DMR: Unknown packet type: %s (%d bytes)", magic, len(packet))
+			log.Printf("DMR: Unknown packet type: %s (%d bytes)", packet[:4], len(packet))
 		}
 	}
 }
@@ -46,50 +54,7 @@ func (n *DMRNetwork) handleRPTACK(packet []byte) {
 		log.Printf("DMR: Received RPTACK in state %d", n.status)
 	}
 
-	switch n.status {
-	case protocol.DMR_WAITING_LOGIN:
-		// Extract salt from packet
-		if len(packet) >= 10 {
-			copy(n.salt, packet[6:10])
-			if n.debug {
-				log.Printf("DMR: Received salt: %02X %02X %02X %02X",
-					n.salt[0], n.salt[1], n.salt[2], n.salt[3])
-			}
-		}
-		// Send authorization
-		n.writeAuth()
-		n.status = protocol.DMR_WAITING_AUTHORISATION
-
-	case protocol.DMR_WAITING_AUTHORISATION:
-		// Send configuration
-		n.writeConfig()
-		n.status = protocol.DMR_WAITING_CONFIG
-
-	case protocol.DMR_WAITING_CONFIG:
-		if len(n.options) > 0 {
-			// Send options
-			n.writeOptions()
-			n.status = protocol.DMR_WAITING_OPTIONS
-		} else {
-			// Connected
-			n.status = protocol.DMR_RUNNING
-			n.timeoutTimer.Start(protocol.DMR_CONNECTION_TIMEOUT/1000, protocol.DMR_CONNECTION_TIMEOUT%1000)
-			if n.debug {
-				log.Printf("DMR: Connected and running")
-			}
-		}
-
-	case protocol.DMR_WAITING_OPTIONS:
-		// Connected
-		n.status = protocol.DMR_RUNNING
-		n.timeoutTimer.Start(protocol.DMR_CONNECTION_TIMEOUT/1000, protocol.DMR_CONNECTION_TIMEOUT%1000)
-		if n.debug {
-			log.Printf("DMR: Connected and running")
-		}
-
-	default:
-		// Ignore RPTACK in other states
-	}
+	n.runLogin(loginACK, packet)
 }
 
 // handleMSTNAK processes MSTNAK negative acknowledgement packets
@@ -98,9 +63,11 @@ func (n *DMRNetwork) handleMSTNAK(packet []byte) {
 		log.Printf("DMR: Received MSTNAK - authentication failed")
 	}
 
-	// Reset to login state
-	n.status = protocol.DMR_WAITING_LOGIN
-	n.retryTimer.Start(protocol.DMR_RETRY_TIMEOUT/1000, protocol.DMR_RETRY_TIMEOUT%1000)
+	rejected := n.optionsRejected
+	n.runLogin(loginNAK, packet)
+	if n.optionsRejected && !rejected {
+		log.Printf("DMR: Master rejected options %q, logging in without them", n.options)
+	}
 }
 
 // handleMSTPONG processes MSTPONG ping response packets
@@ -109,8 +76,8 @@ func (n *DMRNetwork) handleMSTPONG(packet []byte) {
 		log.Printf("DMR: Received MSTPONG")
 	}
 
-	// Restart timeout timer
-	n.timeoutTimer.Start(protocol.DMR_CONNECTION_TIMEOUT/1000, protocol.DMR_CONNECTION_TIMEOUT%1000)
+	n.lastKeepalive = time.Now()
+	n.runLogin(loginPong, packet)
 }
 
 // handleMSTCL processes master close packets
@@ -119,9 +86,7 @@ func (n *DMRNetwork) handleMSTCL(packet []byte) {
 		log.Printf("DMR: Received MSTCL - master closing")
 	}
 
-	// Server is shutting down
-	n.status = protocol.DMR_WAITING_CONNECT
-	n.retryTimer.Start(protocol.DMR_RETRY_TIMEOUT/1000, protocol.DMR_RETRY_TIMEOUT%1000)
+	n.runLogin(loginMasterClosed, packet)
 }
 
 // handleBeacon processes beacon request packets
@@ -133,9 +98,33 @@ func (n *DMRNetwork) handleBeacon(packet []byte) {
 	n.beacon = true
 }
 
+// handleRPTRSSI processes signal strength reports
+func (n *DMRNetwork) handleRPTRSSI(packet []byte) {
+	slotNo, dBm, ok := n.handleRSSI(packet)
+	if n.debug {
+		if ok {
+			log.Printf("DMR: Received RSSI %d dBm on slot %d", dBm, slotNo)
+		} else {
+			log.Printf("DMR: Invalid RPTRSSI packet (%d bytes)", len(packet))
+		}
+	}
+}
+
+// handleDMRA processes talker alias packets
+func (n *DMRNetwork) handleDMRA(packet []byte) {
+	srcID, alias, complete := n.handleTalkerAlias(packet)
+	if n.debug && complete {
+		log.Printf("DMR: Received talker alias %q for %d", alias, srcID)
+	}
+}
+
 // handleDMRD processes DMRD data packets
 func (n *DMRNetwork) handleDMRD(packet []byte) {
-	if !n.enabled || len(packet) != protocol.HOMEBREW_DATA_PACKET_LENGTH {
+	if len(packet) != protocol.HOMEBREW_DATA_PACKET_LENGTH {
+		return
+	}
+	n.lastData = time.Now()
+	if !n.enabled {
 		return
 	}
 
@@ -150,6 +139,11 @@ func (n *DMRNetwork) handleDMRD(packet []byte) {
 		return
 	}
 
+	// Our own traffic echoed back would be bridged again
+	if n.loops.looped(packet) {
+		return
+	}
+
 	// Add to delay buffer
 	if n.delayBuffers[slotNo] != nil {
 		seqNo := packet[4] // Sequence number
@@ -157,61 +151,61 @@ func (n *DMRNetwork) handleDMRD(packet []byte) {
 	}
 }
 
-// handleRetryTimeout handles retry timer expiration
-func (n *DMRNetwork) handleRetryTimeout() {
-	switch n.status {
-	case protocol.DMR_WAITING_CONNECT:
-		// C++ behavior: open socket first, then login if successful
-		err := n.socket.Open()
-		if err != nil {
+// runLogin feeds an event to the login state machine and carries out the
+// resulting actions; packet is the received packet, if any
+func (n *DMRNetwork) runLogin(ev loginEvent, packet []byte) {
+	running := n.status == protocol.DMR_RUNNING
+
+	for _, action := range n.handle(ev) {
+		switch action {
+		case actOpenSocket:
+			// C++ behavior: open socket first, then login if successful
+			if err := n.socket.Open(); err != nil {
+				if n.debug {
+					log.Printf("DMR: Socket open failed: %v", err)
+				}
+				n.runLogin(loginSocketFailed, nil)
+				continue
+			}
 			if n.debug {
-				log.Printf("DMR: Socket open failed: %v", err)
+				log.Printf("DMR: Socket opened, sending login packet")
+			}
+			n.runLogin(loginSocketOpened, nil)
+
+		case actStoreSalt:
+			if len(packet) >= 10 {
+				copy(n.salt, packet[6:10])
+				if n.debug {
+					log.Printf("DMR: Received salt: %02X %02X %02X %02X",
+						n.salt[0], n.salt[1], n.salt[2], n.salt[3])
+				}
 			}
-			// Don't change state, will retry on next timer expiration
-			return
-		}
-
-		if n.debug {
-			log.Printf("DMR: Socket opened, sending login packet")
-		}
-
-		n.writeLogin()
-		n.status = protocol.DMR_WAITING_LOGIN
-		n.timeoutTimer.Start(protocol.DMR_CONNECTION_TIMEOUT/1000, protocol.DMR_CONNECTION_TIMEOUT%1000)
-
-	case protocol.DMR_WAITING_LOGIN:
-		n.writeLogin()
-
-	case protocol.DMR_WAITING_AUTHORISATION:
-		n.writeAuth()
-
-	case protocol.DMR_WAITING_CONFIG:
-		n.writeConfig()
-
-	case protocol.DMR_WAITING_OPTIONS:
-		n.writeOptions()
 
-	case protocol.DMR_RUNNING:
-		n.writePing()
+		case actSendLogin:
+			n.writeLogin()
+		case actSendAuth:
+			n.writeAuth()
+		case actSendConfig:
+			n.writeConfig()
+		case actSendOptions:
+			n.writeOptions()
+		case actSendPing:
+			n.writePing()
 
-	default:
-		// Unknown state
-		n.status = protocol.DMR_WAITING_CONNECT
+		case actStartRetry:
+			n.retryTimer.Start(protocol.DMR_RETRY_TIMEOUT/1000, protocol.DMR_RETRY_TIMEOUT%1000)
+		case actStartTimeout:
+			n.timeoutTimer.Start(protocol.DMR_CONNECTION_TIMEOUT/1000, protocol.DMR_CONNECTION_TIMEOUT%1000)
+		}
 	}
 
-	// Restart retry timer
-	n.retryTimer.Start(protocol.DMR_RETRY_TIMEOUT/1000, protocol.DMR_RETRY_TIMEOUT%1000)
-}
-
-// handleConnectionTimeout handles connection timeout
-func (n *DMRNetwork) handleConnectionTimeout() {
-	if n.debug {
-		log.Printf("DMR: Connection timeout")
+	if !running && n.status == protocol.DMR_RUNNING {
+		now := time.Now()
+		n.lastKeepalive, n.lastData = now, now
+		if n.debug {
+			log.Printf("DMR: Connected and running")
+		}
 	}
-
-	// Connection lost, reconnect
-	n.status = protocol.DMR_WAITING_CONNECT
-	n.retryTimer.Start(protocol.DMR_RETRY_TIMEOUT/1000, protocol.DMR_RETRY_TIMEOUT%1000)
 }
 
 // writeLogin sends login packet (RPTL)
@@ -224,7 +218,6 @@ func (n *DMRNetwork) writeLogin() {
 
 	if n.debug {
 		log.Printf("DMR: Sent login packet to %s:%d", n.address.String(), n.port)
-		log.Printf("DMR: Login packet (hex): %X", packet)
 	}
 }
 
@@ -250,14 +243,67 @@ func (n *DMRNetwork) writeAuth() {
 
 // writeConfig sends configuration packet (RPTC)
 func (n *DMRNetwork) writeConfig() {
+	slots := byte('0')
+	if n.slot1 && n.slot2 {
+		slots = '3' // Both slots
+	} else if n.slot1 {
+		slots = '1' // Slot 1 only
+	} else if n.slot2 {
+		slots = '2' // Slot 2 only
+	}
+
+	n.writePacket(encodeConfigPacket(protocol.NETWORK_MAGIC_CONFIG, n.id, repeaterConfig{
+		callsign:    n.callsign,
+		rxFrequency: n.rxFrequency,
+		txFrequency: n.txFrequency,
+		power:       n.power,
+		colorCode:   n.colorCode,
+		latitude:    n.latitude,
+		longitude:   n.longitude,
+		height:      n.height,
+		location:    n.location,
+		description: n.description,
+		slots:       slots,
+		url:         n.url,
+		version:     n.version,
+		software:    n.hwType.String(),
+	}))
+
+	if n.debug {
+		log.Printf("DMR: Sent config packet")
+	}
+}
+
+// repeaterConfig is the repeater description sent in a config packet
+type repeaterConfig struct {
+	callsign    string
+	rxFrequency uint32
+	txFrequency uint32
+	power       uint32
+	colorCode   uint32
+	latitude    float32
+	longitude   float32
+	height      int
+	location    string
+	description string
+	slots       byte // '1', '2' or '3' for both
+	url         string
+	version     string
+	software    string
+}
+
+// encodeConfigPacket builds a config packet with the given magic
+// Shared by the Homebrew login (RPTC) and the DMRGateway connection (DMRC),
+// which use the same layout.
+func encodeConfigPacket(magic string, id [4]byte, c repeaterConfig) []byte {
 	packet := make([]byte, protocol.NETWORK_CONFIG_LENGTH)
 
 	// Magic and ID
-	copy(packet[0:4], protocol.NETWORK_MAGIC_CONFIG)
-	copy(packet[4:8], n.id[:])
+	copy(packet[0:4], magic)
+	copy(packet[4:8], id[:])
 
 	// Callsign (8 bytes, left-aligned with right padding, matching C++ %-8.8s)
-	callsign := strings.ToUpper(n.callsign)
+	callsign := strings.ToUpper(c.callsign)
 	if len(callsign) > 8 {
 		callsign = callsign[:8]
 	}
@@ -270,88 +316,76 @@ func (n *DMRNetwork) writeConfig() {
 	copy(packet[8:16], callsignBytes)
 
 	// Frequencies (9 bytes each, zero-padded)
-	rxFreqStr := fmt.Sprintf("%09d", n.rxFrequency)
-	txFreqStr := fmt.Sprintf("%09d", n.txFrequency)
+	rxFreqStr := fmt.Sprintf("%09d", c.rxFrequency)
+	txFreqStr := fmt.Sprintf("%09d", c.txFrequency)
 	copy(packet[16:25], rxFreqStr)
 	copy(packet[25:34], txFreqStr)
 
 	// Power (2 bytes, zero-padded)
-	powerStr := fmt.Sprintf("%02d", n.power)
+	powerStr := fmt.Sprintf("%02d", c.power)
 	copy(packet[34:36], powerStr)
 
 	// Color Code (2 bytes, zero-padded)
-	ccStr := fmt.Sprintf("%02d", n.colorCode)
+	ccStr := fmt.Sprintf("%02d", c.colorCode)
 	copy(packet[36:38], ccStr)
 
 	// Latitude (8 bytes) - match C++ %08f then truncate to 8 chars
-	latStr := fmt.Sprintf("%08f", n.latitude)
+	latStr := fmt.Sprintf("%08f", c.latitude)
 	if len(latStr) > 8 {
 		latStr = latStr[:8]
 	}
 	copy(packet[38:46], latStr)
 
 	// Longitude (9 bytes) - match C++ %09f then truncate to 9 chars
-	lngStr := fmt.Sprintf("%09f", n.longitude)
+	lngStr := fmt.Sprintf("%09f", c.longitude)
 	if len(lngStr) > 9 {
 		lngStr = lngStr[:9]
 	}
 	copy(packet[46:55], lngStr)
 
 	// Height (3 bytes)
-	heightStr := fmt.Sprintf("%03d", n.height)
+	heightStr := fmt.Sprintf("%03d", c.height)
 	copy(packet[55:58], heightStr)
 
 	// Location (20 bytes)
-	location := n.location
+	location := c.location
 	if len(location) > 20 {
 		location = location[:20]
 	}
 	copy(packet[58:78], location)
 
 	// Description (19 bytes)
-	description := n.description
+	description := c.description
 	if len(description) > 19 {
 		description = description[:19]
 	}
 	copy(packet[78:97], description)
 
 	// Slots configuration
-	slotConfig := byte('0')
-	if n.slot1 && n.slot2 {
-		slotConfig = '3' // Both slots
-	} else if n.slot1 {
-		slotConfig = '1' // Slot 1 only
-	} else if n.slot2 {
-		slotConfig = '2' // Slot 2 only
-	}
-	packet[97] = slotConfig
+	packet[97] = c.slots
 
 	// URL (124 bytes)
-	url := n.url
+	url := c.url
 	if len(url) > 124 {
 		url = url[:124]
 	}
 	copy(packet[98:222], url)
 
 	// Version (40 bytes)
-	version := n.version
+	version := c.version
 	if len(version) > 40 {
 		version = version[:40]
 	}
 	copy(packet[222:262], version)
 
 	// Software type (40 bytes)
-	hwTypeStr := n.hwType.String()
-	if len(hwTypeStr) > 40 {
-		hwTypeStr = hwTypeStr[:40]
+	software := c.software
+	if len(software) > 40 {
+		software = software[:40]
 	}
-	copy(packet[262:302], hwTypeStr)
-
-	n.writePacket(packet)
+	copy(packet[262:302], software)
 
-	if n.debug {
-		log.Printf("DMR: Sent config packet")
-	}
+	return packet
 }
 
 // writeOptions sends options packet (RPTO)
@@ -408,21 +442,30 @@ func (n *DMRNetwork) writePacket(packet []byte) {
 			log.Printf("DMR: Write error: %v", err)
 		}
 		// Trigger reconnection
-		n.status = protocol.DMR_WAITING_CONNECT
-		n.retryTimer.Start(protocol.DMR_RETRY_TIMEOUT/1000, protocol.DMR_RETRY_TIMEOUT%1000)
+		n.runLogin(loginSocketError, nil)
 	}
 }
 
 // buildDMRDPacket builds a DMRD data packet
 func (n *DMRNetwork) buildDMRDPacket(data *protocol.DMRData) []byte {
+	packet := encodeDMRDPacket(data, n.seqNo, n.id, n.streamId[data.GetSlotNo()])
+	n.seqNo++
+	n.loops.sent(n.streamId[data.GetSlotNo()], data.GetSrcId())
+
+	return packet
+}
+
+// encodeDMRDPacket builds a DMRD data packet for the given repeater ID and stream.
+// Shared by the Homebrew and OpenBridge implementations, which use the same
+// 53-byte layout ahead of their protocol-specific trailer.
+func encodeDMRDPacket(data *protocol.DMRData, seqNo uint8, id [4]byte, streamId uint32) []byte {
 	packet := make([]byte, protocol.HOMEBREW_DATA_PACKET_LENGTH)
 
 	// Magic
 	copy(packet[0:4], protocol.NETWORK_MAGIC_DATA)
 
-	// Sequence number (increment per packet)
-	packet[4] = n.seqNo
-	n.seqNo++
+	// Sequence number
+	packet[4] = seqNo
 
 	// Source ID (3 bytes, big-endian)
 	srcId := data.GetSrcId()
@@ -437,7 +480,7 @@ func (n *DMRNetwork) buildDMRDPacket(data *protocol.DMRData) []byte {
 	packet[10] = byte(dstId)
 
 	// Repeater ID
-	copy(packet[11:15], n.id[:])
+	copy(packet[11:15], id[:])
 
 	// Build flags byte
 	flags := byte(0)
@@ -463,8 +506,7 @@ func (n *DMRNetwork) buildDMRDPacket(data *protocol.DMRData) []byte {
 	}
 	packet[15] = flags
 
-	// Stream ID (use per-slot stream ID)
-	streamId := n.streamId[slotNo]
+	// Stream ID
 	binary.BigEndian.PutUint32(packet[16:20], streamId)
 
 	// DMR data (33 bytes)
@@ -480,6 +522,11 @@ func (n *DMRNetwork) buildDMRDPacket(data *protocol.DMRData) []byte {
 
 // parseDMRDPacket parses a DMRD packet into DMRData
 func (n *DMRNetwork) parseDMRDPacket(packet []byte, data *protocol.DMRData) bool {
+	return decodeDMRDPacket(packet, data)
+}
+
+// decodeDMRDPacket parses a 55-byte DMRD packet into DMRData
+func decodeDMRDPacket(packet []byte, data *protocol.DMRData) bool {
 	if len(packet) != protocol.HOMEBREW_DATA_PACKET_LENGTH {
 		return false
 	}
@@ -286,6 +286,31 @@ func TestDMRNetworkBeacon(t *testing.T) {
 	}
 }
 
+func TestDMRNetworkLastReceived(t *testing.T) {
+	network, err := NewDMRNetwork("127.0.0.1", 62030, 4000, 123456, "test123",
+		true, "1.0.0", false, true, true, protocol.HW_TYPE_HOMEBREW, 120)
+	if err != nil {
+		t.Fatalf("Failed to create network: %v", err)
+	}
+
+	if keepalive, data := network.LastReceived(); !keepalive.IsZero() || !data.IsZero() {
+		t.Fatalf("LastReceived() = %v, %v before any packet", keepalive, data)
+	}
+
+	network.handleMSTPONG([]byte(protocol.NETWORK_MAGIC_PONG))
+	if keepalive, data := network.LastReceived(); keepalive.IsZero() || !data.IsZero() {
+		t.Errorf("LastReceived() = %v, %v after MSTPONG", keepalive, data)
+	}
+
+	// Data counts even while reception is disabled
+	packet := make([]byte, protocol.HOMEBREW_DATA_PACKET_LENGTH)
+	copy(packet, protocol.NETWORK_MAGIC_DATA)
+	network.handleDMRD(packet)
+	if _, data := network.LastReceived(); data.IsZero() {
+		t.Error("DMRD packet not recorded")
+	}
+}
+
 func TestBuildDMRDPacket(t *testing.T) {
 	network, err := NewDMRNetwork("127.0.0.1", 62030, 4000, 123456, "test123",
 		true, "1.0.0", false, true, true, protocol.HW_TYPE_HOMEBREW, 120)
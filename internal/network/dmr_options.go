@@ -0,0 +1,160 @@
+package network
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Master types whose options string can be built from structured settings
+// (DMR Network MasterType= key)
+const (
+	MasterTypeBrandmeister = "brandmeister"
+	MasterTypeXLX          = "xlx"
+	MasterTypeTGIF         = "tgif"
+	MasterTypeFreeDMR      = "freedmr"
+)
+
+// masterQuirks adjusts the Homebrew login for masters that do not behave
+// exactly like a BrandMeister/MMDVMHost master
+type masterQuirks struct {
+	// A MSTNAK while waiting for the options ACK rejects only the options;
+	// the login is retried without them instead of failing forever
+	optionalOptions bool
+}
+
+// quirksFor returns the login adjustments for a master type
+func quirksFor(masterType string) masterQuirks {
+	switch strings.ToLower(masterType) {
+	case MasterTypeTGIF, MasterTypeFreeDMR:
+		return masterQuirks{optionalOptions: true}
+	default:
+		return masterQuirks{}
+	}
+}
+
+// MasterOptions holds the settings sent to the master in the RPTO options packet
+type MasterOptions struct {
+	StaticTGs [2][]uint32 // static talkgroups for TS1 and TS2
+	Dial      uint32      // dial-a-TG reflector, 0 for none
+	Voice     bool        // voice announcements
+	Lang      string      // announcement language, e.g. en_GB
+	Timer     uint32      // user activated talkgroup timeout in minutes, 0 for the master default
+	Single    bool        // single mode: one dynamic talkgroup at a time
+	XLXModule string      // XLX startup module letter
+}
+
+// ParseStaticTGs parses a comma separated list of talkgroups
+// Each entry is a TG, which is static on TS2, or "slot:TG" for either slot,
+// e.g. "91,3100,1:262".
+func ParseStaticTGs(list string) ([2][]uint32, error) {
+	var tgs [2][]uint32
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		slot, tg := "2", entry
+		if i := strings.IndexByte(entry, ':'); i >= 0 {
+			slot, tg = strings.TrimSpace(entry[:i]), strings.TrimSpace(entry[i+1:])
+		}
+		if slot != "1" && slot != "2" {
+			return tgs, fmt.Errorf("invalid slot in static TG %q", entry)
+		}
+
+		id, err := strconv.ParseUint(tg, 10, 32)
+		if err != nil || id == 0 || id > 0xFFFFFF {
+			return tgs, fmt.Errorf("invalid static TG %q", entry)
+		}
+
+		s := slot[0] - '1'
+		tgs[s] = append(tgs[s], uint32(id))
+	}
+	return tgs, nil
+}
+
+// BuildOptions formats the options string expected by a master type
+//
+//	brandmeister: TS1=<tg>,<tg>;TS2=<tg>
+//	tgif:         TS1=...;TS2=...;DIAL=<tg>;VOICE=<0|1>;LANG=<lang>;TIMER=<min>
+//	freedmr:      as tgif plus SINGLE=<0|1>
+//	xlx:          StartRef=<4001+module>;RelinkTime=<min>;UserLink=1;TS1_1=<tg>;TS2_1=<tg>
+//
+// Settings a master type does not support are left out.
+func BuildOptions(masterType string, o MasterOptions) (string, error) {
+	var fields []string
+	add := func(key string, value interface{}) {
+		fields = append(fields, fmt.Sprintf("%s=%v", key, value))
+	}
+
+	masterType = strings.ToLower(masterType)
+	switch masterType {
+	case MasterTypeBrandmeister:
+		for i, tgs := range o.StaticTGs {
+			if len(tgs) > 0 {
+				add(fmt.Sprintf("TS%d", i+1), joinTGs(tgs))
+			}
+		}
+
+	case MasterTypeTGIF, MasterTypeFreeDMR:
+		for i, tgs := range o.StaticTGs {
+			if len(tgs) > 0 {
+				add(fmt.Sprintf("TS%d", i+1), joinTGs(tgs))
+			}
+		}
+		if o.Dial != 0 {
+			add("DIAL", o.Dial)
+		}
+		add("VOICE", boolOption(o.Voice))
+		if o.Lang != "" {
+			add("LANG", o.Lang)
+		}
+		if o.Timer != 0 {
+			add("TIMER", o.Timer)
+		}
+		if masterType == MasterTypeFreeDMR {
+			add("SINGLE", boolOption(o.Single))
+		}
+
+	case MasterTypeXLX:
+		module := strings.ToUpper(strings.TrimSpace(o.XLXModule))
+		if module != "" {
+			if len(module) != 1 || module[0] < 'A' || module[0] > 'Z' {
+				return "", fmt.Errorf("invalid XLX module %q", o.XLXModule)
+			}
+			add("StartRef", 4001+int(module[0]-'A'))
+		}
+		if o.Timer != 0 {
+			add("RelinkTime", o.Timer)
+		}
+		add("UserLink", 1)
+		for i, tgs := range o.StaticTGs {
+			for j, tg := range tgs {
+				add(fmt.Sprintf("TS%d_%d", i+1, j+1), tg)
+			}
+		}
+
+	default:
+		return "", fmt.Errorf("unknown DMR master type: %s", masterType)
+	}
+
+	return strings.Join(fields, ";"), nil
+}
+
+// joinTGs formats talkgroups as a comma separated list
+func joinTGs(tgs []uint32) string {
+	parts := make([]string, len(tgs))
+	for i, tg := range tgs {
+		parts[i] = strconv.FormatUint(uint64(tg), 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+// boolOption formats a flag as 0 or 1
+func boolOption(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
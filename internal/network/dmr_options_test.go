@@ -0,0 +1,70 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStaticTGs(t *testing.T) {
+	tgs, err := ParseStaticTGs(" 91, 3100 ,1:262,2:9990,")
+	if err != nil {
+		t.Fatalf("ParseStaticTGs() error = %v", err)
+	}
+	want := [2][]uint32{{262}, {91, 3100, 9990}}
+	if !reflect.DeepEqual(tgs, want) {
+		t.Errorf("ParseStaticTGs() = %v, want %v", tgs, want)
+	}
+
+	for _, bad := range []string{"3:91", "TG91", "0", "1:", "16777216"} {
+		if _, err := ParseStaticTGs(bad); err == nil {
+			t.Errorf("ParseStaticTGs(%q) expected error", bad)
+		}
+	}
+}
+
+func TestBuildOptions(t *testing.T) {
+	opts := MasterOptions{
+		StaticTGs: [2][]uint32{{262}, {91, 3100}},
+		Dial:      9,
+		Voice:     true,
+		Lang:      "en_GB",
+		Timer:     15,
+		XLXModule: "d",
+	}
+
+	tests := []struct {
+		masterType string
+		want       string
+	}{
+		{MasterTypeBrandmeister, "TS1=262;TS2=91,3100"},
+		{"TGIF", "TS1=262;TS2=91,3100;DIAL=9;VOICE=1;LANG=en_GB;TIMER=15"},
+		{MasterTypeXLX, "StartRef=4004;RelinkTime=15;UserLink=1;TS1_1=262;TS2_1=91;TS2_2=3100"},
+	}
+
+	for _, tt := range tests {
+		got, err := BuildOptions(tt.masterType, opts)
+		if err != nil {
+			t.Errorf("BuildOptions(%s) error = %v", tt.masterType, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("BuildOptions(%s) = %q, want %q", tt.masterType, got, tt.want)
+		}
+	}
+
+	opts.Single = true
+	want := "TS1=262;TS2=91,3100;DIAL=9;VOICE=1;LANG=en_GB;TIMER=15;SINGLE=1"
+	if got, _ := BuildOptions(MasterTypeFreeDMR, opts); got != want {
+		t.Errorf("BuildOptions(freedmr) = %q, want %q", got, want)
+	}
+
+	if got, _ := BuildOptions(MasterTypeBrandmeister, MasterOptions{}); got != "" {
+		t.Errorf("BuildOptions(empty) = %q, want empty", got)
+	}
+	if _, err := BuildOptions(MasterTypeXLX, MasterOptions{XLXModule: "AB"}); err == nil {
+		t.Error("expected error for invalid XLX module")
+	}
+	if _, err := BuildOptions("dmrplus", opts); err == nil {
+		t.Error("expected error for unknown master type")
+	}
+}
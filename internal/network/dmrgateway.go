@@ -0,0 +1,444 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/capture"
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/trace"
+)
+
+// dmrGatewayPingTime is the interval between DMRP keepalives, in seconds
+const dmrGatewayPingTime = 5
+
+// dmrGatewayTGRange is the span of talk groups a DMRGateway network is
+// rewritten into, e.g. TGRewrite=2,2000001,2,1,999999
+const dmrGatewayTGRange = 1000000
+
+// DMRGatewayNetwork connects to a local DMRGateway as its repeater, the way
+// MMDVMHost does with Type=Gateway
+// There is no login: the repeater announces itself with a DMRC config packet
+// and DMRO options, then both sides exchange DMRP keepalives and DMRD data.
+// DMRGateway routes the traffic to its masters, so the gateway can share the
+// networks of a hotspot on the same host.
+type DMRGatewayNetwork struct {
+	// Network configuration
+	address net.IP
+	port    int
+	id      [4]byte // 4-byte repeater ID (big-endian)
+	debug   bool
+	enabled bool
+
+	// Network components
+	socket       *UDPSocket
+	ready        chan struct{} // signalled when packets are waiting for Clock
+	buffer       []byte
+	delayBuffers [3]*DelayBuffer // Index 0 unused, slots 1 and 2
+	pingTimer    *Timer
+
+	// State management
+	open          bool
+	lastKeepalive time.Time
+	lastData      time.Time
+
+	// Stream management
+	streamId [3]uint32 // Index 0 unused, slots 1 and 2
+	seqNo    uint8
+	loops    loopGuard
+
+	// Configuration data
+	config   repeaterConfig
+	options  string
+	tgOffset uint32 // added to talk groups sent, see SetTGOffset
+
+	// Statistics
+	rxPackets uint32
+	txPackets uint32
+	foreign   uint32 // group calls outside our TG range, dropped
+}
+
+// NewDMRGatewayNetwork creates a connection to the DMRGateway listening on
+// address:port, which sends to localPort (its RptPort)
+func NewDMRGatewayNetwork(address string, port int, localPort uint32, id uint32,
+	version string, debug bool, jitter int) (*DMRGatewayNetwork, error) {
+
+	ip, err := Lookup(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve DMRGateway address %s: %v", address, err)
+	}
+
+	network := &DMRGatewayNetwork{
+		address:   ip,
+		port:      port,
+		debug:     debug,
+		socket:    NewUDPSocket("", int(localPort)),
+		buffer:    make([]byte, 500),
+		pingTimer: NewTimer(1000, dmrGatewayPingTime, 0),
+		loops:     loopGuard{name: "DMRGateway"},
+		config: repeaterConfig{
+			slots:    '3',
+			version:  version,
+			software: protocol.HW_TYPE_HOMEBREW.String(),
+		},
+	}
+	binary.BigEndian.PutUint32(network.id[:], id)
+
+	for slotNo := 1; slotNo <= 2; slotNo++ {
+		network.delayBuffers[slotNo] = NewDelayBuffer(
+			protocol.HOMEBREW_DATA_PACKET_LENGTH,
+			protocol.DMR_SLOT_TIME,
+			jitter)
+		network.streamId[slotNo] = rand.Uint32()
+	}
+
+	if debug {
+		log.Printf("DMRGateway Network created: gateway=%s:%d, id=%d, localPort=%d",
+			address, port, id, localPort)
+	}
+
+	network.ready = make(chan struct{}, 1)
+	network.socket.Notify(network.ready)
+
+	return network, nil
+}
+
+// SetConfig sets the repeater configuration sent in the DMRC packet
+func (n *DMRGatewayNetwork) SetConfig(callsign string, rxFrequency, txFrequency, power, colorCode uint32,
+	latitude, longitude float32, height int, location, description, url string) {
+	n.config.callsign = callsign
+	n.config.rxFrequency = rxFrequency
+	n.config.txFrequency = txFrequency
+	n.config.power = power
+	n.config.colorCode = colorCode
+	n.config.latitude = latitude
+	n.config.longitude = longitude
+	n.config.height = height
+	n.config.location = location
+	n.config.description = description
+	n.config.url = url
+}
+
+// SetOptions sets the options sent in the DMRO packet; DMRGateway uses them
+// for networks without Options of their own
+func (n *DMRGatewayNetwork) SetOptions(options string) {
+	n.options = options
+}
+
+// SetTGOffset moves our talk groups into the range DMRGateway rewrites to
+// one of its networks: offset+TG is sent for TG, and received group calls
+// outside offset+1 to offset+999999 belong to other networks and are
+// dropped. 0 leaves talk groups as they are.
+func (n *DMRGatewayNetwork) SetTGOffset(offset uint32) {
+	n.tgOffset = offset
+}
+
+// SetBindAddress binds the socket to an IP address or interface name (see
+// BindIP) when next opened; empty binds any address
+func (n *DMRGatewayNetwork) SetBindAddress(address string) {
+	n.socket.address = address
+}
+
+// SetCapture keeps the packets exchanged with DMRGateway in r
+func (n *DMRGatewayNetwork) SetCapture(r *capture.Ring) {
+	n.socket.SetCapture(r, "DMRGW")
+}
+
+// SetTrace records the packets exchanged with DMRGateway in t
+func (n *DMRGatewayNetwork) SetTrace(t *trace.Tracer) {
+	n.socket.SetTrace(t, "DMRGW")
+}
+
+// Open opens the UDP socket and announces the repeater to DMRGateway
+func (n *DMRGatewayNetwork) Open() error {
+	if n.debug {
+		log.Printf("Opening DMRGateway connection to %s:%d", n.address.String(), n.port)
+	}
+
+	if err := n.socket.Open(); err != nil {
+		return err
+	}
+	n.open = true
+
+	n.writeConfig()
+	n.writePing()
+	n.pingTimer.Start(0, 0)
+	return nil
+}
+
+// Enable enables or disables data reception
+func (n *DMRGatewayNetwork) Enable(enabled bool) {
+	n.enabled = enabled
+	if n.debug {
+		log.Printf("DMRGateway network enabled: %v", enabled)
+	}
+}
+
+// IsConnected returns true when the socket is open
+// DMRGateway runs on the same host and has no login, so there is no
+// connection to lose; LastReceived shows whether it answers.
+func (n *DMRGatewayNetwork) IsConnected() bool {
+	return n.open
+}
+
+// GetStatusString returns the current state for logging
+func (n *DMRGatewayNetwork) GetStatusString() string {
+	if !n.open {
+		return "CLOSED"
+	}
+	if n.lastKeepalive.IsZero() {
+		return "OPEN"
+	}
+	return fmt.Sprintf("OPEN (last keepalive %s ago)", time.Since(n.lastKeepalive).Round(time.Second))
+}
+
+// WantsBeacon always returns false; DMRGateway sends its own beacons
+func (n *DMRGatewayNetwork) WantsBeacon() bool {
+	return false
+}
+
+// Close closes the UDP socket
+func (n *DMRGatewayNetwork) Close() {
+	if n.debug {
+		log.Printf("Closing DMRGateway connection")
+	}
+
+	n.socket.Close()
+	n.pingTimer.Stop()
+	n.open = false
+}
+
+// Read retrieves a DMR data frame
+func (n *DMRGatewayNetwork) Read(data *protocol.DMRData) bool {
+	if !n.enabled || !n.open {
+		return false
+	}
+
+	for slotNo := 1; slotNo <= 2; slotNo++ {
+		tempBuffer := make([]byte, protocol.HOMEBREW_DATA_PACKET_LENGTH)
+		status := n.delayBuffers[slotNo].GetData(tempBuffer)
+
+		if status == protocol.BS_NO_DATA {
+			continue
+		}
+
+		if !decodeDMRDPacket(tempBuffer, data) {
+			continue
+		}
+
+		data.SetMissing(status == protocol.BS_MISSING)
+
+		if n.debug && !data.IsMissing() {
+			log.Printf("DMRGateway Read: %s", data.String())
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// Write sends a DMR data frame, moving group calls into our TG range
+func (n *DMRGatewayNetwork) Write(data *protocol.DMRData) error {
+	if !n.open {
+		return fmt.Errorf("DMRGateway network not open")
+	}
+
+	if !n.enabled {
+		return nil // Silently ignore when disabled
+	}
+
+	slotNo := data.GetSlotNo()
+	packet := encodeDMRDPacket(data, n.seqNo, n.id, n.streamId[slotNo])
+	n.seqNo++
+	n.loops.sent(n.streamId[slotNo], data.GetSrcId())
+	if data.GetFLCO() == protocol.FLCO_GROUP && n.tgOffset != 0 {
+		putDstID(packet, data.GetDstId()+n.tgOffset)
+	}
+
+	if err := n.writePacket(packet); err != nil {
+		return err
+	}
+	n.txPackets++
+
+	if n.debug {
+		log.Printf("DMRGateway Write: %s", data.String())
+	}
+
+	// A new stream ID is needed for every call
+	if data.GetDataType() == protocol.DT_TERMINATOR_WITH_LC {
+		n.streamId[slotNo] = rand.Uint32()
+	}
+
+	return nil
+}
+
+// Clock sends the keepalives and processes incoming packets and the jitter
+// buffers
+func (n *DMRGatewayNetwork) Clock(ms int) {
+	n.pingTimer.Clock(ms)
+	for i := 1; i <= 2; i++ {
+		n.delayBuffers[i].Clock(ms)
+	}
+
+	if !n.open {
+		return
+	}
+
+	if n.pingTimer.HasExpired() {
+		n.writePing()
+		n.pingTimer.Start(0, 0)
+	}
+
+	for {
+		bytesRead, fromAddr, err := n.socket.Read(n.buffer)
+		if err != nil {
+			if n.debug {
+				log.Printf("DMRGateway socket read error: %v", err)
+			}
+			return
+		}
+
+		if bytesRead == 0 {
+			break // No more data
+		}
+
+		// Only accept packets from DMRGateway
+		if !fromAddr.IP.Equal(n.address) || fromAddr.Port != n.port {
+			if n.debug {
+				log.Printf("DMRGateway: Ignoring packet from unexpected source: %s:%d (expected %s:%d)",
+					fromAddr.IP, fromAddr.Port, n.address.String(), n.port)
+			}
+			continue
+		}
+
+		n.processPacket(n.buffer[:bytesRead])
+	}
+}
+
+// LastReceived returns when the last DMRP keepalive and DMRD packet arrived
+func (n *DMRGatewayNetwork) LastReceived() (keepalive, data time.Time) {
+	return n.lastKeepalive, n.lastData
+}
+
+// GetStats returns packet counters (received, transmitted, and group calls
+// dropped as outside our TG range)
+func (n *DMRGatewayNetwork) GetStats() (rx, tx, foreign uint32) {
+	return n.rxPackets, n.txPackets, n.foreign
+}
+
+// Ready is signalled when received packets are waiting for Clock
+func (n *DMRGatewayNetwork) Ready() <-chan struct{} {
+	return n.ready
+}
+
+// LoopsDetected returns the number of our own frames echoed back by DMRGateway
+func (n *DMRGatewayNetwork) LoopsDetected() uint64 {
+	return n.loops.detected
+}
+
+// processPacket handles a packet from DMRGateway
+func (n *DMRGatewayNetwork) processPacket(packet []byte) {
+	if len(packet) < 4 {
+		return
+	}
+
+	switch string(packet[:4]) {
+	case protocol.NETWORK_MAGIC_GW_PING:
+		n.lastKeepalive = time.Now()
+	case protocol.NETWORK_MAGIC_DATA:
+		n.handleDMRD(packet)
+	default:
+		if n.debug {
+			log.Printf("DMRGateway: Unknown packet type: %s (%d bytes)", string(packet[:4]), len(packet))
+		}
+	}
+}
+
+// handleDMRD queues a DMRD packet, moving group calls back out of our TG
+// range
+func (n *DMRGatewayNetwork) handleDMRD(packet []byte) {
+	if len(packet) != protocol.HOMEBREW_DATA_PACKET_LENGTH {
+		if n.debug {
+			log.Printf("DMRGateway: Invalid DMRD length %d", len(packet))
+		}
+		return
+	}
+	n.rxPackets++
+	n.lastData = time.Now()
+	if !n.enabled {
+		return
+	}
+
+	frame := make([]byte, protocol.HOMEBREW_DATA_PACKET_LENGTH)
+	copy(frame, packet)
+
+	if frame[15]&0x40 == 0 && n.tgOffset != 0 {
+		dstId := uint32(frame[8])<<16 | uint32(frame[9])<<8 | uint32(frame[10])
+		if dstId <= n.tgOffset || dstId >= n.tgOffset+dmrGatewayTGRange {
+			n.foreign++
+			return
+		}
+		putDstID(frame, dstId-n.tgOffset)
+	}
+
+	slotNo := 1
+	if (frame[15] & 0x80) != 0 {
+		slotNo = 2
+	}
+
+	// Our own traffic echoed back would be bridged again
+	if n.loops.looped(frame) {
+		return
+	}
+
+	n.delayBuffers[slotNo].AddData(frame, frame[4])
+}
+
+// writeConfig announces the repeater (DMRC), followed by its options (DMRO)
+// when there are any
+func (n *DMRGatewayNetwork) writeConfig() {
+	n.writePacket(encodeConfigPacket(protocol.NETWORK_MAGIC_GW_CONFIG, n.id, n.config))
+
+	if n.options == "" {
+		return
+	}
+	packet := make([]byte, 8+len(n.options))
+	copy(packet[0:4], protocol.NETWORK_MAGIC_GW_OPTIONS)
+	copy(packet[4:8], n.id[:])
+	copy(packet[8:], n.options)
+	n.writePacket(packet)
+}
+
+// writePing sends a DMRP keepalive
+func (n *DMRGatewayNetwork) writePing() {
+	packet := make([]byte, 8)
+	copy(packet[0:4], protocol.NETWORK_MAGIC_GW_PING)
+	copy(packet[4:8], n.id[:])
+	n.writePacket(packet)
+}
+
+// writePacket sends a packet to DMRGateway
+func (n *DMRGatewayNetwork) writePacket(packet []byte) error {
+	addr := &net.UDPAddr{
+		IP:   n.address,
+		Port: n.port,
+	}
+
+	err := n.socket.Write(packet, addr)
+	if err != nil && n.debug {
+		log.Printf("DMRGateway write error: %v", err)
+	}
+	return err
+}
+
+// putDstID sets the 24-bit destination ID of a DMRD packet
+func putDstID(packet []byte, dstId uint32) {
+	packet[8] = byte(dstId >> 16)
+	packet[9] = byte(dstId >> 8)
+	packet[10] = byte(dstId)
+}
@@ -0,0 +1,71 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+func newTestDMRGateway(t *testing.T, offset uint32) *DMRGatewayNetwork {
+	t.Helper()
+	gw, err := NewDMRGatewayNetwork("127.0.0.1", 62035, 0, 3100001, "test", false, 60)
+	if err != nil {
+		t.Fatalf("NewDMRGatewayNetwork() error = %v", err)
+	}
+	gw.SetTGOffset(offset)
+	gw.Enable(true)
+	gw.open = true
+	return gw
+}
+
+// testDMRGatewayPacket returns a DMRD packet from DMRGateway to dstId
+func testDMRGatewayPacket(dstId uint32, private bool) []byte {
+	data := testOpenBridgeFrame(2)
+	data.SetDstId(dstId)
+	if private {
+		data.SetFLCO(protocol.FLCO_USER_USER)
+	}
+	return encodeDMRDPacket(data, 0, [4]byte{0, 0, 0, 1}, 0x1234)
+}
+
+func TestDMRGateway_ReceivedTGOffset(t *testing.T) {
+	gw := newTestDMRGateway(t, 2000000)
+
+	tests := []struct {
+		dstId   uint32
+		private bool
+		want    uint32 // 0 if dropped
+	}{
+		{2000091, false, 91},
+		{91, false, 0},
+		{3000091, false, 0},
+		{3100123, true, 3100123},
+	}
+	for _, tt := range tests {
+		gw.handleDMRD(testDMRGatewayPacket(tt.dstId, tt.private))
+		gw.delayBuffers[2].Clock(1000)
+
+		var data protocol.DMRData
+		got := uint32(0)
+		if gw.Read(&data) {
+			got = data.GetDstId()
+		}
+		gw.delayBuffers[2].Reset()
+		if got != tt.want {
+			t.Errorf("dst %d (private %v) read as %d, want %d", tt.dstId, tt.private, got, tt.want)
+		}
+	}
+	if _, _, foreign := gw.GetStats(); foreign != 2 {
+		t.Errorf("foreign = %d, want 2", foreign)
+	}
+}
+
+func TestDMRGateway_ConfigPacket(t *testing.T) {
+	gw := newTestDMRGateway(t, 0)
+	gw.SetConfig("n0call", 435000000, 435000000, 1, 1, 0, 0, 0, "Here", "Bridge", "")
+
+	packet := encodeConfigPacket(protocol.NETWORK_MAGIC_GW_CONFIG, gw.id, gw.config)
+	if string(packet[:4]) != "DMRC" || string(packet[8:16]) != "N0CALL  " || packet[97] != '3' {
+		t.Errorf("config packet = %q", packet[:98])
+	}
+}
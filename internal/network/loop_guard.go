@@ -0,0 +1,61 @@
+package network
+
+import (
+	"encoding/binary"
+	"log"
+)
+
+// loopGuardStreams is the number of recent outbound streams remembered
+const loopGuardStreams = 8
+
+// LoopDetector is implemented by DMR networks that drop their own traffic
+// when a misconfigured master or reflector echoes it back
+type LoopDetector interface {
+	LoopsDetected() uint64 // frames dropped as our own
+}
+
+// loopGuard recognises inbound DMRD packets of streams we sent
+// A stream is ours when both its stream ID and source ID match one we
+// transmitted, so another station using the same DMR ID is not affected.
+type loopGuard struct {
+	name    string // log prefix
+	streams [loopGuardStreams]struct{ streamId, srcId uint32 }
+	next    int
+
+	lastLooped uint32 // stream last reported, to log each loop once
+	detected   uint64
+}
+
+// sent records an outbound frame
+func (l *loopGuard) sent(streamId, srcId uint32) {
+	last := l.streams[(l.next+loopGuardStreams-1)%loopGuardStreams]
+	if last.streamId == streamId && last.srcId == srcId {
+		return
+	}
+	l.streams[l.next].streamId = streamId
+	l.streams[l.next].srcId = srcId
+	l.next = (l.next + 1) % loopGuardStreams
+}
+
+// looped reports whether an inbound DMRD packet is one of our own streams,
+// counting and logging it if so
+func (l *loopGuard) looped(packet []byte) bool {
+	if len(packet) < 20 {
+		return false
+	}
+	srcId := uint32(packet[5])<<16 | uint32(packet[6])<<8 | uint32(packet[7])
+	streamId := binary.BigEndian.Uint32(packet[16:20])
+
+	for _, s := range l.streams {
+		if s.streamId == streamId && s.srcId == srcId && srcId != 0 {
+			l.detected++
+			if streamId != l.lastLooped {
+				l.lastLooped = streamId
+				log.Printf("%s: loop detected, dropping our own stream 0x%08X from %d echoed back by the network",
+					l.name, streamId, srcId)
+			}
+			return true
+		}
+	}
+	return false
+}
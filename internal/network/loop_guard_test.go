@@ -0,0 +1,75 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+func TestLoopGuard(t *testing.T) {
+	l := &loopGuard{name: "DMR"}
+	data := protocol.NewDMRData()
+	data.SetSrcId(3100001)
+	data.SetDstId(91)
+	data.SetSlotNo(2)
+
+	own := encodeDMRDPacket(data, 0, [4]byte{}, 0x11223344)
+	l.sent(0x11223344, 3100001)
+
+	if !l.looped(own) {
+		t.Error("own stream not detected")
+	}
+
+	// Same stream ID from another source, and another stream from our ID
+	data.SetSrcId(3100002)
+	if l.looped(encodeDMRDPacket(data, 0, [4]byte{}, 0x11223344)) {
+		t.Error("stream from another source detected as a loop")
+	}
+	data.SetSrcId(3100001)
+	if l.looped(encodeDMRDPacket(data, 0, [4]byte{}, 0x55667788)) {
+		t.Error("another stream from our ID detected as a loop")
+	}
+
+	// Older streams are remembered until pushed out by newer ones
+	for i := uint32(1); i < loopGuardStreams; i++ {
+		l.sent(i, 3100001)
+	}
+	if !l.looped(own) {
+		t.Error("recent stream forgotten")
+	}
+	l.sent(loopGuardStreams, 3100001)
+	if l.looped(own) {
+		t.Error("stream remembered beyond the history")
+	}
+
+	if l.detected != 2 {
+		t.Errorf("detected = %d, want 2", l.detected)
+	}
+}
+
+func TestDMRNetworkDropsOwnStream(t *testing.T) {
+	network, err := NewDMRNetwork("127.0.0.1", 62030, 4000, 123456, "test123",
+		true, "1.0.0", false, true, true, protocol.HW_TYPE_HOMEBREW, 120)
+	if err != nil {
+		t.Fatalf("Failed to create network: %v", err)
+	}
+	network.Enable(true)
+	network.status = protocol.DMR_RUNNING
+
+	data := protocol.NewDMRData()
+	data.SetSrcId(123456)
+	data.SetDstId(91)
+	data.SetSlotNo(2)
+	data.SetDataType(protocol.DT_VOICE_SYNC)
+
+	// Echo the packet we would send back to the network
+	network.handleDMRD(network.buildDMRDPacket(data))
+	if network.LoopsDetected() != 1 {
+		t.Errorf("LoopsDetected() = %d, want 1", network.LoopsDetected())
+	}
+
+	network.delayBuffers[2].Clock(protocol.DMR_SLOT_TIME)
+	if network.Read(protocol.NewDMRData()) {
+		t.Error("own stream was passed to the gateway")
+	}
+}
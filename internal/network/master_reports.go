@@ -0,0 +1,119 @@
+package network
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/dmr"
+)
+
+// talkerAliasExpiry is how long an alias received in DMRA packets is kept
+// after its last block
+const talkerAliasExpiry = 10 * time.Minute
+
+// MasterReports is implemented by networks that take in the extension
+// packets some Homebrew masters send: RPTRSSI signal reports and DMRA talker
+// aliases sent beside the voice stream
+type MasterReports interface {
+	TalkerAlias(srcID uint32) (string, bool)   // alias of srcID from DMRA packets
+	RSSI(slotNo uint8) (dBm int, at time.Time) // last RPTRSSI report for slot 1 or 2, zero time if none
+}
+
+// masterReports keeps the data of RPTRSSI and DMRA packets
+type masterReports struct {
+	mu      sync.Mutex
+	aliases map[uint32]*receivedAlias // by source ID
+	rssi    [3]rssiReading            // by slot
+}
+
+type receivedAlias struct {
+	blocks  dmr.TalkerAlias
+	alias   string
+	updated time.Time
+}
+
+type rssiReading struct {
+	dBm int
+	at  time.Time
+}
+
+// handleRSSI stores an RPTRSSI packet:
+//
+//	RPTRSSI <repeater ID:4> <slot:1> <RSSI:1, in -dBm>
+func (r *masterReports) handleRSSI(packet []byte) (slotNo uint8, dBm int, ok bool) {
+	if len(packet) < protocol.NETWORK_RSSI_LENGTH {
+		return 0, 0, false
+	}
+	slotNo = packet[11]
+	if slotNo != 1 && slotNo != 2 {
+		return 0, 0, false
+	}
+	dBm = -int(packet[12])
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rssi[slotNo] = rssiReading{dBm: dBm, at: time.Now()}
+	return slotNo, dBm, true
+}
+
+// handleTalkerAlias adds a DMRA packet, laid out as sent by WriteTalkerAlias,
+// to the alias of its source, returning the alias once it is complete:
+//
+//	DMRA <repeater ID:4> <source ID:3> <block:1, 0 for the header> <data:7>
+func (r *masterReports) handleTalkerAlias(packet []byte) (srcID uint32, alias string, complete bool) {
+	if len(packet) < protocol.NETWORK_TALKERALIAS_LENGTH || packet[11] > 3 {
+		return 0, "", false
+	}
+	srcID = binary.BigEndian.Uint32(packet[7:11]) & 0xFFFFFF
+
+	// The block as the embedded LC it was taken from
+	lc := make([]byte, dmr.EMBEDDED_LC_LENGTH)
+	lc[0] = dmr.FLCO_TALKER_ALIAS_HEADER + packet[11]
+	copy(lc[2:], packet[12:19])
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if r.aliases == nil {
+		r.aliases = make(map[uint32]*receivedAlias)
+	}
+	for id, received := range r.aliases {
+		if now.Sub(received.updated) > talkerAliasExpiry {
+			delete(r.aliases, id)
+		}
+	}
+
+	received, found := r.aliases[srcID]
+	if !found {
+		received = &receivedAlias{}
+		r.aliases[srcID] = received
+	}
+	received.updated = now
+	if alias, complete = received.blocks.Add(lc); complete {
+		received.alias = alias
+	}
+	return srcID, alias, complete
+}
+
+// TalkerAlias returns the alias of srcID received in DMRA packets
+func (r *masterReports) TalkerAlias(srcID uint32) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	received, found := r.aliases[srcID]
+	if !found || received.alias == "" || time.Since(received.updated) > talkerAliasExpiry {
+		return "", false
+	}
+	return received.alias, true
+}
+
+// RSSI returns the last signal report for slotNo
+func (r *masterReports) RSSI(slotNo uint8) (int, time.Time) {
+	if slotNo != 1 && slotNo != 2 {
+		return 0, time.Time{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rssi[slotNo].dBm, r.rssi[slotNo].at
+}
@@ -0,0 +1,60 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+// testDMRA builds a DMRA packet carrying block of a talker alias from srcID
+func testDMRA(srcID uint32, block uint8, data string) []byte {
+	packet := make([]byte, protocol.NETWORK_TALKERALIAS_LENGTH)
+	copy(packet, protocol.NETWORK_MAGIC_TALKERALIAS)
+	packet[8], packet[9], packet[10] = byte(srcID>>16), byte(srcID>>8), byte(srcID)
+	packet[11] = block
+	copy(packet[12:], data)
+	return packet
+}
+
+// testTalkerAlias returns the DMRA packets of a 10 character 8-bit alias,
+// block 1 first
+func testTalkerAlias(srcID uint32) [][]byte {
+	return [][]byte{
+		testDMRA(srcID, 1, "ERMAN"),
+		testDMRA(srcID, 0, "\x54W1AW H"), // 8-bit format, 10 characters
+	}
+}
+
+func TestDMRNetworkMasterReports(t *testing.T) {
+	network, err := NewDMRNetwork("127.0.0.1", 62030, 4000, 123456, "test123",
+		true, "1.0.0", false, true, true, protocol.HW_TYPE_HOMEBREW, 120)
+	if err != nil {
+		t.Fatalf("Failed to create network: %v", err)
+	}
+	var reports MasterReports = network
+
+	if _, at := reports.RSSI(2); !at.IsZero() {
+		t.Error("RSSI reported before any RPTRSSI packet")
+	}
+	network.processPacket([]byte("RPTRSSI\x00\x01\xE2\x40\x02\x49"))
+	if dBm, at := reports.RSSI(2); dBm != -73 || at.IsZero() {
+		t.Errorf("RSSI(2) = %d, %v, want -73", dBm, at)
+	}
+	network.processPacket([]byte("RPTRSSI\x00\x01\xE2\x40\x03\x49"))
+	if _, at := reports.RSSI(1); !at.IsZero() {
+		t.Error("RPTRSSI packet for slot 3 stored")
+	}
+
+	packets := testTalkerAlias(3100001)
+	network.processPacket(packets[0])
+	if alias, ok := reports.TalkerAlias(3100001); ok {
+		t.Errorf("TalkerAlias() = %q before the header", alias)
+	}
+	network.processPacket(packets[1])
+	if alias, ok := reports.TalkerAlias(3100001); !ok || alias != "W1AW HERMA" {
+		t.Errorf("TalkerAlias() = %q, %v, want W1AW HERMA", alias, ok)
+	}
+	if _, ok := reports.TalkerAlias(3100002); ok {
+		t.Error("TalkerAlias() returned an alias for another ID")
+	}
+}
@@ -0,0 +1,391 @@
+package network
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/capture"
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/trace"
+)
+
+// OpenBridgeNetwork provides an OpenBridge (HBP OBP) peer connection
+// OpenBridge is the master-to-master variant of the Homebrew protocol used by
+// Brandmeister and HBlink: there is no login sequence, every DMRD packet is
+// authenticated with an HMAC-SHA1 of its first 53 bytes keyed by the shared
+// passphrase, and all traffic is carried on timeslot 1.
+type OpenBridgeNetwork struct {
+	// Network configuration
+	address    net.IP
+	port       int
+	networkId  [4]byte // 4-byte network ID (big-endian)
+	passphrase []byte
+	debug      bool
+	enabled    bool
+
+	// Network components
+	socket       *UDPSocket
+	ready        chan struct{} // signalled when packets are waiting for Clock
+	buffer       []byte
+	delayBuffers [3]*DelayBuffer // Index 0 unused, slots 1 and 2
+
+	// State management
+	open   bool
+	lastRX time.Time
+
+	// Stream management
+	streamId uint32
+	seqNo    uint8
+	loops    loopGuard
+
+	// Statistics
+	rxPackets    uint32
+	txPackets    uint32
+	authFailed   uint32
+	wrongNetwork uint32 // authenticated packets for another network ID
+}
+
+// NewOpenBridgeNetwork creates a new OpenBridge network instance
+func NewOpenBridgeNetwork(address string, port int, localPort uint32, networkId uint32,
+	passphrase string, debug bool, jitter int) (*OpenBridgeNetwork, error) {
+
+	// Resolve address
+	ip, err := Lookup(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve OpenBridge target address %s: %v", address, err)
+	}
+
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("OpenBridge requires a passphrase")
+	}
+
+	network := &OpenBridgeNetwork{
+		address:    ip,
+		port:       port,
+		passphrase: []byte(passphrase),
+		debug:      debug,
+		enabled:    false,
+		socket:     NewUDPSocket("", int(localPort)),
+		buffer:     make([]byte, 500),
+		loops:      loopGuard{name: "OpenBridge"},
+	}
+
+	binary.BigEndian.PutUint32(network.networkId[:], networkId)
+
+	// Received frames are normalised to the 55-byte Homebrew layout so they can
+	// share the delay buffer and DMRD parser with the Homebrew implementation
+	for slotNo := 1; slotNo <= 2; slotNo++ {
+		network.delayBuffers[slotNo] = NewDelayBuffer(
+			protocol.HOMEBREW_DATA_PACKET_LENGTH,
+			protocol.DMR_SLOT_TIME,
+			jitter)
+	}
+
+	network.streamId = rand.Uint32()
+
+	if debug {
+		log.Printf("OpenBridge Network created: target=%s:%d, network id=%d, localPort=%d",
+			address, port, networkId, localPort)
+	}
+
+	network.ready = make(chan struct{}, 1)
+	network.socket.Notify(network.ready)
+
+	return network, nil
+}
+
+// Open opens the UDP socket; OpenBridge has no login sequence
+func (n *OpenBridgeNetwork) Open() error {
+	if n.debug {
+		log.Printf("Opening OpenBridge connection to %s:%d", n.address.String(), n.port)
+	}
+
+	if err := n.socket.Open(); err != nil {
+		return err
+	}
+
+	n.open = true
+	return nil
+}
+
+// Enable enables or disables data reception
+func (n *OpenBridgeNetwork) Enable(enabled bool) {
+	n.enabled = enabled
+	if n.debug {
+		log.Printf("OpenBridge network enabled: %v", enabled)
+	}
+}
+
+// IsConnected returns true when the socket is open
+// OpenBridge is connectionless, so this cannot detect a dead peer
+func (n *OpenBridgeNetwork) IsConnected() bool {
+	return n.open
+}
+
+// GetStatusString returns the current state for logging
+func (n *OpenBridgeNetwork) GetStatusString() string {
+	if !n.open {
+		return "CLOSED"
+	}
+	if n.lastRX.IsZero() {
+		return "OPEN"
+	}
+	return fmt.Sprintf("OPEN (last rx %s ago)", time.Since(n.lastRX).Round(time.Second))
+}
+
+// WantsBeacon always returns false; OpenBridge peers never request beacons
+func (n *OpenBridgeNetwork) WantsBeacon() bool {
+	return false
+}
+
+// Close closes the UDP socket
+func (n *OpenBridgeNetwork) Close() {
+	if n.debug {
+		log.Printf("Closing OpenBridge connection")
+	}
+
+	n.socket.Close()
+	n.open = false
+}
+
+// Read retrieves a DMR data frame
+func (n *OpenBridgeNetwork) Read(data *protocol.DMRData) bool {
+	if !n.enabled || !n.open {
+		return false
+	}
+
+	for slotNo := 1; slotNo <= 2; slotNo++ {
+		tempBuffer := make([]byte, protocol.HOMEBREW_DATA_PACKET_LENGTH)
+		status := n.delayBuffers[slotNo].GetData(tempBuffer)
+
+		if status == protocol.BS_NO_DATA {
+			continue
+		}
+
+		if !decodeDMRDPacket(tempBuffer, data) {
+			continue
+		}
+
+		data.SetMissing(status == protocol.BS_MISSING)
+
+		if n.debug && !data.IsMissing() {
+			log.Printf("OpenBridge Read: %s", data.String())
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// Write sends a DMR data frame, forcing timeslot 1 as OpenBridge requires
+func (n *OpenBridgeNetwork) Write(data *protocol.DMRData) error {
+	if !n.open {
+		return fmt.Errorf("OpenBridge network not open")
+	}
+
+	if !n.enabled {
+		return nil // Silently ignore when disabled
+	}
+
+	packet := n.buildPacket(data)
+
+	addr := &net.UDPAddr{
+		IP:   n.address,
+		Port: n.port,
+	}
+
+	if err := n.socket.Write(packet, addr); err != nil {
+		if n.debug {
+			log.Printf("OpenBridge write error: %v", err)
+		}
+		return err
+	}
+
+	n.txPackets++
+
+	if n.debug {
+		log.Printf("OpenBridge Write: %s", data.String())
+	}
+
+	// A new stream ID is needed for every call
+	if data.GetDataType() == protocol.DT_TERMINATOR_WITH_LC {
+		n.streamId = rand.Uint32()
+	}
+
+	return nil
+}
+
+// Clock processes incoming packets and the jitter buffers
+func (n *OpenBridgeNetwork) Clock(ms int) {
+	for i := 1; i <= 2; i++ {
+		n.delayBuffers[i].Clock(ms)
+	}
+
+	if !n.open {
+		return
+	}
+
+	for {
+		bytesRead, fromAddr, err := n.socket.Read(n.buffer)
+		if err != nil {
+			if n.debug {
+				log.Printf("OpenBridge socket read error: %v", err)
+			}
+			return
+		}
+
+		if bytesRead == 0 {
+			break // No more data
+		}
+
+		// Only accept packets from the configured peer
+		if !fromAddr.IP.Equal(n.address) || fromAddr.Port != n.port {
+			if n.debug {
+				log.Printf("OpenBridge: Ignoring packet from unexpected source: %s:%d (expected %s:%d)",
+					fromAddr.IP, fromAddr.Port, n.address.String(), n.port)
+			}
+			continue
+		}
+
+		n.processPacket(n.buffer[:bytesRead])
+	}
+}
+
+// SetBindAddress binds the socket to an IP address or interface name (see
+// BindIP) when next opened; empty binds any address
+func (n *OpenBridgeNetwork) SetBindAddress(address string) {
+	n.socket.address = address
+}
+
+// SetProxy relays the traffic with the peer through a SOCKS5 proxy from the
+// next Open; nil sends directly
+func (n *OpenBridgeNetwork) SetProxy(p *SOCKS5Proxy) {
+	n.socket.SetProxy(p)
+}
+
+// SetCapture keeps the packets exchanged with the OpenBridge peer in r
+func (n *OpenBridgeNetwork) SetCapture(r *capture.Ring) {
+	n.socket.SetCapture(r, "OBP")
+}
+
+// SetTrace records the packets exchanged with the OpenBridge peer in t
+func (n *OpenBridgeNetwork) SetTrace(t *trace.Tracer) {
+	n.socket.SetTrace(t, "OBP")
+}
+
+// LastReceived returns when the last authenticated DMRD packet arrived
+// OpenBridge has no keepalive.
+func (n *OpenBridgeNetwork) LastReceived() (keepalive, data time.Time) {
+	return time.Time{}, n.lastRX
+}
+
+// GetStats returns packet counters (received, transmitted, HMAC failures
+// and packets dropped for another network ID)
+func (n *OpenBridgeNetwork) GetStats() (rx, tx, authFailed, wrongNetwork uint32) {
+	return n.rxPackets, n.txPackets, n.authFailed, n.wrongNetwork
+}
+
+// Ready is signalled when received packets are waiting for Clock
+func (n *OpenBridgeNetwork) Ready() <-chan struct{} {
+	return n.ready
+}
+
+// LoopsDetected returns the number of our own frames echoed back by the peer
+func (n *OpenBridgeNetwork) LoopsDetected() uint64 {
+	return n.loops.detected
+}
+
+// processPacket validates and queues an incoming OpenBridge packet
+func (n *OpenBridgeNetwork) processPacket(packet []byte) {
+	if len(packet) < 4 || string(packet[:4]) != protocol.NETWORK_MAGIC_DATA {
+		if n.debug && len(packet) >= 4 {
+			log.Printf("OpenBridge: Unknown packet type: %s (%d bytes)", string(packet[:4]), len(packet))
+		}
+		return
+	}
+
+	if len(packet) != protocol.OPENBRIDGE_DATA_PACKET_LENGTH {
+		if n.debug {
+			log.Printf("OpenBridge: Invalid DMRD length %d", len(packet))
+		}
+		return
+	}
+
+	if !n.verifyHMAC(packet) {
+		n.authFailed++
+		if n.debug {
+			log.Printf("OpenBridge: HMAC verification failed, dropping packet")
+		}
+		return
+	}
+
+	// Like HBlink, the peer must use the same network ID
+	if !bytes.Equal(packet[11:15], n.networkId[:]) {
+		n.wrongNetwork++
+		if n.debug {
+			log.Printf("OpenBridge: Network ID %d does not match %d, dropping packet",
+				binary.BigEndian.Uint32(packet[11:15]), binary.BigEndian.Uint32(n.networkId[:]))
+		}
+		return
+	}
+
+	n.rxPackets++
+	n.lastRX = time.Now()
+
+	if !n.enabled {
+		return
+	}
+
+	// Convert to the Homebrew layout (BER and RSSI are not carried by OpenBridge)
+	frame := make([]byte, protocol.HOMEBREW_DATA_PACKET_LENGTH)
+	copy(frame, packet[:protocol.OPENBRIDGE_PAYLOAD_LENGTH])
+
+	slotNo := 1
+	if (frame[15] & 0x80) != 0 {
+		slotNo = 2
+	}
+
+	// Our own traffic echoed back would be bridged again
+	if n.loops.looped(frame) {
+		return
+	}
+
+	n.delayBuffers[slotNo].AddData(frame, frame[4])
+}
+
+// buildPacket builds an authenticated OpenBridge DMRD packet
+func (n *OpenBridgeNetwork) buildPacket(data *protocol.DMRData) []byte {
+	frame := encodeDMRDPacket(data, n.seqNo, n.networkId, n.streamId)
+	n.seqNo++
+	n.loops.sent(n.streamId, data.GetSrcId())
+
+	// OpenBridge traffic is always on timeslot 1
+	frame[15] &^= 0x80
+
+	packet := make([]byte, protocol.OPENBRIDGE_DATA_PACKET_LENGTH)
+	copy(packet, frame[:protocol.OPENBRIDGE_PAYLOAD_LENGTH])
+	copy(packet[protocol.OPENBRIDGE_PAYLOAD_LENGTH:], n.computeHMAC(packet[:protocol.OPENBRIDGE_PAYLOAD_LENGTH]))
+
+	return packet
+}
+
+// computeHMAC returns the HMAC-SHA1 of data keyed by the passphrase
+func (n *OpenBridgeNetwork) computeHMAC(data []byte) []byte {
+	mac := hmac.New(sha1.New, n.passphrase)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// verifyHMAC checks the trailing HMAC-SHA1 of an OpenBridge packet
+func (n *OpenBridgeNetwork) verifyHMAC(packet []byte) bool {
+	expected := n.computeHMAC(packet[:protocol.OPENBRIDGE_PAYLOAD_LENGTH])
+	return hmac.Equal(expected, packet[protocol.OPENBRIDGE_PAYLOAD_LENGTH:protocol.OPENBRIDGE_DATA_PACKET_LENGTH])
+}
@@ -0,0 +1,147 @@
+package network
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+func newTestOpenBridge(t *testing.T) *OpenBridgeNetwork {
+	t.Helper()
+	obp, err := NewOpenBridgeNetwork("127.0.0.1", 62035, 0, 3100001, "passw0rd", false, 60)
+	if err != nil {
+		t.Fatalf("NewOpenBridgeNetwork() error = %v", err)
+	}
+	return obp
+}
+
+func testOpenBridgeFrame(slotNo uint8) *protocol.DMRData {
+	data := protocol.NewDMRData()
+	data.SetSlotNo(slotNo)
+	data.SetSrcId(3100123)
+	data.SetDstId(91)
+	data.SetFLCO(protocol.FLCO_GROUP)
+	data.SetDataType(protocol.DT_VOICE_LC_HEADER)
+	payload := make([]byte, protocol.DMR_FRAME_LENGTH_BYTES)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	data.SetData(payload)
+	return data
+}
+
+func TestNewOpenBridgeNetwork_RequiresPassphrase(t *testing.T) {
+	if _, err := NewOpenBridgeNetwork("127.0.0.1", 62035, 0, 1, "", false, 60); err == nil {
+		t.Error("expected error for empty passphrase")
+	}
+}
+
+func TestOpenBridge_BuildPacket(t *testing.T) {
+	obp := newTestOpenBridge(t)
+
+	packet := obp.buildPacket(testOpenBridgeFrame(2))
+
+	if len(packet) != protocol.OPENBRIDGE_DATA_PACKET_LENGTH {
+		t.Fatalf("packet length = %d, want %d", len(packet), protocol.OPENBRIDGE_DATA_PACKET_LENGTH)
+	}
+	if string(packet[:4]) != protocol.NETWORK_MAGIC_DATA {
+		t.Errorf("packet magic = %q, want %q", string(packet[:4]), protocol.NETWORK_MAGIC_DATA)
+	}
+	if packet[15]&0x80 != 0 {
+		t.Error("slot 2 flag set, OpenBridge traffic must be on slot 1")
+	}
+	if !bytes.Equal(packet[11:15], obp.networkId[:]) {
+		t.Errorf("network id = %v, want %v", packet[11:15], obp.networkId)
+	}
+	if !obp.verifyHMAC(packet) {
+		t.Error("built packet fails HMAC verification")
+	}
+}
+
+func TestOpenBridge_ProcessPacket(t *testing.T) {
+	obp := newTestOpenBridge(t)
+	obp.Enable(true)
+	obp.open = true
+
+	// Built by a peer: our own stream echoed back would be dropped as a loop
+	peer := newTestOpenBridge(t)
+	sent := testOpenBridgeFrame(1)
+	obp.processPacket(peer.buildPacket(sent))
+
+	rx, _, authFailed, _ := obp.GetStats()
+	if rx != 1 || authFailed != 0 {
+		t.Fatalf("stats rx=%d authFailed=%d, want 1 and 0", rx, authFailed)
+	}
+
+	obp.Clock(protocol.DMR_SLOT_TIME)
+
+	received := protocol.NewDMRData()
+	if !obp.Read(received) {
+		t.Fatal("Read() returned no data")
+	}
+	if received.GetSrcId() != sent.GetSrcId() || received.GetDstId() != sent.GetDstId() {
+		t.Errorf("ids = %d->%d, want %d->%d", received.GetSrcId(), received.GetDstId(),
+			sent.GetSrcId(), sent.GetDstId())
+	}
+	if received.GetData() != sent.GetData() {
+		t.Error("payload mismatch after round trip")
+	}
+}
+
+func TestOpenBridge_RejectsBadHMAC(t *testing.T) {
+	obp := newTestOpenBridge(t)
+	obp.Enable(true)
+
+	packet := obp.buildPacket(testOpenBridgeFrame(1))
+	packet[len(packet)-1] ^= 0xFF
+	obp.processPacket(packet)
+
+	// Wrong passphrase
+	other, err := NewOpenBridgeNetwork("127.0.0.1", 62035, 0, 3100001, "different", false, 60)
+	if err != nil {
+		t.Fatalf("NewOpenBridgeNetwork() error = %v", err)
+	}
+	obp.processPacket(other.buildPacket(testOpenBridgeFrame(1)))
+
+	rx, _, authFailed, _ := obp.GetStats()
+	if rx != 0 {
+		t.Errorf("rx = %d, want 0", rx)
+	}
+	if authFailed != 2 {
+		t.Errorf("authFailed = %d, want 2", authFailed)
+	}
+}
+
+func TestOpenBridge_IgnoresHomebrewLength(t *testing.T) {
+	obp := newTestOpenBridge(t)
+	obp.Enable(true)
+
+	packet := encodeDMRDPacket(testOpenBridgeFrame(1), 0, obp.networkId, 1)
+	obp.processPacket(packet)
+
+	rx, _, authFailed, _ := obp.GetStats()
+	if rx != 0 || authFailed != 0 {
+		t.Errorf("stats rx=%d authFailed=%d, want 0 and 0", rx, authFailed)
+	}
+}
+
+func TestOpenBridge_RejectsWrongNetworkID(t *testing.T) {
+	obp := newTestOpenBridge(t)
+	obp.Enable(true)
+
+	// Same passphrase, other network ID
+	other, err := NewOpenBridgeNetwork("127.0.0.1", 62035, 0, 3100002, "passw0rd", false, 60)
+	if err != nil {
+		t.Fatalf("NewOpenBridgeNetwork() error = %v", err)
+	}
+	obp.processPacket(other.buildPacket(testOpenBridgeFrame(1)))
+	obp.Clock(protocol.DMR_SLOT_TIME)
+
+	if obp.Read(protocol.NewDMRData()) {
+		t.Error("Read() returned a frame for another network ID")
+	}
+	if rx, _, authFailed, wrongNetwork := obp.GetStats(); rx != 0 || authFailed != 0 || wrongNetwork != 1 {
+		t.Errorf("stats rx=%d authFailed=%d wrongNetwork=%d, want 0, 0 and 1", rx, authFailed, wrongNetwork)
+	}
+}
@@ -0,0 +1,159 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+// Errors returned by OutputQueue.Write for frames that were not sent
+var (
+	ErrFrameQueued  = errors.New("DMR network not ready, frame queued")
+	ErrFrameDropped = errors.New("DMR network not ready, frame dropped")
+)
+
+// DropPolicy selects which frame an OutputQueue discards when it is full
+type DropPolicy int
+
+const (
+	DropOldest DropPolicy = iota // discard the oldest queued frame to make room
+	DropNewest                   // discard the frame being written
+)
+
+// ParseDropPolicy parses "oldest" or "newest"
+func ParseDropPolicy(s string) (DropPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "oldest":
+		return DropOldest, nil
+	case "newest":
+		return DropNewest, nil
+	default:
+		return DropOldest, fmt.Errorf("unknown drop policy: %s", s)
+	}
+}
+
+func (p DropPolicy) String() string {
+	if p == DropNewest {
+		return "newest"
+	}
+	return "oldest"
+}
+
+// OutputStats counts what happened to the frames written to an OutputQueue
+type OutputStats struct {
+	Sent    uint64 // written to the network, directly or after waiting
+	Queued  uint64 // held because the network was not ready
+	Dropped uint64 // discarded because the queue was full or the write failed
+	Expired uint64 // discarded after waiting longer than the maximum age
+}
+
+// queuedFrame is a frame waiting for the network with its age in ms
+type queuedFrame struct {
+	data *protocol.DMRData
+	age  int
+}
+
+// OutputQueue sits between the codec and a DMR network writer
+// While the network is reconnecting, frames are held up to a fixed number
+// and age and delivered in order once it is running again; with a size of 0
+// they are dropped. Either way Write reports frames that were not sent, so
+// the caller can decide whether the call is still worth carrying on.
+type OutputQueue struct {
+	network DMRNetworkInterface
+	size    int
+	maxAge  int // ms
+	policy  DropPolicy
+
+	frames []queuedFrame
+	stats  OutputStats
+}
+
+// NewOutputQueue creates an output queue holding up to size frames for at
+// most maxAge ms
+func NewOutputQueue(network DMRNetworkInterface, size, maxAge int, policy DropPolicy) *OutputQueue {
+	return &OutputQueue{
+		network: network,
+		size:    size,
+		maxAge:  maxAge,
+		policy:  policy,
+	}
+}
+
+// Write sends a frame, or queues it while the network is not running
+// It returns ErrFrameQueued or ErrFrameDropped for frames that were not sent
+// now, or the network error if the write itself failed.
+func (q *OutputQueue) Write(data *protocol.DMRData) error {
+	if q.network.IsConnected() {
+		q.flush()
+	}
+	if q.network.IsConnected() && len(q.frames) == 0 {
+		return q.send(data)
+	}
+
+	if len(q.frames) >= q.size {
+		if q.size == 0 || q.policy == DropNewest {
+			q.stats.Dropped++
+			return ErrFrameDropped
+		}
+		q.frames[0].data = nil
+		q.frames = q.frames[1:]
+		q.stats.Dropped++
+	}
+
+	q.frames = append(q.frames, queuedFrame{data: data.Copy()})
+	q.stats.Queued++
+	return ErrFrameQueued
+}
+
+// Clock ages the queued frames, discarding expired ones, and delivers the
+// rest once the network is running
+func (q *OutputQueue) Clock(ms int) {
+	expired := 0
+	for i := range q.frames {
+		q.frames[i].age += ms
+		if q.frames[i].age > q.maxAge {
+			expired = i + 1
+		}
+	}
+	if expired > 0 {
+		q.frames = q.frames[expired:]
+		q.stats.Expired += uint64(expired)
+	}
+
+	if q.network.IsConnected() {
+		q.flush()
+	}
+}
+
+// flush sends the queued frames in order, stopping at the first failure
+func (q *OutputQueue) flush() {
+	for len(q.frames) > 0 {
+		data := q.frames[0].data
+		q.frames = q.frames[1:]
+		if q.send(data) != nil {
+			return
+		}
+	}
+}
+
+// send writes a frame to the network and counts the result
+func (q *OutputQueue) send(data *protocol.DMRData) error {
+	if err := q.network.Write(data); err != nil {
+		q.stats.Dropped++
+		return err
+	}
+	q.stats.Sent++
+	return nil
+}
+
+// Len returns the number of frames waiting for the network
+func (q *OutputQueue) Len() int {
+	return len(q.frames)
+}
+
+// Stats returns the frame counters
+func (q *OutputQueue) Stats() OutputStats {
+	return q.stats
+}
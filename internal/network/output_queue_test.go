@@ -0,0 +1,155 @@
+package network
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+// fakeDMRNetwork records written frames by sequence number
+type fakeDMRNetwork struct {
+	connected bool
+	writeErr  error
+	written   []uint8
+}
+
+func (f *fakeDMRNetwork) Open() error                      { return nil }
+func (f *fakeDMRNetwork) Close()                           {}
+func (f *fakeDMRNetwork) Enable(enabled bool)              {}
+func (f *fakeDMRNetwork) Read(data *protocol.DMRData) bool { return false }
+func (f *fakeDMRNetwork) Clock(ms int)                     {}
+func (f *fakeDMRNetwork) IsConnected() bool                { return f.connected }
+func (f *fakeDMRNetwork) GetStatusString() string          { return "" }
+func (f *fakeDMRNetwork) WantsBeacon() bool                { return false }
+
+func (f *fakeDMRNetwork) Write(data *protocol.DMRData) error {
+	if f.writeErr != nil {
+		return f.writeErr
+	}
+	f.written = append(f.written, data.GetSeqNo())
+	return nil
+}
+
+func writeSeq(q *OutputQueue, seqNo uint8) error {
+	data := protocol.NewDMRData()
+	data.SetSeqNo(seqNo)
+	return q.Write(data)
+}
+
+func TestOutputQueueConnected(t *testing.T) {
+	net := &fakeDMRNetwork{connected: true}
+	q := NewOutputQueue(net, 4, 1000, DropOldest)
+
+	for i := uint8(0); i < 3; i++ {
+		if err := writeSeq(q, i); err != nil {
+			t.Fatalf("Write(%d) error = %v", i, err)
+		}
+	}
+	if len(net.written) != 3 || q.Len() != 0 {
+		t.Errorf("written = %v, queued = %d", net.written, q.Len())
+	}
+	if s := q.Stats(); s.Sent != 3 || s.Queued != 0 || s.Dropped != 0 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestOutputQueueDropPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		policy  DropPolicy
+		want    []uint8 // delivered after reconnecting
+		errs    []error // from each of the five writes
+		dropped uint64
+	}{
+		{"no queue", 0, DropOldest, nil,
+			[]error{ErrFrameDropped, ErrFrameDropped, ErrFrameDropped, ErrFrameDropped, ErrFrameDropped}, 5},
+		{"drop oldest", 3, DropOldest, []uint8{2, 3, 4},
+			[]error{ErrFrameQueued, ErrFrameQueued, ErrFrameQueued, ErrFrameQueued, ErrFrameQueued}, 2},
+		{"drop newest", 3, DropNewest, []uint8{0, 1, 2},
+			[]error{ErrFrameQueued, ErrFrameQueued, ErrFrameQueued, ErrFrameDropped, ErrFrameDropped}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net := &fakeDMRNetwork{}
+			q := NewOutputQueue(net, tt.size, 1000, tt.policy)
+
+			for i := uint8(0); i < 5; i++ {
+				if err := writeSeq(q, i); !errors.Is(err, tt.errs[i]) {
+					t.Errorf("Write(%d) error = %v, want %v", i, err, tt.errs[i])
+				}
+			}
+
+			net.connected = true
+			q.Clock(20)
+
+			if len(net.written) != len(tt.want) {
+				t.Fatalf("written = %v, want %v", net.written, tt.want)
+			}
+			for i := range tt.want {
+				if net.written[i] != tt.want[i] {
+					t.Errorf("written = %v, want %v", net.written, tt.want)
+					break
+				}
+			}
+			if s := q.Stats(); s.Dropped != tt.dropped || s.Sent != uint64(len(tt.want)) {
+				t.Errorf("stats = %+v, want %d dropped and %d sent", s, tt.dropped, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestOutputQueueExpiry(t *testing.T) {
+	net := &fakeDMRNetwork{}
+	q := NewOutputQueue(net, 10, 100, DropOldest)
+
+	writeSeq(q, 0)
+	q.Clock(60)
+	writeSeq(q, 1)
+	q.Clock(60) // frame 0 is now 120ms old
+
+	if q.Len() != 1 || q.Stats().Expired != 1 {
+		t.Fatalf("queued = %d, stats = %+v", q.Len(), q.Stats())
+	}
+
+	// A write on reconnection delivers the queue ahead of the new frame
+	net.connected = true
+	if err := writeSeq(q, 2); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if len(net.written) != 2 || net.written[0] != 1 || net.written[1] != 2 {
+		t.Errorf("written = %v, want [1 2]", net.written)
+	}
+}
+
+func TestOutputQueueWriteError(t *testing.T) {
+	writeErr := errors.New("socket closed")
+	net := &fakeDMRNetwork{connected: true, writeErr: writeErr}
+	q := NewOutputQueue(net, 4, 1000, DropOldest)
+
+	if err := writeSeq(q, 0); err != writeErr {
+		t.Errorf("Write() error = %v, want %v", err, writeErr)
+	}
+	if s := q.Stats(); s.Dropped != 1 {
+		t.Errorf("stats = %+v, want 1 dropped", s)
+	}
+}
+
+func TestParseDropPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		want    DropPolicy
+		wantErr bool
+	}{
+		{"oldest", DropOldest, false},
+		{"Newest", DropNewest, false},
+		{"random", DropOldest, true},
+	} {
+		got, err := ParseDropPolicy(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseDropPolicy(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SOCKS5 protocol values (RFC 1928, RFC 1929)
+const (
+	socks5Version      = 0x05
+	socks5NoAuth       = 0x00
+	socks5UserPassword = 0x02
+	socks5NoAcceptable = 0xFF
+	socks5UDPAssociate = 0x03
+	socks5AddrIPv4     = 0x01
+	socks5AddrDomain   = 0x03
+	socks5AddrIPv6     = 0x04
+)
+
+// SOCKS5DialTimeout bounds connecting to the proxy and the UDP ASSOCIATE
+// handshake
+const SOCKS5DialTimeout = 10 * time.Second
+
+// SOCKS5Proxy is a SOCKS5 proxy that relays UDP (UDP ASSOCIATE)
+type SOCKS5Proxy struct {
+	Address  string // host:port
+	Username string // empty for no authentication
+	Password string
+}
+
+// ParseProxy parses a proxy URL of the form socks5://[user:password@]host:port
+// HTTP proxies are refused as they cannot carry UDP.
+func ParseProxy(proxyURL string) (*SOCKS5Proxy, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %v", proxyURL, err)
+	}
+
+	switch u.Scheme {
+	case "socks5":
+	case "http", "https":
+		return nil, fmt.Errorf("proxy %s: HTTP proxies cannot carry the UDP DMR traffic; use socks5:// or a local UDP forwarder", u.Host)
+	default:
+		return nil, fmt.Errorf("proxy %q: only socks5:// is supported", proxyURL)
+	}
+	if u.Hostname() == "" || u.Port() == "" {
+		return nil, fmt.Errorf("proxy %q: host and port are required", proxyURL)
+	}
+
+	proxy := &SOCKS5Proxy{Address: u.Host}
+	if u.User != nil {
+		proxy.Username = u.User.Username()
+		proxy.Password, _ = u.User.Password()
+	}
+	return proxy, nil
+}
+
+// socks5Association is an open UDP association: datagrams for any destination
+// are sent to the relay with a SOCKS5 header, and it lasts while the control
+// connection stays open
+type socks5Association struct {
+	control net.Conn
+	relay   *net.UDPAddr
+}
+
+// associate opens a UDP association on the proxy
+func (p *SOCKS5Proxy) associate() (*socks5Association, error) {
+	control, err := net.DialTimeout("tcp", p.Address, SOCKS5DialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("SOCKS5 proxy %s unreachable: %v", p.Address, err)
+	}
+	control.SetDeadline(time.Now().Add(SOCKS5DialTimeout))
+
+	relay, err := p.handshake(control)
+	if err != nil {
+		control.Close()
+		return nil, fmt.Errorf("SOCKS5 proxy %s: %v", p.Address, err)
+	}
+	control.SetDeadline(time.Time{})
+
+	// Servers listening on all addresses report 0.0.0.0 for the relay
+	if relay.IP.IsUnspecified() {
+		relay.IP = control.RemoteAddr().(*net.TCPAddr).IP
+	}
+
+	a := &socks5Association{control: control, relay: relay}
+	go a.watch(p.Address)
+	return a, nil
+}
+
+// handshake negotiates authentication and requests a UDP association,
+// returning the relay address
+func (p *SOCKS5Proxy) handshake(conn net.Conn) (*net.UDPAddr, error) {
+	methods := []byte{socks5Version, 1, socks5NoAuth}
+	if p.Username != "" {
+		methods = []byte{socks5Version, 2, socks5NoAuth, socks5UserPassword}
+	}
+	if _, err := conn.Write(methods); err != nil {
+		return nil, err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return nil, fmt.Errorf("no reply: %v", err)
+	}
+	if reply[0] != socks5Version {
+		return nil, fmt.Errorf("not a SOCKS5 proxy")
+	}
+
+	switch reply[1] {
+	case socks5NoAuth:
+	case socks5UserPassword:
+		if p.Username == "" {
+			return nil, fmt.Errorf("proxy requires a username and password")
+		}
+		if len(p.Username) > 255 || len(p.Password) > 255 {
+			return nil, fmt.Errorf("username or password too long")
+		}
+		auth := []byte{0x01, byte(len(p.Username))}
+		auth = append(auth, p.Username...)
+		auth = append(auth, byte(len(p.Password)))
+		auth = append(auth, p.Password...)
+		if _, err := conn.Write(auth); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return nil, fmt.Errorf("no authentication reply: %v", err)
+		}
+		if reply[1] != 0x00 {
+			return nil, fmt.Errorf("username or password rejected")
+		}
+	case socks5NoAcceptable:
+		return nil, fmt.Errorf("no acceptable authentication method")
+	default:
+		return nil, fmt.Errorf("unsupported authentication method %d", reply[1])
+	}
+
+	// The client's address is left unspecified as it may be behind NAT
+	request := []byte{socks5Version, socks5UDPAssociate, 0x00, socks5AddrIPv4, 0, 0, 0, 0, 0, 0}
+	if _, err := conn.Write(request); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 3)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, fmt.Errorf("no UDP ASSOCIATE reply: %v", err)
+	}
+	if header[1] != 0x00 {
+		return nil, fmt.Errorf("UDP ASSOCIATE refused (reply code %d)", header[1])
+	}
+	return readSOCKS5Addr(conn)
+}
+
+// watch logs when the proxy closes the control connection, ending the
+// association; the DMR link then goes down and reconnects
+func (a *socks5Association) watch(proxy string) {
+	buf := make([]byte, 1)
+	for {
+		if _, err := a.control.Read(buf); err != nil {
+			if err == io.EOF {
+				log.Printf("SOCKS5 proxy %s closed the UDP association", proxy)
+			}
+			return
+		}
+	}
+}
+
+// wrap adds the SOCKS5 UDP header addressing data to dst
+func (a *socks5Association) wrap(dst *net.UDPAddr, data []byte) []byte {
+	ip := dst.IP.To4()
+	atyp := byte(socks5AddrIPv4)
+	if ip == nil {
+		ip, atyp = dst.IP.To16(), socks5AddrIPv6
+	}
+
+	packet := make([]byte, 0, 4+len(ip)+2+len(data))
+	packet = append(packet, 0, 0, 0, atyp) // RSV, FRAG
+	packet = append(packet, ip...)
+	packet = binary.BigEndian.AppendUint16(packet, uint16(dst.Port))
+	return append(packet, data...)
+}
+
+// unwrap removes the SOCKS5 UDP header from a datagram from the relay,
+// returning the original sender and the data
+func (a *socks5Association) unwrap(packet []byte) (*net.UDPAddr, []byte, error) {
+	if len(packet) < 4 {
+		return nil, nil, fmt.Errorf("short SOCKS5 datagram")
+	}
+	if packet[2] != 0 {
+		return nil, nil, fmt.Errorf("fragmented SOCKS5 datagram")
+	}
+
+	var ipLen int
+	switch packet[3] {
+	case socks5AddrIPv4:
+		ipLen = net.IPv4len
+	case socks5AddrIPv6:
+		ipLen = net.IPv6len
+	default:
+		return nil, nil, fmt.Errorf("unsupported SOCKS5 address type %d", packet[3])
+	}
+	if len(packet) < 4+ipLen+2 {
+		return nil, nil, fmt.Errorf("short SOCKS5 datagram")
+	}
+
+	from := &net.UDPAddr{
+		IP:   net.IP(append([]byte(nil), packet[4:4+ipLen]...)),
+		Port: int(binary.BigEndian.Uint16(packet[4+ipLen:])),
+	}
+	return from, packet[4+ipLen+2:], nil
+}
+
+// receive unwraps a datagram received from addr, refusing any that did not
+// come through the relay
+func (a *socks5Association) receive(addr *net.UDPAddr, packet []byte) (*net.UDPAddr, []byte, error) {
+	if !addr.IP.Equal(a.relay.IP) || addr.Port != a.relay.Port {
+		return nil, nil, fmt.Errorf("datagram from %s bypassed the proxy", addr)
+	}
+	return a.unwrap(packet)
+}
+
+// Close ends the association
+func (a *socks5Association) Close() {
+	a.control.Close()
+}
+
+// readSOCKS5Addr reads an ATYP, address and port
+func readSOCKS5Addr(r io.Reader) (*net.UDPAddr, error) {
+	atyp := make([]byte, 1)
+	if _, err := io.ReadFull(r, atyp); err != nil {
+		return nil, err
+	}
+
+	var host string
+	switch atyp[0] {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		ip := make([]byte, net.IPv4len)
+		if atyp[0] == socks5AddrIPv6 {
+			ip = make([]byte, net.IPv6len)
+		}
+		if _, err := io.ReadFull(r, ip); err != nil {
+			return nil, err
+		}
+		host = net.IP(ip).String()
+	case socks5AddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(r, length); err != nil {
+			return nil, err
+		}
+		name := make([]byte, length[0])
+		if _, err := io.ReadFull(r, name); err != nil {
+			return nil, err
+		}
+		host = string(name)
+	default:
+		return nil, fmt.Errorf("unsupported address type %d", atyp[0])
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(r, port); err != nil {
+		return nil, err
+	}
+	return net.ResolveUDPAddr("udp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+}
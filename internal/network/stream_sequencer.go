@@ -0,0 +1,118 @@
+package network
+
+import "github.com/dbehnke/ysf2dmr/internal/protocol"
+
+// DEFAULT_REORDER_WINDOW is how many frames ahead of a missing one are held
+// waiting for it before it is given up as lost
+const DEFAULT_REORDER_WINDOW = 3
+
+// SequenceStats counts sequence problems in the current stream
+type SequenceStats struct {
+	Duplicates uint32 // frames already played or too late to play, dropped
+	Reordered  uint32 // frames that arrived early and were held
+	Gaps       uint32 // frames never received
+}
+
+// StreamSequencer delivers the frames of a DMR stream in sequence number
+// order, dropping duplicates and holding early frames for a small window
+// so UDP retransmission and reordering do not reach the codec
+type StreamSequencer struct {
+	window int
+
+	streamId uint32
+	started  bool
+	next     uint8 // sequence number expected next
+	pending  map[uint8]*protocol.DMRData
+	stats    SequenceStats
+}
+
+// NewStreamSequencer creates a sequencer holding up to window early frames
+func NewStreamSequencer(window int) *StreamSequencer {
+	if window < 1 {
+		window = 1
+	}
+	return &StreamSequencer{
+		window:  window,
+		pending: make(map[uint8]*protocol.DMRData),
+	}
+}
+
+// Push adds a received frame and returns the frames now ready, in order
+// The returned frames are copies owned by the caller. A frame of a new
+// stream starts the sequence again.
+func (s *StreamSequencer) Push(data *protocol.DMRData) []*protocol.DMRData {
+	seqNo := data.GetSeqNo()
+
+	if !s.started || data.GetStreamId() != s.streamId {
+		// Whatever is left of the previous stream goes first
+		ready := s.Flush()
+		s.Reset()
+		s.started = true
+		s.streamId = data.GetStreamId()
+		s.next = seqNo + 1
+		return append(ready, data.Copy())
+	}
+
+	// Distance ahead of the expected frame; anything behind is a repeat or
+	// has been given up on
+	ahead := int(int8(seqNo - s.next))
+	if ahead < 0 || s.pending[seqNo] != nil {
+		s.stats.Duplicates++
+		return nil
+	}
+
+	if ahead == 0 {
+		ready := []*protocol.DMRData{data.Copy()}
+		s.next++
+		return s.release(ready)
+	}
+
+	s.stats.Reordered++
+	s.pending[seqNo] = data.Copy()
+
+	// Give up on the missing frames once the window is full, or at the end
+	// of the stream when nothing more will arrive
+	if ahead >= s.window || data.IsTerminator() {
+		return s.Flush()
+	}
+	return nil
+}
+
+// release appends the held frames that now follow in sequence
+func (s *StreamSequencer) release(ready []*protocol.DMRData) []*protocol.DMRData {
+	for {
+		data := s.pending[s.next]
+		if data == nil {
+			return ready
+		}
+		delete(s.pending, s.next)
+		ready = append(ready, data)
+		s.next++
+	}
+}
+
+// Flush returns all held frames in order, counting the frames skipped over
+// as gaps
+func (s *StreamSequencer) Flush() []*protocol.DMRData {
+	var ready []*protocol.DMRData
+	for len(s.pending) > 0 {
+		for s.pending[s.next] == nil {
+			s.next++
+			s.stats.Gaps++
+		}
+		ready = s.release(ready)
+	}
+	return ready
+}
+
+// Reset forgets the current stream, discarding held frames
+func (s *StreamSequencer) Reset() {
+	s.started = false
+	s.pending = make(map[uint8]*protocol.DMRData)
+	s.stats = SequenceStats{}
+}
+
+// Stats returns the sequence counters of the current stream
+func (s *StreamSequencer) Stats() SequenceStats {
+	return s.stats
+}
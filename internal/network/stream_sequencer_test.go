@@ -0,0 +1,88 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+func sequencedFrame(streamId uint32, seqNo uint8, dataType uint8) *protocol.DMRData {
+	data := protocol.NewDMRData()
+	data.SetStreamId(streamId)
+	data.SetSeqNo(seqNo)
+	data.SetDataType(dataType)
+	return data
+}
+
+func TestStreamSequencer(t *testing.T) {
+	tests := []struct {
+		name  string
+		seqs  []uint8
+		want  []uint8
+		stats SequenceStats
+	}{
+		{"in order", []uint8{0, 1, 2, 3}, []uint8{0, 1, 2, 3}, SequenceStats{}},
+		{"wraps", []uint8{254, 255, 0, 1}, []uint8{254, 255, 0, 1}, SequenceStats{}},
+		{"duplicate", []uint8{0, 1, 1, 2, 0}, []uint8{0, 1, 2}, SequenceStats{Duplicates: 2}},
+		{"swapped", []uint8{0, 2, 1, 3}, []uint8{0, 1, 2, 3}, SequenceStats{Reordered: 1}},
+		{"held duplicate", []uint8{0, 2, 2, 1}, []uint8{0, 1, 2}, SequenceStats{Reordered: 1, Duplicates: 1}},
+		{"lost frame", []uint8{0, 2, 3, 4, 5}, []uint8{0, 2, 3, 4, 5}, SequenceStats{Reordered: 3, Gaps: 1}},
+		{"late after gap", []uint8{0, 2, 3, 4, 1, 5}, []uint8{0, 2, 3, 4, 5}, SequenceStats{Reordered: 3, Gaps: 1, Duplicates: 1}},
+		{"jump", []uint8{0, 10, 11}, []uint8{0, 10, 11}, SequenceStats{Reordered: 1, Gaps: 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStreamSequencer(DEFAULT_REORDER_WINDOW)
+			var got []uint8
+			for _, seqNo := range tt.seqs {
+				for _, data := range s.Push(sequencedFrame(1, seqNo, protocol.DT_VOICE)) {
+					got = append(got, data.GetSeqNo())
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("delivered %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("delivered %v, want %v", got, tt.want)
+				}
+			}
+			if s.Stats() != tt.stats {
+				t.Errorf("stats = %+v, want %+v", s.Stats(), tt.stats)
+			}
+		})
+	}
+}
+
+func TestStreamSequencerTerminatorFlushes(t *testing.T) {
+	s := NewStreamSequencer(DEFAULT_REORDER_WINDOW)
+	s.Push(sequencedFrame(1, 0, protocol.DT_VOICE_LC_HEADER))
+
+	// Frame 1 is lost; the terminator must not wait for it
+	if ready := s.Push(sequencedFrame(1, 2, protocol.DT_TERMINATOR_WITH_LC)); len(ready) != 1 || !ready[0].IsTerminator() {
+		t.Fatalf("terminator not delivered: %d frames", len(ready))
+	}
+	if s.Stats().Gaps != 1 {
+		t.Errorf("gaps = %d, want 1", s.Stats().Gaps)
+	}
+}
+
+func TestStreamSequencerNewStream(t *testing.T) {
+	s := NewStreamSequencer(DEFAULT_REORDER_WINDOW)
+	s.Push(sequencedFrame(1, 0, protocol.DT_VOICE))
+	s.Push(sequencedFrame(1, 2, protocol.DT_VOICE)) // held waiting for 1
+
+	ready := s.Push(sequencedFrame(2, 40, protocol.DT_VOICE_LC_HEADER))
+	if len(ready) != 2 || ready[0].GetStreamId() != 1 || ready[1].GetStreamId() != 2 {
+		t.Fatalf("new stream delivered %d frames", len(ready))
+	}
+	if s.Stats() != (SequenceStats{}) {
+		t.Errorf("stats not reset for the new stream: %+v", s.Stats())
+	}
+
+	if ready := s.Push(sequencedFrame(2, 41, protocol.DT_VOICE)); len(ready) != 1 {
+		t.Errorf("next frame of the new stream delivered %d frames", len(ready))
+	}
+}
@@ -1,18 +1,38 @@
 package network
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"sync/atomic"
 	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/capture"
+	"github.com/dbehnke/ysf2dmr/internal/trace"
 )
 
+// udpReadQueue is how many received packets wait for Read
+const udpReadQueue = 64
+
 // UDPSocket provides non-blocking UDP I/O operations equivalent to C++ CUDPSocket
 type UDPSocket struct {
 	conn      *net.UDPConn
 	address   string
 	port      int
 	localAddr *net.UDPAddr
+	open      atomic.Bool // for IsOpen from other goroutines
+
+	tracer    *trace.Tracer // nil unless packets are traced
+	traceName string        // network name in the trace
+	capture   *capture.Ring // nil unless recent packets are kept
+	capName   string        // network name in the capture
+
+	queue  chan Datagram   // packets for Read, once it has started receiving
+	notify chan<- struct{} // signalled as packets are queued for Read, if not nil
+
+	proxy *SOCKS5Proxy       // nil sends directly
+	relay *socks5Association // open while the socket is open through proxy
 }
 
 // NewUDPSocket creates a UDP socket with specific address and port (client mode)
@@ -34,26 +54,18 @@ func NewUDPSocketServer(port int) *UDPSocket {
 // Open creates the UDP socket with C++ equivalent binding behavior
 // Equivalent to C++ CUDPSocket::open()
 func (s *UDPSocket) Open() error {
-	var err error
+	// The bind address pins the interface even when the port is left to the OS
+	ip, err := BindIP(s.address)
+	if err != nil {
+		return err
+	}
 
 	// C++ behavior: Only bind if port > 0, otherwise create unbound socket
 	if s.port > 0 {
 		// Bind to specific port (server mode or configured client)
-		if s.address == "" {
-			// Bind to any address (server mode)
-			s.localAddr = &net.UDPAddr{
-				IP:   net.IPv4zero,
-				Port: s.port,
-			}
-		} else {
-			// Bind to specific address and port
-			s.localAddr = &net.UDPAddr{
-				IP:   net.ParseIP(s.address),
-				Port: s.port,
-			}
-			if s.localAddr.IP == nil {
-				return fmt.Errorf("invalid address: %s", s.address)
-			}
+		s.localAddr = &net.UDPAddr{
+			IP:   ip,
+			Port: s.port,
 		}
 
 		// Create bound UDP socket with SO_REUSEADDR equivalent, force IPv4
@@ -68,7 +80,7 @@ func (s *UDPSocket) Open() error {
 		// Create unbound socket (client mode with ephemeral port)
 		// This matches C++ behavior when m_port == 0
 		s.localAddr = &net.UDPAddr{
-			IP:   net.IPv4zero,
+			IP:   ip,
 			Port: 0, // Let OS assign ephemeral port on first send
 		}
 
@@ -88,31 +100,120 @@ func (s *UDPSocket) Open() error {
 		return err
 	}
 
+	if s.proxy != nil {
+		s.relay, err = s.proxy.associate()
+		if err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return err
+		}
+		log.Printf("UDP socket relayed through SOCKS5 proxy %s (relay %s)", s.proxy.Address, s.relay.relay)
+	}
+
+	s.open.Store(true)
 	return nil
 }
 
+// IsOpen reports whether the socket is open; safe to call from any goroutine
+func (s *UDPSocket) IsOpen() bool {
+	return s.open.Load()
+}
+
+// LocalAddr returns the address the socket is bound to, nil when closed
+func (s *UDPSocket) LocalAddr() *net.UDPAddr {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.LocalAddr().(*net.UDPAddr)
+}
+
 // Read performs non-blocking read operation
 // Equivalent to C++ CUDPSocket::read() with select() and zero timeout
 // Returns: bytes read (>0), 0 if no data available, -1 on error
+// Packets are read by a Receive goroutine started on the first call, as a
+// read with an expired deadline never returns queued datagrams.
 func (s *UDPSocket) Read(buffer []byte) (int, *net.UDPAddr, error) {
 	if s.conn == nil {
 		return -1, nil, fmt.Errorf("socket not open")
 	}
 
-	// Set immediate timeout for non-blocking behavior
-	s.conn.SetReadDeadline(time.Now())
-
-	n, addr, err := s.conn.ReadFromUDP(buffer)
-	if err != nil {
-		// Check if it's a timeout (no data available)
-		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-			return 0, nil, nil // No data available (equivalent to C++ select() timeout)
+	if s.queue == nil {
+		s.queue = make(chan Datagram, udpReadQueue)
+		if err := s.Receive(s.queue, s.notify); err != nil {
+			s.queue = nil
+			return -1, nil, err
 		}
-		log.Printf("UDP read error: %v", err)
-		return -1, nil, err
 	}
 
-	return n, addr, nil
+	select {
+	case packet := <-s.queue:
+		return copy(buffer, packet.Data), packet.From, nil
+	default:
+		return 0, nil, nil // No data available (equivalent to C++ select() timeout)
+	}
+}
+
+// Notify signals ready without blocking whenever a packet is queued for
+// Read, so the caller can wait for packets instead of polling
+// Must be called before the first Read.
+func (s *UDPSocket) Notify(ready chan<- struct{}) {
+	s.notify = ready
+}
+
+// Datagram is a packet received by Receive
+type Datagram struct {
+	Data []byte
+	From *net.UDPAddr
+}
+
+// Receive reads the open socket on a goroutine with blocking reads, so an
+// idle socket costs no CPU
+// Each packet is queued on packets, dropped when it is full, and ready (if
+// not nil) is signalled without blocking. The goroutine ends when the socket is closed.
+// Read must not be used on the socket afterwards.
+func (s *UDPSocket) Receive(packets chan<- Datagram, ready chan<- struct{}) error {
+	conn, relay := s.conn, s.relay
+	local := conn.LocalAddr()
+	if conn == nil {
+		return fmt.Errorf("socket not open")
+	}
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return err
+	}
+
+	go func() {
+		buffer := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFromUDP(buffer)
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					log.Printf("UDP read error: %v", err)
+				}
+				return
+			}
+
+			packet := buffer[:n]
+			if relay != nil {
+				if addr, packet, err = relay.receive(addr, packet); err != nil {
+					continue
+				}
+			}
+			s.tracer.Record(s.traceName, trace.Received, addr, packet)
+			s.capture.Record(s.capName, trace.Received, local, addr, packet)
+
+			data := append([]byte(nil), packet...)
+			select {
+			case packets <- Datagram{Data: data, From: addr}:
+			default:
+				log.Printf("UDP receive queue full, dropping %d bytes from %s", len(data), addr)
+			}
+			select {
+			case ready <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return nil
 }
 
 // Write sends data to specified address and port
@@ -122,21 +223,52 @@ func (s *UDPSocket) Write(buffer []byte, addr *net.UDPAddr) error {
 		return fmt.Errorf("socket not open")
 	}
 
-	_, err := s.conn.WriteToUDP(buffer, addr)
+	var err error
+	if s.relay != nil {
+		_, err = s.conn.WriteToUDP(s.relay.wrap(addr, buffer), s.relay.relay)
+	} else {
+		_, err = s.conn.WriteToUDP(buffer, addr)
+	}
 	if err != nil {
 		log.Printf("UDP write error: %v", err)
 		return err
 	}
 
+	s.tracer.Record(s.traceName, trace.Sent, addr, buffer)
+	s.capture.Record(s.capName, trace.Sent, s.conn.LocalAddr(), addr, buffer)
 	return nil
 }
 
+// SetTrace records the packets sent and received in t under network name
+// (see trace.Describe); nil stops tracing
+func (s *UDPSocket) SetTrace(t *trace.Tracer, name string) {
+	s.tracer, s.traceName = t, name
+}
+
+// SetCapture keeps the packets sent and received in r under network name;
+// nil stops capturing
+func (s *UDPSocket) SetCapture(r *capture.Ring, name string) {
+	s.capture, s.capName = r, name
+}
+
+// SetProxy sends and receives through a SOCKS5 proxy's UDP relay from the
+// next Open; nil sends directly
+func (s *UDPSocket) SetProxy(p *SOCKS5Proxy) {
+	s.proxy = p
+}
+
 // Close closes the UDP socket
 // Equivalent to C++ CUDPSocket::close()
 func (s *UDPSocket) Close() {
 	if s.conn != nil {
+		s.open.Store(false)
 		s.conn.Close()
 		s.conn = nil
+		s.queue = nil
+		if s.relay != nil {
+			s.relay.Close()
+			s.relay = nil
+		}
 		log.Printf("UDP socket closed")
 	}
 }
@@ -165,6 +297,33 @@ func Lookup(hostname string) (net.IP, error) {
 	return nil, fmt.Errorf("no IPv4 address found for %s", hostname)
 }
 
+// BindIP returns the local address to bind for address, which is an IPv4
+// address, a network interface name such as "wg0" (its first IPv4 address) or
+// empty for any address
+func BindIP(address string) (net.IP, error) {
+	if address == "" {
+		return net.IPv4zero, nil
+	}
+	if ip := net.ParseIP(address); ip != nil {
+		return ip, nil
+	}
+
+	iface, err := net.InterfaceByName(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bind address %s: not an IP address or interface", address)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("interface %s: %v", address, err)
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+			return ipNet.IP.To4(), nil
+		}
+	}
+	return nil, fmt.Errorf("interface %s has no IPv4 address", address)
+}
+
 // ParseUDPAddr convenience function to parse address:port strings
 func ParseUDPAddr(address string, port int) (*net.UDPAddr, error) {
 	ip, err := Lookup(address)
@@ -176,4 +335,4 @@ func ParseUDPAddr(address string, port int) (*net.UDPAddr, error) {
 		IP:   ip,
 		Port: port,
 	}, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,42 @@
+package network
+
+import (
+	"net"
+	"testing"
+)
+
+func TestBindIP(t *testing.T) {
+	if ip, err := BindIP(""); err != nil || !ip.Equal(net.IPv4zero) {
+		t.Errorf("BindIP(\"\") = %v, %v, want any address", ip, err)
+	}
+	if ip, err := BindIP("192.0.2.1"); err != nil || !ip.Equal(net.ParseIP("192.0.2.1")) {
+		t.Errorf("BindIP(192.0.2.1) = %v, %v", ip, err)
+	}
+	if _, err := BindIP("nosuchif0"); err == nil {
+		t.Error("BindIP() accepted an unknown interface")
+	}
+
+	// The loopback interface is named lo or lo0 depending on the OS
+	interfaces, _ := net.Interfaces()
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagLoopback == 0 {
+			continue
+		}
+		if ip, err := BindIP(iface.Name); err != nil || !ip.IsLoopback() {
+			t.Errorf("BindIP(%s) = %v, %v, want a loopback address", iface.Name, ip, err)
+		}
+		break
+	}
+}
+
+func TestUDPSocketBindAddress(t *testing.T) {
+	socket := NewUDPSocket("127.0.0.1", 0)
+	if err := socket.Open(); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer socket.Close()
+
+	if local := socket.conn.LocalAddr().(*net.UDPAddr); !local.IP.IsLoopback() || local.Port == 0 {
+		t.Errorf("bound to %s, want 127.0.0.1 with an ephemeral port", local)
+	}
+}
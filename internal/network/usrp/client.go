@@ -0,0 +1,184 @@
+package usrp
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sync/atomic"
+
+	"github.com/dbehnke/ysf2dmr/internal/network"
+)
+
+// Client exchanges USRP packets with Analog_Bridge or an AllStar node
+type Client struct {
+	address *net.UDPAddr
+	socket  *network.UDPSocket
+	buffer  []byte
+	debug   bool
+	open    bool
+	ready   chan struct{} // signalled when packets are waiting for Read
+
+	seqNo        uint32
+	talkGroup    uint32
+	transmitting bool
+
+	// Statistics, read while packets are sent and received on other goroutines
+	rxPackets atomic.Uint32
+	txPackets atomic.Uint32
+	rxErrors  atomic.Uint32
+}
+
+// NewClient creates a USRP client sending to address:port and listening on localPort
+func NewClient(address string, port int, localPort int, debug bool) (*Client, error) {
+	addr, err := network.ParseUDPAddr(address, port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve USRP address %s: %v", address, err)
+	}
+
+	client := &Client{
+		address: addr,
+		socket:  network.NewUDPSocket("", localPort),
+		buffer:  make([]byte, 1024),
+		debug:   debug,
+		ready:   make(chan struct{}, 1),
+	}
+	client.socket.Notify(client.ready)
+	return client, nil
+}
+
+// Open opens the UDP socket
+func (c *Client) Open() error {
+	if c.debug {
+		log.Printf("Opening USRP connection to %s", c.address)
+	}
+
+	if err := c.socket.Open(); err != nil {
+		return err
+	}
+
+	c.open = true
+	return nil
+}
+
+// Close closes the UDP socket, unkeying first if a transmission is active
+func (c *Client) Close() {
+	if !c.open {
+		return
+	}
+
+	if c.transmitting {
+		c.WriteEnd()
+	}
+
+	c.socket.Close()
+	c.open = false
+}
+
+// IsOpen returns true when the socket is open
+func (c *Client) IsOpen() bool {
+	return c.open
+}
+
+// SetTalkGroup sets the talkgroup stamped on outgoing packets
+func (c *Client) SetTalkGroup(tg uint32) {
+	c.talkGroup = tg
+}
+
+// WriteAudio sends one 20ms frame of PCM audio with PTT keyed
+func (c *Client) WriteAudio(samples []int16) error {
+	c.transmitting = true
+	return c.write(&Packet{
+		Keyup:     true,
+		TalkGroup: c.talkGroup,
+		Type:      TYPE_VOICE,
+		Audio:     samples,
+	})
+}
+
+// WriteEnd sends the unkey packet that ends a transmission
+func (c *Client) WriteEnd() error {
+	c.transmitting = false
+	return c.write(&Packet{
+		Keyup:     false,
+		TalkGroup: c.talkGroup,
+		Type:      TYPE_VOICE,
+	})
+}
+
+// WriteText sends a metadata text packet (e.g. callsign information)
+func (c *Client) WriteText(text string) error {
+	payload := append([]byte(text), 0) // NUL terminated
+	return c.write(&Packet{
+		TalkGroup: c.talkGroup,
+		Type:      TYPE_TEXT,
+		Payload:   payload,
+	})
+}
+
+// Read returns the next received packet, false if none is available
+func (c *Client) Read(packet *Packet) bool {
+	if !c.open {
+		return false
+	}
+
+	for {
+		bytesRead, fromAddr, err := c.socket.Read(c.buffer)
+		if err != nil {
+			if c.debug {
+				log.Printf("USRP socket read error: %v", err)
+			}
+			return false
+		}
+
+		if bytesRead == 0 {
+			return false
+		}
+
+		// Only the peer we send to is heard, so another process on its
+		// host cannot key the bridge
+		if !fromAddr.IP.Equal(c.address.IP) || fromAddr.Port != c.address.Port {
+			if c.debug {
+				log.Printf("USRP: Ignoring packet from unexpected source: %s", fromAddr)
+			}
+			continue
+		}
+
+		if err := packet.Unmarshal(c.buffer[:bytesRead]); err != nil {
+			c.rxErrors.Add(1)
+			if c.debug {
+				log.Printf("USRP: %v", err)
+			}
+			continue
+		}
+
+		c.rxPackets.Add(1)
+		return true
+	}
+}
+
+// Ready is signalled when packets are waiting for Read, once Read has been
+// called
+func (c *Client) Ready() <-chan struct{} {
+	return c.ready
+}
+
+// GetStats returns packet counters (received, transmitted, malformed)
+func (c *Client) GetStats() (rx, tx, errors uint32) {
+	return c.rxPackets.Load(), c.txPackets.Load(), c.rxErrors.Load()
+}
+
+func (c *Client) write(packet *Packet) error {
+	if !c.open {
+		return fmt.Errorf("USRP client not open")
+	}
+
+	packet.SeqNo = c.seqNo
+	c.seqNo++
+
+	if err := c.socket.Write(packet.Marshal(), c.address); err != nil {
+		return err
+	}
+
+	c.txPackets.Add(1)
+	return nil
+}
@@ -0,0 +1,123 @@
+package usrp
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// openClient opens a client whose peer is a socket on the loopback address
+func openClient(t *testing.T) (*Client, *net.UDPConn) {
+	t.Helper()
+	peer, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP() error = %v", err)
+	}
+	t.Cleanup(func() { peer.Close() })
+
+	client, err := NewClient("127.0.0.1", peer.LocalAddr().(*net.UDPAddr).Port, 0, false)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if err := client.Open(); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client, peer
+}
+
+// readClient waits for the client to read a packet
+func readClient(client *Client) (*Packet, bool) {
+	var packet Packet
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if client.Read(&packet) {
+			return &packet, true
+		}
+	}
+	return nil, false
+}
+
+// readPeer reads the next packet the client sent to peer
+func readPeer(t *testing.T, peer *net.UDPConn) *Packet {
+	t.Helper()
+	buffer := make([]byte, 1024)
+	peer.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := peer.ReadFromUDP(buffer)
+	if err != nil {
+		t.Fatalf("peer read error = %v", err)
+	}
+	var packet Packet
+	if err := packet.Unmarshal(buffer[:n]); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return &packet
+}
+
+func TestClient_ReadOnlyFromPeer(t *testing.T) {
+	client, peer := openClient(t)
+	local := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: client.socket.LocalAddr().Port}
+
+	// Same host, other port
+	other, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP() error = %v", err)
+	}
+	defer other.Close()
+	stray := &Packet{Keyup: true, TalkGroup: 1, Type: TYPE_VOICE}
+	if _, err := other.WriteToUDP(stray.Marshal(), local); err != nil {
+		t.Fatalf("write error = %v", err)
+	}
+	if _, ok := readClient(client); ok {
+		t.Fatal("Read() returned a packet from another port on the peer's host")
+	}
+
+	// Malformed packets from the peer are counted and skipped
+	peer.WriteToUDP([]byte("not usrp"), local)
+	voice := &Packet{SeqNo: 7, Keyup: true, TalkGroup: 91, Type: TYPE_VOICE, Audio: make([]int16, SAMPLES_PER_FRAME)}
+	peer.WriteToUDP(voice.Marshal(), local)
+
+	packet, ok := readClient(client)
+	if !ok {
+		t.Fatal("Read() returned nothing from the peer")
+	}
+	if packet.SeqNo != 7 || !packet.Keyup || packet.TalkGroup != 91 || len(packet.Audio) != SAMPLES_PER_FRAME {
+		t.Errorf("Read() = %+v, want the peer's voice packet", packet)
+	}
+	if rx, _, rxErrors := client.GetStats(); rx != 1 || rxErrors != 1 {
+		t.Errorf("stats = %d received, %d malformed, want 1 and 1", rx, rxErrors)
+	}
+}
+
+func TestClient_Write(t *testing.T) {
+	client, peer := openClient(t)
+	client.SetTalkGroup(91)
+
+	audio := make([]int16, SAMPLES_PER_FRAME)
+	audio[0] = 1234
+	if err := client.WriteAudio(audio); err != nil {
+		t.Fatalf("WriteAudio() error = %v", err)
+	}
+	packet := readPeer(t, peer)
+	if packet.SeqNo != 0 || !packet.Keyup || packet.TalkGroup != 91 || packet.Type != TYPE_VOICE || packet.Audio[0] != 1234 {
+		t.Errorf("voice packet = %+v", packet)
+	}
+
+	if err := client.WriteText("N0CALL"); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	if packet := readPeer(t, peer); packet.SeqNo != 1 || packet.Type != TYPE_TEXT || string(packet.Payload) != "N0CALL\x00" {
+		t.Errorf("text packet = %+v", packet)
+	}
+
+	// Closing during a transmission unkeys first
+	client.Close()
+	if packet := readPeer(t, peer); packet.SeqNo != 2 || packet.Keyup || packet.Type != TYPE_VOICE {
+		t.Errorf("packet on close = %+v, want the unkey", packet)
+	}
+	if err := client.WriteEnd(); err == nil {
+		t.Error("WriteEnd() succeeded after Close()")
+	}
+	if _, tx, _ := client.GetStats(); tx != 3 {
+		t.Errorf("%d packets sent, want 3", tx)
+	}
+}
@@ -0,0 +1,32 @@
+package usrp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzPacketUnmarshal(f *testing.F) {
+	f.Add((&Packet{SeqNo: 1, Keyup: true, TalkGroup: 91, Type: TYPE_VOICE, Audio: make([]int16, SAMPLES_PER_FRAME)}).Marshal())
+	f.Add((&Packet{SeqNo: 2, Type: TYPE_VOICE}).Marshal()[:HEADER_LENGTH])
+	f.Add((&Packet{SeqNo: 3, Type: TYPE_TEXT, Payload: []byte("N0CALL")}).Marshal())
+	f.Add((&Packet{SeqNo: 4, Type: TYPE_PING}).Marshal())
+
+	f.Fuzz(func(t *testing.T, buffer []byte) {
+		var p Packet
+		if err := p.Unmarshal(buffer); err != nil {
+			return
+		}
+		if p.Type == TYPE_VOICE && p.Audio != nil && len(p.Audio) != SAMPLES_PER_FRAME {
+			t.Fatalf("len(Audio) = %d, want %d", len(p.Audio), SAMPLES_PER_FRAME)
+		}
+
+		var again Packet
+		if err := again.Unmarshal(p.Marshal()); err != nil {
+			t.Fatalf("Unmarshal(Marshal()) error = %v", err)
+		}
+		if again.SeqNo != p.SeqNo || again.Keyup != p.Keyup || again.TalkGroup != p.TalkGroup ||
+			again.Type != p.Type || again.MpxId != p.MpxId || !bytes.Equal(again.Payload, p.Payload) {
+			t.Errorf("round trip = %+v, want %+v", again, p)
+		}
+	})
+}
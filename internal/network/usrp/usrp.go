@@ -0,0 +1,140 @@
+// Package usrp implements the DVSwitch USRP audio protocol used by
+// Analog_Bridge and AllStar (chan_usrp) to exchange 8kHz PCM audio over UDP.
+package usrp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// USRP packet constants
+const (
+	HEADER_LENGTH     = 32                                     // USRP header size
+	SAMPLES_PER_FRAME = 160                                    // 20ms of 8kHz audio
+	AUDIO_LENGTH      = SAMPLES_PER_FRAME * 2                  // 16-bit little-endian samples
+	VOICE_LENGTH      = HEADER_LENGTH + AUDIO_LENGTH           // Full voice packet size
+	SAMPLE_RATE       = 8000                                   // PCM sample rate in Hz
+	FRAME_TIME        = SAMPLES_PER_FRAME * 1000 / SAMPLE_RATE // Frame duration in milliseconds
+
+	MAGIC = "USRP"
+)
+
+// PacketType identifies the USRP payload
+type PacketType uint32
+
+const (
+	TYPE_VOICE       PacketType = 0 // Signed linear PCM
+	TYPE_DTMF        PacketType = 1 // DTMF digit
+	TYPE_TEXT        PacketType = 2 // Metadata text
+	TYPE_PING        PacketType = 3 // Keepalive
+	TYPE_TLV         PacketType = 4 // Tag/length/value metadata
+	TYPE_VOICE_ADPCM PacketType = 5 // ADPCM voice (unsupported)
+	TYPE_VOICE_ULAW  PacketType = 6 // u-law voice (unsupported)
+)
+
+func (t PacketType) String() string {
+	switch t {
+	case TYPE_VOICE:
+		return "VOICE"
+	case TYPE_DTMF:
+		return "DTMF"
+	case TYPE_TEXT:
+		return "TEXT"
+	case TYPE_PING:
+		return "PING"
+	case TYPE_TLV:
+		return "TLV"
+	case TYPE_VOICE_ADPCM:
+		return "VOICE_ADPCM"
+	case TYPE_VOICE_ULAW:
+		return "VOICE_ULAW"
+	default:
+		return fmt.Sprintf("UNKNOWN(%d)", uint32(t))
+	}
+}
+
+// Packet represents a single USRP packet
+type Packet struct {
+	SeqNo     uint32
+	Memory    uint32
+	Keyup     bool // PTT state; false on the final (unkey) packet
+	TalkGroup uint32
+	Type      PacketType
+	MpxId     uint32
+
+	Audio   []int16 // PCM samples for TYPE_VOICE
+	Payload []byte  // Raw payload for non-voice types
+}
+
+// Marshal encodes the packet to its wire format
+func (p *Packet) Marshal() []byte {
+	var payloadLen int
+	if p.Type == TYPE_VOICE {
+		payloadLen = AUDIO_LENGTH
+	} else {
+		payloadLen = len(p.Payload)
+	}
+
+	buffer := make([]byte, HEADER_LENGTH+payloadLen)
+	copy(buffer[0:4], MAGIC)
+	binary.BigEndian.PutUint32(buffer[4:8], p.SeqNo)
+	binary.BigEndian.PutUint32(buffer[8:12], p.Memory)
+	if p.Keyup {
+		binary.BigEndian.PutUint32(buffer[12:16], 1)
+	}
+	binary.BigEndian.PutUint32(buffer[16:20], p.TalkGroup)
+	binary.BigEndian.PutUint32(buffer[20:24], uint32(p.Type))
+	binary.BigEndian.PutUint32(buffer[24:28], p.MpxId)
+	// Bytes 28-31 are reserved
+
+	if p.Type == TYPE_VOICE {
+		// Short frames are padded with silence
+		for i := 0; i < SAMPLES_PER_FRAME && i < len(p.Audio); i++ {
+			binary.LittleEndian.PutUint16(buffer[HEADER_LENGTH+i*2:], uint16(p.Audio[i]))
+		}
+	} else {
+		copy(buffer[HEADER_LENGTH:], p.Payload)
+	}
+
+	return buffer
+}
+
+// Unmarshal decodes a packet from its wire format
+func (p *Packet) Unmarshal(buffer []byte) error {
+	if len(buffer) < HEADER_LENGTH {
+		return fmt.Errorf("USRP packet too short: %d bytes", len(buffer))
+	}
+	if string(buffer[0:4]) != MAGIC {
+		return fmt.Errorf("invalid USRP magic: %q", string(buffer[0:4]))
+	}
+
+	p.SeqNo = binary.BigEndian.Uint32(buffer[4:8])
+	p.Memory = binary.BigEndian.Uint32(buffer[8:12])
+	p.Keyup = binary.BigEndian.Uint32(buffer[12:16]) != 0
+	p.TalkGroup = binary.BigEndian.Uint32(buffer[16:20])
+	p.Type = PacketType(binary.BigEndian.Uint32(buffer[20:24]))
+	p.MpxId = binary.BigEndian.Uint32(buffer[24:28])
+	p.Audio = nil
+	p.Payload = nil
+
+	payload := buffer[HEADER_LENGTH:]
+
+	if p.Type == TYPE_VOICE {
+		// The unkey packet may carry no audio
+		if len(payload) == 0 {
+			return nil
+		}
+		if len(payload) != AUDIO_LENGTH {
+			return fmt.Errorf("invalid USRP voice payload length: %d", len(payload))
+		}
+		p.Audio = make([]int16, SAMPLES_PER_FRAME)
+		for i := range p.Audio {
+			p.Audio[i] = int16(binary.LittleEndian.Uint16(payload[i*2:]))
+		}
+		return nil
+	}
+
+	p.Payload = make([]byte, len(payload))
+	copy(p.Payload, payload)
+	return nil
+}
@@ -0,0 +1,103 @@
+package usrp
+
+import (
+	"testing"
+)
+
+func TestPacket_VoiceRoundTrip(t *testing.T) {
+	audio := make([]int16, SAMPLES_PER_FRAME)
+	for i := range audio {
+		audio[i] = int16(i*200 - 16000)
+	}
+
+	sent := &Packet{
+		SeqNo:     42,
+		Keyup:     true,
+		TalkGroup: 91,
+		Type:      TYPE_VOICE,
+		Audio:     audio,
+	}
+
+	buffer := sent.Marshal()
+	if len(buffer) != VOICE_LENGTH {
+		t.Fatalf("Marshal() length = %d, want %d", len(buffer), VOICE_LENGTH)
+	}
+
+	var received Packet
+	if err := received.Unmarshal(buffer); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if received.SeqNo != 42 || !received.Keyup || received.TalkGroup != 91 || received.Type != TYPE_VOICE {
+		t.Errorf("header mismatch: %+v", received)
+	}
+	for i := range audio {
+		if received.Audio[i] != audio[i] {
+			t.Fatalf("sample %d = %d, want %d", i, received.Audio[i], audio[i])
+		}
+	}
+}
+
+func TestPacket_ShortAudioPadded(t *testing.T) {
+	p := &Packet{Type: TYPE_VOICE, Keyup: true, Audio: []int16{1, 2, 3}}
+
+	var received Packet
+	if err := received.Unmarshal(p.Marshal()); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(received.Audio) != SAMPLES_PER_FRAME {
+		t.Fatalf("audio length = %d, want %d", len(received.Audio), SAMPLES_PER_FRAME)
+	}
+	if received.Audio[2] != 3 || received.Audio[3] != 0 {
+		t.Error("short frame not padded with silence")
+	}
+}
+
+func TestPacket_Unkey(t *testing.T) {
+	p := &Packet{Type: TYPE_VOICE, Keyup: false}
+	buffer := p.Marshal()
+
+	// Analog_Bridge sends the unkey as a header-only packet
+	var received Packet
+	if err := received.Unmarshal(buffer[:HEADER_LENGTH]); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if received.Keyup {
+		t.Error("Keyup = true, want false")
+	}
+	if received.Audio != nil {
+		t.Error("unkey packet should carry no audio")
+	}
+}
+
+func TestPacket_UnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		buffer []byte
+	}{
+		{"too short", []byte("USRP")},
+		{"bad magic", make([]byte, VOICE_LENGTH)},
+		{"bad voice length", append((&Packet{Type: TYPE_VOICE}).Marshal(), 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Packet
+			if err := p.Unmarshal(tt.buffer); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestPacket_Text(t *testing.T) {
+	sent := &Packet{Type: TYPE_TEXT, Payload: []byte("N0CALL\x00")}
+
+	var received Packet
+	if err := received.Unmarshal(sent.Marshal()); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if string(received.Payload) != "N0CALL\x00" {
+		t.Errorf("payload = %q", received.Payload)
+	}
+}
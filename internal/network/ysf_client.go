@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/supervisor"
+	"github.com/dbehnke/ysf2dmr/internal/trace"
 )
 
 // YSFPacket represents a received YSF packet with metadata
@@ -27,6 +29,7 @@ type YSFClient struct {
 	// Network
 	conn       *net.UDPConn
 	serverAddr *net.UDPAddr
+	tracer     *trace.Tracer // nil unless packets are traced
 
 	// Pre-built messages
 	pollMsg   []byte
@@ -44,6 +47,10 @@ type YSFClient struct {
 	// Sync
 	mu      sync.RWMutex
 	running bool
+
+	// Goroutines are restarted if they panic
+	supervisor *supervisor.Supervisor
+	cancel     context.CancelFunc
 }
 
 // YSFConfig holds YSF client configuration
@@ -74,6 +81,8 @@ func NewYSFClient(config *YSFConfig, debug bool) (*YSFClient, error) {
 		outbound: make(chan []byte, 10),
 		events:   make(chan string, 10),
 		shutdown: make(chan struct{}),
+
+		supervisor: supervisor.New("YSF client"),
 	}
 
 	// Initialize pre-built messages
@@ -98,12 +107,15 @@ func (c *YSFClient) Start(ctx context.Context) error {
 	c.mu.Unlock()
 
 	// Bind UDP socket
+	ip, err := BindIP(c.config.LocalAddress)
+	if err != nil {
+		return err
+	}
 	localAddr := &net.UDPAddr{
-		IP:   net.ParseIP(c.config.LocalAddress),
+		IP:   ip,
 		Port: c.config.LocalPort,
 	}
 
-	var err error
 	c.conn, err = net.ListenUDP("udp4", localAddr)
 	if err != nil {
 		return fmt.Errorf("failed to bind YSF socket: %v", err)
@@ -113,18 +125,20 @@ func (c *YSFClient) Start(ctx context.Context) error {
 		log.Printf("YSF Client bound to %s", c.conn.LocalAddr().String())
 	}
 
-	// Start goroutines
-	go c.networkReader(ctx)
-	go c.networkWriter(ctx)
-	go c.keepAliveManager(ctx)
+	// Start goroutines; the socket is closed once the reader stops for good
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.supervisor.Go(ctx, "network reader", func(ctx context.Context) {
+		c.networkReader(ctx)
+		c.conn.Close()
+	})
+	c.supervisor.Go(ctx, "network writer", c.networkWriter)
+	c.supervisor.Go(ctx, "keep alive manager", c.keepAliveManager)
 
 	return nil
 }
 
 // networkReader goroutine - handles incoming packets with blocking reads
 func (c *YSFClient) networkReader(ctx context.Context) {
-	defer c.conn.Close()
-
 	buffer := make([]byte, protocol.BUFFER_LENGTH)
 
 	for {
@@ -159,6 +173,8 @@ func (c *YSFClient) networkReader(ctx context.Context) {
 				}
 			}
 
+			c.tracer.Record("YSF", trace.Received, fromAddr, buffer[:n])
+
 			// Send packet to processing channel
 			packetData := make([]byte, n)
 			copy(packetData, buffer[:n])
@@ -200,7 +216,9 @@ func (c *YSFClient) networkWriter(ctx context.Context) {
 					}
 					// Signal connection problem
 					c.events <- "WRITE_ERROR"
+					continue
 				}
+				c.tracer.Record("YSF", trace.Sent, c.serverAddr, packet)
 			}
 		}
 	}
@@ -224,6 +242,12 @@ func (c *YSFClient) keepAliveManager(ctx context.Context) {
 	}
 }
 
+// SetTrace records the packets exchanged with the server in t
+// Must be called before Start.
+func (c *YSFClient) SetTrace(t *trace.Tracer) {
+	c.tracer = t
+}
+
 // GetInbound returns the inbound packet channel for external processing
 func (c *YSFClient) GetInbound() <-chan *YSFPacket {
 	return c.inbound
@@ -234,6 +258,11 @@ func (c *YSFClient) GetEvents() <-chan string {
 	return c.events
 }
 
+// Panics returns the number of panics recovered in the client goroutines
+func (c *YSFClient) Panics() uint64 {
+	return c.supervisor.Panics()
+}
+
 // Stop gracefully shuts down the YSF client
 func (c *YSFClient) Stop() {
 	c.mu.Lock()
@@ -244,6 +273,9 @@ func (c *YSFClient) Stop() {
 	}
 
 	close(c.shutdown)
+	if c.cancel != nil {
+		c.cancel()
+	}
 	c.running = false
 
 	if c.pollTimer != nil {
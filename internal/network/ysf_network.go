@@ -5,33 +5,53 @@ import (
 	"log"
 	"net"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/dbehnke/ysf2dmr/internal/capture"
 	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/trace"
 )
 
+// ysfReceiveQueue is how many received packets wait for Clock; a second of
+// YSF frames with room for polls
+const ysfReceiveQueue = 64
+
 // YSFNetwork provides YSF network communication equivalent to C++ CYSFNetwork
 type YSFNetwork struct {
-	callsign    string        // 10-byte callsign (space-padded)
-	socket      *UDPSocket    // UDP socket instance
-	debug       bool          // Debug flag for logging
-	address     net.IP        // Destination IP address
-	port        int           // Destination port
-	pollMsg     []byte        // Pre-built 14-byte poll message
-	unlinkMsg   []byte        // Pre-built 14-byte unlink message
-	buffer      *RingBuffer   // Circular buffer for incoming data
-	tempBuffer  []byte        // Temporary buffer for UDP reads
+	callsign  string         // 10-byte callsign (space-padded)
+	socket    *UDPSocket     // UDP socket instance
+	debug     bool           // Debug flag for logging
+	address   net.IP         // Destination IP address
+	port      int            // Destination port
+	pollMsg   []byte         // Pre-built 14-byte poll message
+	unlinkMsg []byte         // Pre-built 14-byte unlink message
+	buffer    *RingBuffer    // Circular buffer for incoming data
+	packets   chan Datagram  // Packets from the socket's reader goroutine
+	ready     chan struct{}  // Signalled when packets are queued
+	peers     *YSFPeerFilter // Allowed peers; nil accepts the destination only
+
+	// Roaming peer tracking: the destination follows the peer's polls
+	trackPeer    bool
+	peerCallsign string // callsign in the destination's polls, once seen
+	rebinds      atomic.Uint64
+
+	// Last poll and data packet accepted, both set when opened
+	lastPoll time.Time
+	lastData time.Time
 }
 
 // NewYSFNetworkClient creates a YSF network client that connects to a remote address/port
 // Equivalent to C++ CYSFNetwork(const std::string& address, unsigned int port, const std::string& callsign, bool debug)
 func NewYSFNetworkClient(address string, port int, callsign string, debug bool) (*YSFNetwork, error) {
 	network := &YSFNetwork{
-		callsign:   padCallsign(callsign),
-		socket:     NewUDPSocket("", 0), // Bind to any local address/port
-		debug:      debug,
-		port:       port,
-		buffer:     NewRingBuffer(protocol.RING_BUFFER_LENGTH, "YSFNetwork"),
-		tempBuffer: make([]byte, protocol.BUFFER_LENGTH),
+		callsign: padCallsign(callsign),
+		socket:   NewUDPSocket("", 0), // Bind to any local address/port
+		debug:    debug,
+		port:     port,
+		buffer:   NewRingBuffer(protocol.RING_BUFFER_LENGTH, "YSFNetwork"),
+		packets:  make(chan Datagram, ysfReceiveQueue),
+		ready:    make(chan struct{}, 1),
 	}
 
 	// Parse destination address
@@ -61,12 +81,13 @@ func NewYSFNetworkClient(address string, port int, callsign string, debug bool)
 // Equivalent to C++ CYSFNetwork(const std::string& localAddress, unsigned int localPort, const std::string& callsign, bool debug)
 func NewYSFNetworkServer(localAddress string, port int, callsign string, debug bool) *YSFNetwork {
 	network := &YSFNetwork{
-		callsign:   padCallsign(callsign),
-		socket:     NewUDPSocket(localAddress, port),
-		debug:      debug,
-		port:       0, // No destination initially
-		buffer:     NewRingBuffer(protocol.RING_BUFFER_LENGTH, "YSFNetwork"),
-		tempBuffer: make([]byte, protocol.BUFFER_LENGTH),
+		callsign: padCallsign(callsign),
+		socket:   NewUDPSocket(localAddress, port),
+		debug:    debug,
+		port:     0, // No destination initially
+		buffer:   NewRingBuffer(protocol.RING_BUFFER_LENGTH, "YSFNetwork"),
+		packets:  make(chan Datagram, ysfReceiveQueue),
+		ready:    make(chan struct{}, 1),
 	}
 
 	// Initialize poll and unlink messages
@@ -92,7 +113,16 @@ func (n *YSFNetwork) Open() error {
 	if n.debug {
 		log.Printf("Opening YSF network connection")
 	}
-	return n.socket.Open()
+	if err := n.socket.Open(); err != nil {
+		return err
+	}
+	if err := n.socket.Receive(n.packets, n.ready); err != nil {
+		n.socket.Close()
+		return err
+	}
+	now := time.Now()
+	n.lastPoll, n.lastData = now, now
+	return nil
 }
 
 // SetDestination stores destination address and port for outbound packets
@@ -121,6 +151,41 @@ func (n *YSFNetwork) SetDestinationByString(address string, port int) error {
 	return nil
 }
 
+// SetPeerFilter restricts incoming traffic to the filter's peers instead of
+// the destination address and port
+func (n *YSFNetwork) SetPeerFilter(peers *YSFPeerFilter) {
+	n.peers = peers
+}
+
+// SetPeerTracking makes the destination follow the peer across address and
+// port changes, such as a hotspot behind carrier-grade NAT being rebound
+// The callsign in the destination's polls identifies the peer; a poll with
+// that callsign from a new address moves the destination there.
+func (n *YSFNetwork) SetPeerTracking(enabled bool) {
+	n.trackPeer = enabled
+	n.peerCallsign = ""
+}
+
+// SetTrace records the packets exchanged with YSF peers in t
+func (n *YSFNetwork) SetTrace(t *trace.Tracer) {
+	n.socket.SetTrace(t, "YSF")
+}
+
+// SetCapture keeps the packets exchanged with the YSF peers in r
+func (n *YSFNetwork) SetCapture(r *capture.Ring) {
+	n.socket.SetCapture(r, "YSF")
+}
+
+// PeerRebinds returns how many times peer tracking moved the destination
+func (n *YSFNetwork) PeerRebinds() uint64 {
+	return n.rebinds.Load()
+}
+
+// PeerFilter returns the filter set with SetPeerFilter, or nil
+func (n *YSFNetwork) PeerFilter() *YSFPeerFilter {
+	return n.peers
+}
+
 // ClearDestination disables outbound packets
 // Equivalent to C++ CYSFNetwork::clearDestination()
 func (n *YSFNetwork) ClearDestination() {
@@ -146,7 +211,6 @@ func (n *YSFNetwork) Write(data []byte) error {
 
 	if n.debug {
 		log.Printf("YSF Network write: %d bytes to %s:%d", len(data), n.address.String(), n.port)
-		// Could add hex dump here like C++ CUtils::dump()
 	}
 
 	addr := &net.UDPAddr{
@@ -212,47 +276,110 @@ func (n *YSFNetwork) Read(data []byte) int {
 	return length
 }
 
-// Clock processes incoming UDP packets and stores them in the ring buffer
+// Ready is signalled when received packets are waiting for Clock
+func (n *YSFNetwork) Ready() <-chan struct{} {
+	return n.ready
+}
+
+// Clock moves the packets received since the last call into the ring buffer
 // Equivalent to C++ CYSFNetwork::clock()
 func (n *YSFNetwork) Clock(ms int) {
-	// Poll UDP socket for incoming data
 	for {
-		bytesRead, fromAddr, err := n.socket.Read(n.tempBuffer)
-		if err != nil {
+		select {
+		case packet := <-n.packets:
+			n.accept(packet.Data, packet.From)
+		default:
+			return
+		}
+	}
+}
+
+// accept filters a received packet and stores it in the ring buffer
+func (n *YSFNetwork) accept(packet []byte, fromAddr *net.UDPAddr) {
+	// Validate sender against the allowed peers, or the destination if set
+	// (for client mode)
+	if n.peers != nil {
+		accept, reply := n.peers.Check(packet, fromAddr, time.Now())
+		if reply != nil {
+			n.socket.Write(reply, fromAddr)
+		}
+		if !accept {
 			if n.debug {
-				log.Printf("YSF Network clock error: %v", err)
+				log.Printf("YSF Network: packet from %s:%d rejected by peer filter",
+					fromAddr.IP.String(), fromAddr.Port)
 			}
-			break
+			return
 		}
+	}
 
-		if bytesRead == 0 {
-			break // No more data available
-		}
+	if n.trackPeer {
+		n.trackPoll(packet, fromAddr)
+	}
 
-		// Validate sender if destination is set (for client mode)
-		if n.port != 0 && n.address != nil {
-			if !fromAddr.IP.Equal(n.address) || fromAddr.Port != n.port {
-				if n.debug {
-					log.Printf("YSF Network: packet from unexpected source %s:%d (expected %s:%d)",
-						fromAddr.IP.String(), fromAddr.Port, n.address.String(), n.port)
-				}
-				continue // Ignore packet from wrong source
+	if n.peers == nil && n.port != 0 && n.address != nil {
+		if !fromAddr.IP.Equal(n.address) || fromAddr.Port != n.port {
+			if n.debug {
+				log.Printf("YSF Network: packet from unexpected source %s:%d (expected %s:%d)",
+					fromAddr.IP.String(), fromAddr.Port, n.address.String(), n.port)
 			}
+			return // Ignore packet from wrong source
 		}
+	}
+
+	if n.debug {
+		log.Printf("YSF Network received: %d bytes from %s:%d",
+			len(packet), fromAddr.IP.String(), fromAddr.Port)
+	}
+
+	switch string(packet[:min(len(packet), 4)]) {
+	case "YSFP":
+		n.lastPoll = time.Now()
+	case "YSFD":
+		n.lastData = time.Now()
+	}
 
+	// Store in ring buffer with length prefix
+	if !n.buffer.AddLength(packet) {
 		if n.debug {
-			log.Printf("YSF Network received: %d bytes from %s:%d",
-				bytesRead, fromAddr.IP.String(), fromAddr.Port)
+			log.Printf("YSF Network: ring buffer full, dropping packet")
 		}
+	}
+}
 
-		// Store in ring buffer with length prefix
-		packetData := n.tempBuffer[:bytesRead]
-		if !n.buffer.AddLength(packetData) {
-			if n.debug {
-				log.Printf("YSF Network: ring buffer full, dropping packet")
-			}
-		}
+// trackPoll learns the destination peer's callsign from its polls and moves
+// the destination when a poll with that callsign comes from a new address
+func (n *YSFNetwork) trackPoll(packet []byte, from *net.UDPAddr) {
+	if len(packet) < protocol.YSF_POLL_MESSAGE_LENGTH || string(packet[:4]) != "YSFP" || n.address == nil {
+		return
+	}
+	callsign := strings.TrimSpace(string(packet[4:protocol.YSF_POLL_MESSAGE_LENGTH]))
+	if callsign == "" {
+		return
+	}
+
+	if from.IP.Equal(n.address) && from.Port == n.port {
+		n.peerCallsign = callsign
+		return
+	}
+	if callsign != n.peerCallsign {
+		return
 	}
+
+	log.Printf("YSF peer %s moved from %s:%d to %s:%d", callsign,
+		n.address.String(), n.port, from.IP.String(), from.Port)
+	n.address, n.port = from.IP, from.Port
+	n.rebinds.Add(1)
+}
+
+// LastReceived returns when the peer last polled and last sent a YSFD
+// packet; both count from the socket opening
+func (n *YSFNetwork) LastReceived() (keepalive, data time.Time) {
+	return n.lastPoll, n.lastData
+}
+
+// IsOpen reports whether the socket is bound; safe to call from any goroutine
+func (n *YSFNetwork) IsOpen() bool {
+	return n.socket.IsOpen()
 }
 
 // Close closes the UDP socket
@@ -297,4 +424,4 @@ func (n *YSFNetwork) String() string {
 	}
 	return fmt.Sprintf("YSFNetwork[%s]: client mode -> %s:%d",
 		strings.TrimSpace(n.callsign), n.address.String(), n.port)
-}
\ No newline at end of file
+}
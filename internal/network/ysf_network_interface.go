@@ -0,0 +1,28 @@
+package network
+
+// YSFNetworkInterface defines the YSF network operations used by the gateway
+// It is implemented by YSFNetwork, and by in-memory networks in tests.
+type YSFNetworkInterface interface {
+	// Lifecycle management
+	Open() error  // Open the socket
+	Close()       // Close the socket
+	IsOpen() bool // Check if the socket is open; safe from any goroutine
+
+	// Data transfer
+	Read(data []byte) int    // Read the next packet, 0 if none available
+	Write(data []byte) error // Write a packet to the destination
+	WritePoll() error        // Write a poll to the destination
+
+	// Timing
+	Clock(ms int)           // Move received packets to where Read finds them
+	Ready() <-chan struct{} // Signalled when received packets are waiting for Clock
+
+	// Destination
+	SetDestinationByString(address string, port int) error
+}
+
+// YSFPeerStats is implemented by YSF networks that filter and track peers
+type YSFPeerStats interface {
+	PeerFilter() *YSFPeerFilter // nil without a filter
+	PeerRebinds() uint64        // times peer tracking moved the destination
+}
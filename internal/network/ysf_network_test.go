@@ -3,6 +3,7 @@ package network
 import (
 	"net"
 	"testing"
+	"time"
 
 	"github.com/dbehnke/ysf2dmr/internal/protocol"
 )
@@ -80,7 +81,7 @@ func TestNewYSFNetworkClient(t *testing.T) {
 }
 
 func TestNewYSFNetworkServer(t *testing.T) {
-	network := NewYSFNetworkServer(14580, "SERVER", true)
+	network := NewYSFNetworkServer("", 14580, "SERVER", true)
 
 	if network == nil {
 		t.Fatalf("Expected non-nil network")
@@ -147,7 +148,7 @@ func TestCallsignPadding(t *testing.T) {
 }
 
 func TestGetCallsign(t *testing.T) {
-	network := NewYSFNetworkServer(14580, "TEST", false)
+	network := NewYSFNetworkServer("", 14580, "TEST", false)
 
 	callsign := network.GetCallsign()
 	expected := "TEST"
@@ -158,7 +159,7 @@ func TestGetCallsign(t *testing.T) {
 }
 
 func TestMessageInitialization(t *testing.T) {
-	network := NewYSFNetworkServer(14580, "MYCALL", false)
+	network := NewYSFNetworkServer("", 14580, "MYCALL", false)
 
 	// Test poll message
 	expectedPoll := make([]byte, protocol.YSF_POLL_MESSAGE_LENGTH)
@@ -192,7 +193,7 @@ func TestMessageInitialization(t *testing.T) {
 }
 
 func TestSetDestination(t *testing.T) {
-	network := NewYSFNetworkServer(14580, "TEST", false)
+	network := NewYSFNetworkServer("", 14580, "TEST", false)
 
 	// Initially no destination
 	if network.port != 0 {
@@ -226,7 +227,7 @@ func TestSetDestination(t *testing.T) {
 }
 
 func TestSetDestinationByString(t *testing.T) {
-	network := NewYSFNetworkServer(14580, "TEST", false)
+	network := NewYSFNetworkServer("", 14580, "TEST", false)
 
 	err := network.SetDestinationByString("127.0.0.1", 42000)
 	if err != nil {
@@ -244,7 +245,7 @@ func TestSetDestinationByString(t *testing.T) {
 }
 
 func TestWriteValidation(t *testing.T) {
-	network := NewYSFNetworkServer(14580, "TEST", false)
+	network := NewYSFNetworkServer("", 14580, "TEST", false)
 
 	// Test with no destination - should return nil without error
 	data := make([]byte, protocol.YSF_FRAME_LENGTH)
@@ -268,7 +269,7 @@ func TestWriteValidation(t *testing.T) {
 }
 
 func TestPollAndUnlinkNoDestination(t *testing.T) {
-	network := NewYSFNetworkServer(14580, "TEST", false)
+	network := NewYSFNetworkServer("", 14580, "TEST", false)
 
 	// Test poll with no destination - should return nil without error
 	err := network.WritePoll()
@@ -284,7 +285,7 @@ func TestPollAndUnlinkNoDestination(t *testing.T) {
 }
 
 func TestReadEmptyBuffer(t *testing.T) {
-	network := NewYSFNetworkServer(14580, "TEST", false)
+	network := NewYSFNetworkServer("", 14580, "TEST", false)
 
 	data := make([]byte, 200)
 	length := network.Read(data)
@@ -300,7 +301,7 @@ func TestReadEmptyBuffer(t *testing.T) {
 
 func TestStringRepresentation(t *testing.T) {
 	// Test server mode
-	server := NewYSFNetworkServer(14580, "SERVER", false)
+	server := NewYSFNetworkServer("", 14580, "SERVER", false)
 	serverStr := server.String()
 	expectedServer := "YSFNetwork[SERVER]: server mode"
 	if serverStr != expectedServer {
@@ -322,7 +323,7 @@ func TestStringRepresentation(t *testing.T) {
 
 // Integration test to verify ring buffer interaction
 func TestRingBufferIntegration(t *testing.T) {
-	network := NewYSFNetworkServer(14580, "TEST", false)
+	network := NewYSFNetworkServer("", 14580, "TEST", false)
 
 	// Manually add some test data to the ring buffer
 	testData := []byte("Hello, YSF!")
@@ -354,4 +355,72 @@ func TestRingBufferIntegration(t *testing.T) {
 	if network.HasData() {
 		t.Errorf("HasData() should return false after reading all data")
 	}
-}
\ No newline at end of file
+}
+func TestPeerTracking(t *testing.T) {
+	network := NewYSFNetworkServer("", 0, "GATEWAY", false)
+	network.SetDestination(net.ParseIP("198.51.100.7"), 42000)
+	network.SetPeerTracking(true)
+
+	poll := func(callsign string) []byte {
+		return append([]byte("YSFP"), []byte(padCallsign(callsign))...)
+	}
+	moved := &net.UDPAddr{IP: net.ParseIP("203.0.113.9"), Port: 51234}
+
+	// Until the destination has polled its callsign is unknown
+	network.trackPoll(poll("HOTSPOT"), moved)
+	if network.port != 42000 {
+		t.Fatalf("destination moved before the peer's callsign was known")
+	}
+
+	network.trackPoll(poll("HOTSPOT"), &net.UDPAddr{IP: net.ParseIP("198.51.100.7"), Port: 42000})
+	network.trackPoll(poll("OTHER"), moved)
+	if network.port != 42000 {
+		t.Fatalf("destination moved to a poll with another callsign")
+	}
+
+	network.trackPoll(poll("HOTSPOT"), moved)
+	if !network.address.Equal(moved.IP) || network.port != moved.Port {
+		t.Errorf("destination = %s:%d, want %s", network.address, network.port, moved)
+	}
+	if network.PeerRebinds() != 1 {
+		t.Errorf("PeerRebinds() = %d, want 1", network.PeerRebinds())
+	}
+
+	// Data packets never move the destination
+	network.trackPoll(append([]byte("YSFD"), []byte(padCallsign("HOTSPOT"))...), &net.UDPAddr{IP: moved.IP, Port: 1})
+	if network.port != moved.Port {
+		t.Errorf("destination moved by a data packet")
+	}
+}
+
+func TestYSFNetworkReceive(t *testing.T) {
+	network := NewYSFNetworkServer("", 0, "GATEWAY", false)
+	if err := network.Open(); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer network.Close()
+
+	port := network.socket.conn.LocalAddr().(*net.UDPAddr).Port
+	peer, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port})
+	if err != nil {
+		t.Fatalf("DialUDP() error = %v", err)
+	}
+	defer peer.Close()
+
+	poll := append([]byte("YSFP"), []byte(padCallsign("HOTSPOT"))...)
+	if _, err := peer.Write(poll); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	select {
+	case <-network.Ready():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Ready() not signalled for a received packet")
+	}
+
+	network.Clock(0)
+	data := make([]byte, 100)
+	if length := network.Read(data); string(data[:length]) != string(poll) {
+		t.Errorf("Read() = %q, want %q", data[:length], poll)
+	}
+}
@@ -0,0 +1,185 @@
+package network
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+// YSF peer authentication packets
+// A peer asks for a challenge with "YSFA" and its callsign, the gateway
+// answers "YSFC" and a nonce, and the peer proves it knows the secret with
+// "YSFA", its callsign and HMAC-SHA256(secret, nonce). "YSFK" confirms.
+const (
+	YSF_MAGIC_AUTH      = "YSFA"
+	YSF_MAGIC_CHALLENGE = "YSFC"
+	YSF_MAGIC_AUTH_ACK  = "YSFK"
+
+	ysfAuthRequestLength  = 4 + protocol.YSF_CALLSIGN_LENGTH
+	ysfAuthResponseLength = ysfAuthRequestLength + sha256.Size
+	ysfNonceLength        = 16
+)
+
+const (
+	ysfPeerTimeout      = 60 * time.Second // authenticated peers silent this long must authenticate again
+	ysfChallengeTimeout = 10 * time.Second
+	ysfMaxChallenges    = 64 // outstanding challenges, so spoofed requests cannot grow the table
+)
+
+// YSFPeerFilter decides which peers may send YSF traffic to the gateway
+// Peers must come from an allowed address (any address when the list is
+// empty) and, when a secret is set, have completed the authentication
+// handshake. It is used from the network's Clock only.
+type YSFPeerFilter struct {
+	allowed []*net.IPNet
+	secret  []byte
+
+	challenges    map[string]ysfChallenge // by peer address
+	authenticated map[string]time.Time    // peer address -> last packet
+
+	rejectedPeer atomic.Uint64 // packets from addresses not allowed
+	rejectedAuth atomic.Uint64 // packets from peers not authenticated
+}
+
+type ysfChallenge struct {
+	nonce   []byte
+	expires time.Time
+}
+
+// NewYSFPeerFilter creates a filter from a comma separated list of IP
+// addresses, CIDR ranges and host names (resolved now) and an optional
+// shared secret
+func NewYSFPeerFilter(peers, secret string) (*YSFPeerFilter, error) {
+	f := &YSFPeerFilter{
+		challenges:    make(map[string]ysfChallenge),
+		authenticated: make(map[string]time.Time),
+	}
+	if secret != "" {
+		f.secret = []byte(secret)
+	}
+
+	for _, peer := range strings.Split(peers, ",") {
+		peer = strings.TrimSpace(peer)
+		if peer == "" {
+			continue
+		}
+		if _, network, err := net.ParseCIDR(peer); err == nil {
+			f.allowed = append(f.allowed, network)
+			continue
+		}
+		ip, err := Lookup(peer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed peer %q: %v", peer, err)
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 8*net.IPv4len
+		}
+		f.allowed = append(f.allowed, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+
+	return f, nil
+}
+
+// Check decides whether a packet from a peer is accepted as YSF traffic
+// Authentication packets are never accepted; reply, when not nil, is the
+// handshake answer to send back to the peer.
+func (f *YSFPeerFilter) Check(packet []byte, from *net.UDPAddr, now time.Time) (accept bool, reply []byte) {
+	if !f.allowedAddress(from.IP) {
+		f.rejectedPeer.Add(1)
+		return false, nil
+	}
+	if f.secret == nil {
+		return true, nil
+	}
+
+	key := from.String()
+	if bytes.HasPrefix(packet, []byte(YSF_MAGIC_AUTH)) {
+		return false, f.handshake(key, packet, now)
+	}
+
+	last, ok := f.authenticated[key]
+	if !ok || now.Sub(last) > ysfPeerTimeout {
+		delete(f.authenticated, key)
+		f.rejectedAuth.Add(1)
+		return false, nil
+	}
+	f.authenticated[key] = now
+	return true, nil
+}
+
+// Rejected returns the packets dropped because the address is not allowed
+// and because the peer has not authenticated
+func (f *YSFPeerFilter) Rejected() (peer, auth uint64) {
+	return f.rejectedPeer.Load(), f.rejectedAuth.Load()
+}
+
+func (f *YSFPeerFilter) allowedAddress(ip net.IP) bool {
+	if len(f.allowed) == 0 {
+		return true
+	}
+	for _, network := range f.allowed {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// handshake answers a challenge request or checks a challenge response
+func (f *YSFPeerFilter) handshake(key string, packet []byte, now time.Time) []byte {
+	switch len(packet) {
+	case ysfAuthRequestLength:
+		f.expireChallenges(now)
+		if len(f.challenges) >= ysfMaxChallenges {
+			f.rejectedAuth.Add(1)
+			return nil
+		}
+		nonce := make([]byte, ysfNonceLength)
+		if _, err := rand.Read(nonce); err != nil {
+			return nil
+		}
+		f.challenges[key] = ysfChallenge{nonce: nonce, expires: now.Add(ysfChallengeTimeout)}
+		return append([]byte(YSF_MAGIC_CHALLENGE), nonce...)
+
+	case ysfAuthResponseLength:
+		challenge, ok := f.challenges[key]
+		delete(f.challenges, key)
+		if !ok || now.After(challenge.expires) {
+			f.rejectedAuth.Add(1)
+			return nil
+		}
+		if !hmac.Equal(packet[ysfAuthRequestLength:], YSFAuthResponse(f.secret, challenge.nonce)) {
+			f.rejectedAuth.Add(1)
+			return nil
+		}
+		f.authenticated[key] = now
+		return append([]byte(YSF_MAGIC_AUTH_ACK), packet[4:ysfAuthRequestLength]...)
+	}
+
+	f.rejectedAuth.Add(1)
+	return nil
+}
+
+func (f *YSFPeerFilter) expireChallenges(now time.Time) {
+	for key, challenge := range f.challenges {
+		if now.After(challenge.expires) {
+			delete(f.challenges, key)
+		}
+	}
+}
+
+// YSFAuthResponse computes a peer's answer to a challenge nonce
+func YSFAuthResponse(secret, nonce []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(nonce)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,115 @@
+package network
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+func udpAddr(ip string, port int) *net.UDPAddr {
+	return &net.UDPAddr{IP: net.ParseIP(ip), Port: port}
+}
+
+func TestYSFPeerFilter_AllowedPeers(t *testing.T) {
+	f, err := NewYSFPeerFilter("192.168.1.0/24, 10.0.0.5,localhost", "")
+	if err != nil {
+		t.Fatalf("NewYSFPeerFilter() error = %v", err)
+	}
+
+	now := time.Now()
+	for _, tt := range []struct {
+		ip     string
+		accept bool
+	}{
+		{"192.168.1.20", true},
+		{"192.168.2.20", false},
+		{"10.0.0.5", true},
+		{"10.0.0.6", false},
+		{"127.0.0.1", true},
+	} {
+		if accept, _ := f.Check([]byte("YSFP"), udpAddr(tt.ip, 42000), now); accept != tt.accept {
+			t.Errorf("Check(%s) = %v, want %v", tt.ip, accept, tt.accept)
+		}
+	}
+	if peer, auth := f.Rejected(); peer != 2 || auth != 0 {
+		t.Errorf("Rejected() = %d, %d, want 2, 0", peer, auth)
+	}
+
+	if _, err := NewYSFPeerFilter("invalid.invalid.invalid", ""); err == nil {
+		t.Error("NewYSFPeerFilter() accepted an unresolvable host")
+	}
+}
+
+func TestYSFPeerFilter_Handshake(t *testing.T) {
+	secret := []byte("s3cret")
+	f, err := NewYSFPeerFilter("", string(secret))
+	if err != nil {
+		t.Fatalf("NewYSFPeerFilter() error = %v", err)
+	}
+
+	peer := udpAddr("192.0.2.1", 42000)
+	now := time.Now()
+	data := []byte("YSFD...")
+	callsign := []byte("N0CALL    ")
+
+	if accept, _ := f.Check(data, peer, now); accept {
+		t.Fatal("accepted traffic before authentication")
+	}
+
+	// Challenge request
+	accept, reply := f.Check(append([]byte(YSF_MAGIC_AUTH), callsign...), peer, now)
+	if accept || !bytes.HasPrefix(reply, []byte(YSF_MAGIC_CHALLENGE)) || len(reply) != 4+ysfNonceLength {
+		t.Fatalf("challenge request: accept %v, reply %q", accept, reply)
+	}
+	nonce := reply[4:]
+
+	// A wrong answer fails and uses up the challenge
+	response := append(append([]byte(YSF_MAGIC_AUTH), callsign...), YSFAuthResponse([]byte("wrong"), nonce)...)
+	if _, reply := f.Check(response, peer, now); reply != nil {
+		t.Fatalf("wrong secret answered with %q", reply)
+	}
+
+	_, reply = f.Check(append([]byte(YSF_MAGIC_AUTH), callsign...), peer, now)
+	nonce = reply[4:]
+	response = append(append([]byte(YSF_MAGIC_AUTH), callsign...), YSFAuthResponse(secret, nonce)...)
+	if _, reply := f.Check(response, peer, now); !bytes.Equal(reply, append([]byte(YSF_MAGIC_AUTH_ACK), callsign...)) {
+		t.Fatalf("correct secret answered with %q", reply)
+	}
+
+	if accept, _ := f.Check(data, peer, now.Add(time.Second)); !accept {
+		t.Error("authenticated peer rejected")
+	}
+	if accept, _ := f.Check(data, udpAddr("192.0.2.1", 42001), now); accept {
+		t.Error("another port of the authenticated address accepted")
+	}
+
+	// Authentication lapses when the peer goes quiet
+	if accept, _ := f.Check(data, peer, now.Add(time.Second+ysfPeerTimeout+time.Millisecond)); accept {
+		t.Error("peer accepted after the authentication timeout")
+	}
+
+	if _, auth := f.Rejected(); auth != 4 {
+		t.Errorf("rejected unauthenticated = %d, want 4", auth)
+	}
+}
+
+func TestYSFPeerFilter_ChallengeLimit(t *testing.T) {
+	f, _ := NewYSFPeerFilter("", "s3cret")
+	request := append([]byte(YSF_MAGIC_AUTH), []byte("N0CALL    ")...)
+	now := time.Now()
+
+	for port := 1; port <= ysfMaxChallenges; port++ {
+		if _, reply := f.Check(request, udpAddr("192.0.2.1", port), now); reply == nil {
+			t.Fatalf("challenge %d refused", port)
+		}
+	}
+	if _, reply := f.Check(request, udpAddr("192.0.2.1", 0), now); reply != nil {
+		t.Error("challenge issued beyond the limit")
+	}
+
+	// Expired challenges make room again
+	if _, reply := f.Check(request, udpAddr("192.0.2.1", 0), now.Add(ysfChallengeTimeout+time.Second)); reply == nil {
+		t.Error("challenge refused after the others expired")
+	}
+}
@@ -0,0 +1,165 @@
+package dmr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+)
+
+// Sync patterns placed in bits 108-155 of a burst (ETSI TS 102 361-1 9.1.1)
+var (
+	BS_SOURCED_VOICE_SYNC = []byte{0x07, 0x55, 0xFD, 0x7D, 0xF7, 0x5F, 0x70}
+	MS_SOURCED_VOICE_SYNC = []byte{0x07, 0xF7, 0xD5, 0xDD, 0x57, 0xDF, 0xD0}
+	MS_SOURCED_DATA_SYNC  = []byte{0x0D, 0x5D, 0x7F, 0x77, 0xFD, 0x75, 0x70}
+)
+
+// Link control start/stop values of the EMB
+const (
+	LCSS_SINGLE       = 0
+	LCSS_FIRST        = 1
+	LCSS_LAST         = 2
+	LCSS_CONTINUATION = 3
+)
+
+// VOICE_SUPERFRAME_LENGTH is the number of voice bursts (A-F) in a superframe
+const VOICE_SUPERFRAME_LENGTH = 6
+
+// SyncSource selects the sync patterns written into transmitted bursts
+type SyncSource uint8
+
+const (
+	BS_SOURCED SyncSource = iota // base station, as repeaters and hotspots send
+	MS_SOURCED                   // mobile station
+)
+
+// ParseSyncSource parses BS or MS
+func ParseSyncSource(value string) (SyncSource, error) {
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "BS":
+		return BS_SOURCED, nil
+	case "MS":
+		return MS_SOURCED, nil
+	default:
+		return BS_SOURCED, fmt.Errorf("unknown sync source %q, want BS or MS", value)
+	}
+}
+
+// String returns BS or MS
+func (s SyncSource) String() string {
+	if s == MS_SOURCED {
+		return "MS"
+	}
+	return "BS"
+}
+
+func (s SyncSource) voiceSync() []byte {
+	if s == MS_SOURCED {
+		return MS_SOURCED_VOICE_SYNC
+	}
+	return BS_SOURCED_VOICE_SYNC
+}
+
+func (s SyncSource) dataSync() []byte {
+	if s == MS_SOURCED {
+		return MS_SOURCED_DATA_SYNC
+	}
+	return BS_SOURCED_DATA_SYNC
+}
+
+// BurstAssembler fills in the signalling of transmitted 33-byte bursts
+// Voice burst A of each superframe gets the voice sync and bursts B-F the
+// EMB carrying the color code and a fragment of the embedded LC; data bursts
+// get the Golay(20,8) slot type and the data sync. It is not safe for
+// concurrent use.
+type BurstAssembler struct {
+	colorCode uint8
+	source    SyncSource
+
+	n        uint8     // position of the next voice burst in its superframe
+	embedded [128]bool // interleaved embedded LC of the current call
+	hasLC    bool
+}
+
+// NewBurstAssembler creates an assembler for colorCode (0-15) and source
+func NewBurstAssembler(colorCode uint8, source SyncSource) *BurstAssembler {
+	return &BurstAssembler{colorCode: colorCode & 0x0F, source: source}
+}
+
+// ColorCode returns the color code written into bursts
+func (a *BurstAssembler) ColorCode() uint8 {
+	return a.colorCode
+}
+
+// Source returns the sync source of the patterns written into bursts
+func (a *BurstAssembler) Source() SyncSource {
+	return a.source
+}
+
+// StartVoice starts a voice call whose bursts B-E carry lc as embedded LC
+// The next voice burst is burst A. A nil lc sends null embedded signalling.
+func (a *BurstAssembler) StartVoice(lc *LinkControl) {
+	a.n = 0
+	a.hasLC = lc != nil
+	if a.hasLC {
+		a.embedded = encodeEmbeddedLC(lc.Encode())
+	}
+}
+
+// Voice adds the sync or EMB and embedded signalling to the next voice burst
+// of the call, returning its position in the superframe (0 for burst A)
+func (a *BurstAssembler) Voice(burst []byte) uint8 {
+	if len(burst) < DMR_FRAME_LENGTH {
+		return 0
+	}
+
+	n := a.n
+	a.n = (a.n + 1) % VOICE_SUPERFRAME_LENGTH
+
+	if n == 0 {
+		writeSync(burst, a.source.voiceSync())
+		return n
+	}
+
+	lcss := uint8(LCSS_SINGLE)
+	var fragment []bool
+	if a.hasLC && n <= 4 {
+		lcss = [5]uint8{0, LCSS_FIRST, LCSS_CONTINUATION, LCSS_CONTINUATION, LCSS_LAST}[n]
+		fragment = a.embedded[(n-1)*32 : n*32]
+	}
+
+	a.writeEMB(burst, lcss)
+	for i := uint32(0); i < 32; i++ {
+		bits.Write(burst, 116+i, fragment != nil && fragment[i])
+	}
+	return n
+}
+
+// Data adds the slot type for dataType and the data sync to a data burst,
+// such as a voice LC header, terminator or data block
+func (a *BurstAssembler) Data(burst []byte, dataType uint8) {
+	if len(burst) < DMR_FRAME_LENGTH {
+		return
+	}
+	addSlotType(burst, dataType, a.colorCode)
+	writeSync(burst, a.source.dataSync())
+}
+
+// writeEMB writes the QR(16,7,6) protected EMB around the embedded data
+func (a *BurstAssembler) writeEMB(burst []byte, lcss uint8) {
+	emb := []byte{a.colorCode<<4 | (lcss&0x03)<<1, 0} // PI is never set
+	codec.QR1676Encode(emb)
+
+	burst[13] = (burst[13] & 0xF0) | (emb[0] >> 4)
+	burst[14] = (burst[14] & 0x0F) | (emb[0] << 4)
+	burst[18] = (burst[18] & 0xF0) | (emb[1] >> 4)
+	burst[19] = (burst[19] & 0x0F) | (emb[1] << 4)
+}
+
+// writeSync writes a sync pattern into bits 108-155 of a burst
+func writeSync(burst []byte, sync []byte) {
+	burst[13] = (burst[13] & 0xF0) | (sync[0] & 0x0F)
+	copy(burst[14:19], sync[1:6])
+	burst[19] = (burst[19] & 0x0F) | (sync[6] & 0xF0)
+}
@@ -0,0 +1,102 @@
+package dmr
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+	"github.com/dbehnke/ysf2dmr/internal/correction"
+)
+
+// readSync returns bits 108-155 of a burst in the layout of the sync patterns
+func readSync(burst []byte) []byte {
+	return []byte{burst[13] & 0x0F, burst[14], burst[15], burst[16], burst[17], burst[18], burst[19] & 0xF0}
+}
+
+// readEMB returns the decoded EMB of a voice burst
+func readEMB(burst []byte) (colorCode uint8, lcss uint8) {
+	emb := []byte{burst[13]<<4 | burst[14]>>4, burst[18]<<4 | burst[19]>>4}
+	value := codec.QR1676Decode(emb)
+	return value >> 3, value & 0x03
+}
+
+func TestParseSyncSource(t *testing.T) {
+	for value, want := range map[string]SyncSource{"BS": BS_SOURCED, "ms": MS_SOURCED, " bs ": BS_SOURCED} {
+		got, err := ParseSyncSource(value)
+		if err != nil || got != want {
+			t.Errorf("ParseSyncSource(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	if _, err := ParseSyncSource("repeater"); err == nil {
+		t.Error("ParseSyncSource accepted an unknown source")
+	}
+}
+
+func TestBurstAssembler_VoiceSuperframe(t *testing.T) {
+	for _, source := range []SyncSource{BS_SOURCED, MS_SOURCED} {
+		a := NewBurstAssembler(7, source)
+		a.StartVoice(&LinkControl{SourceID: 3100001, DestinationID: 91})
+
+		wantLCSS := []uint8{0, LCSS_FIRST, LCSS_CONTINUATION, LCSS_CONTINUATION, LCSS_LAST, LCSS_SINGLE}
+		for i := 0; i < 2*VOICE_SUPERFRAME_LENGTH; i++ {
+			burst := bytes.Repeat([]byte{0xA5}, DMR_FRAME_LENGTH)
+			n := a.Voice(burst)
+			if n != uint8(i%VOICE_SUPERFRAME_LENGTH) {
+				t.Fatalf("%s burst %d: n = %d", source, i, n)
+			}
+			if burst[12] != 0xA5 || burst[20] != 0xA5 {
+				t.Errorf("%s burst %d: voice bits around the sync changed", source, i)
+			}
+
+			if n == 0 {
+				if got := readSync(burst); !bytes.Equal(got, source.voiceSync()) {
+					t.Errorf("%s burst A sync = % X, want % X", source, got, source.voiceSync())
+				}
+				continue
+			}
+			cc, lcss := readEMB(burst)
+			if cc != 7 || lcss != wantLCSS[n] {
+				t.Errorf("%s burst %d EMB = cc %d lcss %d, want cc 7 lcss %d", source, n, cc, lcss, wantLCSS[n])
+			}
+		}
+	}
+}
+
+func TestBurstAssembler_NullEmbeddedData(t *testing.T) {
+	a := NewBurstAssembler(1, BS_SOURCED)
+	a.StartVoice(nil)
+	a.Voice(make([]byte, DMR_FRAME_LENGTH))
+
+	burst := bytes.Repeat([]byte{0xFF}, DMR_FRAME_LENGTH)
+	a.Voice(burst)
+	if _, lcss := readEMB(burst); lcss != LCSS_SINGLE {
+		t.Errorf("lcss = %d, want single", lcss)
+	}
+	for i := uint32(116); i < 148; i++ {
+		if bits.Read(burst, i) {
+			t.Fatalf("embedded bit %d set without an LC", i)
+		}
+	}
+}
+
+func TestBurstAssembler_Data(t *testing.T) {
+	a := NewBurstAssembler(3, MS_SOURCED)
+	burst := make([]byte, DMR_FRAME_LENGTH)
+	a.Data(burst, DT_TERMINATOR_WITH_LC)
+
+	if got := readSync(burst); !bytes.Equal(got, MS_SOURCED_DATA_SYNC) {
+		t.Errorf("data sync = % X, want % X", got, MS_SOURCED_DATA_SYNC)
+	}
+	slotType := []byte{
+		burst[12]<<2 | burst[13]>>6,
+		(burst[13]<<2)&0xC0 | (burst[19]<<2)&0x3C | burst[20]>>6,
+		(burst[20] << 2) & 0xF0,
+	}
+	if errors := correction.Golay2087Decode(slotType); errors != 0 {
+		t.Errorf("slot type has %d bit errors", errors)
+	}
+	if slotType[0] != 3<<4|DT_TERMINATOR_WITH_LC {
+		t.Errorf("slot type = 0x%02X, want 0x%02X", slotType[0], 3<<4|DT_TERMINATOR_WITH_LC)
+	}
+}
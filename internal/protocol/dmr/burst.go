@@ -0,0 +1,38 @@
+package dmr
+
+import (
+	"fmt"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+	"github.com/dbehnke/ysf2dmr/internal/correction"
+)
+
+// BS sourced data sync, placed in bits 108-155 of a burst
+var BS_SOURCED_DATA_SYNC = []byte{0x0D, 0xFF, 0x57, 0xD7, 0x5D, 0xF5, 0xD0}
+
+// BuildDataBurst builds a 33-byte BPTC(196,96) data burst
+// The 12-byte payload is BPTC encoded and the slot type (color code and data
+// type) and data sync are added to the middle of the burst, as for the data
+// header and rate 1/2 blocks.
+func BuildDataBurst(payload []byte, dataType uint8, colorCode uint8) ([]byte, error) {
+	burst, ok := codec.NewBPTC19696().Encode(payload)
+	if !ok {
+		return nil, fmt.Errorf("data burst payload too short: %d bytes", len(payload))
+	}
+
+	addSlotType(burst, dataType, colorCode)
+	writeSync(burst, BS_SOURCED_DATA_SYNC)
+
+	return burst, nil
+}
+
+// addSlotType writes the Golay(20,8) protected slot type into a burst
+func addSlotType(burst []byte, dataType uint8, colorCode uint8) {
+	slotType := []byte{(colorCode << 4) | (dataType & 0x0F), 0, 0}
+	correction.Golay2087Encode(slotType)
+
+	burst[12] = (burst[12] & 0xC0) | ((slotType[0] >> 2) & 0x3F)
+	burst[13] = (burst[13] & 0x0F) | ((slotType[0] << 6) & 0xC0) | ((slotType[1] >> 2) & 0x30)
+	burst[19] = (burst[19] & 0xF0) | ((slotType[1] >> 2) & 0x0F)
+	burst[20] = (burst[20] & 0x03) | ((slotType[1] << 6) & 0xC0) | ((slotType[2] >> 2) & 0x3C)
+}
@@ -0,0 +1,286 @@
+package dmr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+	"github.com/dbehnke/ysf2dmr/internal/correction"
+)
+
+// DMR data call constants (ETSI TS 102 361-1 section 9.3)
+const (
+	DATA_HEADER_LENGTH     = 12 // Decoded data header length (10 bytes + CRC)
+	DATA_BLOCK_RATE12_SIZE = 12 // Unconfirmed rate 1/2 block payload
+	DATA_MAX_BLOCKS        = 127
+	DATA_CRC32_LENGTH      = 4 // Message CRC carried in the last block
+
+	// Data Packet Format values
+	DPF_UDT              = 0x00
+	DPF_RESPONSE         = 0x01
+	DPF_UNCONFIRMED_DATA = 0x02
+	DPF_CONFIRMED_DATA   = 0x03
+	DPF_DEFINED_SHORT    = 0x0D
+	DPF_DEFINED_RAW      = 0x0E
+	DPF_PROPRIETARY      = 0x0F
+
+	// Service Access Points
+	SAP_UDT         = 0x00
+	SAP_TCP_HC      = 0x02
+	SAP_UDP_HC      = 0x03
+	SAP_IP_PACKET   = 0x04
+	SAP_ARP         = 0x05
+	SAP_PROPRIETARY = 0x09
+	SAP_SHORT_DATA  = 0x0A
+)
+
+// CRC mask applied to the data header CRC
+var dataHeaderCRCMask = [2]byte{0xCC, 0xCC}
+
+// DataHeader represents a decoded DMR data header
+type DataHeader struct {
+	Group             bool   // Group or individual destination
+	ResponseRequested bool   // A flag (confirmed delivery requested)
+	DPF               uint8  // Data Packet Format
+	SAP               uint8  // Service Access Point
+	PadOctets         uint8  // Pad octets in the final block
+	DstID             uint32 // Destination logical link ID
+	SrcID             uint32 // Source logical link ID
+	BlocksToFollow    uint8  // Number of data blocks after the header
+}
+
+// DataMessage is a fully reassembled DMR data call
+type DataMessage struct {
+	Header  DataHeader
+	Payload []byte // User data without pad octets and CRC
+	Text    string // Decoded SMS text, empty if none could be found
+}
+
+// DataCallAssembler reassembles unconfirmed rate 1/2 DMR data calls
+// Confirmed, rate 3/4 and rate 1 data are not decoded.
+type DataCallAssembler struct {
+	bptc      *codec.BPTC19696
+	header    *DataHeader
+	blocks    []byte
+	count     uint8
+	crcErrors uint64 // messages dropped for a bad message CRC-32
+}
+
+// NewDataCallAssembler creates a new data call assembler
+func NewDataCallAssembler() *DataCallAssembler {
+	return &DataCallAssembler{
+		bptc: codec.NewBPTC19696(),
+	}
+}
+
+// Reset discards any partially assembled data call
+func (a *DataCallAssembler) Reset() {
+	a.header = nil
+	a.blocks = a.blocks[:0]
+	a.count = 0
+}
+
+// CRCErrors returns the number of data calls dropped because their message
+// CRC-32 did not match
+func (a *DataCallAssembler) CRCErrors() uint64 {
+	return a.crcErrors
+}
+
+// InProgress returns true while a data call is being assembled
+func (a *DataCallAssembler) InProgress() bool {
+	return a.header != nil
+}
+
+// AddHeader decodes a BPTC(196,96) data header burst and starts a new data call
+func (a *DataCallAssembler) AddHeader(frame []byte) (*DataHeader, error) {
+	a.Reset()
+
+	payload, ok := a.bptc.Decode(frame)
+	if !ok {
+		return nil, fmt.Errorf("data header too short: %d bytes", len(frame))
+	}
+
+	header, err := ParseDataHeader(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.DPF != DPF_UNCONFIRMED_DATA {
+		return header, fmt.Errorf("unsupported data packet format 0x%X", header.DPF)
+	}
+	if header.BlocksToFollow == 0 {
+		return header, fmt.Errorf("data header with no blocks to follow")
+	}
+
+	a.header = header
+	return header, nil
+}
+
+// AddBlock decodes a rate 1/2 data block
+// Returns the reassembled message once the last block has been received.
+func (a *DataCallAssembler) AddBlock(frame []byte) (*DataMessage, error) {
+	if a.header == nil {
+		return nil, fmt.Errorf("data block without header")
+	}
+
+	payload, ok := a.bptc.Decode(frame)
+	if !ok {
+		a.Reset()
+		return nil, fmt.Errorf("data block too short: %d bytes", len(frame))
+	}
+
+	a.blocks = append(a.blocks, payload[:DATA_BLOCK_RATE12_SIZE]...)
+	a.count++
+
+	if a.count < a.header.BlocksToFollow {
+		return nil, nil
+	}
+
+	message := &DataMessage{Header: *a.header}
+
+	// The final block ends with pad octets and the message CRC-32
+	end := len(a.blocks) - int(a.header.PadOctets) - DATA_CRC32_LENGTH
+	if end < 0 {
+		a.Reset()
+		return nil, fmt.Errorf("data call shorter than its pad octets (%d)", a.header.PadOctets)
+	}
+	if !checkDataCRC32(a.blocks) {
+		a.crcErrors++
+		a.Reset()
+		return nil, fmt.Errorf("data call CRC-32 error")
+	}
+	message.Payload = make([]byte, end)
+	copy(message.Payload, a.blocks[:end])
+	message.Text = ExtractSMSText(message.Payload, message.Header.SAP)
+
+	a.Reset()
+	return message, nil
+}
+
+// checkDataCRC32 verifies the message CRC-32 in the last four bytes of the
+// data blocks
+// The CRC runs over the user data and pad octets swapped in pairs, as
+// dataCRC32 computes it.
+func checkDataCRC32(blocks []byte) bool {
+	if len(blocks) < DATA_CRC32_LENGTH {
+		return false
+	}
+	n := len(blocks) - DATA_CRC32_LENGTH
+	buffer := make([]byte, len(blocks))
+	copy(buffer, blocks)
+	for i := 0; i+1 < n; i += 2 {
+		buffer[i], buffer[i+1] = blocks[i+1], blocks[i]
+	}
+	return correction.CheckCRC32(buffer)
+}
+
+// ParseDataHeader parses a 12-byte decoded data header and checks its CRC
+func ParseDataHeader(data []byte) (*DataHeader, error) {
+	if len(data) < DATA_HEADER_LENGTH {
+		return nil, fmt.Errorf("data header too short: %d bytes", len(data))
+	}
+
+	buffer := make([]byte, DATA_HEADER_LENGTH)
+	copy(buffer, data[:DATA_HEADER_LENGTH])
+	buffer[10] ^= dataHeaderCRCMask[0]
+	buffer[11] ^= dataHeaderCRCMask[1]
+
+	if !correction.CheckCCITT162(buffer) {
+		return nil, fmt.Errorf("data header CRC error")
+	}
+
+	return &DataHeader{
+		Group:             (buffer[0] & 0x80) != 0,
+		ResponseRequested: (buffer[0] & 0x40) != 0,
+		DPF:               buffer[0] & 0x0F,
+		SAP:               buffer[1] >> 4,
+		PadOctets:         (buffer[0] & 0x10) | (buffer[1] & 0x0F),
+		DstID:             uint32(buffer[2])<<16 | uint32(buffer[3])<<8 | uint32(buffer[4]),
+		SrcID:             uint32(buffer[5])<<16 | uint32(buffer[6])<<8 | uint32(buffer[7]),
+		BlocksToFollow:    buffer[8] & 0x7F,
+	}, nil
+}
+
+// BuildDataHeader encodes a data header to 12 bytes including its masked CRC
+func BuildDataHeader(header *DataHeader) []byte {
+	buffer := make([]byte, DATA_HEADER_LENGTH)
+
+	if header.Group {
+		buffer[0] |= 0x80
+	}
+	if header.ResponseRequested {
+		buffer[0] |= 0x40
+	}
+	buffer[0] |= header.PadOctets & 0x10
+	buffer[0] |= header.DPF & 0x0F
+	buffer[1] = (header.SAP << 4) | (header.PadOctets & 0x0F)
+	buffer[2] = byte(header.DstID >> 16)
+	buffer[3] = byte(header.DstID >> 8)
+	buffer[4] = byte(header.DstID)
+	buffer[5] = byte(header.SrcID >> 16)
+	buffer[6] = byte(header.SrcID >> 8)
+	buffer[7] = byte(header.SrcID)
+	buffer[8] = 0x80 | (header.BlocksToFollow & 0x7F) // Full message flag
+
+	correction.AddCCITT162(buffer)
+	buffer[10] ^= dataHeaderCRCMask[0]
+	buffer[11] ^= dataHeaderCRCMask[1]
+
+	return buffer
+}
+
+// ExtractSMSText finds the text of an SMS carried in a DMR data call
+// IP packet data is unwrapped from its IPv4/UDP headers, then the text is
+// located as UTF-16LE (Motorola/Hytera) or plain ASCII.
+func ExtractSMSText(payload []byte, sap uint8) string {
+	if sap == SAP_IP_PACKET && len(payload) >= 28 && payload[0]>>4 == 4 {
+		ihl := int(payload[0]&0x0F) * 4
+		if payload[9] == 17 && len(payload) >= ihl+8 { // UDP
+			payload = payload[ihl+8:]
+		}
+	}
+
+	if text := longestUTF16Run(payload); len(text) >= 2 {
+		return text
+	}
+	return longestASCIIRun(payload)
+}
+
+// longestUTF16Run returns the longest printable UTF-16LE run in data
+func longestUTF16Run(data []byte) string {
+	best := ""
+	for offset := 0; offset < 2; offset++ {
+		var current strings.Builder
+		for i := offset; i+1 < len(data); i += 2 {
+			r := rune(data[i]) | rune(data[i+1])<<8
+			if data[i+1] == 0 && unicode.IsPrint(r) {
+				current.WriteRune(r)
+				continue
+			}
+			if current.Len() > len(best) {
+				best = current.String()
+			}
+			current.Reset()
+		}
+		if current.Len() > len(best) {
+			best = current.String()
+		}
+	}
+	return strings.TrimSpace(best)
+}
+
+// longestASCIIRun returns the longest printable ASCII run in data
+func longestASCIIRun(data []byte) string {
+	best, start := "", 0
+	for i := 0; i <= len(data); i++ {
+		if i < len(data) && data[i] >= 0x20 && data[i] < 0x7F {
+			continue
+		}
+		if i-start > len(best) {
+			best = string(data[start:i])
+		}
+		start = i + 1
+	}
+	return strings.TrimSpace(best)
+}
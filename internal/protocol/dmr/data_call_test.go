@@ -0,0 +1,161 @@
+package dmr
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+)
+
+func encodeTestBurst(t *testing.T, payload []byte) []byte {
+	t.Helper()
+	frame, ok := codec.NewBPTC19696().Encode(payload)
+	if !ok {
+		t.Fatal("BPTC encode failed")
+	}
+	return frame
+}
+
+func TestDataHeader_RoundTrip(t *testing.T) {
+	header := &DataHeader{
+		Group:          false,
+		DPF:            DPF_UNCONFIRMED_DATA,
+		SAP:            SAP_IP_PACKET,
+		PadOctets:      17,
+		DstID:          3100123,
+		SrcID:          3100456,
+		BlocksToFollow: 5,
+	}
+
+	parsed, err := ParseDataHeader(BuildDataHeader(header))
+	if err != nil {
+		t.Fatalf("ParseDataHeader() error = %v", err)
+	}
+	if *parsed != *header {
+		t.Errorf("ParseDataHeader() = %+v, want %+v", *parsed, *header)
+	}
+
+	corrupted := BuildDataHeader(header)
+	corrupted[3] ^= 0x01
+	if _, err := ParseDataHeader(corrupted); err == nil {
+		t.Error("expected CRC error for corrupted header")
+	}
+}
+
+func TestDataCallAssembler_SMS(t *testing.T) {
+	// UTF-16LE text followed by the 4-byte message CRC
+	text := "HELLO YSF"
+	var message []byte
+	message = append(message, 0x00, 0x12, 0xA0, 0x00) // TMS-style preamble
+	for _, r := range text {
+		message = append(message, byte(r), 0x00)
+	}
+
+	blocks := (len(message) + DATA_CRC32_LENGTH + DATA_BLOCK_RATE12_SIZE - 1) / DATA_BLOCK_RATE12_SIZE
+	padOctets := blocks*DATA_BLOCK_RATE12_SIZE - len(message) - DATA_CRC32_LENGTH
+	data := make([]byte, blocks*DATA_BLOCK_RATE12_SIZE)
+	copy(data, message)
+	binary.LittleEndian.PutUint32(data[len(data)-DATA_CRC32_LENGTH:], dataCRC32(data[:len(data)-DATA_CRC32_LENGTH]))
+
+	header := &DataHeader{
+		DPF:            DPF_UNCONFIRMED_DATA,
+		SAP:            SAP_SHORT_DATA,
+		PadOctets:      uint8(padOctets),
+		DstID:          91,
+		SrcID:          3100456,
+		BlocksToFollow: uint8(blocks),
+	}
+
+	assembler := NewDataCallAssembler()
+	if _, err := assembler.AddHeader(encodeTestBurst(t, BuildDataHeader(header))); err != nil {
+		t.Fatalf("AddHeader() error = %v", err)
+	}
+
+	var result *DataMessage
+	for i := 0; i < blocks; i++ {
+		msg, err := assembler.AddBlock(encodeTestBurst(t, data[i*DATA_BLOCK_RATE12_SIZE:(i+1)*DATA_BLOCK_RATE12_SIZE]))
+		if err != nil {
+			t.Fatalf("AddBlock(%d) error = %v", i, err)
+		}
+		if i < blocks-1 && msg != nil {
+			t.Fatalf("AddBlock(%d) returned message early", i)
+		}
+		result = msg
+	}
+
+	if result == nil {
+		t.Fatal("no message after final block")
+	}
+	if len(result.Payload) != len(message) {
+		t.Errorf("payload length = %d, want %d", len(result.Payload), len(message))
+	}
+	if result.Text != text {
+		t.Errorf("Text = %q, want %q", result.Text, text)
+	}
+	if assembler.InProgress() {
+		t.Error("assembler still in progress after completion")
+	}
+}
+
+func TestDataCallAssembler_CRCError(t *testing.T) {
+	sms, err := BuildSMS(3100456, 91, true, "Hello from YSF")
+	if err != nil {
+		t.Fatalf("BuildSMS() error = %v", err)
+	}
+
+	// Corrupt a payload byte before the BPTC encoding, which would otherwise
+	// correct it
+	blocks := make([][]byte, len(sms.Blocks))
+	for i, block := range sms.Blocks {
+		blocks[i] = append([]byte(nil), block...)
+	}
+	blocks[1][5] ^= 0x20
+
+	assembler := NewDataCallAssembler()
+	for attempt, corrupt := range []bool{true, false} {
+		if _, err := assembler.AddHeader(encodeTestBurst(t, BuildDataHeader(sms.Header))); err != nil {
+			t.Fatalf("AddHeader() error = %v", err)
+		}
+		var message *DataMessage
+		for i := range blocks {
+			block := sms.Blocks[i]
+			if corrupt {
+				block = blocks[i]
+			}
+			message, err = assembler.AddBlock(encodeTestBurst(t, block))
+			if i < len(blocks)-1 && err != nil {
+				t.Fatalf("AddBlock(%d) error = %v", i, err)
+			}
+		}
+		if corrupt && (message != nil || err == nil) {
+			t.Errorf("corrupted message = %+v, %v; want CRC error", message, err)
+		}
+		if !corrupt && (message == nil || message.Text != "Hello from YSF") {
+			t.Errorf("attempt %d: message = %+v, %v", attempt, message, err)
+		}
+		if assembler.InProgress() {
+			t.Errorf("attempt %d: assembler still in progress", attempt)
+		}
+	}
+	if assembler.CRCErrors() != 1 {
+		t.Errorf("CRCErrors() = %d, want 1", assembler.CRCErrors())
+	}
+}
+
+func TestDataCallAssembler_BlockWithoutHeader(t *testing.T) {
+	assembler := NewDataCallAssembler()
+	if _, err := assembler.AddBlock(make([]byte, DMR_FRAME_LENGTH)); err == nil {
+		t.Error("expected error for block without header")
+	}
+}
+
+func TestExtractSMSText_IPUDP(t *testing.T) {
+	packet := make([]byte, 28)
+	packet[0] = 0x45 // IPv4, IHL 5
+	packet[9] = 17   // UDP
+	packet = append(packet, []byte("73 de N0CALL")...)
+
+	if got := ExtractSMSText(packet, SAP_IP_PACKET); got != "73 de N0CALL" {
+		t.Errorf("ExtractSMSText() = %q", got)
+	}
+}
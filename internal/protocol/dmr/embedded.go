@@ -0,0 +1,190 @@
+package dmr
+
+import (
+	"github.com/dbehnke/ysf2dmr/internal/bits"
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+	"github.com/dbehnke/ysf2dmr/internal/correction"
+)
+
+// EMBEDDED_LC_LENGTH is the size of the LC carried in embedded signalling
+const EMBEDDED_LC_LENGTH = 9
+
+// Rows of the embedded LC block holding the 72 LC bits, as [start, end)
+// (ETSI TS 102 361-1 B.2.1); the 5-bit checksum takes the last bit of rows
+// 2-6 and Hamming(16,11,4) parity the remaining 5 bits of rows 0-6
+var (
+	embeddedLCRows     = [7][2]int{{0, 11}, {16, 27}, {32, 42}, {48, 58}, {64, 74}, {80, 90}, {96, 106}}
+	embeddedLCChecksum = [5]int{106, 90, 74, 58, 42} // bit 0 first
+)
+
+// EMB is the embedded signalling field of voice bursts B-F
+type EMB struct {
+	ColorCode uint8
+	PI        bool  // privacy indicator
+	LCSS      uint8 // link control start/stop
+}
+
+// DecodeEMB decodes the QR(16,7,6) protected EMB of a voice burst
+func DecodeEMB(burst []byte) (EMB, bool) {
+	if len(burst) < DMR_FRAME_LENGTH {
+		return EMB{}, false
+	}
+
+	emb := []byte{burst[13]<<4 | burst[14]>>4, burst[18]<<4 | burst[19]>>4}
+	value := codec.QR1676Decode(emb)
+	return EMB{
+		ColorCode: value >> 3,
+		PI:        value&0x04 != 0,
+		LCSS:      value & 0x03,
+	}, true
+}
+
+// EmbeddedLCDecoder reassembles the LC carried in the embedded signalling of
+// voice bursts B-E
+// Fragments are collected from a burst with LCSS first through one with LCSS
+// last; a block that fails its Hamming, parity or checksum checks is dropped.
+type EmbeddedLCDecoder struct {
+	raw       [128]bool
+	fragments int // collected so far, -1 while waiting for a first fragment
+}
+
+// NewEmbeddedLCDecoder creates a decoder waiting for a first fragment
+func NewEmbeddedLCDecoder() *EmbeddedLCDecoder {
+	return &EmbeddedLCDecoder{fragments: -1}
+}
+
+// Reset drops any fragments collected
+func (d *EmbeddedLCDecoder) Reset() {
+	d.fragments = -1
+}
+
+// Add adds the embedded signalling of a voice burst B-F, returning the 9-byte
+// LC once the burst completing it is added
+func (d *EmbeddedLCDecoder) Add(burst []byte) ([]byte, bool) {
+	emb, ok := DecodeEMB(burst)
+	if !ok {
+		return nil, false
+	}
+
+	switch emb.LCSS {
+	case LCSS_FIRST:
+		d.fragments = 0
+	case LCSS_CONTINUATION:
+		if d.fragments < 1 || d.fragments > 2 {
+			d.fragments = -1
+			return nil, false
+		}
+	case LCSS_LAST:
+		if d.fragments != 3 {
+			d.fragments = -1
+			return nil, false
+		}
+	default:
+		// Null embedded data or a single fragment reverse channel
+		return nil, false
+	}
+
+	for i := 0; i < 32; i++ {
+		d.raw[d.fragments*32+i] = bits.Read(burst, uint32(116+i))
+	}
+	d.fragments++
+
+	if emb.LCSS != LCSS_LAST {
+		return nil, false
+	}
+	d.fragments = -1
+	return decodeEmbeddedLC(d.raw)
+}
+
+// encodeEmbeddedLC protects a 9-byte LC with the 5-bit checksum and the
+// Hamming(16,11,4) block code, interleaved in columns for bursts B-E
+// (ETSI TS 102 361-1 B.2.1)
+func encodeEmbeddedLC(lc []byte) [128]bool {
+	var lcBits [72]bool
+	bits.ToBools(lcBits[:], lc)
+
+	var data [128]bool
+	checksum := correction.FiveBitChecksum(lc)
+	for i, pos := range embeddedLCChecksum {
+		data[pos] = checksum&(1<<i) != 0
+	}
+
+	b := 0
+	for _, row := range embeddedLCRows {
+		for a := row[0]; a < row[1]; a++ {
+			data[a] = lcBits[b]
+			b++
+		}
+	}
+
+	for a := 0; a < 112; a += 16 {
+		correction.Encode16114(data[a : a+16])
+	}
+	for a := 0; a < 16; a++ {
+		for row := 0; row < 112; row += 16 {
+			data[112+a] = data[112+a] != data[row+a]
+		}
+	}
+
+	var raw [128]bool
+	b = 0
+	for a := range raw {
+		raw[a] = data[b]
+		b += 16
+		if b > 127 {
+			b -= 127
+		}
+	}
+	return raw
+}
+
+// decodeEmbeddedLC undoes the interleave of an embedded LC block, corrects a
+// single bit error in each row and checks the column parity and checksum
+func decodeEmbeddedLC(raw [128]bool) ([]byte, bool) {
+	var data [128]bool
+	b := 0
+	for a := range raw {
+		data[b] = raw[a]
+		b += 16
+		if b > 127 {
+			b -= 127
+		}
+	}
+
+	for a := 0; a < 112; a += 16 {
+		if !correction.Decode16114(data[a : a+16]) {
+			return nil, false
+		}
+	}
+	for a := 0; a < 16; a++ {
+		parity := false
+		for row := 0; row < 128; row += 16 {
+			parity = parity != data[row+a]
+		}
+		if parity {
+			return nil, false
+		}
+	}
+
+	var lcBits [72]bool
+	b = 0
+	for _, row := range embeddedLCRows {
+		for a := row[0]; a < row[1]; a++ {
+			lcBits[b] = data[a]
+			b++
+		}
+	}
+	lc := make([]byte, EMBEDDED_LC_LENGTH)
+	bits.FromBools(lc, lcBits[:])
+
+	var checksum uint8
+	for i, pos := range embeddedLCChecksum {
+		if data[pos] {
+			checksum |= 1 << i
+		}
+	}
+	if checksum != correction.FiveBitChecksum(lc) {
+		return nil, false
+	}
+	return lc, true
+}
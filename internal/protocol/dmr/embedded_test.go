@@ -0,0 +1,92 @@
+package dmr
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
+)
+
+// voiceSuperframe returns bursts A-F carrying lc as embedded LC
+func voiceSuperframe(lc *LinkControl, colorCode uint8) [][]byte {
+	a := NewBurstAssembler(colorCode, BS_SOURCED)
+	a.StartVoice(lc)
+	bursts := make([][]byte, VOICE_SUPERFRAME_LENGTH)
+	for i := range bursts {
+		bursts[i] = make([]byte, DMR_FRAME_LENGTH)
+		a.Voice(bursts[i])
+	}
+	return bursts
+}
+
+func TestDecodeEMB(t *testing.T) {
+	bursts := voiceSuperframe(&LinkControl{SourceID: 3100001, DestinationID: 91}, 12)
+	for n, want := range []uint8{LCSS_FIRST, LCSS_CONTINUATION, LCSS_CONTINUATION, LCSS_LAST, LCSS_SINGLE} {
+		burst := bursts[n+1]
+		bits.Flip(burst, 104) // a bit error in the EMB is corrected
+		emb, ok := DecodeEMB(burst)
+		if !ok || emb.ColorCode != 12 || emb.LCSS != want || emb.PI {
+			t.Errorf("burst %d EMB = %+v, want cc 12 lcss %d", n+1, emb, want)
+		}
+	}
+	if _, ok := DecodeEMB(make([]byte, 10)); ok {
+		t.Error("DecodeEMB accepted a short burst")
+	}
+}
+
+func TestEmbeddedLCDecoder(t *testing.T) {
+	lc := &LinkControl{FLCO: FLCO_UNIT_TO_UNIT, SourceID: 3100001, DestinationID: 3100002}
+	lc.SetEmergency(true)
+	bursts := voiceSuperframe(lc, 1)
+
+	// One bit error in a fragment is corrected by the Hamming code
+	bits.Flip(bursts[2], 120)
+
+	d := NewEmbeddedLCDecoder()
+	var decoded []byte
+	for n, burst := range bursts[1:] {
+		got, ok := d.Add(burst)
+		if ok != (n == 3) {
+			t.Fatalf("burst %d: complete = %v", n+1, ok)
+		}
+		if ok {
+			decoded = got
+		}
+	}
+	if !bytes.Equal(decoded, lc.Encode()) {
+		t.Fatalf("decoded LC % X, want % X", decoded, lc.Encode())
+	}
+}
+
+func TestEmbeddedLCDecoder_MissingFragment(t *testing.T) {
+	bursts := voiceSuperframe(&LinkControl{SourceID: 3100001, DestinationID: 91}, 1)
+
+	d := NewEmbeddedLCDecoder()
+	for _, n := range []int{1, 2, 4} { // burst D lost
+		if _, ok := d.Add(bursts[n]); ok {
+			t.Fatal("LC decoded without burst D")
+		}
+	}
+
+	// Joining part way through the superframe waits for the next burst B
+	d.Reset()
+	for _, n := range []int{3, 4} {
+		if _, ok := d.Add(bursts[n]); ok {
+			t.Fatal("LC decoded from a partial superframe")
+		}
+	}
+}
+
+func TestEmbeddedLCDecoder_Corrupted(t *testing.T) {
+	bursts := voiceSuperframe(&LinkControl{SourceID: 3100001, DestinationID: 91}, 1)
+	for i := uint32(116); i < 124; i++ {
+		bits.Flip(bursts[3], i)
+	}
+
+	d := NewEmbeddedLCDecoder()
+	for _, burst := range bursts[1:5] {
+		if lc, ok := d.Add(burst); ok {
+			t.Fatalf("corrupted block decoded as % X", lc)
+		}
+	}
+}
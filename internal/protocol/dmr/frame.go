@@ -27,6 +27,12 @@ const (
 	DATA_TYPE_DATA_FRAME       = 0x0B
 	DATA_TYPE_DATA_TERMINATOR  = 0x0C
 
+	// Service options (full LC byte 2)
+	SERVICE_OPTION_EMERGENCY = 0x80
+	SERVICE_OPTION_PRIVACY   = 0x40
+	SERVICE_OPTION_BROADCAST = 0x08
+	SERVICE_OPTION_OVCM      = 0x04
+
 	// Color code range
 	COLOR_CODE_MIN = 0
 	COLOR_CODE_MAX = 15
@@ -63,6 +69,7 @@ type Data struct {
 
 // LinkControl represents DMR Link Control information
 type LinkControl struct {
+	PF            bool   // Protect flag
 	FLCO          uint8  // Forward Link Class Operation
 	SourceID      uint32 // Source radio ID (24-bit)
 	DestinationID uint32 // Destination ID (24-bit)
@@ -232,27 +239,31 @@ func (d *Data) String() string {
 }
 
 // Encode encodes the Link Control information into 9 bytes
+// Layout follows the ETSI full LC: PF|R|FLCO, FID, service options, destination, source
 func (lc *LinkControl) Encode() []byte {
 	data := make([]byte, 9)
 
-	// Byte 0: FLCO and reserved bits
-	data[0] = (lc.FLCO << 2) // FLCO in upper 6 bits
+	// Byte 0: Protect flag, reserved bit and FLCO (6 bits)
+	data[0] = lc.FLCO & 0x3F
+	if lc.PF {
+		data[0] |= 0x80
+	}
 
-	// Bytes 1-3: Destination ID (24-bit, big-endian)
-	data[1] = uint8((lc.DestinationID >> 16) & 0xFF)
-	data[2] = uint8((lc.DestinationID >> 8) & 0xFF)
-	data[3] = uint8(lc.DestinationID & 0xFF)
+	// Byte 1: Feature ID
+	data[1] = lc.FID
 
-	// Bytes 4-6: Source ID (24-bit, big-endian)
-	data[4] = uint8((lc.SourceID >> 16) & 0xFF)
-	data[5] = uint8((lc.SourceID >> 8) & 0xFF)
-	data[6] = uint8(lc.SourceID & 0xFF)
+	// Byte 2: Service options
+	data[2] = lc.Options
 
-	// Byte 7: Feature ID
-	data[7] = lc.FID
+	// Bytes 3-5: Destination ID (24-bit, big-endian)
+	data[3] = uint8((lc.DestinationID >> 16) & 0xFF)
+	data[4] = uint8((lc.DestinationID >> 8) & 0xFF)
+	data[5] = uint8(lc.DestinationID & 0xFF)
 
-	// Byte 8: Options
-	data[8] = lc.Options
+	// Bytes 6-8: Source ID (24-bit, big-endian)
+	data[6] = uint8((lc.SourceID >> 16) & 0xFF)
+	data[7] = uint8((lc.SourceID >> 8) & 0xFF)
+	data[8] = uint8(lc.SourceID & 0xFF)
 
 	return data
 }
@@ -263,24 +274,39 @@ func (lc *LinkControl) Decode(data []byte) error {
 		return fmt.Errorf("Link Control data too short: got %d bytes, need 9", len(data))
 	}
 
-	// Byte 0: FLCO and reserved bits
-	lc.FLCO = (data[0] >> 2) & 0x3F
+	// Byte 0: Protect flag, reserved bit and FLCO (6 bits)
+	lc.PF = (data[0] & 0x80) != 0
+	lc.FLCO = data[0] & 0x3F
 
-	// Bytes 1-3: Destination ID (24-bit, big-endian)
-	lc.DestinationID = (uint32(data[1]) << 16) | (uint32(data[2]) << 8) | uint32(data[3])
+	// Byte 1: Feature ID
+	lc.FID = data[1]
 
-	// Bytes 4-6: Source ID (24-bit, big-endian)
-	lc.SourceID = (uint32(data[4]) << 16) | (uint32(data[5]) << 8) | uint32(data[6])
+	// Byte 2: Service options
+	lc.Options = data[2]
 
-	// Byte 7: Feature ID
-	lc.FID = data[7]
+	// Bytes 3-5: Destination ID (24-bit, big-endian)
+	lc.DestinationID = (uint32(data[3]) << 16) | (uint32(data[4]) << 8) | uint32(data[5])
 
-	// Byte 8: Options
-	lc.Options = data[8]
+	// Bytes 6-8: Source ID (24-bit, big-endian)
+	lc.SourceID = (uint32(data[6]) << 16) | (uint32(data[7]) << 8) | uint32(data[8])
 
 	return nil
 }
 
+// IsEmergency returns true if the emergency service option is set
+func (lc *LinkControl) IsEmergency() bool {
+	return (lc.Options & SERVICE_OPTION_EMERGENCY) != 0
+}
+
+// SetEmergency sets or clears the emergency service option
+func (lc *LinkControl) SetEmergency(emergency bool) {
+	if emergency {
+		lc.Options |= SERVICE_OPTION_EMERGENCY
+	} else {
+		lc.Options &^= SERVICE_OPTION_EMERGENCY
+	}
+}
+
 // Parse parses embedded data from 8 bytes
 func (emb *EmbeddedData) Parse(data []byte) error {
 	if len(data) < 8 {
@@ -0,0 +1,77 @@
+package dmr
+
+import (
+	"fmt"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+	"github.com/dbehnke/ysf2dmr/internal/correction"
+)
+
+// Data types carried in the slot type of full LC bursts
+const (
+	DT_VOICE_LC_HEADER    = 0x01
+	DT_TERMINATOR_WITH_LC = 0x02
+)
+
+// RS(12,9) parity masks for the full LC (ETSI TS 102 361-1 B.3.12)
+var (
+	voiceLCHeaderCRCMask = [3]byte{0x96, 0x96, 0x96}
+	terminatorCRCMask    = [3]byte{0x99, 0x99, 0x99}
+)
+
+// BuildFullLCBurst builds a voice LC header or terminator burst carrying lc
+func BuildFullLCBurst(lc *LinkControl, dataType uint8, colorCode uint8) ([]byte, error) {
+	mask, err := fullLCMask(dataType)
+	if err != nil {
+		return nil, err
+	}
+
+	var codeword [correction.RS129_LENGTH]byte
+	copy(codeword[:9], lc.Encode())
+	if err := correction.RS129Encode(codeword[:]); err != nil {
+		return nil, err
+	}
+	for i := 0; i < 3; i++ {
+		codeword[9+i] ^= mask[i]
+	}
+
+	return BuildDataBurst(codeword[:], dataType, colorCode)
+}
+
+// DecodeFullLCBurst decodes the link control from a voice LC header or terminator burst
+// A single corrupted byte of the LC is corrected by the RS(12,9) code.
+func DecodeFullLCBurst(burst []byte, dataType uint8) (*LinkControl, error) {
+	mask, err := fullLCMask(dataType)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, ok := codec.NewBPTC19696().Decode(burst)
+	if !ok {
+		return nil, fmt.Errorf("full LC burst too short: %d bytes", len(burst))
+	}
+
+	for i := 0; i < 3; i++ {
+		payload[9+i] ^= mask[i]
+	}
+	if correction.RS129Decode(payload) == 0xFF {
+		return nil, fmt.Errorf("full LC RS(12,9) check failed")
+	}
+
+	lc := &LinkControl{}
+	if err := lc.Decode(payload[:9]); err != nil {
+		return nil, err
+	}
+	return lc, nil
+}
+
+func fullLCMask(dataType uint8) ([3]byte, error) {
+	switch dataType {
+	case DT_VOICE_LC_HEADER:
+		return voiceLCHeaderCRCMask, nil
+	case DT_TERMINATOR_WITH_LC:
+		return terminatorCRCMask, nil
+	default:
+		return [3]byte{}, fmt.Errorf("data type 0x%02X does not carry a full LC", dataType)
+	}
+}
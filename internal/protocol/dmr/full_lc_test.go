@@ -0,0 +1,78 @@
+package dmr
+
+import (
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/correction"
+)
+
+func TestFullLCBurst_RoundTrip(t *testing.T) {
+	lc := &LinkControl{
+		FLCO:          0x00,
+		SourceID:      3120001,
+		DestinationID: 91,
+	}
+	lc.SetEmergency(true)
+
+	for _, dataType := range []uint8{DT_VOICE_LC_HEADER, DT_TERMINATOR_WITH_LC} {
+		burst, err := BuildFullLCBurst(lc, dataType, 1)
+		if err != nil {
+			t.Fatalf("BuildFullLCBurst(0x%02X) error: %v", dataType, err)
+		}
+
+		decoded, err := DecodeFullLCBurst(burst, dataType)
+		if err != nil {
+			t.Fatalf("DecodeFullLCBurst(0x%02X) error: %v", dataType, err)
+		}
+		if decoded.SourceID != lc.SourceID || decoded.DestinationID != lc.DestinationID {
+			t.Errorf("decoded IDs %d->%d, want %d->%d",
+				decoded.SourceID, decoded.DestinationID, lc.SourceID, lc.DestinationID)
+		}
+		if !decoded.IsEmergency() {
+			t.Error("emergency flag lost in round trip")
+		}
+	}
+}
+
+func TestFullLCBurst_WrongMask(t *testing.T) {
+	lc := &LinkControl{SourceID: 1234567, DestinationID: 9}
+
+	burst, err := BuildFullLCBurst(lc, DT_VOICE_LC_HEADER, 1)
+	if err != nil {
+		t.Fatalf("BuildFullLCBurst error: %v", err)
+	}
+	if _, err := DecodeFullLCBurst(burst, DT_TERMINATOR_WITH_LC); err == nil {
+		t.Error("expected RS(12,9) failure when decoding a header as a terminator")
+	}
+	if _, err := BuildFullLCBurst(lc, 0x03, 1); err == nil {
+		t.Error("expected error for a data type without a full LC")
+	}
+}
+
+func TestFullLCBurst_CorrectsByteError(t *testing.T) {
+	lc := &LinkControl{SourceID: 3120001, DestinationID: 91}
+
+	codeword := make([]byte, 12)
+	copy(codeword, lc.Encode())
+	if err := correction.RS129Encode(codeword); err != nil {
+		t.Fatalf("RS129Encode error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		codeword[9+i] ^= voiceLCHeaderCRCMask[i]
+	}
+	codeword[7] ^= 0xA5 // Corrupt a byte of the source ID
+
+	burst, err := BuildDataBurst(codeword, DT_VOICE_LC_HEADER, 1)
+	if err != nil {
+		t.Fatalf("BuildDataBurst error: %v", err)
+	}
+
+	decoded, err := DecodeFullLCBurst(burst, DT_VOICE_LC_HEADER)
+	if err != nil {
+		t.Fatalf("DecodeFullLCBurst error: %v", err)
+	}
+	if decoded.SourceID != lc.SourceID || decoded.DestinationID != lc.DestinationID {
+		t.Errorf("decoded IDs %d->%d, want %d->%d",
+			decoded.SourceID, decoded.DestinationID, lc.SourceID, lc.DestinationID)
+	}
+}
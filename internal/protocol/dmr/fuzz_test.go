@@ -0,0 +1,99 @@
+package dmr
+
+import (
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+)
+
+func FuzzDataParse(f *testing.F) {
+	f.Add((&Data{SlotNumber: 1, SourceID: 3100123, DestinationID: 91, FLCO: FLCO_GROUP_CALL}).Build())
+	f.Add((&Data{SlotNumber: 2, SourceID: 3100123, DestinationID: 3100456, FLCO: FLCO_UNIT_TO_UNIT}).Build())
+	f.Add(make([]byte, DMR_FRAME_LENGTH-1))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var frame Data
+		if err := frame.Parse(data); err != nil {
+			return
+		}
+		if frame.SlotNumber != 1 && frame.SlotNumber != 2 {
+			t.Fatalf("Parse() accepted slot %d", frame.SlotNumber)
+		}
+		if len(frame.Payload) != DMR_PAYLOAD_LENGTH {
+			t.Fatalf("len(Payload) = %d, want %d", len(frame.Payload), DMR_PAYLOAD_LENGTH)
+		}
+
+		var lc LinkControl
+		lc.Decode(frame.Payload)
+		var emb EmbeddedData
+		emb.Parse(frame.Payload)
+		var st SlotType
+		st.Decode(frame.Payload)
+		DetectSync(frame.Payload)
+	})
+}
+
+func FuzzDecodeFullLCBurst(f *testing.F) {
+	lc := &LinkControl{FLCO: FLCO_GROUP_CALL, DestinationID: 91, SourceID: 3100123}
+	for _, dataType := range []uint8{DT_VOICE_LC_HEADER, DT_TERMINATOR_WITH_LC} {
+		burst, err := BuildFullLCBurst(lc, dataType, 1)
+		if err != nil {
+			f.Fatalf("BuildFullLCBurst() error = %v", err)
+		}
+		f.Add(burst, dataType)
+	}
+	f.Add(make([]byte, 10), uint8(DT_VOICE_LC_HEADER))
+
+	f.Fuzz(func(t *testing.T, burst []byte, dataType uint8) {
+		decoded, err := DecodeFullLCBurst(burst, dataType)
+		if err != nil {
+			return
+		}
+		if decoded.DestinationID > 0xFFFFFF || decoded.SourceID > 0xFFFFFF {
+			t.Fatalf("decoded IDs out of range: %d -> %d", decoded.SourceID, decoded.DestinationID)
+		}
+	})
+}
+
+// FuzzDataCallAssembler feeds a header burst followed by any number of
+// block bursts, each DMR_FRAME_LENGTH bytes, through the data call assembler
+func FuzzDataCallAssembler(f *testing.F) {
+	sms, err := BuildSMS(3100123, 3100456, false, "HELLO YSF")
+	if err != nil {
+		f.Fatalf("BuildSMS() error = %v", err)
+	}
+	bptc := codec.NewBPTC19696()
+	seed, _ := bptc.Encode(BuildDataHeader(sms.Header))
+	for _, block := range sms.Blocks {
+		burst, _ := bptc.Encode(block)
+		seed = append(seed, burst...)
+	}
+	f.Add(seed)
+	f.Add(seed[:DMR_FRAME_LENGTH])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		assembler := NewDataCallAssembler()
+		if len(data) < DMR_FRAME_LENGTH {
+			return
+		}
+		header, err := assembler.AddHeader(data[:DMR_FRAME_LENGTH])
+		if err != nil {
+			return
+		}
+
+		for data = data[DMR_FRAME_LENGTH:]; len(data) > 0 && assembler.InProgress(); {
+			n := DMR_FRAME_LENGTH
+			if n > len(data) {
+				n = len(data)
+			}
+			message, err := assembler.AddBlock(data[:n])
+			data = data[n:]
+			if err != nil || message == nil {
+				continue
+			}
+			if max := int(header.BlocksToFollow) * DATA_BLOCK_RATE12_SIZE; len(message.Payload) > max {
+				t.Fatalf("len(Payload) = %d, more than %d blocks hold", len(message.Payload), header.BlocksToFollow)
+			}
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package dmr
+
+import (
+	"encoding/binary"
+)
+
+// LRRP (Motorola Location Request/Response Protocol) constants
+const (
+	LRRP_UDP_PORT = 4001
+
+	LRRP_IMMEDIATE_RESPONSE = 0x05
+	LRRP_TRIGGERED_RESPONSE = 0x07
+	LRRP_TRIGGERED_REPORT   = 0x0D
+
+	lrrpTokenRequestID = 0x22 // length octet, then the ID
+	lrrpTokenTime      = 0x34 // 5 octets
+	lrrpTokenResult    = 0x37 // 1 octet
+	lrrpTokenPoint2D   = 0x51 // latitude, longitude
+	lrrpTokenCircle2D  = 0x54 // latitude, longitude, 2 octet radius
+	lrrpTokenDirection = 0x56 // 1 octet, units of 2 degrees
+)
+
+// GPS Info LC, sent in the embedded signalling by radios reporting their
+// position during a voice call (ETSI TS 102 361-2)
+const (
+	FLCO_GPS_INFO = 0x08
+
+	gpsInfoPositionUnknown = 0x07
+)
+
+// Position is a location reported by a DMR radio
+type Position struct {
+	Latitude  float64 // degrees, north positive
+	Longitude float64 // degrees, east positive
+	Course    int     // degrees from north, -1 if not reported
+}
+
+// valid returns true if the position is on the globe and not the 0,0 a radio
+// without a GPS fix reports
+func (p Position) valid() bool {
+	if p.Latitude == 0 && p.Longitude == 0 {
+		return false
+	}
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180
+}
+
+// ExtractLRRP finds the position in an LRRP report carried in a DMR data call
+// Only IPv4/UDP packets to the LRRP port are decoded.
+func ExtractLRRP(payload []byte, sap uint8) (Position, bool) {
+	if sap != SAP_IP_PACKET || len(payload) < SMS_IP_HEADER_SIZE+SMS_UDP_HDR_SIZE || payload[0]>>4 != 4 {
+		return Position{}, false
+	}
+	ihl := int(payload[0]&0x0F) * 4
+	if payload[9] != 17 || len(payload) < ihl+SMS_UDP_HDR_SIZE {
+		return Position{}, false
+	}
+	udp := payload[ihl:]
+	if binary.BigEndian.Uint16(udp[2:4]) != LRRP_UDP_PORT {
+		return Position{}, false
+	}
+	return ParseLRRP(udp[SMS_UDP_HDR_SIZE:])
+}
+
+// ParseLRRP decodes the position in an LRRP location response or report
+// Decoding stops at the first token it does not know, keeping the position
+// if one came before it.
+func ParseLRRP(data []byte) (Position, bool) {
+	if len(data) < 2 {
+		return Position{}, false
+	}
+	switch data[0] {
+	case LRRP_IMMEDIATE_RESPONSE, LRRP_TRIGGERED_RESPONSE, LRRP_TRIGGERED_REPORT:
+	default:
+		return Position{}, false
+	}
+	end := 2 + int(data[1])
+	if end > len(data) {
+		end = len(data)
+	}
+
+	pos := Position{Course: -1}
+	found := false
+	tokens := data[2:end]
+	for len(tokens) > 0 {
+		var size int
+		switch tokens[0] {
+		case lrrpTokenRequestID:
+			if len(tokens) < 2 {
+				return pos, found && pos.valid()
+			}
+			size = 2 + int(tokens[1])
+		case lrrpTokenTime:
+			size = 6
+		case lrrpTokenResult, lrrpTokenDirection:
+			size = 2
+		case lrrpTokenPoint2D:
+			size = 9
+		case lrrpTokenCircle2D:
+			size = 11
+		default:
+			return pos, found && pos.valid()
+		}
+		if len(tokens) < size {
+			return pos, found && pos.valid()
+		}
+
+		switch tokens[0] {
+		case lrrpTokenPoint2D, lrrpTokenCircle2D:
+			pos.Latitude = float64(int32(binary.BigEndian.Uint32(tokens[1:5]))) * 180 / (1 << 32)
+			pos.Longitude = float64(int32(binary.BigEndian.Uint32(tokens[5:9]))) * 360 / (1 << 32)
+			found = true
+		case lrrpTokenDirection:
+			pos.Course = int(tokens[1]) * 2 % 360
+		}
+		tokens = tokens[size:]
+	}
+	return pos, found && pos.valid()
+}
+
+// IsGPSInfo returns true if an LC carries a GPS Info position
+func IsGPSInfo(lc []byte) bool {
+	return len(lc) >= EMBEDDED_LC_LENGTH && lc[0]&0x3F == FLCO_GPS_INFO && lc[1] == 0
+}
+
+// DecodeGPSInfo decodes the position in a GPS Info LC
+func DecodeGPSInfo(lc []byte) (Position, bool) {
+	if !IsGPSInfo(lc) || (lc[2]>>1)&0x07 == gpsInfoPositionUnknown {
+		return Position{}, false
+	}
+
+	lon := uint32(lc[2]&0x01)<<24 | uint32(lc[3])<<16 | uint32(lc[4])<<8 | uint32(lc[5])
+	lat := uint32(lc[6])<<16 | uint32(lc[7])<<8 | uint32(lc[8])
+
+	// Sign extend the 25 bit longitude and 24 bit latitude
+	pos := Position{
+		Latitude:  float64(int32(lat<<8)>>8) * 180 / (1 << 24),
+		Longitude: float64(int32(lon<<7)>>7) * 360 / (1 << 25),
+		Course:    -1,
+	}
+	return pos, pos.valid()
+}
@@ -0,0 +1,76 @@
+package dmr
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// lrrpReport builds a triggered location report at lat, lon
+func lrrpReport(lat, lon float64, extra ...byte) []byte {
+	tokens := []byte{lrrpTokenRequestID, 0x02, 0x12, 0x34, lrrpTokenPoint2D}
+	tokens = binary.BigEndian.AppendUint32(tokens, uint32(int32(math.Round(lat*(1<<32)/180))))
+	tokens = binary.BigEndian.AppendUint32(tokens, uint32(int32(math.Round(lon*(1<<32)/360))))
+	tokens = append(tokens, extra...)
+	return append([]byte{LRRP_TRIGGERED_REPORT, byte(len(tokens))}, tokens...)
+}
+
+func TestExtractLRRP(t *testing.T) {
+	packet := buildIPv4UDP(smsIPAddress(3100001, false), smsIPAddress(9, false), LRRP_UDP_PORT,
+		lrrpReport(42.5, -83.25, lrrpTokenDirection, 45))
+
+	pos, ok := ExtractLRRP(packet, SAP_IP_PACKET)
+	if !ok {
+		t.Fatal("ExtractLRRP() found no position")
+	}
+	if math.Abs(pos.Latitude-42.5) > 1e-6 || math.Abs(pos.Longitude+83.25) > 1e-6 {
+		t.Errorf("position = %f,%f, want 42.5,-83.25", pos.Latitude, pos.Longitude)
+	}
+	if pos.Course != 90 {
+		t.Errorf("Course = %d, want 90", pos.Course)
+	}
+
+	// An SMS on the TMS port is not a position
+	sms := buildIPv4UDP(smsIPAddress(3100001, false), smsIPAddress(9, false), SMS_UDP_PORT, lrrpReport(42.5, -83.25))
+	if _, ok := ExtractLRRP(sms, SAP_IP_PACKET); ok {
+		t.Error("ExtractLRRP() decoded a packet to the SMS port")
+	}
+}
+
+func TestParseLRRP_Invalid(t *testing.T) {
+	tests := map[string][]byte{
+		"no fix":        lrrpReport(0, 0),
+		"request":       {0x04, 0x02, lrrpTokenRequestID, 0x00},
+		"truncated":     lrrpReport(42.5, -83.25)[:10],
+		"unknown token": {LRRP_TRIGGERED_REPORT, 0x02, 0xEE, 0x00},
+	}
+	for name, data := range tests {
+		if pos, ok := ParseLRRP(data); ok {
+			t.Errorf("%s: ParseLRRP() = %+v, want no position", name, pos)
+		}
+	}
+}
+
+func TestDecodeGPSInfo(t *testing.T) {
+	lat := uint32(int32(math.Round(-33.75*(1<<24)/180))) & 0xFFFFFF
+	lon := uint32(int32(math.Round(151.25*(1<<25)/360))) & 0x1FFFFFF
+
+	lc := make([]byte, EMBEDDED_LC_LENGTH)
+	lc[0] = FLCO_GPS_INFO
+	lc[2] = byte(lon >> 24)
+	lc[3], lc[4], lc[5] = byte(lon>>16), byte(lon>>8), byte(lon)
+	lc[6], lc[7], lc[8] = byte(lat>>16), byte(lat>>8), byte(lat)
+
+	pos, ok := DecodeGPSInfo(lc)
+	if !ok {
+		t.Fatal("DecodeGPSInfo() found no position")
+	}
+	if math.Abs(pos.Latitude+33.75) > 1e-4 || math.Abs(pos.Longitude-151.25) > 1e-4 {
+		t.Errorf("position = %f,%f, want -33.75,151.25", pos.Latitude, pos.Longitude)
+	}
+
+	lc[2] |= gpsInfoPositionUnknown << 1
+	if _, ok := DecodeGPSInfo(lc); ok {
+		t.Error("DecodeGPSInfo() decoded a position marked unknown")
+	}
+}
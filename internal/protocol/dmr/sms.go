@@ -0,0 +1,152 @@
+package dmr
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// DMR SMS constants
+const (
+	SMS_UDP_PORT       = 4007 // Motorola TMS text messaging port
+	SMS_MAX_TEXT       = 140  // Maximum characters carried in one SMS
+	SMS_IP_HEADER_SIZE = 20
+	SMS_UDP_HDR_SIZE   = 8
+)
+
+// TMS text message header preceding the UTF-16LE text
+var smsTextHeader = []byte{0xA0, 0x00, 0x00, 0x04, 0x0D, 0x00, 0x0A, 0x00}
+
+// SMS is an outgoing DMR text message ready for transmission
+type SMS struct {
+	Header *DataHeader // Encoded with BuildDataHeader
+	Blocks [][]byte    // Unconfirmed rate 1/2 blocks (12 bytes each)
+}
+
+// BuildSMS builds an unconfirmed DMR SMS carried over IPv4/UDP
+// The text is sent UTF-16LE after a TMS header, the format used by Motorola
+// and Hytera radios and routed by the DMR masters.
+func BuildSMS(srcID, dstID uint32, group bool, text string) (*SMS, error) {
+	if len(text) == 0 {
+		return nil, fmt.Errorf("empty SMS text")
+	}
+
+	runes := []rune(text)
+	if len(runes) > SMS_MAX_TEXT {
+		runes = runes[:SMS_MAX_TEXT]
+	}
+
+	// TMS payload: length, header, UTF-16LE text
+	tms := make([]byte, 2, 2+len(smsTextHeader)+len(runes)*2)
+	tms = append(tms, smsTextHeader...)
+	for _, r := range runes {
+		if r > 0xFFFF {
+			r = '?'
+		}
+		tms = append(tms, byte(r), byte(r>>8))
+	}
+	binary.BigEndian.PutUint16(tms[0:2], uint16(len(tms)-2))
+
+	packet := buildIPv4UDP(smsIPAddress(srcID, false), smsIPAddress(dstID, group), SMS_UDP_PORT, tms)
+
+	// Pad to whole blocks, leaving room for the CRC-32 at the end of the last one
+	length := len(packet) + DATA_CRC32_LENGTH
+	blockCount := (length + DATA_BLOCK_RATE12_SIZE - 1) / DATA_BLOCK_RATE12_SIZE
+	if blockCount > DATA_MAX_BLOCKS {
+		return nil, fmt.Errorf("SMS too long: %d blocks", blockCount)
+	}
+	padOctets := blockCount*DATA_BLOCK_RATE12_SIZE - length
+
+	data := make([]byte, blockCount*DATA_BLOCK_RATE12_SIZE)
+	copy(data, packet)
+	crc := dataCRC32(data[:len(data)-DATA_CRC32_LENGTH])
+	binary.LittleEndian.PutUint32(data[len(data)-DATA_CRC32_LENGTH:], crc)
+
+	sms := &SMS{
+		Header: &DataHeader{
+			Group:          group,
+			DPF:            DPF_UNCONFIRMED_DATA,
+			SAP:            SAP_IP_PACKET,
+			PadOctets:      uint8(padOctets),
+			DstID:          dstID,
+			SrcID:          srcID,
+			BlocksToFollow: uint8(blockCount),
+		},
+	}
+
+	for i := 0; i < blockCount; i++ {
+		sms.Blocks = append(sms.Blocks, data[i*DATA_BLOCK_RATE12_SIZE:(i+1)*DATA_BLOCK_RATE12_SIZE])
+	}
+
+	return sms, nil
+}
+
+// smsIPAddress maps a DMR ID to its radio IP address (12.x.x.x, or 225.x.x.x for groups)
+func smsIPAddress(id uint32, group bool) [4]byte {
+	network := byte(12)
+	if group {
+		network = 225
+	}
+	return [4]byte{network, byte(id >> 16), byte(id >> 8), byte(id)}
+}
+
+// buildIPv4UDP wraps a payload in minimal IPv4 and UDP headers
+func buildIPv4UDP(src, dst [4]byte, port uint16, payload []byte) []byte {
+	total := SMS_IP_HEADER_SIZE + SMS_UDP_HDR_SIZE + len(payload)
+	packet := make([]byte, total)
+
+	// IPv4 header
+	packet[0] = 0x45 // Version 4, IHL 5
+	binary.BigEndian.PutUint16(packet[2:4], uint16(total))
+	packet[8] = 64 // TTL
+	packet[9] = 17 // UDP
+	copy(packet[12:16], src[:])
+	copy(packet[16:20], dst[:])
+	binary.BigEndian.PutUint16(packet[10:12], ipChecksum(packet[:SMS_IP_HEADER_SIZE]))
+
+	// UDP header (checksum optional for IPv4)
+	udp := packet[SMS_IP_HEADER_SIZE:]
+	binary.BigEndian.PutUint16(udp[0:2], port)
+	binary.BigEndian.PutUint16(udp[2:4], port)
+	binary.BigEndian.PutUint16(udp[4:6], uint16(SMS_UDP_HDR_SIZE+len(payload)))
+	copy(udp[SMS_UDP_HDR_SIZE:], payload)
+
+	return packet
+}
+
+// ipChecksum calculates the IPv4 header checksum
+func ipChecksum(header []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(header); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(header[i:]))
+	}
+	for sum > 0xFFFF {
+		sum = (sum >> 16) + (sum & 0xFFFF)
+	}
+	return ^uint16(sum)
+}
+
+// dataCRC32 calculates the DMR packet data CRC-32 (ETSI TS 102 361-1 B.3.9)
+// Octets are processed in pairs, second octet first.
+func dataCRC32(data []byte) uint32 {
+	const poly = 0x04C11DB7
+	var crc uint32
+
+	process := func(b byte) {
+		for bit := 7; bit >= 0; bit-- {
+			msb := (crc >> 31) & 1
+			crc <<= 1
+			if msb^uint32((b>>uint(bit))&1) != 0 {
+				crc ^= poly
+			}
+		}
+	}
+
+	for i := 0; i < len(data); i += 2 {
+		if i+1 < len(data) {
+			process(data[i+1])
+		}
+		process(data[i])
+	}
+
+	return crc
+}
@@ -0,0 +1,59 @@
+package dmr
+
+import "testing"
+
+func TestBuildSMS_Reassembles(t *testing.T) {
+	sms, err := BuildSMS(3100456, 91, true, "Hello from YSF")
+	if err != nil {
+		t.Fatalf("BuildSMS() error = %v", err)
+	}
+	if int(sms.Header.BlocksToFollow) != len(sms.Blocks) {
+		t.Fatalf("BlocksToFollow = %d, blocks = %d", sms.Header.BlocksToFollow, len(sms.Blocks))
+	}
+
+	assembler := NewDataCallAssembler()
+	headerBurst, err := BuildDataBurst(BuildDataHeader(sms.Header), 0x06, 1)
+	if err != nil {
+		t.Fatalf("BuildDataBurst() error = %v", err)
+	}
+	if _, err := assembler.AddHeader(headerBurst); err != nil {
+		t.Fatalf("AddHeader() error = %v", err)
+	}
+
+	var message *DataMessage
+	for _, block := range sms.Blocks {
+		burst, err := BuildDataBurst(block, 0x07, 1)
+		if err != nil {
+			t.Fatalf("BuildDataBurst() error = %v", err)
+		}
+		if message, err = assembler.AddBlock(burst); err != nil {
+			t.Fatalf("AddBlock() error = %v", err)
+		}
+	}
+
+	if message == nil {
+		t.Fatal("no message reassembled")
+	}
+	if message.Text != "Hello from YSF" {
+		t.Errorf("Text = %q, want %q", message.Text, "Hello from YSF")
+	}
+	if !message.Header.Group || message.Header.DstID != 91 || message.Header.SrcID != 3100456 {
+		t.Errorf("header = %+v", message.Header)
+	}
+}
+
+func TestBuildDataBurst_SyncAndSlotType(t *testing.T) {
+	burst, err := BuildDataBurst(make([]byte, DATA_BLOCK_RATE12_SIZE), 0x07, 1)
+	if err != nil {
+		t.Fatalf("BuildDataBurst() error = %v", err)
+	}
+	if burst[14] != 0xFF || burst[18] != 0xF5 {
+		t.Errorf("data sync missing: % X", burst[13:20])
+	}
+}
+
+func TestBuildSMS_Empty(t *testing.T) {
+	if _, err := BuildSMS(1, 2, false, ""); err == nil {
+		t.Error("expected error for empty text")
+	}
+}
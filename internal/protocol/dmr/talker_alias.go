@@ -0,0 +1,138 @@
+package dmr
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
+)
+
+// FLCO values of the talker alias header and blocks sent in embedded LC
+const (
+	FLCO_TALKER_ALIAS_HEADER = 0x04
+	FLCO_TALKER_ALIAS_BLOCK1 = 0x05
+	FLCO_TALKER_ALIAS_BLOCK2 = 0x06
+	FLCO_TALKER_ALIAS_BLOCK3 = 0x07
+)
+
+// Talker alias formats (header bits 7-6 of LC byte 2)
+const (
+	TALKER_ALIAS_7BIT  = 0
+	TALKER_ALIAS_8BIT  = 1 // ISO 8859
+	TALKER_ALIAS_UTF8  = 2
+	TALKER_ALIAS_UTF16 = 3
+)
+
+// talkerAliasBlockLength is the alias data in each header or block LC
+const talkerAliasBlockLength = 7
+
+// IsTalkerAlias reports whether an embedded LC is a talker alias header or block
+func IsTalkerAlias(lc []byte) bool {
+	if len(lc) < EMBEDDED_LC_LENGTH {
+		return false
+	}
+	flco := lc[0] & 0x3F
+	return flco >= FLCO_TALKER_ALIAS_HEADER && flco <= FLCO_TALKER_ALIAS_BLOCK3
+}
+
+// TalkerAlias reassembles the talker alias sent by the calling radio in its
+// header and up to three blocks, which may arrive in any order
+type TalkerAlias struct {
+	data     [4 * talkerAliasBlockLength]byte
+	received uint8 // bit n set once block n (0 for the header) is stored
+}
+
+// Reset drops the blocks received
+func (t *TalkerAlias) Reset() {
+	t.received = 0
+}
+
+// Add adds a talker alias header or block LC, returning the alias once all
+// the blocks its length needs have been received
+func (t *TalkerAlias) Add(lc []byte) (string, bool) {
+	if !IsTalkerAlias(lc) {
+		return "", false
+	}
+
+	block := int(lc[0]&0x3F) - FLCO_TALKER_ALIAS_HEADER
+	copy(t.data[block*talkerAliasBlockLength:], lc[2:EMBEDDED_LC_LENGTH])
+	t.received |= 1 << block
+
+	if t.received&0x01 == 0 {
+		return "", false
+	}
+	format, length := t.data[0]>>6, int(t.data[0]>>1)&0x1F
+	if max := maxTalkerAliasLength(format); length > max {
+		length = max
+	}
+	blocks := (aliasBytes(format, length) + talkerAliasBlockLength - 1) / talkerAliasBlockLength
+	if mask := uint8(1)<<blocks - 1; t.received&mask != mask {
+		return "", false
+	}
+	return t.decode(format, length), true
+}
+
+// aliasBytes returns how much of the alias data holds length characters,
+// counting the byte with the format and length
+func aliasBytes(format uint8, length int) int {
+	switch format {
+	case TALKER_ALIAS_7BIT:
+		return (7 + 7*length + 7) / 8 // the first character starts at bit 7
+	case TALKER_ALIAS_UTF16:
+		return 1 + 2*length
+	default:
+		return 1 + length
+	}
+}
+
+// maxTalkerAliasLength returns the longest alias the header and three blocks
+// can carry in format
+func maxTalkerAliasLength(format uint8) int {
+	switch format {
+	case TALKER_ALIAS_7BIT:
+		return (8*4*talkerAliasBlockLength - 7) / 7
+	case TALKER_ALIAS_UTF16:
+		return (4*talkerAliasBlockLength - 1) / 2
+	default:
+		return 4*talkerAliasBlockLength - 1
+	}
+}
+
+// decode returns the alias of length characters in format
+func (t *TalkerAlias) decode(format uint8, length int) string {
+	var alias string
+	switch format {
+	case TALKER_ALIAS_7BIT:
+		chars := make([]byte, 0, length)
+		for i := 0; i < length; i++ {
+			var c byte
+			for j := 0; j < 7; j++ {
+				c <<= 1
+				if bits.Read(t.data[:], uint32(7+7*i+j)) {
+					c |= 1
+				}
+			}
+			chars = append(chars, c)
+		}
+		alias = string(chars)
+	case TALKER_ALIAS_8BIT:
+		runes := make([]rune, 0, length)
+		for _, c := range t.data[1 : 1+length] {
+			runes = append(runes, rune(c)) // ISO 8859-1 maps to the first 256 code points
+		}
+		alias = string(runes)
+	case TALKER_ALIAS_UTF8:
+		alias = strings.ToValidUTF8(string(t.data[1:1+length]), "")
+	case TALKER_ALIAS_UTF16:
+		units := make([]uint16, 0, length)
+		for i := 0; i < length; i++ {
+			units = append(units, uint16(t.data[1+2*i])<<8|uint16(t.data[2+2*i]))
+		}
+		alias = string(utf16.Decode(units))
+	}
+
+	return strings.TrimFunc(alias, func(r rune) bool {
+		return r <= ' ' || r == utf8.RuneError
+	})
+}
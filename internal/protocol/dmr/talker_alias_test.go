@@ -0,0 +1,99 @@
+package dmr
+
+import (
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/bits"
+)
+
+// talkerAliasLCs encodes alias data as a header and three block LCs
+func talkerAliasLCs(format uint8, length int, data []byte) [][]byte {
+	var payload [4 * talkerAliasBlockLength]byte
+	payload[0] = format<<6 | uint8(length)<<1
+	copy(payload[1:], data)
+
+	lcs := make([][]byte, 4)
+	for block := range lcs {
+		lc := make([]byte, EMBEDDED_LC_LENGTH)
+		lc[0] = FLCO_TALKER_ALIAS_HEADER + uint8(block)
+		copy(lc[2:], payload[block*talkerAliasBlockLength:])
+		lcs[block] = lc
+	}
+	return lcs
+}
+
+func TestTalkerAlias_Formats(t *testing.T) {
+	tests := []struct {
+		name string
+		lcs  [][]byte
+		want string
+	}{
+		{"8-bit", talkerAliasLCs(TALKER_ALIAS_8BIT, 10, []byte("N0CALL Bob")), "N0CALL Bob"},
+		{"ISO 8859", talkerAliasLCs(TALKER_ALIAS_8BIT, 4, []byte{'J', 'o', 's', 0xE9}), "José"},
+		{"UTF-8", talkerAliasLCs(TALKER_ALIAS_UTF8, 5, []byte("José")), "José"},
+		{"UTF-16", talkerAliasLCs(TALKER_ALIAS_UTF16, 3, []byte{0, 'A', 0, 'B', 0x03, 0xA9}), "ABΩ"},
+		{"padded", talkerAliasLCs(TALKER_ALIAS_8BIT, 8, []byte("G4KLX   ")), "G4KLX"},
+	}
+
+	for _, tt := range tests {
+		var ta TalkerAlias
+		var alias string
+		var ok bool
+		for _, lc := range tt.lcs {
+			if alias, ok = ta.Add(lc); ok {
+				break
+			}
+		}
+		if !ok || alias != tt.want {
+			t.Errorf("%s: alias = %q, %v, want %q", tt.name, alias, ok, tt.want)
+		}
+	}
+}
+
+func TestTalkerAlias_SevenBit(t *testing.T) {
+	alias := "DL1ABC Hans"
+	var payload [4 * talkerAliasBlockLength]byte
+	payload[0] = TALKER_ALIAS_7BIT<<6 | uint8(len(alias))<<1
+	for i := 0; i < len(alias); i++ {
+		for j := 0; j < 7; j++ {
+			bits.Write(payload[:], uint32(7+7*i+j), alias[i]&(0x40>>j) != 0)
+		}
+	}
+
+	var ta TalkerAlias
+	got := ""
+	for block := 0; block < 4 && got == ""; block++ {
+		lc := make([]byte, EMBEDDED_LC_LENGTH)
+		lc[0] = FLCO_TALKER_ALIAS_HEADER + uint8(block)
+		copy(lc[2:], payload[block*talkerAliasBlockLength:])
+		got, _ = ta.Add(lc)
+	}
+	if got != alias {
+		t.Errorf("alias = %q, want %q", got, alias)
+	}
+}
+
+func TestTalkerAlias_BlocksInAnyOrder(t *testing.T) {
+	lcs := talkerAliasLCs(TALKER_ALIAS_8BIT, 22, []byte("PA3XYZ Peter Amsterdam"))
+
+	var ta TalkerAlias
+	for _, block := range []int{2, 0, 3} {
+		if alias, ok := ta.Add(lcs[block]); ok {
+			t.Fatalf("alias %q complete before block 1", alias)
+		}
+	}
+	alias, ok := ta.Add(lcs[1])
+	if !ok || alias != "PA3XYZ Peter Amsterdam" {
+		t.Errorf("alias = %q, %v", alias, ok)
+	}
+
+	// A short alias is complete with its header alone
+	ta.Reset()
+	if alias, ok := ta.Add(talkerAliasLCs(TALKER_ALIAS_8BIT, 5, []byte("K1ABC"))[0]); !ok || alias != "K1ABC" {
+		t.Errorf("short alias = %q, %v", alias, ok)
+	}
+
+	if _, ok := ta.Add([]byte{0x00, 0, 0, 0, 0, 91, 0x2F, 0x4E, 0x61}); ok {
+		t.Error("group voice LC taken as a talker alias")
+	}
+}
@@ -7,6 +7,9 @@ const (
 	DMR_FRAME_LENGTH_BYTES         = 33  // DMR frame payload length
 	DMR_SLOT_TIME                 = 60  // DMR slot time in milliseconds
 	HOMEBREW_DATA_PACKET_LENGTH   = 55  // Total DMRD packet size
+	OPENBRIDGE_DATA_PACKET_LENGTH = 73  // DMRD (53 bytes) + HMAC-SHA1 (20 bytes)
+	OPENBRIDGE_PAYLOAD_LENGTH     = 53  // DMRD bytes covered by the HMAC
+	OPENBRIDGE_HMAC_LENGTH        = 20  // HMAC-SHA1 digest length
 
 	// Network packet lengths
 	NETWORK_LOGIN_LENGTH          = 8   // RPTL packet
@@ -20,6 +23,7 @@ const (
 	NETWORK_BEACON_LENGTH         = 8   // RPTSBKN packet
 	NETWORK_POSITION_LENGTH       = 18  // DMRG packet
 	NETWORK_TALKERALIAS_LENGTH    = 19  // DMRA packet
+	NETWORK_RSSI_LENGTH           = 13  // RPTRSSI packet
 
 	// Timer constants
 	DMR_RETRY_TIMEOUT             = 10000 // 10 seconds in milliseconds
@@ -93,6 +97,12 @@ const (
 	NETWORK_MAGIC_PONG     = "MSTPONG"  // Ping response
 	NETWORK_MAGIC_CLOSE_MASTER = "MSTCL" // Master closing
 	NETWORK_MAGIC_BEACON   = "RPTSBKN"  // Beacon request
+	NETWORK_MAGIC_RSSI     = "RPTRSSI"  // Received signal strength report
+
+	// DMRGateway repeater port packets; data uses DMRD as above
+	NETWORK_MAGIC_GW_CONFIG  = "DMRC" // Configuration
+	NETWORK_MAGIC_GW_OPTIONS = "DMRO" // Options
+	NETWORK_MAGIC_GW_PING    = "DMRP" // Keepalive, sent both ways
 )
 
 // Slot numbers
@@ -0,0 +1,52 @@
+package protocol
+
+import "sync"
+
+// Pools for the objects allocated on every network clock tick
+// Buffers and DMRData values are returned to the pool once a frame has been
+// handled, so an idle gateway polling every few milliseconds stops
+// producing garbage.
+var (
+	bufferPool = sync.Pool{
+		New: func() any {
+			buffer := make([]byte, BUFFER_LENGTH)
+			return &buffer
+		},
+	}
+
+	dmrDataPool = sync.Pool{
+		New: func() any {
+			return NewDMRData()
+		},
+	}
+)
+
+// GetBuffer returns a BUFFER_LENGTH byte packet buffer from the pool
+// The contents are not cleared.
+func GetBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+// PutBuffer returns a buffer obtained from GetBuffer to the pool
+func PutBuffer(buffer *[]byte) {
+	if buffer == nil || cap(*buffer) < BUFFER_LENGTH {
+		return
+	}
+	*buffer = (*buffer)[:BUFFER_LENGTH]
+	bufferPool.Put(buffer)
+}
+
+// GetDMRData returns a cleared DMRData from the pool
+func GetDMRData() *DMRData {
+	data := dmrDataPool.Get().(*DMRData)
+	data.Reset()
+	return data
+}
+
+// PutDMRData returns a DMRData to the pool
+// The caller must not keep any reference to it afterwards.
+func PutDMRData(data *DMRData) {
+	if data != nil {
+		dmrDataPool.Put(data)
+	}
+}
@@ -0,0 +1,70 @@
+package protocol
+
+import "testing"
+
+func TestGetDMRDataIsReset(t *testing.T) {
+	data := GetDMRData()
+	data.SetSrcId(1234)
+	data.SetData([]byte{0x01, 0x02})
+	PutDMRData(data)
+
+	data = GetDMRData()
+	defer PutDMRData(data)
+	if data.GetSrcId() != 0 || data.GetData()[0] != 0 {
+		t.Errorf("GetDMRData() returned a dirty value: %s", data.String())
+	}
+}
+
+func TestPutBufferRestoresLength(t *testing.T) {
+	buffer := GetBuffer()
+	*buffer = (*buffer)[:10]
+	PutBuffer(buffer)
+
+	buffer = GetBuffer()
+	defer PutBuffer(buffer)
+	if len(*buffer) != BUFFER_LENGTH {
+		t.Errorf("len(GetBuffer()) = %d, want %d", len(*buffer), BUFFER_LENGTH)
+	}
+}
+
+func BenchmarkDMRDataAlloc(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		data := NewDMRData()
+		data.SetSrcId(uint32(i))
+		sinkDMRData = data
+	}
+}
+
+func BenchmarkDMRDataPool(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		data := GetDMRData()
+		data.SetSrcId(uint32(i))
+		sinkDMRData = data
+		PutDMRData(data)
+	}
+}
+
+func BenchmarkBufferAlloc(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buffer := make([]byte, BUFFER_LENGTH)
+		sinkBuffer = buffer
+	}
+}
+
+func BenchmarkBufferPool(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buffer := GetBuffer()
+		sinkBuffer = *buffer
+		PutBuffer(buffer)
+	}
+}
+
+// Package level sinks stop the compiler optimising the allocations away
+var (
+	sinkDMRData *DMRData
+	sinkBuffer  []byte
+)
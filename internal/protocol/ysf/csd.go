@@ -0,0 +1,51 @@
+package ysf
+
+import "strings"
+
+// Callsign data (CSD) carried in the header and terminator payloads
+// CSD1 holds the destination and source callsigns, CSD2 the downlink and
+// uplink repeater callsigns; each callsign is space padded to 10 characters.
+// CSD3 holds four 5-character remarks, the first being the radio ID.
+const (
+	CSD_LENGTH            = 2 * CALLSIGN_LENGTH // one CSD block
+	HEADER_PAYLOAD_LENGTH = 3 * CSD_LENGTH      // CSD1, CSD2 and CSD3
+	RADIO_ID_LENGTH       = 5
+	radioIDOffset         = 2 * CSD_LENGTH // Rem1 of CSD3
+)
+
+// BuildHeaderPayload builds the header/terminator payload for a call
+// Empty callsigns are sent as spaces, as radios show nothing for them.
+func BuildHeaderPayload(dest, source, downlink, uplink string) []byte {
+	payload := make([]byte, 0, HEADER_PAYLOAD_LENGTH)
+	for _, callsign := range []string{dest, source, downlink, uplink} {
+		if len(callsign) > CALLSIGN_LENGTH {
+			callsign = callsign[:CALLSIGN_LENGTH]
+		}
+		payload = append(payload, padCallsign(callsign)...)
+	}
+	for len(payload) < HEADER_PAYLOAD_LENGTH {
+		payload = append(payload, ' ')
+	}
+	return payload
+}
+
+// HeaderCallsigns returns the callsigns of a header/terminator payload
+// ok is false if the payload is too short to hold CSD1 and CSD2.
+func HeaderCallsigns(payload []byte) (dest, source, downlink, uplink string, ok bool) {
+	if len(payload) < 2*CSD_LENGTH {
+		return "", "", "", "", false
+	}
+	field := func(i int) string {
+		return extractCallsign(payload[i*CALLSIGN_LENGTH : (i+1)*CALLSIGN_LENGTH])
+	}
+	return field(0), field(1), field(2), field(3), true
+}
+
+// HeaderRadioID returns the 5-character ID of the transmitting radio from a
+// header/terminator payload, or "" if it is missing or blank
+func HeaderRadioID(payload []byte) string {
+	if len(payload) < radioIDOffset+RADIO_ID_LENGTH {
+		return ""
+	}
+	return strings.Trim(string(payload[radioIDOffset:radioIDOffset+RADIO_ID_LENGTH]), " \x00")
+}
@@ -0,0 +1,55 @@
+package ysf
+
+import "testing"
+
+func TestHeaderPayloadRoundTrip(t *testing.T) {
+	payload := BuildHeaderPayload("ALL", "KD8XYZ", "", "GATEWAY-LONGNAME")
+	if len(payload) != HEADER_PAYLOAD_LENGTH {
+		t.Fatalf("len = %d, want %d", len(payload), HEADER_PAYLOAD_LENGTH)
+	}
+	if got := string(payload[10:20]); got != "KD8XYZ    " {
+		t.Errorf("CSD1 source = %q, want %q", got, "KD8XYZ    ")
+	}
+
+	// The header payload survives a frame build and parse
+	frame := &Frame{
+		SourceCallsign: "KD8XYZ",
+		DestCallsign:   "ALL",
+		FICH:           FICH{FI: 0, DT: 0},
+		Payload:        payload,
+	}
+	parsed := &Frame{}
+	if err := parsed.Parse(frame.Build()); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	dest, source, downlink, uplink, ok := HeaderCallsigns(parsed.Payload)
+	if !ok {
+		t.Fatal("HeaderCallsigns() ok = false")
+	}
+	if dest != "ALL" || source != "KD8XYZ" || downlink != "" || uplink != "GATEWAY-LO" {
+		t.Errorf("HeaderCallsigns() = %q, %q, %q, %q", dest, source, downlink, uplink)
+	}
+}
+
+func TestHeaderCallsignsShort(t *testing.T) {
+	if _, _, _, _, ok := HeaderCallsigns(make([]byte, 2*CSD_LENGTH-1)); ok {
+		t.Error("HeaderCallsigns() ok = true for a short payload")
+	}
+}
+
+func TestHeaderRadioID(t *testing.T) {
+	payload := BuildHeaderPayload("ALL", "G4KLX", "", "")
+	if id := HeaderRadioID(payload); id != "" {
+		t.Errorf("HeaderRadioID() = %q for a blank CSD3, want none", id)
+	}
+
+	copy(payload[2*CSD_LENGTH:], "E0aBc")
+	if id := HeaderRadioID(payload); id != "E0aBc" {
+		t.Errorf("HeaderRadioID() = %q, want %q", id, "E0aBc")
+	}
+
+	if id := HeaderRadioID(payload[:2*CSD_LENGTH]); id != "" {
+		t.Errorf("HeaderRadioID() = %q for a payload without CSD3", id)
+	}
+}
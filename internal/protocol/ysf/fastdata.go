@@ -0,0 +1,71 @@
+package ysf
+
+// FAST_DATA_MAX_LENGTH bounds the data kept from one data FR mode transmission
+const FAST_DATA_MAX_LENGTH = 256 * 1024
+
+// FastDataAssembler collects everything a radio sends in one data FR mode
+// transmission, such as a picture, from the header to the terminator
+// Unlike the DataAssembler it does not look for the message framing, so
+// transmissions spanning several blocks or restarting FN are kept whole.
+type FastDataAssembler struct {
+	data      []byte
+	active    bool
+	truncated bool
+}
+
+// NewFastDataAssembler creates a new fast data assembler
+func NewFastDataAssembler() *FastDataAssembler {
+	return &FastDataAssembler{}
+}
+
+// Reset discards any partially received transmission
+func (a *FastDataAssembler) Reset() {
+	a.data = a.data[:0]
+	a.active = false
+	a.truncated = false
+}
+
+// Truncated reports whether the last transmission returned was longer than
+// FAST_DATA_MAX_LENGTH
+func (a *FastDataAssembler) Truncated() bool {
+	return a.truncated
+}
+
+// Add adds a frame and returns the data of the transmission once its
+// terminator is received
+func (a *FastDataAssembler) Add(frame *Frame) ([]byte, bool) {
+	if !frame.IsData() {
+		return nil, false
+	}
+
+	switch {
+	case frame.IsHeader():
+		a.Reset()
+		a.active = true
+	case frame.IsCommunications():
+		if !a.active || frame.FICH.FN == 0 {
+			return nil, false // FN 0 carries the callsign data
+		}
+		length := DATA_BLOCK_LENGTH
+		if frame.FICH.FN == 1 {
+			length = DATA_FIRST_BLOCK_LENGTH
+		}
+		payload := frame.Payload
+		if len(payload) > length {
+			payload = payload[:length]
+		}
+		if len(a.data)+len(payload) > FAST_DATA_MAX_LENGTH {
+			a.truncated = true
+			return nil, false
+		}
+		a.data = append(a.data, payload...)
+	case frame.IsTerminator():
+		if !a.active || len(a.data) == 0 {
+			a.active = false
+			return nil, false
+		}
+		a.active = false
+		return append([]byte(nil), a.data...), true
+	}
+	return nil, false
+}
@@ -0,0 +1,47 @@
+package ysf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFastDataAssembler_SpansBlocks(t *testing.T) {
+	data := make([]byte, 2*DATA_MAX_LENGTH+50)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	assembler := NewFastDataAssembler()
+	var got []byte
+	for i, raw := range BuildDataFrames("N0CALL", "ALL", data) {
+		frame := &Frame{}
+		if err := frame.Parse(raw); err != nil {
+			t.Fatalf("Parse(frame %d) error = %v", i, err)
+		}
+		if result, ok := assembler.Add(frame); ok {
+			got = result
+		}
+	}
+
+	if !bytes.HasPrefix(got, data) {
+		t.Errorf("assembled %d bytes, want the %d bytes sent", len(got), len(data))
+	}
+	if assembler.Truncated() {
+		t.Error("Truncated() = true")
+	}
+}
+
+func TestFastDataAssembler_NeedsHeader(t *testing.T) {
+	frames := BuildDataFrames("N0CALL", "ALL", []byte("PICTURE DATA"))
+
+	assembler := NewFastDataAssembler()
+	for i, raw := range frames[1:] {
+		frame := &Frame{}
+		if err := frame.Parse(raw); err != nil {
+			t.Fatalf("Parse(frame %d) error = %v", i, err)
+		}
+		if _, ok := assembler.Add(frame); ok {
+			t.Fatal("data returned without a header")
+		}
+	}
+}
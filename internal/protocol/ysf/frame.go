@@ -3,6 +3,8 @@ package ysf
 import (
 	"fmt"
 	"strings"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
 )
 
 // YSF frame constants
@@ -16,24 +18,40 @@ const (
 	CALLSIGN_LENGTH       = 10  // YSF callsign field length
 )
 
+// FICH call modes
+const (
+	CM_GROUP      = 0 // Group call to ALL
+	CM_GROUP2     = 1 // Group call to a group
+	CM_INDIVIDUAL = 3 // Individual (private) call
+)
+
+// FICH data types
+const (
+	DT_VD_MODE1 = 0 // Voice/data mode 1
+	DT_DATA_FR  = 1 // Data full rate
+	DT_VD_MODE2 = 2 // Voice/data mode 2
+	DT_VOICE_FR = 3 // Voice full rate
+)
+
 // YSF sync pattern
 var YSF_SYNC = []byte{0xD4, 0x71, 0xC9, 0x63, 0x4D}
 
 // Frame Information CHannel (FICH) structure
+// On air the FICH is 4 information bytes plus a CCITT CRC, Golay (24,12)
+// and convolutionally encoded into the 25 bytes after the sync pattern.
 type FICH struct {
 	FI uint8 // Frame indicator (0=header, 1=communications, 2=terminator)
-	DT uint8 // Data type (0=VD mode 1, 1=data, 2=VD mode 2, 3=voice FR)
+	CS uint8 // Callsign field (2 bits)
 	CM uint8 // Call mode (0=group, 1=group2, 3=individual)
-	CS uint8 // Calling standards
-	FN uint8 // Frame number (0-5)
-	FT uint8 // Frame type (0=last, 1=not last)
+	BN uint8 // Block number (2 bits)
+	BT uint8 // Block total (2 bits)
+	FN uint8 // Frame number (0-7)
+	FT uint8 // Frame total (0-7)
+	Dev uint8 // Wide deviation (DN/VW) when set
 	MR uint8 // Message route (0=direct, 1=not busy, 2=busy)
-	BN uint8 // Block number
-	BT uint8 // Block type
-	SQL uint8 // Squelch
-	VOIPIndicator uint8 // VOIP indicator
-	DestinationID uint16 // Destination ID
-	SourceID      uint16 // Source ID
+	VOIPIndicator uint8 // Set by network equipment
+	DT uint8 // Data type (0=VD mode 1, 1=data, 2=VD mode 2, 3=voice FR)
+	SQL uint8 // Squelch (bit 7 enables, low 7 bits are the code)
 }
 
 // YSF Frame structure
@@ -132,6 +150,16 @@ func (f *Frame) IsVoice() bool {
 	return f.FICH.DT == 0 || f.FICH.DT == 2 || f.FICH.DT == 3
 }
 
+// IsVDMode returns true if this frame carries half-rate AMBE+2 voice (VD mode 1 or 2)
+func (f *Frame) IsVDMode() bool {
+	return f.FICH.DT == 0 || f.FICH.DT == 2
+}
+
+// IsVoiceFR returns true if this frame carries full-rate IMBE voice (VW mode, DN off)
+func (f *Frame) IsVoiceFR() bool {
+	return f.FICH.DT == 3
+}
+
 // IsData returns true if this frame contains data
 func (f *Frame) IsData() bool {
 	return f.FICH.DT == 1
@@ -139,76 +167,72 @@ func (f *Frame) IsData() bool {
 
 // IsGroupCall returns true if this is a group call
 func (f *Frame) IsGroupCall() bool {
-	return f.FICH.CM == 0 || f.FICH.CM == 1
+	return f.FICH.CM == CM_GROUP || f.FICH.CM == CM_GROUP2
 }
 
 // IsIndividualCall returns true if this is an individual call
 func (f *Frame) IsIndividualCall() bool {
-	return f.FICH.CM == 3
+	return f.FICH.CM == CM_INDIVIDUAL
 }
 
 // Encode encodes the FICH structure into 25 bytes
+// The fields are packed into 4 bytes, protected with a CCITT CRC and
+// Golay (24,12), then convolutionally encoded and interleaved.
 func (fich *FICH) Encode() []byte {
-	data := make([]byte, YSF_FICH_LENGTH)
-
-	// Pack FICH fields into bytes
-	// First byte: FI (2 bits) | DT (2 bits) | CM (2 bits) | CS (2 bits)
-	data[0] = (fich.FI << 6) | (fich.DT << 4) | (fich.CM << 2) | fich.CS
-
-	// Second byte: FN (3 bits) | FT (1 bit) | MR (2 bits) | reserved (2 bits)
-	data[1] = (fich.FN << 5) | (fich.FT << 4) | (fich.MR << 2)
-
-	// Remaining fields
-	data[2] = fich.BN
-	data[3] = fich.BT
-	data[4] = fich.SQL
-	data[5] = fich.VOIPIndicator
-
-	// Destination ID (16-bit, big-endian)
-	data[6] = uint8(fich.DestinationID >> 8)
-	data[7] = uint8(fich.DestinationID & 0xFF)
-
-	// Source ID (16-bit, big-endian)
-	data[8] = uint8(fich.SourceID >> 8)
-	data[9] = uint8(fich.SourceID & 0xFF)
-
-	// Remaining bytes are typically used for error correction
-	// For now, leave them as zeros
-
-	return data
+	encoded := codec.EncodeYSFFICH(fich.pack())
+	return encoded[:]
 }
 
 // Decode decodes 25 bytes into the FICH structure
+// Bit errors are corrected where possible; an error is returned when the
+// CRC still fails afterwards.
 func (fich *FICH) Decode(data []byte) error {
 	if len(data) < YSF_FICH_LENGTH {
 		return fmt.Errorf("FICH data too short: got %d bytes, need %d", len(data), YSF_FICH_LENGTH)
 	}
 
-	// Unpack FICH fields from bytes
-	// First byte: FI (2 bits) | DT (2 bits) | CM (2 bits) | CS (2 bits)
-	fich.FI = (data[0] >> 6) & 0x03
-	fich.DT = (data[0] >> 4) & 0x03
-	fich.CM = (data[0] >> 2) & 0x03
-	fich.CS = data[0] & 0x03
+	info, ok := codec.DecodeYSFFICH(data[:YSF_FICH_LENGTH])
+	if !ok {
+		return fmt.Errorf("FICH CRC check failed")
+	}
+	fich.unpack(info)
+
+	return nil
+}
 
-	// Second byte: FN (3 bits) | FT (1 bit) | MR (2 bits) | reserved (2 bits)
-	fich.FN = (data[1] >> 5) & 0x07
-	fich.FT = (data[1] >> 4) & 0x01
-	fich.MR = (data[1] >> 2) & 0x03
+// pack packs the FICH fields into the 4 information bytes
+// Byte 0: FI (2 bits) | CS (2 bits) | CM (2 bits) | BN (2 bits)
+// Byte 1: BT (2 bits) | FN (3 bits) | FT (3 bits)
+// Byte 2: reserved | Dev | reserved | MR (2 bits) | VoIP | DT (2 bits)
+// Byte 3: SQL
+func (fich *FICH) pack() [4]byte {
+	var info [4]byte
 
-	// Remaining fields
-	fich.BN = data[2]
-	fich.BT = data[3]
-	fich.SQL = data[4]
-	fich.VOIPIndicator = data[5]
+	info[0] = (fich.FI&0x03)<<6 | (fich.CS&0x03)<<4 | (fich.CM&0x03)<<2 | fich.BN&0x03
+	info[1] = (fich.BT&0x03)<<6 | (fich.FN&0x07)<<3 | fich.FT&0x07
+	info[2] = (fich.Dev&0x01)<<6 | (fich.MR&0x03)<<3 | (fich.VOIPIndicator&0x01)<<2 | fich.DT&0x03
+	info[3] = fich.SQL
 
-	// Destination ID (16-bit, big-endian)
-	fich.DestinationID = (uint16(data[6]) << 8) | uint16(data[7])
+	return info
+}
 
-	// Source ID (16-bit, big-endian)
-	fich.SourceID = (uint16(data[8]) << 8) | uint16(data[9])
+// unpack sets the FICH fields from the 4 information bytes
+func (fich *FICH) unpack(info [4]byte) {
+	fich.FI = (info[0] >> 6) & 0x03
+	fich.CS = (info[0] >> 4) & 0x03
+	fich.CM = (info[0] >> 2) & 0x03
+	fich.BN = info[0] & 0x03
 
-	return nil
+	fich.BT = (info[1] >> 6) & 0x03
+	fich.FN = (info[1] >> 3) & 0x07
+	fich.FT = info[1] & 0x07
+
+	fich.Dev = (info[2] >> 6) & 0x01
+	fich.MR = (info[2] >> 3) & 0x03
+	fich.VOIPIndicator = (info[2] >> 2) & 0x01
+	fich.DT = info[2] & 0x03
+
+	fich.SQL = info[3]
 }
 
 // String returns a human-readable representation of the FICH
@@ -232,8 +256,8 @@ func (fich *FICH) String() string {
 		callMode = callModes[fich.CM]
 	}
 
-	return fmt.Sprintf("FICH{Type=%s, Data=%s, Call=%s, FN=%d, SrcID=%d, DstID=%d}",
-		frameType, dataType, callMode, fich.FN, fich.SourceID, fich.DestinationID)
+	return fmt.Sprintf("FICH{Type=%s, Data=%s, Call=%s, FN=%d, FT=%d}",
+		frameType, dataType, callMode, fich.FN, fich.FT)
 }
 
 // extractCallsign extracts a callsign from a 10-byte field, removing padding
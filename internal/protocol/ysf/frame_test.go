@@ -63,6 +63,9 @@ func TestYSFFrame_ParseHeader(t *testing.T) {
 				copy(padded, tt.input)
 				tt.input = padded
 			}
+			if !tt.expectedErr {
+				copy(tt.input[40:65], tt.expectedFICH.Encode())
+			}
 
 			frame := &Frame{}
 			err := frame.Parse(tt.input)
@@ -111,7 +114,7 @@ func TestYSFFrame_ParseCommunications(t *testing.T) {
 				// Set YSF sync pattern at offset 35
 				copy(frame[35:40], []byte{0xD4, 0x71, 0xC9, 0x63, 0x4D})
 				// FICH bytes (25 bytes starting at offset 40)
-				frame[40] = 0x40 // FI=1 (communications), other fields 0
+				copy(frame[40:65], (&FICH{FI: 1}).Encode()) // Communications, other fields 0
 				return frame
 			}(),
 			expectFICH: FICH{
@@ -130,7 +133,7 @@ func TestYSFFrame_ParseCommunications(t *testing.T) {
 				copy(frame[4:14], []byte("VK3DRS    "))
 				copy(frame[14:24], []byte("VK3DRS    "))
 				copy(frame[35:40], []byte{0xD4, 0x71, 0xC9, 0x63, 0x4D})
-				frame[40] = 0x80 // FI=2 (terminator)
+				copy(frame[40:65], (&FICH{FI: 2}).Encode()) // Terminator
 				return frame
 			}(),
 			expectFICH: FICH{
@@ -182,6 +185,7 @@ func TestYSFFrame_ExtractCallsign(t *testing.T) {
 				copy(frame[4:14], []byte("G4KLX     "))  // Source
 				copy(frame[14:24], []byte("VK3DRS    ")) // Dest
 				copy(frame[35:40], []byte{0xD4, 0x71, 0xC9, 0x63, 0x4D}) // YSF sync
+				copy(frame[40:65], (&FICH{}).Encode())
 				return frame
 			}(),
 			expectedSrc:  "G4KLX",
@@ -195,6 +199,7 @@ func TestYSFFrame_ExtractCallsign(t *testing.T) {
 				copy(frame[4:14], []byte("VK3A      "))
 				copy(frame[14:24], []byte("G0ABC     "))
 				copy(frame[35:40], []byte{0xD4, 0x71, 0xC9, 0x63, 0x4D}) // YSF sync
+				copy(frame[40:65], (&FICH{}).Encode())
 				return frame
 			}(),
 			expectedSrc:  "VK3A",
@@ -207,6 +212,7 @@ func TestYSFFrame_ExtractCallsign(t *testing.T) {
 				copy(frame[:4], []byte{'Y', 'S', 'F', 'D'})
 				// Leave callsign fields as zeros/spaces
 				copy(frame[35:40], []byte{0xD4, 0x71, 0xC9, 0x63, 0x4D}) // YSF sync
+				copy(frame[40:65], (&FICH{}).Encode())
 				return frame
 			}(),
 			expectedSrc:  "",
@@ -375,13 +381,18 @@ func TestFICH_Decode(t *testing.T) {
 		expectFICH  FICH
 	}{
 		{
-			name:        "all zeros",
-			input:       make([]byte, 25),
+			name:        "encoded header",
+			input:       (&FICH{FI: 0, DT: 1, FT: 2}).Encode(),
 			expectError: false,
 			expectFICH: FICH{
-				FI: 0, DT: 0, CM: 0, CS: 0, FN: 0, FT: 0, MR: 0,
+				FI: 0, DT: 1, CM: 0, CS: 0, FN: 0, FT: 2, MR: 0,
 			},
 		},
+		{
+			name:        "all zeros fails CRC",
+			input:       make([]byte, 25),
+			expectError: true,
+		},
 		{
 			name:        "too short",
 			input:       make([]byte, 10),
@@ -421,6 +432,7 @@ func BenchmarkYSFFrame_Parse(b *testing.B) {
 	copy(frame[:4], []byte{'Y', 'S', 'F', 'D'})
 	copy(frame[4:14], []byte("G4KLX     "))
 	copy(frame[35:40], []byte{0xD4, 0x71, 0xC9, 0x63, 0x4D})
+	copy(frame[40:65], (&FICH{FI: 1}).Encode())
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -443,4 +455,63 @@ func BenchmarkYSFFrame_Build(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		frame.Build()
 	}
-}
\ No newline at end of file
+}
+func TestYSFFrame_VoiceModes(t *testing.T) {
+	tests := []struct {
+		dt      uint8
+		vdMode  bool
+		voiceFR bool
+	}{
+		{0, true, false},  // VD Mode 1
+		{1, false, false}, // Data FR
+		{2, true, false},  // VD Mode 2
+		{3, false, true},  // Voice FR (VW)
+	}
+
+	for _, tt := range tests {
+		frame := &Frame{FICH: FICH{FI: 1, DT: tt.dt}}
+		if frame.IsVDMode() != tt.vdMode {
+			t.Errorf("DT=%d IsVDMode() = %v, want %v", tt.dt, frame.IsVDMode(), tt.vdMode)
+		}
+		if frame.IsVoiceFR() != tt.voiceFR {
+			t.Errorf("DT=%d IsVoiceFR() = %v, want %v", tt.dt, frame.IsVoiceFR(), tt.voiceFR)
+		}
+	}
+}
+
+func TestFICH_RoundTripAllFields(t *testing.T) {
+	fich := FICH{
+		FI: 1, CS: 2, CM: 3, BN: 1, BT: 2, FN: 6, FT: 7,
+		Dev: 1, MR: 2, VOIPIndicator: 1, DT: 2, SQL: 0x85,
+	}
+
+	encoded := fich.Encode()
+	var decoded FICH
+	if err := decoded.Decode(encoded); err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if decoded != fich {
+		t.Errorf("decoded %+v, want %+v", decoded, fich)
+	}
+
+	// Packed information bytes
+	if got := fich.pack(); got != [4]byte{0x6D, 0xB7, 0x56, 0x85} {
+		t.Errorf("pack() = % X, want 6D B7 56 85", got)
+	}
+}
+
+func TestFICH_DecodeCorrectsErrors(t *testing.T) {
+	fich := FICH{FI: 1, DT: 1, FN: 2, FT: 3}
+
+	encoded := fich.Encode()
+	encoded[4] ^= 0x10
+	encoded[17] ^= 0x01
+
+	var decoded FICH
+	if err := decoded.Decode(encoded); err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if decoded != fich {
+		t.Errorf("decoded %+v, want %+v", decoded, fich)
+	}
+}
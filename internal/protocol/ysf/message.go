@@ -0,0 +1,224 @@
+package ysf
+
+import (
+	"strings"
+
+	"github.com/dbehnke/ysf2dmr/internal/correction"
+)
+
+// YSF data FR mode message constants
+// Messages use the same framing as WiresX commands: a sequence byte, the
+// content, an 0x03 end marker and an additive checksum, spread over the
+// communications frames (20 bytes in FN 1, 40 bytes in each later frame).
+const (
+	DATA_FIRST_BLOCK_LENGTH = 20
+	DATA_BLOCK_LENGTH       = 40
+	DATA_MAX_FRAMES         = 7 // FN is a 3-bit field
+	DATA_MAX_LENGTH         = DATA_FIRST_BLOCK_LENGTH + (DATA_MAX_FRAMES-1)*DATA_BLOCK_LENGTH
+	DATA_MAX_BLOCKS         = 4 // BT is a 2-bit field
+	DATA_END_MARKER         = 0x03
+
+	TEXT_MAX_LENGTH = DATA_MAX_LENGTH - 3 // Sequence byte, end marker and checksum
+)
+
+// WiresX commands start with this byte after the sequence number
+const wiresXCommandPrefix = 0x5D
+
+// DataAssembler collects data FR mode communications frames into a message
+type DataAssembler struct {
+	buffer []byte
+	last   int
+}
+
+// NewDataAssembler creates a new data FR mode assembler
+func NewDataAssembler() *DataAssembler {
+	return &DataAssembler{
+		buffer: make([]byte, DATA_MAX_LENGTH),
+	}
+}
+
+// Reset discards any partially received message
+func (a *DataAssembler) Reset() {
+	for i := range a.buffer {
+		a.buffer[i] = 0
+	}
+	a.last = 0
+}
+
+// Add adds a frame and returns the message content (without the end marker
+// and checksum) once a complete, valid message has been received
+func (a *DataAssembler) Add(frame *Frame) ([]byte, bool) {
+	if !frame.IsData() {
+		return nil, false
+	}
+
+	if frame.IsHeader() {
+		a.Reset()
+		return nil, false
+	}
+
+	if frame.IsCommunications() {
+		if frame.FICH.FN == 0 {
+			return nil, false // FN 0 carries the callsign data
+		}
+		if frame.FICH.FN == 1 {
+			a.Reset()
+		}
+		a.store(frame.FICH.FN, frame.Payload)
+
+		if frame.FICH.FN != frame.FICH.FT {
+			return nil, false
+		}
+	}
+
+	// The last frame or the terminator completes the message
+	message, ok := a.complete()
+	if ok || frame.IsTerminator() {
+		a.Reset()
+	}
+	return message, ok
+}
+
+// store copies one frame's data into its position in the message
+func (a *DataAssembler) store(fn uint8, payload []byte) {
+	offset, length := 0, DATA_FIRST_BLOCK_LENGTH
+	if fn > 1 {
+		offset = DATA_FIRST_BLOCK_LENGTH + int(fn-2)*DATA_BLOCK_LENGTH
+		length = DATA_BLOCK_LENGTH
+	}
+	if offset+length > len(a.buffer) {
+		return
+	}
+	if length > len(payload) {
+		length = len(payload)
+	}
+
+	copy(a.buffer[offset:offset+length], payload[:length])
+	if offset+length > a.last {
+		a.last = offset + length
+	}
+}
+
+// complete checks for the end marker and checksum
+func (a *DataAssembler) complete() ([]byte, bool) {
+	for i := a.last - 2; i > 0; i-- {
+		if a.buffer[i] != DATA_END_MARKER {
+			continue
+		}
+		if correction.AddCRC(a.buffer[:i+1]) != a.buffer[i+1] {
+			continue
+		}
+
+		message := make([]byte, i)
+		copy(message, a.buffer[:i])
+		return message, true
+	}
+	return nil, false
+}
+
+// IsWiresXCommand returns true if an assembled message is a WiresX command
+func IsWiresXCommand(message []byte) bool {
+	return len(message) >= 4 && message[1] == wiresXCommandPrefix
+}
+
+// ParseTextMessage extracts printable text from an assembled message
+func ParseTextMessage(message []byte) (string, bool) {
+	if len(message) < 2 || IsWiresXCommand(message) {
+		return "", false
+	}
+
+	var text strings.Builder
+	for _, b := range message[1:] {
+		if b == 0 {
+			break
+		}
+		if b < 0x20 || b > 0x7E {
+			return "", false
+		}
+		text.WriteByte(b)
+	}
+
+	result := strings.TrimSpace(text.String())
+	return result, len(result) > 0
+}
+
+// BuildTextMessageFrames builds the data FR mode frames carrying a text message
+// Returns the header, communications and terminator frames in order.
+func BuildTextMessageFrames(source, dest string, seqNo uint8, text string) [][]byte {
+	if len(text) > TEXT_MAX_LENGTH {
+		text = text[:TEXT_MAX_LENGTH]
+	}
+
+	message := make([]byte, 0, len(text)+3)
+	message = append(message, seqNo)
+	message = append(message, []byte(text)...)
+	message = append(message, DATA_END_MARKER)
+	message = append(message, correction.AddCRC(message))
+
+	return BuildDataFrames(source, dest, message)
+}
+
+// BuildDataFrames builds the data FR mode frames carrying a framed message
+// (sequence byte, content, end marker and checksum), such as a WiresX reply
+// Messages longer than DATA_MAX_LENGTH are split into blocks numbered by the
+// FICH BN/BT fields, each starting again at FN 1. Returns the header,
+// communications and terminator frames in order, or nil when the message
+// needs more than DATA_MAX_BLOCKS blocks.
+func BuildDataFrames(source, dest string, message []byte) [][]byte {
+	blocks := (len(message) + DATA_MAX_LENGTH - 1) / DATA_MAX_LENGTH
+	if blocks == 0 {
+		blocks = 1
+	}
+	if blocks > DATA_MAX_BLOCKS {
+		return nil
+	}
+
+	newFrame := func(fi, bn, fn, ft uint8, payload []byte) []byte {
+		f := &Frame{
+			SourceCallsign: source,
+			DestCallsign:   dest,
+			FICH: FICH{
+				FI: fi,
+				BN: bn,
+				BT: uint8(blocks - 1),
+				DT: 1, // Data FR mode
+				FN: fn,
+				FT: ft,
+			},
+			Payload: payload,
+		}
+		return f.Build()
+	}
+
+	var frames [][]byte
+	for bn := 0; bn < blocks; bn++ {
+		block := message[bn*DATA_MAX_LENGTH:]
+		if len(block) > DATA_MAX_LENGTH {
+			block = block[:DATA_MAX_LENGTH]
+		}
+
+		frameCount := 1
+		if len(block) > DATA_FIRST_BLOCK_LENGTH {
+			frameCount += (len(block) - DATA_FIRST_BLOCK_LENGTH + DATA_BLOCK_LENGTH - 1) / DATA_BLOCK_LENGTH
+		}
+		if bn == 0 {
+			frames = append(frames, newFrame(0, 0, 0, uint8(frameCount), nil))
+		}
+
+		for fn := 1; fn <= frameCount; fn++ {
+			offset, length := 0, DATA_FIRST_BLOCK_LENGTH
+			if fn > 1 {
+				offset = DATA_FIRST_BLOCK_LENGTH + (fn-2)*DATA_BLOCK_LENGTH
+				length = DATA_BLOCK_LENGTH
+			}
+			end := offset + length
+			if end > len(block) {
+				end = len(block)
+			}
+			frames = append(frames, newFrame(1, uint8(bn), uint8(fn), uint8(frameCount), block[offset:end]))
+		}
+	}
+	frames = append(frames, newFrame(2, 0, 0, 0, nil))
+
+	return frames
+}
@@ -0,0 +1,120 @@
+package ysf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func assembleFrames(t *testing.T, frames [][]byte) ([]byte, bool) {
+	t.Helper()
+	assembler := NewDataAssembler()
+	for i, raw := range frames {
+		frame := &Frame{}
+		if err := frame.Parse(raw); err != nil {
+			t.Fatalf("Parse(frame %d) error = %v", i, err)
+		}
+		if message, ok := assembler.Add(frame); ok {
+			return message, true
+		}
+	}
+	return nil, false
+}
+
+func TestTextMessage_RoundTrip(t *testing.T) {
+	tests := []string{
+		"HI",
+		"CQ CQ DE N0CALL",
+		strings.Repeat("LONG MESSAGE ", 8),
+	}
+
+	for _, text := range tests {
+		frames := BuildTextMessageFrames("N0CALL", "ALL", 1, text)
+
+		message, ok := assembleFrames(t, frames)
+		if !ok {
+			t.Fatalf("no message assembled for %q", text)
+		}
+
+		got, ok := ParseTextMessage(message)
+		if !ok {
+			t.Fatalf("ParseTextMessage() failed for %q", text)
+		}
+		if got != strings.TrimSpace(text) {
+			t.Errorf("ParseTextMessage() = %q, want %q", got, strings.TrimSpace(text))
+		}
+	}
+}
+
+func TestTextMessage_Truncated(t *testing.T) {
+	frames := BuildTextMessageFrames("N0CALL", "ALL", 0, strings.Repeat("X", 400))
+	if len(frames) != DATA_MAX_FRAMES+2 {
+		t.Errorf("frame count = %d, want %d", len(frames), DATA_MAX_FRAMES+2)
+	}
+}
+
+func TestParseTextMessage_WiresXCommand(t *testing.T) {
+	command := []byte{0x00, 0x5D, 0x71, 0x5F, 0x01}
+	if !IsWiresXCommand(command) {
+		t.Error("IsWiresXCommand() = false for DX request")
+	}
+	if _, ok := ParseTextMessage(command); ok {
+		t.Error("ParseTextMessage() accepted a WiresX command")
+	}
+}
+
+func TestDataAssembler_BadChecksum(t *testing.T) {
+	frames := BuildTextMessageFrames("N0CALL", "ALL", 1, "HELLO")
+	// Corrupt the checksum byte in the first communications frame payload
+	frames[1][65+len("HELLO")+2] ^= 0xFF
+
+	if _, ok := assembleFrames(t, frames); ok {
+		t.Error("message with bad checksum was accepted")
+	}
+}
+
+func TestBuildDataFrames_Blocks(t *testing.T) {
+	message := make([]byte, DATA_MAX_LENGTH+100)
+	for i := range message {
+		message[i] = byte(i)
+	}
+
+	frames := BuildDataFrames("N0CALL", "ALL", message)
+	// Header, 7 frames for the first block, 3 for the second, terminator
+	if len(frames) != 1+DATA_MAX_FRAMES+3+1 {
+		t.Fatalf("frame count = %d, want %d", len(frames), 1+DATA_MAX_FRAMES+3+1)
+	}
+
+	var data []byte
+	for i, raw := range frames {
+		frame := &Frame{}
+		if err := frame.Parse(raw); err != nil {
+			t.Fatalf("Parse(frame %d) error = %v", i, err)
+		}
+		if frame.FICH.BT != 1 {
+			t.Errorf("frame %d BT = %d, want 1", i, frame.FICH.BT)
+		}
+		if !frame.IsCommunications() {
+			continue
+		}
+		wantBN := uint8(0)
+		if i > DATA_MAX_FRAMES {
+			wantBN = 1
+		}
+		if frame.FICH.BN != wantBN {
+			t.Errorf("frame %d BN = %d, want %d", i, frame.FICH.BN, wantBN)
+		}
+		length := DATA_BLOCK_LENGTH
+		if frame.FICH.FN == 1 {
+			length = DATA_FIRST_BLOCK_LENGTH
+		}
+		data = append(data, frame.Payload[:length]...)
+	}
+	if !bytes.HasPrefix(data, message) {
+		t.Error("communications frames do not carry the message in order")
+	}
+
+	if frames := BuildDataFrames("N0CALL", "ALL", make([]byte, DATA_MAX_BLOCKS*DATA_MAX_LENGTH+1)); frames != nil {
+		t.Errorf("oversized message built %d frames, want none", len(frames))
+	}
+}
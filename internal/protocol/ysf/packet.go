@@ -0,0 +1,79 @@
+package ysf
+
+// PacketType classifies a packet received from the YSF network
+type PacketType int
+
+const (
+	PacketUnknown PacketType = iota // Unrecognised tag
+	PacketData                      // "YSFD" voice/data frame
+	PacketPoll                      // "YSFP" keepalive poll
+	PacketUnlink                    // "YSFU" unlink request
+	PacketStatus                    // "YSFS" reflector status request/reply
+	PacketOptions                   // "YSFO" options string
+	PacketInfo                      // "YSFI" repeater information
+	PacketInvalid                   // Known tag with the wrong length
+
+	PacketTypeCount // Number of packet types, for counter arrays
+)
+
+// Minimum lengths of the non-data packets
+const (
+	pollPacketLength   = 14 // "YSFP" + 10-byte callsign
+	unlinkPacketLength = 14 // "YSFU" + 10-byte callsign
+	statusPacketLength = 4  // "YSFS" request; replies are longer
+	optionPacketLength = 5  // "YSFO" + at least one character
+	infoPacketLength   = 14 // "YSFI" + 10-byte callsign, then details
+)
+
+var packetTypeNames = [PacketTypeCount]string{
+	PacketUnknown: "unknown",
+	PacketData:    "data",
+	PacketPoll:    "poll",
+	PacketUnlink:  "unlink",
+	PacketStatus:  "status",
+	PacketOptions: "options",
+	PacketInfo:    "info",
+	PacketInvalid: "invalid",
+}
+
+func (t PacketType) String() string {
+	if t >= 0 && t < PacketTypeCount {
+		return packetTypeNames[t]
+	}
+	return "unknown"
+}
+
+// ClassifyPacket identifies a YSF network packet from its tag and length
+// Only PacketData packets are complete frames that can be passed to Parse.
+func ClassifyPacket(data []byte) PacketType {
+	if len(data) < 4 {
+		return PacketUnknown
+	}
+
+	var t PacketType
+	var minLength int
+	switch string(data[0:4]) {
+	case YSF_MAGIC:
+		if len(data) != YSF_FRAME_LENGTH {
+			return PacketInvalid
+		}
+		return PacketData
+	case "YSFP":
+		t, minLength = PacketPoll, pollPacketLength
+	case "YSFU":
+		t, minLength = PacketUnlink, unlinkPacketLength
+	case "YSFS":
+		t, minLength = PacketStatus, statusPacketLength
+	case "YSFO":
+		t, minLength = PacketOptions, optionPacketLength
+	case "YSFI":
+		t, minLength = PacketInfo, infoPacketLength
+	default:
+		return PacketUnknown
+	}
+
+	if len(data) < minLength {
+		return PacketInvalid
+	}
+	return t
+}
@@ -0,0 +1,33 @@
+package ysf
+
+import "testing"
+
+func TestClassifyPacket(t *testing.T) {
+	frame := (&Frame{SourceCallsign: "G4KLX", FICH: FICH{FI: 1}}).Build()
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  PacketType
+	}{
+		{"data frame", frame, PacketData},
+		{"truncated data frame", frame[:100], PacketInvalid},
+		{"oversized data frame", append(frame, 0x00), PacketInvalid},
+		{"poll", []byte("YSFPG4KLX     "), PacketPoll},
+		{"short poll", []byte("YSFPG4"), PacketInvalid},
+		{"unlink", []byte("YSFUG4KLX     "), PacketUnlink},
+		{"status request", []byte("YSFS"), PacketStatus},
+		{"options", []byte("YSFO91"), PacketOptions},
+		{"info", []byte("YSFIG4KLX     00000"), PacketInfo},
+		{"unknown tag", []byte("ABCD1234"), PacketUnknown},
+		{"too short", []byte("YS"), PacketUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyPacket(tt.input); got != tt.want {
+				t.Errorf("ClassifyPacket() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package radioid
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dbehnke/ysf2dmr/internal/database"
+)
+
+// Filter limits the users a sync stores to the regions a gateway hears
+// Entries are radio ID prefixes, such as a country's MCC ("310" or "2"), or
+// country names as spelled in the RadioID CSV. DMRIds.dat imports carry no
+// country, so only prefixes match them. An empty filter matches every user.
+type Filter struct {
+	spec      string
+	prefixes  []string
+	countries map[string]bool
+}
+
+// ParseFilter parses a comma separated list of ID prefixes and countries
+func ParseFilter(spec string) Filter {
+	f := Filter{spec: strings.TrimSpace(spec)}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if _, err := strconv.ParseUint(entry, 10, 32); err == nil {
+			f.prefixes = append(f.prefixes, entry)
+			continue
+		}
+		if f.countries == nil {
+			f.countries = make(map[string]bool)
+		}
+		f.countries[strings.ToLower(entry)] = true
+	}
+	return f
+}
+
+// Empty reports whether the filter matches every user
+func (f Filter) Empty() bool {
+	return len(f.prefixes) == 0 && len(f.countries) == 0
+}
+
+// Match reports whether a user is within the filter
+func (f Filter) Match(user database.DMRUser) bool {
+	if f.Empty() {
+		return true
+	}
+	if f.countries[strings.ToLower(user.Country)] {
+		return true
+	}
+	id := strconv.FormatUint(uint64(user.RadioID), 10)
+	for _, prefix := range f.prefixes {
+		if strings.HasPrefix(id, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the filter in ParseFilter's format
+func (f Filter) String() string {
+	return f.spec
+}
+
+// apply returns the users within the filter, reusing the slice
+func (f Filter) apply(users []database.DMRUser) []database.DMRUser {
+	if f.Empty() {
+		return users
+	}
+	kept := users[:0]
+	for _, user := range users {
+		if f.Match(user) {
+			kept = append(kept, user)
+		}
+	}
+	return kept
+}
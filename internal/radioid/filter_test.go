@@ -0,0 +1,81 @@
+package radioid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/database"
+)
+
+func TestFilter_Match(t *testing.T) {
+	f := ParseFilter(" 310, 2345 ,united kingdom,,")
+	for _, tt := range []struct {
+		user  database.DMRUser
+		match bool
+	}{
+		{database.DMRUser{RadioID: 3101234, Country: "United States"}, true},
+		{database.DMRUser{RadioID: 3120001, Country: "United States"}, false},
+		{database.DMRUser{RadioID: 2345678}, true},
+		{database.DMRUser{RadioID: 2350001, Country: "United Kingdom"}, true},
+		{database.DMRUser{RadioID: 2620001, Country: "Germany"}, false},
+	} {
+		if got := f.Match(tt.user); got != tt.match {
+			t.Errorf("Match(%d %s) = %v, want %v", tt.user.RadioID, tt.user.Country, got, tt.match)
+		}
+	}
+
+	if !ParseFilter(" , ").Empty() || !ParseFilter("").Match(database.DMRUser{RadioID: 1}) {
+		t.Error("empty filter does not match every user")
+	}
+}
+
+func TestSyncer_FilteredSync(t *testing.T) {
+	countries := "United States"
+	s := newTestSyncer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "RADIO_ID,CALLSIGN,FIRST_NAME,LAST_NAME,CITY,STATE,COUNTRY\n")
+		fmt.Fprint(w, "3120001,W1AW,Hiram,Maxim,Newington,Connecticut,United States\n")
+		fmt.Fprint(w, "2345678,G4KLX,Jonathan,Naylor,,,United Kingdom\n")
+		fmt.Fprint(w, "2620001,DL1ABC,Hans,,,,Germany\n")
+	})
+
+	// Users stored before the filter was set are pruned
+	if err := s.SyncNow(context.Background()); err != nil {
+		t.Fatalf("unfiltered SyncNow error: %v", err)
+	}
+	s.filter = ParseFilter(countries + ",234")
+	if err := s.SyncNow(context.Background()); err != nil {
+		t.Fatalf("filtered SyncNow error: %v", err)
+	}
+
+	if rows := s.Status().RowsUpdated; rows != 2 {
+		t.Errorf("RowsUpdated = %d, want 2", rows)
+	}
+	if count, _ := s.repository.Count(); count != 2 {
+		t.Errorf("Count() = %d, want 2", count)
+	}
+	if _, err := s.repository.GetByRadioID(2620001); err == nil {
+		t.Error("user outside the filter kept")
+	}
+
+	// A filter matching nothing fails instead of emptying the database
+	s.filter = ParseFilter("999")
+	if err := s.SyncNow(context.Background()); err == nil {
+		t.Error("SyncNow succeeded with no users matching the filter")
+	}
+	if count, _ := s.repository.Count(); count != 2 {
+		t.Errorf("Count() after an empty filtered sync = %d, want 2", count)
+	}
+}
+
+func TestSyncer_FilteredImport(t *testing.T) {
+	s := newTestSyncer(t, func(w http.ResponseWriter, r *http.Request) {})
+	s.filter = ParseFilter("310")
+
+	n, err := s.Import(strings.NewReader("3101234 N0CALL Joe\n2345678 G4KLX Jonathan\n"))
+	if err != nil || n != 1 {
+		t.Fatalf("Import() = %d, %v, want 1 user", n, err)
+	}
+}
@@ -0,0 +1,144 @@
+package radioid
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/database"
+)
+
+// detectBytes is how much of an import is examined to detect its format
+const detectBytes = 64 * 1024
+
+// ImportFile imports a local RadioID user.csv or DMRIds.dat file into the database
+// The outcome is recorded in the sync status like a download.
+func (s *Syncer) ImportFile(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if s.logger != nil {
+		s.logger.Printf("Importing DMR users from %s", path)
+	}
+	return s.Import(file)
+}
+
+// Import imports users from a reader holding RadioID CSV or DMRIds.dat data
+// The format is detected from the first non-comment line. It returns
+// ErrSyncRunning if a sync is in progress.
+func (s *Syncer) Import(reader io.Reader) (int, error) {
+	startTime, err := s.beginSync()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := s.importOnce(reader)
+	s.finishSync(startTime, rows, err)
+	return rows, err
+}
+
+func (s *Syncer) importOnce(reader io.Reader) (int, error) {
+	startTime := time.Now()
+	buffered := bufio.NewReaderSize(reader, detectBytes)
+
+	isCSV, err := detectCSV(buffered)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read import data: %w", err)
+	}
+
+	var users []database.DMRUser
+	if isCSV {
+		users, err = s.parseCSV(buffered)
+	} else {
+		users, err = s.parseDMRIds(buffered)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse import data: %w", err)
+	}
+
+	if len(users) == 0 {
+		return 0, fmt.Errorf("no valid users found in import data")
+	}
+
+	imported, err := s.store(users, startTime)
+	if err != nil {
+		return 0, err
+	}
+
+	if s.logger != nil {
+		s.logger.Printf("DMR user import completed: %d users imported", imported)
+	}
+	return imported, nil
+}
+
+// detectCSV peeks at the data and reports whether it is RadioID CSV
+// DMRIds.dat lines are whitespace separated and never contain commas.
+func detectCSV(reader *bufio.Reader) (bool, error) {
+	peek, err := reader.Peek(detectBytes)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return false, err
+	}
+
+	for _, line := range bytes.Split(peek, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		return bytes.IndexByte(line, ',') >= 0, nil
+	}
+	return false, nil
+}
+
+// parseDMRIds parses the DMRIds.dat format: "ID CALLSIGN [NAME...]"
+func (s *Syncer) parseDMRIds(reader io.Reader) ([]database.DMRUser, error) {
+	scanner := bufio.NewScanner(reader)
+	users := make([]database.DMRUser, 0, 100000)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		radioID, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil || radioID == 0 {
+			if s.logger != nil {
+				s.logger.Printf("Skipping invalid record at line %d: %q", lineNumber, line)
+			}
+			continue
+		}
+
+		user := database.DMRUser{
+			RadioID:   uint32(radioID),
+			Callsign:  strings.ToUpper(fields[1]),
+			UpdatedAt: time.Now(),
+		}
+		if len(fields) > 2 {
+			user.FirstName = fields[2]
+		}
+		if !user.IsValid() {
+			continue
+		}
+		users = append(users, user)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading line %d: %w", lineNumber, err)
+	}
+	return users, nil
+}
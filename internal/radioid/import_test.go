@@ -0,0 +1,78 @@
+package radioid
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncer_ImportDMRIds(t *testing.T) {
+	s := newTestSyncer(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	synced := false
+	s.SetOnSync(func() { synced = true })
+
+	data := "# DMRIds.dat\n3120001 w1aw Hiram\n\n2345678\tG4KLX\nbogus LINE\n"
+	path := filepath.Join(t.TempDir(), "DMRIds.dat")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := s.ImportFile(path)
+	if err != nil {
+		t.Fatalf("ImportFile error: %v", err)
+	}
+	if rows != 2 {
+		t.Errorf("rows = %d, want 2", rows)
+	}
+	if !synced {
+		t.Error("OnSync callback not called")
+	}
+
+	user, err := s.repository.GetByRadioID(3120001)
+	if err != nil {
+		t.Fatalf("GetByRadioID error: %v", err)
+	}
+	if user.Callsign != "W1AW" || user.FirstName != "Hiram" {
+		t.Errorf("user = %+v", user)
+	}
+	if status := s.Status(); status.RowsUpdated != 2 || status.LastSuccess.IsZero() {
+		t.Errorf("status after import = %+v", status)
+	}
+}
+
+func TestSyncer_ImportCSV(t *testing.T) {
+	s := newTestSyncer(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	data := "RADIO_ID,CALLSIGN,FIRST_NAME,LAST_NAME,CITY,STATE,COUNTRY\n" +
+		"2345678,G4KLX,Jonathan,Naylor,,,United Kingdom\n"
+
+	rows, err := s.Import(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Import error: %v", err)
+	}
+	if rows != 1 {
+		t.Errorf("rows = %d, want 1", rows)
+	}
+
+	user, err := s.repository.GetByRadioID(2345678)
+	if err != nil {
+		t.Fatalf("GetByRadioID error: %v", err)
+	}
+	if user.Country != "United Kingdom" {
+		t.Errorf("Country = %q, want United Kingdom", user.Country)
+	}
+}
+
+func TestSyncer_ImportEmpty(t *testing.T) {
+	s := newTestSyncer(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	if _, err := s.Import(strings.NewReader("# nothing here\n")); err == nil {
+		t.Error("Import of an empty file succeeded")
+	}
+	if status := s.Status(); status.ConsecutiveFailures != 1 {
+		t.Errorf("ConsecutiveFailures = %d, want 1", status.ConsecutiveFailures)
+	}
+}
@@ -0,0 +1,270 @@
+package radioid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/database"
+)
+
+const (
+	// UserAPIURL is the RadioID.net API answering single ID lookups
+	UserAPIURL = "https://radioid.net/api/dmr/user/"
+
+	// DefaultLookupRetry is how long an ID that could not be found is not
+	// looked up again
+	DefaultLookupRetry = time.Hour
+
+	// lookupQueueSize is how many IDs may wait for a lookup; more are dropped
+	lookupQueueSize = 16
+
+	// maxLookupsTracked bounds the IDs remembered as pending or not found
+	maxLookupsTracked = 4096
+
+	// minUserID is the lowest ID looked up; RadioID user IDs have seven
+	// digits and shorter IDs are talk groups
+	minUserID = 1000000
+	maxUserID = 0xFFFFFE // 0xFFFFFF is "all"
+)
+
+// IDLookup looks up single DMR IDs missing from the database on the RadioID.net
+// API, in the background, and stores the users found
+// Newly issued IDs then show correctly without waiting for the next sync.
+type IDLookup struct {
+	repository *database.DMRUserRepository
+	logger     *log.Logger
+	httpClient *http.Client
+	url        string
+	retryAfter time.Duration
+	onFound    func(database.DMRUser) // called from the lookup goroutine
+
+	queue chan uint32
+
+	mu   sync.Mutex
+	seen map[uint32]time.Time // ID -> when it may be looked up again
+
+	found    atomic.Uint64
+	notFound atomic.Uint64
+	failed   atomic.Uint64
+	dropped  atomic.Uint64
+}
+
+// IDLookupConfig holds configuration for the ID lookup
+type IDLookupConfig struct {
+	URL         string        // API URL (default: UserAPIURL)
+	HTTPTimeout time.Duration // HTTP request timeout (default: 30 seconds)
+	RetryAfter  time.Duration // Delay before looking up a missing ID again (default: 1 hour)
+}
+
+// IDLookupStats counts the outcome of the lookups
+type IDLookupStats struct {
+	Found    uint64 `json:"found"`
+	NotFound uint64 `json:"not_found"`
+	Failed   uint64 `json:"failed"`
+	Dropped  uint64 `json:"dropped"` // queue full
+}
+
+// NewIDLookup creates an ID lookup storing the users it finds in repository
+func NewIDLookup(repository *database.DMRUserRepository, logger *log.Logger, config IDLookupConfig) *IDLookup {
+	if config.URL == "" {
+		config.URL = UserAPIURL
+	}
+	if config.HTTPTimeout <= 0 {
+		config.HTTPTimeout = RequestTimeout
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = DefaultLookupRetry
+	}
+
+	return &IDLookup{
+		repository: repository,
+		logger:     logger,
+		httpClient: &http.Client{Timeout: config.HTTPTimeout},
+		url:        config.URL,
+		retryAfter: config.RetryAfter,
+		queue:      make(chan uint32, lookupQueueSize),
+		seen:       make(map[uint32]time.Time),
+	}
+}
+
+// SetOnFound sets a function called, from the lookup goroutine, after a user
+// has been found and stored
+// Must be called before Start.
+func (l *IDLookup) SetOnFound(fn func(database.DMRUser)) {
+	l.onFound = fn
+}
+
+// Request queues a lookup of id without blocking
+// IDs already queued, not found recently, or too short to be a user are
+// ignored.
+func (l *IDLookup) Request(id uint32) {
+	if id < minUserID || id > maxUserID {
+		return
+	}
+	now := time.Now()
+
+	l.mu.Lock()
+	if until, ok := l.seen[id]; ok && now.Before(until) {
+		l.mu.Unlock()
+		return
+	}
+	if len(l.seen) >= maxLookupsTracked {
+		l.expire(now)
+		if len(l.seen) >= maxLookupsTracked {
+			l.mu.Unlock()
+			l.dropped.Add(1)
+			return
+		}
+	}
+	l.seen[id] = now.Add(l.retryAfter)
+	l.mu.Unlock()
+
+	select {
+	case l.queue <- id:
+	default:
+		l.dropped.Add(1)
+		l.mu.Lock()
+		delete(l.seen, id)
+		l.mu.Unlock()
+	}
+}
+
+// Start looks up the queued IDs until ctx is done
+func (l *IDLookup) Start(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-l.queue:
+			l.lookup(ctx, id)
+		}
+	}
+}
+
+// Stats returns the lookup counters
+func (l *IDLookup) Stats() IDLookupStats {
+	return IDLookupStats{
+		Found:    l.found.Load(),
+		NotFound: l.notFound.Load(),
+		Failed:   l.failed.Load(),
+		Dropped:  l.dropped.Load(),
+	}
+}
+
+func (l *IDLookup) lookup(ctx context.Context, id uint32) {
+	user, err := l.Fetch(ctx, id)
+	switch {
+	case err != nil:
+		l.failed.Add(1)
+		l.logf("RadioID lookup of %d failed: %v", id, err)
+		return
+	case user == nil:
+		l.notFound.Add(1)
+		l.logf("RadioID lookup: %d is not a registered ID", id)
+		return
+	}
+
+	if err := l.repository.Upsert(user); err != nil {
+		l.failed.Add(1)
+		l.logf("RadioID lookup of %d: failed to store %s: %v", id, user.Callsign, err)
+		return
+	}
+
+	// Stored users are found in the database from now on
+	l.mu.Lock()
+	delete(l.seen, id)
+	l.mu.Unlock()
+
+	l.found.Add(1)
+	l.logf("RadioID lookup: %d is %s", id, user.Callsign)
+	if l.onFound != nil {
+		l.onFound(*user)
+	}
+}
+
+// apiUser is a user in a RadioID.net API response
+type apiUser struct {
+	ID       uint32 `json:"id"`
+	Callsign string `json:"callsign"`
+	FName    string `json:"fname"`
+	Surname  string `json:"surname"`
+	City     string `json:"city"`
+	State    string `json:"state"`
+	Country  string `json:"country"`
+}
+
+// Fetch queries the API for id, returning nil when the ID is not registered
+// The user is not stored.
+func (l *IDLookup) Fetch(ctx context.Context, id uint32) (*database.DMRUser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s?id=%d", l.url, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "YSF2DMR-Go/1.0")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	var body struct {
+		Results []apiUser `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	for _, result := range body.Results {
+		if result.ID != id {
+			continue
+		}
+		user := &database.DMRUser{
+			RadioID:   id,
+			Callsign:  strings.ToUpper(strings.TrimSpace(result.Callsign)),
+			FirstName: strings.TrimSpace(result.FName),
+			LastName:  strings.TrimSpace(result.Surname),
+			City:      strings.TrimSpace(result.City),
+			State:     strings.TrimSpace(result.State),
+			Country:   strings.TrimSpace(result.Country),
+			UpdatedAt: time.Now(),
+		}
+		if !user.IsValid() {
+			return nil, fmt.Errorf("invalid user %q", result.Callsign)
+		}
+		return user, nil
+	}
+	return nil, nil
+}
+
+// expire forgets IDs that may be looked up again
+// Callers must hold l.mu.
+func (l *IDLookup) expire(now time.Time) {
+	for id, until := range l.seen {
+		if !now.Before(until) {
+			delete(l.seen, id)
+		}
+	}
+}
+
+func (l *IDLookup) logf(format string, args ...interface{}) {
+	if l.logger != nil {
+		l.logger.Printf(format, args...)
+	}
+}
@@ -0,0 +1,110 @@
+package radioid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/database"
+)
+
+func newTestIDLookup(t *testing.T, handler http.HandlerFunc) (*IDLookup, *database.DMRUserRepository) {
+	t.Helper()
+
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "users.db")}, nil)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	repository := database.NewDMRUserRepository(db.GetDB())
+	return NewIDLookup(repository, nil, IDLookupConfig{URL: server.URL}), repository
+}
+
+func TestIDLookup_Found(t *testing.T) {
+	var requests atomic.Int32
+	l, repository := newTestIDLookup(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("id") != "3120001" {
+			fmt.Fprint(w, `{"count":0,"results":[]}`)
+			return
+		}
+		fmt.Fprint(w, `{"count":1,"results":[{"callsign":"w1aw","city":"Newington","country":"United States",`+
+			`"fname":"Hiram","id":3120001,"remarks":"","state":"Connecticut","surname":"Maxim"}]}`)
+	})
+
+	found := make(chan database.DMRUser, 1)
+	l.SetOnFound(func(user database.DMRUser) { found <- user })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go l.Start(ctx)
+
+	l.Request(3120001)
+	l.Request(3120001) // already queued
+	select {
+	case user := <-found:
+		if user.Callsign != "W1AW" || user.LastName != "Maxim" {
+			t.Errorf("found %+v", user)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("user not found")
+	}
+
+	if user, err := repository.GetByRadioID(3120001); err != nil || user.City != "Newington" {
+		t.Errorf("stored user = %+v, %v", user, err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("%d API requests, want 1", n)
+	}
+}
+
+func TestIDLookup_NotFound(t *testing.T) {
+	var requests atomic.Int32
+	l, _ := newTestIDLookup(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(w, `{"count":0,"results":[]}`)
+	})
+
+	ctx := context.Background()
+	l.Request(3129999)
+	l.lookup(ctx, <-l.queue)
+
+	// Not looked up again until the retry delay has passed
+	l.Request(3129999)
+	l.Request(91) // talk group
+	if len(l.queue) != 0 {
+		t.Errorf("%d lookups queued, want none", len(l.queue))
+	}
+	if stats := l.Stats(); stats.NotFound != 1 || stats.Found != 0 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("%d API requests, want 1", n)
+	}
+}
+
+func TestIDLookup_QueueFull(t *testing.T) {
+	l, _ := newTestIDLookup(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	for id := uint32(3100000); id < 3100000+lookupQueueSize+2; id++ {
+		l.Request(id)
+	}
+	if stats := l.Stats(); stats.Dropped != 2 {
+		t.Errorf("dropped %d, want 2", stats.Dropped)
+	}
+
+	// A dropped ID may be requested again
+	<-l.queue
+	l.Request(3100000 + lookupQueueSize + 1)
+	if len(l.queue) != lookupQueueSize {
+		t.Errorf("%d lookups queued, want %d", len(l.queue), lookupQueueSize)
+	}
+}
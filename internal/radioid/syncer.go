@@ -3,12 +3,14 @@ package radioid
 import (
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dbehnke/ysf2dmr/internal/database"
@@ -29,6 +31,9 @@ const (
 
 	// RetryDelay between retry attempts
 	RetryDelay = 5 * time.Second
+
+	// DefaultAlertAfterFailures is how many consecutive failed syncs trigger a warning
+	DefaultAlertAfterFailures = 3
 )
 
 // Syncer handles automatic synchronization of DMR user data from RadioID.net
@@ -37,12 +42,42 @@ type Syncer struct {
 	logger       *log.Logger
 	syncInterval time.Duration
 	httpClient   *http.Client
+	url          string
+	retryDelay   time.Duration
+	onSync       func() // Called after each successful sync
+	alertAfter   int
+	filter       Filter
+
+	mu     sync.Mutex
+	status SyncStatus
+}
+
+// SyncStatus describes the state of the RadioID synchronization
+type SyncStatus struct {
+	Running             bool          `json:"running"`              // A sync is in progress
+	LastAttempt         time.Time     `json:"last_attempt"`         // Start of the most recent sync
+	LastSuccess         time.Time     `json:"last_success"`         // End of the most recent successful sync
+	LastDuration        time.Duration `json:"last_duration"`        // Duration of the most recent sync
+	RowsUpdated         int           `json:"rows_updated"`         // Users imported by the last successful sync
+	LastError           string        `json:"last_error,omitempty"` // Error from the most recent failed sync
+	LastErrorTime       time.Time     `json:"last_error_time"`      // Time of the most recent failure
+	ConsecutiveFailures int           `json:"consecutive_failures"` // Failed syncs since the last success
+	NextSync            time.Time     `json:"next_sync"`            // Next scheduled sync (zero if not scheduled)
+	Interval            time.Duration `json:"interval"`
+}
+
+// Healthy returns false once syncs have failed alertAfter times in a row
+func (st SyncStatus) Healthy(alertAfter int) bool {
+	return alertAfter <= 0 || st.ConsecutiveFailures < alertAfter
 }
 
 // SyncerConfig holds configuration for the syncer
 type SyncerConfig struct {
-	SyncInterval time.Duration // How often to sync (default: 24 hours)
-	HTTPTimeout  time.Duration // HTTP request timeout (default: 30 seconds)
+	SyncInterval       time.Duration // How often to sync (default: 24 hours)
+	HTTPTimeout        time.Duration // HTTP request timeout (default: 30 seconds)
+	AlertAfterFailures int           // Consecutive failures before warning (default: 3)
+	URL                string        // Download URL (default: RadioIDURL)
+	Filter             Filter        // Users to store (default: all)
 }
 
 // NewSyncer creates a new RadioID syncer
@@ -61,6 +96,12 @@ func NewSyncerWithConfig(repository *database.DMRUserRepository, logger *log.Log
 	if config.HTTPTimeout <= 0 {
 		config.HTTPTimeout = RequestTimeout
 	}
+	if config.URL == "" {
+		config.URL = RadioIDURL
+	}
+	if config.AlertAfterFailures <= 0 {
+		config.AlertAfterFailures = DefaultAlertAfterFailures
+	}
 
 	return &Syncer{
 		repository:   repository,
@@ -69,9 +110,20 @@ func NewSyncerWithConfig(repository *database.DMRUserRepository, logger *log.Log
 		httpClient: &http.Client{
 			Timeout: config.HTTPTimeout,
 		},
+		url:        config.URL,
+		retryDelay: RetryDelay,
+		alertAfter: config.AlertAfterFailures,
+		filter:     config.Filter,
+		status:     SyncStatus{Interval: config.SyncInterval},
 	}
 }
 
+// SetOnSync sets a function called after each successful sync
+// Must be called before Start.
+func (s *Syncer) SetOnSync(fn func()) {
+	s.onSync = fn
+}
+
 // Start begins the automatic synchronization process
 func (s *Syncer) Start(ctx context.Context) {
 	if s.logger != nil {
@@ -88,6 +140,7 @@ func (s *Syncer) Start(ctx context.Context) {
 	// Set up periodic sync
 	ticker := time.NewTicker(s.syncInterval)
 	defer ticker.Stop()
+	s.setNextSync(time.Now().Add(s.syncInterval))
 
 	for {
 		select {
@@ -95,9 +148,11 @@ func (s *Syncer) Start(ctx context.Context) {
 			if s.logger != nil {
 				s.logger.Printf("RadioID syncer stopping")
 			}
+			s.setNextSync(time.Time{})
 			return
 
 		case <-ticker.C:
+			s.setNextSync(time.Now().Add(s.syncInterval))
 			if err := s.SyncNow(ctx); err != nil {
 				if s.logger != nil {
 					s.logger.Printf("RadioID sync failed: %v", err)
@@ -107,12 +162,109 @@ func (s *Syncer) Start(ctx context.Context) {
 	}
 }
 
-// SyncNow performs an immediate synchronization
+// ErrSyncRunning is returned when a sync or import is started while another
+// one is in progress
+var ErrSyncRunning = errors.New("sync already running")
+
+// SyncNow performs an immediate synchronization and records its outcome in the status
+// It returns ErrSyncRunning without syncing if a sync is in progress.
 func (s *Syncer) SyncNow(ctx context.Context) error {
+	startTime, err := s.beginSync()
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.syncOnce(ctx)
+	s.finishSync(startTime, rows, err)
+	return err
+}
+
+// SyncInBackground starts a synchronization limited to timeout on a new
+// goroutine, logging its failure
+// It returns ErrSyncRunning if a sync is in progress.
+func (s *Syncer) SyncInBackground(timeout time.Duration) error {
+	startTime, err := s.beginSync()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		rows, err := s.syncOnce(ctx)
+		s.finishSync(startTime, rows, err)
+		if err != nil && s.logger != nil {
+			s.logger.Printf("Requested RadioID sync failed: %v", err)
+		}
+	}()
+	return nil
+}
+
+// beginSync marks a sync or import as running, unless one already is
+func (s *Syncer) beginSync() (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status.Running {
+		return time.Time{}, ErrSyncRunning
+	}
+	startTime := time.Now()
+	s.status.Running = true
+	s.status.LastAttempt = startTime
+	return startTime, nil
+}
+
+// finishSync records the outcome of a sync or import started at startTime
+func (s *Syncer) finishSync(startTime time.Time, rows int, err error) {
+	s.mu.Lock()
+	s.status.Running = false
+	s.status.LastDuration = time.Since(startTime)
+	if err != nil {
+		s.status.LastError = err.Error()
+		s.status.LastErrorTime = time.Now()
+		s.status.ConsecutiveFailures++
+	} else {
+		s.status.LastSuccess = time.Now()
+		s.status.RowsUpdated = rows
+		s.status.LastError = ""
+		s.status.ConsecutiveFailures = 0
+	}
+	failures := s.status.ConsecutiveFailures
+	s.mu.Unlock()
+
+	if failures >= s.alertAfter && s.logger != nil {
+		s.logger.Printf("WARNING: RadioID sync has failed %d consecutive times (last error: %v); DMR ID data may be stale",
+			failures, err)
+	}
+
+	if err == nil && s.onSync != nil {
+		s.onSync()
+	}
+}
+
+// Status returns a copy of the current sync status
+func (s *Syncer) Status() SyncStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Healthy returns false once syncs have failed AlertAfterFailures times in a row
+func (s *Syncer) Healthy() bool {
+	return s.Status().Healthy(s.alertAfter)
+}
+
+func (s *Syncer) setNextSync(next time.Time) {
+	s.mu.Lock()
+	s.status.NextSync = next
+	s.mu.Unlock()
+}
+
+// syncOnce downloads and imports the RadioID data, returning the number of users imported
+func (s *Syncer) syncOnce(ctx context.Context) (int, error) {
 	startTime := time.Now()
 
 	if s.logger != nil {
-		s.logger.Printf("Starting RadioID sync from %s", RadioIDURL)
+		s.logger.Printf("Starting RadioID sync from %s", s.url)
 	}
 
 	// Download CSV data with retries
@@ -132,44 +284,79 @@ func (s *Syncer) SyncNow(ctx context.Context) error {
 		if attempt < MaxRetries {
 			select {
 			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(RetryDelay):
+				return 0, ctx.Err()
+			case <-time.After(s.retryDelay):
 				// Continue to next attempt
 			}
 		}
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to download after %d attempts: %w", MaxRetries, err)
+		return 0, fmt.Errorf("failed to download after %d attempts: %w", MaxRetries, err)
 	}
 	defer csvData.Close()
 
 	// Parse and import data
 	users, err := s.parseCSV(csvData)
 	if err != nil {
-		return fmt.Errorf("failed to parse CSV: %w", err)
+		return 0, fmt.Errorf("failed to parse CSV: %w", err)
 	}
 
 	if len(users) == 0 {
-		return fmt.Errorf("no valid users found in CSV")
+		return 0, fmt.Errorf("no valid users found in CSV")
 	}
 
 	// Import to database
-	if err := s.repository.UpsertBatch(users); err != nil {
-		return fmt.Errorf("failed to import users: %w", err)
+	imported, err := s.store(users, startTime)
+	if err != nil {
+		return 0, err
 	}
 
 	duration := time.Since(startTime)
 	if s.logger != nil {
-		s.logger.Printf("RadioID sync completed: %d users imported in %v", len(users), duration)
+		s.logger.Printf("RadioID sync completed: %d users imported in %v", imported, duration)
 	}
 
-	return nil
+	return imported, nil
+}
+
+// store imports the users within the filter for a sync started at
+// startTime, returning how many were imported
+// With a filter set, users stored earlier that are no longer within it (or
+// no longer listed) are removed, so the database only holds relevant IDs.
+func (s *Syncer) store(users []database.DMRUser, startTime time.Time) (int, error) {
+	if s.filter.Empty() {
+		if err := s.repository.UpsertBatch(users); err != nil {
+			return 0, fmt.Errorf("failed to import users: %w", err)
+		}
+		return len(users), nil
+	}
+
+	total := len(users)
+	users = s.filter.apply(users)
+	if s.logger != nil {
+		s.logger.Printf("Sync filter %q kept %d of %d users", s.filter, len(users), total)
+	}
+	if len(users) == 0 {
+		return 0, fmt.Errorf("no users match the sync filter %q", s.filter)
+	}
+	if err := s.repository.UpsertBatch(users); err != nil {
+		return 0, fmt.Errorf("failed to import users: %w", err)
+	}
+
+	pruned, err := s.repository.DeleteUpdatedBefore(startTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove users outside the sync filter: %w", err)
+	}
+	if pruned > 0 && s.logger != nil {
+		s.logger.Printf("Removed %d users outside the sync filter", pruned)
+	}
+	return len(users), nil
 }
 
 // downloadCSV downloads the CSV file from RadioID.net
 func (s *Syncer) downloadCSV(ctx context.Context) (io.ReadCloser, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", RadioIDURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", s.url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -307,7 +494,24 @@ func (s *Syncer) GetSyncStatistics() (map[string]interface{}, error) {
 	lastSync, _ := s.GetLastSyncTime()
 	stats["last_sync"] = lastSync
 	stats["sync_interval"] = s.syncInterval.String()
-	stats["next_sync"] = time.Now().Add(s.syncInterval)
+	for k, v := range s.StatusMap() {
+		stats[k] = v
+	}
 
 	return stats, nil
-}
\ No newline at end of file
+}
+
+// StatusMap returns the sync status as statistics entries
+func (s *Syncer) StatusMap() map[string]interface{} {
+	status := s.Status()
+	return map[string]interface{}{
+		"sync_running":              status.Running,
+		"sync_last_attempt":         status.LastAttempt,
+		"sync_last_success":         status.LastSuccess,
+		"sync_rows_updated":         status.RowsUpdated,
+		"sync_last_error":           status.LastError,
+		"sync_consecutive_failures": status.ConsecutiveFailures,
+		"next_sync":                 status.NextSync,
+		"sync_healthy":              status.Healthy(s.alertAfter),
+	}
+}
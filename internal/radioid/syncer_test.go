@@ -0,0 +1,113 @@
+package radioid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/database"
+)
+
+func newTestSyncer(t *testing.T, handler http.HandlerFunc) *Syncer {
+	t.Helper()
+
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "users.db")}, nil)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	s := NewSyncerWithConfig(database.NewDMRUserRepository(db.GetDB()), nil, SyncerConfig{
+		URL:                server.URL,
+		AlertAfterFailures: 2,
+	})
+	s.retryDelay = time.Millisecond
+	return s
+}
+
+func TestSyncer_StatusAfterSuccess(t *testing.T) {
+	s := newTestSyncer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "RADIO_ID,CALLSIGN,FIRST_NAME,LAST_NAME,CITY,STATE,COUNTRY\n")
+		fmt.Fprint(w, "3120001,W1AW,Hiram,Maxim,Newington,Connecticut,United States\n")
+		fmt.Fprint(w, "2345678,G4KLX,Jonathan,Naylor,,,United Kingdom\n")
+	})
+
+	synced := false
+	s.SetOnSync(func() { synced = true })
+
+	if err := s.SyncNow(context.Background()); err != nil {
+		t.Fatalf("SyncNow error: %v", err)
+	}
+
+	status := s.Status()
+	if status.RowsUpdated != 2 || status.LastSuccess.IsZero() || status.LastError != "" || status.Running {
+		t.Errorf("status after success = %+v", status)
+	}
+	if !synced {
+		t.Error("OnSync callback not called")
+	}
+	if !s.Healthy() {
+		t.Error("Healthy() = false after a successful sync")
+	}
+}
+
+func TestSyncer_StatusAfterFailures(t *testing.T) {
+	s := newTestSyncer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+
+	for i := 1; i <= 2; i++ {
+		if err := s.SyncNow(context.Background()); err == nil {
+			t.Fatal("expected sync error")
+		}
+		status := s.Status()
+		if status.ConsecutiveFailures != i || status.LastError == "" || status.LastErrorTime.IsZero() {
+			t.Errorf("status after failure %d = %+v", i, status)
+		}
+	}
+
+	if s.Healthy() {
+		t.Error("Healthy() = true after reaching the failure threshold")
+	}
+	if healthy := s.StatusMap()["sync_healthy"]; healthy != false {
+		t.Errorf("StatusMap sync_healthy = %v, want false", healthy)
+	}
+}
+
+func TestSyncer_RejectsConcurrentSync(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	s := newTestSyncer(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		fmt.Fprint(w, "RADIO_ID,CALLSIGN,FIRST_NAME,LAST_NAME,CITY,STATE,COUNTRY\n")
+	})
+
+	if err := s.SyncInBackground(time.Minute); err != nil {
+		t.Fatalf("SyncInBackground error: %v", err)
+	}
+	<-started
+	if err := s.SyncNow(context.Background()); err != ErrSyncRunning {
+		t.Errorf("SyncNow during a sync = %v, want ErrSyncRunning", err)
+	}
+	if err := s.SyncInBackground(time.Minute); err != ErrSyncRunning {
+		t.Errorf("SyncInBackground during a sync = %v, want ErrSyncRunning", err)
+	}
+	if _, err := s.Import(strings.NewReader("")); err != ErrSyncRunning {
+		t.Errorf("Import during a sync = %v, want ErrSyncRunning", err)
+	}
+
+	close(release)
+	for deadline := time.Now().Add(5 * time.Second); s.Status().Running; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("sync did not finish")
+		}
+	}
+}
@@ -0,0 +1,398 @@
+// Package recorder writes bridged calls to disk for traffic logging.
+//
+// Every call produces a raw AMBE stream (<base>.ambe, 9-byte AMBE+2 frames
+// with FEC as carried on air) and a metadata file (<base>.json). When a
+// transcoder backend is available the decoded audio is also written as an
+// 8kHz mono 16-bit WAV file (<base>.wav).
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+)
+
+// Recording file constants
+const (
+	AMBE_FRAME_LENGTH     = 9 // 72-bit AMBE+2 frame
+	AMBE_FRAMES_PER_BURST = 3
+
+	metadataExt = ".json"
+
+	// maxNameSuffix bounds the suffixes tried for calls starting in the
+	// same second from the same caller
+	maxNameSuffix = 100
+)
+
+// Metadata describes a recorded call
+type Metadata struct {
+	Source       string    `json:"source"` // Originating network ("YSF" or "DMR")
+	Src          string    `json:"src"`
+	Dst          string    `json:"dst"`
+	SrcID        uint32    `json:"src_id,omitempty"`
+	DstID        uint32    `json:"dst_id,omitempty"`
+	Private      bool      `json:"private,omitempty"` // DstID is a user, not a talk group
+	Emergency    bool      `json:"emergency,omitempty"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Frames       uint32    `json:"ambe_frames"`
+	DecodeErrors uint32    `json:"decode_errors,omitempty"`
+	Files        []string  `json:"files"`
+}
+
+// Recorder creates per-call recordings and enforces the retention limits
+type Recorder struct {
+	dir        string
+	maxAge     time.Duration // Zero disables age based pruning
+	maxBytes   int64         // Zero disables size based pruning
+	transcoder codec.Transcoder
+
+	mu sync.Mutex // serializes Prune
+
+	// Background pruning after each call; pruneAgain asks a running prune
+	// to go round once more
+	pruning    atomic.Bool
+	pruneAgain atomic.Bool
+	pruneWG    sync.WaitGroup
+}
+
+// NewRecorder creates a recorder writing to dir
+// transcoder may be nil, in which case only the AMBE stream is recorded.
+func NewRecorder(dir string, maxAge time.Duration, maxBytes int64, transcoder codec.Transcoder) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory %s: %v", dir, err)
+	}
+
+	return &Recorder{
+		dir:        dir,
+		maxAge:     maxAge,
+		maxBytes:   maxBytes,
+		transcoder: transcoder,
+	}, nil
+}
+
+// Start opens a new recording for a call
+func (r *Recorder) Start(meta Metadata) (*Recording, error) {
+	if meta.Start.IsZero() {
+		meta.Start = time.Now()
+	}
+
+	base, ambe, err := r.create(fmt.Sprintf("%s_%s_%s", meta.Start.UTC().Format("20060102-150405"),
+		meta.Source, sanitize(meta.Src)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AMBE recording: %v", err)
+	}
+	path := filepath.Join(r.dir, base)
+
+	rec := &Recording{
+		recorder: r,
+		path:     path,
+		meta:     meta,
+		ambe:     ambe,
+	}
+	rec.meta.Files = []string{base + ".ambe"}
+
+	if r.transcoder != nil {
+		wav, err := newWAVWriter(path + ".wav")
+		if err != nil {
+			log.Printf("Recorder: WAV output disabled for %s: %v", base, err)
+		} else {
+			rec.wav = wav
+			rec.meta.Files = append(rec.meta.Files, base+".wav")
+		}
+	}
+
+	return rec, nil
+}
+
+// create creates the AMBE file of a new recording named base, or base-2,
+// base-3 and so on when an earlier call took the name, returning the name used
+func (r *Recorder) create(base string) (string, *os.File, error) {
+	name := base
+	for n := 2; ; n++ {
+		file, err := os.OpenFile(filepath.Join(r.dir, name+".ambe"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			return name, file, nil
+		}
+		if !os.IsExist(err) || n > maxNameSuffix {
+			return "", nil, err
+		}
+		name = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// pruneInBackground runs Prune on a goroutine, so closing a recording
+// never waits for the directory walk
+// Requests made while a prune runs are folded into one more pass.
+func (r *Recorder) pruneInBackground() {
+	if r.maxAge <= 0 && r.maxBytes <= 0 {
+		return
+	}
+	r.pruneAgain.Store(true)
+	if !r.pruning.CompareAndSwap(false, true) {
+		return
+	}
+
+	r.pruneWG.Add(1)
+	go func() {
+		defer r.pruneWG.Done()
+		for {
+			r.pruneAgain.Store(false)
+			if err := r.Prune(); err != nil {
+				log.Printf("Recorder: %v", err)
+			}
+			r.pruning.Store(false)
+			if !r.pruneAgain.Load() || !r.pruning.CompareAndSwap(false, true) {
+				return
+			}
+		}
+	}()
+}
+
+// Wait blocks until background pruning has finished
+func (r *Recorder) Wait() {
+	r.pruneWG.Wait()
+}
+
+// Prune removes recordings older than the maximum age and then the oldest
+// recordings until the directory is within the size limit
+func (r *Recorder) Prune() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls, err := r.listCalls()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, c := range calls {
+		total += c.size
+	}
+
+	cutoff := time.Now().Add(-r.maxAge)
+	for _, c := range calls { // Oldest first
+		expired := r.maxAge > 0 && c.modTime.Before(cutoff)
+		oversize := r.maxBytes > 0 && total > r.maxBytes
+		if !expired && !oversize {
+			continue
+		}
+		for _, file := range c.files {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				log.Printf("Recorder: failed to remove %s: %v", file, err)
+			}
+		}
+		total -= c.size
+	}
+
+	return nil
+}
+
+// call groups the files belonging to one recording
+type call struct {
+	files   []string
+	size    int64
+	modTime time.Time
+}
+
+// listCalls returns the recordings in the directory, oldest first
+func (r *Recorder) listCalls() ([]*call, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording directory: %v", err)
+	}
+
+	byBase := make(map[string]*call)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		if ext != ".ambe" && ext != ".wav" && ext != metadataExt {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		base := strings.TrimSuffix(name, ext)
+		c, ok := byBase[base]
+		if !ok {
+			c = &call{}
+			byBase[base] = c
+		}
+		c.files = append(c.files, filepath.Join(r.dir, name))
+		c.size += info.Size()
+		if info.ModTime().After(c.modTime) {
+			c.modTime = info.ModTime()
+		}
+	}
+
+	calls := make([]*call, 0, len(byBase))
+	for _, c := range byBase {
+		calls = append(calls, c)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		return calls[i].modTime.Before(calls[j].modTime)
+	})
+	return calls, nil
+}
+
+// Recording is a single call being written to disk
+type Recording struct {
+	recorder *Recorder
+	path     string
+	meta     Metadata
+	ambe     *os.File
+	wav      *wavWriter
+	closed   bool
+}
+
+// WriteDMRBurst records the three AMBE frames carried in a 33-byte DMR voice burst
+func (rec *Recording) WriteDMRBurst(burst []byte) error {
+	frames, err := ExtractDMRAMBE(burst)
+	if err != nil {
+		return err
+	}
+	for _, frame := range frames {
+		if err := rec.WriteAMBE(frame[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteAMBE records one 9-byte AMBE frame
+func (rec *Recording) WriteAMBE(frame []byte) error {
+	if rec.closed {
+		return fmt.Errorf("recording already closed")
+	}
+	if len(frame) != AMBE_FRAME_LENGTH {
+		return fmt.Errorf("invalid AMBE frame length: got %d, want %d", len(frame), AMBE_FRAME_LENGTH)
+	}
+
+	if _, err := rec.ambe.Write(frame); err != nil {
+		return fmt.Errorf("failed to write AMBE frame: %v", err)
+	}
+	rec.meta.Frames++
+
+	if rec.wav != nil {
+		pcm, err := rec.recorder.transcoder.DecodeAMBE(frame)
+		if err != nil {
+			rec.meta.DecodeErrors++
+			pcm = make([]int16, codec.PCM_SAMPLES_PER_FRAME) // Keep the timing with silence
+		}
+		if err := rec.wav.WriteSamples(pcm); err != nil {
+			return fmt.Errorf("failed to write WAV samples: %v", err)
+		}
+	}
+	return nil
+}
+
+// SetEmergency marks the recorded call as an emergency call
+func (rec *Recording) SetEmergency(emergency bool) {
+	rec.meta.Emergency = emergency
+}
+
+// Close finishes the recording and writes its metadata
+// The retention limits are applied afterwards in the background.
+func (rec *Recording) Close() error {
+	if rec.closed {
+		return nil
+	}
+	rec.closed = true
+	rec.meta.End = time.Now()
+
+	var firstErr error
+	if err := rec.ambe.Close(); err != nil {
+		firstErr = err
+	}
+	if rec.wav != nil {
+		if err := rec.wav.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	rec.meta.Files = append(rec.meta.Files, filepath.Base(rec.path)+metadataExt)
+	data, err := json.MarshalIndent(rec.meta, "", "  ")
+	if err == nil {
+		err = os.WriteFile(rec.path+metadataExt, data, 0644)
+	}
+	if err != nil && firstErr == nil {
+		firstErr = fmt.Errorf("failed to write recording metadata: %v", err)
+	}
+
+	rec.recorder.pruneInBackground()
+	return firstErr
+}
+
+// Metadata returns the current metadata of the recording
+func (rec *Recording) Metadata() Metadata {
+	return rec.meta
+}
+
+// ExtractDMRAMBE extracts the three 72-bit AMBE frames from a DMR voice burst
+// The frames occupy bits 0-107 and 156-263; the 48 bits between carry sync or EMB.
+func ExtractDMRAMBE(burst []byte) ([AMBE_FRAMES_PER_BURST][AMBE_FRAME_LENGTH]byte, error) {
+	var frames [AMBE_FRAMES_PER_BURST][AMBE_FRAME_LENGTH]byte
+	if len(burst) < 33 {
+		return frames, fmt.Errorf("DMR burst too short: got %d bytes, need 33", len(burst))
+	}
+
+	out := 0
+	for pos := 0; pos < 264; pos++ {
+		if pos >= 108 && pos < 156 {
+			continue
+		}
+		if burst[pos/8]&(0x80>>(pos%8)) != 0 {
+			frames[out/72][(out%72)/8] |= 0x80 >> (out % 8)
+		}
+		out++
+	}
+	return frames, nil
+}
+
+// InsertDMRAMBE builds a 33-byte DMR voice burst from three AMBE frames
+// It is the inverse of ExtractDMRAMBE; the sync/EMB field is left zero.
+func InsertDMRAMBE(frames [AMBE_FRAMES_PER_BURST][AMBE_FRAME_LENGTH]byte) []byte {
+	burst := make([]byte, 33)
+
+	in := 0
+	for pos := 0; pos < 264; pos++ {
+		if pos >= 108 && pos < 156 {
+			continue
+		}
+		if frames[in/72][(in%72)/8]&(0x80>>(in%8)) != 0 {
+			burst[pos/8] |= 0x80 >> (pos % 8)
+		}
+		in++
+	}
+	return burst
+}
+
+// sanitize makes a callsign or ID safe for use in a file name
+func sanitize(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "unknown"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
@@ -0,0 +1,236 @@
+package recorder
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+)
+
+type fakeTranscoder struct{}
+
+func (fakeTranscoder) DecodeAMBE(frame []byte) ([]int16, error) {
+	return make([]int16, codec.PCM_SAMPLES_PER_FRAME), nil
+}
+
+func (fakeTranscoder) EncodeAMBE(pcm []int16) ([]byte, error) {
+	return make([]byte, AMBE_FRAME_LENGTH), nil
+}
+
+func (fakeTranscoder) Close() error { return nil }
+
+func TestRecording_WriteAndClose(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(dir, 0, 0, fakeTranscoder{})
+	if err != nil {
+		t.Fatalf("NewRecorder error: %v", err)
+	}
+
+	rec, err := r.Start(Metadata{Source: "DMR", Src: "W1AW/3120001", Dst: "TG 91", Start: time.Unix(1700000000, 0)})
+	if err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	if err := rec.WriteDMRBurst(make([]byte, 33)); err != nil {
+		t.Fatalf("WriteDMRBurst error: %v", err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+
+	base := filepath.Join(dir, "20231114-221320_DMR_W1AW_3120001")
+
+	ambe, err := os.ReadFile(base + ".ambe")
+	if err != nil {
+		t.Fatalf("AMBE file missing: %v", err)
+	}
+	if len(ambe) != AMBE_FRAMES_PER_BURST*AMBE_FRAME_LENGTH {
+		t.Errorf("AMBE file length = %d, want %d", len(ambe), AMBE_FRAMES_PER_BURST*AMBE_FRAME_LENGTH)
+	}
+
+	wav, err := os.ReadFile(base + ".wav")
+	if err != nil {
+		t.Fatalf("WAV file missing: %v", err)
+	}
+	wantData := uint32(AMBE_FRAMES_PER_BURST * codec.PCM_SAMPLES_PER_FRAME * 2)
+	if got := binary.LittleEndian.Uint32(wav[40:44]); got != wantData {
+		t.Errorf("WAV data size = %d, want %d", got, wantData)
+	}
+
+	data, err := os.ReadFile(base + ".json")
+	if err != nil {
+		t.Fatalf("metadata file missing: %v", err)
+	}
+	var meta Metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		t.Fatalf("metadata decode error: %v", err)
+	}
+	if meta.Frames != 3 || len(meta.Files) != 3 {
+		t.Errorf("metadata Frames=%d Files=%v, want 3 frames and 3 files", meta.Frames, meta.Files)
+	}
+}
+
+func TestRecorder_SameSecondCalls(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(dir, 0, 0, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder error: %v", err)
+	}
+
+	meta := Metadata{Source: "YSF", Src: "W1AW", Dst: "TG 91", Start: time.Unix(1700000000, 0)}
+	var names []string
+	for i := 1; i <= 3; i++ {
+		rec, err := r.Start(meta)
+		if err != nil {
+			t.Fatalf("Start(%d) error: %v", i, err)
+		}
+		for j := 0; j < i; j++ {
+			rec.WriteAMBE(make([]byte, AMBE_FRAME_LENGTH))
+		}
+		if err := rec.Close(); err != nil {
+			t.Fatalf("Close(%d) error: %v", i, err)
+		}
+		names = append(names, rec.Metadata().Files[0])
+	}
+
+	want := []string{"20231114-221320_YSF_W1AW.ambe", "20231114-221320_YSF_W1AW-2.ambe", "20231114-221320_YSF_W1AW-3.ambe"}
+	for i, name := range names {
+		if name != want[i] {
+			t.Errorf("recording %d = %s, want %s", i+1, name, want[i])
+		}
+		// Each call keeps its own frames
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || info.Size() != int64((i+1)*AMBE_FRAME_LENGTH) {
+			t.Errorf("%s: %v, size %v", name, err, info)
+		}
+	}
+}
+
+func TestRecording_ClosePrunesInBackground(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(dir, time.Hour, 0, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder error: %v", err)
+	}
+	path := filepath.Join(dir, "old.ambe")
+	os.WriteFile(path, make([]byte, AMBE_FRAME_LENGTH), 0644)
+	old := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(path, old, old)
+
+	rec, err := r.Start(Metadata{Source: "DMR", Src: "3120001"})
+	if err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	r.Wait()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expired recording was not pruned after Close")
+	}
+	if _, err := os.Stat(filepath.Join(dir, rec.Metadata().Files[0])); err != nil {
+		t.Errorf("new recording pruned: %v", err)
+	}
+}
+
+func TestRecorder_PruneBySize(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(dir, 0, 100, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder error: %v", err)
+	}
+
+	old := time.Now().Add(-time.Hour)
+	for i, name := range []string{"a", "b"} {
+		path := filepath.Join(dir, name+".ambe")
+		if err := os.WriteFile(path, make([]byte, 80), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := old.Add(time.Duration(i) * time.Minute)
+		os.Chtimes(path, modTime, modTime)
+	}
+
+	if err := r.Prune(); err != nil {
+		t.Fatalf("Prune error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.ambe")); !os.IsNotExist(err) {
+		t.Error("oldest recording was not pruned")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.ambe")); err != nil {
+		t.Error("newest recording was pruned")
+	}
+}
+
+func TestRecorder_PruneByAge(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewRecorder(dir, 24*time.Hour, 0, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder error: %v", err)
+	}
+
+	path := filepath.Join(dir, "old.json")
+	os.WriteFile(path, []byte("{}"), 0644)
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(path, old, old)
+
+	if err := r.Prune(); err != nil {
+		t.Fatalf("Prune error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expired recording was not pruned")
+	}
+}
+
+func TestExtractDMRAMBE(t *testing.T) {
+	burst := make([]byte, 33)
+	for i := range burst {
+		burst[i] = 0xFF
+	}
+	// Clear the sync/EMB field (bits 108-155)
+	for pos := 108; pos < 156; pos++ {
+		burst[pos/8] &^= 0x80 >> (pos % 8)
+	}
+
+	frames, err := ExtractDMRAMBE(burst)
+	if err != nil {
+		t.Fatalf("ExtractDMRAMBE error: %v", err)
+	}
+	for i, frame := range frames {
+		for _, b := range frame {
+			if b != 0xFF {
+				t.Fatalf("frame %d = %X, want all ones", i, frame)
+			}
+		}
+	}
+
+	if _, err := ExtractDMRAMBE(make([]byte, 10)); err == nil {
+		t.Error("expected error for short burst")
+	}
+}
+
+func TestInsertDMRAMBE(t *testing.T) {
+	var frames [AMBE_FRAMES_PER_BURST][AMBE_FRAME_LENGTH]byte
+	for i := range frames {
+		for j := range frames[i] {
+			frames[i][j] = byte(i*AMBE_FRAME_LENGTH + j + 1)
+		}
+	}
+
+	burst := InsertDMRAMBE(frames)
+	for pos := 108; pos < 156; pos++ {
+		if burst[pos/8]&(0x80>>(pos%8)) != 0 {
+			t.Fatalf("sync/EMB bit %d set", pos)
+		}
+	}
+
+	got, err := ExtractDMRAMBE(burst)
+	if err != nil {
+		t.Fatalf("ExtractDMRAMBE error: %v", err)
+	}
+	if got != frames {
+		t.Errorf("round trip = %X, want %X", got, frames)
+	}
+}
@@ -0,0 +1,81 @@
+package recorder
+
+import (
+	"encoding/binary"
+	"os"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+)
+
+const wavHeaderLength = 44
+
+// wavWriter writes 8kHz mono 16-bit PCM to a WAV file
+// The RIFF sizes are patched in when the file is closed.
+type wavWriter struct {
+	file     *os.File
+	dataSize uint32
+}
+
+func newWAVWriter(path string) (*wavWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &wavWriter{file: file}
+	if err := w.writeHeader(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// WriteSamples appends PCM samples
+func (w *wavWriter) WriteSamples(pcm []int16) error {
+	buf := make([]byte, len(pcm)*2)
+	for i, sample := range pcm {
+		binary.LittleEndian.PutUint16(buf[i*2:], uint16(sample))
+	}
+	n, err := w.file.Write(buf)
+	w.dataSize += uint32(n)
+	return err
+}
+
+// Close patches the header sizes and closes the file
+func (w *wavWriter) Close() error {
+	if _, err := w.file.Seek(0, 0); err != nil {
+		w.file.Close()
+		return err
+	}
+	if err := w.writeHeader(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+func (w *wavWriter) writeHeader() error {
+	const (
+		channels      = 1
+		bitsPerSample = 16
+		blockAlign    = channels * bitsPerSample / 8
+	)
+
+	header := make([]byte, wavHeaderLength)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], 36+w.dataSize)
+	copy(header[8:12], "WAVE")
+	copy(header[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:20], 16) // PCM format chunk size
+	binary.LittleEndian.PutUint16(header[20:22], 1)  // PCM
+	binary.LittleEndian.PutUint16(header[22:24], channels)
+	binary.LittleEndian.PutUint32(header[24:28], codec.PCM_SAMPLE_RATE)
+	binary.LittleEndian.PutUint32(header[28:32], codec.PCM_SAMPLE_RATE*blockAlign)
+	binary.LittleEndian.PutUint16(header[32:34], blockAlign)
+	binary.LittleEndian.PutUint16(header[34:36], bitsPerSample)
+	copy(header[36:40], "data")
+	binary.LittleEndian.PutUint32(header[40:44], w.dataSize)
+
+	_, err := w.file.Write(header)
+	return err
+}
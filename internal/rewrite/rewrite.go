@@ -0,0 +1,105 @@
+// Package rewrite maps talk groups and private call IDs between the numbers
+// YSF users select and the ones the DMR master uses, like the TGRewrite and
+// PCRewrite rules of DMRGateway.
+package rewrite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule maps Range IDs from FromID on FromSlot, as seen from YSF, to the same
+// number of IDs from ToID on ToSlot on the master, and back
+type Rule struct {
+	Private  bool // PCRewrite: private call IDs, otherwise talk groups
+	FromSlot uint8
+	FromID   uint32
+	ToSlot   uint8
+	ToID     uint32
+	Range    uint32
+}
+
+// ParseRule parses a rule written as fromSlot,fromID,toSlot,toID,range
+func ParseRule(private bool, value string) (Rule, error) {
+	fields := strings.Split(value, ",")
+	if len(fields) != 5 {
+		return Rule{}, fmt.Errorf("rewrite rule %q: want fromSlot,fromID,toSlot,toID,range", value)
+	}
+
+	var numbers [5]uint32
+	for i, field := range fields {
+		v, err := strconv.ParseUint(strings.TrimSpace(field), 10, 24)
+		if err != nil {
+			return Rule{}, fmt.Errorf("rewrite rule %q: invalid number %q", value, strings.TrimSpace(field))
+		}
+		numbers[i] = uint32(v)
+	}
+
+	rule := Rule{
+		Private:  private,
+		FromSlot: uint8(numbers[0]),
+		FromID:   numbers[1],
+		ToSlot:   uint8(numbers[2]),
+		ToID:     numbers[3],
+		Range:    numbers[4],
+	}
+	switch {
+	case rule.FromSlot < 1 || rule.FromSlot > 2 || rule.ToSlot < 1 || rule.ToSlot > 2:
+		return Rule{}, fmt.Errorf("rewrite rule %q: slots must be 1 or 2", value)
+	case rule.Range == 0:
+		return Rule{}, fmt.Errorf("rewrite rule %q: range must be at least 1", value)
+	case rule.FromID+rule.Range > 1<<24 || rule.ToID+rule.Range > 1<<24:
+		return Rule{}, fmt.Errorf("rewrite rule %q: IDs beyond the 24-bit range", value)
+	}
+	return rule, nil
+}
+
+// String formats the rule for logs
+func (r Rule) String() string {
+	kind := "TG"
+	if r.Private {
+		kind = "PC"
+	}
+	if r.Range == 1 {
+		return fmt.Sprintf("%s %d/%d <-> %d/%d", kind, r.FromSlot, r.FromID, r.ToSlot, r.ToID)
+	}
+	return fmt.Sprintf("%s %d/%d-%d <-> %d/%d-%d", kind,
+		r.FromSlot, r.FromID, r.FromID+r.Range-1, r.ToSlot, r.ToID, r.ToID+r.Range-1)
+}
+
+// Rewriter applies the first matching rule in each direction; IDs no rule
+// matches are left as they are
+type Rewriter struct {
+	rules []Rule
+}
+
+// New creates a rewriter for rules, tried in order
+func New(rules []Rule) *Rewriter {
+	return &Rewriter{rules: rules}
+}
+
+// Rules returns the rules in the order they are tried
+func (r *Rewriter) Rules() []Rule {
+	return r.rules
+}
+
+// ToNetwork returns the slot and ID sent to the master for a call from YSF
+func (r *Rewriter) ToNetwork(slot uint8, id uint32, private bool) (uint8, uint32) {
+	for _, rule := range r.rules {
+		if rule.Private == private && rule.FromSlot == slot && id >= rule.FromID && id-rule.FromID < rule.Range {
+			return rule.ToSlot, rule.ToID + (id - rule.FromID)
+		}
+	}
+	return slot, id
+}
+
+// FromNetwork returns the slot and ID shown on YSF for a call from the master
+func (r *Rewriter) FromNetwork(slot uint8, id uint32, private bool) (uint8, uint32) {
+	for _, rule := range r.rules {
+		if rule.Private == private && rule.ToSlot == slot && id >= rule.ToID && id-rule.ToID < rule.Range {
+			return rule.FromSlot, rule.FromID + (id - rule.ToID)
+		}
+	}
+	return slot, id
+}
@@ -0,0 +1,57 @@
+package rewrite
+
+import "testing"
+
+func TestParseRule(t *testing.T) {
+	rule, err := ParseRule(false, "2, 9, 1, 9990, 1")
+	if err != nil {
+		t.Fatalf("ParseRule() error = %v", err)
+	}
+	want := Rule{FromSlot: 2, FromID: 9, ToSlot: 1, ToID: 9990, Range: 1}
+	if rule != want {
+		t.Errorf("ParseRule() = %+v, want %+v", rule, want)
+	}
+
+	for _, value := range []string{"2,9,1,9990", "3,9,1,9990,1", "2,9,1,9990,0", "2,x,1,9990,1", "2,16777215,2,1,2"} {
+		if _, err := ParseRule(false, value); err == nil {
+			t.Errorf("ParseRule(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestRewriter(t *testing.T) {
+	r := New([]Rule{
+		{FromSlot: 2, FromID: 1, ToSlot: 2, ToID: 2000001, Range: 999999},
+		{Private: true, FromSlot: 2, FromID: 4000, ToSlot: 2, ToID: 84000, Range: 1001},
+		{FromSlot: 2, FromID: 9, ToSlot: 1, ToID: 9, Range: 1},
+	})
+
+	tests := []struct {
+		slot     uint8
+		id       uint32
+		private  bool
+		wantSlot uint8
+		wantID   uint32
+	}{
+		{2, 91, false, 2, 2000091},
+		{2, 4001, true, 2, 84001},
+		{2, 4001, false, 2, 2004001},
+		{1, 91, false, 1, 91},
+		{2, 5001, true, 2, 5001},
+	}
+	for _, tt := range tests {
+		slot, id := r.ToNetwork(tt.slot, tt.id, tt.private)
+		if slot != tt.wantSlot || id != tt.wantID {
+			t.Errorf("ToNetwork(%d, %d, %v) = %d, %d, want %d, %d",
+				tt.slot, tt.id, tt.private, slot, id, tt.wantSlot, tt.wantID)
+		}
+		if slot, id = r.FromNetwork(slot, id, tt.private); slot != tt.slot || id != tt.id {
+			t.Errorf("FromNetwork(ToNetwork(%d, %d)) = %d, %d, want the original back", tt.slot, tt.id, slot, id)
+		}
+	}
+
+	// A rule can move a talk group to the other slot on the master
+	if slot, id := r.FromNetwork(1, 9, false); slot != 2 || id != 9 {
+		t.Errorf("FromNetwork(1, 9) = %d, %d, want 2, 9", slot, id)
+	}
+}
@@ -0,0 +1,124 @@
+// Package scheduler drives periodic work from a single timer using monotonic
+// deadlines. Each task's next deadline is its previous deadline plus its
+// period, so late wake-ups do not accumulate into drift and tasks keep their
+// timing relative to each other.
+package scheduler
+
+import (
+	"time"
+)
+
+// TaskFunc runs a task; elapsed is the time since the task's previous
+// deadline, always a whole number of periods
+type TaskFunc func(elapsed time.Duration)
+
+// Task is a periodic task registered with a Scheduler
+type Task struct {
+	name     string
+	period   time.Duration
+	next     time.Time
+	fn       TaskFunc
+	overruns uint64
+}
+
+// Name returns the task name
+func (t *Task) Name() string {
+	return t.name
+}
+
+// Overruns returns the number of deadlines skipped because the task ran late
+func (t *Task) Overruns() uint64 {
+	return t.overruns
+}
+
+// Scheduler runs periodic tasks at their deadlines
+// It is not safe for concurrent use; tasks run on the goroutine calling RunDue.
+type Scheduler struct {
+	tasks []*Task
+	timer *time.Timer
+}
+
+// New creates an empty scheduler
+func New() *Scheduler {
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	return &Scheduler{timer: timer}
+}
+
+// Every registers a task that runs once per period, first one period from now
+func (s *Scheduler) Every(name string, period time.Duration, fn TaskFunc) *Task {
+	return s.add(name, period, time.Now(), fn)
+}
+
+func (s *Scheduler) add(name string, period time.Duration, start time.Time, fn TaskFunc) *Task {
+	if period <= 0 {
+		panic("scheduler: non-positive period for " + name)
+	}
+	task := &Task{name: name, period: period, next: start.Add(period), fn: fn}
+	s.tasks = append(s.tasks, task)
+	s.arm(start)
+	return task
+}
+
+// C returns a channel that receives when the earliest deadline is reached
+// Call RunDue after every receive.
+func (s *Scheduler) C() <-chan time.Time {
+	return s.timer.C
+}
+
+// RunDue runs every task whose deadline has passed at now, then re-arms the timer
+// A task that has fallen more than a period behind runs once, skipping the
+// missed deadlines, rather than bursting to catch up.
+func (s *Scheduler) RunDue(now time.Time) {
+	for _, task := range s.tasks {
+		if now.Before(task.next) {
+			continue
+		}
+
+		missed := uint64(now.Sub(task.next) / task.period)
+		task.overruns += missed
+		elapsed := time.Duration(missed+1) * task.period
+		task.next = task.next.Add(elapsed)
+		task.fn(elapsed)
+	}
+	s.arm(now)
+}
+
+// Next returns the earliest deadline
+func (s *Scheduler) Next() time.Time {
+	var next time.Time
+	for _, task := range s.tasks {
+		if next.IsZero() || task.next.Before(next) {
+			next = task.next
+		}
+	}
+	return next
+}
+
+// Overruns returns the number of deadlines skipped across all tasks
+func (s *Scheduler) Overruns() uint64 {
+	var total uint64
+	for _, task := range s.tasks {
+		total += task.overruns
+	}
+	return total
+}
+
+// Stop stops the timer
+func (s *Scheduler) Stop() {
+	s.timer.Stop()
+}
+
+// arm sets the timer for the earliest deadline
+func (s *Scheduler) arm(now time.Time) {
+	next := s.Next()
+	if next.IsZero() {
+		return
+	}
+	s.timer.Stop()
+	select {
+	case <-s.timer.C:
+	default:
+	}
+	s.timer.Reset(next.Sub(now))
+}
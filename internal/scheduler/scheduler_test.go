@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduler_DeadlinesDoNotDrift(t *testing.T) {
+	s := New()
+	defer s.Stop()
+
+	start := time.Now()
+	var runs int
+	var total time.Duration
+	s.add("frame", 60*time.Millisecond, start, func(elapsed time.Duration) {
+		runs++
+		total += elapsed
+	})
+
+	// Every wake-up is 7ms late, but each deadline stays anchored to the start
+	for i := 1; i <= 100; i++ {
+		s.RunDue(start.Add(time.Duration(i)*60*time.Millisecond + 7*time.Millisecond))
+	}
+
+	if runs != 100 {
+		t.Errorf("runs = %d, want 100", runs)
+	}
+	if total != 6*time.Second {
+		t.Errorf("total elapsed = %v, want 6s", total)
+	}
+	if want := start.Add(101 * 60 * time.Millisecond); !s.Next().Equal(want) {
+		t.Errorf("Next() = %v after start, want %v", s.Next().Sub(start), want.Sub(start))
+	}
+	if s.Overruns() != 0 {
+		t.Errorf("Overruns() = %d, want 0", s.Overruns())
+	}
+}
+
+func TestScheduler_RunsOnlyDueTasks(t *testing.T) {
+	s := New()
+	defer s.Stop()
+
+	start := time.Now()
+	counts := map[string]int{}
+	for _, task := range []struct {
+		name   string
+		period time.Duration
+	}{{"ysf", 100 * time.Millisecond}, {"dmr", 60 * time.Millisecond}, {"clock", 10 * time.Millisecond}} {
+		name := task.name
+		s.add(name, task.period, start, func(time.Duration) { counts[name]++ })
+	}
+
+	for now := start; now.Before(start.Add(time.Second)); now = now.Add(time.Millisecond) {
+		s.RunDue(now)
+	}
+
+	// Deadlines up to 999ms after the start
+	want := map[string]int{"ysf": 9, "dmr": 16, "clock": 99}
+	for name, n := range want {
+		if counts[name] != n {
+			t.Errorf("%s ran %d times, want %d", name, counts[name], n)
+		}
+	}
+}
+
+func TestScheduler_SkipsMissedDeadlines(t *testing.T) {
+	s := New()
+	defer s.Stop()
+
+	start := time.Now()
+	var elapsed []time.Duration
+	task := s.add("frame", 100*time.Millisecond, start, func(e time.Duration) {
+		elapsed = append(elapsed, e)
+	})
+
+	// Stalled for 350ms past the first deadline
+	s.RunDue(start.Add(450 * time.Millisecond))
+	s.RunDue(start.Add(500 * time.Millisecond))
+
+	if len(elapsed) != 2 {
+		t.Fatalf("ran %d times, want 2", len(elapsed))
+	}
+	if elapsed[0] != 400*time.Millisecond || elapsed[1] != 100*time.Millisecond {
+		t.Errorf("elapsed = %v, want [400ms 100ms]", elapsed)
+	}
+	if task.Overruns() != 3 {
+		t.Errorf("Overruns() = %d, want 3", task.Overruns())
+	}
+}
+
+func TestScheduler_TimerFires(t *testing.T) {
+	s := New()
+	defer s.Stop()
+
+	ran := false
+	s.Every("quick", 5*time.Millisecond, func(time.Duration) { ran = true })
+
+	select {
+	case <-s.C():
+		s.RunDue(time.Now())
+	case <-time.After(time.Second):
+		t.Fatal("timer did not fire")
+	}
+	if !ran {
+		t.Error("task did not run")
+	}
+}
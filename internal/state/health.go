@@ -0,0 +1,84 @@
+package state
+
+import (
+	"fmt"
+	"time"
+)
+
+// Health is a network link's health judged from the traffic received on it
+type Health int32
+
+const (
+	HealthOK       Health = iota
+	HealthDegraded        // keepalive replies missed, or no data for too long
+	HealthDown            // not connected, or keepalive replies stopped
+)
+
+func (h Health) String() string {
+	switch h {
+	case HealthOK:
+		return "OK"
+	case HealthDegraded:
+		return "DEGRADED"
+	case HealthDown:
+		return "DOWN"
+	default:
+		return "unknown"
+	}
+}
+
+// Keepalive replies missing this many intervals degrade a link, and this
+// many take it down
+const (
+	healthDegradedIntervals = 2
+	healthDownIntervals     = 3
+)
+
+// HealthPolicy is what a link's traffic is judged against
+type HealthPolicy struct {
+	KeepaliveInterval time.Duration // expected time between keepalive replies; 0 for links without them
+	DataTimeout       time.Duration // longest expected silence between data packets; 0 does not score data
+}
+
+// Score judges a link from whether it is connected and when it last received
+// a keepalive reply and a data packet, returning the reason when not OK
+// A half-dead link that answers keepalives but carries no data is degraded
+// once the policy's data timeout passes. Zero times are not scored.
+func Score(connected bool, keepalive, data time.Time, policy HealthPolicy, now time.Time) (Health, string) {
+	if !connected {
+		return HealthDown, "not connected"
+	}
+
+	if policy.KeepaliveInterval > 0 && !keepalive.IsZero() {
+		silent := now.Sub(keepalive)
+		if silent > healthDownIntervals*policy.KeepaliveInterval {
+			return HealthDown, fmt.Sprintf("no keepalive reply for %v", silent.Round(time.Second))
+		}
+		if silent > healthDegradedIntervals*policy.KeepaliveInterval {
+			return HealthDegraded, fmt.Sprintf("no keepalive reply for %v", silent.Round(time.Second))
+		}
+	}
+
+	if policy.DataTimeout > 0 && !data.IsZero() {
+		if silent := now.Sub(data); silent > policy.DataTimeout {
+			return HealthDegraded, fmt.Sprintf("no data for %v", silent.Round(time.Second))
+		}
+	}
+
+	return HealthOK, ""
+}
+
+// Health returns the health of the DMR and YSF links when last scored
+func (s *State) Health() (dmr, ysf Health) {
+	return Health(s.dmrHealth.Load()), Health(s.ysfHealth.Load())
+}
+
+// SetDMRHealth records the DMR link's health, reporting whether it changed
+func (s *State) SetDMRHealth(h Health) (changed bool) {
+	return Health(s.dmrHealth.Swap(int32(h))) != h
+}
+
+// SetYSFHealth records the YSF link's health, reporting whether it changed
+func (s *State) SetYSFHealth(h Health) (changed bool) {
+	return Health(s.ysfHealth.Swap(int32(h))) != h
+}
@@ -0,0 +1,53 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScore(t *testing.T) {
+	now := time.Now()
+	policy := HealthPolicy{KeepaliveInterval: 10 * time.Second, DataTimeout: 10 * time.Minute}
+
+	for _, tt := range []struct {
+		name      string
+		connected bool
+		keepalive time.Time
+		data      time.Time
+		policy    HealthPolicy
+		want      Health
+	}{
+		{"healthy", true, now.Add(-5 * time.Second), now.Add(-time.Minute), policy, HealthOK},
+		{"not connected", false, now, now, policy, HealthDown},
+		{"missed a reply", true, now.Add(-25 * time.Second), now, policy, HealthDegraded},
+		{"replies stopped", true, now.Add(-31 * time.Second), now, policy, HealthDown},
+		{"no data", true, now, now.Add(-11 * time.Minute), policy, HealthDegraded},
+		{"data not scored", true, now, now.Add(-11 * time.Minute), HealthPolicy{KeepaliveInterval: 10 * time.Second}, HealthOK},
+		{"no keepalive", true, time.Time{}, now, policy, HealthOK},
+	} {
+		health, reason := Score(tt.connected, tt.keepalive, tt.data, tt.policy, now)
+		if health != tt.want {
+			t.Errorf("%s: Score() = %v (%s), want %v", tt.name, health, reason, tt.want)
+		}
+		if (health == HealthOK) != (reason == "") {
+			t.Errorf("%s: Score() = %v with reason %q", tt.name, health, reason)
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	s := New(91, time.Now())
+	if dmr, ysf := s.Health(); dmr != HealthDown || ysf != HealthDown {
+		t.Fatalf("new state health = %v, %v", dmr, ysf)
+	}
+
+	if !s.SetDMRHealth(HealthOK) || s.SetDMRHealth(HealthOK) {
+		t.Error("SetDMRHealth() did not report the change once")
+	}
+	if !s.SetYSFHealth(HealthDegraded) {
+		t.Error("SetYSFHealth() did not report a change")
+	}
+	if dmr, ysf := s.Health(); dmr != HealthOK || ysf != HealthDegraded {
+		t.Errorf("Health() = %v, %v", dmr, ysf)
+	}
+}
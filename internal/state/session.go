@@ -0,0 +1,68 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Session is the destination chosen by the users, kept in a state file so it
+// survives restarts instead of reverting to StartupDstId
+type Session struct {
+	DstID   uint32    `json:"dst_id"`
+	Private bool      `json:"private"`
+	Saved   time.Time `json:"saved"`
+
+	// WiresX entry shown for a private call to a user found by a search
+	WiresXUser *SessionUser `json:"wiresx_user,omitempty"`
+}
+
+// SessionUser is the WiresX search entry of a private call destination
+type SessionUser struct {
+	SelectID string `json:"select_id"`
+	Name     string `json:"name"`
+	Desc     string `json:"desc"`
+}
+
+// LoadSession reads the session from a state file; a missing file is
+// reported with an error satisfying os.IsNotExist
+func LoadSession(path string) (Session, error) {
+	var session Session
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return session, err
+	}
+	if err := json.Unmarshal(data, &session); err != nil {
+		return session, fmt.Errorf("invalid state file %s: %v", path, err)
+	}
+	return session, nil
+}
+
+// SaveSession writes the session to a state file through a temporary file,
+// so a crash while saving keeps the previous session
+func SaveSession(path string, session Session) error {
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,38 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSession_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if _, err := LoadSession(path); !os.IsNotExist(err) {
+		t.Fatalf("LoadSession() of a missing file error = %v", err)
+	}
+
+	saved := Session{
+		DstID:      3100001,
+		Private:    true,
+		Saved:      time.Date(2026, 10, 16, 14, 3, 7, 0, time.UTC),
+		WiresXUser: &SessionUser{SelectID: "0090001", Name: "N0CALL          ", Desc: "JOHN          "},
+	}
+	if err := SaveSession(path, saved); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	loaded, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if loaded.DstID != saved.DstID || !loaded.Private || !loaded.Saved.Equal(saved.Saved) ||
+		loaded.WiresXUser == nil || *loaded.WiresXUser != *saved.WiresXUser {
+		t.Errorf("loaded %+v, want %+v", loaded, saved)
+	}
+
+	os.WriteFile(path, []byte("{"), 0644)
+	if _, err := LoadSession(path); err == nil || os.IsNotExist(err) {
+		t.Errorf("LoadSession() of a corrupt file error = %v", err)
+	}
+}
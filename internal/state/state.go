@@ -0,0 +1,213 @@
+// Package state holds the gateway's call and link state behind accessors that
+// are safe for concurrent use
+// The main loop changes the state; timers, hooks and HTTP handlers read it
+// from their own goroutines.
+package state
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CallState is the direction of the call being bridged, if any
+type CallState int32
+
+const (
+	CallIdle CallState = iota
+	CallYSF            // Receiving YSF, transmitting DMR
+	CallDMR            // Receiving DMR, transmitting YSF
+)
+
+func (c CallState) String() string {
+	switch c {
+	case CallIdle:
+		return "idle"
+	case CallYSF:
+		return "YSF->DMR"
+	case CallDMR:
+		return "DMR->YSF"
+	default:
+		return "unknown"
+	}
+}
+
+// Call is a snapshot of the current call
+type Call struct {
+	State   CallState
+	SrcID   uint32 // DMR caller of a DMR->YSF call
+	DstID   uint32 // destination of YSF->DMR calls, kept between calls
+	Private bool   // DstID is a DMR user selected via WiresX search
+	Stream  uint32 // DMR stream of a DMR->YSF call
+
+	Emergency bool // the call carries the emergency flag
+}
+
+// State is the call and link state of a gateway
+type State struct {
+	mu   sync.RWMutex
+	call Call
+
+	dmrLinkUp        bool
+	dmrLastConnected time.Time
+
+	// Link health when last scored, as Health
+	dmrHealth atomic.Int32
+	ysfHealth atomic.Int32
+
+	ysfErrors atomic.Int64
+	dmrErrors atomic.Int64
+
+	paused atomic.Bool // no new calls are bridged
+}
+
+// New creates an idle state sending YSF->DMR calls to the talkgroup dstID
+// The DMR link counts as last connected at now, and both links as down until
+// scored.
+func New(dstID uint32, now time.Time) *State {
+	s := &State{
+		call:             Call{DstID: dstID},
+		dmrLastConnected: now,
+	}
+	s.dmrHealth.Store(int32(HealthDown))
+	s.ysfHealth.Store(int32(HealthDown))
+	return s
+}
+
+// Call returns a copy of the current call
+func (s *State) Call() Call {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.call
+}
+
+// CallState returns the direction of the current call
+func (s *State) CallState() CallState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.call.State
+}
+
+// Destination returns the DMR destination of YSF->DMR calls and whether it
+// is a private call to a DMR user
+func (s *State) Destination() (id uint32, private bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.call.DstID, s.call.Private
+}
+
+// SetDestination changes the DMR destination of YSF->DMR calls
+func (s *State) SetDestination(id uint32, private bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.call.DstID, s.call.Private = id, private
+}
+
+// StartYSFCall marks a YSF->DMR call to the current destination as started
+func (s *State) StartYSFCall() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.call.State = CallYSF
+	s.call.Emergency = false
+}
+
+// StartDMRCall marks a DMR->YSF call from srcID on streamID as started
+func (s *State) StartDMRCall(srcID, streamID uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.call.State = CallDMR
+	s.call.SrcID = srcID
+	s.call.Stream = streamID
+	s.call.Emergency = false
+}
+
+// RaiseEmergency flags the current call as an emergency, reporting whether
+// it was not flagged yet
+func (s *State) RaiseEmergency() (raised bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	raised = !s.call.Emergency
+	s.call.Emergency = true
+	return raised
+}
+
+// EndCall returns to idle, returning the call that ended
+// The destination is kept for the next YSF->DMR call.
+func (s *State) EndCall() Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ended := s.call
+	s.call.State = CallIdle
+	return ended
+}
+
+// Paused reports whether bridging is paused
+func (s *State) Paused() bool {
+	return s.paused.Load()
+}
+
+// SetPaused pauses or resumes bridging, reporting whether it changed
+// Calls in progress are not affected; the links stay up either way.
+func (s *State) SetPaused(paused bool) (changed bool) {
+	return s.paused.Swap(paused) != paused
+}
+
+// DMRLink returns whether the DMR network was connected when last checked
+// and when it was last seen connected
+func (s *State) DMRLink() (up bool, lastConnected time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dmrLinkUp, s.dmrLastConnected
+}
+
+// SetDMRLink records the DMR network connection status at now, reporting
+// whether it changed
+func (s *State) SetDMRLink(up bool, now time.Time) (changed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed = up != s.dmrLinkUp
+	s.dmrLinkUp = up
+	if up {
+		s.dmrLastConnected = now
+	}
+	return changed
+}
+
+// SetDMRConnected records a successful DMR reconnection at now
+// The link is reported up by the next SetDMRLink once the login completes.
+func (s *State) SetDMRConnected(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dmrLastConnected = now
+}
+
+// AddYSFError counts a YSF network error, returning the new count
+func (s *State) AddYSFError() int64 {
+	return s.ysfErrors.Add(1)
+}
+
+// AddDMRError counts a DMR network error, returning the new count
+func (s *State) AddDMRError() int64 {
+	return s.dmrErrors.Add(1)
+}
+
+// NetworkErrors returns the YSF and DMR network errors counted since the
+// last reset
+func (s *State) NetworkErrors() (ysf, dmr int64) {
+	return s.ysfErrors.Load(), s.dmrErrors.Load()
+}
+
+// ResetYSFErrors clears the YSF network error count
+func (s *State) ResetYSFErrors() {
+	s.ysfErrors.Store(0)
+}
+
+// ResetDMRErrors clears the DMR network error count
+func (s *State) ResetDMRErrors() {
+	s.dmrErrors.Store(0)
+}
+
+// ResetNetworkErrors clears both error counts, returning the counts cleared
+func (s *State) ResetNetworkErrors() (ysf, dmr int64) {
+	return s.ysfErrors.Swap(0), s.dmrErrors.Swap(0)
+}
@@ -0,0 +1,170 @@
+package state
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCallLifecycle(t *testing.T) {
+	s := New(91, time.Now())
+	if c := s.Call(); c.State != CallIdle || c.DstID != 91 || c.Private {
+		t.Fatalf("new state call = %+v", c)
+	}
+
+	s.SetDestination(3100001, true)
+	s.StartYSFCall()
+	if id, private := s.Destination(); s.CallState() != CallYSF || id != 3100001 || !private {
+		t.Errorf("YSF call: state %v, destination %d private %v", s.CallState(), id, private)
+	}
+	if ended := s.EndCall(); ended.State != CallYSF {
+		t.Errorf("EndCall() = %+v, want the YSF call", ended)
+	}
+
+	s.StartDMRCall(2345678, 0xCAFE)
+	if c := s.Call(); c.State != CallDMR || c.SrcID != 2345678 || c.Stream != 0xCAFE {
+		t.Errorf("DMR call = %+v", c)
+	}
+	s.EndCall()
+
+	// The destination outlives the calls
+	if c := s.Call(); c.State != CallIdle || c.DstID != 3100001 || !c.Private {
+		t.Errorf("after calls = %+v", c)
+	}
+}
+
+func TestRaiseEmergency(t *testing.T) {
+	s := New(91, time.Now())
+	s.StartDMRCall(2345678, 0xCAFE)
+	if !s.RaiseEmergency() {
+		t.Error("first RaiseEmergency() = false")
+	}
+	if s.RaiseEmergency() {
+		t.Error("second RaiseEmergency() in the same call = true")
+	}
+	if !s.Call().Emergency {
+		t.Error("call not flagged as an emergency")
+	}
+
+	// A new call starts without the flag
+	s.EndCall()
+	s.StartYSFCall()
+	if s.Call().Emergency || !s.RaiseEmergency() {
+		t.Error("emergency flag carried into the next call")
+	}
+}
+
+func TestDMRLink(t *testing.T) {
+	start := time.Now()
+	s := New(91, start)
+
+	if up, last := s.DMRLink(); up || !last.Equal(start) {
+		t.Fatalf("new link = %v, %v", up, last)
+	}
+	if !s.SetDMRLink(true, start.Add(time.Second)) {
+		t.Error("SetDMRLink(true) did not report a change")
+	}
+	if s.SetDMRLink(true, start.Add(2*time.Second)) {
+		t.Error("SetDMRLink(true) again reported a change")
+	}
+	if !s.SetDMRLink(false, start.Add(3*time.Second)) {
+		t.Error("SetDMRLink(false) did not report a change")
+	}
+
+	// Last connected is when the link was last seen up
+	if up, last := s.DMRLink(); up || !last.Equal(start.Add(2*time.Second)) {
+		t.Errorf("link = %v, %v", up, last)
+	}
+}
+
+func TestPaused(t *testing.T) {
+	s := New(91, time.Now())
+	if s.Paused() {
+		t.Fatal("new state is paused")
+	}
+	if !s.SetPaused(true) || s.SetPaused(true) || !s.Paused() {
+		t.Error("SetPaused(true) did not pause once")
+	}
+	if !s.SetPaused(false) || s.Paused() {
+		t.Error("SetPaused(false) did not resume")
+	}
+}
+
+func TestNetworkErrors(t *testing.T) {
+	s := New(91, time.Now())
+	s.AddYSFError()
+	s.AddDMRError()
+	if n := s.AddDMRError(); n != 2 {
+		t.Errorf("AddDMRError() = %d, want 2", n)
+	}
+
+	s.ResetDMRErrors()
+	if ysf, dmr := s.NetworkErrors(); ysf != 1 || dmr != 0 {
+		t.Errorf("after ResetDMRErrors() = %d, %d", ysf, dmr)
+	}
+	s.ResetYSFErrors()
+	if ysf, _ := s.NetworkErrors(); ysf != 0 {
+		t.Errorf("after ResetYSFErrors() YSF errors = %d", ysf)
+	}
+	s.AddYSFError()
+	if ysf, dmr := s.ResetNetworkErrors(); ysf != 1 || dmr != 0 {
+		t.Errorf("ResetNetworkErrors() = %d, %d", ysf, dmr)
+	}
+	if ysf, dmr := s.NetworkErrors(); ysf != 0 || dmr != 0 {
+		t.Errorf("after ResetNetworkErrors() = %d, %d", ysf, dmr)
+	}
+}
+
+// The main loop changes calls while readers on other goroutines take
+// snapshots; run with -race
+func TestConcurrentAccess(t *testing.T) {
+	s := New(91, time.Now())
+	const rounds = 1000
+
+	var wg sync.WaitGroup
+	wg.Add(4)
+	go func() { // main loop
+		defer wg.Done()
+		for i := uint32(1); i <= rounds; i++ {
+			if i%2 == 0 {
+				s.SetDestination(i, false)
+				s.StartYSFCall()
+			} else {
+				s.StartDMRCall(i, i)
+			}
+			s.EndCall()
+		}
+	}()
+	go func() { // API handler
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			// A DMR call's source and stream are always set together
+			if c := s.Call(); c.State == CallDMR && c.SrcID != c.Stream {
+				t.Errorf("torn DMR call snapshot %+v", c)
+				return
+			}
+		}
+	}()
+	go func() { // timers counting errors
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			s.AddYSFError()
+			s.AddDMRError()
+		}
+	}()
+	go func() { // health monitor
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			s.SetDMRLink(i%2 == 0, time.Now())
+			s.DMRLink()
+		}
+	}()
+	wg.Wait()
+
+	if ysf, dmr := s.NetworkErrors(); ysf != rounds || dmr != rounds {
+		t.Errorf("NetworkErrors() = %d, %d, want %d each", ysf, dmr, rounds)
+	}
+	if c := s.Call(); c.State != CallIdle || c.DstID != rounds {
+		t.Errorf("final call = %+v", c)
+	}
+}
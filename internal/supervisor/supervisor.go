@@ -0,0 +1,114 @@
+// Package supervisor keeps long-running goroutines alive. A panic in a
+// supervised task is recovered, logged with its stack trace and counted, and
+// the task is restarted after a backoff delay.
+package supervisor
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default restart delays
+const (
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// Task is a supervised function; it should return when ctx is done
+type Task func(ctx context.Context)
+
+// Supervisor runs a group of tasks, such as the goroutines of one network client
+type Supervisor struct {
+	name       string
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	panics atomic.Uint64
+	wg     sync.WaitGroup
+}
+
+// New creates a supervisor; name prefixes its log messages
+func New(name string) *Supervisor {
+	return &Supervisor{
+		name:       name,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+}
+
+// SetBackoff sets the delay before the first restart and the limit it doubles up to
+func (s *Supervisor) SetBackoff(minDelay, maxDelay time.Duration) {
+	if maxDelay < minDelay {
+		maxDelay = minDelay
+	}
+	s.minBackoff = minDelay
+	s.maxBackoff = maxDelay
+}
+
+// Go starts a task in a new goroutine
+// The task is restarted whenever it panics, until ctx is done. A task that
+// returns normally is not restarted.
+func (s *Supervisor) Go(ctx context.Context, name string, task Task) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx, name, task)
+	}()
+}
+
+// Panics returns the number of panics recovered from all tasks
+func (s *Supervisor) Panics() uint64 {
+	return s.panics.Load()
+}
+
+// Wait blocks until every task has stopped
+func (s *Supervisor) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Supervisor) run(ctx context.Context, name string, task Task) {
+	backoff := s.minBackoff
+	for {
+		started := time.Now()
+		if !s.call(ctx, name, task) {
+			return
+		}
+
+		// A task that ran for a while before failing starts again quickly
+		if time.Since(started) > s.maxBackoff {
+			backoff = s.minBackoff
+		}
+
+		log.Printf("%s: restarting %s in %v", s.name, name, backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > s.maxBackoff {
+			backoff = s.maxBackoff
+		}
+	}
+}
+
+// call runs the task once and reports whether it panicked
+func (s *Supervisor) call(ctx context.Context, name string, task Task) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.panics.Add(1)
+			log.Printf("%s: %s panic: %v\n%s", s.name, name, r, debug.Stack())
+			panicked = true
+		}
+	}()
+
+	task(ctx)
+	return false
+}
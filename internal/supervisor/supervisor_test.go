@@ -0,0 +1,107 @@
+package supervisor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSupervisor_RestartsAfterPanic(t *testing.T) {
+	s := New("test")
+	s.SetBackoff(time.Millisecond, 4*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+	done := make(chan struct{})
+	s.Go(ctx, "worker", func(ctx context.Context) {
+		if runs.Add(1) <= 3 {
+			panic("boom")
+		}
+		close(done)
+	})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("task not restarted, %d runs", runs.Load())
+	}
+	s.Wait()
+
+	if runs.Load() != 4 {
+		t.Errorf("runs = %d, want 4", runs.Load())
+	}
+	if s.Panics() != 3 {
+		t.Errorf("Panics() = %d, want 3", s.Panics())
+	}
+}
+
+func TestSupervisor_NormalReturnIsNotRestarted(t *testing.T) {
+	s := New("test")
+
+	var runs atomic.Int32
+	s.Go(context.Background(), "worker", func(ctx context.Context) {
+		runs.Add(1)
+	})
+	s.Wait()
+
+	if runs.Load() != 1 || s.Panics() != 0 {
+		t.Errorf("runs = %d, panics = %d; want 1 and 0", runs.Load(), s.Panics())
+	}
+}
+
+func TestSupervisor_StopsDuringBackoff(t *testing.T) {
+	s := New("test")
+	s.SetBackoff(time.Hour, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Go(ctx, "worker", func(ctx context.Context) {
+		panic("boom")
+	})
+
+	// Wait for the first panic, then cancel while the restart is pending
+	for s.Panics() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Wait() did not return after cancel")
+	}
+}
+
+func TestSupervisor_BackoffDoubles(t *testing.T) {
+	s := New("test")
+	s.SetBackoff(10*time.Millisecond, 40*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var starts []time.Time
+	done := make(chan struct{})
+	s.Go(ctx, "worker", func(ctx context.Context) {
+		starts = append(starts, time.Now())
+		if len(starts) <= 4 {
+			panic("boom")
+		}
+		close(done)
+	})
+	<-done
+
+	// Delays of 10, 20, 40 and 40ms (capped)
+	want := []time.Duration{10, 20, 40, 40}
+	for i, w := range want {
+		if gap := starts[i+1].Sub(starts[i]); gap < w*time.Millisecond {
+			t.Errorf("restart %d after %v, want at least %v", i+1, gap, w*time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package testutil
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+// Master is a fake Homebrew DMR master on a localhost UDP port
+// It runs the login (RPTL, RPTK, RPTC, RPTO), answers pings and records the
+// DMRD packets it receives. With echo on it sends each DMRD back as a parrot
+// would, under a new stream ID so the gateway's loop guard lets it through.
+// It serves one repeater at a time and is safe for concurrent use.
+type Master struct {
+	password string
+	conn     *net.UDPConn
+	done     chan struct{}
+
+	mu       sync.Mutex
+	peer     *net.UDPAddr
+	id       [4]byte
+	salt     [protocol.DMR_SALT_LENGTH]byte
+	authed   bool
+	loggedIn bool
+	options  string
+	pings    int
+	received [][]byte
+	echo     bool
+}
+
+// NewMaster starts a master accepting password on a free localhost port
+func NewMaster(password string) (*Master, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return nil, fmt.Errorf("fake master: %v", err)
+	}
+
+	m := &Master{password: password, conn: conn, done: make(chan struct{})}
+	go m.serve()
+	return m, nil
+}
+
+// Address returns the master's IP address as a string
+func (m *Master) Address() string {
+	return m.conn.LocalAddr().(*net.UDPAddr).IP.String()
+}
+
+// Port returns the master's UDP port
+func (m *Master) Port() int {
+	return m.conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// Close stops the master
+func (m *Master) Close() {
+	m.conn.Close()
+	<-m.done
+}
+
+// SetEcho turns the parrot echo of DMRD packets on or off
+func (m *Master) SetEcho(echo bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.echo = echo
+}
+
+// LoggedIn reports whether a repeater has completed the login
+func (m *Master) LoggedIn() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.loggedIn
+}
+
+// Options returns the options sent in RPTO, if any
+func (m *Master) Options() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.options
+}
+
+// Pings returns how many pings have been answered
+func (m *Master) Pings() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pings
+}
+
+// Received returns the DMRD packets received so far
+func (m *Master) Received() [][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([][]byte(nil), m.received...)
+}
+
+// Send sends a packet to the logged in repeater, such as a DMRD from another
+// station or an MSTCL
+func (m *Master) Send(packet []byte) error {
+	m.mu.Lock()
+	peer := m.peer
+	m.mu.Unlock()
+	if peer == nil {
+		return fmt.Errorf("fake master: no repeater has logged in")
+	}
+	_, err := m.conn.WriteToUDP(packet, peer)
+	return err
+}
+
+func (m *Master) serve() {
+	defer close(m.done)
+	buffer := make([]byte, 1500)
+	for {
+		n, from, err := m.conn.ReadFromUDP(buffer)
+		if err != nil {
+			return
+		}
+		if reply := m.handle(buffer[:n], from); reply != nil {
+			m.conn.WriteToUDP(reply, from)
+		}
+	}
+}
+
+// handle processes a packet from a repeater, returning the reply if any
+func (m *Master) handle(packet []byte, from *net.UDPAddr) []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// RPTCL must be matched before RPTC, which it starts with
+	switch {
+	case bytes.HasPrefix(packet, []byte(protocol.NETWORK_MAGIC_LOGIN)) && len(packet) >= 8:
+		m.peer = from
+		copy(m.id[:], packet[4:8])
+		m.authed, m.loggedIn = false, false
+		rand.Read(m.salt[:])
+		return append([]byte(protocol.NETWORK_MAGIC_ACK), m.salt[:]...)
+
+	case bytes.HasPrefix(packet, []byte(protocol.NETWORK_MAGIC_AUTH)) && len(packet) >= 40:
+		if !m.from(from) {
+			return m.nak(packet[4:8])
+		}
+		hash := sha256.Sum256(append(m.salt[:], m.password...))
+		if !bytes.Equal(packet[8:40], hash[:]) {
+			return m.nak(m.id[:])
+		}
+		m.authed = true
+		return m.ack()
+
+	case bytes.HasPrefix(packet, []byte(protocol.NETWORK_MAGIC_CLOSE)):
+		if m.from(from) {
+			m.loggedIn = false
+			m.peer = nil
+		}
+		return nil
+
+	case bytes.HasPrefix(packet, []byte(protocol.NETWORK_MAGIC_CONFIG)):
+		if !m.from(from) || !m.authed {
+			return m.nak(m.id[:])
+		}
+		m.loggedIn = true
+		return m.ack()
+
+	case bytes.HasPrefix(packet, []byte(protocol.NETWORK_MAGIC_OPTIONS)):
+		if !m.from(from) || !m.loggedIn {
+			return m.nak(m.id[:])
+		}
+		m.options = string(bytes.TrimRight(packet[8:], "\x00"))
+		return m.ack()
+
+	case bytes.HasPrefix(packet, []byte(protocol.NETWORK_MAGIC_PING)):
+		if !m.from(from) || !m.loggedIn {
+			return m.nak(m.id[:])
+		}
+		m.pings++
+		return append([]byte(protocol.NETWORK_MAGIC_PONG), m.id[:]...)
+
+	case bytes.HasPrefix(packet, []byte(protocol.NETWORK_MAGIC_DATA)):
+		if !m.from(from) || !m.loggedIn || len(packet) != protocol.HOMEBREW_DATA_PACKET_LENGTH {
+			return nil
+		}
+		m.received = append(m.received, append([]byte(nil), packet...))
+		if !m.echo {
+			return nil
+		}
+		echo := append([]byte(nil), packet...)
+		binary.BigEndian.PutUint32(echo[16:20], ^binary.BigEndian.Uint32(packet[16:20]))
+		return echo
+	}
+	return nil
+}
+
+// from reports whether a packet came from the repeater that sent RPTL
+func (m *Master) from(addr *net.UDPAddr) bool {
+	return m.peer != nil && m.peer.IP.Equal(addr.IP) && m.peer.Port == addr.Port
+}
+
+func (m *Master) ack() []byte {
+	return append([]byte(protocol.NETWORK_MAGIC_ACK), m.id[:]...)
+}
+
+func (m *Master) nak(id []byte) []byte {
+	return append([]byte(protocol.NETWORK_MAGIC_NAK), id...)
+}
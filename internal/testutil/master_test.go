@@ -0,0 +1,148 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/network"
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+// clockUntil clocks n in 10ms steps until done returns true or a second of
+// real time passes
+func clockUntil(t *testing.T, n *network.DMRNetwork, done func() bool) bool {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		n.Clock(10)
+		if done() {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestNetwork(t *testing.T, master *Master, password string) *network.DMRNetwork {
+	t.Helper()
+	n, err := network.NewDMRNetwork(master.Address(), master.Port(), 0, 1234567, password,
+		false, "test", false, true, true, protocol.HW_TYPE_HOMEBREW, 0)
+	if err != nil {
+		t.Fatalf("NewDMRNetwork() error = %v", err)
+	}
+	if err := n.Open(); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(n.Close)
+
+	n.Clock(protocol.DMR_RETRY_TIMEOUT) // the first login attempt waits for the retry timer
+	return n
+}
+
+func TestMasterLogin(t *testing.T) {
+	master, err := NewMaster("passw0rd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer master.Close()
+
+	n := newTestNetwork(t, master, "passw0rd")
+	if !clockUntil(t, n, n.IsConnected) {
+		t.Fatalf("not logged in, status %s", n.GetStatusString())
+	}
+	if !master.LoggedIn() {
+		t.Error("master does not see the login")
+	}
+}
+
+func TestMasterWrongPassword(t *testing.T) {
+	master, err := NewMaster("passw0rd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer master.Close()
+
+	n := newTestNetwork(t, master, "wrong")
+	if clockUntil(t, n, n.IsConnected) {
+		t.Fatal("logged in with the wrong password")
+	}
+	if master.LoggedIn() {
+		t.Error("master accepted the wrong password")
+	}
+}
+
+func TestMasterPingAndEcho(t *testing.T) {
+	master, err := NewMaster("passw0rd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer master.Close()
+	master.SetEcho(true)
+
+	n := newTestNetwork(t, master, "passw0rd")
+	if !clockUntil(t, n, n.IsConnected) {
+		t.Fatalf("not logged in, status %s", n.GetStatusString())
+	}
+	n.Enable(true)
+
+	n.Clock(protocol.DMR_RETRY_TIMEOUT) // pings go out on the retry timer once running
+	if !clockUntil(t, n, func() bool { return master.Pings() > 0 }) {
+		t.Error("no ping answered")
+	}
+
+	frame := protocol.NewDMRData()
+	frame.SetSlotNo(2)
+	frame.SetSrcId(3100001)
+	frame.SetDstId(91)
+	frame.SetFLCO(protocol.FLCO_GROUP)
+	frame.SetDataType(protocol.DT_VOICE_LC_HEADER)
+	if err := n.Write(frame); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !clockUntil(t, n, func() bool { return len(master.Received()) == 1 }) {
+		t.Fatal("master received no DMRD")
+	}
+
+	var echoed protocol.DMRData
+	if !clockUntil(t, n, func() bool { return n.Read(&echoed) }) {
+		t.Fatal("echo not read back")
+	}
+	if echoed.GetSrcId() != 3100001 || echoed.GetDstId() != 91 || echoed.GetSlotNo() != 2 {
+		t.Errorf("echo = %s", echoed.String())
+	}
+}
+
+func TestInMemoryNetworks(t *testing.T) {
+	ysf := NewYSFNetwork()
+	ysf.Open()
+	ysf.Inject([]byte("YSFP"))
+	select {
+	case <-ysf.Ready():
+	default:
+		t.Error("Ready not signalled for an injected packet")
+	}
+	buffer := make([]byte, 10)
+	if n := ysf.Read(buffer); string(buffer[:n]) != "YSFP" {
+		t.Errorf("Read() = %q", buffer[:n])
+	}
+	ysf.Write([]byte("YSFD"))
+	if sent := ysf.Sent(); len(sent) != 1 || string(sent[0]) != "YSFD" {
+		t.Errorf("Sent() = %q", sent)
+	}
+
+	dmr := NewDMRNetwork()
+	dmr.Open()
+	frame := protocol.NewDMRData()
+	frame.SetSrcId(1234)
+	dmr.Inject(frame)
+	var data protocol.DMRData
+	if dmr.Read(&data) {
+		t.Error("disabled network delivered a frame")
+	}
+	dmr.Enable(true)
+	if !dmr.Read(&data) || data.GetSrcId() != 1234 {
+		t.Errorf("Read() = %s", data.String())
+	}
+	dmr.SetConnected(false)
+	if dmr.IsConnected() {
+		t.Error("connected after SetConnected(false)")
+	}
+}
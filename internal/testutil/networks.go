@@ -0,0 +1,250 @@
+// Package testutil provides in-memory networks and a fake DMR master so the
+// gateway can be exercised end to end without real sockets or servers
+package testutil
+
+import (
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/dbehnke/ysf2dmr/internal/network"
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+var (
+	_ network.YSFNetworkInterface = (*YSFNetwork)(nil)
+	_ network.DMRNetworkInterface = (*DMRNetwork)(nil)
+)
+
+// YSFNetwork is an in-memory YSF network
+// Packets given to Inject are read by the gateway; packets the gateway writes
+// are returned by Sent. It is safe for concurrent use.
+type YSFNetwork struct {
+	mu          sync.Mutex
+	open        bool
+	incoming    [][]byte
+	sent        [][]byte
+	polls       int
+	destination string
+	ready       chan struct{}
+}
+
+// NewYSFNetwork creates a closed in-memory YSF network
+func NewYSFNetwork() *YSFNetwork {
+	return &YSFNetwork{ready: make(chan struct{}, 1)}
+}
+
+// Open opens the network
+func (n *YSFNetwork) Open() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.open = true
+	return nil
+}
+
+// Close closes the network
+func (n *YSFNetwork) Close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.open = false
+}
+
+// IsOpen reports whether the network is open
+func (n *YSFNetwork) IsOpen() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.open
+}
+
+// Read returns the next injected packet, 0 if there is none
+func (n *YSFNetwork) Read(data []byte) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.incoming) == 0 {
+		return 0
+	}
+	packet := n.incoming[0]
+	n.incoming = n.incoming[1:]
+	return copy(data, packet)
+}
+
+// Write records a packet sent by the gateway
+func (n *YSFNetwork) Write(data []byte) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, append([]byte(nil), data...))
+	return nil
+}
+
+// WritePoll counts a poll sent by the gateway
+func (n *YSFNetwork) WritePoll() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.polls++
+	return nil
+}
+
+// Clock does nothing; injected packets are readable at once
+func (n *YSFNetwork) Clock(ms int) {}
+
+// Ready is signalled when packets are injected
+func (n *YSFNetwork) Ready() <-chan struct{} {
+	return n.ready
+}
+
+// SetDestinationByString records the destination
+func (n *YSFNetwork) SetDestinationByString(address string, port int) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.destination = net.JoinHostPort(address, strconv.Itoa(port))
+	return nil
+}
+
+// Destination returns the destination last set, as host:port
+func (n *YSFNetwork) Destination() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.destination
+}
+
+// Inject queues a packet for the gateway to read
+func (n *YSFNetwork) Inject(packet []byte) {
+	n.mu.Lock()
+	n.incoming = append(n.incoming, append([]byte(nil), packet...))
+	n.mu.Unlock()
+
+	select {
+	case n.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Sent returns the packets written by the gateway so far
+func (n *YSFNetwork) Sent() [][]byte {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([][]byte(nil), n.sent...)
+}
+
+// Polls returns how many polls the gateway has sent
+func (n *YSFNetwork) Polls() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.polls
+}
+
+// DMRNetwork is an in-memory DMR network
+// It reports itself connected once opened unless SetConnected says otherwise.
+// Frames given to Inject are read by the gateway; frames the gateway writes
+// are returned by Written. It is safe for concurrent use.
+type DMRNetwork struct {
+	mu        sync.Mutex
+	open      bool
+	connected bool
+	enabled   bool
+	beacon    bool
+	incoming  []protocol.DMRData
+	written   []protocol.DMRData
+}
+
+// NewDMRNetwork creates a closed in-memory DMR network
+func NewDMRNetwork() *DMRNetwork {
+	return &DMRNetwork{connected: true}
+}
+
+// Open opens the network
+func (n *DMRNetwork) Open() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.open = true
+	return nil
+}
+
+// Close closes the network
+func (n *DMRNetwork) Close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.open = false
+}
+
+// Enable enables or disables delivery of injected frames
+func (n *DMRNetwork) Enable(enabled bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.enabled = enabled
+}
+
+// Read returns the next injected frame if the network is enabled
+func (n *DMRNetwork) Read(data *protocol.DMRData) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.enabled || len(n.incoming) == 0 {
+		return false
+	}
+	*data = n.incoming[0]
+	n.incoming = n.incoming[1:]
+	return true
+}
+
+// Write records a frame sent by the gateway
+func (n *DMRNetwork) Write(data *protocol.DMRData) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.written = append(n.written, *data)
+	return nil
+}
+
+// Clock does nothing; there is no login to run
+func (n *DMRNetwork) Clock(ms int) {}
+
+// IsConnected reports whether the network is open and connected
+func (n *DMRNetwork) IsConnected() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.open && n.connected
+}
+
+// GetStatusString describes the connection
+func (n *DMRNetwork) GetStatusString() string {
+	if n.IsConnected() {
+		return "Running"
+	}
+	return "Waiting Connect"
+}
+
+// WantsBeacon reports and clears a beacon request set by RequestBeacon
+func (n *DMRNetwork) WantsBeacon() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	beacon := n.beacon
+	n.beacon = false
+	return beacon
+}
+
+// SetConnected simulates the link to the master going up or down
+func (n *DMRNetwork) SetConnected(connected bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.connected = connected
+}
+
+// RequestBeacon simulates the master asking for a beacon
+func (n *DMRNetwork) RequestBeacon() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.beacon = true
+}
+
+// Inject queues a frame for the gateway to read
+func (n *DMRNetwork) Inject(data *protocol.DMRData) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.incoming = append(n.incoming, *data)
+}
+
+// Written returns the frames written by the gateway so far
+func (n *DMRNetwork) Written() []protocol.DMRData {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]protocol.DMRData(nil), n.written...)
+}
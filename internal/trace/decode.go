@@ -0,0 +1,132 @@
+package trace
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+)
+
+// Describe returns a one-line summary of a packet on network: "YSF" for the
+// YSF network, "DMR" for a Homebrew master and "OBP" for OpenBridge
+func Describe(network string, packet []byte) string {
+	switch network {
+	case "YSF":
+		return describeYSF(packet)
+	case "DMR", "OBP":
+		return describeDMR(packet)
+	}
+	return "?"
+}
+
+// describeYSF decodes a YSF network packet: YSFD frames are "YSFD", the
+// gateway, source and destination callsigns, a counter byte and the frame
+func describeYSF(packet []byte) string {
+	tag := printableTag(packet, 4)
+	switch tag {
+	case "YSFD":
+		if len(packet) != ysf.YSF_FRAME_LENGTH {
+			return "YSFD invalid length"
+		}
+		summary := fmt.Sprintf("YSFD gw=%s src=%s dst=%s seq=%d", callsign(packet[4:14]),
+			callsign(packet[14:24]), callsign(packet[24:34]), packet[34]>>1)
+		if packet[34]&0x01 != 0 {
+			summary += " end"
+		}
+		var fich ysf.FICH
+		if err := fich.Decode(packet[40:65]); err != nil {
+			return summary + " FICH invalid"
+		}
+		return fmt.Sprintf("%s FI=%d DT=%d CM=%d FN=%d FT=%d BN=%d BT=%d",
+			summary, fich.FI, fich.DT, fich.CM, fich.FN, fich.FT, fich.BN, fich.BT)
+
+	case "YSFP", "YSFU", "YSFI", "YSFA", "YSFK":
+		if len(packet) >= 14 {
+			return fmt.Sprintf("%s %s", tag, callsign(packet[4:14]))
+		}
+	}
+	return tag
+}
+
+// dmrTags are the Homebrew packet tags, longest first so RPTCL is not taken
+// for RPTC
+var dmrTags = []string{
+	"RPTSBKN", "RPTPING", "MSTPONG", "RPTACK", "MSTNAK", "MSTCL", "RPTCL",
+	"DMRD", "DMRA", "DMRG", "RPTL", "RPTK", "RPTC", "RPTO",
+}
+
+// describeDMR decodes a Homebrew or OpenBridge packet
+// DMRD carries the sequence, source, destination, repeater, a flags byte
+// (slot, call type, frame type, data type or voice sequence) and the stream.
+func describeDMR(packet []byte) string {
+	tag := ""
+	for _, candidate := range dmrTags {
+		if strings.HasPrefix(string(packet), candidate) {
+			tag = candidate
+			break
+		}
+	}
+
+	switch tag {
+	case "":
+		return printableTag(packet, 4)
+	case "DMRD":
+		if len(packet) < 20 {
+			return "DMRD invalid length"
+		}
+		flags := packet[15]
+		slot, call := 1, "group"
+		if flags&0x80 != 0 {
+			slot = 2
+		}
+		if flags&0x40 != 0 {
+			call = "private"
+		}
+		var frame string
+		switch (flags >> 4) & 0x03 {
+		case 0:
+			frame = fmt.Sprintf("voice %c", 'A'+flags&0x0F)
+		case 1:
+			frame = "voice sync"
+		case 2:
+			frame = fmt.Sprintf("data DT=%d", flags&0x0F)
+		default:
+			frame = "unknown"
+		}
+		return fmt.Sprintf("DMRD seq=%d src=%d dst=%d rptr=%d TS%d %s %s stream=%08X",
+			packet[4], uint24(packet[5:8]), uint24(packet[8:11]), binary.BigEndian.Uint32(packet[11:15]),
+			slot, call, frame, binary.BigEndian.Uint32(packet[16:20]))
+	case "RPTL", "RPTK", "RPTC", "RPTO", "RPTCL", "RPTPING", "MSTPONG", "MSTCL", "RPTSBKN", "MSTNAK":
+		if len(packet) >= len(tag)+4 {
+			return fmt.Sprintf("%s id=%d", tag, binary.BigEndian.Uint32(packet[len(tag):len(tag)+4]))
+		}
+	}
+	return tag
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+}
+
+// callsign returns a space padded callsign field without the padding
+func callsign(field []byte) string {
+	return strings.TrimRight(strings.TrimRight(string(field), " "), "\x00")
+}
+
+// printableTag returns the first n bytes of a packet, or "?" when they are
+// not printable
+func printableTag(packet []byte, n int) string {
+	if len(packet) < n {
+		n = len(packet)
+	}
+	for _, c := range packet[:n] {
+		if c < 0x20 || c > 0x7E {
+			return "?"
+		}
+	}
+	if n == 0 {
+		return "?"
+	}
+	return string(packet[:n])
+}
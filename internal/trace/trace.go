@@ -0,0 +1,176 @@
+// Package trace writes every network packet to a rotating trace file, each
+// with a timestamp, a decoded one-line summary and a hex dump
+// It replaces ad hoc debug dumps: a trace covers all packet types in both
+// directions and stays out of the main log.
+package trace
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FormatVersion is written in the header of each trace file and changes
+// whenever the line format does, so tools reading traces can tell them apart
+const FormatVersion = 1
+
+const (
+	// DefaultMaxSize is the size at which the trace file is rotated
+	DefaultMaxSize = 10 << 20
+
+	// DefaultKeep is how many rotated files are kept (path.1 is the newest)
+	DefaultKeep = 3
+
+	timeFormat = "2006-01-02 15:04:05.000000"
+)
+
+// Direction of a packet relative to the gateway
+type Direction int
+
+const (
+	Received Direction = iota
+	Sent
+)
+
+func (d Direction) String() string {
+	if d == Sent {
+		return ">"
+	}
+	return "<"
+}
+
+// Tracer writes packets to a trace file
+// A nil *Tracer ignores packets, so networks can always call Record. It is
+// safe for concurrent use.
+type Tracer struct {
+	path    string
+	maxSize int64
+	keep    int
+	version string
+
+	mu   sync.Mutex
+	file *os.File
+	out  *bufio.Writer
+	size int64
+}
+
+// Open creates or appends to the trace file at path, rotating it once it
+// reaches maxSize bytes and keeping keep rotated files
+// version, the gateway version, is recorded in the file header.
+func Open(path string, maxSize int64, keep int, version string) (*Tracer, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if keep < 0 {
+		keep = DefaultKeep
+	}
+
+	t := &Tracer{path: path, maxSize: maxSize, keep: keep, version: version}
+	if err := t.open(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Path returns the trace file path
+func (t *Tracer) Path() string {
+	return t.path
+}
+
+// Record writes a packet exchanged with peer on network ("YSF", "DMR" or
+// "OBP", see Describe)
+func (t *Tracer) Record(network string, dir Direction, peer net.Addr, packet []byte) {
+	if t == nil {
+		return
+	}
+
+	var entry strings.Builder
+	peerName := "-"
+	if peer != nil {
+		peerName = peer.String()
+	}
+	fmt.Fprintf(&entry, "%s %s %s %s %s (%d bytes)\n", time.Now().Format(timeFormat), network, dir, peerName,
+		Describe(network, packet), len(packet))
+	for _, line := range strings.SplitAfter(hex.Dump(packet), "\n") {
+		if line != "" {
+			entry.WriteString("    ")
+			entry.WriteString(line)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.file == nil {
+		return
+	}
+	if t.size+int64(entry.Len()) > t.maxSize {
+		if err := t.rotate(); err != nil {
+			return
+		}
+	}
+	n, _ := t.out.WriteString(entry.String())
+	t.size += int64(n)
+	t.out.Flush()
+}
+
+// Close closes the trace file
+func (t *Tracer) Close() error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.close()
+}
+
+// open opens the trace file, writing the header when it is new
+// Callers must hold t.mu, or own t.
+func (t *Tracer) open() error {
+	file, err := os.OpenFile(t.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	t.file, t.out, t.size = file, bufio.NewWriter(file), info.Size()
+	header := fmt.Sprintf("# ysf2dmr packet trace format %d, gateway %s, opened %s\n",
+		FormatVersion, t.version, time.Now().Format(timeFormat))
+	n, _ := t.out.WriteString(header)
+	t.size += int64(n)
+	return t.out.Flush()
+}
+
+// rotate moves path to path.1, path.1 to path.2 and so on, dropping the
+// oldest, and opens a new file
+// Callers must hold t.mu.
+func (t *Tracer) rotate() error {
+	t.close()
+	if t.keep == 0 {
+		os.Remove(t.path)
+	} else {
+		for i := t.keep - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", t.path, i), fmt.Sprintf("%s.%d", t.path, i+1))
+		}
+		os.Rename(t.path, t.path+".1")
+	}
+	return t.open()
+}
+
+func (t *Tracer) close() error {
+	if t.file == nil {
+		return nil
+	}
+	t.out.Flush()
+	err := t.file.Close()
+	t.file, t.out = nil, nil
+	return err
+}
@@ -0,0 +1,116 @@
+package trace
+
+import (
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+)
+
+func TestDescribe_YSF(t *testing.T) {
+	packet := make([]byte, ysf.YSF_FRAME_LENGTH)
+	copy(packet, "YSFDGATEWAY   N0CALL    ALL       ")
+	packet[34] = 5<<1 | 1
+	fich := ysf.FICH{FI: 2, DT: 2, CM: 0, FN: 3, FT: 6}
+	copy(packet[40:], fich.Encode())
+
+	want := "YSFD gw=GATEWAY src=N0CALL dst=ALL seq=5 end FI=2 DT=2 CM=0 FN=3 FT=6 BN=0 BT=0"
+	if got := Describe("YSF", packet); got != want {
+		t.Errorf("Describe(YSFD) = %q, want %q", got, want)
+	}
+
+	if got := Describe("YSF", []byte("YSFPN0CALL    ")); got != "YSFP N0CALL" {
+		t.Errorf("Describe(YSFP) = %q", got)
+	}
+	if got := Describe("YSF", []byte{0x01, 0x02}); got != "?" {
+		t.Errorf("Describe(binary) = %q, want ?", got)
+	}
+}
+
+func TestDescribe_DMR(t *testing.T) {
+	packet := make([]byte, 53)
+	copy(packet, "DMRD")
+	packet[4] = 7
+	packet[5], packet[6], packet[7] = 0x2F, 0x9B, 0xE4 // 3120100
+	packet[10] = 91
+	binary.BigEndian.PutUint32(packet[11:15], 311805201)
+	packet[15] = 0x80 | 0x10 // slot 2, group, voice sync
+	binary.BigEndian.PutUint32(packet[16:20], 0xCAFE0001)
+
+	want := "DMRD seq=7 src=3120100 dst=91 rptr=311805201 TS2 group voice sync stream=CAFE0001"
+	if got := Describe("DMR", packet); got != want {
+		t.Errorf("Describe(DMRD) = %q, want %q", got, want)
+	}
+
+	login := append([]byte("RPTL"), 0x12, 0x95, 0x8A, 0x91)
+	if got := Describe("DMR", login); got != "RPTL id=311790225" {
+		t.Errorf("Describe(RPTL) = %q", got)
+	}
+	if got := Describe("OBP", []byte("RPTCL\x12\x95\x8A\x91")); got != "RPTCL id=311790225" {
+		t.Errorf("Describe(RPTCL) = %q", got)
+	}
+}
+
+func TestTracer_Record(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.log")
+	tracer, err := Open(path, 0, 0, "test")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	peer := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 42000}
+	tracer.Record("YSF", Sent, peer, []byte("YSFPN0CALL    "))
+	tracer.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	if !strings.HasPrefix(lines[0], "# ysf2dmr packet trace format 1, gateway test") {
+		t.Errorf("header = %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], " YSF > 192.0.2.1:42000 YSFP N0CALL (14 bytes)") {
+		t.Errorf("summary = %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "    00000000  59 53 46 50") {
+		t.Errorf("hex dump = %q", lines[2])
+	}
+}
+
+func TestTracer_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.log")
+	tracer, err := Open(path, 400, 2, "test")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer tracer.Close()
+
+	for i := 0; i < 10; i++ {
+		tracer.Record("DMR", Received, nil, []byte("RPTPING\x12\x95\x8A\x91"))
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("%s missing: %v", filepath.Base(name), err)
+		}
+		if info.Size() > 400 {
+			t.Errorf("%s is %d bytes, over the limit", filepath.Base(name), info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Error("more rotated files kept than configured")
+	}
+}
+
+func TestTracer_Nil(t *testing.T) {
+	var tracer *Tracer
+	tracer.Record("YSF", Received, nil, []byte("YSFP"))
+	if err := tracer.Close(); err != nil {
+		t.Errorf("Close() on nil = %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+// Package version identifies the running build of the gateway.
+//
+// Release builds set the version, commit and build date with the linker:
+//
+//	go build -ldflags "-X github.com/dbehnke/ysf2dmr/internal/version.Version=1.1.0 \
+//	  -X github.com/dbehnke/ysf2dmr/internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/dbehnke/ysf2dmr/internal/version.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/ysf2dmr
+//
+// Builds without them fall back to the VCS details the Go toolchain embeds
+// when building from a git checkout.
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Set with -ldflags "-X"; see the package documentation
+var (
+	Version = "1.0.0-go"
+	Commit  = ""
+	Date    = "" // RFC 3339
+)
+
+// shortCommit is the length commits are shown with
+const shortCommit = 7
+
+// Info describes a build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // built from a checkout with uncommitted changes
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the details of the running build
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date, GoVersion: runtime.Version()}
+	if info.Commit != "" {
+		return info
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// String formats the build for logs and the DMR master, e.g.
+// "1.0.0-go (3f2c1ab, 2026-10-16)"; with a version of up to 12 characters
+// it fits the 40 byte version field of the Homebrew RPTC packet
+func (i Info) String() string {
+	var details []string
+	if i.Commit != "" {
+		commit := i.Commit
+		if len(commit) > shortCommit {
+			commit = commit[:shortCommit]
+		}
+		if i.Modified {
+			commit += "-dirty"
+		}
+		details = append(details, commit)
+	}
+	if i.Date != "" {
+		details = append(details, strings.SplitN(i.Date, "T", 2)[0])
+	}
+	if len(details) == 0 {
+		return i.Version
+	}
+	return fmt.Sprintf("%s (%s)", i.Version, strings.Join(details, ", "))
+}
@@ -0,0 +1,36 @@
+package version
+
+import "testing"
+
+func TestInfo_String(t *testing.T) {
+	tests := []struct {
+		info Info
+		want string
+	}{
+		{Info{Version: "1.1.0"}, "1.1.0"},
+		{Info{Version: "1.1.0", Commit: "3f2c1ab9d0e4", Date: "2026-10-16T12:00:00Z"}, "1.1.0 (3f2c1ab, 2026-10-16)"},
+		{Info{Version: "1.1.0", Commit: "3f2c1ab", Modified: true}, "1.1.0 (3f2c1ab-dirty)"},
+		{Info{Version: "1.1.0", Date: "2026-10-16"}, "1.1.0 (2026-10-16)"},
+	}
+	for _, tt := range tests {
+		if got := tt.info.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.info, got, tt.want)
+		}
+	}
+
+	long := Info{Version: "1.10.0-beta2", Commit: "3f2c1ab9d0e4", Modified: true, Date: "2026-10-16T12:00:00Z"}
+	if got := long.String(); len(got) > 40 {
+		t.Errorf("String() = %q is longer than the 40 byte RPTC version field", got)
+	}
+}
+
+func TestGet(t *testing.T) {
+	saved := Commit
+	defer func() { Commit = saved }()
+
+	Commit = "3f2c1ab"
+	info := Get()
+	if info.Version != Version || info.Commit != "3f2c1ab" || info.GoVersion == "" {
+		t.Errorf("Get() = %+v, want the linker-set commit", info)
+	}
+}
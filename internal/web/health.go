@@ -0,0 +1,170 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLivenessTimeout is how long the main loop may go without progress
+// before /healthz reports the gateway dead
+const DefaultLivenessTimeout = 10 * time.Second
+
+// HealthCheck reports the status of one gateway component
+// status is encoded as JSON in the /health response.
+type HealthCheck func() (status interface{}, healthy bool)
+
+type healthResult struct {
+	Healthy bool        `json:"healthy"`
+	Status  interface{} `json:"status,omitempty"`
+}
+
+type healthResponse struct {
+	Status string                  `json:"status"` // "ok" or "degraded"; "ready", "starting" or "degraded" for /readyz
+	Checks map[string]healthResult `json:"checks"`
+}
+
+type livenessResponse struct {
+	Status       string    `json:"status"` // "alive" or "dead"
+	LastProgress time.Time `json:"last_progress"`
+	SinceMs      int64     `json:"since_ms"`
+}
+
+// Heartbeat records the progress of the gateway main loop for /healthz
+// It is safe for concurrent use.
+type Heartbeat struct {
+	last atomic.Int64 // Unix nanoseconds
+}
+
+// Beat records progress at now
+func (h *Heartbeat) Beat(now time.Time) {
+	h.last.Store(now.UnixNano())
+}
+
+// Last returns the time of the last Beat, or the zero time
+func (h *Heartbeat) Last() time.Time {
+	if last := h.last.Load(); last != 0 {
+		return time.Unix(0, last)
+	}
+	return time.Time{}
+}
+
+// AddHealthCheck registers a component check reported by /health
+func (s *Server) AddHealthCheck(name string, check HealthCheck) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.healthChecks[name] = check
+}
+
+// AddReadinessCheck registers a check that must pass for /readyz to report
+// the gateway ready
+func (s *Server) AddReadinessCheck(name string, check HealthCheck) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readyChecks[name] = check
+}
+
+// SetLiveness makes /healthz report the gateway dead once heartbeat has not
+// beaten for timeout (DefaultLivenessTimeout when 0)
+// Without a heartbeat, /healthz only shows the process is serving.
+func (s *Server) SetLiveness(heartbeat *Heartbeat, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultLivenessTimeout
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heartbeat, s.livenessTimeout = heartbeat, timeout
+}
+
+// handleHealth reports every registered check; any unhealthy check returns 503
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	checks := s.copyChecks(s.healthChecks)
+	s.mu.Unlock()
+
+	response := healthResponse{Status: "ok"}
+	var healthy bool
+	response.Checks, healthy = runChecks(checks)
+	if !healthy {
+		response.Status = "degraded"
+	}
+	writeHealth(w, response, healthy)
+}
+
+// handleLiveness reports whether the main loop is still making progress
+func (s *Server) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	heartbeat, timeout := s.heartbeat, s.livenessTimeout
+	s.mu.Unlock()
+
+	response := livenessResponse{Status: "alive"}
+	if heartbeat != nil {
+		response.LastProgress = heartbeat.Last()
+		since := time.Since(response.LastProgress)
+		response.SinceMs = since.Milliseconds()
+		if since > timeout {
+			response.Status = "dead"
+		}
+	}
+	writeHealth(w, response, response.Status == "alive")
+}
+
+// handleReadiness reports whether every readiness check passes: "ready",
+// "starting" until they first all pass, then "degraded" whenever one fails
+func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	checks := s.copyChecks(s.readyChecks)
+	s.mu.Unlock()
+
+	response := healthResponse{Status: "ready"}
+	var ready bool
+	response.Checks, ready = runChecks(checks)
+	switch {
+	case ready:
+		s.wasReady.Store(true)
+	case s.wasReady.Load():
+		response.Status = "degraded"
+	default:
+		response.Status = "starting"
+	}
+	writeHealth(w, response, ready)
+}
+
+// copyChecks copies checks so they run without holding s.mu
+// Callers must hold s.mu.
+func (s *Server) copyChecks(checks map[string]HealthCheck) map[string]HealthCheck {
+	copied := make(map[string]HealthCheck, len(checks))
+	for name, check := range checks {
+		copied[name] = check
+	}
+	return copied
+}
+
+// runChecks runs checks in name order, reporting whether all passed
+func runChecks(checks map[string]HealthCheck) (map[string]healthResult, bool) {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make(map[string]healthResult, len(checks))
+	allHealthy := true
+	for _, name := range names {
+		status, healthy := checks[name]()
+		results[name] = healthResult{Healthy: healthy, Status: status}
+		allHealthy = allHealthy && healthy
+	}
+	return results, allHealthy
+}
+
+// writeHealth writes a health response, with 503 when not healthy
+func writeHealth(w http.ResponseWriter, response interface{}, healthy bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if !healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+)
+
+// RuntimeStats is the /api/runtime response, used to watch a long running
+// gateway for goroutine and memory leaks
+type RuntimeStats struct {
+	Goroutines  int    `json:"goroutines"`
+	HeapAlloc   uint64 `json:"heap_alloc"`   // bytes of live and not yet collected heap objects
+	HeapInuse   uint64 `json:"heap_inuse"`   // bytes in in-use heap spans
+	HeapObjects uint64 `json:"heap_objects"` // allocated heap objects
+	Sys         uint64 `json:"sys"`          // bytes obtained from the OS
+	NumGC       uint32 `json:"num_gc"`
+}
+
+// handleRuntime reports the goroutine count and memory statistics
+func (s *Server) handleRuntime(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RuntimeStats{
+		Goroutines:  runtime.NumGoroutine(),
+		HeapAlloc:   mem.HeapAlloc,
+		HeapInuse:   mem.HeapInuse,
+		HeapObjects: mem.HeapObjects,
+		Sys:         mem.Sys,
+		NumGC:       mem.NumGC,
+	})
+}
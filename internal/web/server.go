@@ -0,0 +1,179 @@
+// Package web provides the gateway's HTTP server and live event stream.
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/events"
+)
+
+// Per client queue of pending messages; slow clients are disconnected
+// rather than delaying the gateway.
+const clientQueueLength = 64
+
+// Server is the gateway HTTP server
+type Server struct {
+	addr       string
+	mux        *http.ServeMux
+	httpServer *http.Server
+	listener   net.Listener
+
+	mu              sync.Mutex
+	clients         map[*wsClient]struct{}
+	healthChecks    map[string]HealthCheck
+	readyChecks     map[string]HealthCheck
+	heartbeat       *Heartbeat
+	livenessTimeout time.Duration
+
+	wasReady atomic.Bool // /readyz has passed once, so failures are "degraded"
+}
+
+type wsClient struct {
+	conn  *wsConn
+	queue chan []byte
+}
+
+// NewServer creates an HTTP server listening on addr
+// Events published on bus are pushed to connected /ws clients.
+func NewServer(addr string, bus *events.Bus) *Server {
+	s := &Server{
+		addr:         addr,
+		mux:          http.NewServeMux(),
+		clients:      make(map[*wsClient]struct{}),
+		healthChecks: make(map[string]HealthCheck),
+		readyChecks:  make(map[string]HealthCheck),
+	}
+	s.mux.HandleFunc("/ws", s.handleWebSocket)
+	s.mux.HandleFunc("/health", s.handleHealth)
+	s.mux.HandleFunc("/healthz", s.handleLiveness)
+	s.mux.HandleFunc("/readyz", s.handleReadiness)
+	s.mux.HandleFunc("/api/runtime", s.handleRuntime)
+	s.httpServer = &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if bus != nil {
+		bus.Subscribe(s.Broadcast)
+	}
+	return s
+}
+
+// Handle registers an additional handler on the server
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Start starts listening and serving in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", s.addr, err)
+	}
+	s.listener = listener
+
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("HTTP server error: %v", err)
+		}
+	}()
+
+	log.Printf("HTTP server listening on %s", listener.Addr())
+	return nil
+}
+
+// Addr returns the listening address, or the configured address before Start
+func (s *Server) Addr() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.addr
+}
+
+// Shutdown stops the server and disconnects all WebSocket clients
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.httpServer.Shutdown(ctx)
+
+	s.mu.Lock()
+	for client := range s.clients {
+		client.conn.Close()
+	}
+	s.mu.Unlock()
+
+	return err
+}
+
+// Broadcast sends an event to all WebSocket clients
+func (s *Server) Broadcast(event events.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("WebSocket: failed to encode %s event: %v", event.Type, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for client := range s.clients {
+		select {
+		case client.queue <- data:
+		default:
+			log.Printf("WebSocket: client %s too slow, disconnecting", client.conn.conn.RemoteAddr())
+			client.conn.Close()
+		}
+	}
+}
+
+// ClientCount returns the number of connected WebSocket clients
+func (s *Server) ClientCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.clients)
+}
+
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		log.Printf("WebSocket: %v", err)
+		return
+	}
+
+	client := &wsClient{conn: conn, queue: make(chan []byte, clientQueueLength)}
+	s.mu.Lock()
+	s.clients[client] = struct{}{}
+	s.mu.Unlock()
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case data := <-client.queue:
+				if err := conn.WriteText(data); err != nil {
+					conn.Close()
+					return
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	conn.readLoop() // Returns when the client goes away
+	conn.Close()
+	close(stop)
+
+	s.mu.Lock()
+	delete(s.clients, client)
+	s.mu.Unlock()
+	<-done
+}
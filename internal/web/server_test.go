@@ -0,0 +1,245 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/events"
+)
+
+func TestWebsocketAccept(t *testing.T) {
+	// Example from RFC 6455 section 1.3
+	if got := websocketAccept("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("websocketAccept() = %q", got)
+	}
+}
+
+func dialWebSocket(t *testing.T, addr string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	fmt.Fprintf(conn, "GET /ws HTTP/1.1\r\nHost: %s\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n", addr)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("handshake error: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake status = %d, want 101", resp.StatusCode)
+	}
+	return conn, reader
+}
+
+func readTextFrame(t *testing.T, reader *bufio.Reader) []byte {
+	t.Helper()
+
+	var header [2]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		t.Fatalf("frame read error: %v", err)
+	}
+	if header[0] != 0x81 {
+		t.Fatalf("frame header = 0x%02X, want final text frame", header[0])
+	}
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		io.ReadFull(reader, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		t.Fatalf("payload read error: %v", err)
+	}
+	return payload
+}
+
+func TestServer_WebSocketEvents(t *testing.T) {
+	bus := events.NewBus()
+	server := NewServer("127.0.0.1:0", bus)
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	conn, reader := dialWebSocket(t, server.Addr())
+	defer conn.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for server.ClientCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	bus.Publish(events.Event{
+		Type:   events.CallStart,
+		Source: "YSF",
+		Fields: map[string]string{"callsign": "W1AW"},
+	})
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var event events.Event
+	if err := json.Unmarshal(readTextFrame(t, reader), &event); err != nil {
+		t.Fatalf("event decode error: %v", err)
+	}
+	if event.Type != events.CallStart || event.Fields["callsign"] != "W1AW" {
+		t.Errorf("received %+v", event)
+	}
+}
+
+func TestServer_RejectsPlainRequest(t *testing.T) {
+	server := NewServer("127.0.0.1:0", nil)
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	resp, err := http.Get("http://" + server.Addr() + "/ws")
+	if err != nil {
+		t.Fatalf("GET error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+	if !strings.Contains(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Errorf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+}
+
+func TestServer_Health(t *testing.T) {
+	server := NewServer("127.0.0.1:0", nil)
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	healthy := true
+	server.AddHealthCheck("radioid", func() (interface{}, bool) {
+		return map[string]int{"consecutive_failures": 0}, healthy
+	})
+
+	get := func() (int, map[string]interface{}) {
+		resp, err := http.Get("http://" + server.Addr() + "/health")
+		if err != nil {
+			t.Fatalf("GET error: %v", err)
+		}
+		defer resp.Body.Close()
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+
+	if code, body := get(); code != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("healthy response = %d %v", code, body)
+	}
+
+	healthy = false
+	if code, body := get(); code != http.StatusServiceUnavailable || body["status"] != "degraded" {
+		t.Errorf("unhealthy response = %d %v", code, body)
+	}
+}
+
+func TestServer_Liveness(t *testing.T) {
+	server := NewServer("127.0.0.1:0", nil)
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	var heartbeat Heartbeat
+	server.SetLiveness(&heartbeat, time.Minute)
+
+	get := func() (int, string) {
+		resp, err := http.Get("http://" + server.Addr() + "/healthz")
+		if err != nil {
+			t.Fatalf("GET error: %v", err)
+		}
+		defer resp.Body.Close()
+		var body livenessResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body.Status
+	}
+
+	heartbeat.Beat(time.Now())
+	if code, status := get(); code != http.StatusOK || status != "alive" {
+		t.Errorf("progressing loop = %d %q", code, status)
+	}
+
+	heartbeat.Beat(time.Now().Add(-2 * time.Minute))
+	if code, status := get(); code != http.StatusServiceUnavailable || status != "dead" {
+		t.Errorf("stalled loop = %d %q", code, status)
+	}
+}
+
+func TestServer_Readiness(t *testing.T) {
+	server := NewServer("127.0.0.1:0", nil)
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	authenticated := false
+	server.AddReadinessCheck("dmr", func() (interface{}, bool) { return nil, authenticated })
+	server.AddReadinessCheck("ysf", func() (interface{}, bool) { return nil, true })
+
+	get := func() (int, string) {
+		resp, err := http.Get("http://" + server.Addr() + "/readyz")
+		if err != nil {
+			t.Fatalf("GET error: %v", err)
+		}
+		defer resp.Body.Close()
+		var body healthResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body.Status
+	}
+
+	for _, step := range []struct {
+		authenticated bool
+		code          int
+		status        string
+	}{
+		{false, http.StatusServiceUnavailable, "starting"},
+		{true, http.StatusOK, "ready"},
+		{false, http.StatusServiceUnavailable, "degraded"},
+	} {
+		authenticated = step.authenticated
+		if code, status := get(); code != step.code || status != step.status {
+			t.Errorf("authenticated=%v: %d %q, want %d %q", step.authenticated, code, status, step.code, step.status)
+		}
+	}
+}
+
+func TestServer_Runtime(t *testing.T) {
+	server := NewServer("127.0.0.1:0", nil)
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	resp, err := http.Get("http://" + server.Addr() + "/api/runtime")
+	if err != nil {
+		t.Fatalf("GET error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var stats RuntimeStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || stats.Goroutines == 0 || stats.HeapAlloc == 0 {
+		t.Errorf("runtime response = %d %+v", resp.StatusCode, stats)
+	}
+}
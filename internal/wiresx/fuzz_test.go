@@ -0,0 +1,61 @@
+package wiresx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newFuzzWiresX returns a handler with a talk group list and user search,
+// so every reply builder is reachable
+func newFuzzWiresX() *WiresX {
+	var tgList strings.Builder
+	for id := 1; id <= 25; id++ {
+		fmt.Fprintf(&tgList, "%d;0;TG %d;Talk group\n", id, id)
+	}
+
+	wx := NewWiresX("G4KLX", "", "", false)
+	wx.SetInfo("Test Node", 145800000, 145200000, 9)
+	wx.registry.LoadFromString(tgList.String())
+	wx.SetUserSearch("*", func(prefix string, limit int) []User {
+		return []User{{ID: 3120001, Callsign: "W1AW", Name: "Hiram"}}
+	})
+	return wx
+}
+
+// FuzzProcessCommand sends a framed and checksummed command, so the fuzzer
+// exercises the command handlers rather than the checksum
+func FuzzProcessCommand(f *testing.F) {
+	f.Add("G4KLX", []byte{0x5D, 0x71, 0x5F})
+	f.Add("G4KLX", []byte{0x5D, 0x23, 0x5F, '0', '0', '0', '0', '9', '1'})
+	f.Add("G4KLX", []byte{0x5D, 0x2A, 0x5F})
+	f.Add("G4KLX", []byte{0x5D, 0x66, 0x5F, '0', '0', '1', '0', '2', '1'})
+	f.Add("W1AW", append([]byte{0x5D, 0x66, 0x5F, '0', '1', '1', '0', '0', '1'}, []byte(fmt.Sprintf("%-16s", "TG"))...))
+	f.Add("W1AW", append([]byte{0x5D, 0x66, 0x5F, '0', '1', '1', '0', '0', '1'}, []byte(fmt.Sprintf("%-16s", "*W1"))...))
+	f.Add("G4KLX", []byte{0x5D, 0x67, 0x5F, '0', '0', '1', '0', '0', '1'})
+
+	f.Fuzz(func(t *testing.T, source string, payload []byte) {
+		wx := newFuzzWiresX()
+		processCommand(wx, source, payload)
+		wx.handleTimerExpiry()
+		wx.SendConnectReply(wx.GetDstID())
+		wx.SendDisconnectReply()
+	})
+}
+
+// FuzzProcessFrame sends single raw frames with any frame numbers
+func FuzzProcessFrame(f *testing.F) {
+	f.Add(uint8(1), uint8(1), []byte{0x01, 0x5D, 0x71, 0x5F, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x31})
+	f.Add(uint8(2), uint8(2), make([]byte, 40))
+	f.Add(uint8(7), uint8(7), make([]byte, 40))
+	f.Add(uint8(255), uint8(255), make([]byte, 90))
+
+	f.Fuzz(func(t *testing.T, fn, ft uint8, data []byte) {
+		wx := newFuzzWiresX()
+		wx.Process(data, []byte("G4KLX     "), 1, 1, fn, ft)
+		if wx.commandLength > COMMAND_MAX_LENGTH {
+			t.Fatalf("command length %d exceeds %d", wx.commandLength, COMMAND_MAX_LENGTH)
+		}
+		wx.handleTimerExpiry()
+	})
+}
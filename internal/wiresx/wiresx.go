@@ -3,7 +3,8 @@ package wiresx
 import (
 	"bufio"
 	"fmt"
-	"hash/fnv"
+	"log"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -29,6 +30,16 @@ var (
 	NET_HEADER   = []byte("YSFD                    ALL      ")
 )
 
+// Commands are spread over the data FR mode communications frames: 20 bytes
+// in FN 1 and 40 bytes in each later frame. FN and FT are 3-bit fields, which
+// bounds the size of a command.
+const (
+	COMMAND_FIRST_BLOCK_LENGTH = 20
+	COMMAND_BLOCK_LENGTH       = 40
+	COMMAND_MAX_FRAMES         = 7
+	COMMAND_MAX_LENGTH         = COMMAND_FIRST_BLOCK_LENGTH + (COMMAND_MAX_FRAMES-1)*COMMAND_BLOCK_LENGTH
+)
+
 // Status represents WiresX processing status
 type Status int
 
@@ -62,10 +73,45 @@ type TalkGroup struct {
 	Desc string // Description (14 chars, space-padded)
 }
 
+// User is a DMR user returned by a WiresX user search
+type User struct {
+	ID       uint32
+	Callsign string
+	Name     string
+}
+
+// UserSearchFunc finds up to limit DMR users whose callsign starts with prefix
+type UserSearchFunc func(prefix string, limit int) []User
+
+// WiresX user search limits
+// The radio only shows 5-digit IDs, so each result is given a selection ID
+// that stands in for the 7-digit DMR ID until the next user search.
+const (
+	userSearchLimit = 100
+	userSelectBase  = 90000
+	userSelectMax   = 99999
+)
+
+// userTarget maps a selection ID shown to the radio to a DMR user
+type userTarget struct {
+	entry TalkGroup
+	dmrID uint32
+}
+
 // TalkGroupRegistry manages talk group lists
 type TalkGroupRegistry struct {
 	talkGroups []TalkGroup
 	makeUpper  bool
+
+	// Indexes rebuilt after each load
+	byID   map[uint32]int // Numeric ID -> index into talkGroups
+	byName []nameIndex    // Sorted by upper case name for prefix search
+}
+
+// nameIndex is one entry of the registry's name index
+type nameIndex struct {
+	name  string // Upper case, trimmed
+	index int
 }
 
 // NewTalkGroupRegistry creates a new talk group registry
@@ -73,7 +119,17 @@ func NewTalkGroupRegistry(makeUpper bool) *TalkGroupRegistry {
 	return &TalkGroupRegistry{
 		talkGroups: make([]TalkGroup, 0),
 		makeUpper:  makeUpper,
+		byID:       make(map[uint32]int),
+	}
+}
+
+// LoadFromFile loads talk groups from a TG list file ("ID;Opt;Name;Desc" per line)
+func (r *TalkGroupRegistry) LoadFromFile(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read TG list %s: %w", filename, err)
 	}
+	return r.LoadFromString(string(data))
 }
 
 // LoadFromString loads talk groups from string data (used for testing)
@@ -130,19 +186,38 @@ func (r *TalkGroupRegistry) LoadFromString(data string) error {
 		r.talkGroups = append(r.talkGroups, tg)
 	}
 
+	r.buildIndex()
 	return scanner.Err()
 }
 
-// FindByID finds a talk group by numeric ID
-func (r *TalkGroupRegistry) FindByID(id uint32) *TalkGroup {
-	idStr := fmt.Sprintf("%07d", id)
+// buildIndex rebuilds the ID and name indexes from the talk group list
+// The first entry wins when an ID is listed more than once.
+func (r *TalkGroupRegistry) buildIndex() {
+	r.byID = make(map[uint32]int, len(r.talkGroups))
+	r.byName = make([]nameIndex, 0, len(r.talkGroups))
 
-	for i := range r.talkGroups {
-		if r.talkGroups[i].ID == idStr {
-			return &r.talkGroups[i]
+	for i, tg := range r.talkGroups {
+		if id, err := strconv.ParseUint(tg.ID, 10, 32); err == nil {
+			if _, exists := r.byID[uint32(id)]; !exists {
+				r.byID[uint32(id)] = i
+			}
 		}
+		r.byName = append(r.byName, nameIndex{
+			name:  strings.ToUpper(strings.TrimSpace(tg.Name)),
+			index: i,
+		})
 	}
 
+	sort.SliceStable(r.byName, func(i, j int) bool {
+		return r.byName[i].name < r.byName[j].name
+	})
+}
+
+// FindByID finds a talk group by numeric ID
+func (r *TalkGroupRegistry) FindByID(id uint32) *TalkGroup {
+	if i, exists := r.byID[id]; exists {
+		return &r.talkGroups[i]
+	}
 	return nil
 }
 
@@ -153,26 +228,25 @@ func (r *TalkGroupRegistry) Search(searchTerm string) []TalkGroup {
 		return nil
 	}
 
-	var results []TalkGroup
+	// Matches are contiguous in the sorted name index
+	first := sort.Search(len(r.byName), func(i int) bool {
+		return r.byName[i].name >= searchTerm
+	})
 
-	for _, tg := range r.talkGroups {
-		name := strings.ToUpper(strings.TrimSpace(tg.Name))
-		if strings.HasPrefix(name, searchTerm) {
-			results = append(results, tg)
+	var results []TalkGroup
+	for _, entry := range r.byName[first:] {
+		if !strings.HasPrefix(entry.name, searchTerm) {
+			break
 		}
+		results = append(results, r.talkGroups[entry.index])
 	}
 
-	// Sort results by name
-	sort.Slice(results, func(i, j int) bool {
-		return strings.TrimSpace(results[i].Name) < strings.TrimSpace(results[j].Name)
-	})
-
 	return results
 }
 
 // GetAll returns all talk groups with pagination
 func (r *TalkGroupRegistry) GetAll(start, count int) []TalkGroup {
-	if start >= len(r.talkGroups) {
+	if start < 0 || count <= 0 || start >= len(r.talkGroups) {
 		return nil
 	}
 
@@ -193,14 +267,19 @@ func (r *TalkGroupRegistry) GetCount() int {
 type WiresX struct {
 	callsign      string
 	node          string
+	baseNode      string // Callsign and suffix, before any module letter
+	nodeModule    string // Module letter, NODE_MODULE_XLX or empty
 	id            string
+	fixedID       uint32 // Configured repeater ID, 0 derives it from the callsign
 	name          string
 	txFrequency   uint32
 	rxFrequency   uint32
 	dstID         uint32
 	fullDstID     uint32
-	network       NetworkWriter
 	command       []byte
+	commandLength int   // Bytes of the command received so far
+	commandFN     uint8 // Next expected frame number, 0 when no command is in progress
+	commandFT     uint8 // Frame total of the command in progress
 	timer         *time.Timer
 	timerDuration time.Duration
 	seqNo         uint8
@@ -208,49 +287,100 @@ type WiresX struct {
 	csd1          []byte
 	csd2          []byte
 	csd3          []byte
-	status        InternalStatus
-	start         int
-	search        string
+	pending       []pendingReply // Replies sent when the timer expires
+	browsing      map[string]*browseState
+	browseExpiry  time.Duration
 	category      []TalkGroup
 	registry      *TalkGroupRegistry
-	bufferTX      [][]byte
-	lastTX        time.Time
+	bufferTX      [][]byte // Replies waiting for the gateway to send them
+
+	// DMR user search (searches starting with userSearchPrefix)
+	userSearchPrefix string
+	userSearch       UserSearchFunc
+	private          *userTarget // Connected user when dstID is a private call
+
+	// Text shown in DX and connect replies for talk groups
+	tgPrefix    string // Before the ID of a talk group, e.g. "TG 3126"
+	description string // For talk groups without a TG list description
+	localName   string // TG 9
+	parrotName  string // TG 9990
+	unlinkName  string // TG 4000
+
+	crcErrors   uint64 // Commands rejected for a missing end marker or bad checksum
+	frameErrors uint64 // Command frames rejected for an invalid or out of sequence FN/FT
 }
 
-// NetworkWriter interface for writing network data
-type NetworkWriter interface {
-	Write(data []byte) error
+// NODE_MODULE_XLX makes the node name follow the linked XLX module: TG 4001
+// is module A through TG 4026, module Z
+const NODE_MODULE_XLX = "XLX"
+
+// XLX module talk groups
+const (
+	xlxModuleFirstTG = 4001
+	xlxModuleLastTG  = 4026
+)
+
+// Defaults of the text shown for talk groups in DX and connect replies
+const (
+	DefaultTGPrefix    = "TG"
+	DefaultDescription = "Description"
+	DefaultLocalName   = "LOCAL"
+	DefaultParrotName  = "PARROT"
+	DefaultUnlinkName  = "UNLINK"
+)
+
+// browseStateExpiry is how long a station's ALL/SEARCH position is kept
+const browseStateExpiry = 5 * time.Minute
+
+// browseState is the ALL/SEARCH position of one requesting station
+// Keeping it per station stops radios browsing at the same time from
+// corrupting each other's pagination.
+type browseState struct {
+	start       int
+	search      string
+	userTargets map[uint32]userTarget // Selection ID -> user from this station's last user search
+	lastUsed    time.Time
+}
+
+// pendingReply is a reply waiting for the WiresX timer
+type pendingReply struct {
+	status InternalStatus
+	browse *browseState
 }
 
 // NewWiresX creates a new WiresX handler
-func NewWiresX(callsign, suffix string, network NetworkWriter, tgFile string, makeUpper bool) *WiresX {
+func NewWiresX(callsign, suffix string, tgFile string, makeUpper bool) *WiresX {
 	wx := &WiresX{
 		callsign:      callsign,
-		network:       network,
-		command:       make([]byte, 300),
+		command:       make([]byte, COMMAND_MAX_LENGTH),
 		timerDuration: time.Second,
 		header:        make([]byte, 34),
 		csd1:          make([]byte, 20),
 		csd2:          make([]byte, 20),
 		csd3:          make([]byte, 20),
-		status:        InternalStatusNone,
+		browsing:      make(map[string]*browseState),
+		browseExpiry:  browseStateExpiry,
 		registry:      NewTalkGroupRegistry(makeUpper),
 		bufferTX:      make([][]byte, 0),
-		lastTX:        time.Now(),
+		tgPrefix:      DefaultTGPrefix,
+		description:   DefaultDescription,
+		localName:     DefaultLocalName,
+		parrotName:    DefaultParrotName,
+		unlinkName:    DefaultUnlinkName,
 	}
 
-	// Build node name from callsign and suffix
-	wx.node = callsign
-	if len(suffix) > 0 {
-		wx.node += "-" + suffix
+	if tgFile != "" {
+		if err := wx.registry.LoadFromFile(tgFile); err != nil {
+			log.Printf("WiresX: %v", err)
+		}
 	}
 
-	// Pad to 10 characters
-	if len(wx.node) > 10 {
-		wx.node = wx.node[:10]
-	} else {
-		wx.node = wx.node + strings.Repeat(" ", 10-len(wx.node))
+	// Build node name from callsign and suffix
+	wx.baseNode = callsign
+	if len(suffix) > 0 {
+		wx.baseNode += "-" + suffix
 	}
+	wx.updateNode()
 
 	// Pad callsign to 10 characters
 	if len(wx.callsign) > 10 {
@@ -262,6 +392,17 @@ func NewWiresX(callsign, suffix string, network NetworkWriter, tgFile string, ma
 	return wx
 }
 
+// ReloadTalkGroups replaces the talk group list with the contents of filename
+// The current list is kept if the file cannot be read.
+func (wx *WiresX) ReloadTalkGroups(filename string) error {
+	registry := NewTalkGroupRegistry(wx.registry.makeUpper)
+	if err := registry.LoadFromFile(filename); err != nil {
+		return err
+	}
+	wx.registry = registry
+	return nil
+}
+
 // SetInfo sets the repeater information
 func (wx *WiresX) SetInfo(name string, txFrequency, rxFrequency uint32, dstID uint32) {
 	wx.name = name
@@ -276,11 +417,12 @@ func (wx *WiresX) SetInfo(name string, txFrequency, rxFrequency uint32, dstID ui
 		wx.name = name + strings.Repeat(" ", 14-len(name))
 	}
 
-	// Generate repeater ID using hash
-	hasher := fnv.New32a()
-	hasher.Write([]byte(name))
-	hash := hasher.Sum32()
-	wx.id = fmt.Sprintf("%05d", hash%100000)
+	// The repeater ID is shown in CSD3 and every reply
+	id := wx.fixedID
+	if id == 0 {
+		id = DeriveRepeaterID(wx.callsign)
+	}
+	wx.id = fmt.Sprintf("%05d", id)
 
 	// Initialize CSD fields
 	for i := range wx.csd1 {
@@ -294,7 +436,7 @@ func (wx *WiresX) SetInfo(name string, txFrequency, rxFrequency uint32, dstID ui
 	}
 
 	// Set node in CSD1
-	copy(wx.csd1[10:], wx.node[:10])
+	wx.updateNode()
 
 	// Set callsign in CSD2
 	copy(wx.csd2[0:], wx.callsign[:10])
@@ -309,6 +451,108 @@ func (wx *WiresX) SetInfo(name string, txFrequency, rxFrequency uint32, dstID ui
 	copy(wx.header[14:], wx.node[:10])
 }
 
+// SetNodeModule adds a module letter to the node name, e.g. "W8XYZ C", or
+// with NODE_MODULE_XLX the letter of the linked XLX module; empty removes it
+func (wx *WiresX) SetNodeModule(module string) {
+	wx.nodeModule = strings.ToUpper(strings.TrimSpace(module))
+	wx.updateNode()
+}
+
+// updateNode rebuilds the 10 character node name for the module letter of
+// the current destination, keeping the letter when the name is cut short
+func (wx *WiresX) updateNode() {
+	module := wx.nodeModule
+	if module == NODE_MODULE_XLX {
+		module = ""
+		if wx.dstID >= xlxModuleFirstTG && wx.dstID <= xlxModuleLastTG && wx.private == nil {
+			module = string(rune('A' + wx.dstID - xlxModuleFirstTG))
+		}
+	}
+
+	node := wx.baseNode
+	if module != "" {
+		if len(node) > 10-len(module)-1 {
+			node = node[:10-len(module)-1]
+		}
+		node += " " + module
+	}
+	wx.node = padRight(node, 10)
+
+	copy(wx.csd1[10:], wx.node[:10])
+	copy(wx.header[14:], wx.node[:10])
+}
+
+// Link returns the current destination and, for a private call to a user
+// found by a search, the search entry shown for it
+func (wx *WiresX) Link() (uint32, *TalkGroup) {
+	if wx.private != nil && wx.private.dmrID == wx.dstID {
+		entry := wx.private.entry
+		return wx.dstID, &entry
+	}
+	return wx.dstID, nil
+}
+
+// RestoreLink restores a destination returned by Link, e.g. after a restart
+func (wx *WiresX) RestoreLink(dstID uint32, user *TalkGroup) {
+	wx.dstID = dstID
+	wx.private = nil
+	if user != nil && len(user.ID) == 7 {
+		wx.private = &userTarget{entry: *user, dmrID: dstID}
+	}
+	wx.updateNode()
+}
+
+// SetRepeaterID sets the 5-digit repeater ID instead of deriving it from the
+// callsign; it takes effect at the next SetInfo
+func (wx *WiresX) SetRepeaterID(id uint32) {
+	wx.fixedID = id % 100000
+}
+
+// DeriveRepeaterID derives a repeater ID from a callsign with the
+// one-at-a-time hash the C++ YSF2DMR uses, so the ID stays the same when the
+// description changes
+func DeriveRepeaterID(callsign string) uint32 {
+	var hash uint32
+	for _, c := range []byte(strings.TrimSpace(callsign)) {
+		hash += uint32(c)
+		hash += hash << 10
+		hash ^= hash >> 6
+	}
+	hash += hash << 3
+	hash ^= hash >> 11
+	hash += hash << 15
+	return hash % 100000
+}
+
+// SetLabels sets the text shown for talk groups in DX and connect replies:
+// the prefix before a talk group ID and the description of talk groups the TG
+// list does not describe
+func (wx *WiresX) SetLabels(tgPrefix, description string) {
+	wx.tgPrefix = tgPrefix
+	wx.description = description
+}
+
+// SetNames sets the names shown for the local talk group (TG 9), the parrot
+// (TG 9990) and unlinking (TG 4000) when the TG list does not name them
+func (wx *WiresX) SetNames(local, parrot, unlink string) {
+	wx.localName = local
+	wx.parrotName = parrot
+	wx.unlinkName = unlink
+}
+
+// SetUserSearch enables DMR user searches: a search term starting with prefix
+// is looked up with search and the results can be selected for private calls
+func (wx *WiresX) SetUserSearch(prefix string, search UserSearchFunc) {
+	wx.userSearchPrefix = prefix
+	wx.userSearch = search
+}
+
+// IsPrivate returns true if the current destination is a DMR user selected
+// from a user search rather than a talk group
+func (wx *WiresX) IsPrivate() bool {
+	return wx.private != nil
+}
+
 // Process processes a WiresX command
 func (wx *WiresX) Process(data []byte, source []byte, fi, dt, fn, ft uint8) Status {
 	// Only process data FR mode communications frames
@@ -320,49 +564,23 @@ func (wx *WiresX) Process(data []byte, source []byte, fi, dt, fn, ft uint8) Stat
 		return StatusNone
 	}
 
-	// Extract command data (simplified - real implementation would use YSFPayload)
-	if fn == 1 {
-		// First frame contains up to 20 bytes
-		copyLen := 20
-		if len(data) < copyLen {
-			copyLen = len(data)
-		}
-		copy(wx.command[0:copyLen], data[:copyLen])
-	} else {
-		// Subsequent frames contain up to 40 bytes each
-		offset := int(fn-2)*40 + 20
-		copyLen := 40
-		if len(data) < copyLen {
-			copyLen = len(data)
-		}
-		if offset+copyLen <= len(wx.command) {
-			copy(wx.command[offset:offset+copyLen], data[:copyLen])
-		}
+	if !wx.addCommandFrame(data, fn, ft) {
+		wx.frameErrors++
+		return StatusNone
 	}
 
 	// Check if this is the final frame
 	if fn == ft {
-		// Find the end marker (0x03)
-		cmdLen := int(fn-1)*40 + 20
-		valid := false
-
-		for i := cmdLen; i > 0; i-- {
-			if i < len(wx.command) && wx.command[i] == 0x03 {
-				// Verify CRC (simplified - just check if CRC byte exists)
-				if i+1 < len(wx.command) {
-					// For now, accept any CRC value - real implementation would verify
-					valid = true
-				}
-				break
-			}
-		}
+		length := wx.commandLength
+		wx.commandFN = 0
 
-		if !valid {
+		if !wx.checkCommand(length) {
+			wx.crcErrors++
 			return StatusNone
 		}
 
 		// Process different command types
-		if len(wx.command) >= 4 {
+		if length >= 4 {
 			cmd := wx.command[1:4]
 
 			if bytesEqual(cmd, DX_REQ) {
@@ -388,6 +606,76 @@ func (wx *WiresX) Process(data []byte, source []byte, fi, dt, fn, ft uint8) Stat
 	return StatusNone
 }
 
+// addCommandFrame copies one frame into the command buffer
+// FN 1 starts a new command; later frames must follow in sequence with the
+// same FT. Anything else abandons the command in progress and is rejected.
+func (wx *WiresX) addCommandFrame(data []byte, fn, ft uint8) bool {
+	if ft > COMMAND_MAX_FRAMES || fn > ft {
+		wx.resetCommand()
+		return false
+	}
+
+	offset, length := 0, COMMAND_FIRST_BLOCK_LENGTH
+	if fn == 1 {
+		wx.resetCommand()
+	} else {
+		if fn != wx.commandFN || ft != wx.commandFT {
+			wx.resetCommand()
+			return false
+		}
+		offset = COMMAND_FIRST_BLOCK_LENGTH + int(fn-2)*COMMAND_BLOCK_LENGTH
+		length = COMMAND_BLOCK_LENGTH
+	}
+	if len(data) < length {
+		length = len(data)
+	}
+
+	copy(wx.command[offset:offset+length], data[:length])
+	wx.commandLength = offset + length
+	wx.commandFN = fn + 1
+	wx.commandFT = ft
+	return true
+}
+
+// resetCommand clears the command buffer so no part of a previous command,
+// in particular its end marker, can complete the next one
+func (wx *WiresX) resetCommand() {
+	for i := range wx.command {
+		wx.command[i] = 0
+	}
+	wx.commandLength = 0
+	wx.commandFN = 0
+	wx.commandFT = 0
+}
+
+// checkCommand looks for the 0x03 end marker followed by a matching
+// additive checksum within the first cmdLen bytes of the command
+func (wx *WiresX) checkCommand(cmdLen int) bool {
+	if cmdLen > len(wx.command) {
+		cmdLen = len(wx.command)
+	}
+	for i := cmdLen - 2; i > 0; i-- {
+		if wx.command[i] != 0x03 {
+			continue
+		}
+		if correction.AddCRC(wx.command[:i+1]) == wx.command[i+1] {
+			return true
+		}
+	}
+	return false
+}
+
+// CRCErrors returns the number of commands rejected for a bad checksum
+func (wx *WiresX) CRCErrors() uint64 {
+	return wx.crcErrors
+}
+
+// FrameErrors returns the number of command frames rejected for an invalid
+// or out of sequence frame number
+func (wx *WiresX) FrameErrors() uint64 {
+	return wx.frameErrors
+}
+
 // GetDstID returns the current destination ID
 func (wx *WiresX) GetDstID() uint32 {
 	return wx.dstID
@@ -420,17 +708,18 @@ func (wx *WiresX) GetRepeaterID() string {
 // ProcessConnect handles external connect requests
 func (wx *WiresX) ProcessConnect(reflector uint32) {
 	wx.dstID = reflector
-	wx.status = InternalStatusConnect
-	wx.startTimer()
+	wx.private = nil
+	wx.updateNode()
+	wx.queueReply(InternalStatusConnect, nil)
 }
 
 // ProcessDisconnect handles external disconnect requests
 func (wx *WiresX) ProcessDisconnect() {
-	wx.status = InternalStatusDisconnect
-	wx.startTimer()
+	wx.private = nil
+	wx.queueReply(InternalStatusDisconnect, nil)
 }
 
-// Clock updates the WiresX timer and processes pending responses
+// Clock updates the WiresX timer and builds pending responses
 func (wx *WiresX) Clock(ms uint32) {
 	// Check timer expiration
 	if wx.timer != nil {
@@ -440,25 +729,25 @@ func (wx *WiresX) Clock(ms uint32) {
 		default:
 		}
 	}
+}
 
-	// Handle TX buffer with rate limiting
-	if time.Since(wx.lastTX) > 90*time.Millisecond && len(wx.bufferTX) > 0 {
-		frame := wx.bufferTX[0]
-		wx.bufferTX = wx.bufferTX[1:]
-
-		if wx.network != nil {
-			wx.network.Write(frame)
-		}
-
-		wx.lastTX = time.Now()
+// NextReply returns the oldest reply waiting to be sent, or nil
+// Replies are complete messages (sequence byte, content, end marker and
+// checksum); the gateway frames them and paces them with its other YSF
+// transmissions.
+func (wx *WiresX) NextReply() []byte {
+	if len(wx.bufferTX) == 0 {
+		return nil
 	}
+	reply := wx.bufferTX[0]
+	wx.bufferTX = wx.bufferTX[1:]
+	return reply
 }
 
 // Private methods
 
 func (wx *WiresX) processDX(source []byte) {
-	wx.status = InternalStatusDX
-	wx.startTimer()
+	wx.queueReply(InternalStatusDX, nil)
 }
 
 func (wx *WiresX) processAll(source []byte, data []byte) {
@@ -468,30 +757,30 @@ func (wx *WiresX) processAll(source []byte, data []byte) {
 
 	if data[0] == '0' && data[1] == '1' {
 		// ALL request
-		startStr := string(data[2:5])
-		start, _ := strconv.Atoi(startStr)
-		if start > 0 {
-			start--
-		}
-		wx.start = start
-		wx.status = InternalStatusAll
-		wx.startTimer()
+		browse := wx.browseFor(source)
+		browse.start = browseStart(data[2:5])
+		wx.queueReply(InternalStatusAll, browse)
 	} else if data[0] == '1' && data[1] == '1' {
 		// SEARCH request
-		startStr := string(data[2:5])
-		start, _ := strconv.Atoi(startStr)
-		if start > 0 {
-			start--
-		}
-		wx.start = start
+		browse := wx.browseFor(source)
+		browse.start = browseStart(data[2:5])
 
 		if len(data) >= 21 {
-			wx.search = string(data[5:21])
+			browse.search = string(data[5:21])
 		}
 
-		wx.status = InternalStatusSearch
-		wx.startTimer()
+		wx.queueReply(InternalStatusSearch, browse)
+	}
+}
+
+// browseStart converts the 1-based start position of an ALL or SEARCH
+// request to an index; anything that is not a positive number starts at 0
+func browseStart(field []byte) int {
+	start, err := strconv.Atoi(string(field))
+	if err != nil || start < 1 {
+		return 0
 	}
+	return start - 1
 }
 
 func (wx *WiresX) processConnect(source []byte, data []byte) Status {
@@ -506,20 +795,48 @@ func (wx *WiresX) processConnect(source []byte, data []byte) Status {
 	}
 
 	wx.dstID = uint32(id)
-	wx.status = InternalStatusConnect
-	wx.startTimer()
+	wx.private = nil
+	if target, ok := wx.browseFor(source).userTargets[uint32(id)]; ok {
+		wx.dstID = target.dmrID
+		wx.private = &target
+	}
+	wx.queueReply(InternalStatusConnect, nil)
 
 	return StatusConnect
 }
 
 func (wx *WiresX) processDisconnect(source []byte) {
-	wx.status = InternalStatusDisconnect
-	wx.startTimer()
+	wx.private = nil
+	wx.queueReply(InternalStatusDisconnect, nil)
 }
 
 func (wx *WiresX) processCategory(source []byte, data []byte) {
 	// Category processing (simplified)
-	wx.status = InternalStatusCategory
+	wx.queueReply(InternalStatusCategory, wx.browseFor(source))
+}
+
+// browseFor returns the browsing state of a station, dropping expired states
+func (wx *WiresX) browseFor(source []byte) *browseState {
+	now := time.Now()
+	for key, browse := range wx.browsing {
+		if now.Sub(browse.lastUsed) > wx.browseExpiry {
+			delete(wx.browsing, key)
+		}
+	}
+
+	key := strings.TrimSpace(strings.Trim(string(source), "\x00"))
+	browse, exists := wx.browsing[key]
+	if !exists {
+		browse = &browseState{}
+		wx.browsing[key] = browse
+	}
+	browse.lastUsed = now
+	return browse
+}
+
+// queueReply schedules a reply for when the timer expires
+func (wx *WiresX) queueReply(status InternalStatus, browse *browseState) {
+	wx.pending = append(wx.pending, pendingReply{status: status, browse: browse})
 	wx.startTimer()
 }
 
@@ -531,23 +848,26 @@ func (wx *WiresX) startTimer() {
 }
 
 func (wx *WiresX) handleTimerExpiry() {
-	switch wx.status {
-	case InternalStatusDX:
-		wx.sendDXReply()
-	case InternalStatusAll:
-		wx.sendAllReply()
-	case InternalStatusSearch:
-		wx.sendSearchReply()
-	case InternalStatusConnect:
-		// Connect response is handled externally
-	case InternalStatusDisconnect:
-		// Disconnect response is handled externally
-	case InternalStatusCategory:
-		wx.sendCategoryReply()
-	}
-
-	wx.status = InternalStatusNone
+	pending := wx.pending
+	wx.pending = nil
 	wx.timer = nil
+
+	for _, reply := range pending {
+		switch reply.status {
+		case InternalStatusDX:
+			wx.sendDXReply()
+		case InternalStatusAll:
+			wx.sendAllReply(reply.browse)
+		case InternalStatusSearch:
+			wx.sendSearchReply(reply.browse)
+		case InternalStatusConnect:
+			// Connect response is handled externally
+		case InternalStatusDisconnect:
+			// Disconnect response is handled externally
+		case InternalStatusCategory:
+			wx.sendCategoryReply(reply.browse)
+		}
+	}
 }
 
 func (wx *WiresX) sendDXReply() {
@@ -556,37 +876,92 @@ func (wx *WiresX) sendDXReply() {
 	wx.seqNo++
 }
 
-func (wx *WiresX) sendAllReply() {
-	data := wx.createAllResponse()
+func (wx *WiresX) sendAllReply(browse *browseState) {
+	data := wx.createAllResponse(browse.start)
 	wx.createReply(data)
 	wx.seqNo++
 }
 
-func (wx *WiresX) sendSearchReply() {
-	if len(wx.search) == 0 {
+func (wx *WiresX) sendSearchReply(browse *browseState) {
+	if len(browse.search) == 0 {
 		wx.sendSearchNotFoundReply()
 		return
 	}
 
-	results := wx.registry.Search(wx.search)
+	var results []TalkGroup
+	if term, ok := wx.userSearchTerm(browse.search); ok {
+		results = wx.searchUsers(browse, term)
+	} else {
+		results = wx.registry.Search(browse.search)
+	}
 	if len(results) == 0 {
 		wx.sendSearchNotFoundReply()
 		return
 	}
 
-	data := wx.createSearchResponse(results)
+	data := wx.createSearchResponse(results, browse.start)
 	wx.createReply(data)
 	wx.seqNo++
 }
 
+// userSearchTerm returns the callsign prefix of a DMR user search
+func (wx *WiresX) userSearchTerm(search string) (string, bool) {
+	if wx.userSearch == nil || wx.userSearchPrefix == "" {
+		return "", false
+	}
+
+	term := strings.TrimSpace(search)
+	if !strings.HasPrefix(term, wx.userSearchPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(term, wx.userSearchPrefix)), true
+}
+
+// searchUsers looks up DMR users and presents them as search entries
+// Each user gets a selection ID that does not clash with a talk group.
+func (wx *WiresX) searchUsers(browse *browseState, term string) []TalkGroup {
+	if len(term) == 0 {
+		return nil
+	}
+
+	browse.userTargets = make(map[uint32]userTarget)
+	results := make([]TalkGroup, 0)
+
+	selectID := uint32(userSelectBase)
+	for _, user := range wx.userSearch(term, userSearchLimit) {
+		selectID++
+		for wx.registry.FindByID(selectID) != nil {
+			selectID++
+		}
+		if selectID > userSelectMax {
+			break
+		}
+
+		desc := user.Name
+		if wx.registry.makeUpper {
+			desc = strings.ToUpper(desc)
+		}
+		entry := TalkGroup{
+			ID:   fmt.Sprintf("%07d", selectID),
+			Opt:  "0",
+			Name: padRight(user.Callsign, 16),
+			Desc: padRight(desc, 14),
+		}
+		browse.userTargets[selectID] = userTarget{entry: entry, dmrID: user.ID}
+		results = append(results, entry)
+	}
+
+	return results
+}
+
 func (wx *WiresX) sendSearchNotFoundReply() {
 	data := wx.createSearchNotFoundResponse()
 	wx.createReply(data)
 	wx.seqNo++
 }
 
-func (wx *WiresX) sendCategoryReply() {
-	data := wx.createCategoryResponse()
+func (wx *WiresX) sendCategoryReply(browse *browseState) {
+	data := wx.createCategoryResponse(browse.start)
 	wx.createReply(data)
 	wx.seqNo++
 }
@@ -594,6 +969,7 @@ func (wx *WiresX) sendCategoryReply() {
 // SendConnectReply sends a connect response
 func (wx *WiresX) SendConnectReply(dstID uint32) {
 	wx.dstID = dstID
+	wx.updateNode()
 	data := wx.createConnectResponse(dstID)
 	wx.createReply(data)
 	wx.seqNo++
@@ -601,14 +977,14 @@ func (wx *WiresX) SendConnectReply(dstID uint32) {
 
 // SendDisconnectReply sends a disconnect response
 func (wx *WiresX) SendDisconnectReply() {
+	wx.dstID = 0
+	wx.updateNode()
 	data := wx.createDisconnectResponse()
 	wx.createReply(data)
 	wx.seqNo++
 }
 
 func (wx *WiresX) createReply(data []byte) {
-	// Simplified reply creation - real implementation would properly encode YSF frames
-	// For now, just add to TX buffer
 	frame := make([]byte, len(data))
 	copy(frame, data)
 	wx.bufferTX = append(wx.bufferTX, frame)
@@ -645,27 +1021,12 @@ func (wx *WiresX) createDXResponse() []byte {
 		data[34] = '1'
 		data[35] = '5'
 
-		dstIDStr := fmt.Sprintf("%05d", wx.dstID)
+		dstIDStr, name, desc := wx.destinationLabel(wx.dstID)
 		copy(data[36:], dstIDStr)
 
-		var name string
-		if wx.dstID == 9 {
-			name = "LOCAL"
-		} else if wx.dstID == 9990 {
-			name = "PARROT"
-		} else if wx.dstID == 4000 {
-			name = "UNLINK"
-		} else {
-			name = fmt.Sprintf("TG %d", wx.dstID)
-		}
-
-		if len(name) < 16 {
-			name = name + strings.Repeat(" ", 16-len(name))
-		}
-
 		copy(data[41:], name[:16])
 		copy(data[57:], "000")
-		copy(data[70:], "Descripcion   ")
+		copy(data[70:], desc[:14])
 	}
 
 	// Frequency information
@@ -711,27 +1072,12 @@ func (wx *WiresX) createConnectResponse(dstID uint32) []byte {
 	data[34] = '1'
 	data[35] = '5'
 
-	dstIDStr := fmt.Sprintf("%05d", dstID)
+	dstIDStr, name, desc := wx.destinationLabel(dstID)
 	copy(data[36:], dstIDStr)
 
-	var name string
-	if dstID == 9 {
-		name = "LOCAL"
-	} else if dstID == 9990 {
-		name = "PARROT"
-	} else if dstID == 4000 {
-		name = "UNLINK"
-	} else {
-		name = fmt.Sprintf("TG %d", dstID)
-	}
-
-	if len(name) < 16 {
-		name = name + strings.Repeat(" ", 16-len(name))
-	}
-
 	copy(data[41:], name[:16])
 	copy(data[57:], "000")
-	copy(data[70:], "Descripcion   ")
+	copy(data[70:], desc[:14])
 	copy(data[84:], "00000")
 
 	data[89] = 0x03 // End marker
@@ -740,6 +1086,33 @@ func (wx *WiresX) createConnectResponse(dstID uint32) []byte {
 	return data
 }
 
+// destinationLabel returns the 5-digit ID, 16 character name and 14
+// character description shown for dstID
+// Talk groups in the TG list are shown with their name, e.g. "TG 3126
+// Michigan", and description.
+func (wx *WiresX) destinationLabel(dstID uint32) (string, string, string) {
+	if wx.private != nil && wx.private.dmrID == dstID {
+		return wx.private.entry.ID[2:7], wx.private.entry.Name, padRight(wx.private.entry.Desc, 14)
+	}
+
+	name := strings.TrimSpace(wx.tgPrefix + " " + strconv.FormatUint(uint64(dstID), 10))
+	desc := wx.description
+	if tg := wx.registry.FindByID(dstID); tg != nil {
+		name += " " + strings.TrimSpace(tg.Name)
+		if d := strings.TrimSpace(tg.Desc); d != "" {
+			desc = d
+		}
+	} else if dstID == 9 {
+		name = wx.localName
+	} else if dstID == 9990 {
+		name = wx.parrotName
+	} else if dstID == 4000 {
+		name = wx.unlinkName
+	}
+
+	return fmt.Sprintf("%05d", dstID), padRight(name, 16), padRight(desc, 14)
+}
+
 func (wx *WiresX) createDisconnectResponse() []byte {
 	data := make([]byte, 91)
 
@@ -764,18 +1137,21 @@ func (wx *WiresX) createDisconnectResponse() []byte {
 	return data
 }
 
-func (wx *WiresX) createAllResponse() []byte {
+func (wx *WiresX) createAllResponse(start int) []byte {
 	total := wx.registry.GetCount()
 	if total > 999 {
 		total = 999
 	}
 
-	n := total - wx.start
+	n := total - start
 	if n > 20 {
 		n = 20
 	}
+	if n < 0 {
+		n = 0
+	}
 
-	talkGroups := wx.registry.GetAll(wx.start, n)
+	talkGroups := wx.registry.GetAll(start, n)
 
 	// Calculate response size
 	size := 29 + n*50 + (1029-29-n*50) + 2
@@ -821,19 +1197,19 @@ func (wx *WiresX) createAllResponse() []byte {
 	return data[:offset+2]
 }
 
-func (wx *WiresX) createSearchResponse(results []TalkGroup) []byte {
+func (wx *WiresX) createSearchResponse(results []TalkGroup, start int) []byte {
 	total := len(results)
 	if total > 999 {
 		total = 999
 	}
 
-	n := len(results) - wx.start
+	n := len(results) - start
 	if n > 20 {
 		n = 20
 	}
 
-	if wx.start < len(results) {
-		results = results[wx.start:]
+	if start < len(results) {
+		results = results[start:]
 	} else {
 		results = nil
 		n = 0
@@ -908,12 +1284,21 @@ func (wx *WiresX) createSearchNotFoundResponse() []byte {
 	return data
 }
 
-func (wx *WiresX) createCategoryResponse() []byte {
+func (wx *WiresX) createCategoryResponse(start int) []byte {
 	// Simplified category response
-	return wx.createAllResponse()
+	return wx.createAllResponse(start)
+}
+
+// Utility functions
+
+// padRight pads s with spaces, or truncates it, to exactly n characters
+func padRight(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s + strings.Repeat(" ", n-len(s))
 }
 
-// Utility function
 func bytesEqual(a, b []byte) bool {
 	if len(a) != len(b) {
 		return false
@@ -1,7 +1,15 @@
 package wiresx
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/correction"
 )
 
 func TestWiresX_ProcessDXRequest(t *testing.T) {
@@ -13,13 +21,13 @@ func TestWiresX_ProcessDXRequest(t *testing.T) {
 	}{
 		{
 			name:           "valid DX request",
-			command:        []byte{0x01, 0x5D, 0x71, 0x5F, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x4A}, // DX_REQ with proper framing and length
+			command:        []byte{0x01, 0x5D, 0x71, 0x5F, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x31}, // DX_REQ with proper framing and length
 			expectedStatus: StatusDX,
 			expectedReply:  true,
 		},
 		{
 			name:           "invalid command",
-			command:        []byte{0x01, 0x5D, 0xFF, 0x5F, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x00},
+			command:        []byte{0x01, 0x5D, 0xFF, 0x5F, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0xBF},
 			expectedStatus: StatusFail,
 			expectedReply:  false,
 		},
@@ -27,7 +35,7 @@ func TestWiresX_ProcessDXRequest(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			wx := NewWiresX("G4KLX", "", nil, "", false)
+			wx := NewWiresX("G4KLX", "", "", false)
 			wx.SetInfo("Test Node", 145800000, 145200000, 9)
 
 			status := wx.Process(tt.command, []byte("G4KLX     "), 1, 1, 1, 1)
@@ -39,6 +47,94 @@ func TestWiresX_ProcessDXRequest(t *testing.T) {
 	}
 }
 
+func TestWiresX_ProcessBadChecksum(t *testing.T) {
+	wx := NewWiresX("G4KLX", "", "", false)
+	wx.SetInfo("Test Node", 145800000, 145200000, 9)
+
+	// CONN_REQ to TG 91 with one corrupted digit
+	command := []byte{0x01, 0x5D, 0x23, 0x5F, '0', '0', '0', '0', '9', '2', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x0D}
+	if status := wx.Process(command, []byte("G4KLX     "), 1, 1, 1, 1); status != StatusNone {
+		t.Errorf("Process() status = %v, want %v", status, StatusNone)
+	}
+	if wx.GetDstID() != 9 {
+		t.Errorf("GetDstID() = %d, want 9", wx.GetDstID())
+	}
+
+	// No end marker at all
+	command = []byte{0x01, 0x5D, 0x71, 0x5F, 0x00, 0x00, 0x31}
+	if status := wx.Process(command, []byte("G4KLX     "), 1, 1, 1, 1); status != StatusNone {
+		t.Errorf("Process() status = %v, want %v", status, StatusNone)
+	}
+
+	if wx.CRCErrors() != 2 {
+		t.Errorf("CRCErrors() = %d, want 2", wx.CRCErrors())
+	}
+
+	// A valid command after the rejected ones is still accepted
+	command = []byte{0x01, 0x5D, 0x71, 0x5F, 0x00, 0x03, 0x31}
+	if status := wx.Process(command, []byte("G4KLX     "), 1, 1, 1, 1); status != StatusDX {
+		t.Errorf("Process() status = %v, want %v", status, StatusDX)
+	}
+}
+
+func TestWiresX_ProcessFrameSequence(t *testing.T) {
+	// CONN_REQ to TG 91 padded to span two frames
+	command := append([]byte{0x01}, CONN_REQ...)
+	command = append(command, []byte("000091")...)
+	command = append(command, make([]byte, 15)...)
+	command = append(command, 0x03)
+	command = append(command, correction.AddCRC(command))
+	first, second := command[:COMMAND_FIRST_BLOCK_LENGTH], command[COMMAND_FIRST_BLOCK_LENGTH:]
+
+	type frame struct {
+		data   []byte
+		fn, ft uint8
+	}
+	tests := []struct {
+		name        string
+		frames      []frame
+		want        Status
+		frameErrors uint64
+	}{
+		{"in sequence", []frame{{first, 1, 2}, {second, 2, 2}}, StatusConnect, 0},
+		{"restarted", []frame{{first, 1, 2}, {first, 1, 2}, {second, 2, 2}}, StatusConnect, 0},
+		{"no first frame", []frame{{second, 2, 2}}, StatusNone, 1},
+		{"repeated frame", []frame{{first, 1, 2}, {second, 2, 2}, {second, 2, 2}}, StatusNone, 1},
+		{"skipped frame", []frame{{first, 1, 3}, {second, 3, 3}}, StatusNone, 1},
+		{"frame total changed", []frame{{first, 1, 3}, {second, 2, 2}}, StatusNone, 1},
+		{"frame number beyond total", []frame{{first, 1, 2}, {second, 3, 2}}, StatusNone, 1},
+		{"frame total too large", []frame{{first, 1, 8}}, StatusNone, 1},
+		{"maximum frame numbers", []frame{{second, 255, 255}}, StatusNone, 1},
+		{"oversized frames", []frame{
+			{append(append([]byte{}, first...), make([]byte, 100)...), 1, 2},
+			{append(append([]byte{}, second...), make([]byte, 100)...), 2, 2},
+		}, StatusConnect, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wx := NewWiresX("G4KLX", "", "", false)
+			wx.SetInfo("Test Node", 145800000, 145200000, 9)
+
+			var status Status
+			for _, f := range tt.frames {
+				status = wx.Process(f.data, []byte("G4KLX     "), 1, 1, f.fn, f.ft)
+			}
+			if status != tt.want {
+				t.Errorf("Process() status = %v, want %v", status, tt.want)
+			}
+			if wx.FrameErrors() != tt.frameErrors {
+				t.Errorf("FrameErrors() = %d, want %d", wx.FrameErrors(), tt.frameErrors)
+			}
+
+			// A rejected sequence never blocks the next command
+			if status := processCommand(wx, "G4KLX", []byte{0x5D, 0x71, 0x5F}); status != StatusDX {
+				t.Errorf("following command status = %v, want %v", status, StatusDX)
+			}
+		})
+	}
+}
+
 func TestWiresX_ProcessConnectRequest(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -48,13 +144,13 @@ func TestWiresX_ProcessConnectRequest(t *testing.T) {
 	}{
 		{
 			name:           "valid connect to TG 9",
-			command:        []byte{0x01, 0x5D, 0x23, 0x5F, '0', '0', '0', '0', '0', '9', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x7D}, // CONN_REQ to TG 9
+			command:        []byte{0x01, 0x5D, 0x23, 0x5F, '0', '0', '0', '0', '0', '9', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x0C}, // CONN_REQ to TG 9
 			expectedStatus: StatusConnect,
 			expectedDstID:  9,
 		},
 		{
 			name:           "valid connect to TG 91",
-			command:        []byte{0x01, 0x5D, 0x23, 0x5F, '0', '0', '0', '0', '9', '1', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x18},
+			command:        []byte{0x01, 0x5D, 0x23, 0x5F, '0', '0', '0', '0', '9', '1', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x0D},
 			expectedStatus: StatusConnect,
 			expectedDstID:  91,
 		},
@@ -62,7 +158,7 @@ func TestWiresX_ProcessConnectRequest(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			wx := NewWiresX("G4KLX", "", nil, "", false)
+			wx := NewWiresX("G4KLX", "", "", false)
 			wx.SetInfo("Test Node", 145800000, 145200000, 0)
 
 			status := wx.Process(tt.command, []byte("G4KLX     "), 1, 1, 1, 1)
@@ -79,10 +175,10 @@ func TestWiresX_ProcessConnectRequest(t *testing.T) {
 }
 
 func TestWiresX_ProcessDisconnectRequest(t *testing.T) {
-	wx := NewWiresX("G4KLX", "", nil, "", false)
+	wx := NewWiresX("G4KLX", "", "", false)
 	wx.SetInfo("Test Node", 145800000, 145200000, 91)
 
-	command := []byte{0x01, 0x5D, 0x2A, 0x5F, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x2B} // DISC_REQ
+	command := []byte{0x01, 0x5D, 0x2A, 0x5F, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0xEA} // DISC_REQ
 	status := wx.Process(command, []byte("G4KLX     "), 1, 1, 1, 1)
 
 	if status != StatusDisconnect {
@@ -98,19 +194,19 @@ func TestWiresX_ProcessAllRequest(t *testing.T) {
 	}{
 		{
 			name:           "ALL request for page 0",
-			command:        []byte{0x01, 0x5D, 0x66, 0x5F, '0', '1', '0', '0', '0', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x2F},
+			command:        []byte{0x01, 0x5D, 0x66, 0x5F, '0', '1', '0', '0', '0', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x17},
 			expectedStatus: StatusAll,
 		},
 		{
 			name:           "SEARCH request",
-			command:        []byte{0x01, 0x5D, 0x66, 0x5F, '1', '1', '0', '0', '0', 'T', 'E', 'S', 'T', ' ', 'S', 'E', 'A', 'R', 0x03, 0x5E}, // Truncated search term
+			command:        []byte{0x01, 0x5D, 0x66, 0x5F, '1', '1', '0', '0', '0', 'T', 'E', 'S', 'T', ' ', 'S', 'E', 'A', 'R', 0x03, 0xA3}, // Truncated search term
 			expectedStatus: StatusAll, // Search is handled as ALL with different parameters
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			wx := NewWiresX("G4KLX", "", nil, "", false)
+			wx := NewWiresX("G4KLX", "", "", false)
 			wx.SetInfo("Test Node", 145800000, 145200000, 0)
 
 			status := wx.Process(tt.command, []byte("G4KLX     "), 1, 1, 1, 1)
@@ -223,8 +319,70 @@ func TestTalkGroupRegistry_Search(t *testing.T) {
 	}
 }
 
+func TestTalkGroupRegistry_Index(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "TGList.txt")
+	testData := `91;0;Worldwide;Worldwide
+3126;0;michigan;Michigan
+3100;0;USA Nationwide;USA
+91;1;Duplicate;Listed twice
+31261;0;Michigan 2;Second`
+	if err := os.WriteFile(path, []byte(testData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := NewTalkGroupRegistry(false)
+	if err := registry.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+
+	if tg := registry.FindByID(91); tg == nil || tg.Opt != "0" {
+		t.Errorf("FindByID(91) = %+v, want the first entry", tg)
+	}
+	if tg := registry.FindByID(3126); tg == nil || tg.ID != "0003126" {
+		t.Errorf("FindByID(3126) = %+v", tg)
+	}
+	if tg := registry.FindByID(4000); tg != nil {
+		t.Errorf("FindByID(4000) = %+v, want nil", tg)
+	}
+
+	results := registry.Search("mich")
+	if len(results) != 2 || results[0].ID != "0003126" || results[1].ID != "0031261" {
+		t.Errorf("Search(mich) = %+v", results)
+	}
+	if results := registry.Search("USA N"); len(results) != 1 {
+		t.Errorf("Search(USA N) returned %d results, want 1", len(results))
+	}
+}
+
+func TestWiresX_ReloadTalkGroups(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	os.WriteFile(first, []byte("91;0;Worldwide;Worldwide\n3100;0;USA;USA"), 0o644)
+	os.WriteFile(second, []byte("3126;0;Michigan;Michigan"), 0o644)
+
+	wx := NewWiresX("G4KLX", "", first, false)
+	if wx.registry.GetCount() != 2 {
+		t.Fatalf("initial count = %d, want 2", wx.registry.GetCount())
+	}
+
+	if err := wx.ReloadTalkGroups(second); err != nil {
+		t.Fatalf("ReloadTalkGroups() error = %v", err)
+	}
+	if wx.registry.GetCount() != 1 || wx.registry.FindByID(91) != nil || wx.registry.FindByID(3126) == nil {
+		t.Errorf("after reload: count %d, want only TG 3126", wx.registry.GetCount())
+	}
+
+	if err := wx.ReloadTalkGroups(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("ReloadTalkGroups() error = nil for a missing file")
+	}
+	if wx.registry.FindByID(3126) == nil {
+		t.Error("failed reload discarded the current list")
+	}
+}
+
 func TestWiresX_ResponseGeneration(t *testing.T) {
-	wx := NewWiresX("G4KLX", "RPT", nil, "", false)
+	wx := NewWiresX("G4KLX", "RPT", "", false)
 	wx.SetInfo("Test Repeater", 145800000, 145200000, 91)
 
 	// Test DX response generation
@@ -258,8 +416,31 @@ func TestWiresX_ResponseGeneration(t *testing.T) {
 	})
 }
 
+func TestWiresX_TalkGroupLabels(t *testing.T) {
+	wx := NewWiresX("G4KLX", "RPT", "", false)
+	wx.registry.LoadFromString("3126;0;Michigan;Statewide")
+	wx.SetInfo("Test Repeater", 145800000, 145200000, 3126)
+
+	dx := string(wx.createDXResponse())
+	if got := dx[36:84]; got != "03126TG 3126 Michigan000          Statewide     " {
+		t.Errorf("DX response destination = %q, want the TG list name and description", got)
+	}
+
+	wx.SetLabels("GRP", "Talkgroup")
+	conn := string(wx.createConnectResponse(91))
+	if got := conn[36:84]; got != "00091GRP 91          000          Talkgroup     " {
+		t.Errorf("connect response destination = %q, want the configured labels", got)
+	}
+
+	wx.SetNames("LOKAL", "PAPAGEI", "TRENNEN")
+	conn = string(wx.createConnectResponse(4000))
+	if got := conn[36:57]; got != "04000TRENNEN         " {
+		t.Errorf("connect response destination = %q, want the configured unlink name", got)
+	}
+}
+
 func TestWiresX_RepeaterID(t *testing.T) {
-	wx := NewWiresX("G4KLX", "", nil, "", false)
+	wx := NewWiresX("G4KLX", "", "", false)
 	wx.SetInfo("Test Node", 145800000, 145200000, 0)
 
 	id := wx.GetRepeaterID()
@@ -275,12 +456,78 @@ func TestWiresX_RepeaterID(t *testing.T) {
 	}
 }
 
+func TestWiresX_RepeaterIDStable(t *testing.T) {
+	wx := NewWiresX("G4KLX", "", "", false)
+	wx.SetInfo("Test Node", 145800000, 145200000, 0)
+	id := wx.GetRepeaterID()
+
+	wx.SetInfo("Renamed Node", 145800000, 145200000, 0)
+	if got := wx.GetRepeaterID(); got != id {
+		t.Errorf("GetRepeaterID() = %s after a description change, want %s", got, id)
+	}
+	if want := fmt.Sprintf("%05d", DeriveRepeaterID("G4KLX")); id != want {
+		t.Errorf("GetRepeaterID() = %s, want %s derived from the callsign", id, want)
+	}
+
+	wx.SetRepeaterID(42)
+	wx.SetInfo("Renamed Node", 145800000, 145200000, 91)
+	if got := wx.GetRepeaterID(); got != "00042" {
+		t.Errorf("GetRepeaterID() = %s, want the configured 00042", got)
+	}
+	if got := string(wx.csd3[:5]); got != "00042" {
+		t.Errorf("CSD3 ID = %s, want 00042", got)
+	}
+	for name, reply := range map[string][]byte{"DX": wx.createDXResponse(), "connect": wx.createConnectResponse(91)} {
+		if got := string(reply[5:10]); got != "00042" {
+			t.Errorf("%s reply ID = %s, want 00042", name, got)
+		}
+	}
+}
+
+func TestWiresX_NodeModule(t *testing.T) {
+	wx := NewWiresX("W8XYZ", "", "", false)
+	wx.SetInfo("Test Node", 145800000, 145200000, 0)
+
+	wx.SetNodeModule("c")
+	if got := string(wx.csd1[10:20]); got != "W8XYZ C   " {
+		t.Errorf("CSD1 node = %q, want the fixed module", got)
+	}
+
+	wx.SetNodeModule(NODE_MODULE_XLX)
+	for _, tt := range []struct {
+		dstID uint32
+		want  string
+	}{
+		{4003, "W8XYZ C   "},
+		{4026, "W8XYZ Z   "},
+		{91, "W8XYZ     "},
+	} {
+		wx.SendConnectReply(tt.dstID)
+		if got := string(wx.createConnectResponse(tt.dstID)[10:20]); got != tt.want {
+			t.Errorf("linked to %d: node = %q, want %q", tt.dstID, got, tt.want)
+		}
+	}
+
+	wx.SendConnectReply(4001)
+	wx.SendDisconnectReply()
+	if got := string(wx.header[14:24]); got != "W8XYZ     " {
+		t.Errorf("header node after disconnect = %q, want no module", got)
+	}
+
+	// The callsign and suffix are shortened so the letter fits
+	long := NewWiresX("W8XYZ", "ROOM", "", false)
+	long.SetNodeModule("C")
+	if got := long.node; got != "W8XYZ-RO C" {
+		t.Errorf("node = %q, want W8XYZ-RO C", got)
+	}
+}
+
 func TestWiresX_Timer(t *testing.T) {
-	wx := NewWiresX("G4KLX", "", nil, "", false)
+	wx := NewWiresX("G4KLX", "", "", false)
 	wx.SetInfo("Test Node", 145800000, 145200000, 0)
 
 	// Simulate DX request
-	command := []byte{0x01, 0x5D, 0x71, 0x5F, 0x00, 0x03, 0x00}
+	command := []byte{0x01, 0x5D, 0x71, 0x5F, 0x00, 0x03, 0x31}
 	status := wx.Process(command, []byte("G4KLX     "), 1, 1, 1, 1)
 
 	if status != StatusDX {
@@ -294,11 +541,183 @@ func TestWiresX_Timer(t *testing.T) {
 	// This would require checking the output buffer/network write
 }
 
+func TestWiresX_NextReply(t *testing.T) {
+	wx := NewWiresX("G4KLX", "", "", false)
+	wx.SetInfo("Test Node", 145800000, 145200000, 0)
+
+	if reply := wx.NextReply(); reply != nil {
+		t.Fatalf("NextReply() before any command = %q", reply)
+	}
+
+	processCommand(wx, "G4KLX", []byte{0x5D, 0x71, 0x5F})
+	if reply := wx.NextReply(); reply != nil {
+		t.Fatal("NextReply() returned a reply before the timer expired")
+	}
+	wx.handleTimerExpiry()
+
+	reply := wx.NextReply()
+	if reply == nil {
+		t.Fatal("NextReply() = nil after the timer expired")
+	}
+	if !bytes.Equal(reply[1:5], DX_RESP) {
+		t.Errorf("reply type = % X, want DX response % X", reply[1:5], DX_RESP)
+	}
+	if reply[len(reply)-2] != 0x03 {
+		t.Error("reply does not end with the end marker and checksum")
+	}
+	if reply := wx.NextReply(); reply != nil {
+		t.Error("NextReply() returned the reply twice")
+	}
+}
+
+// processCommand feeds a WiresX command through Process as data FR frames
+func processCommand(wx *WiresX, source string, payload []byte) Status {
+	command := append([]byte{0x01}, payload...)
+	command = append(command, 0x03)
+	command = append(command, correction.AddCRC(command))
+
+	frames := 1
+	if len(command) > 20 {
+		frames += (len(command) - 20 + 39) / 40
+	}
+
+	var status Status
+	for fn, offset := 1, 0; fn <= frames; fn++ {
+		length := 40
+		if fn == 1 {
+			length = 20
+		}
+		end := min(offset+length, len(command))
+		status = wx.Process(command[offset:end], []byte(fmt.Sprintf("%-10s", source)), 1, 1, uint8(fn), uint8(frames))
+		offset = end
+	}
+	return status
+}
+
+func TestWiresX_UserSearch(t *testing.T) {
+	wx := NewWiresX("G4KLX", "", "", true)
+	wx.SetInfo("Test Node", 145800000, 145200000, 0)
+	wx.registry.LoadFromString("90001;0;CLASH;Occupies 90001")
+
+	var searched string
+	wx.SetUserSearch("*", func(prefix string, limit int) []User {
+		searched = prefix
+		return []User{
+			{ID: 3120001, Callsign: "W1AW", Name: "Hiram"},
+			{ID: 3120002, Callsign: "W1AX"},
+		}
+	})
+
+	search := append([]byte{0x5D, 0x66, 0x5F, '0', '1', '1', '0', '0', '1'}, []byte(fmt.Sprintf("%-16s", "*w1a"))...)
+	processCommand(wx, "G4KLX", search)
+	wx.handleTimerExpiry()
+
+	if searched != "w1a" {
+		t.Errorf("user search prefix = %q, want w1a", searched)
+	}
+	if len(wx.bufferTX) != 1 {
+		t.Fatalf("buffered %d replies, want 1", len(wx.bufferTX))
+	}
+	reply := string(wx.bufferTX[0])
+	if !strings.Contains(reply, "190002W1AW") || !strings.Contains(reply, "HIRAM") {
+		t.Errorf("search reply does not list W1AW as 90002: %q", reply)
+	}
+
+	// Selecting the entry connects a private call to the DMR ID
+	status := processCommand(wx, "G4KLX", []byte{0x5D, 0x23, 0x5F, '0', '9', '0', '0', '0', '2'})
+	if status != StatusConnect {
+		t.Fatalf("Process() status = %v, want %v", status, StatusConnect)
+	}
+	if wx.GetDstID() != 3120001 || !wx.IsPrivate() {
+		t.Errorf("GetDstID() = %d, IsPrivate() = %v; want 3120001, true", wx.GetDstID(), wx.IsPrivate())
+	}
+	if reply := string(wx.createConnectResponse(3120001)); !strings.Contains(reply, "90002W1AW") {
+		t.Errorf("connect reply does not show W1AW: %q", reply)
+	}
+
+	// Talk groups are still reached by their own ID
+	processCommand(wx, "G4KLX", []byte{0x5D, 0x23, 0x5F, '0', '0', '0', '0', '9', '1'})
+	if wx.GetDstID() != 91 || wx.IsPrivate() {
+		t.Errorf("GetDstID() = %d, IsPrivate() = %v; want 91, false", wx.GetDstID(), wx.IsPrivate())
+	}
+}
+
+func TestWiresX_RestoreLink(t *testing.T) {
+	wx := NewWiresX("G4KLX", "", "", true)
+	wx.SetInfo("Test Node", 145800000, 145200000, 9)
+
+	user := &TalkGroup{ID: "0090002", Opt: "0", Name: padRight("W1AW", 16), Desc: padRight("HIRAM", 14)}
+	wx.RestoreLink(3120001, user)
+	if dstID, entry := wx.Link(); dstID != 3120001 || entry == nil || *entry != *user || !wx.IsPrivate() {
+		t.Errorf("Link() = %d, %+v after restoring a private call", dstID, entry)
+	}
+	if reply := string(wx.createConnectResponse(3120001)); !strings.Contains(reply, "90002W1AW") {
+		t.Errorf("connect reply does not show the restored W1AW: %q", reply)
+	}
+
+	wx.RestoreLink(91, nil)
+	if dstID, entry := wx.Link(); dstID != 91 || entry != nil || wx.IsPrivate() {
+		t.Errorf("Link() = %d, %+v after restoring TG 91", dstID, entry)
+	}
+}
+
+func TestWiresX_BrowsingPerStation(t *testing.T) {
+	var tgList strings.Builder
+	for id := 1; id <= 25; id++ {
+		fmt.Fprintf(&tgList, "%d;0;TG %d;Talk group\n", id, id)
+	}
+
+	wx := NewWiresX("G4KLX", "", "", false)
+	wx.SetInfo("Test Node", 145800000, 145200000, 0)
+	wx.registry.LoadFromString(tgList.String())
+	wx.SetUserSearch("*", func(prefix string, limit int) []User {
+		return []User{{ID: 3120001, Callsign: "W1AW"}}
+	})
+
+	// Both stations ask for a page before either reply is sent
+	processCommand(wx, "G4KLX", []byte{0x5D, 0x66, 0x5F, '0', '0', '1', '0', '2', '1'})
+	processCommand(wx, "W1AW", []byte{0x5D, 0x66, 0x5F, '0', '0', '1', '0', '0', '1'})
+	wx.handleTimerExpiry()
+
+	if len(wx.bufferTX) != 2 {
+		t.Fatalf("buffered %d replies, want 2", len(wx.bufferTX))
+	}
+	if counts := string(wx.bufferTX[0][22:28]); counts != "005025" {
+		t.Errorf("G4KLX page counts = %q, want 005025", counts)
+	}
+	if counts := string(wx.bufferTX[1][22:28]); counts != "020025" {
+		t.Errorf("W1AW page counts = %q, want 020025", counts)
+	}
+
+	// User search results can only be selected by the station that searched
+	search := append([]byte{0x5D, 0x66, 0x5F, '0', '1', '1', '0', '0', '1'}, []byte(fmt.Sprintf("%-16s", "*W1"))...)
+	processCommand(wx, "G4KLX", search)
+	wx.handleTimerExpiry()
+
+	processCommand(wx, "W1AW", []byte{0x5D, 0x23, 0x5F, '0', '9', '0', '0', '0', '1'})
+	if wx.IsPrivate() || wx.GetDstID() != 90001 {
+		t.Errorf("W1AW selected G4KLX's search result: dst %d", wx.GetDstID())
+	}
+	processCommand(wx, "G4KLX", []byte{0x5D, 0x23, 0x5F, '0', '9', '0', '0', '0', '1'})
+	if !wx.IsPrivate() || wx.GetDstID() != 3120001 {
+		t.Errorf("G4KLX selection: dst %d, private %v", wx.GetDstID(), wx.IsPrivate())
+	}
+
+	// Idle stations are forgotten
+	for _, browse := range wx.browsing {
+		browse.lastUsed = time.Now().Add(-browseStateExpiry - time.Second)
+	}
+	wx.browseFor([]byte("M0ABC     "))
+	if len(wx.browsing) != 1 {
+		t.Errorf("%d browsing states kept, want 1", len(wx.browsing))
+	}
+}
+
 // Benchmark tests for performance
 func BenchmarkWiresX_ProcessDX(b *testing.B) {
-	wx := NewWiresX("G4KLX", "", nil, "", false)
+	wx := NewWiresX("G4KLX", "", "", false)
 	wx.SetInfo("Test Node", 145800000, 145200000, 0)
-	command := []byte{0x01, 0x5D, 0x71, 0x5F, 0x00, 0x03, 0x00}
+	command := []byte{0x01, 0x5D, 0x71, 0x5F, 0x00, 0x03, 0x31}
 	source := []byte("G4KLX     ")
 
 	b.ResetTimer()
@@ -319,4 +738,19 @@ func BenchmarkTalkGroupRegistry_Search(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		registry.Search("LOCAL")
 	}
-}
\ No newline at end of file
+}
+
+func BenchmarkTalkGroupRegistry_FindByID(b *testing.B) {
+	var data strings.Builder
+	for id := 1; id <= 5000; id++ {
+		fmt.Fprintf(&data, "%d;0;TG %d;Talk group\n", id, id)
+	}
+
+	registry := NewTalkGroupRegistry(false)
+	registry.LoadFromString(data.String())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		registry.FindByID(4999)
+	}
+}
@@ -0,0 +1,72 @@
+package gateway
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/aprs"
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/dmr"
+)
+
+// aprsInterval is the shortest time between positions logged and forwarded
+// for one radio; radios in a voice call repeat their GPS Info every
+// superframe
+const aprsInterval = time.Minute
+
+// initializeAPRS creates the APRS-IS client when DMR positions are forwarded
+func initializeAPRS(cfg *config.Config, version string) *aprs.Client {
+	if !cfg.GetAPRSEnabled() || !cfg.GetAPRSDMRPositions() {
+		return nil
+	}
+	if cfg.GetAPRSCallsign() == "" || cfg.GetAPRSServer() == "" {
+		log.Printf("APRS: AprsCallsign and Server are needed to forward DMR positions")
+		return nil
+	}
+
+	log.Printf("APRS: forwarding DMR positions to %s:%d as %s", cfg.GetAPRSServer(), cfg.GetAPRSPort(), cfg.GetAPRSCallsign())
+	return aprs.NewClient(cfg.GetAPRSServer(), cfg.GetAPRSPort(), strings.ToUpper(cfg.GetAPRSCallsign()),
+		cfg.GetAPRSPassword(), version)
+}
+
+// reportDMRPosition logs the position a DMR radio reported and forwards it
+// to APRS-IS under the radio's callsign, at most once per aprsInterval
+// Radios the DMR ID lookup does not know have no callsign to report under
+// and are only logged.
+func (g *Gateway) reportDMRPosition(srcID uint32, pos dmr.Position, via string) {
+	now := time.Now()
+	if last, ok := g.aprsReported[srcID]; ok && now.Sub(last) < aprsInterval {
+		return
+	}
+	g.aprsReported[srcID] = now
+
+	log.Printf("DMR position from %s (%s): %.5f, %.5f", g.describeDMRUser(srcID), via, pos.Latitude, pos.Longitude)
+	if g.aprs == nil {
+		return
+	}
+
+	callsign := ""
+	if g.dmrLookup != nil {
+		if user, ok := g.dmrLookup.FindUser(srcID); ok {
+			callsign = strings.ToUpper(strings.TrimSpace(user.Callsign))
+		}
+	}
+	if callsign == "" {
+		log.Printf("APRS: position of DMR ID %d not sent, callsign unknown", srcID)
+		return
+	}
+
+	ssid := uint(g.config.GetAPRSDMRSSID())
+	source := callsign
+	if ssid != 0 {
+		source = fmt.Sprintf("%s-%d", callsign, ssid)
+	}
+	packet := aprs.FormatPosition(source, strings.ToUpper(g.config.GetAPRSCallsign()), pos.Latitude, pos.Longitude,
+		pos.Course, aprs.Symbol(ssid), fmt.Sprintf("DMR ID %d", srcID))
+
+	if !g.aprs.Send(packet) {
+		log.Printf("APRS: position of %s dropped, APRS-IS queue full", source)
+	}
+}
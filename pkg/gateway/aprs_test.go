@@ -0,0 +1,68 @@
+package gateway
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol/dmr"
+)
+
+func TestDMRPositionToAPRS(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	ids := filepath.Join(t.TempDir(), "DMRIds.dat")
+	if err := os.WriteFile(ids, []byte("3109999 w1abc Test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g, _, _ := startGateway(t, func(b *ConfigBuilder) {
+		b.Set("DMR Id Lookup", "File", ids).
+			SetBool("aprs.fi", "Enable", true).
+			SetBool("aprs.fi", "DMRPositions", true).
+			Set("aprs.fi", "AprsCallsign", "N0CALL").
+			Set("aprs.fi", "Server", "127.0.0.1").
+			Set("aprs.fi", "Port", strconv.Itoa(listener.Addr().(*net.TCPAddr).Port))
+	})
+
+	report := func() {
+		g.reportDMRPosition(3109999, dmr.Position{Latitude: 42.5, Longitude: -83.25, Course: -1}, "LRRP")
+	}
+	if err := g.runOnMainLoop(func() { report(); report() }); err != nil {
+		t.Fatal(err)
+	}
+
+	listener.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	reader := bufio.NewReader(conn)
+	if _, err := reader.ReadString('\n'); err != nil {
+		t.Fatalf("no login: %v", err)
+	}
+	packet, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "W1ABC-7>APZDMR,qAR,N0CALL:!4230.00N/08315.00W[DMR ID 3109999\r\n"; packet != want {
+		t.Errorf("packet = %q, want %q", packet, want)
+	}
+
+	// The repeat within aprsInterval is not sent
+	conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if extra, err := reader.ReadString('\n'); err == nil {
+		t.Errorf("unexpected packet %q", extra)
+	}
+}
@@ -0,0 +1,221 @@
+package gateway
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/audio"
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/events"
+	"github.com/dbehnke/ysf2dmr/internal/recorder"
+)
+
+// audioLevelInterval is how often the level of the call in progress is
+// published
+const audioLevelInterval = time.Second
+
+// audioPath meters the voice bridged in one direction and applies its gain
+type audioPath struct {
+	direction string
+	gain      *audio.Gain
+	call      audio.Meter // since the call started
+	live      audio.Meter // since the last audio_level event
+	published time.Time
+	calls     uint64 // calls ended, to drop levels decoded too late
+}
+
+// initializeAudio creates the transcoder used for the gain and the paths for
+// both directions; all are nil when no transcoder is configured or it cannot
+// be opened
+// The voice worker decodes the audio that is only metered.
+func initializeAudio(cfg *config.Config) (codec.Transcoder, *audioPath, *audioPath) {
+	name := cfg.GetAudioTranscoder()
+	if name == "" {
+		return nil, nil, nil
+	}
+	transcoder, err := codec.NewTranscoder(name, cfg.GetAudioTranscoderArg())
+	if err != nil {
+		log.Printf("Audio levels disabled: %v", err)
+		return nil, nil, nil
+	}
+
+	target, maxGain := cfg.GetAudioAGCTarget(), cfg.GetAudioAGCMaxGain()
+	ysfPath := &audioPath{
+		direction: "YSF->DMR",
+		gain:      audio.NewGain(cfg.GetAudioYSFGain(), cfg.GetAudioYSFAGC(), target, maxGain),
+	}
+	dmrPath := &audioPath{
+		direction: "DMR->YSF",
+		gain:      audio.NewGain(cfg.GetAudioDMRGain(), cfg.GetAudioDMRAGC(), target, maxGain),
+	}
+	log.Printf("Audio levels metered with the %s transcoder (YSF->DMR %s, DMR->YSF %s)",
+		name, describeGain(ysfPath.gain, target), describeGain(dmrPath.gain, target))
+	return newBreakerTranscoder(name, transcoder), ysfPath, dmrPath
+}
+
+// describeGain describes a gain setting for the startup log
+func describeGain(gain *audio.Gain, target float64) string {
+	switch {
+	case gain.AGC():
+		return fmt.Sprintf("AGC to %.0f dBFS", target)
+	case gain.Active():
+		return fmt.Sprintf("%+.1f dB", gain.DB())
+	default:
+		return "unchanged"
+	}
+}
+
+// processVoice meters the voice of a DMR burst for path, applies its gain
+// and, when toUSRP is set, sends it to USRP with tg
+// Each burst is decoded once, by the voice worker, unless a gain is set: the
+// burst is then decoded, adjusted and re-encoded here, as it is sent on, and
+// the worker is given the adjusted audio for USRP. A transcoder that stops
+// answering costs one timeout before its breaker opens. The level is
+// measured before the gain, so it shows how loud the caller arrived.
+// Calls that came from USRP are not sent back to it.
+func (g *Gateway) processVoice(path *audioPath, burst []byte, tg uint32, toUSRP bool) {
+	toUSRP = toUSRP && g.usrp != nil && !g.usrp.rx
+	if path == nil && !toUSRP {
+		return
+	}
+	frames, err := recorder.ExtractDMRAMBE(burst)
+	if err != nil {
+		return
+	}
+
+	job := voiceJob{frames: frames, usrp: toUSRP, tg: tg}
+	if path != nil {
+		g.collectVoiceLevels()
+		if path.gain.Active() {
+			job.pcm = g.applyGain(path, frames, burst)
+		} else {
+			job.path, job.call = path, path.calls
+		}
+		g.publishAudioLevel(path)
+	}
+	if job.usrp {
+		g.usrp.tx = true
+	}
+	if job.usrp || job.path != nil {
+		g.voice.submit(job)
+	}
+}
+
+// applyGain decodes the frames of burst, meters them for path and applies
+// its gain, re-encoding them in place; it returns the adjusted audio, nil
+// for frames the transcoder could not decode
+// Frames the transcoder cannot encode are left as they are.
+func (g *Gateway) applyGain(path *audioPath, frames [recorder.AMBE_FRAMES_PER_BURST][recorder.AMBE_FRAME_LENGTH]byte, burst []byte) [][]int16 {
+	adjusted := make([][]int16, len(frames))
+	changed := false
+	for i := range frames {
+		pcm, err := g.audioTranscoder.DecodeAMBE(frames[i][:])
+		if err != nil {
+			continue
+		}
+		var level audio.Meter
+		level.Add(pcm)
+		path.add(pcm)
+
+		path.gain.Apply(pcm, level.RMS())
+		adjusted[i] = pcm
+		ambe, err := g.audioTranscoder.EncodeAMBE(pcm)
+		if err != nil || len(ambe) != recorder.AMBE_FRAME_LENGTH {
+			continue
+		}
+		copy(frames[i][:], ambe)
+		changed = true
+	}
+
+	if changed {
+		// Bits 108-155 hold the sync or EMB and are kept
+		insert := recorder.InsertDMRAMBE(frames)
+		copy(burst[:13], insert[:13])
+		burst[13] = insert[13]&0xF0 | burst[13]&0x0F
+		burst[19] = burst[19]&0xF0 | insert[19]&0x0F
+		copy(burst[20:33], insert[20:33])
+	}
+	return adjusted
+}
+
+// collectVoiceLevels meters the bursts the voice worker has decoded; those
+// of calls that have ended are dropped
+func (g *Gateway) collectVoiceLevels() {
+	for {
+		select {
+		case level := <-g.voice.levels:
+			if level.call != level.path.calls {
+				continue
+			}
+			for _, pcm := range level.pcm {
+				if pcm != nil {
+					level.path.add(pcm)
+				}
+			}
+		default:
+			return
+		}
+	}
+}
+
+// add meters the PCM of one frame
+func (p *audioPath) add(pcm []int16) {
+	p.call.Add(pcm)
+	p.live.Add(pcm)
+}
+
+// publishAudioLevel publishes the level of path every audioLevelInterval
+func (g *Gateway) publishAudioLevel(path *audioPath) {
+	if time.Since(path.published) < audioLevelInterval || path.live.Samples() == 0 {
+		return
+	}
+	g.events.Publish(events.Event{
+		Type:   events.AudioLevel,
+		Source: path.direction[:3],
+		Fields: path.levelFields(&path.live),
+	})
+	path.live.Reset()
+	path.published = time.Now()
+}
+
+// levelFields describes the level measured by meter and the current gain
+func (p *audioPath) levelFields(meter *audio.Meter) map[string]string {
+	fields := map[string]string{
+		"direction":  p.direction,
+		"audio_peak": strconv.FormatFloat(meter.Peak(), 'f', 1, 64),
+		"audio_rms":  strconv.FormatFloat(meter.RMS(), 'f', 1, 64),
+	}
+	if p.gain.Active() {
+		fields["audio_gain"] = strconv.FormatFloat(p.gain.DB(), 'f', 1, 64)
+	}
+	return fields
+}
+
+// endAudioCall adds the level of the call ending in direction to its
+// call_end fields and makes the path ready for the next call
+// Bursts the voice worker has not decoded yet are left out.
+func (g *Gateway) endAudioCall(direction string, fields map[string]string) {
+	g.collectVoiceLevels()
+	path := g.ysfAudio
+	if direction == g.dmrAudio.direction {
+		path = g.dmrAudio
+	}
+
+	if path.call.Samples() > 0 {
+		level := path.levelFields(&path.call)
+		for key, value := range level {
+			if key != "direction" {
+				fields[key] = value
+			}
+		}
+		log.Printf("%s audio level: peak %s dBFS, RMS %s dBFS", direction, level["audio_peak"], level["audio_rms"])
+	}
+
+	path.call.Reset()
+	path.live.Reset()
+	path.gain.Reset()
+	path.calls++
+}
@@ -0,0 +1,117 @@
+package gateway
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+	"github.com/dbehnke/ysf2dmr/internal/events"
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/dmr"
+)
+
+// levelTranscoder decodes every frame to a constant level and keeps the
+// audio it is asked to encode
+type levelTranscoder struct {
+	mu      sync.Mutex
+	encoded []int16
+}
+
+var testTranscoder = &levelTranscoder{}
+
+func init() {
+	codec.RegisterTranscoder("gateway-test", func(string) (codec.Transcoder, error) {
+		return testTranscoder, nil
+	})
+}
+
+func (l *levelTranscoder) DecodeAMBE(frame []byte) ([]int16, error) {
+	pcm := make([]int16, codec.PCM_SAMPLES_PER_FRAME)
+	for i := range pcm {
+		pcm[i] = 1000
+	}
+	return pcm, nil
+}
+
+func (l *levelTranscoder) EncodeAMBE(pcm []int16) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.encoded = append(l.encoded, pcm[0])
+	return make([]byte, 9), nil
+}
+
+func (l *levelTranscoder) Close() error { return nil }
+
+func TestDMRCallAudioLevelAndGain(t *testing.T) {
+	testTranscoder.mu.Lock()
+	testTranscoder.encoded = nil
+	testTranscoder.mu.Unlock()
+
+	g, _, dmrNet := startGateway(t, func(b *ConfigBuilder) {
+		b.Set("Audio", "Transcoder", "gateway-test").
+			Set("Audio", "DMRGain", "6.0206")
+	})
+	callEnd := make(chan events.Event, 1)
+	g.events.Subscribe(func(event events.Event) {
+		if event.Type == events.CallEnd {
+			callEnd <- event
+		}
+	})
+
+	injectDMRHeader(dmrNet)
+	bursts := dmr.NewBurstAssembler(1, dmr.BS_SOURCED)
+	bursts.StartVoice(&dmr.LinkControl{FLCO: dmr.FLCO_GROUP_CALL, SourceID: 3109999, DestinationID: 91})
+	for seq := 1; seq <= 6; seq++ {
+		data := protocol.NewDMRData()
+		data.SetSlotNo(2)
+		data.SetSrcId(3109999)
+		data.SetDstId(91)
+		data.SetFLCO(protocol.FLCO_GROUP)
+		data.SetStreamId(0x5678)
+		data.SetSeqNo(uint8(seq))
+		var burst [33]byte
+		if n := bursts.Voice(burst[:]); n == 0 {
+			data.SetDataType(protocol.DT_VOICE_SYNC)
+		} else {
+			data.SetDataType(protocol.DT_VOICE)
+			data.SetN(n)
+		}
+		data.SetData(burst[:])
+		dmrNet.Inject(data)
+	}
+	terminator := protocol.NewDMRData()
+	terminator.SetSlotNo(2)
+	terminator.SetSrcId(3109999)
+	terminator.SetDstId(91)
+	terminator.SetFLCO(protocol.FLCO_GROUP)
+	terminator.SetStreamId(0x5678)
+	terminator.SetSeqNo(7)
+	terminator.SetDataType(protocol.DT_TERMINATOR_WITH_LC)
+	dmrNet.Inject(terminator)
+
+	select {
+	case event := <-callEnd:
+		// 1000 is -30.3 dBFS
+		if event.Fields["audio_rms"] != "-30.3" || event.Fields["audio_peak"] != "-30.3" {
+			t.Errorf("call_end level = %q peak %q RMS, want -30.3 dBFS",
+				event.Fields["audio_peak"], event.Fields["audio_rms"])
+		}
+		if event.Fields["audio_gain"] != "6.0" {
+			t.Errorf("call_end audio_gain = %q, want 6.0", event.Fields["audio_gain"])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("DMR call did not end")
+	}
+
+	testTranscoder.mu.Lock()
+	defer testTranscoder.mu.Unlock()
+	if len(testTranscoder.encoded) != 18 {
+		t.Fatalf("%d frames re-encoded, want 18", len(testTranscoder.encoded))
+	}
+	for _, sample := range testTranscoder.encoded {
+		if sample != 2000 {
+			t.Fatalf("re-encoded sample = %d, want 2000 after 6 dB of gain", sample)
+		}
+	}
+}
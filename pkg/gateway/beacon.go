@@ -0,0 +1,156 @@
+package gateway
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+	"github.com/dbehnke/ysf2dmr/internal/recorder"
+	"github.com/dbehnke/ysf2dmr/internal/state"
+)
+
+// beacon transmits a short identification toward YSF when the DMR master
+// requests one (RPTSBKN) or the local interval expires, as MMDVMHost keys its
+// transmitter for DMR beacons
+// The prompt played after a call is cut off is sent the same way.
+type beacon struct {
+	text  string
+	voice [][]byte // YSF voice payloads converted from the AMBE file
+
+	pending bool     // a beacon is due once the gateway is idle
+	queue   [][]byte // YSF frames still to be sent, one per YSF frame period
+	sent    uint64
+}
+
+// newBeacon prepares the configured beacon text and voice
+// The voice file holds 9-byte AMBE+2 frames, as written by the call recorder.
+func newBeacon(text, voicePath string) (*beacon, error) {
+	b := &beacon{text: text}
+	if voicePath == "" {
+		return b, nil
+	}
+
+	data, err := os.ReadFile(voicePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read beacon voice: %v", err)
+	}
+
+	frameLen := recorder.AMBE_FRAME_LENGTH
+	burstLen := recorder.AMBE_FRAMES_PER_BURST * frameLen
+	if len(data) < burstLen {
+		return nil, fmt.Errorf("beacon voice %s is shorter than one DMR burst", voicePath)
+	}
+
+	converter := codec.NewFrameRatioConverter()
+	for offset := 0; offset+burstLen <= len(data); offset += burstLen {
+		var frames [recorder.AMBE_FRAMES_PER_BURST][recorder.AMBE_FRAME_LENGTH]byte
+		for i := range frames {
+			copy(frames[i][:], data[offset+i*frameLen:])
+		}
+
+		payloads, err := converter.ConvertDMRToYSF(recorder.InsertDMRAMBE(frames))
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert beacon voice: %v", err)
+		}
+		b.voice = append(b.voice, payloads...)
+	}
+
+	return b, nil
+}
+
+// frames builds the YSF frames of one beacon transmission
+// The text message follows the voice; with neither configured the beacon is
+// a header and terminator carrying the gateway callsign.
+func (b *beacon) frames(source string, seqNo uint8) [][]byte {
+	newFrame := func(fi, fn uint8, payload []byte) []byte {
+		f := &ysf.Frame{
+			SourceCallsign: source,
+			DestCallsign:   "ALL",
+			FICH: ysf.FICH{
+				FI: fi,
+				DT: 0, // VD Mode 1
+				FN: fn,
+				FT: 7,
+			},
+			Payload: payload,
+		}
+		return f.Build()
+	}
+
+	var frames [][]byte
+	if len(b.voice) > 0 || b.text == "" {
+		frames = append(frames, newFrame(0, 0, nil))
+		for i, payload := range b.voice {
+			frames = append(frames, newFrame(1, uint8(i%8), payload))
+		}
+		frames = append(frames, newFrame(2, 0, nil))
+	}
+	if b.text != "" {
+		frames = append(frames, ysf.BuildTextMessageFrames(source, "ALL", seqNo, b.text)...)
+	}
+	return frames
+}
+
+// requestBeacon marks a beacon as due
+func (g *Gateway) requestBeacon(reason string) {
+	if g.beacon == nil {
+		return
+	}
+	if !g.beacon.pending && len(g.beacon.queue) == 0 {
+		log.Printf("Beacon requested (%s)", reason)
+	}
+	g.beacon.pending = true
+}
+
+// processBeacon sends the next beacon frame, starting a due beacon when the
+// gateway is idle; a call that starts part way through cancels the rest
+func (g *Gateway) processBeacon() {
+	g.transmit(g.beacon, "Beacon")
+}
+
+// processTimeoutPrompt sends the next frame of the prompt played after a call
+// is cut off, reporting whether one was sent
+func (g *Gateway) processTimeoutPrompt() bool {
+	return g.transmit(g.timeoutPrompt, "Timeout prompt")
+}
+
+// transmit sends the next frame of b toward YSF, starting it when pending and
+// the gateway is idle, and reports whether a frame was sent
+func (g *Gateway) transmit(b *beacon, what string) bool {
+	if b == nil {
+		return false
+	}
+
+	if g.state.CallState() != state.CallIdle {
+		if len(b.queue) > 0 {
+			log.Printf("%s interrupted by call, %d frames dropped", what, len(b.queue))
+			b.queue = nil
+		}
+		return false
+	}
+
+	if len(b.queue) == 0 {
+		if !b.pending {
+			return false
+		}
+		b.pending = false
+		b.queue = b.frames(g.config.GetCallsign(), g.ysfMessageSeqNo)
+		if b.text != "" {
+			g.ysfMessageSeqNo++
+		}
+	}
+
+	if err := g.ysfNetwork.Write(b.queue[0]); err != nil {
+		log.Printf("%s send error: %v", what, err)
+		b.queue = nil
+		return false
+	}
+	b.queue = b.queue[1:]
+	if len(b.queue) == 0 {
+		b.sent++
+		log.Printf("%s sent", what)
+	}
+	return true
+}
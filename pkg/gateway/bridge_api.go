@@ -0,0 +1,46 @@
+package gateway
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/dbehnke/ysf2dmr/internal/events"
+)
+
+// bridgeHandler pauses or resumes bridging on POST /api/bridge/pause and
+// /api/bridge/resume
+// Both links stay up and polls keep flowing; a call in progress finishes.
+func bridgeHandler(g *Gateway, pause bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		g.setPaused(pause, "HTTP")
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]bool{"paused": pause})
+	})
+}
+
+// setPaused pauses or resumes bridging, logging and publishing the change
+func (g *Gateway) setPaused(pause bool, by string) {
+	if !g.state.SetPaused(pause) {
+		return
+	}
+
+	eventType := events.Resumed
+	if pause {
+		eventType = events.Paused
+		log.Printf("Bridging paused (%s); links stay up", by)
+	} else {
+		log.Printf("Bridging resumed (%s)", by)
+	}
+	g.events.Publish(events.Event{
+		Type:   eventType,
+		Source: by,
+	})
+}
@@ -0,0 +1,96 @@
+package gateway
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/database"
+	"github.com/dbehnke/ysf2dmr/internal/events"
+)
+
+// callHistoryQueue is how many finished calls may wait to be written before
+// new ones are dropped
+const callHistoryQueue = 64
+
+// callHistory writes finished calls to the database in the background, so a
+// slow database never delays the main loop
+type callHistory struct {
+	repo  *database.CallRepository
+	queue chan database.Call
+	keep  time.Duration // calls older than this are pruned, 0 keeps all
+}
+
+// newCallHistory creates the call history recorder, keeping calls for
+// keepDays days
+func newCallHistory(repo *database.CallRepository, keepDays uint32) *callHistory {
+	return &callHistory{
+		repo:  repo,
+		queue: make(chan database.Call, callHistoryQueue),
+		keep:  time.Duration(keepDays) * 24 * time.Hour,
+	}
+}
+
+// Handle queues the call of a call end event; it is suitable as an events.Handler
+func (h *callHistory) Handle(event events.Event) {
+	if event.Type != events.CallEnd {
+		return
+	}
+
+	call := database.Call{
+		Direction: event.Fields["direction"],
+		Callsign:  event.Fields["callsign"],
+		Private:   event.Fields["call_type"] == callType(true),
+		Reason:    event.Fields["reason"],
+	}
+	if tg, err := strconv.ParseUint(event.Fields["tg"], 10, 32); err == nil {
+		call.TG = uint32(tg)
+	}
+	if id, err := strconv.ParseUint(event.Fields["id"], 10, 32); err == nil {
+		call.SrcID = uint32(id)
+	}
+	if duration, err := strconv.ParseFloat(event.Fields["duration"], 64); err == nil {
+		call.Duration = duration
+	}
+	call.StartedAt = event.Time.Add(-time.Duration(call.Duration * float64(time.Second))).UTC()
+
+	select {
+	case h.queue <- call:
+	default:
+		log.Printf("Call history: dropped call from %s, %d calls waiting to be written", call.Callsign, cap(h.queue))
+	}
+}
+
+// Run writes queued calls and prunes old ones daily until ctx is cancelled
+func (h *callHistory) Run(ctx context.Context) {
+	prune := time.NewTicker(24 * time.Hour)
+	defer prune.Stop()
+	h.prune()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case call := <-h.queue:
+			if err := h.repo.Record(&call); err != nil {
+				log.Printf("Call history: failed to record call from %s: %v", call.Callsign, err)
+			}
+		case <-prune.C:
+			h.prune()
+		}
+	}
+}
+
+// prune removes calls older than the retention period
+func (h *callHistory) prune() {
+	if h.keep == 0 {
+		return
+	}
+	removed, err := h.repo.DeleteBefore(time.Now().Add(-h.keep))
+	if err != nil {
+		log.Printf("Call history: pruning failed: %v", err)
+	} else if removed > 0 {
+		log.Printf("Call history: removed %d calls older than %v", removed, h.keep)
+	}
+}
@@ -0,0 +1,117 @@
+package gateway
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/database"
+)
+
+// Limits for GET /api/calls and /api/lastheard
+const (
+	defaultCallsLimit = 100
+	maxCallsLimit     = 10000
+)
+
+// callsHandler serves GET /api/calls, the call history, and with lastHeard
+// GET /api/lastheard, the latest call of each callsign
+// Both accept from and to (RFC 3339), callsign, tg and limit, and
+// format=csv downloads the result as a CSV file.
+func callsHandler(repo *database.CallRepository, lastHeard bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		filter, err := parseCallFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		list, name := repo.List, "calls"
+		if lastHeard {
+			list, name = repo.LastHeard, "lastheard"
+		}
+		calls, err := list(filter)
+		if err != nil {
+			http.Error(w, "call history query failed", http.StatusInternalServerError)
+			return
+		}
+		if calls == nil {
+			calls = []database.Call{}
+		}
+
+		if r.URL.Query().Get("format") == "csv" {
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".csv"))
+			writeCallsCSV(w, calls)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(calls)
+	})
+}
+
+// parseCallFilter reads the call history filter from the query string
+func parseCallFilter(r *http.Request) (database.CallFilter, error) {
+	query := r.URL.Query()
+	filter := database.CallFilter{
+		Callsign: query.Get("callsign"),
+		Limit:    defaultCallsLimit,
+	}
+
+	for key, t := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(key); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s, want RFC 3339 e.g. 2026-10-16T19:00:00Z", key)
+			}
+			*t = parsed
+		}
+	}
+	if value := query.Get("tg"); value != "" {
+		tg, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return filter, fmt.Errorf("invalid tg")
+		}
+		filter.TG = uint32(tg)
+	}
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return filter, fmt.Errorf("invalid limit")
+		}
+		filter.Limit = min(n, maxCallsLimit)
+	}
+	return filter, nil
+}
+
+// writeCallsCSV writes calls as CSV with a header row
+func writeCallsCSV(w http.ResponseWriter, calls []database.Call) {
+	out := csv.NewWriter(w)
+	out.Write([]string{"started_at", "duration", "direction", "callsign", "src_id", "tg", "call_type", "reason"})
+	for _, call := range calls {
+		srcID := ""
+		if call.SrcID != 0 {
+			srcID = strconv.FormatUint(uint64(call.SrcID), 10)
+		}
+		out.Write([]string{
+			call.StartedAt.UTC().Format(time.RFC3339),
+			strconv.FormatFloat(call.Duration, 'f', 1, 64),
+			call.Direction,
+			call.Callsign,
+			srcID,
+			strconv.FormatUint(uint64(call.TG), 10),
+			callType(call.Private),
+			call.Reason,
+		})
+	}
+	out.Flush()
+}
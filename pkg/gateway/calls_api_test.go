@@ -0,0 +1,81 @@
+package gateway
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/database"
+	"github.com/dbehnke/ysf2dmr/internal/events"
+)
+
+func TestCallsHandler(t *testing.T) {
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "calls.db")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := database.NewCallRepository(db.GetDB())
+
+	// Record calls the way the gateway does, from call end events
+	history := newCallHistory(repo, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go history.Run(ctx)
+
+	end := time.Date(2026, 10, 16, 19, 0, 0, 0, time.UTC)
+	for i, fields := range []map[string]string{
+		{"callsign": "W1AW", "tg": "3100", "call_type": "group", "direction": "YSF->DMR", "duration": "12.5"},
+		{"callsign": "K1ABC", "tg": "91", "call_type": "group", "direction": "DMR->YSF", "duration": "3.0", "id": "3100002"},
+		{"callsign": "W1AW", "tg": "3100", "call_type": "group", "direction": "YSF->DMR", "duration": "8.0", "reason": "timeout"},
+	} {
+		history.Handle(events.Event{Type: events.CallEnd, Time: end.Add(time.Duration(i) * time.Minute), Fields: fields})
+	}
+	history.Handle(events.Event{Type: events.CallStart, Fields: map[string]string{"callsign": "N0CALL"}})
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if calls, _ := repo.List(database.CallFilter{}); len(calls) == 3 {
+			break
+		}
+	}
+
+	get := func(handler http.Handler, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	rec := get(callsHandler(repo, false), "/api/calls?tg=3100")
+	var calls []database.Call
+	if err := json.Unmarshal(rec.Body.Bytes(), &calls); err != nil {
+		t.Fatalf("GET /api/calls: %v: %s", err, rec.Body)
+	}
+	if len(calls) != 2 || calls[0].Reason != "timeout" || calls[1].Duration != 12.5 {
+		t.Errorf("GET /api/calls?tg=3100 = %+v", calls)
+	}
+	if want := end.Add(-12500 * time.Millisecond); !calls[1].StartedAt.Equal(want) {
+		t.Errorf("first call started at %v, want %v", calls[1].StartedAt, want)
+	}
+
+	rec = get(callsHandler(repo, true), "/api/lastheard?format=csv&from=2026-10-16T18:00:00Z")
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="lastheard.csv"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("GET /api/lastheard CSV: %v", err)
+	}
+	if len(records) != 3 || records[1][3] != "W1AW" || records[2][3] != "K1ABC" || records[2][4] != "3100002" {
+		t.Errorf("GET /api/lastheard CSV = %v", records)
+	}
+
+	for _, target := range []string{"/api/calls?from=yesterday", "/api/calls?tg=ALL", "/api/calls?limit=0"} {
+		if rec := get(callsHandler(repo, false), target); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/capture"
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+	"github.com/dbehnke/ysf2dmr/internal/network"
+)
+
+// enableCapture keeps the last [Capture] Seconds of YSF and DMR packets in
+// memory for a dump when conversions fail
+func (g *Gateway) enableCapture() {
+	window := time.Duration(g.config.GetCaptureSeconds()) * time.Second
+	g.capture = capture.New(window, g.config.GetCaptureDirectory(), g.config.GetLogFileRoot()+"-capture")
+	if capturer, ok := g.ysfNetwork.(network.PacketCapturer); ok {
+		capturer.SetCapture(g.capture)
+	}
+	if capturer, ok := g.dmrNetwork.(network.PacketCapturer); ok {
+		capturer.SetCapture(g.capture)
+	}
+	log.Printf("Keeping the last %v of packets for a capture on conversion errors", window)
+}
+
+// captureConversionError dumps the packets kept on a BPTC decode failure, or
+// once ErrorThreshold conversion errors happen within the capture window
+func (g *Gateway) captureConversionError(err error) {
+	if g.capture == nil {
+		return
+	}
+
+	now := time.Now()
+	if errors.Is(err, codec.ErrBPTCDecode) {
+		g.dumpCapture("BPTC decode failure", now)
+		return
+	}
+
+	recent := g.captureErrors[:0]
+	for _, t := range g.captureErrors {
+		if now.Sub(t) <= g.capture.Window() {
+			recent = append(recent, t)
+		}
+	}
+	g.captureErrors = append(recent, now)
+	if len(g.captureErrors) >= int(g.config.GetCaptureErrorThreshold()) {
+		g.dumpCapture(fmt.Sprintf("%d conversion errors in %v", len(g.captureErrors), g.capture.Window()), now)
+		g.captureErrors = g.captureErrors[:0]
+	}
+}
+
+// dumpCapture writes the packets kept to a pcap file without holding up the
+// main loop
+func (g *Gateway) dumpCapture(reason string, now time.Time) {
+	go func() {
+		path, err := g.capture.Dump(now)
+		switch {
+		case err != nil:
+			log.Printf("Packet capture after %s failed: %v", reason, err)
+		case path != "":
+			log.Printf("Captured the last %v of packets to %s after %s", g.capture.Window(), path, reason)
+		}
+	}()
+}
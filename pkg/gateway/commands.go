@@ -0,0 +1,71 @@
+package gateway
+
+import (
+	"log"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/dtmf"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+)
+
+// initializeCommands creates the parser for DTMF-style commands from YSF
+// radios when they are enabled
+func initializeCommands(cfg *config.Config) *dtmf.Parser {
+	if !cfg.GetCommandsEnabled() {
+		return nil
+	}
+	log.Printf("YSF commands enabled: connect %q, private %q, disconnect %q, select %q (%d presets)",
+		cfg.GetCommandConnect(), cfg.GetCommandPrivate(), cfg.GetCommandDisconnect(),
+		cfg.GetCommandSelect(), len(cfg.GetCommandPresets()))
+	return dtmf.NewParser(dtmf.Triggers{
+		Connect:    cfg.GetCommandConnect(),
+		Private:    cfg.GetCommandPrivate(),
+		Disconnect: cfg.GetCommandDisconnect(),
+		Select:     cfg.GetCommandSelect(),
+		Terminator: cfg.GetCommandTerminator(),
+	})
+}
+
+// ysfHeaderCommand carries out a command given as the destination in the
+// data channel of a YSF header, returning true if there was one
+func (g *Gateway) ysfHeaderCommand(frame *ysf.Frame, source string) bool {
+	if g.commands == nil {
+		return false
+	}
+	dest, _, _, _, ok := ysf.HeaderCallsigns(frame.Payload)
+	return ok && g.runYSFCommand(dest, source)
+}
+
+// runYSFCommand carries out the command in text from source, returning
+// true if text was a command
+func (g *Gateway) runYSFCommand(text, source string) bool {
+	if g.commands == nil {
+		return false
+	}
+	command, ok := g.commands.Parse(text)
+	if !ok {
+		return false
+	}
+
+	by := "YSF command from " + source
+	dstID, private := command.Number, false
+	switch command.Action {
+	case dtmf.Disconnect:
+		dstID = 0
+	case dtmf.Private:
+		private = dstID != 0
+	case dtmf.Select:
+		presets := g.config.GetCommandPresets()
+		if dstID == 0 || int(dstID) > len(presets) {
+			log.Printf("%s: no preset %d (%d configured)", by, dstID, len(presets))
+			return true
+		}
+		dstID = presets[dstID-1]
+	}
+
+	if g.wiresX != nil {
+		g.wiresX.RestoreLink(dstID, nil)
+	}
+	g.setDestination(dstID, private, by)
+	return true
+}
@@ -0,0 +1,52 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+)
+
+func TestYSFHeaderCommand(t *testing.T) {
+	g, ysfNet, dmrNet := startGateway(t, func(b *ConfigBuilder) {
+		b.SetBool("Commands", "Enable", true).
+			Set("Commands", "Presets", "91,3100,9990")
+	})
+
+	command := func(dest string) {
+		for _, fi := range []uint8{0, 2} { // header and terminator
+			frame := &ysf.Frame{
+				SourceCallsign: "N0CALL",
+				DestCallsign:   "ALL",
+				FICH:           ysf.FICH{FI: fi, DT: ysf.DT_VD_MODE2},
+				Payload:        ysf.BuildHeaderPayload(dest, "N0CALL", "", ""),
+			}
+			ysfNet.Inject(frame.Build())
+		}
+	}
+	waitForDestination := func(want uint32, wantPrivate bool) {
+		t.Helper()
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if dstID, private := g.state.Destination(); dstID == want && private == wantPrivate {
+				return
+			}
+		}
+		dstID, private := g.state.Destination()
+		t.Fatalf("destination = %d (private %v), want %d (private %v)", dstID, private, want, wantPrivate)
+	}
+
+	command("*3100#")
+	waitForDestination(3100, false)
+	command("**3109999#")
+	waitForDestination(3109999, true)
+	command("#3#")
+	waitForDestination(9990, false)
+	command("##")
+	waitForDestination(0, false)
+
+	// A command is not bridged as a call
+	if waitForDMR(dmrNet, protocol.DT_VOICE_LC_HEADER) {
+		t.Error("command transmission bridged to DMR")
+	}
+}
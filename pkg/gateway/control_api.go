@@ -0,0 +1,129 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/events"
+)
+
+const (
+	// controlTimeout bounds the wait for the main loop to carry out a
+	// remote control request
+	controlTimeout = 2 * time.Second
+)
+
+// errMainLoopBusy is returned when the main loop does not take a request
+var errMainLoopBusy = errors.New("main loop did not answer")
+
+// destinationRequest is the POST /api/tg body
+type destinationRequest struct {
+	TG      uint32 `json:"tg"`
+	Private bool   `json:"private"`
+}
+
+// destinationHandler links to a talk group, or a private call, on POST
+// /api/tg; TG 0 unlinks
+func destinationHandler(g *Gateway) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req destinationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		private := req.Private && req.TG != 0
+
+		err := g.runOnMainLoop(func() {
+			if g.wiresX != nil {
+				g.wiresX.RestoreLink(req.TG, nil)
+			}
+			g.setDestination(req.TG, private, "HTTP")
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"dst_id": req.TG, "private": private})
+	})
+}
+
+// reconnectHandler logs in to the DMR master again on POST /api/reconnect
+func reconnectHandler(g *Gateway) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		err := g.runOnMainLoop(func() {
+			log.Printf("DMR reconnection requested (HTTP)")
+			g.state.ResetDMRErrors()
+			g.attemptReconnect()
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		up, _ := g.state.DMRLink()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]bool{"dmr_link_up": up})
+	})
+}
+
+// setDestination links to dstID, a private call when private is set and 0 to
+// unlink, keeping it in the state file and publishing the change
+func (g *Gateway) setDestination(dstID uint32, private bool, by string) {
+	g.state.SetDestination(dstID, private)
+	g.saveSession()
+
+	switch {
+	case dstID == 0:
+		log.Printf("%s disconnect", by)
+	case private:
+		log.Printf("%s connect to %s (private call)", by, g.describeDMRUser(dstID))
+	default:
+		log.Printf("%s connect to %s", by, g.formatDMRAddress(dstID, true))
+	}
+	g.events.Publish(events.Event{
+		Type:   events.Destination,
+		Source: by,
+		Fields: map[string]string{
+			"dst_id":  strconv.FormatUint(uint64(dstID), 10),
+			"private": strconv.FormatBool(private),
+		},
+	})
+}
+
+// runOnMainLoop runs fn on the main loop and waits for it to finish
+func (g *Gateway) runOnMainLoop(fn func()) error {
+	done := make(chan struct{})
+	timeout := time.NewTimer(controlTimeout)
+	defer timeout.Stop()
+
+	select {
+	case g.controlRequests <- func() { fn(); close(done) }:
+	case <-timeout.C:
+		return fmt.Errorf("%w within %v", errMainLoopBusy, controlTimeout)
+	}
+	select {
+	case <-done:
+		return nil
+	case <-timeout.C:
+		return fmt.Errorf("%w within %v", errMainLoopBusy, controlTimeout)
+	}
+}
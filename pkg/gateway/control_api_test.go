@@ -0,0 +1,40 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDestinationHandler(t *testing.T) {
+	g, _, _ := startGateway(t)
+	handler := destinationHandler(g)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/tg", strings.NewReader(`{"tg":3126}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /api/tg status = %d: %s", rec.Code, rec.Body)
+	}
+	if dstID, private := g.state.Destination(); dstID != 3126 || private {
+		t.Errorf("destination = %d (private %v), want TG 3126", dstID, private)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/tg", strings.NewReader(`{"tg":0,"private":true}`)))
+	if dstID, private := g.state.Destination(); dstID != 0 || private {
+		t.Errorf("destination = %d (private %v), want unlinked", dstID, private)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/tg", strings.NewReader(`tg=91`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /api/tg with a form body status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tg", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /api/tg status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,80 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/diag"
+)
+
+const (
+	// diagStatsTimeout bounds the wait for the main loop to report its
+	// statistics; a stuck loop is itself worth reporting
+	diagStatsTimeout = 2 * time.Second
+)
+
+// RecentLog keeps the last log lines for diagnostic bundles; they only hold
+// the log when it is also written here, as the ysf2dmr command does
+var RecentLog = diag.NewLogBuffer(diag.DefaultLines)
+
+// NewBundle assembles a diagnostic bundle for configFile
+// stats is called with panics recovered, as it may run on a gateway that has
+// just failed.
+func NewBundle(configFile, version, reason string, stats func() []string) *diag.Bundle {
+	bundle := &diag.Bundle{
+		Reason:  reason,
+		Version: version,
+		Logs:    RecentLog.Lines(),
+	}
+	if data, err := os.ReadFile(configFile); err == nil {
+		bundle.Config = diag.Redact(string(data))
+	} else {
+		bundle.Config = fmt.Sprintf("(%v)", err)
+	}
+	if stats != nil {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					bundle.Stats = []string{fmt.Sprintf("(statistics unavailable: %v)", r)}
+				}
+			}()
+			bundle.Stats = stats()
+		}()
+	}
+	return bundle
+}
+
+// diagHandler serves GET /api/diag with a diagnostic bundle of the running
+// gateway, as saved by -diag
+func diagHandler(g *Gateway) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		bundle := NewBundle(g.config.GetFilename(), g.version, "requested with -diag", g.requestStats)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		bundle.WriteTo(w)
+	})
+}
+
+// requestStats returns the statistics lines from the main loop
+func (g *Gateway) requestStats() []string {
+	reply := make(chan []string, 1)
+	timeout := time.NewTimer(diagStatsTimeout)
+	defer timeout.Stop()
+
+	select {
+	case g.diagRequests <- reply:
+	case <-timeout.C:
+		return []string{fmt.Sprintf("(main loop did not take the request within %v)", diagStatsTimeout)}
+	}
+	select {
+	case stats := <-reply:
+		return stats
+	case <-timeout.C:
+		return []string{fmt.Sprintf("(main loop did not answer within %v)", diagStatsTimeout)}
+	}
+}
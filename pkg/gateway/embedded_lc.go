@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/dbehnke/ysf2dmr/internal/callsign"
+	"github.com/dbehnke/ysf2dmr/internal/events"
+	"github.com/dbehnke/ysf2dmr/internal/network"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/dmr"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+)
+
+// processDMREmbeddedLC handles an LC reassembled from the embedded signalling
+// of the current DMR->YSF call: a talker alias block, the caller's GPS
+// position, or the voice LC, which stands in for the header a late entry
+// missed
+func (g *Gateway) processDMREmbeddedLC(data []byte, src, dst string) {
+	if dmr.IsTalkerAlias(data) {
+		if alias, ok := g.dmrTalkerAlias.Add(data); ok && alias != "" && alias != g.dmrCallAlias {
+			g.setDMRTalkerAlias(alias)
+		}
+		return
+	}
+	if dmr.IsGPSInfo(data) {
+		if pos, ok := dmr.DecodeGPSInfo(data); ok {
+			g.reportDMRPosition(g.state.Call().SrcID, pos, "GPS Info")
+		}
+		return
+	}
+
+	lc := &dmr.LinkControl{}
+	if err := lc.Decode(data); err != nil || !g.dmrLateEntry {
+		return
+	}
+	if lc.FLCO != dmr.FLCO_GROUP_CALL && lc.FLCO != dmr.FLCO_UNIT_TO_UNIT {
+		return
+	}
+	g.dmrLateEntry = false
+
+	log.Printf("DMR: LC recovered from embedded signalling, %d -> %d", lc.SourceID, lc.DestinationID)
+	if lc.IsEmergency() {
+		g.raiseEmergency("DMR", src, dst)
+	}
+}
+
+// checkMasterTalkerAlias takes the talker alias of the current DMR->YSF call
+// from the master's DMRA packets, for masters that send it beside the voice
+// stream instead of, or as well as, in the embedded signalling
+func (g *Gateway) checkMasterTalkerAlias() {
+	reports, ok := g.dmrNetwork.(network.MasterReports)
+	if !ok {
+		return
+	}
+	if alias, ok := reports.TalkerAlias(g.state.Call().SrcID); ok && alias != "" {
+		g.setDMRTalkerAlias(alias)
+	}
+}
+
+// setDMRTalkerAlias records the talker alias of the current DMR->YSF call
+// YSF radios show it in place of the DMR ID of a caller the lookup does not
+// know, and the dashboard gets a talker_alias event.
+func (g *Gateway) setDMRTalkerAlias(alias string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	call := g.state.Call()
+	g.dmrCallAlias = alias
+	log.Printf("DMR talker alias of %s: %q", g.describeDMRUser(call.SrcID), alias)
+
+	if g.dmrCallSource == strconv.FormatUint(uint64(call.SrcID), 10) {
+		if name := callsign.Normalize(alias, g.dmrCallsigns); name != "" {
+			if len(name) > ysf.CALLSIGN_LENGTH {
+				name = name[:ysf.CALLSIGN_LENGTH]
+			}
+			g.dmrCallSource = name
+		}
+	}
+
+	g.events.Publish(events.Event{
+		Type:   events.TalkerAlias,
+		Source: "DMR",
+		Fields: map[string]string{
+			"id":       strconv.FormatUint(uint64(call.SrcID), 10),
+			"callsign": g.callCallsign,
+			"alias":    alias,
+		},
+	})
+}
@@ -0,0 +1,94 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/fastdata"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+)
+
+// initializeFastData creates the fast data store when it is enabled
+func initializeFastData(cfg *config.Config) (*fastdata.Store, error) {
+	if !cfg.GetFastDataEnabled() {
+		return nil, nil
+	}
+
+	store, err := fastdata.New(cfg.GetFastDataDirectory(), int(cfg.GetFastDataMaxFiles()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize fast data store: %v", err)
+	}
+	log.Printf("YSF fast data saved to %s (last %d files)", cfg.GetFastDataDirectory(), cfg.GetFastDataMaxFiles())
+	return store, nil
+}
+
+// processYSFFastData handles a data FR mode transmission that is neither a
+// text message nor a WiresX command, such as a picture: it cannot be bridged
+// to DMR, so it is logged and kept when the store is enabled
+func (g *Gateway) processYSFFastData(frame *ysf.Frame, source string) {
+	if frame.IsHeader() {
+		g.ysfDataMessage = false
+	}
+	data, ok := g.ysfFastData.Add(frame)
+	if !ok || g.ysfDataMessage {
+		return
+	}
+
+	kind := "data"
+	if _, isPicture := fastdata.ExtractJPEG(data); isPicture {
+		kind = "picture"
+	}
+	log.Printf("YSF fast data %s from %s: %d bytes, not bridged to DMR", kind, source, len(data))
+	if g.ysfFastData.Truncated() {
+		log.Printf("YSF fast data from %s cut off at %d bytes", source, ysf.FAST_DATA_MAX_LENGTH)
+	}
+	if g.fastData == nil {
+		return
+	}
+
+	item, err := g.fastData.Save(source, time.Now(), data)
+	if err != nil {
+		log.Printf("YSF fast data: %v", err)
+		return
+	}
+	log.Printf("YSF fast data saved as %s", item.Name)
+}
+
+// fastDataHandler serves GET /api/fastdata, the stored transmissions newest
+// first, and GET /api/fastdata/<name>, one of the files
+func fastDataHandler(store *fastdata.Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/fastdata"), "/")
+		if name == "" {
+			items, err := store.List()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if items == nil {
+				items = []fastdata.Item{}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(items)
+			return
+		}
+
+		path, ok := store.Path(name)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, path)
+	})
+}
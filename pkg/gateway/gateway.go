@@ -0,0 +1,3046 @@
+// Package gateway bridges a YSF network to a DMR master
+// New builds a Gateway from a Config and Run bridges until its context is
+// cancelled; options replace the networks, so the gateway can be embedded in
+// other programs or driven by in-memory networks in tests.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/aprs"
+	"github.com/dbehnke/ysf2dmr/internal/blocklist"
+	"github.com/dbehnke/ysf2dmr/internal/callsign"
+	"github.com/dbehnke/ysf2dmr/internal/capture"
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/database"
+	"github.com/dbehnke/ysf2dmr/internal/dtmf"
+	"github.com/dbehnke/ysf2dmr/internal/events"
+	"github.com/dbehnke/ysf2dmr/internal/fastdata"
+	"github.com/dbehnke/ysf2dmr/internal/hooks"
+	"github.com/dbehnke/ysf2dmr/internal/hosts"
+	"github.com/dbehnke/ysf2dmr/internal/i18n"
+	"github.com/dbehnke/ysf2dmr/internal/latency"
+	"github.com/dbehnke/ysf2dmr/internal/lookup"
+	"github.com/dbehnke/ysf2dmr/internal/network"
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/dmr"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+	"github.com/dbehnke/ysf2dmr/internal/radioid"
+	"github.com/dbehnke/ysf2dmr/internal/recorder"
+	"github.com/dbehnke/ysf2dmr/internal/rewrite"
+	"github.com/dbehnke/ysf2dmr/internal/scheduler"
+	"github.com/dbehnke/ysf2dmr/internal/state"
+	"github.com/dbehnke/ysf2dmr/internal/trace"
+	"github.com/dbehnke/ysf2dmr/internal/version"
+	"github.com/dbehnke/ysf2dmr/internal/web"
+	"github.com/dbehnke/ysf2dmr/internal/wiresx"
+)
+
+const (
+	NETWORK_CLOCK_PER = 10 * time.Millisecond // Network Clock() and read period
+)
+
+// Version is reported to the DMR master and in logs unless WithVersion is
+// used: the release, commit and build date of the running build
+var Version = version.Get().String()
+
+// callHang holds a talkgroup for a while after a call ends, so that DMR
+// traffic on other talkgroups does not cut into the conversation
+type callHang struct {
+	name     string
+	duration time.Duration // zero disables the hang
+	until    time.Time
+	tg       uint32
+}
+
+// start holds tg for the hang duration from now
+func (h *callHang) start(now time.Time, tg uint32) {
+	if h.duration <= 0 {
+		return
+	}
+	h.until = now.Add(h.duration)
+	h.tg = tg
+}
+
+// active reports whether the hang is still running at now
+func (h *callHang) active(now time.Time) bool {
+	return !h.until.IsZero() && now.Before(h.until)
+}
+
+// stop cancels the hang
+func (h *callHang) stop() {
+	h.until = time.Time{}
+}
+
+// Gateway represents the YSF2DMR gateway
+type Gateway struct {
+	config        *config.Config
+	version       string
+	started       time.Time
+	wiresX        *wiresx.WiresX
+	codec         *codec.AMBEConverter
+	ysfNetwork    network.YSFNetworkInterface
+	dmrNetwork    network.DMRNetworkInterface
+	dmrOutput     *network.OutputQueue        // frames toward dmrNetwork, held while it reconnects
+	dmrSequencers [3]*network.StreamSequencer // per slot, index 0 unused
+	dmrBursts     *dmr.BurstAssembler         // sync, EMB and slot type of bursts sent to DMR
+	dmrLookup     lookup.DMRLookupInterface   // Can be file-based or database-backed
+	running       bool
+	mu            sync.RWMutex
+
+	// Database components (when database mode is enabled)
+	db              *database.DB
+	syncer          *radioid.Syncer
+	idLookup        *radioid.IDLookup  // nil unless OnDemandLookup is enabled
+	dmrUserFound    chan uint32        // IDs found by idLookup, handled on the main loop
+	diagRequests    chan chan []string // /api/diag requests for statsLines, answered on the main loop
+	controlRequests chan func()        // remote control requests, run on the main loop
+	dbMaintaining   int32              // Set while a maintenance run is in progress
+
+	// Advanced codec chain with error correction and timing
+	frameRatioConverter *codec.FrameRatioConverter
+	repack              *codec.RepackConverter // VD mode 2 passthrough, nil when disabled
+
+	// Time voice frames spend in the gateway, from network read to network write
+	ysfLatency   *latency.Tracker // YSF->DMR
+	dmrLatency   *latency.Tracker // DMR->YSF
+	dmrExtractor *codec.DMRAMBEExtractor
+	dmrQuality   *codec.LinkQuality // audio of the current DMR->YSF call
+
+	// DMR data calls (SMS/GPS) are reassembled here and never reach the codec chain
+	dmrDataCall   *dmr.DataCallAssembler
+	dmrDataFrames uint32
+
+	// YSF data FR mode messages, bridged to and from DMR SMS
+	ysfDataAssembler *ysf.DataAssembler
+	ysfMessageSeqNo  uint8
+
+	// Other YSF data FR mode transmissions, such as pictures, are kept instead
+	ysfFastData    *ysf.FastDataAssembler
+	ysfDataMessage bool            // the current transmission carried a text message or WiresX command
+	fastData       *fastdata.Store // nil when fast data is not kept
+
+	// Voice bit errors of the current YSF call; the BER of the last frame is
+	// forwarded in DMRD packets
+	ysfBER     uint8 // percent
+	ysfErrors  uint32
+	ysfChecked uint32
+
+	// Beacon sent toward YSF (nil when disabled)
+	beacon *beacon
+
+	// Calls running longer than this are cut off (0 disables), followed by
+	// the timeout prompt toward YSF when one is configured
+	maxCallDuration time.Duration
+	timeoutPrompt   *beacon
+	callEndReason   string // published with the call end, e.g. "timeout"
+
+	// Frames of the WiresX reply or text message being sent toward YSF, one
+	// per YSF frame period, and the text messages waiting their turn
+	ysfDataTX   [][]byte
+	ysfDataText [][]byte // every frame of the text message in ysfDataTX
+	ysfTexts    [][][]byte
+
+	// Bursts of the SMS being sent toward DMR, one per DMR frame period, and
+	// the SMS waiting their turn
+	dmrSMS     *dmrSMS
+	dmrSMSNext int
+	dmrSMSList []*dmrSMS
+
+	// Gateway events (emergency calls are published with high priority)
+	events *events.Bus
+
+	// Details of the current call for call end events
+	callStart     time.Time
+	callCallsign  string
+	callDirection string
+	callTG        uint32
+	callPrivate   bool   // callTG is a user ID
+	callSrcID     uint32 // DMR ID of the caller, 0 when unknown
+
+	// Text in the configured language for WiresX replies, logs and prompts
+	catalog *i18n.Catalog
+
+	// Call history for /api/calls and /api/lastheard (nil without a database)
+	callHistory *callHistory
+
+	// Operator hook scripts run on gateway events (nil when none configured)
+	hooks *hooks.Runner
+
+	// HTTP server with the /ws live event stream (nil when disabled)
+	web *web.Server
+
+	// -trace packet trace (nil when disabled)
+	tracer *trace.Tracer
+
+	// Recent packets dumped on conversion errors (nil when disabled)
+	capture       *capture.Ring
+	captureErrors []time.Time // conversion errors within the capture window
+
+	// Main loop progress for /healthz
+	heartbeat web.Heartbeat
+
+	// Per-call recording (nil when disabled)
+	recorder  *recorder.Recorder
+	recording *recorder.Recording
+
+	// Conversion state
+	ysfFrames   uint32
+	dmrFrames   uint32
+	ysfVWFrames uint32 // Voice FR frames dropped (no IMBE transcoder)
+	ysfVWLogged bool   // Unsupported mode already reported for this call
+
+	// Frames of the current YSF call that did not reach the DMR network
+	ysfCallDropped uint32
+	ysfCallAborted bool // OutputAbort or MaxCallDuration ended the call; ignore it until the next header
+
+	// YSF radios refused by radio ID; a blocked call is ignored up to the next header
+	radioIDs       *blocklist.List
+	ysfCallBlocked bool
+	ysfBlocked     uint32 // calls refused
+
+	// The current YSF call started while bridging was paused or inhibited
+	ysfCallHeld bool
+
+	// Daily maintenance window pausing the bridge (nil when disabled)
+	maintenance *maintenanceWindow
+
+	// DTMF-style commands from YSF radios without WiresX (nil when disabled);
+	// a transmission carrying one is not bridged
+	commands       *dtmf.Parser
+	ysfCommandCall bool
+
+	// Talk groups and private call IDs as YSF users know them are rewritten
+	// to the master's numbering, and back
+	rewriter *rewrite.Rewriter
+
+	// Group calls to muted talk groups are counted but never reach YSF
+	mutedTGs       map[uint32]bool
+	mutedStream    uint32 // muted call in progress, logged once
+	dmrMutedCalls  uint32
+	dmrMutedFrames uint32
+
+	// FICH fields of frames sent toward YSF
+	ysfFICH ysf.FICH
+
+	// Voice level metering and gain for each direction (nil without a
+	// transcoder); the transcoder applies the gain
+	audioTranscoder codec.Transcoder
+	ysfAudio        *audioPath // YSF->DMR
+	dmrAudio        *audioPath // DMR->YSF
+
+	// PCM audio toward Analog_Bridge or AllStar (nil unless [USRP] is enabled)
+	usrp *usrpBridge
+
+	// Transcodes for metering and USRP off the main loop (nil when neither
+	// is enabled)
+	voice *voiceWorker
+
+	// Positions reported by DMR radios, forwarded to APRS-IS (nil when disabled)
+	aprs         *aprs.Client
+	aprsReported map[uint32]time.Time // last position logged for each DMR ID
+
+	// Callsign clean-up for YSF radios and for looked-up DMR users
+	ysfCallsigns callsign.Options
+	dmrCallsigns callsign.Options
+
+	// YSF network packets received, by type (only data frames are parsed)
+	ysfPackets [ysf.PacketTypeCount]uint64
+
+	// Network state
+	networkWatchdog time.Time
+	ysfWatch        time.Time
+	dmrWatch        time.Time
+
+	// Current call, YSF->DMR destination, DMR link status and network error
+	// counts; safe to read from timers and HTTP handlers
+	state *state.State
+
+	dmrCallDstID   uint32 // destination of the current DMR->YSF call
+	dmrCallPrivate bool   // the current DMR->YSF call is a private call to dmrCallDstID
+	dmrCallSource  string // YSF source callsign of the current DMR->YSF call
+	dmrCallSlot    uint8
+	dmrCallAlias   string // talker alias sent by the DMR caller
+	dmrLateEntry   bool   // the current DMR->YSF call started without its voice LC header
+	dmrEmbeddedLC  *dmr.EmbeddedLCDecoder
+	dmrTalkerAlias dmr.TalkerAlias
+	dmrEndedStream uint32   // last DMR->YSF stream ended, so its stragglers do not restart it
+	heldStream     uint32   // DMR stream dropped because of a hang or TX inhibit
+	rfHang         callHang // after a YSF->DMR call
+	netHang        callHang // after a DMR->YSF call
+
+	// Dead air after our own transmission before a call the other way is
+	// bridged, so the far end's courtesy tone is not doubled; tg is unused
+	rfInhibit  callHang // after a YSF->DMR call, DMR->YSF calls wait
+	netInhibit callHang // after a DMR->YSF call, YSF->DMR calls wait
+
+	// A call from one network during a call from the other takes over or
+	// waits according to the interrupt policy
+	arbiter          *callArbiter
+	ysfCallWaiting   bool   // a YSF call is waiting for a DMR call to end
+	dmrWaitingStream uint32 // DMR stream waiting for a YSF call to end
+
+	// Periodic work (frame timing, network Clock() calls, stats)
+	scheduler *scheduler.Scheduler
+
+	// Host and TG file downloads (nil when no URL is configured); updated
+	// files are reloaded on the main loop
+	hosts        *hosts.Fetcher
+	hostsUpdated chan hosts.File
+
+	// Network error recovery; the timer only signals dmrReconnect so the
+	// reconnection runs on the main loop, which owns the DMR network
+	dmrReconnectTimer *time.Timer
+	dmrReconnect      chan struct{}
+
+	// The YSF socket is reopened when the peer stops polling, moving to the
+	// next destination when a secondary is configured
+	ysfReconnectTimer *time.Timer
+	ysfReconnect      chan struct{}
+	ysfTargets        []ysfTarget
+	ysfTargetIndex    int // destination in use
+}
+
+// ysfTarget is a configured YSF destination, resolved when it is used
+type ysfTarget struct {
+	address string
+	port    int
+}
+
+// maxQueuedMessages is how many bridged text messages may wait in each
+// direction for the channel to be free
+const maxQueuedMessages = 16
+
+// dmrSMS is an SMS waiting to be sent toward DMR: its data header and rate
+// 1/2 blocks, to dstID on slot as used on the master
+type dmrSMS struct {
+	bursts []dmrDataBurst
+	slot   uint8
+	dstID  uint32
+	group  bool
+}
+
+// dmrDataBurst is the data type and payload of one burst of a dmrSMS
+type dmrDataBurst struct {
+	dataType uint8
+	payload  []byte
+}
+
+// Define DMR slot constants
+const (
+	DMR_SLOT_1 = 1
+	DMR_SLOT_2 = 2
+
+	// Network error recovery constants
+	DMR_RECONNECT_INTERVAL   = 30 * time.Second
+	YSF_RECONNECT_INTERVAL   = 15 * time.Second
+	DMR_CONNECTION_CHECK     = 60 * time.Second
+	MAX_NETWORK_ERRORS       = 5
+	NETWORK_ERROR_RESET_TIME = 5 * time.Minute
+
+	// Keepalives: the DMR master answers a ping every DMR_RETRY_TIMEOUT and
+	// the YSF peer polls as often as we do
+	DMR_PING_INTERVAL = protocol.DMR_RETRY_TIMEOUT * time.Millisecond
+	YSF_POLL_INTERVAL = 5 * time.Second
+)
+
+// New creates a gateway for cfg
+// The networks are opened by Run.
+func New(cfg *Config, opts ...Option) (*Gateway, error) {
+	o := options{version: Version}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ysfCallsigns, err := callsign.ParseOptions(cfg.GetYSFNormalizeCallsign())
+	if err != nil {
+		return nil, fmt.Errorf("invalid [YSF Network] NormalizeCallsign: %v", err)
+	}
+	dmrCallsigns, err := callsign.ParseOptions(cfg.GetDMRNormalizeCallsign())
+	if err != nil {
+		return nil, fmt.Errorf("invalid [DMR Network] NormalizeCallsign: %v", err)
+	}
+
+	thresholds, err := qualityThresholds(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := i18n.New(cfg.GetLanguage())
+	if err != nil {
+		return nil, fmt.Errorf("invalid [Info] Language: %v", err)
+	}
+
+	// Initialize codec converter
+	ambeCodec := codec.NewAMBEConverter()
+
+	// Initialize advanced codec chain with error correction and timing
+	frameRatioConverter := codec.NewFrameRatioConverter()
+	var repack *codec.RepackConverter
+	if cfg.GetYSFPassthrough() {
+		repack = codec.NewRepackConverter()
+	}
+	dmrExtractor := codec.NewDMRAMBEExtractor()
+
+	ysfNet := o.ysfNetwork
+	if ysfNet == nil {
+		if ysfNet, err = newYSFNetwork(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	// Set destination for outgoing YSF packets
+	err = ysfNet.SetDestinationByString(cfg.GetDstAddress(), int(cfg.GetDstPort()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set YSF destination: %v", err)
+	}
+
+	// Initialize DMR Network (Homebrew or OpenBridge)
+	dmrNet := o.dmrNetwork
+	if dmrNet == nil {
+		protocolNet, err := NewDMRNetwork(cfg, o.version)
+		if err != nil {
+			return nil, err
+		}
+		// The client handles the master's packets on its goroutine as they
+		// arrive; the network clock only takes the frames it delivered
+		dmrNet = network.NewDMRClient(protocolNet, cfg.GetDMRNetworkDebug())
+	}
+
+	// Download the host and TG files before anything reads them; failures
+	// fall back to the cached copies
+	fetcher := newHostsFetcher(cfg)
+	if fetcher != nil {
+		fetcher.FetchAll(context.Background())
+	}
+
+	// Initialize WiresX if enabled
+	var wx *wiresx.WiresX
+	if cfg.GetEnableWiresX() {
+		wx = wiresx.NewWiresX(
+			cfg.GetCallsign(),
+			cfg.GetSuffix(),
+			cfg.GetDMRTGListFile(),
+			cfg.GetWiresXMakeUpper(),
+		)
+		wx.SetRepeaterID(cfg.GetRepeaterID())
+		wx.SetInfo(
+			cfg.GetDescription(),
+			cfg.GetTxFrequency(),
+			cfg.GetRxFrequency(),
+			cfg.GetDMRDstId(),
+		)
+		description := cfg.GetWiresXDescription()
+		if description == "" {
+			description = catalog.Text(i18n.WiresXDescription)
+		}
+		wx.SetLabels(cfg.GetWiresXTGPrefix(), description)
+		wx.SetNames(catalog.Text(i18n.WiresXLocal), catalog.Text(i18n.WiresXParrot), catalog.Text(i18n.WiresXUnlink))
+		wx.SetNodeModule(cfg.GetNodeModule())
+	}
+
+	// Initialize DMR Lookup (database-backed or file-based)
+	dmrLookup, db, syncer, idLookup := initializeDMRLookup(cfg)
+	if dmrLookup != nil && cfg.GetDMRHotspotIDs() {
+		dmrLookup = lookup.NewHotspotLookup(dmrLookup)
+	}
+	if wx != nil && dmrLookup != nil && cfg.GetWiresXUserSearch() != "" {
+		wx.SetUserSearch(cfg.GetWiresXUserSearch(), func(prefix string, limit int) []wiresx.User {
+			var users []wiresx.User
+			for _, user := range dmrLookup.SearchCallsign(prefix, limit) {
+				users = append(users, wiresx.User{ID: user.ID, Callsign: user.Callsign, Name: user.Name})
+			}
+			return users
+		})
+	}
+
+	// Initialize call recorder if enabled
+	fastDataStore, err := initializeFastData(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	callRecorder, err := initializeRecorder(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize beacon if enabled
+	gatewayBeacon, err := initializeBeacon(cfg, catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	var timeoutPrompt *beacon
+	if cfg.GetMaxCallDuration() > 0 && cfg.GetTimeoutPrompt() != "" {
+		if timeoutPrompt, err = newBeacon("", catalog.Prompt(cfg.GetTimeoutPrompt())); err != nil {
+			return nil, fmt.Errorf("failed to load the timeout prompt: %v", err)
+		}
+	}
+
+	rewriter, err := initializeRewriter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	maintenance, err := initializeMaintenance(cfg, catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	audioTranscoder, ysfAudio, dmrAudio := initializeAudio(cfg)
+
+	usrpBridge, err := initializeUSRP(cfg)
+	if err != nil {
+		return nil, err
+	}
+	voice, err := newVoiceWorker(cfg, audioTranscoder != nil, usrpBridge)
+	if err != nil {
+		return nil, err
+	}
+
+	// The config only accepts valid policies
+	dropPolicy, _ := network.ParseDropPolicy(cfg.GetDMROutputDropPolicy())
+	dmrOutput := network.NewOutputQueue(dmrNet, int(cfg.GetDMROutputQueue()), int(cfg.GetDMROutputMaxAge()), dropPolicy)
+	syncSource, _ := dmr.ParseSyncSource(cfg.GetDMRSyncSource())
+
+	now := time.Now()
+	gateway := &Gateway{
+		config:       cfg,
+		version:      o.version,
+		started:      now,
+		wiresX:       wx,
+		codec:        ambeCodec,
+		ysfNetwork:   ysfNet,
+		dmrNetwork:   dmrNet,
+		dmrOutput:    dmrOutput,
+		radioIDs:     blocklist.New(cfg.GetBlockRadioIDs(), cfg.GetAllowRadioIDs()),
+		ysfCallsigns: ysfCallsigns,
+		dmrCallsigns: dmrCallsigns,
+		dmrSequencers: [3]*network.StreamSequencer{
+			1: network.NewStreamSequencer(network.DEFAULT_REORDER_WINDOW),
+			2: network.NewStreamSequencer(network.DEFAULT_REORDER_WINDOW),
+		},
+		dmrBursts:           dmr.NewBurstAssembler(cfg.GetDMRColorCode(), syncSource),
+		dmrEmbeddedLC:       dmr.NewEmbeddedLCDecoder(),
+		dmrLookup:           dmrLookup,
+		db:                  db,
+		syncer:              syncer,
+		idLookup:            idLookup,
+		dmrUserFound:        make(chan uint32, 4),
+		diagRequests:        make(chan chan []string),
+		controlRequests:     make(chan func()),
+		frameRatioConverter: frameRatioConverter,
+		repack:              repack,
+		ysfLatency:          latency.NewTracker(),
+		dmrLatency:          latency.NewTracker(),
+		dmrExtractor:        dmrExtractor,
+		dmrQuality:          codec.NewLinkQuality(thresholds),
+		dmrDataCall:         dmr.NewDataCallAssembler(),
+		ysfDataAssembler:    ysf.NewDataAssembler(),
+		ysfFastData:         ysf.NewFastDataAssembler(),
+		fastData:            fastDataStore,
+		events:              events.NewBus(),
+		recorder:            callRecorder,
+		beacon:              gatewayBeacon,
+		maxCallDuration:     time.Duration(cfg.GetMaxCallDuration()) * time.Second,
+		timeoutPrompt:       timeoutPrompt,
+		hooks:               initializeHooks(cfg),
+		networkWatchdog:     now,
+		ysfWatch:            now,
+		dmrWatch:            now,
+		rfHang:              callHang{name: "RF", duration: time.Duration(cfg.GetRFHangTime()) * time.Millisecond},
+		netHang:             callHang{name: "Net", duration: time.Duration(cfg.GetNetHangTime()) * time.Millisecond},
+		arbiter:             newCallArbiter(cfg),
+		ysfFICH:             newYSFFICH(cfg),
+		mutedTGs:            newMutedTGs(cfg),
+		aprs:                initializeAPRS(cfg, o.version),
+		commands:            initializeCommands(cfg),
+		maintenance:         maintenance,
+		audioTranscoder:     audioTranscoder,
+		usrp:                usrpBridge,
+		voice:               voice,
+		ysfAudio:            ysfAudio,
+		dmrAudio:            dmrAudio,
+		aprsReported:        make(map[uint32]time.Time),
+		catalog:             catalog,
+		rewriter:            rewriter,
+		rfInhibit:           callHang{name: "RF TX inhibit", duration: time.Duration(cfg.GetRFTXInhibit()) * time.Millisecond},
+		netInhibit:          callHang{name: "Net TX inhibit", duration: time.Duration(cfg.GetNetTXInhibit()) * time.Millisecond},
+		state:               state.New(cfg.GetDMRDstId(), now), // Default destination
+		dmrReconnect:        make(chan struct{}, 1),
+		ysfReconnect:        make(chan struct{}, 1),
+		ysfTargets:          []ysfTarget{{cfg.GetDstAddress(), int(cfg.GetDstPort())}},
+		hosts:               fetcher,
+		hostsUpdated:        make(chan hosts.File, 4),
+	}
+
+	if address := cfg.GetSecondaryDstAddress(); address != "" {
+		gateway.ysfTargets = append(gateway.ysfTargets, ysfTarget{address, int(cfg.GetSecondaryDstPort())})
+	}
+
+	if gateway.hooks != nil {
+		gateway.events.Subscribe(gateway.hooks.Handle)
+	}
+	if db != nil {
+		gateway.callHistory = newCallHistory(database.NewCallRepository(db.GetDB()), cfg.GetDatabaseCallHistoryDays())
+		gateway.events.Subscribe(gateway.callHistory.Handle)
+	}
+	if cfg.GetCaptureEnabled() {
+		gateway.enableCapture()
+	}
+	if cfg.GetDMRRestoreDestination() {
+		gateway.restoreSession()
+	}
+
+	if cfg.GetHTTPEnabled() {
+		gateway.web = web.NewServer(cfg.GetHTTPAddress(), gateway.events)
+		if syncer != nil {
+			gateway.web.AddHealthCheck("radioid_sync", func() (interface{}, bool) {
+				return syncer.Status(), syncer.Healthy()
+			})
+			gateway.web.Handle("/api/sync-users", syncUsersHandler(syncer))
+		}
+		if dmrLookup != nil {
+			gateway.web.Handle("/api/users", userSearchHandler(dmrLookup))
+		}
+		if gateway.callHistory != nil {
+			gateway.web.Handle("/api/calls", callsHandler(gateway.callHistory.repo, false))
+			gateway.web.Handle("/api/lastheard", callsHandler(gateway.callHistory.repo, true))
+		}
+		gateway.web.Handle("/api/latency", latencyHandler(gateway.ysfLatency, gateway.dmrLatency))
+		gateway.web.Handle("/api/state", stateHandler(gateway.state))
+		gateway.web.Handle("/status", statusHandler(gateway.version, gateway.started))
+		gateway.web.Handle("/api/bridge/pause", bridgeHandler(gateway, true))
+		gateway.web.Handle("/api/bridge/resume", bridgeHandler(gateway, false))
+		gateway.web.Handle("/api/diag", diagHandler(gateway))
+		gateway.web.Handle("/api/tg", destinationHandler(gateway))
+		gateway.web.Handle("/api/reconnect", reconnectHandler(gateway))
+		if fastDataStore != nil {
+			gateway.web.Handle("/api/fastdata", fastDataHandler(fastDataStore))
+			gateway.web.Handle("/api/fastdata/", fastDataHandler(fastDataStore))
+		}
+		gateway.web.SetLiveness(&gateway.heartbeat, time.Duration(cfg.GetHTTPLivenessTimeout())*time.Second)
+		gateway.addReadinessChecks()
+		if fetcher != nil {
+			gateway.web.AddHealthCheck("hosts", func() (interface{}, bool) {
+				statuses := fetcher.StatusAll()
+				for _, status := range statuses {
+					if !status.Cached {
+						return statuses, false
+					}
+				}
+				return statuses, true
+			})
+		}
+	}
+
+	return gateway, nil
+}
+
+// newYSFNetwork creates the YSF network in server mode, listening for the
+// YSF reflector or radio
+func newYSFNetwork(cfg *config.Config) (*network.YSFNetwork, error) {
+	ysfNet := network.NewYSFNetworkServer(
+		cfg.GetLocalAddress(),
+		int(cfg.GetLocalPort()),
+		cfg.GetCallsign(),
+		cfg.GetYSFDebug(),
+	)
+
+	// Without an allowed-peer list or secret only the destination is accepted
+	if cfg.GetYSFAllowedPeers() != "" || cfg.GetYSFPeerSecret() != "" {
+		peers, err := network.NewYSFPeerFilter(cfg.GetYSFAllowedPeers(), cfg.GetYSFPeerSecret())
+		if err != nil {
+			return nil, fmt.Errorf("invalid [YSF Network] AllowedPeers: %v", err)
+		}
+		ysfNet.SetPeerFilter(peers)
+	}
+	ysfNet.SetPeerTracking(cfg.GetYSFTrackPeer())
+	return ysfNet, nil
+}
+
+// newHostsFetcher creates the downloader for the files in the [Hosts] section,
+// or nil when no URL is set
+func newHostsFetcher(cfg *config.Config) *hosts.Fetcher {
+	fetcher := hosts.New(hosts.Config{
+		Files: []hosts.File{
+			{Name: hostsDMR, URL: cfg.GetHostsDMRURL(), Path: cfg.GetHostsDMRFile()},
+			{Name: hostsXLX, URL: cfg.GetHostsXLXURL(), Path: cfg.GetDMRXLXFile()},
+			{Name: hostsTGList, URL: cfg.GetHostsTGListURL(), Path: cfg.GetDMRTGListFile()},
+		},
+		Interval: time.Duration(cfg.GetHostsInterval()) * time.Hour,
+	}, log.Default())
+	if len(fetcher.Files()) == 0 {
+		return nil
+	}
+	return fetcher
+}
+
+// Names of the downloaded host files
+const (
+	hostsDMR    = "DMRHosts"
+	hostsXLX    = "XLXHosts"
+	hostsTGList = "TGList"
+)
+
+// reloadHostFile picks up a downloaded file that has changed
+// Only the TG list is read after startup; the WiresX room list follows it.
+func (g *Gateway) reloadHostFile(file hosts.File) {
+	if file.Name != hostsTGList || g.wiresX == nil {
+		return
+	}
+	if err := g.wiresX.ReloadTalkGroups(file.Path); err != nil {
+		log.Printf("WiresX TG list reload failed: %v", err)
+		return
+	}
+	log.Printf("WiresX TG list reloaded from %s", file.Path)
+}
+
+// NewDMRNetwork creates the DMR network selected by the DMR Network Protocol key
+// reporting version to the master; it connects once opened and clocked
+func NewDMRNetwork(cfg *config.Config, version string) (network.DMRNetworkInterface, error) {
+	options, err := DMRNetworkOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := DMRProxy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.GetDMRNetworkProtocol() {
+	case network.DMRProtocolOpenBridge:
+		obpNet, err := network.NewOpenBridgeNetwork(
+			cfg.GetDMRNetworkAddress(),
+			int(cfg.GetDMRNetworkPort()),
+			cfg.GetDMRNetworkLocal(),
+			cfg.GetDMRId(),              // Network ID
+			cfg.GetDMRNetworkPassword(), // HMAC passphrase
+			cfg.GetDMRNetworkDebug(),
+			int(cfg.GetDMRNetworkJitter()),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OpenBridge network: %v", err)
+		}
+		obpNet.SetBindAddress(cfg.GetDMRBindAddress())
+		obpNet.SetProxy(proxy)
+		return obpNet, nil
+
+	case network.DMRProtocolDMRGateway:
+		gwNet, err := network.NewDMRGatewayNetwork(
+			cfg.GetDMRNetworkAddress(),
+			int(cfg.GetDMRNetworkPort()),
+			cfg.GetDMRNetworkLocal(), // DMRGateway's RptPort
+			cfg.GetDMRId(),
+			version,
+			cfg.GetDMRNetworkDebug(),
+			int(cfg.GetDMRNetworkJitter()),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create DMRGateway network: %v", err)
+		}
+		gwNet.SetConfig(
+			cfg.GetCallsign(),
+			cfg.GetRxFrequency(),
+			cfg.GetTxFrequency(),
+			cfg.GetPower(),
+			uint32(cfg.GetDMRColorCode()),
+			float32(cfg.GetLatitude()),
+			float32(cfg.GetLongitude()),
+			int(cfg.GetHeight()),
+			cfg.GetLocation(),
+			cfg.GetDescription(),
+			cfg.GetURL(),
+		)
+		gwNet.SetOptions(options)
+		gwNet.SetTGOffset(cfg.GetDMRGatewayTGOffset())
+		gwNet.SetBindAddress(cfg.GetDMRBindAddress())
+		return gwNet, nil
+
+	case network.DMRProtocolHomebrew, "":
+		// Homebrew (MMDVM) master, created below
+
+	default:
+		return nil, fmt.Errorf("unknown DMR network protocol: %s", cfg.GetDMRNetworkProtocol())
+	}
+
+	dmrNet, err := network.NewDMRNetwork(
+		cfg.GetDMRNetworkAddress(),
+		int(cfg.GetDMRNetworkPort()),
+		cfg.GetDMRNetworkLocal(), // Local port for DMR socket binding (0 = any port)
+		cfg.GetDMRId(),
+		cfg.GetDMRNetworkPassword(),
+		options != "", // duplex mode if options exist
+		version,
+		cfg.GetDMRNetworkDebug(),
+		true,                      // slot1 - use default for now
+		true,                      // slot2 - use default for now
+		protocol.HW_TYPE_HOMEBREW, // Default to homebrew for now
+		int(cfg.GetDMRNetworkJitter()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DMR network: %v", err)
+	}
+
+	// Set DMR network configuration
+	dmrNet.SetConfig(
+		cfg.GetCallsign(),
+		cfg.GetRxFrequency(),
+		cfg.GetTxFrequency(),
+		cfg.GetPower(),
+		uint32(cfg.GetDMRColorCode()),
+		float32(cfg.GetLatitude()),
+		float32(cfg.GetLongitude()),
+		int(cfg.GetHeight()),
+		cfg.GetLocation(),
+		cfg.GetDescription(),
+		cfg.GetURL(),
+	)
+
+	// Set DMR options if provided
+	if options != "" {
+		dmrNet.SetOptions(options)
+	}
+	dmrNet.SetMasterType(cfg.GetDMRMasterType())
+	dmrNet.SetBindAddress(cfg.GetDMRBindAddress())
+	dmrNet.SetProxy(proxy)
+
+	return dmrNet, nil
+}
+
+// DMRProxy returns the proxy set by the DMR Network Proxy key, or nil
+func DMRProxy(cfg *config.Config) (*network.SOCKS5Proxy, error) {
+	if cfg.GetDMRProxy() == "" {
+		return nil, nil
+	}
+	proxy, err := network.ParseProxy(cfg.GetDMRProxy())
+	if err != nil {
+		return nil, fmt.Errorf("invalid [DMR Network] Proxy: %v", err)
+	}
+	return proxy, nil
+}
+
+// DMRNetworkOptions returns the options string sent to the master
+// An explicit Options= wins; otherwise it is built for MasterType from the
+// structured keys.
+func DMRNetworkOptions(cfg *config.Config) (string, error) {
+	if cfg.GetDMRNetworkOptions() != "" || cfg.GetDMRMasterType() == "" {
+		return cfg.GetDMRNetworkOptions(), nil
+	}
+
+	staticTGs, err := network.ParseStaticTGs(cfg.GetDMRStaticTGs())
+	if err != nil {
+		return "", fmt.Errorf("invalid StaticTGs: %v", err)
+	}
+
+	options, err := network.BuildOptions(cfg.GetDMRMasterType(), network.MasterOptions{
+		StaticTGs: staticTGs,
+		Dial:      cfg.GetDMRDial(),
+		Voice:     cfg.GetDMRVoice(),
+		Lang:      cfg.GetDMRLang(),
+		Timer:     cfg.GetDMRTimer(),
+		Single:    cfg.GetDMRSingle(),
+		XLXModule: cfg.GetDMRXLXModule(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("DMR options for %s: %s", cfg.GetDMRMasterType(), options)
+	return options, nil
+}
+
+// formatDMRAddress formats a DMR ID with callsign lookup (matching C++ behavior)
+func (g *Gateway) formatDMRAddress(id uint32, isGroup bool) string {
+	if g.dmrLookup != nil {
+		if isGroup {
+			return fmt.Sprintf("TG %s", g.dmrLookup.FindCS(id))
+		}
+		if user, found := g.dmrLookup.FindUser(id); found {
+			return user.Callsign
+		}
+		return g.dmrLookup.FindCS(id)
+	}
+
+	// Fallback if no lookup available
+	if isGroup {
+		return fmt.Sprintf("TG %d", id)
+	}
+	return fmt.Sprintf("%d", id)
+}
+
+// describeDMRUser formats a DMR ID as the callsign followed by the user's name and location
+func (g *Gateway) describeDMRUser(id uint32) string {
+	if g.dmrLookup == nil {
+		return fmt.Sprintf("%d", id)
+	}
+	user, found := g.dmrLookup.FindUser(id)
+	if !found {
+		return g.dmrLookup.FindCS(id)
+	}
+
+	var details []string
+	for _, detail := range []string{user.Name, user.City, user.Country} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+	if len(details) == 0 {
+		return user.Callsign
+	}
+	return fmt.Sprintf("%s (%s)", user.Callsign, strings.Join(details, ", "))
+}
+
+// ysfCallsignForDMR returns the callsign YSF radios show for a DMR user: the
+// looked-up callsign after clean-up, or the DMR ID when the user is unknown
+func (g *Gateway) ysfCallsignForDMR(id uint32) string {
+	name := ""
+	if g.dmrLookup != nil {
+		if user, found := g.dmrLookup.FindUser(id); found {
+			name = callsign.Normalize(user.Callsign, g.dmrCallsigns)
+		}
+	}
+	if name == "" {
+		name = strconv.FormatUint(uint64(id), 10)
+	}
+	if len(name) > ysf.CALLSIGN_LENGTH {
+		name = name[:ysf.CALLSIGN_LENGTH]
+	}
+	return name
+}
+
+// Run starts the gateway main loop
+func (g *Gateway) Run(ctx context.Context) error {
+	g.mu.Lock()
+	g.running = true
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		g.running = false
+		g.mu.Unlock()
+	}()
+
+	log.Printf("YSF2DMR Gateway v%s starting", g.version)
+	log.Printf("Callsign: %s-%s", g.config.GetCallsign(), g.config.GetSuffix())
+	log.Printf("YSF: %s:%d -> %s:%d",
+		g.config.GetLocalAddress(), g.config.GetLocalPort(),
+		g.config.GetDstAddress(), g.config.GetDstPort())
+	if len(g.ysfTargets) > 1 {
+		log.Printf("YSF failover: %s:%d", g.ysfTargets[1].address, g.ysfTargets[1].port)
+	}
+	log.Printf("DMR: %s:%d (ID: %d)",
+		g.config.GetDMRNetworkAddress(), g.config.GetDMRNetworkPort(),
+		g.config.GetDMRId())
+	if bind := g.config.GetDMRBindAddress(); bind != "" {
+		log.Printf("DMR bound to %s", bind)
+	}
+
+	if g.config.GetEnableWiresX() {
+		log.Printf("WiresX enabled")
+	}
+
+	// Open networks
+	if err := g.ysfNetwork.Open(); err != nil {
+		return fmt.Errorf("failed to open YSF network: %v", err)
+	}
+
+	if err := g.dmrNetwork.Open(); err != nil {
+		g.ysfNetwork.Close()
+		return fmt.Errorf("failed to open DMR network: %v", err)
+	}
+
+	// Enable DMR network
+	g.dmrNetwork.Enable(true)
+
+	if g.usrp != nil {
+		if err := g.usrp.client.Open(); err != nil {
+			g.ysfNetwork.Close()
+			g.dmrNetwork.Close()
+			return fmt.Errorf("failed to open USRP network: %v", err)
+		}
+		log.Printf("USRP: :%d -> %s:%d", g.config.GetUSRPLocalPort(),
+			g.config.GetUSRPAddress(), g.config.GetUSRPPort())
+	}
+
+	// Start the HTTP server for dashboard live updates
+	g.heartbeat.Beat(time.Now())
+	if g.web != nil {
+		if err := g.web.Start(); err != nil {
+			g.ysfNetwork.Close()
+			g.dmrNetwork.Close()
+			return fmt.Errorf("failed to start HTTP server: %v", err)
+		}
+	}
+
+	// Periodic work runs from monotonic deadlines on a single timer, so the
+	// frame and network clocks cannot drift apart
+	g.scheduler = scheduler.New()
+	g.scheduler.Every("network clock", NETWORK_CLOCK_PER, func(elapsed time.Duration) {
+		g.heartbeat.Beat(time.Now())
+
+		// Clock the networks; the DMR client clocks its own network on its
+		// goroutine, as packets arrive
+		ms := int(elapsed.Milliseconds())
+		g.ysfNetwork.Clock(ms)
+		g.dmrNetwork.Clock(ms)
+		g.dmrOutput.Clock(ms)
+
+		// Process network data after Clock() calls
+		if err := g.processNetworks(); err != nil {
+			log.Printf("Network processing error: %v", err)
+		}
+
+		// Process WiresX if enabled
+		if g.wiresX != nil {
+			g.wiresX.Clock(uint32(time.Since(g.ysfWatch).Milliseconds()))
+		}
+
+		// Check hang timer
+		g.checkHangTimer()
+
+		// Cut off calls running too long
+		g.checkCallDuration()
+
+		// Monitor network health and handle recovery
+		g.monitorNetworkHealth()
+	})
+	// Frame periods come from the config (100ms YSF, 60ms DMR by default)
+	ysfFramePeriod := time.Duration(g.config.GetYSFFramePeriod()) * time.Millisecond
+	dmrFramePeriod := time.Duration(g.config.GetDMRFramePeriod()) * time.Millisecond
+	g.scheduler.Every("YSF frame", ysfFramePeriod, func(time.Duration) {
+		if err := g.processYSFTimer(); err != nil {
+			log.Printf("YSF timer error: %v", err)
+		}
+	})
+	g.scheduler.Every("DMR frame", dmrFramePeriod, func(time.Duration) {
+		if err := g.processDMRTimer(); err != nil {
+			log.Printf("DMR timer error: %v", err)
+		}
+	})
+	g.scheduler.Every("stats", 30*time.Second, func(time.Duration) {
+		g.printStats()
+	})
+	g.scheduler.Every("event stats", time.Second, func(time.Duration) {
+		// Frame counters for live displays
+		g.publishStats()
+	})
+	g.scheduler.Every("YSF poll", YSF_POLL_INTERVAL, func(time.Duration) {
+		// Send YSF poll message for keep-alive
+		if err := g.ysfNetwork.WritePoll(); err != nil {
+			log.Printf("YSF poll error: %v", err)
+			g.state.AddYSFError()
+		}
+	})
+
+	if g.beacon != nil && g.config.GetBeaconInterval() > 0 {
+		g.scheduler.Every("beacon", time.Duration(g.config.GetBeaconInterval())*time.Second, func(time.Duration) {
+			g.requestBeacon("interval")
+		})
+	}
+
+	if g.maintenance != nil {
+		g.checkMaintenance(time.Now())
+		g.scheduler.Every("maintenance window", maintenanceCheckInterval, func(time.Duration) {
+			g.checkMaintenance(time.Now())
+		})
+	}
+
+	// Database maintenance (optimize, checkpoint, vacuum, backup)
+	if g.db != nil && g.config.GetDatabaseMaintenanceHours() > 0 {
+		g.scheduler.Every("database maintenance", time.Duration(g.config.GetDatabaseMaintenanceHours())*time.Hour, func(time.Duration) {
+			g.maintainDatabase()
+		})
+	}
+
+	// Scheduled host and TG file downloads
+	if g.hosts != nil {
+		g.hosts.SetOnUpdate(func(file hosts.File) {
+			select {
+			case g.hostsUpdated <- file:
+			default:
+				log.Printf("%s updated but a reload is already pending", file.Name)
+			}
+		})
+		go g.hosts.Start(ctx)
+	}
+
+	// APRS-IS connection for DMR positions
+	if g.aprs != nil {
+		go g.aprs.Run(ctx)
+	}
+
+	// Call history writes
+	if g.callHistory != nil {
+		go g.callHistory.Run(ctx)
+	}
+
+	// On-demand lookups of unknown DMR IDs
+	if g.idLookup != nil {
+		g.idLookup.SetOnFound(func(user database.DMRUser) {
+			select {
+			case g.dmrUserFound <- user.RadioID:
+			default:
+			}
+		})
+		go g.idLookup.Start(ctx)
+	}
+
+	if g.voice != nil {
+		g.voice.start(ctx)
+	}
+
+	defer func() {
+		g.scheduler.Stop()
+		if g.dmrReconnectTimer != nil {
+			g.dmrReconnectTimer.Stop()
+		}
+		if g.ysfReconnectTimer != nil {
+			g.ysfReconnectTimer.Stop()
+		}
+		g.ysfNetwork.Close()
+		g.dmrNetwork.Close()
+		g.stopRecording()
+		if g.recorder != nil {
+			g.recorder.Wait()
+		}
+		if g.web != nil {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			g.web.Shutdown(shutdownCtx)
+			cancel()
+		}
+		if g.hooks != nil {
+			g.hooks.Wait()
+		}
+		if g.audioTranscoder != nil {
+			g.audioTranscoder.Close()
+		}
+		if g.voice != nil {
+			g.voice.stop()
+		}
+		if g.usrp != nil {
+			g.usrp.close()
+		}
+		if g.dmrLookup != nil {
+			g.dmrLookup.Stop()
+		}
+		g.tracer.Close()
+	}()
+
+	log.Printf("Gateway running - press Ctrl+C to stop")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Shutdown requested")
+			return nil
+
+		case <-g.scheduler.C():
+			g.scheduler.RunDue(time.Now())
+
+		case <-g.dmrReconnect:
+			g.attemptReconnect()
+
+		case <-g.ysfReconnect:
+			g.attemptYSFReconnect()
+
+		case file := <-g.hostsUpdated:
+			g.reloadHostFile(file)
+
+		case id := <-g.dmrUserFound:
+			g.refreshDMRCallSource(id)
+
+		case reply := <-g.diagRequests:
+			reply <- g.statsLines()
+
+		case fn := <-g.controlRequests:
+			fn()
+
+		case <-g.ysfNetwork.Ready():
+			// YSF packets are handled as they arrive rather than on the
+			// next network clock
+			g.ysfNetwork.Clock(0)
+			g.processYSFNetwork()
+		}
+	}
+}
+
+// processNetworks handles incoming data from both networks
+func (g *Gateway) processNetworks() error {
+	g.processYSFNetwork()
+
+	g.processUSRPNetwork()
+
+	// Process DMR network data
+	dmrData := protocol.GetDMRData()
+	defer protocol.PutDMRData(dmrData)
+	if g.dmrNetwork.Read(dmrData) {
+		g.rewriteFromNetwork(dmrData)
+
+		// Duplicates are dropped and reordered frames put back in sequence
+		for _, data := range g.dmrSequencers[dmrData.GetSlotNo()].Push(dmrData) {
+			if err := g.processDMRData(data); err != nil {
+				log.Printf("DMR data processing error: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// processYSFNetwork handles every YSF packet waiting in the ring buffer
+func (g *Gateway) processYSFNetwork() {
+	ysfBuffer := protocol.GetBuffer()
+	defer protocol.PutBuffer(ysfBuffer)
+	for {
+		bytesRead := g.ysfNetwork.Read(*ysfBuffer)
+		if bytesRead == 0 {
+			return
+		}
+
+		ysfData := (*ysfBuffer)[:bytesRead]
+		packetType := ysf.ClassifyPacket(ysfData)
+		g.ysfPackets[packetType]++
+
+		switch packetType {
+		case ysf.PacketData:
+			if err := g.processYSFData(ysfData); err != nil {
+				log.Printf("YSF data processing error: %v", err)
+			}
+		case ysf.PacketUnknown, ysf.PacketInvalid:
+			log.Printf("YSF: dropped %s packet (%d bytes)", packetType, bytesRead)
+		}
+	}
+}
+
+// processYSFData processes incoming YSF data
+func (g *Gateway) processYSFData(data []byte) error {
+	// Parse YSF frame
+	frame := &ysf.Frame{}
+	if err := frame.Parse(data); err != nil {
+		return fmt.Errorf("YSF frame parse error: %v", err)
+	}
+
+	log.Printf("YSF: %s -> %s (%s)", frame.SourceCallsign, frame.DestCallsign, frame.FICH.String())
+
+	// Padding and suffixes such as "-ND" or "/P" would break ID lookups
+	source := callsign.Normalize(frame.SourceCallsign, g.ysfCallsigns)
+
+	// Radios are refused by the radio ID in the header, not the callsign
+	if frame.IsHeader() {
+		radioID := ysf.HeaderRadioID(frame.Payload)
+		g.ysfCallBlocked = !g.radioIDs.Allowed(radioID)
+		if g.ysfCallBlocked {
+			g.ysfBlocked++
+			log.Printf("YSF: ignoring call from %s, radio ID %q is blocked", source, radioID)
+		}
+	}
+	if g.ysfCallBlocked {
+		g.ysfFrames++
+		return nil
+	}
+
+	// A header whose destination is a command carries it out instead of
+	// starting a call
+	if frame.IsHeader() {
+		g.ysfCommandCall = g.ysfHeaderCommand(frame, source)
+	}
+	if g.ysfCommandCall {
+		g.ysfCommandCall = !frame.IsTerminator()
+		g.ysfFrames++
+		return nil
+	}
+
+	// The rest of a call aborted by OutputAbort is not bridged
+	if g.ysfCallAborted && !frame.IsHeader() {
+		g.ysfFrames++
+		return nil
+	}
+
+	// While bridging is paused, or just after a DMR->YSF call, new calls are
+	// ignored up to the next header; WiresX still works
+	if frame.IsHeader() {
+		g.ysfCallHeld = false
+		if g.state.CallState() != state.CallYSF {
+			if g.txInhibited(&g.netInhibit) {
+				log.Printf("YSF: ignoring call from %s during the %s", source, g.netInhibit.name)
+				g.ysfCallHeld = true
+			} else {
+				g.ysfCallHeld = g.state.Paused()
+			}
+		}
+	}
+	if g.ysfCallHeld {
+		g.ysfFrames++
+		g.processWiresX(frame)
+		return nil
+	}
+
+	// A YSF call during a DMR call waits unless the interrupt policy lets it
+	// take over
+	if g.arbitrateYSFCall(frame, source) {
+		g.ysfFrames++
+		g.processWiresX(frame)
+		return nil
+	}
+
+	// Update call state if this is the start of a new call (header frame)
+	if frame.IsHeader() {
+		g.startYSFCall(source)
+		if !frame.IsData() {
+			g.sendDMRFullLC(protocol.DT_VOICE_LC_HEADER)
+		}
+	}
+
+	// Handle terminator frames
+	if frame.IsTerminator() {
+		if !frame.IsData() {
+			g.sendDMRFullLC(protocol.DT_TERMINATOR_WITH_LC)
+		}
+		g.endCall()
+	}
+
+	g.processWiresX(frame)
+
+	// Text messages sent in data FR mode are bridged to DMR SMS; pictures and
+	// other fast data are only logged and kept
+	if frame.IsData() {
+		if message, ok := g.ysfDataAssembler.Add(frame); ok {
+			if ysf.IsWiresXCommand(message) {
+				g.ysfDataMessage = true
+			} else if text, ok := ysf.ParseTextMessage(message); ok {
+				g.ysfDataMessage = true
+				if !g.runYSFCommand(text, source) {
+					g.bridgeYSFTextToDMR(frame, text)
+				}
+			}
+		}
+		g.processYSFFastData(frame, source)
+	}
+
+	// Voice FR (VW mode) carries full-rate IMBE which cannot be bridged
+	if frame.IsVoiceFR() {
+		g.handleYSFVoiceFR(frame)
+	} else if frame.IsVoice() {
+		g.measureYSFBER(frame)
+		g.ysfLatency.Received(time.Now())
+
+		// VD mode 2 carries the DMR vocoder and is repacked as is when
+		// passthrough is enabled; anything else goes through the advanced codec
+		// chain with Frame Ratio Converter for proper 3:5 timing
+		var dmrFrames [][]byte
+		var err error
+		if g.repack != nil && frame.FICH.DT == 2 {
+			dmrFrames, err = g.repack.ConvertYSFToDMR(frame.Payload)
+		} else {
+			dmrFrames, err = g.frameRatioConverter.ConvertYSFToDMR(frame.Payload)
+		}
+		if err != nil {
+			log.Printf("YSF to DMR conversion error: %v", err)
+			g.captureConversionError(err)
+		} else if len(dmrFrames) > 0 {
+			// Frame Ratio Converter has produced DMR frames (3 YSF → 5 DMR)
+			log.Printf("Generated %d DMR frames from YSF frame buffer", len(dmrFrames))
+			for i, dmrFrame := range dmrFrames {
+				if err := g.sendDMRFrame(dmrFrame); err != nil && g.dmrOutputFailed(fmt.Sprintf("frame %d", i), err) {
+					break
+				}
+				if i == 0 {
+					g.ysfLatency.Sent(time.Now())
+				}
+			}
+		}
+		// If len(dmrFrames) == 0, the frame is buffered waiting for complete 3-frame set
+	}
+
+	g.ysfFrames++
+	return nil
+}
+
+// measureYSFBER updates the BER from the FEC of a VD mode 2 voice frame
+// VD mode 1 frames are not measured and leave the BER unchanged.
+func (g *Gateway) measureYSFBER(frame *ysf.Frame) {
+	if frame.FICH.DT != 2 {
+		return
+	}
+
+	errors := codec.CountYSFVDMode2Errors(frame.Payload)
+	g.ysfBER = uint8(errors * 100 / codec.YSF_VD_MODE2_CHECKED_BITS)
+	g.ysfErrors += uint32(errors)
+	g.ysfChecked += codec.YSF_VD_MODE2_CHECKED_BITS
+}
+
+// handleYSFVoiceFR drops a Voice FR frame, reporting the unsupported mode once per call
+// Its full-rate IMBE audio has no AMBE+2 equivalent.
+func (g *Gateway) handleYSFVoiceFR(frame *ysf.Frame) {
+	g.ysfVWFrames++
+
+	if !g.ysfVWLogged {
+		g.ysfVWLogged = true
+		log.Printf("YSF: %s is transmitting in Voice FR (VW) mode, which cannot be bridged to DMR - set the radio to DN mode",
+			frame.SourceCallsign)
+	}
+}
+
+// processWiresX handles WiresX commands in YSF data frames
+func (g *Gateway) processWiresX(frame *ysf.Frame) {
+	if g.wiresX == nil || !frame.IsData() {
+		return
+	}
+
+	status := g.wiresX.Process(frame.Payload, []byte(frame.SourceCallsign),
+		frame.FICH.FI, frame.FICH.DT, frame.FICH.FN, frame.FICH.FT)
+
+	switch status {
+	case wiresx.StatusConnect:
+		dstID := g.wiresX.GetDstID()
+		g.wiresX.SendConnectReply(dstID)
+		g.setDestination(dstID, g.wiresX.IsPrivate(), "WiresX")
+	case wiresx.StatusDisconnect:
+		g.wiresX.SendDisconnectReply()
+		g.setDestination(0, false, "WiresX")
+	case wiresx.StatusDX:
+		log.Printf("WiresX DX request")
+	case wiresx.StatusAll:
+		log.Printf("WiresX ALL request")
+	}
+}
+
+// processDMRData processes incoming DMR data
+func (g *Gateway) processDMRData(data *protocol.DMRData) error {
+	// Format source and destination with callsign lookup (matching C++ behavior)
+	srcStr := g.formatDMRAddress(data.GetSrcId(), false) // Source is never a group
+	dstStr := g.formatDMRAddress(data.GetDstId(), data.IsGroupCall())
+
+	log.Printf("DMR: Slot %d, Src %s, Dst %s, FLCO %s, DT %s, Seq %d",
+		data.GetSlotNo(), srcStr, dstStr,
+		data.GetFLCOString(), data.GetDataTypeString(), data.GetSeqNo())
+
+	// Traffic the master sends on muted talk groups is only counted
+	if g.muteDMRCall(data, srcStr, dstStr) {
+		g.networkWatchdog = time.Now()
+		return nil
+	}
+
+	// Data bursts are handled separately so they never reach the voice pipeline
+	if data.IsDataSync() {
+		g.processDMRDataCall(data)
+		g.dmrDataFrames++
+		g.networkWatchdog = time.Now()
+		return nil
+	}
+
+	// While bridging is paused new DMR calls are not bridged
+	if g.state.CallState() != state.CallDMR && g.state.Paused() {
+		g.networkWatchdog = time.Now()
+		return nil
+	}
+
+	// A DMR call during a YSF call waits unless the interrupt policy lets it
+	// take over
+	if g.arbitrateDMRCall(data, srcStr, dstStr) {
+		g.networkWatchdog = time.Now()
+		return nil
+	}
+
+	// Calls on other talkgroups wait until the hang timers expire; a held
+	// stream stays dropped even if the hang ends part way through it
+	if g.state.CallState() != state.CallDMR {
+		if data.GetStreamId() == g.heldStream {
+			g.networkWatchdog = time.Now()
+			return nil
+		}
+		if g.holdDMRCall(data) {
+			log.Printf("DMR: holding call to %s during hang time", dstStr)
+			g.heldStream = data.GetStreamId()
+			g.networkWatchdog = time.Now()
+			return nil
+		}
+		if g.txInhibited(&g.rfInhibit) {
+			log.Printf("DMR: holding call to %s during the %s", dstStr, g.rfInhibit.name)
+			g.heldStream = data.GetStreamId()
+			g.networkWatchdog = time.Now()
+			return nil
+		}
+	}
+
+	// Update call state if this is the start of a new call
+	if data.IsVoiceLCHeader() {
+		g.startDMRCall(data.GetSrcId(), data.GetDstId(), data.GetStreamId(), data.GetFLCO())
+		g.dmrCallSlot = data.GetSlotNo()
+
+		payload := data.GetData()
+		lc, err := dmr.DecodeFullLCBurst(payload[:], dmr.DT_VOICE_LC_HEADER)
+		if err != nil {
+			log.Printf("DMR voice LC header decode error: %v", err)
+		} else if lc.IsEmergency() {
+			g.raiseEmergency("DMR", srcStr, dstStr)
+		}
+
+		if err := g.sendYSFHeader(0); err != nil {
+			log.Printf("YSF header send error: %v", err)
+		}
+	} else if data.IsVoice() && g.state.CallState() == state.CallIdle && data.GetStreamId() != g.dmrEndedStream {
+		// Late entry: the voice LC header was lost, so the call is started from
+		// the DMRD addressing of its first voice frame
+		log.Printf("DMR: late entry into stream 0x%08X, voice LC header not received", data.GetStreamId())
+		g.startDMRCall(data.GetSrcId(), data.GetDstId(), data.GetStreamId(), data.GetFLCO())
+		g.dmrCallSlot = data.GetSlotNo()
+		g.dmrLateEntry = true
+
+		if err := g.sendYSFHeader(0); err != nil {
+			log.Printf("YSF header send error: %v", err)
+		}
+	}
+
+	// Extract audio and convert to YSF if this is a voice frame
+	if data.IsVoice() {
+		dmrPayload := data.GetData()
+		g.dmrLatency.Received(time.Now())
+		g.processVoice(g.dmrAudio, dmrPayload[:], data.GetDstId(), g.state.CallState() == state.CallDMR)
+		g.recordDMRBurst(dmrPayload[:])
+		if !data.IsVoiceSync() && g.state.CallState() == state.CallDMR {
+			// Bursts B-F carry the LC and talker alias in embedded signalling
+			if lc, ok := g.dmrEmbeddedLC.Add(dmrPayload[:]); ok {
+				g.processDMREmbeddedLC(lc, srcStr, dstStr)
+			}
+		} else if g.state.CallState() == state.CallDMR && g.dmrCallAlias == "" {
+			g.checkMasterTalkerAlias()
+		}
+		if err := g.dmrQuality.AddDMRBurst(dmrPayload[:]); err != nil {
+			log.Printf("DMR audio quality error: %v", err)
+		}
+
+		// Use advanced codec chain with Frame Ratio Converter for proper 5:3
+		// timing, or repack straight into VD mode 2 with passthrough
+		var ysfFrames [][]byte
+		var err error
+		if g.repack != nil {
+			ysfFrames, err = g.repack.ConvertDMRToYSF(dmrPayload[:])
+		} else {
+			ysfFrames, err = g.frameRatioConverter.ConvertDMRToYSF(dmrPayload[:])
+		}
+		if err != nil {
+			log.Printf("DMR to YSF conversion error: %v", err)
+			g.captureConversionError(err)
+		} else if len(ysfFrames) > 0 {
+			// Frame Ratio Converter has produced YSF frames (5 DMR → 3 YSF)
+			log.Printf("Generated %d YSF frames from DMR frame buffer", len(ysfFrames))
+			for i, ysfFrame := range ysfFrames {
+				if err := g.sendYSFFrame(ysfFrame); err != nil {
+					log.Printf("YSF send error (frame %d): %v", i, err)
+				} else if i == 0 {
+					g.dmrLatency.Sent(time.Now())
+				}
+			}
+		}
+		// If len(ysfFrames) == 0, the frame is buffered waiting for complete 5-frame set
+	}
+
+	// Handle call termination
+	if data.IsTerminator() {
+		if g.state.CallState() == state.CallDMR {
+			if err := g.sendYSFHeader(2); err != nil {
+				log.Printf("YSF terminator send error: %v", err)
+			}
+			g.reportLinkQuality()
+		}
+		g.endCall()
+	}
+
+	g.dmrFrames++
+	g.networkWatchdog = time.Now()
+	return nil
+}
+
+// processDMRDataCall reassembles DMR data calls, bridging any SMS text found
+// and reporting LRRP positions
+func (g *Gateway) processDMRDataCall(data *protocol.DMRData) {
+	payload := data.GetData()
+	srcStr := g.formatDMRAddress(data.GetSrcId(), false)
+
+	switch data.GetDataType() {
+	case protocol.DT_DATA_HEADER:
+		header, err := g.dmrDataCall.AddHeader(payload[:])
+		if err != nil {
+			log.Printf("DMR data call from %s discarded: %v", srcStr, err)
+			return
+		}
+		log.Printf("DMR data call from %s to %d, SAP %d, %d blocks",
+			srcStr, header.DstID, header.SAP, header.BlocksToFollow)
+
+	case protocol.DT_RATE_12_DATA:
+		if !g.dmrDataCall.InProgress() {
+			return
+		}
+		message, err := g.dmrDataCall.AddBlock(payload[:])
+		if err != nil {
+			log.Printf("DMR data call from %s discarded: %v", srcStr, err)
+			return
+		}
+		if message == nil {
+			return
+		}
+		if message.Text != "" {
+			log.Printf("DMR SMS from %s to %s: %q", srcStr,
+				g.formatDMRAddress(message.Header.DstID, message.Header.Group), message.Text)
+			g.bridgeDMRTextToYSF(message.Header.SrcID, message.Text)
+		} else if pos, ok := dmr.ExtractLRRP(message.Payload, message.Header.SAP); ok {
+			g.reportDMRPosition(message.Header.SrcID, pos, "LRRP")
+		} else {
+			log.Printf("DMR data call from %s complete (%d bytes, no text)", srcStr, len(message.Payload))
+		}
+
+	default:
+		// Rate 3/4 and rate 1 data are not decoded
+		if g.dmrDataCall.InProgress() {
+			log.Printf("DMR data call from %s discarded: %s blocks not supported",
+				srcStr, data.GetDataTypeString())
+			g.dmrDataCall.Reset()
+		}
+	}
+}
+
+// bridgeYSFTextToDMR sends a YSF text message as a DMR SMS
+// A numeric YSF destination is treated as a private DMR ID, otherwise the
+// message goes to the current talkgroup.
+func (g *Gateway) bridgeYSFTextToDMR(frame *ysf.Frame, text string) {
+	dstID, _ := g.state.Destination()
+	group := true
+	if id, err := strconv.ParseUint(frame.DestCallsign, 10, 32); err == nil && id > 0 {
+		dstID, group = uint32(id), false
+	}
+
+	if dstID == 0 {
+		log.Printf("YSF message from %s dropped: no DMR destination", frame.SourceCallsign)
+		return
+	}
+
+	slot, netDstID := g.toNetwork(dstID, !group)
+	sms, err := dmr.BuildSMS(g.config.GetDMRId(), netDstID, group, text)
+	if err != nil {
+		log.Printf("YSF message from %s dropped: %v", frame.SourceCallsign, err)
+		return
+	}
+
+	log.Printf("YSF message from %s to %s: %q", frame.SourceCallsign, g.formatDMRAddress(dstID, group), text)
+
+	if len(g.dmrSMSList) >= maxQueuedMessages {
+		log.Printf("YSF message from %s dropped: %d messages already waiting", frame.SourceCallsign, len(g.dmrSMSList))
+		return
+	}
+	message := &dmrSMS{slot: slot, dstID: netDstID, group: group}
+	message.bursts = append(message.bursts, dmrDataBurst{protocol.DT_DATA_HEADER, dmr.BuildDataHeader(sms.Header)})
+	for _, block := range sms.Blocks {
+		message.bursts = append(message.bursts, dmrDataBurst{protocol.DT_RATE_12_DATA, block})
+	}
+	g.dmrSMSList = append(g.dmrSMSList, message)
+}
+
+// bridgeDMRTextToYSF sends a DMR SMS as a YSF data FR mode text message
+func (g *Gateway) bridgeDMRTextToYSF(srcID uint32, text string) {
+	source := fmt.Sprintf("%d", srcID)
+	if g.dmrLookup != nil {
+		source = g.dmrLookup.FindCS(srcID)
+	}
+
+	g.queueYSFText(ysf.BuildTextMessageFrames(source, "ALL", g.ysfMessageSeqNo, text))
+	g.ysfMessageSeqNo++
+}
+
+// queueYSFText queues the frames of a text message toward YSF, which
+// processYSFDataTX sends when the channel is free
+func (g *Gateway) queueYSFText(frames [][]byte) {
+	if len(g.ysfTexts) >= maxQueuedMessages {
+		log.Printf("YSF message dropped: %d messages already waiting", len(g.ysfTexts))
+		return
+	}
+	g.ysfTexts = append(g.ysfTexts, frames)
+}
+
+// qualityThresholds returns the audio quality profile from the config, with
+// any thresholds set individually taking precedence
+func qualityThresholds(cfg *config.Config) (codec.ValidatorThresholds, error) {
+	thresholds, err := codec.ValidatorProfile(cfg.GetYSFQualityProfile())
+	if err != nil {
+		return thresholds, fmt.Errorf("invalid [YSF Network] QualityProfile: %v", err)
+	}
+	if v := cfg.GetYSFQualityChange(); v > 0 {
+		thresholds.Change = float32(v)
+	}
+	if v := cfg.GetYSFQualitySilence(); v > 0 {
+		thresholds.Silence = float32(v)
+	}
+	if v := cfg.GetYSFQualityNoise(); v > 0 {
+		thresholds.Noise = float32(v)
+	}
+	return thresholds, nil
+}
+
+// reportLinkQuality logs the audio quality of the DMR->YSF call that just
+// ended and, when it is below the QualityReport percentage, tells the YSF
+// listeners with a text message from the gateway
+func (g *Gateway) reportLinkQuality() {
+	if g.dmrQuality.Frames() == 0 {
+		return
+	}
+
+	quality, ber := g.dmrQuality.Quality()
+	percent := int(quality * 100)
+	log.Printf("DMR audio quality: %d%%, estimated BER %.1f%%", percent, ber*100)
+
+	threshold := int(g.config.GetYSFQualityReport())
+	if percent >= threshold {
+		return
+	}
+
+	text := fmt.Sprintf("DMR AUDIO %d%% BER %.1f%%", percent, ber*100)
+	g.queueYSFText(ysf.BuildTextMessageFrames(g.config.GetCallsign(), "ALL", g.ysfMessageSeqNo, text))
+	g.ysfMessageSeqNo++
+}
+
+// sendDMRDataBurst sends a BPTC(196,96) data burst (data header or rate 1/2
+// block) to dstID on slot, both as used on the master
+func (g *Gateway) sendDMRDataBurst(dataType uint8, payload []byte, slot uint8, dstID uint32, group bool) error {
+	burst, err := dmr.BuildDataBurst(payload, dataType, g.config.GetDMRColorCode())
+	if err != nil {
+		return err
+	}
+	g.dmrBursts.Data(burst, dataType)
+
+	dmrData := protocol.GetDMRData()
+	defer protocol.PutDMRData(dmrData)
+	dmrData.SetSlotNo(slot)
+	dmrData.SetSrcId(g.config.GetDMRId())
+	dmrData.SetDstId(dstID)
+	if group {
+		dmrData.SetFLCO(protocol.FLCO_GROUP)
+	} else {
+		dmrData.SetFLCO(protocol.FLCO_USER_USER)
+	}
+	dmrData.SetDataType(dataType)
+	dmrData.SetData(burst)
+
+	// A queued burst is delivered when the network is back
+	if err := g.dmrOutput.Write(dmrData); !errors.Is(err, network.ErrFrameQueued) {
+		return err
+	}
+	return nil
+}
+
+// publishCallStart records the details of a new call and publishes a call start event
+// Callers must hold g.mu.
+func (g *Gateway) publishCallStart(direction, callsign string, tg uint32, private bool) {
+	g.callStart = time.Now()
+	g.callCallsign = callsign
+	g.callDirection = direction
+	g.callTG = tg
+	g.callPrivate = private
+	g.callSrcID = 0
+
+	fields := map[string]string{
+		"callsign":  callsign,
+		"tg":        strconv.FormatUint(uint64(tg), 10),
+		"call_type": callType(private),
+		"direction": direction,
+	}
+	if g.dmrLookup != nil {
+		if id := g.dmrLookup.FindID(callsign); id != lookup.DMR_ID_UNKNOWN {
+			if user, found := g.dmrLookup.FindUser(id); found {
+				g.callSrcID = id
+				fields["id"] = strconv.FormatUint(uint64(id), 10)
+				setNonEmpty(fields, "name", user.Name)
+				setNonEmpty(fields, "city", user.City)
+				setNonEmpty(fields, "state", user.State)
+				setNonEmpty(fields, "country", user.Country)
+			}
+		}
+	}
+
+	g.events.Publish(events.Event{
+		Type:   events.CallStart,
+		Time:   g.callStart,
+		Source: direction[:3],
+		Fields: fields,
+	})
+}
+
+// callType names the kind of call in events: "group" or "private"
+func callType(private bool) string {
+	if private {
+		return "private"
+	}
+	return "group"
+}
+
+// setNonEmpty sets fields[key] when value is not empty
+func setNonEmpty(fields map[string]string, key, value string) {
+	if value != "" {
+		fields[key] = value
+	}
+}
+
+// publishCallEnd publishes a call end event for the current call
+// Callers must hold g.mu.
+func (g *Gateway) publishCallEnd() {
+	duration := time.Since(g.callStart)
+	log.Print(g.catalog.Format(i18n.CallSummary, g.callDirection, g.callCallsign,
+		g.formatDMRAddress(g.callTG, !g.callPrivate), duration.Round(100*time.Millisecond)))
+
+	fields := map[string]string{
+		"callsign":  g.callCallsign,
+		"tg":        strconv.FormatUint(uint64(g.callTG), 10),
+		"call_type": callType(g.callPrivate),
+		"direction": g.callDirection,
+		"duration":  strconv.FormatFloat(duration.Seconds(), 'f', 1, 64),
+	}
+	if g.callSrcID != 0 {
+		fields["id"] = strconv.FormatUint(uint64(g.callSrcID), 10)
+	}
+	if g.callEndReason != "" {
+		fields["reason"] = g.callEndReason
+	}
+	if g.callDirection == "DMR->YSF" {
+		seq := g.dmrSequenceStats()
+		fields["duplicates"] = strconv.FormatUint(uint64(seq.Duplicates), 10)
+		fields["reordered"] = strconv.FormatUint(uint64(seq.Reordered), 10)
+		fields["lost"] = strconv.FormatUint(uint64(seq.Gaps), 10)
+		setNonEmpty(fields, "talker_alias", g.dmrCallAlias)
+		if reports, ok := g.dmrNetwork.(network.MasterReports); ok {
+			if dBm, at := reports.RSSI(g.dmrCallSlot); at.After(g.callStart) {
+				fields["rssi"] = strconv.Itoa(dBm)
+			}
+		}
+		if g.dmrQuality.Frames() > 0 {
+			quality, _ := g.dmrQuality.Quality()
+			fields["quality"] = strconv.Itoa(int(quality * 100))
+		}
+	}
+	if g.audioTranscoder != nil {
+		g.endAudioCall(g.callDirection, fields)
+	}
+
+	g.events.Publish(events.Event{
+		Type:   events.CallEnd,
+		Source: g.callDirection[:3],
+		Fields: fields,
+	})
+}
+
+// dmrSequenceStats returns the sequence counters of the current DMR->YSF call
+func (g *Gateway) dmrSequenceStats() network.SequenceStats {
+	if g.dmrCallSlot < 1 || g.dmrCallSlot > 2 {
+		return network.SequenceStats{}
+	}
+	return g.dmrSequencers[g.dmrCallSlot].Stats()
+}
+
+// startRecording opens a recording for a new call, closing any previous one
+// Callers must hold g.mu.
+func (g *Gateway) startRecording(meta recorder.Metadata) {
+	if g.recorder == nil {
+		return
+	}
+	g.stopRecording()
+
+	rec, err := g.recorder.Start(meta)
+	if err != nil {
+		log.Printf("Recorder: %v", err)
+		return
+	}
+	g.recording = rec
+}
+
+// stopRecording finishes the current recording, if any
+func (g *Gateway) stopRecording() {
+	if g.recording == nil {
+		return
+	}
+	g.recording.SetEmergency(g.state.Call().Emergency)
+	if err := g.recording.Close(); err != nil {
+		log.Printf("Recorder: %v", err)
+	}
+	g.recording = nil
+}
+
+// recordDMRBurst adds the AMBE frames of a bridged DMR voice burst to the current recording
+func (g *Gateway) recordDMRBurst(burst []byte) {
+	if g.recording == nil {
+		return
+	}
+	if err := g.recording.WriteDMRBurst(burst); err != nil {
+		log.Printf("Recorder: %v", err)
+	}
+}
+
+// raiseEmergency flags the current call as an emergency and publishes a high
+// priority event, once per call
+func (g *Gateway) raiseEmergency(source, src, dst string) {
+	if !g.state.RaiseEmergency() {
+		return
+	}
+
+	log.Printf("EMERGENCY call on %s from %s to %s", source, src, dst)
+	g.events.Publish(events.Event{
+		Type:     events.Emergency,
+		Priority: events.PriorityHigh,
+		Source:   source,
+		Fields:   map[string]string{"src": src, "dst": dst},
+	})
+}
+
+// destinationFLCO returns the call type for calls to the YSF->DMR destination
+func destinationFLCO(private bool) uint8 {
+	if private {
+		return protocol.FLCO_USER_USER
+	}
+	return protocol.FLCO_GROUP
+}
+
+// formatDestination formats the YSF->DMR destination for logs and events
+func (g *Gateway) formatDestination() string {
+	dstID, private := g.state.Destination()
+	return g.formatDMRAddress(dstID, !private)
+}
+
+// sendDMRFullLC sends a voice LC header or terminator for the current YSF call
+func (g *Gateway) sendDMRFullLC(dataType uint8) {
+	dstID, private := g.state.Destination()
+	if dstID == 0 {
+		return
+	}
+	slot, dstID := g.toNetwork(dstID, private)
+
+	lc := &dmr.LinkControl{
+		FLCO:          destinationFLCO(private),
+		SourceID:      g.config.GetDMRId(),
+		DestinationID: dstID,
+	}
+	// YSF has no emergency indication, so calls from YSF never carry the
+	// emergency flag
+
+	burst, err := dmr.BuildFullLCBurst(lc, dataType, g.config.GetDMRColorCode())
+	if err != nil {
+		log.Printf("DMR full LC build error: %v", err)
+		return
+	}
+	g.dmrBursts.Data(burst, dataType)
+	if dataType == protocol.DT_VOICE_LC_HEADER {
+		// The voice bursts that follow repeat the LC in their embedded signalling
+		g.dmrBursts.StartVoice(lc)
+	}
+
+	dmrData := protocol.GetDMRData()
+	defer protocol.PutDMRData(dmrData)
+	dmrData.SetSlotNo(slot)
+	dmrData.SetSrcId(lc.SourceID)
+	dmrData.SetDstId(lc.DestinationID)
+	dmrData.SetFLCO(lc.FLCO)
+	dmrData.SetDataType(dataType)
+	dmrData.SetData(burst)
+	dmrData.SetRSSI(g.config.GetYSFRSSI())
+
+	if err := g.dmrOutput.Write(dmrData); err != nil {
+		g.dmrOutputFailed("full LC", err)
+	}
+}
+
+// dmrOutputFailed handles a frame of the current YSF call that was not sent
+// Queued frames are only counted; a dropped frame ends the call when
+// OutputAbort is set, in which case true is returned.
+func (g *Gateway) dmrOutputFailed(what string, err error) bool {
+	if errors.Is(err, network.ErrFrameQueued) {
+		return false
+	}
+
+	g.ysfCallDropped++
+	if !errors.Is(err, network.ErrFrameDropped) || g.ysfCallDropped == 1 {
+		log.Printf("DMR send error (%s): %v", what, err)
+	}
+
+	if !g.config.GetDMROutputAbort() || g.state.CallState() != state.CallYSF {
+		return false
+	}
+	log.Printf("DMR network not ready, aborting YSF call")
+	g.ysfCallAborted = true
+	g.endCall()
+	return true
+}
+
+// sendDMRFrame sends a DMR frame
+func (g *Gateway) sendDMRFrame(audioData []byte) error {
+	// Create DMR data structure
+	dmrData := protocol.GetDMRData()
+	defer protocol.PutDMRData(dmrData)
+	dstID, private := g.state.Destination()
+	slot, netDstID := g.toNetwork(dstID, private)
+	dmrData.SetSlotNo(slot)
+	dmrData.SetSrcId(g.config.GetDMRId())
+	dmrData.SetDstId(netDstID)
+	dmrData.SetFLCO(destinationFLCO(private))
+	dmrData.SetSeqNo(uint8(g.dmrFrames % 256))
+	dmrData.SetBER(g.ysfBER)
+	dmrData.SetRSSI(g.config.GetYSFRSSI())
+
+	// Copy audio data to payload - truncate if necessary
+	var payload [33]byte
+	copyLen := len(audioData)
+	if copyLen > 33 {
+		copyLen = 33
+	}
+	copy(payload[:], audioData[:copyLen])
+	g.processVoice(g.ysfAudio, payload[:], dstID, true)
+
+	// Burst A of each superframe carries the voice sync, B-F the EMB
+	n := g.dmrBursts.Voice(payload[:])
+	if n == 0 {
+		dmrData.SetDataType(protocol.DT_VOICE_SYNC)
+	} else {
+		dmrData.SetDataType(protocol.DT_VOICE)
+	}
+	dmrData.SetN(n)
+	dmrData.SetData(payload[:])
+	g.recordDMRBurst(payload[:])
+
+	// Send via network, or hold while it reconnects
+	return g.dmrOutput.Write(dmrData)
+}
+
+// refreshDMRCallSource shows a DMR caller found by an on-demand lookup for
+// the rest of the call
+func (g *Gateway) refreshDMRCallSource(id uint32) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if call := g.state.Call(); call.State != state.CallDMR || call.SrcID != id {
+		return
+	}
+	g.dmrCallSource = g.ysfCallsignForDMR(id)
+	log.Printf("DMR caller %d is %s", id, g.describeDMRUser(id))
+}
+
+// ysfSource returns the source callsign of frames sent toward YSF: the DMR
+// caller during a DMR->YSF call, otherwise the gateway callsign
+func (g *Gateway) ysfSource() string {
+	if g.state.CallState() == state.CallDMR && g.dmrCallSource != "" {
+		return g.dmrCallSource
+	}
+	return g.config.GetCallsign()
+}
+
+// ysfDestination returns the destination callsign and FICH call mode of
+// frames sent toward YSF: the called user in individual mode during a private
+// DMR->YSF call, otherwise ALL in the configured FICHCallMode
+func (g *Gateway) ysfDestination() (string, uint8) {
+	if g.state.CallState() == state.CallDMR && g.dmrCallPrivate {
+		return g.ysfCallsignForDMR(g.dmrCallDstID), ysf.CM_INDIVIDUAL
+	}
+	return "ALL", g.ysfFICH.CM
+}
+
+// newYSFFICH returns the FICH fields of voice frames sent toward YSF from the
+// FICH* keys
+// Both the codec chain and passthrough build VD mode 2 audio, so a
+// FICHDataType radios would decode as something else is not used.
+func newYSFFICH(cfg *config.Config) ysf.FICH {
+	fich := ysf.FICH{
+		CS:            cfg.GetFICHCallSign(),
+		CM:            cfg.GetFICHCallMode(),
+		FT:            cfg.GetFICHFrameTotal(),
+		MR:            cfg.GetFICHMessageRoute(),
+		VOIPIndicator: cfg.GetFICHVOIP(),
+		DT:            cfg.GetFICHDataType(),
+		SQL:           (cfg.GetFICHSQLType()&0x01)<<7 | cfg.GetFICHSQLCode()&0x7F,
+	}
+	if fich.DT != ysf.DT_VD_MODE2 {
+		log.Printf("FICHDataType=%d ignored, voice is sent to YSF as VD mode 2 (%d)", fich.DT, ysf.DT_VD_MODE2)
+		fich.DT = ysf.DT_VD_MODE2
+	}
+	return fich
+}
+
+// ysfFrameFICH returns the FICH of a frame sent toward YSF with frame
+// indicator fi and frame number fn
+func (g *Gateway) ysfFrameFICH(fi, fn uint8) ysf.FICH {
+	fich := g.ysfFICH
+	fich.FI = fi
+	fich.FN = fn
+	_, fich.CM = g.ysfDestination()
+	return fich
+}
+
+// sendYSFHeader sends the header (fi 0) or terminator (fi 2) of a DMR->YSF
+// call, with the DMR caller as the CSD1 source so radios show who is talking
+func (g *Gateway) sendYSFHeader(fi uint8) error {
+	dest, _ := g.ysfDestination()
+	frame := &ysf.Frame{
+		SourceCallsign: g.ysfSource(),
+		DestCallsign:   dest,
+		FICH:           g.ysfFrameFICH(fi, 0),
+		Payload:        ysf.BuildHeaderPayload(dest, g.ysfSource(), "", ""),
+	}
+
+	return g.ysfNetwork.Write(frame.Build())
+}
+
+// sendYSFFrame sends a YSF frame
+func (g *Gateway) sendYSFFrame(audioData []byte) error {
+	// Create YSF frame
+	dest, _ := g.ysfDestination()
+	frame := &ysf.Frame{
+		SourceCallsign: g.ysfSource(),
+		DestCallsign:   dest,
+		FICH:           g.ysfFrameFICH(1, uint8(g.ysfFrames%8)), // Communications
+		Payload:        make([]byte, 90),
+	}
+
+	// Copy audio data to payload
+	copy(frame.Payload, audioData)
+
+	// Build and send frame
+	frameData := frame.Build()
+	return g.ysfNetwork.Write(frameData)
+}
+
+// processYSFTimer handles YSF timing events
+func (g *Gateway) processYSFTimer() error {
+	g.ysfWatch = time.Now()
+	// The prompt after a cut-off goes out first, while the calls that could
+	// collide with it were just ended
+	if g.processTimeoutPrompt() {
+		return nil
+	}
+	if g.maintenance != nil && g.transmit(g.maintenance.announcement, "Maintenance announcement") {
+		return nil
+	}
+	// Only one data transmission goes out at a time; a beacon already under
+	// way finishes before a WiresX reply or text message starts
+	if g.beacon == nil || len(g.beacon.queue) == 0 {
+		if g.processYSFDataTX() {
+			return nil
+		}
+	}
+	g.processBeacon()
+	return nil
+}
+
+// processYSFDataTX sends the next frame of a WiresX reply or text message,
+// reporting whether one was sent
+// They wait until no call is in progress and the hang timers have expired,
+// so they do not collide with voice. A call that starts part way through
+// cancels the rest of a WiresX reply; a text message is sent again from its
+// first frame afterwards.
+func (g *Gateway) processYSFDataTX() bool {
+	if g.state.CallState() != state.CallIdle {
+		if len(g.ysfDataTX) > 0 {
+			if g.ysfDataText != nil {
+				log.Printf("YSF message interrupted by call, sending it again after the call")
+				g.ysfTexts = append([][][]byte{g.ysfDataText}, g.ysfTexts...)
+			} else {
+				log.Printf("WiresX reply interrupted by call, %d frames dropped", len(g.ysfDataTX))
+			}
+			g.ysfDataTX, g.ysfDataText = nil, nil
+		}
+		return false
+	}
+
+	if len(g.ysfDataTX) == 0 {
+		if g.hangActive(time.Now()) || !g.nextYSFDataTX() {
+			return false
+		}
+	}
+
+	if err := g.ysfNetwork.Write(g.ysfDataTX[0]); err != nil {
+		log.Printf("YSF data send error: %v", err)
+		g.ysfDataTX, g.ysfDataText = nil, nil
+		return false
+	}
+	g.ysfDataTX = g.ysfDataTX[1:]
+	if len(g.ysfDataTX) == 0 {
+		g.ysfDataText = nil
+	}
+	return true
+}
+
+// nextYSFDataTX starts the next WiresX reply or, when there is none, the next
+// text message, reporting whether there was one to start
+func (g *Gateway) nextYSFDataTX() bool {
+	if g.wiresX != nil {
+		if reply := g.wiresX.NextReply(); reply != nil {
+			g.ysfDataTX = ysf.BuildDataFrames(g.config.GetCallsign(), "ALL", reply)
+			if g.ysfDataTX == nil {
+				log.Printf("WiresX reply of %d bytes is too long to send", len(reply))
+				return false
+			}
+			return true
+		}
+	}
+	if len(g.ysfTexts) == 0 {
+		return false
+	}
+	g.ysfDataText = g.ysfTexts[0]
+	g.ysfDataTX = g.ysfDataText
+	g.ysfTexts = g.ysfTexts[1:]
+	return true
+}
+
+// processDMRTimer handles DMR timing events
+func (g *Gateway) processDMRTimer() error {
+	g.dmrWatch = time.Now()
+
+	// The master asks for a beacon with RPTSBKN
+	if g.dmrNetwork.WantsBeacon() {
+		g.requestBeacon("master request")
+	}
+
+	g.processDMRSMS()
+
+	// Check network watchdog
+	if time.Since(g.networkWatchdog) > 30*time.Second {
+		log.Printf("Network watchdog expired")
+		g.networkWatchdog = time.Now()
+		g.dmrFrames = 0
+	}
+
+	return nil
+}
+
+// processDMRSMS sends the next burst of an SMS toward DMR
+// Like the YSF data, SMS wait until no call is in progress and the hang
+// timers have expired; a call that starts part way through pauses the SMS,
+// which is sent again from its data header afterwards.
+func (g *Gateway) processDMRSMS() {
+	if g.state.CallState() != state.CallIdle {
+		if g.dmrSMS != nil && g.dmrSMSNext > 0 {
+			log.Printf("DMR SMS interrupted by call, sending it again after the call")
+			g.dmrSMSNext = 0
+		}
+		return
+	}
+
+	if g.dmrSMS == nil {
+		if len(g.dmrSMSList) == 0 || g.hangActive(time.Now()) {
+			return
+		}
+		g.dmrSMS, g.dmrSMSNext = g.dmrSMSList[0], 0
+		g.dmrSMSList = g.dmrSMSList[1:]
+	}
+
+	message := g.dmrSMS
+	burst := message.bursts[g.dmrSMSNext]
+	if err := g.sendDMRDataBurst(burst.dataType, burst.payload, message.slot, message.dstID, message.group); err != nil {
+		log.Printf("DMR SMS send error: %v", err)
+		g.dmrSMS = nil
+		return
+	}
+	g.dmrSMSNext++
+	if g.dmrSMSNext == len(message.bursts) {
+		g.dmrSMS = nil
+	}
+}
+
+// maintainDatabase runs the database maintenance routine in the background
+func (g *Gateway) maintainDatabase() {
+	if !atomic.CompareAndSwapInt32(&g.dbMaintaining, 0, 1) {
+		log.Printf("Database maintenance still running, skipping")
+		return
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&g.dbMaintaining, 0)
+
+		start := time.Now()
+		err := g.db.Maintain(database.MaintenanceConfig{
+			BackupDir:  g.config.GetDatabaseBackupDir(),
+			BackupKeep: int(g.config.GetDatabaseBackupKeep()),
+		})
+		if err != nil {
+			log.Printf("Database maintenance failed: %v", err)
+			return
+		}
+		log.Printf("Database maintenance completed in %v", time.Since(start).Round(time.Millisecond))
+	}()
+}
+
+// publishStats publishes the frame counters as a stats event
+func (g *Gateway) publishStats() {
+	ysfToDmr, dmrToYsf, convErrors := g.frameRatioConverter.GetConversionStats()
+	dstID, _ := g.state.Destination()
+
+	event := events.Event{
+		Type: events.Stats,
+		Fields: map[string]string{
+			"ysf_frames":          strconv.FormatUint(uint64(g.ysfFrames), 10),
+			"ysf_vw_dropped":      strconv.FormatUint(uint64(g.ysfVWFrames), 10),
+			"dmr_frames":          strconv.FormatUint(uint64(g.dmrFrames), 10),
+			"dmr_data_frames":     strconv.FormatUint(uint64(g.dmrDataFrames), 10),
+			"dmr_muted_frames":    strconv.FormatUint(uint64(g.dmrMutedFrames), 10),
+			"ysf_to_dmr":          fmt.Sprint(ysfToDmr),
+			"dmr_to_ysf":          fmt.Sprint(dmrToYsf),
+			"conversion_errors":   fmt.Sprint(convErrors),
+			"tg":                  strconv.FormatUint(uint64(dstID), 10),
+			"dmr_connected":       strconv.FormatBool(g.dmrNetwork.IsConnected()),
+			"wiresx_crc_errors":   strconv.FormatUint(g.wiresXCRCErrors(), 10),
+			"wiresx_frame_errors": strconv.FormatUint(g.wiresXFrameErrors(), 10),
+			"ysf_packets":         g.ysfPacketSummary(),
+		},
+	}
+	dmrHealth, ysfHealth := g.state.Health()
+	event.Fields["dmr_health"] = dmrHealth.String()
+	event.Fields["ysf_health"] = ysfHealth.String()
+	event.Fields["paused"] = strconv.FormatBool(g.state.Paused())
+	if peers := g.ysfPeerFilter(); peers != nil {
+		peer, auth := peers.Rejected()
+		event.Fields["ysf_rejected_peer"] = strconv.FormatUint(peer, 10)
+		event.Fields["ysf_rejected_auth"] = strconv.FormatUint(auth, 10)
+	}
+	addLatencyFields(event.Fields, "ysf_dmr", g.ysfLatency.Snapshot())
+	addLatencyFields(event.Fields, "dmr_ysf", g.dmrLatency.Snapshot())
+	g.events.Publish(event)
+}
+
+// addLatencyFields adds the latency percentiles of one direction to a stats
+// event, in milliseconds, once any latency has been measured
+func addLatencyFields(fields map[string]string, prefix string, s latency.Snapshot) {
+	if s.Count == 0 {
+		return
+	}
+	fields[prefix+"_latency_p50_ms"] = strconv.FormatFloat(milliseconds(s.Quantile(0.50)), 'f', 0, 64)
+	fields[prefix+"_latency_p95_ms"] = strconv.FormatFloat(milliseconds(s.Quantile(0.95)), 'f', 0, 64)
+	fields[prefix+"_latency_p99_ms"] = strconv.FormatFloat(milliseconds(s.Quantile(0.99)), 'f', 0, 64)
+}
+
+// latencySummary formats the latency percentiles of one direction for the log
+func latencySummary(s latency.Snapshot) string {
+	if s.Count == 0 {
+		return "no frames"
+	}
+	return fmt.Sprintf("p50 %v, p95 %v, p99 %v, max %v (%d frames)",
+		s.Quantile(0.50), s.Quantile(0.95), s.Quantile(0.99), s.Max.Round(time.Millisecond), s.Count)
+}
+
+// ysfPacketSummary formats the YSF packet counters as "type=count" pairs
+func (g *Gateway) ysfPacketSummary() string {
+	parts := make([]string, 0, len(g.ysfPackets))
+	for t, count := range g.ysfPackets {
+		parts = append(parts, fmt.Sprintf("%s=%d", ysf.PacketType(t), count))
+	}
+	return strings.Join(parts, " ")
+}
+
+// wiresXCRCErrors returns the number of WiresX commands rejected for a bad checksum
+func (g *Gateway) wiresXCRCErrors() uint64 {
+	if g.wiresX == nil {
+		return 0
+	}
+	return g.wiresX.CRCErrors()
+}
+
+// wiresXFrameErrors returns the number of WiresX command frames rejected for
+// an invalid or out of sequence frame number
+func (g *Gateway) wiresXFrameErrors() uint64 {
+	if g.wiresX == nil {
+		return 0
+	}
+	return g.wiresX.FrameErrors()
+}
+
+// printStats prints periodic statistics
+func (g *Gateway) printStats() {
+	for _, line := range g.statsLines() {
+		log.Print(line)
+	}
+}
+
+// Stats returns the statistics lines printed every 30 seconds
+// While Run is running they are taken on its main loop.
+func (g *Gateway) Stats() []string {
+	g.mu.RLock()
+	running := g.running
+	g.mu.RUnlock()
+	if running {
+		return g.requestStats()
+	}
+	return g.statsLines()
+}
+
+// statsLines returns the statistics lines printed by printStats
+// Must be called from the main loop, or once it has stopped.
+func (g *Gateway) statsLines() []string {
+	var lines []string
+
+	connectionStatus := "Disconnected"
+	dmrState := g.dmrNetwork.GetStatusString()
+	if g.dmrNetwork.IsConnected() {
+		connectionStatus = "Connected"
+	}
+
+	// Get Frame Ratio Converter statistics
+	ysfToDmr, dmrToYsf, convErrors := g.frameRatioConverter.GetConversionStats()
+	call := g.state.Call()
+
+	lines = append(lines, fmt.Sprintf("Stats: YSF frames: %d (VW dropped: %d), DMR frames: %d (data: %d), WiresX CRC errors: %d, Current TG: %d, DMR: %s (%s), State: %v",
+		g.ysfFrames, g.ysfVWFrames, g.dmrFrames, g.dmrDataFrames, g.wiresXCRCErrors(), call.DstID, connectionStatus, dmrState, call.State))
+	lines = append(lines, fmt.Sprintf("YSF packets: %s", g.ysfPacketSummary()))
+	dmrHealth, ysfHealth := g.state.Health()
+	lines = append(lines, fmt.Sprintf("Link health: DMR %v, YSF %v", dmrHealth, ysfHealth))
+	if g.state.Paused() {
+		lines = append(lines, "Bridging paused")
+	}
+	lines = append(lines, fmt.Sprintf("Codec: YSF→DMR: %d, DMR→YSF: %d, Conv Errors: %d, YSF Buffer: %v, DMR Buffer: %v",
+		ysfToDmr, dmrToYsf, convErrors,
+		g.frameRatioConverter.IsYSFBufferReady(), g.frameRatioConverter.IsDMRBufferReady()))
+	lines = append(lines, fmt.Sprintf("Latency: YSF→DMR %s; DMR→YSF %s",
+		latencySummary(g.ysfLatency.Snapshot()), latencySummary(g.dmrLatency.Snapshot())))
+	if g.repack != nil {
+		bursts, payloads := g.repack.GetConversionStats()
+		lines = append(lines, fmt.Sprintf("Passthrough: %d DMR bursts, %d YSF payloads repacked", bursts, payloads))
+	}
+	if g.scheduler != nil && g.scheduler.Overruns() > 0 {
+		lines = append(lines, fmt.Sprintf("Timing: %d scheduler deadlines missed", g.scheduler.Overruns()))
+	}
+	if g.beacon != nil {
+		lines = append(lines, fmt.Sprintf("Beacons sent: %d", g.beacon.sent))
+	}
+	if g.usrp != nil {
+		rx, tx, rxErrors := g.usrp.client.GetStats()
+		lines = append(lines, fmt.Sprintf("USRP packets: %d received (%d malformed), %d sent", rx, rxErrors, tx))
+	}
+	if g.voice != nil && g.voice.dropped.Load() > 0 {
+		lines = append(lines, fmt.Sprintf("Voice bursts dropped by the voice worker: %d", g.voice.dropped.Load()))
+	}
+	if g.ysfBlocked > 0 {
+		lines = append(lines, fmt.Sprintf("YSF calls blocked by radio ID: %d", g.ysfBlocked))
+	}
+	if g.dmrMutedFrames > 0 {
+		lines = append(lines, fmt.Sprintf("DMR muted talk groups: %d calls, %d frames", g.dmrMutedCalls, g.dmrMutedFrames))
+	}
+	if peers := g.ysfPeerFilter(); peers != nil {
+		if peer, auth := peers.Rejected(); peer > 0 || auth > 0 {
+			lines = append(lines, fmt.Sprintf("YSF packets rejected: %d from peers not allowed, %d not authenticated", peer, auth))
+		}
+	}
+	if stats, ok := g.ysfNetwork.(network.YSFPeerStats); ok && stats.PeerRebinds() > 0 {
+		lines = append(lines, fmt.Sprintf("YSF peer moved address %d times", stats.PeerRebinds()))
+	}
+	if g.idLookup != nil {
+		stats := g.idLookup.Stats()
+		lines = append(lines, fmt.Sprintf("RadioID lookups: %d found, %d not registered, %d failed, %d dropped",
+			stats.Found, stats.NotFound, stats.Failed, stats.Dropped))
+	}
+	if g.dmrDataCall.CRCErrors() > 0 {
+		lines = append(lines, fmt.Sprintf("DMR data calls dropped for CRC errors: %d", g.dmrDataCall.CRCErrors()))
+	}
+	if loops, ok := g.dmrNetwork.(network.LoopDetector); ok && loops.LoopsDetected() > 0 {
+		lines = append(lines, fmt.Sprintf("DMR loops: %d of our own frames echoed back and dropped", loops.LoopsDetected()))
+	}
+	if out := g.dmrOutput.Stats(); out.Queued > 0 || out.Dropped > 0 || out.Expired > 0 {
+		lines = append(lines, fmt.Sprintf("DMR output: sent %d, queued %d (waiting %d), dropped %d, expired %d",
+			out.Sent, out.Queued, g.dmrOutput.Len(), out.Dropped, out.Expired))
+	}
+	return lines
+}
+
+// startYSFCall starts a new call from YSF
+func (g *Gateway) startYSFCall(srcCallsign string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	log.Printf("Starting YSF call from %s", srcCallsign)
+	g.state.StartYSFCall()
+	g.ysfVWLogged = false
+	g.ysfBER, g.ysfErrors, g.ysfChecked = 0, 0, 0
+	g.ysfCallDropped = 0
+	g.ysfCallAborted = false
+
+	dstID, private := g.state.Destination()
+	g.publishCallStart("YSF->DMR", srcCallsign, dstID, private)
+	g.startRecording(recorder.Metadata{
+		Source:  "YSF",
+		Src:     srcCallsign,
+		Dst:     g.formatDestination(),
+		DstID:   dstID,
+		Private: private,
+	})
+
+	// Reset frame ratio converter for clean state
+	g.frameRatioConverter.Reset()
+	if g.repack != nil {
+		g.repack.Reset()
+	}
+
+	// A new call takes over from any hang
+	g.rfHang.stop()
+	g.netHang.stop()
+}
+
+// startDMRCall starts a new call from DMR; flco tells a group call from a
+// private call to a user
+func (g *Gateway) startDMRCall(srcId, dstId, streamId uint32, flco uint8) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Format IDs with callsign lookup (matching C++ behavior)
+	private := flco == protocol.FLCO_USER_USER
+	srcStr := g.formatDMRAddress(srcId, false) // Source is never a group
+	dstStr := g.formatDMRAddress(dstId, !private)
+
+	log.Printf("Starting DMR call from %s to %s (stream 0x%08X)", g.describeDMRUser(srcId), dstStr, streamId)
+	g.state.StartDMRCall(srcId, streamId)
+	g.dmrCallDstID = dstId
+	g.dmrCallPrivate = private
+	g.dmrCallSource = g.ysfCallsignForDMR(srcId)
+	g.dmrCallAlias = ""
+	g.dmrLateEntry = false
+	g.dmrEmbeddedLC.Reset()
+	g.dmrTalkerAlias.Reset()
+	g.dmrQuality.Reset()
+
+	g.publishCallStart("DMR->YSF", srcStr, dstId, private)
+	g.startRecording(recorder.Metadata{
+		Source:  "DMR",
+		Src:     srcStr,
+		Dst:     dstStr,
+		SrcID:   srcId,
+		DstID:   dstId,
+		Private: private,
+	})
+
+	// Reset frame ratio converter for clean state
+	g.frameRatioConverter.Reset()
+	if g.repack != nil {
+		g.repack.Reset()
+	}
+
+	// A new call takes over from any hang
+	g.rfHang.stop()
+	g.netHang.stop()
+}
+
+// endCall ends the current call and starts the hang timer for its direction
+func (g *Gateway) endCall() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	call := g.state.Call()
+	var hang, inhibit *callHang
+	var tg uint32
+	switch call.State {
+	case state.CallYSF:
+		hang, inhibit, tg = &g.rfHang, &g.rfInhibit, call.DstID
+	case state.CallDMR:
+		hang, inhibit, tg = &g.netHang, &g.netInhibit, g.dmrCallDstID
+		g.dmrEndedStream = call.Stream
+	default:
+		return
+	}
+
+	log.Printf("Ending call, starting %s hang timer (%v)", hang.name, hang.duration)
+	if call.State == state.CallYSF && g.ysfChecked > 0 {
+		log.Printf("YSF BER: %.1f%%", float64(g.ysfErrors)*100/float64(g.ysfChecked))
+	}
+	if call.State == state.CallYSF && g.ysfCallDropped > 0 {
+		log.Printf("%d frames of the call were not delivered to DMR", g.ysfCallDropped)
+	}
+	if call.State == state.CallDMR {
+		if seq := g.dmrSequenceStats(); seq != (network.SequenceStats{}) {
+			log.Printf("DMR sequence: %d duplicates dropped, %d reordered, %d lost",
+				seq.Duplicates, seq.Reordered, seq.Gaps)
+		}
+	}
+	g.state.EndCall()
+	g.endUSRPAudio()
+	g.ysfLatency.Reset()
+	g.dmrLatency.Reset()
+	g.stopRecording()
+	g.publishCallEnd()
+	g.callEndReason = ""
+	now := time.Now()
+	hang.start(now, tg)
+	inhibit.start(now, 0)
+}
+
+// checkHangTimer reports hang timers that have expired
+func (g *Gateway) checkHangTimer() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	for _, hang := range []*callHang{&g.rfHang, &g.netHang} {
+		if !hang.until.IsZero() && !hang.active(now) {
+			log.Printf("%s hang timer expired", hang.name)
+			hang.stop()
+		}
+	}
+}
+
+// checkCallDuration cuts off a call that has run longer than
+// MaxCallDuration, protecting the talk group from a stuck transmitter
+// The other side gets a terminator and the rest of the transmission is
+// dropped.
+func (g *Gateway) checkCallDuration() {
+	if g.maxCallDuration <= 0 {
+		return
+	}
+	call := g.state.Call()
+	if call.State == state.CallIdle || time.Since(g.callStart) < g.maxCallDuration {
+		return
+	}
+
+	log.Printf("%s call from %s exceeded %v, cutting it off", g.callDirection, g.callCallsign, g.maxCallDuration)
+	switch call.State {
+	case state.CallYSF:
+		g.sendDMRFullLC(protocol.DT_TERMINATOR_WITH_LC)
+		g.ysfCallAborted = true
+	case state.CallDMR:
+		if err := g.sendYSFHeader(2); err != nil {
+			log.Printf("YSF terminator send error: %v", err)
+		}
+		g.heldStream = call.Stream
+	}
+	g.callEndReason = "timeout"
+	g.endCall()
+
+	if g.timeoutPrompt != nil {
+		g.timeoutPrompt.pending = true
+	}
+}
+
+// hangActive reports whether either hang timer is running at now
+func (g *Gateway) hangActive(now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.rfHang.active(now) || g.netHang.active(now)
+}
+
+// txInhibited reports whether a call may not start because inhibit, which
+// follows a call the other way, is running
+func (g *Gateway) txInhibited(inhibit *callHang) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return inhibit.active(time.Now())
+}
+
+// holdDMRCall reports whether a DMR group call must not be relayed to YSF
+// because a hang timer is holding a different talkgroup
+func (g *Gateway) holdDMRCall(data *protocol.DMRData) bool {
+	if !data.IsGroupCall() {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	for _, hang := range []*callHang{&g.rfHang, &g.netHang} {
+		if hang.active(now) && data.GetDstId() != hang.tg {
+			return true
+		}
+	}
+	return false
+}
+
+// monitorNetworkHealth checks network connection status and handles recovery
+func (g *Gateway) monitorNetworkHealth() {
+	now := time.Now()
+
+	// Check DMR network connection
+	connected := g.dmrNetwork.IsConnected()
+	if g.state.SetDMRLink(connected, now) {
+		eventType := events.LinkDown
+		if connected {
+			eventType = events.LinkUp
+		}
+		g.events.Publish(events.Event{
+			Type:   eventType,
+			Source: "DMR",
+			Fields: map[string]string{
+				"address": fmt.Sprintf("%s:%d", g.config.GetDMRNetworkAddress(), g.config.GetDMRNetworkPort()),
+			},
+		})
+	}
+
+	if connected {
+		g.state.ResetDMRErrors() // Reset error count when connected
+	} else {
+		// DMR not connected - check if we need to attempt reconnection
+		if _, lastConnected := g.state.DMRLink(); now.Sub(lastConnected) > DMR_CONNECTION_CHECK {
+			if g.dmrReconnectTimer == nil {
+				log.Printf("DMR network disconnected, scheduling reconnection...")
+				g.scheduleReconnect()
+			}
+		}
+	}
+
+	g.scoreLinks(now)
+
+	// YSF peer silent or socket closed - reopen it, failing over if configured
+	if _, ysfHealth := g.state.Health(); ysfHealth == state.HealthDown && g.ysfReconnectTimer == nil {
+		log.Printf("YSF network down, scheduling reconnection...")
+		g.scheduleYSFReconnect()
+	}
+
+	// Reset error counts periodically
+	if now.Sub(g.networkWatchdog) > NETWORK_ERROR_RESET_TIME {
+		if ysfErrors, dmrErrors := g.state.ResetNetworkErrors(); ysfErrors > 0 || dmrErrors > 0 {
+			log.Printf("Resetting network error counts (YSF: %d, DMR: %d)", ysfErrors, dmrErrors)
+		}
+		g.networkWatchdog = now
+	}
+}
+
+// ysfPeerFilter returns the YSF network's peer filter, nil if it has none
+func (g *Gateway) ysfPeerFilter() *network.YSFPeerFilter {
+	if stats, ok := g.ysfNetwork.(network.YSFPeerStats); ok {
+		return stats.PeerFilter()
+	}
+	return nil
+}
+
+// scoreLinks judges the health of both links from the keepalives and data
+// received on them, so a link that stays logged in but carries nothing is
+// noticed
+func (g *Gateway) scoreLinks(now time.Time) {
+	var keepalive, data time.Time
+	if activity, ok := g.dmrNetwork.(network.LinkActivity); ok {
+		keepalive, data = activity.LastReceived()
+	}
+	health, reason := state.Score(g.dmrNetwork.IsConnected(), keepalive, data, state.HealthPolicy{
+		KeepaliveInterval: DMR_PING_INTERVAL,
+		DataTimeout:       time.Duration(g.config.GetDMRDataTimeout()) * time.Second,
+	}, now)
+	if g.state.SetDMRHealth(health) {
+		logHealth("DMR", health, reason)
+	}
+
+	keepalive, data = time.Time{}, time.Time{}
+	if activity, ok := g.ysfNetwork.(network.LinkActivity); ok {
+		keepalive, data = activity.LastReceived()
+	}
+	health, reason = state.Score(g.ysfNetwork.IsOpen(), keepalive, data, state.HealthPolicy{
+		KeepaliveInterval: YSF_POLL_INTERVAL,
+		DataTimeout:       time.Duration(g.config.GetYSFDataTimeout()) * time.Second,
+	}, now)
+	if g.state.SetYSFHealth(health) {
+		logHealth("YSF", health, reason)
+	}
+}
+
+func logHealth(link string, health state.Health, reason string) {
+	if health == state.HealthOK {
+		log.Printf("%s link health %v", link, health)
+		return
+	}
+	log.Printf("%s link health %v: %s", link, health, reason)
+}
+
+// scheduleReconnect schedules a DMR network reconnection attempt
+func (g *Gateway) scheduleReconnect() {
+	if g.dmrReconnectTimer != nil {
+		g.dmrReconnectTimer.Stop()
+	}
+
+	g.dmrReconnectTimer = time.AfterFunc(DMR_RECONNECT_INTERVAL, func() {
+		select {
+		case g.dmrReconnect <- struct{}{}:
+		default: // already pending
+		}
+	})
+}
+
+// attemptReconnect attempts to reconnect the DMR network
+func (g *Gateway) attemptReconnect() {
+	log.Printf("Attempting DMR network reconnection...")
+
+	// Close existing connection
+	g.dmrNetwork.Close()
+
+	// Attempt to reopen
+	if err := g.dmrNetwork.Open(); err != nil {
+		log.Printf("DMR reconnection failed: %v", err)
+
+		if g.state.AddDMRError() < MAX_NETWORK_ERRORS {
+			g.scheduleReconnect() // Try again
+		} else {
+			log.Printf("Maximum DMR reconnection attempts reached, giving up")
+		}
+	} else {
+		log.Printf("DMR network reconnected successfully")
+		g.dmrNetwork.Enable(true)
+		g.state.ResetDMRErrors()
+		g.state.SetDMRConnected(time.Now())
+
+		if g.dmrReconnectTimer != nil {
+			g.dmrReconnectTimer.Stop()
+			g.dmrReconnectTimer = nil
+		}
+	}
+}
+
+// scheduleYSFReconnect schedules a YSF network reconnection attempt
+func (g *Gateway) scheduleYSFReconnect() {
+	if g.ysfReconnectTimer != nil {
+		g.ysfReconnectTimer.Stop()
+	}
+
+	g.ysfReconnectTimer = time.AfterFunc(YSF_RECONNECT_INTERVAL, func() {
+		select {
+		case g.ysfReconnect <- struct{}{}:
+		default: // already pending
+		}
+	})
+}
+
+// attemptYSFReconnect reopens the YSF socket, moving to the next configured
+// destination first
+func (g *Gateway) attemptYSFReconnect() {
+	if _, ysfHealth := g.state.Health(); ysfHealth != state.HealthDown {
+		log.Printf("YSF network recovered, reconnection cancelled")
+		g.ysfReconnectTimer = nil
+		return
+	}
+	log.Printf("Attempting YSF network reconnection...")
+
+	g.ysfNetwork.Close()
+
+	if len(g.ysfTargets) > 1 {
+		g.ysfTargetIndex = (g.ysfTargetIndex + 1) % len(g.ysfTargets)
+		target := g.ysfTargets[g.ysfTargetIndex]
+		if err := g.ysfNetwork.SetDestinationByString(target.address, target.port); err != nil {
+			log.Printf("YSF failover to %s:%d failed: %v", target.address, target.port, err)
+		} else {
+			log.Printf("YSF destination failed over to %s:%d", target.address, target.port)
+		}
+	}
+
+	if err := g.ysfNetwork.Open(); err != nil {
+		log.Printf("YSF reconnection failed: %v", err)
+
+		if g.state.AddYSFError() < MAX_NETWORK_ERRORS {
+			g.scheduleYSFReconnect() // Try again
+		} else {
+			log.Printf("Maximum YSF reconnection attempts reached, giving up")
+		}
+		return
+	}
+
+	log.Printf("YSF network reopened")
+	g.state.ResetYSFErrors()
+	g.ysfReconnectTimer = nil
+	if err := g.ysfNetwork.WritePoll(); err != nil {
+		log.Printf("YSF poll error: %v", err)
+	}
+}
+
+// handleNetworkError increments error count and triggers recovery if needed
+func (g *Gateway) handleNetworkError(network string, err error) {
+	if err == nil {
+		return
+	}
+
+	log.Printf("%s network error: %v", network, err)
+
+	if network == "YSF" {
+		g.state.AddYSFError()
+		if !g.ysfNetwork.IsOpen() && g.ysfReconnectTimer == nil {
+			g.scheduleYSFReconnect()
+		}
+	} else if network == "DMR" {
+		g.state.AddDMRError()
+		if !g.dmrNetwork.IsConnected() && g.dmrReconnectTimer == nil {
+			g.scheduleReconnect()
+		}
+	}
+}
+
+// initializeDMRLookup creates either a database-backed or file-based DMR lookup service
+// Returns the lookup interface, database instance (if database mode), syncer (if database mode)
+// and on-demand ID lookup (if enabled in database mode)
+func initializeDMRLookup(cfg *config.Config) (lookup.DMRLookupInterface, *database.DB, *radioid.Syncer, *radioid.IDLookup) {
+	// Check if database mode is enabled
+	if cfg.GetDatabaseEnabled() {
+		log.Printf("Initializing database-backed DMR lookup...")
+
+		// Create database with configuration
+		dbConfig := database.Config{
+			Driver: cfg.GetDatabaseDriver(),
+			Path:   cfg.GetDatabasePath(),
+			DSN:    cfg.GetDatabaseDSN(),
+		}
+
+		db, err := database.NewDB(dbConfig, log.New(os.Stdout, "[DB] ", log.LstdFlags))
+		if err != nil {
+			log.Printf("Failed to initialize database: %v", err)
+			log.Printf("Falling back to file-based lookup...")
+			return initializeFileLookup(cfg), nil, nil, nil
+		}
+
+		// Create repository
+		userRepo := database.NewDMRUserRepository(db.GetDB())
+
+		// Create database adapter with configuration
+		cacheSize := cfg.GetDatabaseCacheSize()
+		if cacheSize == 0 {
+			cacheSize = 1000 // Default
+		}
+
+		adapterConfig := lookup.DMRDatabaseAdapterConfig{
+			EnableCache:    true,
+			CacheSize:      int(cacheSize),
+			CacheExpiry:    5 * time.Minute,
+			EnableSnapshot: cfg.GetDatabaseSnapshot(),
+		}
+		adapter := lookup.NewDMRDatabaseAdapterWithConfig(userRepo, adapterConfig)
+		adapter.SetDebug(cfg.GetDatabaseDebug())
+
+		// Start the adapter
+		if err := adapter.Start(); err != nil {
+			log.Printf("Failed to start database adapter: %v", err)
+			log.Printf("Falling back to file-based lookup...")
+			db.Close()
+			return initializeFileLookup(cfg), nil, nil, nil
+		}
+
+		// Create and start RadioID syncer
+		syncHours := cfg.GetDatabaseSyncHours()
+		if syncHours == 0 {
+			syncHours = 24 // Default
+		}
+
+		syncerConfig := radioid.SyncerConfig{
+			SyncInterval:       time.Duration(syncHours) * time.Hour,
+			HTTPTimeout:        30 * time.Second,
+			AlertAfterFailures: int(cfg.GetDatabaseSyncAlertFailures()),
+			Filter:             radioid.ParseFilter(cfg.GetDatabaseSyncFilter()),
+		}
+
+		syncer := radioid.NewSyncerWithConfig(userRepo, log.New(os.Stdout, "[SYNC] ", log.LstdFlags), syncerConfig)
+		syncer.SetOnSync(adapter.RefreshSnapshot) // Pick up the new data without per-call queries
+		adapter.SetSyncStatus(syncer.StatusMap)
+
+		// Start syncer in background
+		go syncer.Start(context.Background())
+
+		// Look up IDs missing from the database on RadioID.net as they are heard
+		var idLookup *radioid.IDLookup
+		if cfg.GetDatabaseOnDemandLookup() {
+			idLookup = radioid.NewIDLookup(userRepo, log.New(os.Stdout, "[SYNC] ", log.LstdFlags), radioid.IDLookupConfig{})
+			adapter.SetMissHandler(idLookup.Request)
+			log.Printf("On-demand RadioID lookup of unknown IDs enabled")
+		}
+
+		count := adapter.GetEntryCount()
+		log.Printf("Database-backed DMR lookup initialized with %d entries", count)
+
+		return adapter, db, syncer, idLookup
+	}
+
+	// Fall back to file-based lookup
+	return initializeFileLookup(cfg), nil, nil, nil
+}
+
+// initializeHooks creates the hook runner and subscribes it to gateway events
+// Returns nil when no hook commands are configured.
+func initializeHooks(cfg *config.Config) *hooks.Runner {
+	runner := hooks.NewRunner(map[events.Type]string{
+		events.CallStart: cfg.GetHookCallStart(),
+		events.CallEnd:   cfg.GetHookCallEnd(),
+		events.LinkUp:    cfg.GetHookLinkUp(),
+		events.LinkDown:  cfg.GetHookLinkDown(),
+		events.Emergency: cfg.GetHookEmergency(),
+	}, time.Duration(cfg.GetHookTimeout())*time.Second, int(cfg.GetHookMaxConcurrent()))
+
+	if !runner.Enabled() {
+		return nil
+	}
+	log.Printf("Event hooks enabled (timeout %ds, max %d concurrent)",
+		cfg.GetHookTimeout(), cfg.GetHookMaxConcurrent())
+	return runner
+}
+
+// initializeRecorder creates the call recorder when recording is enabled
+func initializeRecorder(cfg *config.Config) (*recorder.Recorder, error) {
+	if !cfg.GetRecordingEnabled() {
+		return nil, nil
+	}
+
+	var transcoder codec.Transcoder
+	if name := cfg.GetRecordingTranscoder(); name != "" {
+		t, err := codec.NewTranscoder(name, cfg.GetRecordingTranscoderArg())
+		if err != nil {
+			log.Printf("Recorder: WAV output disabled: %v", err)
+		} else {
+			transcoder = t
+		}
+	}
+
+	maxAge := time.Duration(cfg.GetRecordingMaxAgeDays()) * 24 * time.Hour
+	maxBytes := int64(cfg.GetRecordingMaxSizeMB()) * 1024 * 1024
+
+	rec, err := recorder.NewRecorder(cfg.GetRecordingDirectory(), maxAge, maxBytes, transcoder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize call recorder: %v", err)
+	}
+	if err := rec.Prune(); err != nil {
+		log.Printf("Recorder: %v", err)
+	}
+
+	log.Printf("Call recording enabled: %s (max %d days, %d MB)",
+		cfg.GetRecordingDirectory(), cfg.GetRecordingMaxAgeDays(), cfg.GetRecordingMaxSizeMB())
+	return rec, nil
+}
+
+// initializeBeacon loads the beacon text and voice when beacons are enabled
+func initializeBeacon(cfg *config.Config, catalog *i18n.Catalog) (*beacon, error) {
+	if !cfg.GetBeaconEnabled() {
+		return nil, nil
+	}
+
+	b, err := newBeacon(cfg.GetBeaconText(), catalog.Prompt(cfg.GetBeaconVoice()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize beacon: %v", err)
+	}
+
+	if cfg.GetBeaconInterval() > 0 {
+		log.Printf("Beacon enabled: every %ds and on master request", cfg.GetBeaconInterval())
+	} else {
+		log.Printf("Beacon enabled: on master request")
+	}
+	return b, nil
+}
+
+// initializeFileLookup creates a traditional file-based DMR lookup
+func initializeFileLookup(cfg *config.Config) lookup.DMRLookupInterface {
+	if cfg.GetDMRIdLookupFile() == "" {
+		log.Printf("DMR ID lookup disabled (no file configured and database mode disabled)")
+		return nil
+	}
+
+	dmrLookup := lookup.NewDMRLookup(
+		cfg.GetDMRIdLookupFile(),
+		cfg.GetDMRIdLookupTime(),
+	)
+	dmrLookup.SetDebug(cfg.GetDatabaseDebug()) // Use same debug setting
+
+	// Start the lookup service
+	if err := dmrLookup.Start(); err != nil {
+		log.Printf("Warning: Failed to start file-based DMR ID lookup: %v", err)
+		return nil // Disable lookup on error
+	}
+
+	log.Printf("File-based DMR ID lookup initialized with %d entries from %s",
+		dmrLookup.GetEntryCount(), cfg.GetDMRIdLookupFile())
+
+	return dmrLookup
+}
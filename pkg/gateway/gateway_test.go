@@ -0,0 +1,226 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/dmr"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+	"github.com/dbehnke/ysf2dmr/internal/state"
+	"github.com/dbehnke/ysf2dmr/internal/testutil"
+)
+
+// runGateway runs a gateway on in-memory networks until the test ends
+func runGateway(t *testing.T) (*testutil.YSFNetwork, *testutil.DMRNetwork) {
+	t.Helper()
+	_, ysfNet, dmrNet := startGateway(t)
+	return ysfNet, dmrNet
+}
+
+// startGateway is runGateway, also returning the gateway; settings change the
+// test configuration
+func startGateway(t *testing.T, settings ...func(*ConfigBuilder)) (*Gateway, *testutil.YSFNetwork, *testutil.DMRNetwork) {
+	t.Helper()
+	builder := NewConfig().
+		Set("YSF Network", "Callsign", "N0CALL").
+		Set("YSF Network", "DstAddress", "127.0.0.1").
+		SetInt("YSF Network", "DstPort", 42000).
+		SetInt("DMR Network", "Id", 3100001).
+		SetInt("DMR Network", "StartupDstId", 91).
+		SetBool("DMR Network", "RestoreDestination", false).
+		Set("Log", "FilePath", t.TempDir())
+	for _, setting := range settings {
+		setting(builder)
+	}
+	cfg, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	ysfNet, dmrNet := testutil.NewYSFNetwork(), testutil.NewDMRNetwork()
+	g, err := New(cfg, WithYSFNetwork(ysfNet), WithDMRNetwork(dmrNet), WithVersion("test"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- g.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	})
+	return g, ysfNet, dmrNet
+}
+
+func TestNewUsesNetworkOptions(t *testing.T) {
+	ysfNet, _ := runGateway(t)
+	if got := ysfNet.Destination(); got != "127.0.0.1:42000" {
+		t.Errorf("YSF destination = %q, want the configured DstAddress", got)
+	}
+}
+
+func TestYSFCallReachesDMR(t *testing.T) {
+	ysfNet, dmrNet := runGateway(t)
+
+	header := &ysf.Frame{
+		SourceCallsign: "N0CALL",
+		DestCallsign:   "ALL",
+		FICH:           ysf.FICH{FI: 0, DT: 2},
+		Payload:        ysf.BuildHeaderPayload("ALL", "N0CALL", "", ""),
+	}
+	ysfNet.Inject(header.Build())
+
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		for _, frame := range dmrNet.Written() {
+			if frame.GetDataType() == protocol.DT_VOICE_LC_HEADER {
+				if frame.GetSrcId() != 3100001 || frame.GetDstId() != 91 {
+					t.Errorf("DMR header %d -> %d, want 3100001 -> 91", frame.GetSrcId(), frame.GetDstId())
+				}
+				if burst := frame.GetData(); !bytes.Equal(burst[14:19], dmr.BS_SOURCED_DATA_SYNC[1:6]) {
+					t.Errorf("DMR header sync % X, want BS sourced data sync", burst[13:20])
+				}
+				return
+			}
+		}
+	}
+	t.Fatal("YSF header did not start a DMR call")
+}
+
+func TestYSFCallIsNeverEmergency(t *testing.T) {
+	g, ysfNet, dmrNet := startGateway(t, func(b *ConfigBuilder) {
+		b.SetInt("YSF Network", "NetHangTime", 0)
+	})
+
+	// An emergency call from DMR...
+	lc := &dmr.LinkControl{FLCO: dmr.FLCO_GROUP_CALL, SourceID: 3109999, DestinationID: 91}
+	lc.SetEmergency(true)
+	for seq, dataType := range []uint8{protocol.DT_VOICE_LC_HEADER, protocol.DT_TERMINATOR_WITH_LC} {
+		burst, err := dmr.BuildFullLCBurst(lc, dataType, 1)
+		if err != nil {
+			t.Fatalf("BuildFullLCBurst() error = %v", err)
+		}
+		data := protocol.NewDMRData()
+		data.SetSlotNo(2)
+		data.SetSrcId(3109999)
+		data.SetDstId(91)
+		data.SetFLCO(protocol.FLCO_GROUP)
+		data.SetStreamId(0x5678)
+		data.SetSeqNo(uint8(seq))
+		data.SetDataType(dataType)
+		data.SetData(burst)
+		dmrNet.Inject(data)
+
+		for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+			call := g.state.Call()
+			if dataType == protocol.DT_VOICE_LC_HEADER && call.Emergency ||
+				dataType == protocol.DT_TERMINATOR_WITH_LC && call.State == state.CallIdle {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("call = %+v after the DMR %#x", call, dataType)
+			}
+		}
+	}
+
+	// ...is not passed on by the YSF call that follows
+	startYSFCall(t, ysfNet, dmrNet)
+	for _, frame := range dmrNet.Written() {
+		if frame.GetDataType() != protocol.DT_VOICE_LC_HEADER {
+			continue
+		}
+		burst := frame.GetData()
+		lc, err := dmr.DecodeFullLCBurst(burst[:], protocol.DT_VOICE_LC_HEADER)
+		if err != nil {
+			t.Fatalf("DecodeFullLCBurst() error = %v", err)
+		}
+		if lc.IsEmergency() {
+			t.Error("YSF call sent to DMR with the emergency flag")
+		}
+	}
+	if g.state.Call().Emergency {
+		t.Error("YSF call flagged as an emergency")
+	}
+}
+
+func TestDMRTalkerAliasReachesYSF(t *testing.T) {
+	ysfNet, dmrNet := runGateway(t)
+
+	// A late entry from an unknown ID whose bursts carry a talker alias header
+	var alias dmr.LinkControl
+	alias.Decode([]byte{dmr.FLCO_TALKER_ALIAS_HEADER, 0, dmr.TALKER_ALIAS_8BIT<<6 | 5<<1, 'K', '1', 'A', 'B', 'C', 0})
+	bursts := dmr.NewBurstAssembler(1, dmr.BS_SOURCED)
+	bursts.StartVoice(&alias)
+
+	for seq := 0; seq < 12; seq++ {
+		data := protocol.NewDMRData()
+		data.SetSlotNo(2)
+		data.SetSrcId(3109999)
+		data.SetDstId(91)
+		data.SetFLCO(protocol.FLCO_GROUP)
+		data.SetStreamId(0x1234)
+		data.SetSeqNo(uint8(seq))
+		var burst [33]byte
+		n := bursts.Voice(burst[:])
+		if n == 0 {
+			data.SetDataType(protocol.DT_VOICE_SYNC)
+		} else {
+			data.SetDataType(protocol.DT_VOICE)
+		}
+		data.SetN(n)
+		data.SetData(burst[:])
+		dmrNet.Inject(data)
+	}
+
+	// The YSF terminator carries the caller shown for the call
+	terminator := protocol.NewDMRData()
+	terminator.SetSlotNo(2)
+	terminator.SetSrcId(3109999)
+	terminator.SetDstId(91)
+	terminator.SetFLCO(protocol.FLCO_GROUP)
+	terminator.SetStreamId(0x1234)
+	terminator.SetSeqNo(12)
+	terminator.SetDataType(protocol.DT_TERMINATOR_WITH_LC)
+	dmrNet.Inject(terminator)
+
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		for _, packet := range ysfNet.Sent() {
+			if bytes.Contains(packet, []byte("K1ABC")) {
+				return
+			}
+		}
+	}
+	t.Fatal("talker alias not shown as the YSF source")
+}
+
+func TestDMRCallYSFHeaderFICH(t *testing.T) {
+	_, ysfNet, dmrNet := startGateway(t, func(b *ConfigBuilder) {
+		b.Set("YSF Network", "FICHDataType", "0")
+		b.Set("YSF Network", "FICHSQLType", "1")
+		b.Set("YSF Network", "FICHSQLCode", "42")
+	})
+	injectDMRHeader(dmrNet)
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		sent := ysfNet.Sent()
+		if len(sent) == 0 {
+			continue
+		}
+		var frame ysf.Frame
+		if err := frame.Parse(sent[0]); err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		fich := frame.FICH
+		if fich.FI != 0 || fich.DT != ysf.DT_VD_MODE2 || fich.CS != 2 || fich.FT != 7 || fich.SQL != 0x80|42 {
+			t.Errorf("header FICH = FI %d DT %d CS %d FT %d SQL %#x, want FI 0 DT 2 CS 2 FT 7 SQL 0xaa",
+				fich.FI, fich.DT, fich.CS, fich.FT, fich.SQL)
+		}
+		return
+	}
+	t.Fatal("DMR call not bridged to YSF")
+}
@@ -0,0 +1,67 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/latency"
+)
+
+// latencyBucket is one histogram bucket in the /api/latency response
+// LEMs is 0 for the overflow bucket.
+type latencyBucket struct {
+	LEMs  float64 `json:"le_ms"`
+	Count uint64  `json:"count"`
+}
+
+// latencyReport summarizes one direction in the /api/latency response
+type latencyReport struct {
+	Count   uint64          `json:"count"`
+	MeanMs  float64         `json:"mean_ms"`
+	P50Ms   float64         `json:"p50_ms"`
+	P95Ms   float64         `json:"p95_ms"`
+	P99Ms   float64         `json:"p99_ms"`
+	MaxMs   float64         `json:"max_ms"`
+	Buckets []latencyBucket `json:"buckets"`
+}
+
+// newLatencyReport converts a histogram snapshot for the API
+func newLatencyReport(s latency.Snapshot) latencyReport {
+	report := latencyReport{
+		Count: s.Count,
+		P50Ms: milliseconds(s.Quantile(0.50)),
+		P95Ms: milliseconds(s.Quantile(0.95)),
+		P99Ms: milliseconds(s.Quantile(0.99)),
+		MaxMs: milliseconds(s.Max),
+	}
+	if s.Count > 0 {
+		report.MeanMs = milliseconds(s.Sum) / float64(s.Count)
+	}
+	for _, b := range s.Buckets {
+		report.Buckets = append(report.Buckets, latencyBucket{LEMs: milliseconds(b.LE), Count: b.Count})
+	}
+	return report
+}
+
+// latencyHandler serves GET /api/latency with the latency histogram of each direction
+func latencyHandler(ysfToDMR, dmrToYSF *latency.Tracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]latencyReport{
+			"ysf_to_dmr": newLatencyReport(ysfToDMR.Snapshot()),
+			"dmr_to_ysf": newLatencyReport(dmrToYSF.Snapshot()),
+		})
+	})
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
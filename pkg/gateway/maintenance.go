@@ -0,0 +1,155 @@
+package gateway
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/i18n"
+)
+
+// maintenanceCheckInterval is how often the gateway checks whether it has
+// entered or left the maintenance window
+const maintenanceCheckInterval = 10 * time.Second
+
+// maintenanceWindow is a daily period during which bridging is paused,
+// e.g. while the reflector or master restarts
+type maintenanceWindow struct {
+	start    time.Duration // from local midnight
+	duration time.Duration
+	days     map[time.Weekday]bool // days the window starts on, every day when empty
+	unlink   bool
+
+	announcement *beacon // sent toward YSF on entering the window, nil for none
+
+	active    bool
+	paused    bool   // the window paused bridging, so it resumes it
+	savedDst  uint32 // destination unlinked by the window
+	savedPriv bool
+}
+
+// weekdays are the Days names of the maintenance window
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// initializeMaintenance creates the maintenance window when it is enabled
+func initializeMaintenance(cfg *config.Config, catalog *i18n.Catalog) (*maintenanceWindow, error) {
+	if !cfg.GetMaintenanceEnabled() {
+		return nil, nil
+	}
+
+	var hour, minute int
+	if _, err := fmt.Sscanf(cfg.GetMaintenanceStart(), "%d:%d", &hour, &minute); err != nil ||
+		hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return nil, fmt.Errorf("invalid maintenance Start %q, want HH:MM", cfg.GetMaintenanceStart())
+	}
+	if cfg.GetMaintenanceDuration() == 0 || cfg.GetMaintenanceDuration() >= 24*60 {
+		return nil, fmt.Errorf("invalid maintenance Duration %d, want 1-1439 minutes", cfg.GetMaintenanceDuration())
+	}
+
+	w := &maintenanceWindow{
+		start:    time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute,
+		duration: time.Duration(cfg.GetMaintenanceDuration()) * time.Minute,
+		days:     make(map[time.Weekday]bool),
+		unlink:   cfg.GetMaintenanceUnlink(),
+	}
+	for _, name := range strings.Split(cfg.GetMaintenanceDays(), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		day, ok := weekdays[name[:min(3, len(name))]]
+		if !ok {
+			return nil, fmt.Errorf("invalid maintenance day %q", name)
+		}
+		w.days[day] = true
+	}
+
+	if cfg.GetMaintenanceText() != "" || cfg.GetMaintenanceVoice() != "" {
+		announcement, err := newBeacon(cfg.GetMaintenanceText(), catalog.Prompt(cfg.GetMaintenanceVoice()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the maintenance announcement: %v", err)
+		}
+		w.announcement = announcement
+	}
+
+	log.Printf("Maintenance window: %s for %v daily%s", cfg.GetMaintenanceStart(), w.duration, w.describeDays())
+	return w, nil
+}
+
+// describeDays lists the days the window starts on for the startup log
+func (w *maintenanceWindow) describeDays() string {
+	if len(w.days) == 0 {
+		return ""
+	}
+	var names []string
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if w.days[day] {
+			names = append(names, day.String()[:3])
+		}
+	}
+	return " on " + strings.Join(names, ",")
+}
+
+// contains returns true if now falls in the window, including a window that
+// started the day before and runs past midnight
+func (w *maintenanceWindow) contains(now time.Time) bool {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, dayStart := range []time.Time{midnight, midnight.AddDate(0, 0, -1)} {
+		if len(w.days) > 0 && !w.days[dayStart.Weekday()] {
+			continue
+		}
+		start := dayStart.Add(w.start)
+		if !now.Before(start) && now.Before(start.Add(w.duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMaintenance enters or leaves the maintenance window at now
+// Entering announces the window toward YSF, pauses bridging and, with
+// Unlink, unlinks from the DMR talk group; leaving undoes what entering did,
+// unless the operator has changed it in the meantime.
+func (g *Gateway) checkMaintenance(now time.Time) {
+	w := g.maintenance
+	if w == nil || w.contains(now) == w.active {
+		return
+	}
+	w.active = !w.active
+
+	if w.active {
+		log.Printf("Maintenance window started, bridging paused for %v", w.duration)
+		if w.announcement != nil {
+			w.announcement.pending = true
+		}
+		w.paused = !g.state.Paused()
+		g.setPaused(true, "maintenance")
+
+		w.savedDst, w.savedPriv = 0, false
+		if dstID, private := g.state.Destination(); w.unlink && dstID != 0 {
+			w.savedDst, w.savedPriv = dstID, private
+			g.setDestination(0, false, "Maintenance")
+		}
+		return
+	}
+
+	log.Printf("Maintenance window ended")
+	if w.savedDst != 0 {
+		if dstID, _ := g.state.Destination(); dstID == 0 {
+			if g.wiresX != nil {
+				g.wiresX.RestoreLink(w.savedDst, nil)
+			}
+			g.setDestination(w.savedDst, w.savedPriv, "Maintenance")
+		}
+		w.savedDst = 0
+	}
+	if w.paused {
+		g.setPaused(false, "maintenance")
+		w.paused = false
+	}
+}
@@ -0,0 +1,96 @@
+package gateway
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/i18n"
+)
+
+// english is the catalog of the default language
+var english, _ = i18n.New(i18n.DefaultLanguage)
+
+func TestMaintenanceWindowContains(t *testing.T) {
+	cfg := config.NewConfig("")
+	if err := cfg.LoadFromString("[Maintenance]\nEnable=1\nStart=23:30\nDuration=60\nDays=Sat,sunday"); err != nil {
+		t.Fatal(err)
+	}
+	w, err := initializeMaintenance(cfg, english)
+	if err != nil {
+		t.Fatalf("initializeMaintenance() error = %v", err)
+	}
+
+	// 2026-10-17 is a Saturday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.Local)
+	}
+	tests := []struct {
+		when time.Time
+		want bool
+	}{
+		{at(17, 23, 29), false},
+		{at(17, 23, 30), true},
+		{at(18, 0, 29), true}, // Saturday's window runs past midnight
+		{at(18, 0, 30), false},
+		{at(18, 23, 45), true}, // Sunday
+		{at(19, 0, 15), true},
+		{at(19, 23, 45), false}, // Monday
+		{at(20, 0, 15), false},
+	}
+	for _, tt := range tests {
+		if got := w.contains(tt.when); got != tt.want {
+			t.Errorf("contains(%s) = %v, want %v", tt.when.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestMaintenanceWindowInvalid(t *testing.T) {
+	for _, settings := range []string{"Start=25:00", "Start=noon", "Duration=0", "Days=Funday"} {
+		cfg := config.NewConfig("")
+		if err := cfg.LoadFromString("[Maintenance]\nEnable=1\n" + settings); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := initializeMaintenance(cfg, english); err == nil {
+			t.Errorf("initializeMaintenance() accepted %s", settings)
+		}
+	}
+}
+
+func TestMaintenancePausesAndUnlinks(t *testing.T) {
+	// The window is half a day away, so the check at startup is outside it
+	start := time.Now().Add(12 * time.Hour)
+	g, ysfNet, _ := startGateway(t, func(b *ConfigBuilder) {
+		b.SetBool("Maintenance", "Enable", true).
+			Set("Maintenance", "Start", start.Format("15:04")).
+			Set("Maintenance", "Text", "BACK SOON")
+	})
+
+	check := func(now time.Time) {
+		if err := g.runOnMainLoop(func() { g.checkMaintenance(now) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check(start.Add(time.Minute))
+	if dstID, _ := g.state.Destination(); !g.state.Paused() || dstID != 0 {
+		t.Fatalf("in the window: paused %v, destination %d, want paused and unlinked", g.state.Paused(), dstID)
+	}
+	announced := false
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline) && !announced; time.Sleep(10 * time.Millisecond) {
+		for _, packet := range ysfNet.Sent() {
+			if strings.Contains(string(packet), "BACK") {
+				announced = true
+			}
+		}
+	}
+	if !announced {
+		t.Error("maintenance announcement not sent toward YSF")
+	}
+
+	check(start.Add(40 * time.Minute))
+	if dstID, _ := g.state.Destination(); g.state.Paused() || dstID != 91 {
+		t.Errorf("after the window: paused %v, destination %d, want resumed on TG 91", g.state.Paused(), dstID)
+	}
+}
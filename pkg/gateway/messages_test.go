@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/dmr"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+	"github.com/dbehnke/ysf2dmr/internal/testutil"
+)
+
+// injectDMRBurst injects data burst seqNo of a data call from 3109999 to TG 91
+func injectDMRBurst(t *testing.T, dmrNet *testutil.DMRNetwork, seqNo uint8, dataType uint8, payload []byte) {
+	t.Helper()
+	burst, err := dmr.BuildDataBurst(payload, dataType, 1)
+	if err != nil {
+		t.Fatalf("BuildDataBurst() error = %v", err)
+	}
+	data := protocol.NewDMRData()
+	data.SetSlotNo(2)
+	data.SetSrcId(3109999)
+	data.SetDstId(91)
+	data.SetFLCO(protocol.FLCO_GROUP)
+	data.SetStreamId(0x9abc)
+	data.SetSeqNo(seqNo)
+	data.SetDataType(dataType)
+	data.SetData(burst)
+	dmrNet.Inject(data)
+}
+
+func TestDMRMessageWaitsForHangAndIsPaced(t *testing.T) {
+	const hang = 400 * time.Millisecond
+	_, ysfNet, dmrNet := startGateway(t, func(b *ConfigBuilder) {
+		b.SetInt("YSF Network", "NetHangTime", int64(hang/time.Millisecond))
+	})
+
+	// A DMR call ends, starting the net hang
+	injectDMRHeader(dmrNet)
+	terminator := protocol.NewDMRData()
+	terminator.SetSlotNo(2)
+	terminator.SetSrcId(3109999)
+	terminator.SetDstId(91)
+	terminator.SetFLCO(protocol.FLCO_GROUP)
+	terminator.SetStreamId(0x5678)
+	terminator.SetSeqNo(1)
+	terminator.SetDataType(protocol.DT_TERMINATOR_WITH_LC)
+	dmrNet.Inject(terminator)
+
+	sms, err := dmr.BuildSMS(3109999, 91, true, "HELLO YSF")
+	if err != nil {
+		t.Fatalf("BuildSMS() error = %v", err)
+	}
+	ended := time.Now()
+	injectDMRBurst(t, dmrNet, 0, protocol.DT_DATA_HEADER, dmr.BuildDataHeader(sms.Header))
+	for i, block := range sms.Blocks {
+		injectDMRBurst(t, dmrNet, uint8(i+1), protocol.DT_RATE_12_DATA, block)
+	}
+
+	// Record when each text message frame goes out
+	want := len(ysf.BuildTextMessageFrames("3109999", "ALL", 0, "HELLO YSF"))
+	var sentAt []time.Time
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline) && len(sentAt) < want; time.Sleep(5 * time.Millisecond) {
+		frames := 0
+		for _, packet := range ysfNet.Sent() {
+			var frame ysf.Frame
+			if frame.Parse(packet) == nil && frame.FICH.DT == ysf.DT_DATA_FR {
+				frames++
+			}
+		}
+		for len(sentAt) < frames {
+			sentAt = append(sentAt, time.Now())
+		}
+	}
+	if len(sentAt) < want {
+		t.Fatalf("%d of %d text message frames sent to YSF", len(sentAt), want)
+	}
+
+	if wait := sentAt[0].Sub(ended); wait < hang-50*time.Millisecond {
+		t.Errorf("text message sent %v after the call, want it to wait for the %v hang", wait, hang)
+	}
+	// One frame per 100 ms YSF frame period, with slack for the scheduler
+	if spread := sentAt[want-1].Sub(sentAt[0]); spread < time.Duration(want-1)*50*time.Millisecond {
+		t.Errorf("%d text message frames sent over %v, want one per frame period", want, spread)
+	}
+}
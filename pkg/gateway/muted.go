@@ -0,0 +1,35 @@
+package gateway
+
+import (
+	"log"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+// newMutedTGs returns the talk groups whose DMR traffic is never converted
+// to YSF
+func newMutedTGs(cfg *config.Config) map[uint32]bool {
+	muted := make(map[uint32]bool)
+	for _, tg := range cfg.GetDMRMutedTGs() {
+		muted[tg] = true
+	}
+	return muted
+}
+
+// muteDMRCall counts a frame of a group call to a muted talk group, returning
+// true if it must be dropped
+// Muted calls never start a DMR call, so they neither hold back YSF calls nor
+// run the hang timers.
+func (g *Gateway) muteDMRCall(data *protocol.DMRData, srcStr, dstStr string) bool {
+	if !data.IsGroupCall() || !g.mutedTGs[data.GetDstId()] {
+		return false
+	}
+	if data.GetStreamId() != g.mutedStream {
+		log.Printf("DMR: call from %s to muted %s not bridged", srcStr, dstStr)
+		g.mutedStream = data.GetStreamId()
+		g.dmrMutedCalls++
+	}
+	g.dmrMutedFrames++
+	return true
+}
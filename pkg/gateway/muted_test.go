@@ -0,0 +1,37 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+func TestMutedTGNotBridged(t *testing.T) {
+	_, ysfNet, dmrNet := startGateway(t, func(b *ConfigBuilder) {
+		b.Set("DMR Network", "MutedTGs", "9,91")
+	})
+
+	injectDMRHeader(dmrNet)
+
+	// A call to a talk group that is not muted is bridged; frames are
+	// handled in order, so by then the muted call has been dropped
+	data := protocol.NewDMRData()
+	data.SetSlotNo(2)
+	data.SetSrcId(3109999)
+	data.SetDstId(3100)
+	data.SetFLCO(protocol.FLCO_GROUP)
+	data.SetStreamId(0x9abc)
+	data.SetDataType(protocol.DT_VOICE_LC_HEADER)
+	dmrNet.Inject(data)
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if sent := ysfNet.Sent(); len(sent) > 0 {
+			if len(sent) != 1 {
+				t.Fatalf("%d YSF packets sent, want only the header of the call to TG 3100", len(sent))
+			}
+			return
+		}
+	}
+	t.Fatal("call to TG 3100 not bridged")
+}
@@ -0,0 +1,67 @@
+package gateway
+
+import (
+	"fmt"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/network"
+)
+
+// Config is the gateway configuration, as read from a YSF2DMR.ini file
+type Config = config.Config
+
+// ConfigBuilder builds a Config in code, by INI section and key
+type ConfigBuilder = config.Builder
+
+// NewConfig starts a Config from the defaults; see ysf2dmr.ini for the
+// sections and keys
+func NewConfig() *ConfigBuilder {
+	return config.NewBuilder()
+}
+
+// YSFNetwork is the YSF side of the gateway
+type YSFNetwork = network.YSFNetworkInterface
+
+// DMRNetwork is the DMR side of the gateway
+type DMRNetwork = network.DMRNetworkInterface
+
+// LoadConfig reads the configuration file at path
+func LoadConfig(path string) (*Config, error) {
+	cfg := config.NewConfig(path)
+	if err := cfg.Load(); err != nil {
+		return nil, fmt.Errorf("failed to load config: %v", err)
+	}
+	return cfg, nil
+}
+
+// Option changes how New builds a gateway
+type Option func(*options)
+
+type options struct {
+	version    string
+	ysfNetwork YSFNetwork
+	dmrNetwork DMRNetwork
+}
+
+// WithVersion sets the software version sent to the DMR master and written
+// to logs, traces and diagnostic bundles
+func WithVersion(version string) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// WithYSFNetwork bridges n instead of a YSF socket on [YSF Network]
+// LocalAddress and LocalPort; the [YSF Network] peer filter keys do not apply
+func WithYSFNetwork(n YSFNetwork) Option {
+	return func(o *options) {
+		o.ysfNetwork = n
+	}
+}
+
+// WithDMRNetwork bridges n instead of connecting to the [DMR Network] master
+func WithDMRNetwork(n DMRNetwork) Option {
+	return func(o *options) {
+		o.dmrNetwork = n
+	}
+}
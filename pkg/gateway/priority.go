@@ -0,0 +1,160 @@
+package gateway
+
+import (
+	"log"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+	"github.com/dbehnke/ysf2dmr/internal/state"
+)
+
+// Interrupt policies for a call from one network during a call from the other
+const (
+	InterruptImmediate = "immediate" // the new call takes over
+	InterruptBlock     = "block"     // the new call waits for the current one to end
+	InterruptAfter     = "after"     // the new call takes over once the current one has run InterruptAfter
+)
+
+// callArbiter decides whether a call from the other network may cut off the
+// call in progress
+type callArbiter struct {
+	policy string
+	after  time.Duration
+	tgs    map[uint32]bool // calls to these talk groups always interrupt
+	ids    map[uint32]bool // calls from these DMR IDs always interrupt
+}
+
+// newCallArbiter creates the arbiter for the configured interrupt policy
+func newCallArbiter(cfg *config.Config) *callArbiter {
+	a := &callArbiter{
+		policy: cfg.GetInterruptPolicy(),
+		after:  time.Duration(cfg.GetInterruptAfter()) * time.Second,
+		tgs:    make(map[uint32]bool),
+		ids:    make(map[uint32]bool),
+	}
+	for _, tg := range cfg.GetPriorityTGs() {
+		a.tgs[tg] = true
+	}
+	for _, id := range cfg.GetPriorityIDs() {
+		a.ids[id] = true
+	}
+	return a
+}
+
+// mayInterrupt reports whether a call from srcID to dstID may cut off a call
+// that has run for running; srcID is 0 when the caller's DMR ID is unknown
+func (a *callArbiter) mayInterrupt(running time.Duration, srcID, dstID uint32) bool {
+	if a.tgs[dstID] || (srcID != 0 && a.ids[srcID]) {
+		return true
+	}
+	switch a.policy {
+	case InterruptBlock:
+		return false
+	case InterruptAfter:
+		return running >= a.after
+	default:
+		return true
+	}
+}
+
+// arbitrateYSFCall handles a YSF frame arriving during a DMR call, returning
+// true if it must be dropped
+// The YSF call takes over when the policy allows it, part way through if
+// need be; a call still waiting when the DMR call ends is bridged from its
+// next frame.
+func (g *Gateway) arbitrateYSFCall(frame *ysf.Frame, source string) bool {
+	switch g.state.CallState() {
+	case state.CallDMR:
+		dstID, _ := g.state.Destination()
+		if frame.IsTerminator() || !g.arbiter.mayInterrupt(time.Since(g.callStart), g.ysfCallerID(source), dstID) {
+			if !frame.IsTerminator() && !g.ysfCallWaiting {
+				log.Printf("YSF: call from %s waits for the DMR call in progress", source)
+			}
+			g.ysfCallWaiting = !frame.IsTerminator()
+			return true
+		}
+		g.interruptCall("YSF", source)
+	case state.CallIdle:
+		if !g.ysfCallWaiting {
+			return false
+		}
+		if frame.IsTerminator() {
+			g.ysfCallWaiting = false
+			return true
+		}
+		if g.txInhibited(&g.netInhibit) {
+			return true
+		}
+	default:
+		g.ysfCallWaiting = false
+		return false
+	}
+
+	// A call taking over part way through, or bridged once the DMR call it
+	// waited for has ended, starts here without its header
+	if !frame.IsHeader() {
+		g.startYSFCall(source)
+		if !frame.IsData() {
+			g.sendDMRFullLC(protocol.DT_VOICE_LC_HEADER)
+		}
+	}
+	g.ysfCallWaiting = false
+	return false
+}
+
+// arbitrateDMRCall handles a DMR frame arriving during a YSF call, returning
+// true if it must be dropped
+func (g *Gateway) arbitrateDMRCall(data *protocol.DMRData, srcStr, dstStr string) bool {
+	if g.state.CallState() != state.CallYSF {
+		return false
+	}
+	if data.IsTerminator() || data.GetStreamId() == g.heldStream ||
+		!g.arbiter.mayInterrupt(time.Since(g.callStart), data.GetSrcId(), data.GetDstId()) {
+		if !data.IsTerminator() && data.GetStreamId() != g.dmrWaitingStream && data.GetStreamId() != g.heldStream {
+			log.Printf("DMR: call from %s to %s waits for the YSF call in progress", srcStr, dstStr)
+			g.dmrWaitingStream = data.GetStreamId()
+		}
+		return true
+	}
+	g.interruptCall("DMR", srcStr)
+	return false
+}
+
+// ysfCallerID returns the DMR ID of a YSF caller for PriorityIDs, 0 if none
+// are configured or the lookup does not know the callsign
+func (g *Gateway) ysfCallerID(source string) uint32 {
+	if g.dmrLookup == nil || len(g.arbiter.ids) == 0 {
+		return 0
+	}
+	return g.dmrLookup.FindID(source)
+}
+
+// interruptCall ends the call in progress for a call from the other network
+// The other side gets a terminator and the rest of the interrupted
+// transmission is dropped, as for MaxCallDuration; the hang and inhibit
+// timers are cancelled so they do not hold back the call taking over.
+func (g *Gateway) interruptCall(by, caller string) {
+	call := g.state.Call()
+	log.Printf("%s call from %s interrupts the %s call from %s", by, caller, g.callDirection, g.callCallsign)
+
+	switch call.State {
+	case state.CallYSF:
+		g.sendDMRFullLC(protocol.DT_TERMINATOR_WITH_LC)
+		g.ysfCallAborted = true
+	case state.CallDMR:
+		if err := g.sendYSFHeader(2); err != nil {
+			log.Printf("YSF terminator send error: %v", err)
+		}
+		g.heldStream = call.Stream
+	}
+	g.callEndReason = "interrupted"
+	g.endCall()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, hang := range []*callHang{&g.rfHang, &g.netHang, &g.rfInhibit, &g.netInhibit} {
+		hang.stop()
+	}
+}
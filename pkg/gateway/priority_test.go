@@ -0,0 +1,102 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+	"github.com/dbehnke/ysf2dmr/internal/testutil"
+)
+
+func TestCallArbiter(t *testing.T) {
+	arbiter := &callArbiter{
+		after: 10 * time.Second,
+		tgs:   map[uint32]bool{9112: true},
+		ids:   map[uint32]bool{3100001: true},
+	}
+
+	tests := []struct {
+		policy  string
+		running time.Duration
+		srcID   uint32
+		dstID   uint32
+		want    bool
+	}{
+		{InterruptImmediate, 0, 3109999, 91, true},
+		{InterruptBlock, time.Minute, 3109999, 91, false},
+		{InterruptBlock, 0, 3109999, 9112, true},
+		{InterruptBlock, 0, 3100001, 91, true},
+		{InterruptBlock, 0, 0, 91, false},
+		{InterruptAfter, 5 * time.Second, 3109999, 91, false},
+		{InterruptAfter, 10 * time.Second, 3109999, 91, true},
+	}
+	for _, tt := range tests {
+		arbiter.policy = tt.policy
+		if got := arbiter.mayInterrupt(tt.running, tt.srcID, tt.dstID); got != tt.want {
+			t.Errorf("%s after %v: mayInterrupt(%d, %d) = %v, want %v",
+				tt.policy, tt.running, tt.srcID, tt.dstID, got, tt.want)
+		}
+	}
+}
+
+// startYSFCall starts a YSF call and waits for its DMR voice header
+func startYSFCall(t *testing.T, ysfNet *testutil.YSFNetwork, dmrNet *testutil.DMRNetwork) {
+	t.Helper()
+	header := &ysf.Frame{
+		SourceCallsign: "N0CALL",
+		DestCallsign:   "ALL",
+		FICH:           ysf.FICH{FI: 0, DT: 2},
+		Payload:        ysf.BuildHeaderPayload("ALL", "N0CALL", "", ""),
+	}
+	ysfNet.Inject(header.Build())
+	if !waitForDMR(dmrNet, protocol.DT_VOICE_LC_HEADER) {
+		t.Fatal("YSF header did not start a DMR call")
+	}
+}
+
+// injectDMRHeader sends the voice LC header of a DMR call to TG 91
+func injectDMRHeader(dmrNet *testutil.DMRNetwork) {
+	data := protocol.NewDMRData()
+	data.SetSlotNo(2)
+	data.SetSrcId(3109999)
+	data.SetDstId(91)
+	data.SetFLCO(protocol.FLCO_GROUP)
+	data.SetStreamId(0x5678)
+	data.SetDataType(protocol.DT_VOICE_LC_HEADER)
+	dmrNet.Inject(data)
+}
+
+// waitForDMR reports whether the gateway writes a DMR frame of dataType
+func waitForDMR(dmrNet *testutil.DMRNetwork, dataType uint8) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		for _, frame := range dmrNet.Written() {
+			if frame.GetDataType() == dataType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestDMRCallInterruptsYSFCall(t *testing.T) {
+	ysfNet, dmrNet := runGateway(t)
+	startYSFCall(t, ysfNet, dmrNet)
+
+	injectDMRHeader(dmrNet)
+	if !waitForDMR(dmrNet, protocol.DT_TERMINATOR_WITH_LC) {
+		t.Fatal("YSF call not ended for the DMR call")
+	}
+}
+
+func TestDMRCallWaitsForYSFCall(t *testing.T) {
+	_, ysfNet, dmrNet := startGateway(t, func(b *ConfigBuilder) {
+		b.Set("YSF Network", "InterruptPolicy", "block")
+	})
+	startYSFCall(t, ysfNet, dmrNet)
+
+	injectDMRHeader(dmrNet)
+	if waitForDMR(dmrNet, protocol.DT_TERMINATOR_WITH_LC) {
+		t.Fatal("YSF call ended for a DMR call with InterruptPolicy=block")
+	}
+}
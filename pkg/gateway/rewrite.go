@@ -0,0 +1,50 @@
+package gateway
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/rewrite"
+)
+
+// ysfSlot is the timeslot of calls from YSF before rewriting
+const ysfSlot = 2
+
+// initializeRewriter creates the TG and private call rewriter from the
+// TGRewrite and PCRewrite rules; with none, IDs are left as they are
+func initializeRewriter(cfg *config.Config) (*rewrite.Rewriter, error) {
+	var rules []rewrite.Rule
+	for _, kind := range []struct {
+		private bool
+		values  []string
+	}{
+		{false, cfg.GetDMRTGRewrites()},
+		{true, cfg.GetDMRPCRewrites()},
+	} {
+		for _, value := range kind.values {
+			rule, err := rewrite.ParseRule(kind.private, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid [DMR Network] rewrite: %v", err)
+			}
+			log.Printf("DMR rewrite: %s", rule)
+			rules = append(rules, rule)
+		}
+	}
+	return rewrite.New(rules), nil
+}
+
+// toNetwork returns the slot and ID a call from YSF to dstID uses on the
+// master
+func (g *Gateway) toNetwork(dstID uint32, private bool) (uint8, uint32) {
+	return g.rewriter.ToNetwork(ysfSlot, dstID, private)
+}
+
+// rewriteFromNetwork changes the slot and destination of a frame from the
+// master to the ones YSF users know, before anything else sees it
+func (g *Gateway) rewriteFromNetwork(data *protocol.DMRData) {
+	slot, dstID := g.rewriter.FromNetwork(data.GetSlotNo(), data.GetDstId(), data.GetFLCO() == protocol.FLCO_USER_USER)
+	data.SetSlotNo(slot)
+	data.SetDstId(dstID)
+}
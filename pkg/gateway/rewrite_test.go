@@ -0,0 +1,41 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+)
+
+func TestRewriteToAndFromNetwork(t *testing.T) {
+	g, ysfNet, dmrNet := startGateway(t, func(b *ConfigBuilder) {
+		b.Set("DMR Network", "TGRewrite0", "2,1,1,2000001,999999")
+	})
+
+	startYSFCall(t, ysfNet, dmrNet)
+	for _, frame := range dmrNet.Written() {
+		if frame.GetSlotNo() != 1 || frame.GetDstId() != 2000091 {
+			t.Fatalf("YSF call to TG 91 sent on slot %d to %d, want slot 1 to 2000091", frame.GetSlotNo(), frame.GetDstId())
+		}
+	}
+
+	// The master's TG 2000091 on slot 1 is TG 91 on YSF
+	data := protocol.NewDMRData()
+	data.SetSlotNo(1)
+	data.SetSrcId(3109999)
+	data.SetDstId(2000091)
+	data.SetFLCO(protocol.FLCO_GROUP)
+	data.SetStreamId(0x5678)
+	data.SetDataType(protocol.DT_VOICE_LC_HEADER)
+	dmrNet.Inject(data)
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if call := g.state.Call(); call.SrcID == 3109999 {
+			if call.DstID != 91 {
+				t.Fatalf("DMR call to %d, want TG 91", call.DstID)
+			}
+			return
+		}
+	}
+	t.Fatal("DMR call not started")
+}
@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/state"
+	"github.com/dbehnke/ysf2dmr/internal/wiresx"
+)
+
+// restoreSession restores the destination selected before the last restart
+// from the state file, if there is one
+func (g *Gateway) restoreSession() {
+	path := g.config.GetDMRStateFile()
+	session, err := state.LoadSession(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Starting with StartupDstId: %v", err)
+		}
+		return
+	}
+
+	g.state.SetDestination(session.DstID, session.Private)
+	if g.wiresX != nil {
+		var user *wiresx.TalkGroup
+		if session.Private && session.WiresXUser != nil {
+			user = &wiresx.TalkGroup{
+				ID:   session.WiresXUser.SelectID,
+				Opt:  "0",
+				Name: session.WiresXUser.Name,
+				Desc: session.WiresXUser.Desc,
+			}
+		}
+		g.wiresX.RestoreLink(session.DstID, user)
+	}
+	log.Printf("Restored destination %s from %s (selected %s)",
+		g.formatDMRAddress(session.DstID, !session.Private), path, session.Saved.Format(time.RFC3339))
+}
+
+// saveSession keeps the current destination in the state file
+func (g *Gateway) saveSession() {
+	if !g.config.GetDMRRestoreDestination() {
+		return
+	}
+
+	dstID, private := g.state.Destination()
+	session := state.Session{DstID: dstID, Private: private, Saved: time.Now()}
+	if g.wiresX != nil && private {
+		if _, user := g.wiresX.Link(); user != nil {
+			session.WiresXUser = &state.SessionUser{SelectID: user.ID, Name: user.Name, Desc: user.Desc}
+		}
+	}
+	if err := state.SaveSession(g.config.GetDMRStateFile(), session); err != nil {
+		log.Printf("Failed to save the destination: %v", err)
+	}
+}
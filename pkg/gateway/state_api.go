@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/state"
+)
+
+// stateReport is the /api/state response
+type stateReport struct {
+	Call          string    `json:"call"`
+	SrcID         uint32    `json:"src_id,omitempty"`
+	DstID         uint32    `json:"dst_id"`
+	Private       bool      `json:"private"`
+	Paused        bool      `json:"paused"`
+	DMRLinkUp     bool      `json:"dmr_link_up"`
+	LastConnected time.Time `json:"dmr_last_connected"`
+	DMRHealth     string    `json:"dmr_health"`
+	YSFHealth     string    `json:"ysf_health"`
+	YSFErrors     int64     `json:"ysf_errors"`
+	DMRErrors     int64     `json:"dmr_errors"`
+}
+
+// stateHandler serves GET /api/state with the current call, the DMR link
+// status, the health of both links and the network error counts
+func stateHandler(s *state.State) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		call := s.Call()
+		report := stateReport{
+			Call:    call.State.String(),
+			DstID:   call.DstID,
+			Private: call.Private,
+			Paused:  s.Paused(),
+		}
+		if call.State == state.CallDMR {
+			report.SrcID = call.SrcID
+		}
+		report.DMRLinkUp, report.LastConnected = s.DMRLink()
+		dmrHealth, ysfHealth := s.Health()
+		report.DMRHealth, report.YSFHealth = dmrHealth.String(), ysfHealth.String()
+		report.YSFErrors, report.DMRErrors = s.NetworkErrors()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// addReadinessChecks registers the /readyz checks: logged in to the DMR
+// master, YSF socket bound and, when configured, the DMR ID lookup loaded
+func (g *Gateway) addReadinessChecks() {
+	g.web.AddReadinessCheck("dmr", func() (interface{}, bool) {
+		up, last := g.state.DMRLink()
+		return map[string]interface{}{"authenticated": up, "last_connected": last}, up
+	})
+	g.web.AddReadinessCheck("ysf", func() (interface{}, bool) {
+		bound := g.ysfNetwork.IsOpen()
+		return map[string]bool{"bound": bound}, bound
+	})
+	if g.dmrLookup != nil {
+		g.web.AddReadinessCheck("lookup", func() (interface{}, bool) {
+			entries := g.dmrLookup.GetEntryCount()
+			return map[string]uint32{"entries": entries}, entries > 0
+		})
+	}
+}
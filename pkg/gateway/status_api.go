@@ -0,0 +1,37 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/version"
+)
+
+// statusReport is the /status response
+type statusReport struct {
+	version.Info
+	Reported string    `json:"reported_version"` // as sent to the DMR master
+	Started  time.Time `json:"started"`
+	Uptime   string    `json:"uptime"`
+}
+
+// statusHandler serves GET /status with the build details of the running
+// gateway, for support requests
+func statusHandler(reported string, started time.Time) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(statusReport{
+			Info:     version.Get(),
+			Reported: reported,
+			Started:  started,
+			Uptime:   time.Since(started).Round(time.Second).String(),
+		})
+	})
+}
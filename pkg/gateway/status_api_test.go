@@ -0,0 +1,33 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/version"
+)
+
+func TestStatusHandler(t *testing.T) {
+	started := time.Now().Add(-90 * time.Second)
+	rec := httptest.NewRecorder()
+	statusHandler("1.0.0-go (3f2c1ab)", started).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	var status struct {
+		Version   string `json:"version"`
+		GoVersion string `json:"go_version"`
+		Reported  string `json:"reported_version"`
+		Uptime    string `json:"uptime"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("GET /status: %v: %s", err, rec.Body)
+	}
+	if status.Version != version.Version || status.GoVersion == "" || status.Reported != "1.0.0-go (3f2c1ab)" {
+		t.Errorf("GET /status = %+v", status)
+	}
+	if status.Uptime != "1m30s" {
+		t.Errorf("uptime = %q, want 1m30s", status.Uptime)
+	}
+}
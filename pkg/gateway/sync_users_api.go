@@ -0,0 +1,29 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/radioid"
+)
+
+// syncUsersHandler triggers an immediate RadioID download on POST /api/sync-users
+// The sync runs in the background; its progress is reported by /health.
+func syncUsersHandler(syncer *radioid.Syncer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := syncer.SyncInBackground(10 * time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"status": "sync started"})
+	})
+}
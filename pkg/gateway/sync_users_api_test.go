@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/database"
+	"github.com/dbehnke/ysf2dmr/internal/radioid"
+)
+
+func TestSyncUsersHandler(t *testing.T) {
+	db, err := database.NewDB(database.Config{Path: filepath.Join(t.TempDir(), "users.db")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// The download blocks until released, so the first sync is still
+	// running when the second request arrives
+	release := make(chan struct{})
+	radioID := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		fmt.Fprint(w, "RADIO_ID,CALLSIGN,FIRST_NAME,LAST_NAME,CITY,STATE,COUNTRY\n")
+		fmt.Fprint(w, "3120001,W1AW,Hiram,Maxim,Newington,Connecticut,United States\n")
+	}))
+	t.Cleanup(radioID.Close)
+	syncer := radioid.NewSyncerWithConfig(database.NewDMRUserRepository(db.GetDB()), nil,
+		radioid.SyncerConfig{URL: radioID.URL})
+
+	server := httptest.NewServer(syncUsersHandler(syncer))
+	t.Cleanup(server.Close)
+	post := func() int {
+		resp, err := http.Post(server.URL, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+
+	if code := post(); code != http.StatusAccepted {
+		t.Fatalf("first POST status = %d, want %d", code, http.StatusAccepted)
+	}
+	if code := post(); code != http.StatusConflict {
+		t.Errorf("second POST status = %d, want %d", code, http.StatusConflict)
+	}
+
+	close(release)
+	for deadline := time.Now().Add(5 * time.Second); syncer.Status().Running; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("sync did not finish")
+		}
+	}
+	if status := syncer.Status(); status.RowsUpdated != 1 || status.LastError != "" {
+		t.Errorf("status after sync = %+v", status)
+	}
+	if code := post(); code != http.StatusAccepted {
+		t.Errorf("POST after the sync status = %d, want %d", code, http.StatusAccepted)
+	}
+}
@@ -0,0 +1,40 @@
+package gateway
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/network"
+	"github.com/dbehnke/ysf2dmr/internal/trace"
+)
+
+// OpenTrace opens the -trace packet trace next to the log files, as
+// <FileRoot>-trace.log in the [Log] FilePath
+func OpenTrace(cfg *config.Config, version string) (*trace.Tracer, error) {
+	path := filepath.Join(cfg.GetLogFilePath(), cfg.GetLogFileRoot()+"-trace.log")
+	t, err := trace.Open(path, trace.DefaultMaxSize, trace.DefaultKeep, version)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Tracing all packets to %s", path)
+	return t, nil
+}
+
+// EnableTrace records every YSF and DMR packet in the trace file until the
+// gateway stops
+// Must be called before Run.
+func (g *Gateway) EnableTrace() error {
+	t, err := OpenTrace(g.config, g.version)
+	if err != nil {
+		return err
+	}
+	g.tracer = t
+	if tracer, ok := g.ysfNetwork.(network.PacketTracer); ok {
+		tracer.SetTrace(t)
+	}
+	if tracer, ok := g.dmrNetwork.(network.PacketTracer); ok {
+		tracer.SetTrace(t)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dbehnke/ysf2dmr/internal/lookup"
+)
+
+// Limits for GET /api/users
+const (
+	defaultUserSearchLimit = 20
+	maxUserSearchLimit     = 100
+)
+
+// userSearchHandler serves GET /api/users?callsign=<prefix>&limit=<n> for the dashboard
+func userSearchHandler(dmrLookup lookup.DMRLookupInterface) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		prefix := r.URL.Query().Get("callsign")
+		if prefix == "" {
+			http.Error(w, "callsign parameter required", http.StatusBadRequest)
+			return
+		}
+
+		limit := defaultUserSearchLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = min(n, maxUserSearchLimit)
+		}
+
+		users := dmrLookup.SearchCallsign(prefix, limit)
+		if users == nil {
+			users = []lookup.DMRUserInfo{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(users)
+	})
+}
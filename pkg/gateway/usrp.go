@@ -0,0 +1,249 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/network"
+	"github.com/dbehnke/ysf2dmr/internal/network/usrp"
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/dmr"
+	"github.com/dbehnke/ysf2dmr/internal/recorder"
+	"github.com/dbehnke/ysf2dmr/internal/state"
+)
+
+// usrpTimeout ends a call from USRP whose unkey packet was lost
+const usrpTimeout = 500 * time.Millisecond
+
+// usrpBridge carries voice between the gateway and Analog_Bridge or an
+// AllStar node over USRP
+// The voice of YSF and DMR calls is decoded to PCM toward it, and its keyed
+// audio is encoded and sent to DMR as a call from the gateway to the
+// YSF->DMR destination. YSF hears that call through the DMR->YSF path.
+// The voice worker does the transcoding and owns the client while it runs.
+type usrpBridge struct {
+	client     *usrp.Client
+	transcoder codec.Transcoder    // encodes the audio from USRP
+	bursts     *dmr.BurstAssembler // sync, EMB and slot type of bursts built from its audio
+
+	tx bool // audio is being sent to it
+
+	// The call from USRP in progress
+	rx     bool
+	held   bool // keyed audio is dropped until the unkey
+	last   time.Time
+	lc     *dmr.LinkControl // addressed as used on the master
+	slot   uint8
+	dstID  uint32 // as dialled, for the DMR->YSF path
+	stream uint32
+	seqNo  uint8
+	frames [recorder.AMBE_FRAMES_PER_BURST][recorder.AMBE_FRAME_LENGTH]byte
+	nFrame int // frames of the next burst encoded so far
+}
+
+// initializeUSRP creates the USRP bridge, nil when [USRP] is not enabled
+func initializeUSRP(cfg *config.Config) (*usrpBridge, error) {
+	if !cfg.GetUSRPEnabled() {
+		return nil, nil
+	}
+	name := cfg.GetUSRPTranscoder()
+	transcoder, err := codec.NewTranscoder(name, cfg.GetUSRPTranscoderArg())
+	if err != nil {
+		return nil, fmt.Errorf("invalid [USRP] Transcoder: %v", err)
+	}
+	client, err := usrp.NewClient(cfg.GetUSRPAddress(), int(cfg.GetUSRPPort()), int(cfg.GetUSRPLocalPort()), false)
+	if err != nil {
+		transcoder.Close()
+		return nil, err
+	}
+	syncSource, _ := dmr.ParseSyncSource(cfg.GetDMRSyncSource())
+	return &usrpBridge{
+		client:     client,
+		transcoder: newBreakerTranscoder(name, transcoder),
+		bursts:     dmr.NewBurstAssembler(cfg.GetDMRColorCode(), syncSource),
+	}, nil
+}
+
+// close unkeys and closes the connection and the transcoder
+func (u *usrpBridge) close() {
+	u.client.Close()
+	u.transcoder.Close()
+}
+
+// endUSRPAudio unkeys USRP at the end of a call sent to it
+func (g *Gateway) endUSRPAudio() {
+	u := g.usrp
+	if u == nil || !u.tx {
+		return
+	}
+	u.tx = false
+	g.voice.submit(voiceJob{unkey: true})
+}
+
+// processUSRPNetwork bridges the audio received from USRP, as encoded by
+// the voice worker
+func (g *Gateway) processUSRPNetwork() {
+	u := g.usrp
+	if u == nil {
+		return
+	}
+
+received:
+	for {
+		select {
+		case audio := <-g.voice.audio:
+			g.bridgeUSRPAudio(audio)
+		default:
+			break received
+		}
+	}
+
+	if (u.rx || u.held) && time.Since(u.last) > usrpTimeout {
+		log.Printf("USRP audio stopped without an unkey")
+		g.endUSRPCall()
+	}
+}
+
+// bridgeUSRPAudio sends a voice packet from USRP to DMR, starting and
+// ending the call; frames that could not be encoded are left out
+func (g *Gateway) bridgeUSRPAudio(audio usrpAudio) {
+	u := g.usrp
+	if !audio.keyup {
+		g.endUSRPCall()
+		return
+	}
+	u.last = time.Now()
+	if !audio.voice || u.held {
+		return
+	}
+	if !u.rx && !g.startUSRPCall() {
+		return
+	}
+	if audio.ambe == nil {
+		return
+	}
+
+	copy(u.frames[u.nFrame][:], audio.ambe)
+	if u.nFrame++; u.nFrame < len(u.frames) {
+		return
+	}
+	u.nFrame = 0
+
+	// A cut-off ends the call before its unkey
+	if call := g.state.Call(); call.State != state.CallDMR || call.Stream != u.stream {
+		log.Printf("USRP call ended by the gateway")
+		u.rx, u.held = false, true
+		return
+	}
+	burst := recorder.InsertDMRAMBE(u.frames)
+	n := u.bursts.Voice(burst)
+	if n == 0 {
+		g.sendUSRPFrame(protocol.DT_VOICE_SYNC, n, burst)
+	} else {
+		g.sendUSRPFrame(protocol.DT_VOICE, n, burst)
+	}
+}
+
+// startUSRPCall starts a call from USRP to the YSF->DMR destination,
+// reporting whether it started
+// Audio is held until the unkey while another call is in progress or the
+// DMR->YSF path does not take the call, e.g. on a muted talk group.
+func (g *Gateway) startUSRPCall() bool {
+	u := g.usrp
+	u.held = true
+
+	if g.state.CallState() != state.CallIdle {
+		log.Printf("USRP audio dropped: a call is in progress")
+		return false
+	}
+	dstID, private := g.state.Destination()
+	if dstID == 0 {
+		log.Printf("USRP audio dropped: no DMR destination")
+		return false
+	}
+
+	slot, netDstID := g.toNetwork(dstID, private)
+	u.lc = &dmr.LinkControl{
+		FLCO:          destinationFLCO(private),
+		SourceID:      g.config.GetDMRId(),
+		DestinationID: netDstID,
+	}
+	u.slot, u.dstID = slot, dstID
+	u.stream, u.seqNo, u.nFrame = rand.Uint32(), 0, 0
+
+	burst, err := dmr.BuildFullLCBurst(u.lc, protocol.DT_VOICE_LC_HEADER, g.config.GetDMRColorCode())
+	if err != nil {
+		log.Printf("USRP call header build error: %v", err)
+		return false
+	}
+	u.bursts.Data(burst, protocol.DT_VOICE_LC_HEADER)
+	u.bursts.StartVoice(u.lc)
+
+	log.Printf("USRP call to %s", g.formatDestination())
+	if !g.sendUSRPFrame(protocol.DT_VOICE_LC_HEADER, 0, burst) {
+		return false
+	}
+	u.rx, u.held = true, false
+	return true
+}
+
+// endUSRPCall ends the call from USRP in progress, if any
+// Audio left over for a partial burst is dropped.
+func (g *Gateway) endUSRPCall() {
+	u := g.usrp
+	rx := u.rx
+	u.rx, u.held = false, false
+	if !rx {
+		return
+	}
+
+	if call := g.state.Call(); call.State != state.CallDMR || call.Stream != u.stream {
+		return
+	}
+	burst, err := dmr.BuildFullLCBurst(u.lc, protocol.DT_TERMINATOR_WITH_LC, g.config.GetDMRColorCode())
+	if err != nil {
+		log.Printf("USRP call terminator build error: %v", err)
+		return
+	}
+	u.bursts.Data(burst, protocol.DT_TERMINATOR_WITH_LC)
+	g.sendUSRPFrame(protocol.DT_TERMINATOR_WITH_LC, 0, burst)
+}
+
+// sendUSRPFrame sends a frame of the call from USRP toward YSF through the
+// DMR->YSF path and, once that has taken the call, to the DMR network,
+// reporting whether it was sent; n is the voice burst number
+func (g *Gateway) sendUSRPFrame(dataType uint8, n uint8, burst []byte) bool {
+	u := g.usrp
+	data := protocol.GetDMRData()
+	defer protocol.PutDMRData(data)
+	data.SetSlotNo(u.slot)
+	data.SetSrcId(u.lc.SourceID)
+	data.SetDstId(u.dstID)
+	data.SetFLCO(u.lc.FLCO)
+	data.SetStreamId(u.stream)
+	data.SetSeqNo(u.seqNo)
+	data.SetDataType(dataType)
+	data.SetN(n)
+	data.SetData(burst)
+	u.seqNo++
+
+	if err := g.processDMRData(data); err != nil {
+		log.Printf("USRP call processing error: %v", err)
+	}
+	if dataType == protocol.DT_VOICE_LC_HEADER {
+		if call := g.state.Call(); call.State != state.CallDMR || call.Stream != u.stream {
+			return false
+		}
+	}
+
+	data.SetDstId(u.lc.DestinationID)
+	if err := g.dmrOutput.Write(data); err != nil && !errors.Is(err, network.ErrFrameQueued) {
+		log.Printf("USRP call DMR send error: %v", err)
+	}
+	return true
+}
@@ -0,0 +1,332 @@
+package gateway
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+	"github.com/dbehnke/ysf2dmr/internal/events"
+	"github.com/dbehnke/ysf2dmr/internal/network/usrp"
+	"github.com/dbehnke/ysf2dmr/internal/protocol"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/dmr"
+	"github.com/dbehnke/ysf2dmr/internal/protocol/ysf"
+	"github.com/dbehnke/ysf2dmr/internal/testutil"
+)
+
+// fixedTranscoder decodes every frame to samples of 500 and encodes to zeros
+type fixedTranscoder struct{}
+
+func init() {
+	codec.RegisterTranscoder("usrp-test", func(string) (codec.Transcoder, error) {
+		return fixedTranscoder{}, nil
+	})
+}
+
+func (fixedTranscoder) DecodeAMBE(frame []byte) ([]int16, error) {
+	pcm := make([]int16, codec.PCM_SAMPLES_PER_FRAME)
+	for i := range pcm {
+		pcm[i] = 500
+	}
+	return pcm, nil
+}
+
+func (fixedTranscoder) EncodeAMBE(pcm []int16) ([]byte, error) { return make([]byte, 9), nil }
+
+func (fixedTranscoder) Close() error { return nil }
+
+// stalledTranscoder never answers: each frame fails after the md380-emu
+// timeout
+type stalledTranscoder struct{}
+
+// stalledCalls counts the frames given to stalledTranscoder
+var stalledCalls atomic.Int64
+
+func init() {
+	codec.RegisterTranscoder("stalled-test", func(string) (codec.Transcoder, error) {
+		return stalledTranscoder{}, nil
+	})
+}
+
+func (stalledTranscoder) DecodeAMBE(frame []byte) ([]int16, error) {
+	stalledCalls.Add(1)
+	time.Sleep(codec.MD380_EMU_TIMEOUT)
+	return nil, errors.New("no reply")
+}
+
+func (stalledTranscoder) EncodeAMBE(pcm []int16) ([]byte, error) {
+	stalledCalls.Add(1)
+	time.Sleep(codec.MD380_EMU_TIMEOUT)
+	return nil, errors.New("no reply")
+}
+
+func (stalledTranscoder) Close() error { return nil }
+
+// countingTranscoder is fixedTranscoder, counting the frames it is given
+type countingTranscoder struct{ fixedTranscoder }
+
+// countedCalls counts the frames given to countingTranscoder
+var countedCalls atomic.Int64
+
+func init() {
+	codec.RegisterTranscoder("counting-test", func(string) (codec.Transcoder, error) {
+		return countingTranscoder{}, nil
+	})
+}
+
+func (c countingTranscoder) DecodeAMBE(frame []byte) ([]int16, error) {
+	countedCalls.Add(1)
+	return c.fixedTranscoder.DecodeAMBE(frame)
+}
+
+func (c countingTranscoder) EncodeAMBE(pcm []int16) ([]byte, error) {
+	countedCalls.Add(1)
+	return c.fixedTranscoder.EncodeAMBE(pcm)
+}
+
+// startUSRPGateway runs a gateway bridging to a USRP peer socket through
+// transcoder, returning the peer and the address the gateway listens on;
+// settings change the test configuration
+func startUSRPGateway(t *testing.T, transcoder string, settings ...func(*ConfigBuilder)) (*Gateway, *testutil.YSFNetwork, *testutil.DMRNetwork, *net.UDPConn, *net.UDPAddr) {
+	t.Helper()
+	peer, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP() error = %v", err)
+	}
+	t.Cleanup(func() { peer.Close() })
+
+	// A port the OS just handed out is free for the gateway
+	free, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP() error = %v", err)
+	}
+	local := free.LocalAddr().(*net.UDPAddr)
+	free.Close()
+
+	g, ysfNet, dmrNet := startGateway(t, append([]func(*ConfigBuilder){func(b *ConfigBuilder) {
+		b.SetBool("USRP", "Enable", true).
+			Set("USRP", "Address", "127.0.0.1").
+			SetInt("USRP", "Port", int64(peer.LocalAddr().(*net.UDPAddr).Port)).
+			SetInt("USRP", "LocalPort", int64(local.Port)).
+			Set("USRP", "Transcoder", transcoder)
+	}}, settings...)...)
+	// Packets sent before the gateway opens its socket are lost
+	time.Sleep(200 * time.Millisecond)
+	return g, ysfNet, dmrNet, peer, local
+}
+
+// readUSRP reads the packets the gateway sends to peer until none arrive
+// for a while
+func readUSRP(t *testing.T, peer *net.UDPConn, quiet time.Duration) []usrp.Packet {
+	t.Helper()
+	var packets []usrp.Packet
+	buffer := make([]byte, 1024)
+	for {
+		peer.SetReadDeadline(time.Now().Add(quiet))
+		n, _, err := peer.ReadFromUDP(buffer)
+		if err != nil {
+			return packets
+		}
+		var packet usrp.Packet
+		if err := packet.Unmarshal(buffer[:n]); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		packets = append(packets, packet)
+	}
+}
+
+func TestDMRCallReachesUSRP(t *testing.T) {
+	_, _, dmrNet, peer, _ := startUSRPGateway(t, "usrp-test")
+
+	injectDMRCall(dmrNet, 2, 0)
+
+	packets := readUSRP(t, peer, 500*time.Millisecond)
+	if len(packets) != 7 {
+		t.Fatalf("%d USRP packets, want 6 voice frames and the unkey", len(packets))
+	}
+	for i, packet := range packets[:6] {
+		if !packet.Keyup || packet.TalkGroup != 91 || len(packet.Audio) != usrp.SAMPLES_PER_FRAME || packet.Audio[0] != 500 {
+			t.Fatalf("packet %d = keyup %v TG %d, %d samples, want keyed decoded voice to TG 91",
+				i, packet.Keyup, packet.TalkGroup, len(packet.Audio))
+		}
+	}
+	if packets[6].Keyup {
+		t.Error("last packet is keyed, want the unkey")
+	}
+}
+
+func TestUSRPCallReachesDMRAndYSF(t *testing.T) {
+	_, ysfNet, dmrNet, peer, local := startUSRPGateway(t, "usrp-test")
+
+	// Two bursts of audio, then the unkey
+	for seq := 0; seq < 6; seq++ {
+		voice := &usrp.Packet{SeqNo: uint32(seq), Keyup: true, Type: usrp.TYPE_VOICE, Audio: make([]int16, usrp.SAMPLES_PER_FRAME)}
+		peer.WriteToUDP(voice.Marshal(), local)
+		time.Sleep(usrp.FRAME_TIME * time.Millisecond)
+	}
+	unkey := &usrp.Packet{SeqNo: 6, Type: usrp.TYPE_VOICE}
+	peer.WriteToUDP(unkey.Marshal(), local)
+
+	var types []uint8
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		types = types[:0]
+		for _, frame := range dmrNet.Written() {
+			if frame.GetSrcId() != 3100001 || frame.GetDstId() != 91 || frame.GetSlotNo() != 2 {
+				t.Fatalf("DMR frame %d -> %d on slot %d, want 3100001 -> 91 on slot 2",
+					frame.GetSrcId(), frame.GetDstId(), frame.GetSlotNo())
+			}
+			types = append(types, frame.GetDataType())
+		}
+		if len(types) == 4 {
+			break
+		}
+	}
+	want := []uint8{protocol.DT_VOICE_LC_HEADER, protocol.DT_VOICE_SYNC, protocol.DT_VOICE, protocol.DT_TERMINATOR_WITH_LC}
+	if len(types) != len(want) {
+		t.Fatalf("DMR frames = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("DMR frames = %v, want %v", types, want)
+		}
+	}
+
+	// YSF hears the call through the DMR->YSF path
+	var header, terminator bool
+	for _, packet := range ysfNet.Sent() {
+		var frame ysf.Frame
+		if frame.Parse(packet) != nil {
+			continue
+		}
+		header = header || frame.FICH.FI == 0
+		terminator = terminator || frame.FICH.FI == 2
+	}
+	if !header || !terminator {
+		t.Errorf("YSF header sent %v, terminator sent %v, want both", header, terminator)
+	}
+
+	// The call is not sent back to USRP
+	if packets := readUSRP(t, peer, 100*time.Millisecond); len(packets) > 0 {
+		t.Errorf("%d packets echoed to USRP", len(packets))
+	}
+}
+
+// injectDMRCall sends a DMR call to TG 91 with the given number of voice
+// bursts, one every interval as from the master, and its terminator
+func injectDMRCall(dmrNet *testutil.DMRNetwork, voiceBursts int, interval time.Duration) {
+	injectDMRHeader(dmrNet)
+	bursts := dmr.NewBurstAssembler(1, dmr.BS_SOURCED)
+	bursts.StartVoice(&dmr.LinkControl{FLCO: dmr.FLCO_GROUP_CALL, SourceID: 3109999, DestinationID: 91})
+	newFrame := func(seq int) *protocol.DMRData {
+		data := protocol.NewDMRData()
+		data.SetSlotNo(2)
+		data.SetSrcId(3109999)
+		data.SetDstId(91)
+		data.SetFLCO(protocol.FLCO_GROUP)
+		data.SetStreamId(0x5678)
+		data.SetSeqNo(uint8(seq))
+		return data
+	}
+	for seq := 1; seq <= voiceBursts; seq++ {
+		data := newFrame(seq)
+		var burst [33]byte
+		if n := bursts.Voice(burst[:]); n == 0 {
+			data.SetDataType(protocol.DT_VOICE_SYNC)
+		} else {
+			data.SetDataType(protocol.DT_VOICE)
+			data.SetN(n)
+		}
+		data.SetData(burst[:])
+		dmrNet.Inject(data)
+		time.Sleep(interval)
+	}
+	terminator := newFrame(voiceBursts + 1)
+	terminator.SetDataType(protocol.DT_TERMINATOR_WITH_LC)
+	dmrNet.Inject(terminator)
+}
+
+func TestStalledTranscoderKeepsDMRTiming(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings func(*ConfigBuilder)
+	}{
+		{"USRP", func(*ConfigBuilder) {}},
+		{"USRP and gain", func(b *ConfigBuilder) {
+			b.Set("Audio", "Transcoder", "stalled-test").Set("Audio", "DMRGain", "6")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ysfNet, dmrNet, peer, _ := startUSRPGateway(t, "stalled-test", tt.settings)
+			stalledCalls.Store(0)
+			injectDMRCall(dmrNet, 12, 60*time.Millisecond)
+
+			// Had each burst waited for the transcoder, YSF would hear the
+			// end of the call seconds late
+			injected := time.Now()
+			ended := func() bool {
+				for _, packet := range ysfNet.Sent() {
+					var frame ysf.Frame
+					if frame.Parse(packet) == nil && frame.FICH.FI == 2 {
+						return true
+					}
+				}
+				return false
+			}
+			for !ended() {
+				if time.Since(injected) > 200*time.Millisecond {
+					t.Fatal("YSF terminator not sent within 200ms of the DMR terminator")
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			// One timeout opens the breaker; USRP hears silence in time with the call
+			packets := readUSRP(t, peer, 300*time.Millisecond)
+			if len(packets) != 37 {
+				t.Fatalf("%d USRP packets, want 36 frames of silence and the unkey", len(packets))
+			}
+			for i, packet := range packets[:36] {
+				if !packet.Keyup || len(packet.Audio) != usrp.SAMPLES_PER_FRAME || packet.Audio[0] != 0 {
+					t.Fatalf("packet %d = keyup %v, %d samples, want keyed silence", i, packet.Keyup, len(packet.Audio))
+				}
+			}
+			if calls := stalledCalls.Load(); calls > 2 {
+				t.Errorf("transcoder asked for %d frames, want one and the probe", calls)
+			}
+		})
+	}
+}
+
+func TestVoiceDecodedOncePerBurst(t *testing.T) {
+	g, _, dmrNet, peer, _ := startUSRPGateway(t, "counting-test", func(b *ConfigBuilder) {
+		b.Set("Audio", "Transcoder", "counting-test")
+	})
+	callEnd := make(chan events.Event, 1)
+	g.events.Subscribe(func(event events.Event) {
+		if event.Type == events.CallEnd {
+			callEnd <- event
+		}
+	})
+	countedCalls.Store(0)
+	injectDMRCall(dmrNet, 2, 60*time.Millisecond)
+
+	select {
+	case event := <-callEnd:
+		// 500 is -36.3 dBFS
+		if event.Fields["audio_rms"] != "-36.3" {
+			t.Errorf("call_end audio_rms = %q, want -36.3", event.Fields["audio_rms"])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("DMR call did not end")
+	}
+	if packets := readUSRP(t, peer, 300*time.Millisecond); len(packets) != 7 {
+		t.Errorf("%d USRP packets, want 6 voice frames and the unkey", len(packets))
+	}
+
+	// Metering and USRP share the decoded audio
+	if calls := countedCalls.Load(); calls != 6 {
+		t.Errorf("transcoder asked for %d frames, want the 6 of the call", calls)
+	}
+}
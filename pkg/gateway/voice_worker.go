@@ -0,0 +1,308 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dbehnke/ysf2dmr/internal/codec"
+	"github.com/dbehnke/ysf2dmr/internal/config"
+	"github.com/dbehnke/ysf2dmr/internal/network/usrp"
+	"github.com/dbehnke/ysf2dmr/internal/recorder"
+	"github.com/dbehnke/ysf2dmr/internal/supervisor"
+)
+
+// voiceQueueLength bounds the bursts and USRP packets waiting to be
+// transcoded, metered or bridged; the oldest is dropped when the other side
+// falls behind
+const voiceQueueLength = 16
+
+// transcoderProbeInterval is how often a transcoder that stopped answering
+// is tried again
+const transcoderProbeInterval = time.Second
+
+// errTranscoderDown is returned while a transcoder is not answering
+var errTranscoderDown = errors.New("transcoder not answering")
+
+// breakerTranscoder fails fast while its transcoder is not answering
+// The first error opens the breaker, so a stopped md380-emu costs one
+// timeout rather than one per frame. A silent frame is decoded every
+// transcoderProbeInterval until the transcoder answers and the breaker
+// closes again.
+type breakerTranscoder struct {
+	codec.Transcoder
+	name string
+
+	open atomic.Bool
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newBreakerTranscoder wraps the transcoder opened as name
+func newBreakerTranscoder(name string, transcoder codec.Transcoder) *breakerTranscoder {
+	return &breakerTranscoder{Transcoder: transcoder, name: name, done: make(chan struct{})}
+}
+
+// DecodeAMBE decodes an AMBE+2 frame, failing fast while the breaker is open
+func (b *breakerTranscoder) DecodeAMBE(frame []byte) ([]int16, error) {
+	if b.open.Load() {
+		return nil, errTranscoderDown
+	}
+	pcm, err := b.Transcoder.DecodeAMBE(frame)
+	if err != nil {
+		b.trip(err)
+	}
+	return pcm, err
+}
+
+// EncodeAMBE encodes PCM to an AMBE+2 frame, failing fast while the breaker
+// is open
+func (b *breakerTranscoder) EncodeAMBE(pcm []int16) ([]byte, error) {
+	if b.open.Load() {
+		return nil, errTranscoderDown
+	}
+	ambe, err := b.Transcoder.EncodeAMBE(pcm)
+	if err != nil {
+		b.trip(err)
+	}
+	return ambe, err
+}
+
+// trip opens the breaker and probes the transcoder until it answers
+func (b *breakerTranscoder) trip(err error) {
+	if !b.open.CompareAndSwap(false, true) {
+		return
+	}
+	log.Printf("Transcoder %s not answering, audio is silenced until it does: %v", b.name, err)
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		ticker := time.NewTicker(transcoderProbeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.done:
+				return
+			case <-ticker.C:
+			}
+			if _, err := b.Transcoder.DecodeAMBE(make([]byte, recorder.AMBE_FRAME_LENGTH)); err == nil {
+				log.Printf("Transcoder %s answering again", b.name)
+				b.open.Store(false)
+				return
+			}
+		}
+	}()
+}
+
+// Close stops probing and closes the transcoder
+func (b *breakerTranscoder) Close() error {
+	close(b.done)
+	err := b.Transcoder.Close()
+	b.wg.Wait()
+	return err
+}
+
+// voiceJob is a DMR voice burst for the voice worker, or the end of the
+// transmission to USRP
+type voiceJob struct {
+	frames [recorder.AMBE_FRAMES_PER_BURST][recorder.AMBE_FRAME_LENGTH]byte
+	pcm    [][]int16  // frames decoded on the main loop, nil when the worker decodes them
+	path   *audioPath // path to meter the frames for, nil when already metered
+	call   uint64     // call of path the burst belongs to
+	usrp   bool       // send the frames to USRP
+	tg     uint32     // talk group stamped on them
+	unkey  bool       // unkey USRP instead
+}
+
+// voiceLevel is the decoded voice of a burst, metered on the main loop
+type voiceLevel struct {
+	path *audioPath
+	call uint64
+	pcm  [][]int16 // nil for frames that could not be decoded
+}
+
+// usrpAudio is a voice packet received from USRP, encoded for DMR
+type usrpAudio struct {
+	keyup bool
+	voice bool   // the packet carried audio
+	ambe  []byte // its AMBE+2 frame, nil when it could not be encoded
+}
+
+// voiceWorker transcodes voice for level metering and USRP off the main
+// loop, so a slow or stopped transcoder does not hold up the YSF and DMR
+// frames
+// Each DMR burst is decoded once, by one goroutine: the PCM goes back to the
+// main loop on levels for metering and is written to USRP by the worker,
+// which alone sends to the USRP client while it runs. Audio received from
+// USRP is encoded by a second goroutine and handed to the main loop on audio.
+type voiceWorker struct {
+	decoder *breakerTranscoder
+	usrp    *usrp.Client     // nil unless [USRP] is enabled
+	encoder codec.Transcoder // for the audio from USRP
+	jobs    chan voiceJob    // from the main loop
+	levels  chan voiceLevel  // to the main loop
+	audio   chan usrpAudio   // to the main loop
+	dropped atomic.Uint64    // bursts not transcoded in time
+
+	tasks  *supervisor.Supervisor
+	cancel context.CancelFunc
+}
+
+// newVoiceWorker creates the voice worker, decoding with a transcoder of
+// its own from [Audio] when the audio is metered, else from [USRP]; nil
+// when neither is enabled
+func newVoiceWorker(cfg *config.Config, metered bool, bridge *usrpBridge) (*voiceWorker, error) {
+	if !metered && bridge == nil {
+		return nil, nil
+	}
+	name, arg := cfg.GetAudioTranscoder(), cfg.GetAudioTranscoderArg()
+	if !metered {
+		name, arg = cfg.GetUSRPTranscoder(), cfg.GetUSRPTranscoderArg()
+	}
+	decoder, err := codec.NewTranscoder(name, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the %s transcoder: %v", name, err)
+	}
+
+	w := &voiceWorker{
+		decoder: newBreakerTranscoder(name, decoder),
+		jobs:    make(chan voiceJob, voiceQueueLength),
+		levels:  make(chan voiceLevel, voiceQueueLength),
+		audio:   make(chan usrpAudio, voiceQueueLength),
+		tasks:   supervisor.New("voice worker"),
+	}
+	if bridge != nil {
+		w.usrp, w.encoder = bridge.client, bridge.transcoder
+	}
+	return w, nil
+}
+
+// start runs the worker goroutines until stop; the USRP client must be open
+func (w *voiceWorker) start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.tasks.Go(ctx, "decoder", w.decode)
+	if w.usrp != nil {
+		w.tasks.Go(ctx, "USRP receiver", w.receive)
+	}
+}
+
+// stop stops the worker goroutines and closes the worker's transcoder
+// Jobs still queued are dropped.
+func (w *voiceWorker) stop() {
+	if w.cancel != nil {
+		w.cancel()
+		w.tasks.Wait()
+	}
+	w.decoder.Close()
+}
+
+// submit queues a job, dropping the oldest queued one when the worker has
+// fallen behind
+func (w *voiceWorker) submit(job voiceJob) {
+	if queueDropOldest(w.jobs, job) {
+		w.dropped.Add(1)
+	}
+}
+
+// decode goroutine - decodes the queued bursts for metering and USRP
+func (w *voiceWorker) decode(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-w.jobs:
+			w.handle(job)
+		}
+	}
+}
+
+// handle decodes the frames of job, unless the main loop did, returns them
+// for metering and writes them to USRP
+func (w *voiceWorker) handle(job voiceJob) {
+	if job.unkey {
+		if err := w.usrp.WriteEnd(); err != nil {
+			log.Printf("USRP send error: %v", err)
+		}
+		return
+	}
+
+	pcm := job.pcm
+	if pcm == nil {
+		pcm = make([][]int16, len(job.frames))
+		for i := range job.frames {
+			pcm[i], _ = w.decoder.DecodeAMBE(job.frames[i][:])
+		}
+	}
+	if job.path != nil {
+		// Metering skips a burst rather than holding up USRP
+		select {
+		case w.levels <- voiceLevel{path: job.path, call: job.call, pcm: pcm}:
+		default:
+		}
+	}
+	if !job.usrp {
+		return
+	}
+
+	w.usrp.SetTalkGroup(job.tg)
+	for _, frame := range pcm {
+		if frame == nil {
+			// Silence keeps the stream timed for the listeners
+			frame = make([]int16, usrp.SAMPLES_PER_FRAME)
+		}
+		if err := w.usrp.WriteAudio(frame); err != nil {
+			log.Printf("USRP send error: %v", err)
+			return
+		}
+	}
+}
+
+// receive goroutine - encodes the voice packets received from USRP
+func (w *voiceWorker) receive(ctx context.Context) {
+	var packet usrp.Packet
+	for {
+		for w.usrp.Read(&packet) {
+			if packet.Type != usrp.TYPE_VOICE {
+				continue
+			}
+			audio := usrpAudio{keyup: packet.Keyup, voice: len(packet.Audio) > 0}
+			if audio.keyup && audio.voice {
+				ambe, err := w.encoder.EncodeAMBE(packet.Audio)
+				if err == nil && len(ambe) == recorder.AMBE_FRAME_LENGTH {
+					audio.ambe = ambe
+				}
+			}
+			queueDropOldest(w.audio, audio)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.usrp.Ready():
+		}
+	}
+}
+
+// queueDropOldest queues v on ch, dropping the oldest entries while ch is
+// full, and reports whether any was dropped
+// ch must have a single sender.
+func queueDropOldest[T any](ch chan T, v T) bool {
+	dropped := false
+	for {
+		select {
+		case ch <- v:
+			return dropped
+		default:
+		}
+		select {
+		case <-ch:
+			dropped = true
+		default:
+		}
+	}
+}